@@ -0,0 +1,217 @@
+// Command apikey mints, lists and revokes the scoped API keys sibling
+// services use to call the API on behalf of users. The secret is printed
+// once at creation; only its hash is stored.
+//
+//	go run ./cmd/apikey create -name sous -scopes import:write,search:read -expires 90d
+//	go run ./cmd/apikey create -name ops -scopes admin
+//	go run ./cmd/apikey list
+//	go run ./cmd/apikey revoke -id <key id>
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	_ "github.com/joho/godotenv/autoload"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/apikey"
+)
+
+const usage = `Usage: apikey <command> [flags]
+
+Commands:
+  create  -name NAME -scopes SCOPE,... [-users UUID,...] [-expires 90d|2160h]
+  list
+  revoke  -id KEY_ID
+
+Scopes: %s, admin
+`
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, usage, strings.Join(apikey.Scopes, ", "))
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	queries := generated.New(pool)
+
+	args := flag.Args()[1:]
+	switch flag.Arg(0) {
+	case "create":
+		create(ctx, queries, args)
+	case "list":
+		list(ctx, queries)
+	case "revoke":
+		revoke(ctx, queries, args)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+func create(ctx context.Context, queries *generated.Queries, args []string) {
+	fs := flag.NewFlagSet("create", flag.ExitOnError)
+	name := fs.String("name", "", "calling service, shown in audit logs")
+	scopes := fs.String("scopes", "", "comma-separated scopes")
+	users := fs.String("users", "", "comma-separated user IDs the key may act for (default any user)")
+	expires := fs.String("expires", "", "lifetime, e.g. 90d or 720h (default never)")
+	fs.Parse(args)
+
+	if strings.TrimSpace(*name) == "" {
+		log.Fatalf("-name is required")
+	}
+	granted, err := apikey.ParseScopes(splitList(*scopes))
+	if err != nil {
+		log.Fatalf("Invalid scopes: %v", err)
+	}
+
+	var allowedUsers []pgtype.UUID
+	for _, raw := range splitList(*users) {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			log.Fatalf("Invalid user ID %q: %v", raw, err)
+		}
+		allowedUsers = append(allowedUsers, pgtype.UUID{Bytes: id, Valid: true})
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if *expires != "" {
+		lifetime, err := parseLifetime(*expires)
+		if err != nil {
+			log.Fatalf("Invalid -expires: %v", err)
+		}
+		expiresAt = pgtype.Timestamptz{Time: time.Now().Add(lifetime), Valid: true}
+	}
+
+	key, err := apikey.Generate()
+	if err != nil {
+		log.Fatalf("Failed to generate key: %v", err)
+	}
+
+	row, err := queries.CreateServiceAPIKey(ctx, generated.CreateServiceAPIKeyParams{
+		Name:           strings.TrimSpace(*name),
+		KeyPrefix:      key.DisplayPrefix,
+		KeyHash:        key.Hash,
+		Scopes:         granted,
+		AllowedUserIds: allowedUsers,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		log.Fatalf("Failed to store key: %v", err)
+	}
+
+	fmt.Printf("Created key %s for %s\n", uuid.UUID(row.ID.Bytes), row.Name)
+	fmt.Printf("Scopes: %s\n", strings.Join(row.Scopes, ","))
+	if row.ExpiresAt.Valid {
+		fmt.Printf("Expires: %s\n", row.ExpiresAt.Time.Format(time.RFC3339))
+	}
+	fmt.Printf("\n%s\n\nStore this secret now; it cannot be shown again.\n", key.Secret)
+}
+
+func list(ctx context.Context, queries *generated.Queries) {
+	keys, err := queries.ListServiceAPIKeys(ctx)
+	if err != nil {
+		log.Fatalf("Failed to list keys: %v", err)
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tPREFIX\tSCOPES\tUSERS\tEXPIRES\tLAST USED\tSTATUS")
+	for _, key := range keys {
+		users := "any"
+		if len(key.AllowedUserIds) > 0 {
+			users = strconv.Itoa(len(key.AllowedUserIds))
+		}
+		status := "active"
+		switch {
+		case key.RevokedAt.Valid:
+			status = "revoked"
+		case key.ExpiresAt.Valid && key.ExpiresAt.Time.Before(time.Now()):
+			status = "expired"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			uuid.UUID(key.ID.Bytes), key.Name, key.KeyPrefix, strings.Join(key.Scopes, ","),
+			users, formatTime(key.ExpiresAt), formatTime(key.LastUsedAt), status)
+	}
+	tw.Flush()
+}
+
+func revoke(ctx context.Context, queries *generated.Queries, args []string) {
+	fs := flag.NewFlagSet("revoke", flag.ExitOnError)
+	id := fs.String("id", "", "ID of the key to revoke")
+	fs.Parse(args)
+
+	keyID, err := uuid.Parse(*id)
+	if err != nil {
+		log.Fatalf("Invalid -id: %v", err)
+	}
+
+	row, err := queries.RevokeServiceAPIKey(ctx, pgtype.UUID{Bytes: keyID, Valid: true})
+	if err != nil {
+		log.Fatalf("Failed to revoke key: %v", err)
+	}
+	fmt.Printf("Revoked key %s (%s)\n", keyID, row.Name)
+}
+
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// parseLifetime accepts a Go duration or a whole number of days ("90d").
+func parseLifetime(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("%q is not a number of days", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, err
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("%q is not a positive duration", s)
+	}
+	return d, nil
+}
+
+func formatTime(t pgtype.Timestamptz) string {
+	if !t.Valid {
+		return "-"
+	}
+	return t.Time.Format(time.RFC3339)
+}
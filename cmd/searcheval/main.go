@@ -0,0 +1,164 @@
+// Command searcheval runs a judged query set against a seeded database and
+// reports NDCG, MRR and recall per hybrid ranking strategy, so ranking
+// changes can be compared before they ship. It scores the fused ranking of
+// each strategy before query expansion and reranking, so the LLM steps do
+// not blur the comparison.
+//
+//	go run ./cmd/searcheval -queries cmd/searcheval/queries.example.json -strategies linear,rrf
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	_ "github.com/joho/godotenv/autoload"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/openai"
+	"github.com/socialchef/remy/internal/services/search"
+)
+
+type strategyReport struct {
+	Strategy string                         `json:"strategy"`
+	Mean     search.QueryMetrics            `json:"mean"`
+	Queries  map[string]search.QueryMetrics `json:"queries,omitempty"`
+}
+
+func main() {
+	queriesPath := flag.String("queries", "cmd/searcheval/queries.example.json", "path to the judged query set (JSON)")
+	strategies := flag.String("strategies", "linear,rrf", "comma-separated ranking strategies to compare")
+	k := flag.Int("k", 10, "rank cut-off for NDCG and recall")
+	vectorWeight := flag.Float64("vector-weight", 0, "override search.vector_weight; 0 turns the vector signal off")
+	textWeight := flag.Float64("text-weight", 0, "override search.text_weight; 0 turns the text signal off")
+	rrfK := flag.Int("rrf-k", 0, "override search.rrf_k")
+	perQuery := flag.Bool("per-query", false, "include per-query metrics")
+	jsonOutput := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	ctx := context.Background()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+	// Weights apply when their flag is given, so 0 can be compared too
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "vector-weight":
+			cfg.Search.VectorWeight = vectorWeight
+		case "text-weight":
+			cfg.Search.TextWeight = textWeight
+		}
+	})
+	if *rrfK > 0 {
+		cfg.Search.RRFK = *rrfK
+	}
+
+	queries, err := loadJudgedQueries(*queriesPath)
+	if err != nil {
+		log.Fatalf("Failed to load judged queries: %v", err)
+	}
+
+	pool, err := db.NewPool(ctx, cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer pool.Close()
+
+	queriesDB := generated.New(pool)
+	openaiClient := openai.NewClient(cfg.OpenAIKey)
+
+	var reports []strategyReport
+	for _, strategy := range strings.Split(*strategies, ",") {
+		strategy = strings.TrimSpace(strategy)
+		if strategy != search.RankingLinear && strategy != search.RankingRRF {
+			log.Fatalf("Unknown ranking strategy %q", strategy)
+		}
+
+		strategyCfg := *cfg
+		strategyCfg.Search.RankingStrategy = strategy
+		client := search.NewClient(queriesDB, openaiClient, &strategyCfg)
+
+		report := strategyReport{Strategy: strategy}
+		if *perQuery {
+			report.Queries = make(map[string]search.QueryMetrics, len(queries))
+		}
+
+		all := make([]search.QueryMetrics, 0, len(queries))
+		for _, q := range queries {
+			results, err := client.RankHybrid(ctx, q.Query, int32(*k))
+			if err != nil {
+				log.Fatalf("Search failed for %q (%s): %v", q.Query, strategy, err)
+			}
+
+			ranked := make([]string, len(results))
+			for i, r := range results {
+				ranked[i] = r.ID
+			}
+
+			m := search.EvaluateRanking(ranked, q.Judgments, *k)
+			all = append(all, m)
+			if *perQuery {
+				report.Queries[q.Query] = m
+			}
+		}
+		report.Mean = search.MeanMetrics(all)
+		reports = append(reports, report)
+	}
+
+	if *jsonOutput {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatalf("Failed to encode report: %v", err)
+		}
+		return
+	}
+
+	printReports(reports, queries, *k)
+}
+
+func loadJudgedQueries(path string) ([]search.JudgedQuery, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var queries []search.JudgedQuery
+	if err := json.Unmarshal(data, &queries); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(queries) == 0 {
+		return nil, fmt.Errorf("%s contains no queries", path)
+	}
+	return queries, nil
+}
+
+func printReports(reports []strategyReport, queries []search.JudgedQuery, k int) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer w.Flush()
+
+	fmt.Fprintf(w, "%d queries, k=%d\n\n", len(queries), k)
+	fmt.Fprintf(w, "STRATEGY\tNDCG@%d\tMRR\tRECALL@%d\n", k, k)
+	for _, r := range reports {
+		fmt.Fprintf(w, "%s\t%.4f\t%.4f\t%.4f\n", r.Strategy, r.Mean.NDCG, r.Mean.ReciprocalRank, r.Mean.Recall)
+	}
+
+	for _, r := range reports {
+		if len(r.Queries) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s\nQUERY\tNDCG@%d\tRR\tRECALL@%d\n", r.Strategy, k, k)
+		for _, q := range queries {
+			m := r.Queries[q.Query]
+			fmt.Fprintf(w, "%s\t%.4f\t%.4f\t%.4f\n", q.Query, m.NDCG, m.ReciprocalRank, m.Recall)
+		}
+	}
+}
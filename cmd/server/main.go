@@ -6,22 +6,32 @@ import (
 	"log"
 	"log/slog"
 	"net/http"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
+	"github.com/hibiken/asynq"
 	"github.com/riandyrn/otelchi"
 	otelchimetric "github.com/riandyrn/otelchi/metric"
 	"github.com/socialchef/remy/internal/api"
+	"github.com/socialchef/remy/internal/cache"
 	"github.com/socialchef/remy/internal/config"
 	"github.com/socialchef/remy/internal/db"
 	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/health"
 	"github.com/socialchef/remy/internal/logger"
 	"github.com/socialchef/remy/internal/metrics"
 	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/openapi"
 	"github.com/socialchef/remy/internal/sentry"
+	"github.com/socialchef/remy/internal/services/apikey"
+	"github.com/socialchef/remy/internal/services/idempotency"
 	"github.com/socialchef/remy/internal/services/openai"
+	"github.com/socialchef/remy/internal/services/ratelimit"
 	"github.com/socialchef/remy/internal/services/search"
+	"github.com/socialchef/remy/internal/services/storage"
 	"github.com/socialchef/remy/internal/telemetry"
 	"github.com/socialchef/remy/internal/worker"
 	"go.opentelemetry.io/otel"
@@ -76,28 +86,81 @@ func main() {
 	asynqClient := worker.NewClient(cfg.RedisURL)
 	defer asynqClient.Close()
 
-	// Initialize broadcaster for progress updates
-	_ = worker.NewProgressBroadcaster(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey)
-
 	// Initialize OpenAI client for search
 	openaiClient := openai.NewClient(cfg.OpenAIKey)
-	// Initialize search client
+	// Initialize search client with Redis-backed query caching
+	redisClient := worker.NewRedisClient(cfg.RedisURL)
+	defer redisClient.Close()
 	searchClient := search.NewClient(queries, openaiClient, cfg)
+	searchClient.SetQueryCache(cache.NewQueryCache(redisClient))
 
 	// API handlers
 	apiServer := api.NewServer(cfg, queries, asynqClient, searchClient)
+	apiServer.SetTxBeginner(pool)
+	storageClient := storage.NewClient(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey)
+	apiServer.SetImageUploader(storageClient)
+	apiServer.SetExportStore(storageClient)
+	apiServer.SetImportStore(storageClient)
+	apiServer.SetProgressStream(worker.NewProgressStream(redisClient))
+	apiServer.SetQueueInspector(asynq.NewInspectorFromRedisClient(redisClient))
+	apiServer.SetIdempotencyStore(idempotency.NewStore(redisClient))
+
+	// Per-user rate limits, shared by every instance through Redis
+	var rateLimiter middleware.RateLimiter
+	if cfg.RateLimit.Enabled {
+		rateLimiter = ratelimit.NewLimiter(redisClient)
+	}
+	apiRateLimit := middleware.RateLimit(rateLimiter, "api",
+		ratelimit.PerMinute(cfg.RateLimit.RequestsPerMinute, cfg.RateLimit.Burst),
+		ratelimit.PerMinute(cfg.RateLimit.ServiceRequestsPerMinute, cfg.RateLimit.ServiceBurst),
+	)
+	importRateLimit := middleware.UserRateLimit(rateLimiter, "import",
+		ratelimit.PerMinute(cfg.RateLimit.ImportsPerMinute, cfg.RateLimit.ImportBurst),
+	)
+
+	// API description, also used to validate request bodies
+	spec, err := openapi.Load()
+	if err != nil {
+		log.Fatalf("Failed to load API spec: %v", err)
+	}
+	validateRequest := middleware.ValidateRequest(spec, cfg.Security.MaxBodyBytes)
+
+	// Data-changing requests are recorded in audit_events
+	audit := middleware.Audit(queries)
+
+	// Readiness of the dependencies every request needs
+	readiness := health.NewChecker(cfg.Server.ReadinessCacheTTL)
+	readiness.Require("database", pool.Ping)
+	readiness.Require("redis", func(ctx context.Context) error {
+		return redisClient.Ping(ctx).Err()
+	})
+	if cfg.Server.CheckProviders {
+		providerClient := &http.Client{Timeout: 2 * time.Second}
+		providers := []struct{ name, key, url string }{
+			{"openai", cfg.OpenAIKey, "https://api.openai.com/v1/models"},
+			{"groq", cfg.GroqKey, "https://api.groq.com/openai/v1/models"},
+			{"cerebras", cfg.CerebrasKey, "https://api.cerebras.ai/v1/models"},
+		}
+		for _, p := range providers {
+			if p.key != "" {
+				readiness.Optional(p.name, health.HTTPCheck(providerClient, p.url))
+			}
+		}
+	}
 
 	// Router
 	r := chi.NewRouter()
 
 	// Middleware
+	r.Use(middleware.NoWriteTimeout("/api/v1/imports/stream", "/api/imports/stream"))
+	r.Use(middleware.RequestID)
 	r.Use(sentry.HTTPMiddleware)
 
 	// Middleware
 	r.Use(otelchi.Middleware("socialchef-server",
 		otelchi.WithChiRoutes(r),
 		otelchi.WithFilter(func(r *http.Request) bool {
-			return r.URL.Path != "/health"
+			return r.URL.Path != "/health" && r.URL.Path != "/livez" && r.URL.Path != "/readyz"
 		}),
 	))
 
@@ -107,36 +170,152 @@ func main() {
 	r.Use(otelchimetric.NewRequestInFlight(metricCfg))
 	r.Use(otelchimetric.NewResponseSizeBytes(metricCfg))
 
-	r.Use(cors.Handler(cors.Options{
-		AllowedOrigins:   []string{"*"},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-On-Behalf-Of"},
-		AllowCredentials: true,
-	}))
+	// Headers browser clients may read from responses
+	exposedHeaders := []string{
+		middleware.RequestIDHeader, "Retry-After", api.IdempotentReplayedHeader,
+		middleware.RateLimitLimitHeader, middleware.RateLimitRemainingHeader, middleware.RateLimitResetHeader,
+		api.QuotaDailyLimitHeader, api.QuotaDailyRemainingHeader, api.QuotaMonthlyLimitHeader, api.QuotaMonthlyRemainingHeader,
+		middleware.DeprecationHeader, middleware.SunsetHeader, "Link",
+	}
 
-	// Health check endpoint
+	// CORS is off unless origins are configured for the environment
+	if len(cfg.CORS.AllowedOrigins) > 0 {
+		r.Use(cors.Handler(cors.Options{
+			AllowedOrigins:   cfg.CORS.AllowedOrigins,
+			AllowedMethods:   cfg.CORS.AllowedMethods,
+			AllowedHeaders:   cfg.CORS.AllowedHeaders,
+			ExposedHeaders:   exposedHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           int(cfg.CORS.MaxAge.Seconds()),
+		}))
+	}
+	r.Use(middleware.SecurityHeaders(cfg.Security))
+	// The upload routes limit their own, larger bodies
+	r.Use(middleware.LimitBody(cfg.Security.MaxBodyBytes,
+		"/api/v1/imports/image", "/api/recipe/from-image",
+		"/api/v1/bulk-imports/file", "/api/bulk-import/file",
+	))
+
+	// Health check endpoints; /health is kept for existing probes
 	r.Get("/health", func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	})
+	r.Get("/livez", readiness.HandleLive)
+	r.Get("/readyz", readiness.HandleReady)
+	r.Method(http.MethodGet, "/openapi.json", spec)
+
+	// API routes live under /api/v1; the routes from before it stay as
+	// deprecated aliases, listed in openapi.json
+	v1 := api.NewV1Router(r, spec)
 
 	// Protected API routes
-	r.Group(func(r chi.Router) {
-		r.Use(middleware.AuthMiddleware(cfg))
-		r.Post("/api/recipe", apiServer.HandleImportRecipe)
-		r.Get("/api/recipe-status", apiServer.HandleJobStatus)
-		r.Get("/api/instruction-ingredients-count", apiServer.HandleGetInstructionIngredientsCount)
-		r.Get("/api/user-import-status", apiServer.HandleUserImportStatus)
-		r.Post("/api/generate-embedding", apiServer.HandleGenerateEmbedding)
-		r.Post("/api/v1/search", apiServer.HandleSearch)
-		r.Post("/api/v1/search/semantic", apiServer.HandleSearchSemantic)
-		r.Post("/api/v1/search/by-name", apiServer.HandleSearchByName)
-		r.Post("/api/bulk-import", apiServer.HandleBulkImportRecipe)
-		r.Get("/api/bulk-import/{bulkJobID}", apiServer.HandleBulkImportStatus)
-		r.Get("/api/bulk-imports", apiServer.HandleListUserBulkImports)
-		r.Delete("/api/bulk-import/{bulkJobID}", apiServer.HandleCancelBulkImport)
-		r.Get("/api/recipes/{recipeID}", apiServer.HandleGetRecipe)
-		r.Get("/api/recipes/{recipeID}/steps", apiServer.HandleGetRecipeSteps)
+	v1.Group(func(r api.V1Router) {
+		r.Use(middleware.AuthMiddleware(cfg, queries))
+		r.Use(apiRateLimit)
+		r.Use(validateRequest)
+
+		// Service API keys only reach the routes their scopes allow
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeImportWrite))
+			r.Use(audit)
+			r.With(importRateLimit).Post("/imports", apiServer.HandleImportRecipe)
+			r.With(importRateLimit).Post("/imports/text", apiServer.HandleImportRecipeFromText)
+			r.With(importRateLimit).Post("/imports/image", apiServer.HandleImportRecipeFromImage)
+			r.With(importRateLimit).Post("/bulk-imports", apiServer.HandleBulkImportRecipe)
+			r.With(importRateLimit).Post("/bulk-imports/file", apiServer.HandleBulkImportFile)
+			r.Delete("/bulk-imports/{bulkJobID}", apiServer.HandleCancelBulkImport)
+		})
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeImportRead))
+			r.Get("/imports/{jobID}", apiServer.HandleJobStatus)
+			r.Get("/imports", apiServer.HandleUserImportStatus)
+			r.Get("/imports/stream", apiServer.HandleImportsStream)
+			r.Get("/bulk-imports/{bulkJobID}", apiServer.HandleBulkImportStatus)
+			r.Get("/bulk-imports", apiServer.HandleListUserBulkImports)
+		})
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeSearchRead))
+			r.Post("/embeddings", apiServer.HandleGenerateEmbedding)
+			r.Post("/search", apiServer.HandleSearch)
+			r.Post("/search/semantic", apiServer.HandleSearchSemantic)
+			r.Post("/search/by-name", apiServer.HandleSearchByName)
+		})
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeRecipeRead))
+			r.Get("/recipes/{recipeID}/instruction-ingredients-count", apiServer.HandleGetInstructionIngredientsCount)
+			r.Get("/recipes/{recipeID}", apiServer.HandleGetRecipe)
+			r.Get("/recipes/{recipeID}/steps", apiServer.HandleGetRecipeSteps)
+			r.Get("/recipes/{recipeID}/revisions", apiServer.HandleListRecipeRevisions)
+			r.Get("/recipes/{recipeID}/revisions/{revision}", apiServer.HandleGetRecipeRevision)
+			r.Get("/recipes/{recipeID}/regenerations/{regenerationID}", apiServer.HandleGetRecipeRegeneration)
+			r.Get("/recipes/{recipeID}/export", apiServer.HandleExportRecipe)
+			r.With(audit).Post("/exports", apiServer.HandleCreateRecipeExport)
+			r.Get("/exports/{exportID}", apiServer.HandleGetRecipeExport)
+			r.Get("/recipes/{recipeID}/shares", apiServer.HandleListRecipeShares)
+		})
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeRecipeWrite))
+			r.Use(audit)
+			r.Patch("/recipes/{recipeID}", apiServer.HandleUpdateRecipe)
+			r.Delete("/recipes/{recipeID}", apiServer.HandleDeleteRecipe)
+			r.Post("/recipes/{recipeID}/revisions/{revision}/restore", apiServer.HandleRestoreRecipeRevision)
+			r.With(importRateLimit).Post("/recipes/{recipeID}/regenerate", apiServer.HandleRegenerateRecipe)
+			r.Post("/recipes/{recipeID}/shares", apiServer.HandleCreateRecipeShare)
+			r.Delete("/recipes/{recipeID}/shares/{shareID}", apiServer.HandleRevokeRecipeShare)
+		})
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeWebhookRead))
+			r.Get("/webhooks", apiServer.HandleListWebhooks)
+			r.Get("/webhooks/{webhookID}/deliveries", apiServer.HandleListWebhookDeliveries)
+		})
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireScope(apikey.ScopeWebhookWrite))
+			r.Use(audit)
+			r.Post("/webhooks", apiServer.HandleCreateWebhook)
+			r.Delete("/webhooks/{webhookID}", apiServer.HandleDeleteWebhook)
+			r.Post("/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver", apiServer.HandleRedeliverWebhook)
+		})
+		r.With(middleware.RequireScope(apikey.ScopeUsageRead)).Get("/me/usage", apiServer.HandleGetUsage)
+		r.With(middleware.RequireScope(apikey.ScopeActivityRead)).Get("/me/activity", apiServer.HandleListActivity)
+		// Only the user themself may export or erase their account
+		r.Group(func(r api.V1Router) {
+			r.Use(middleware.RequireUser)
+			r.Use(audit)
+			r.Post("/me/exports", apiServer.HandleCreateAccountExport)
+			r.Get("/me/exports/{exportID}", apiServer.HandleGetAccountExport)
+			r.Delete("/me", apiServer.HandleEraseAccount)
+			r.Get("/me/erasure", apiServer.HandleGetAccountErasure)
+		})
+	})
+
+	// Public share links (signed token, no account needed)
+	r.Get("/r/{token}", apiServer.HandleGetSharedRecipe)
+
+	// Admin API routes (operators, see AdminMiddleware)
+	v1.Group(func(r api.V1Router) {
+		r.Use(middleware.AdminMiddleware(cfg, queries))
+		r.Use(validateRequest)
+		r.Use(audit)
+		r.Post("/admin/embeddings/backfill", apiServer.HandleTriggerEmbeddingBackfill)
+		r.Get("/admin/embeddings/backfill", apiServer.HandleEmbeddingBackfillStatus)
+		r.Post("/admin/webhooks", apiServer.HandleCreateServiceWebhook)
+		r.Get("/admin/webhooks", apiServer.HandleListServiceWebhooks)
+		r.Delete("/admin/webhooks/{webhookID}", apiServer.HandleDeleteServiceWebhook)
+		r.Get("/admin/webhooks/{webhookID}/deliveries", apiServer.HandleListServiceWebhookDeliveries)
+		r.Post("/admin/webhooks/{webhookID}/deliveries/{deliveryID}/redeliver", apiServer.HandleRedeliverServiceWebhook)
+		r.Put("/admin/users/{userID}/plan", apiServer.HandleSetUserPlan)
+		r.Get("/admin/jobs", apiServer.HandleAdminListImportJobs)
+		r.Get("/admin/jobs/stats", apiServer.HandleAdminImportJobStats)
+		r.Post("/admin/jobs/{jobID}/retry", apiServer.HandleAdminRetryImportJob)
+		r.Post("/admin/jobs/{jobID}/cancel", apiServer.HandleAdminCancelImportJob)
+		r.Get("/admin/bulk-imports", apiServer.HandleAdminListBulkImports)
+		r.Get("/admin/queues", apiServer.HandleAdminListQueues)
+		r.Get("/admin/queues/{queue}/dead", apiServer.HandleAdminListDeadTasks)
+		r.Post("/admin/queues/{queue}/dead/{taskID}/run", apiServer.HandleAdminRunDeadTask)
+		r.Delete("/admin/queues/{queue}/dead/{taskID}", apiServer.HandleAdminDeleteDeadTask)
+		r.Post("/admin/maintenance/{task}", apiServer.HandleAdminRunMaintenance)
+		r.Get("/admin/audit-events", apiServer.HandleAdminListAuditEvents)
 	})
 
 	// Start server
@@ -145,9 +324,45 @@ func main() {
 		port = "8080"
 	}
 
-	slog.Info("Starting server", "port", port)
+	srv := &http.Server{
+		Addr:              ":" + port,
+		Handler:           r,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+	srv.RegisterOnShutdown(apiServer.CloseStreams)
+
+	// Handle shutdown
+	stop, cancel := signal.NotifyContext(ctx, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("Starting server", "port", port)
+		serveErr <- srv.ListenAndServe()
+	}()
 
-	if err := http.ListenAndServe(":"+port, r); err != nil {
+	select {
+	case err := <-serveErr:
 		log.Fatalf("Server failed: %v", err)
+	case <-stop.Done():
+	}
+
+	// Fail readiness and keep serving until the load balancer's next probe
+	// has seen it, then stop accepting connections and let in-flight
+	// requests finish
+	slog.Info("Draining server...", "drain_period", cfg.Server.DrainPeriod)
+	readiness.Drain()
+	time.Sleep(cfg.Server.DrainPeriod)
+
+	slog.Info("Shutting down server...", "timeout", cfg.Server.ShutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(ctx, cfg.Server.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		slog.Error("Graceful shutdown timed out", "error", err)
+		srv.Close()
 	}
+	slog.Info("Server stopped")
 }
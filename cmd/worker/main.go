@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"flag"
 	"github.com/hibiken/asynq"
 	_ "github.com/joho/godotenv/autoload"
 	"log"
@@ -17,12 +18,15 @@ import (
 	"github.com/socialchef/remy/internal/logger"
 	"github.com/socialchef/remy/internal/metrics"
 	"github.com/socialchef/remy/internal/sentry"
+	"github.com/socialchef/remy/internal/services/authadmin"
 	"github.com/socialchef/remy/internal/services/openai"
 	"github.com/socialchef/remy/internal/services/recipe"
 	"github.com/socialchef/remy/internal/services/scraper"
 	"github.com/socialchef/remy/internal/services/storage"
 	"github.com/socialchef/remy/internal/services/transcription"
+	"github.com/socialchef/remy/internal/services/webhook"
 	"github.com/socialchef/remy/internal/telemetry"
+	"github.com/socialchef/remy/internal/utils"
 	"github.com/socialchef/remy/internal/worker"
 )
 
@@ -31,6 +35,9 @@ func main() {
 		sentry.Recover()
 	}()
 
+	runScheduler := flag.Bool("scheduler", false, "run the periodic task scheduler; enable it in one worker process only")
+	flag.Parse()
+
 	ctx := context.Background()
 
 	cfg, err := config.Load()
@@ -94,7 +101,14 @@ func main() {
 	provider := transcription.NewProvider(cfg.Transcription, cfg.OpenAIKey, cfg.GroqKey)
 	transcriptionClient := transcription.NewProviderAdapter(provider)
 	storageClient := storage.NewClient(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey)
-	broadcaster := worker.NewProgressBroadcaster(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey)
+
+	// Progress goes to Supabase Realtime and to the API server's event stream
+	redisClient := worker.NewRedisClient(cfg.RedisURL)
+	defer redisClient.Close()
+	broadcaster := worker.MultiBroadcaster{
+		worker.NewProgressBroadcaster(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey),
+		worker.NewProgressStream(redisClient),
+	}
 
 	workerMetrics, err := worker.NewWorkerMetrics()
 	if err != nil {
@@ -119,6 +133,21 @@ func main() {
 		workerMetrics,
 		asynqClient,
 	)
+	processor.SetEmbeddingConfig(cfg.Embedding)
+	processor.SetTxBeginner(pool)
+	processor.SetAuthAdmin(authadmin.NewClient(cfg.SupabaseURL, cfg.SupabaseServiceRoleKey))
+	if cfg.Env == "development" {
+		// Local webhook receivers listen on loopback or private addresses
+		processor.SetWebhookClient(webhook.NewClient(webhook.NewHTTPClient(true), utils.WebhookRetryConfig()))
+	}
+
+	// Providers a regeneration can request by name; an explicit choice runs without fallback
+	recipeProviders := make(map[string]worker.GroqClient)
+	for _, name := range []recipe.ProviderType{recipe.ProviderGroq, recipe.ProviderCerebras, recipe.ProviderOpenAI} {
+		provider := recipe.NewProvider(config.RecipeGenerationConfig{Provider: string(name)}, cfg.GroqKey, cfg.CerebrasKey, cfg.OpenAIKey)
+		recipeProviders[string(name)] = recipe.NewGroqClientAdapter(provider)
+	}
+	processor.SetRecipeProviders(recipeProviders)
 
 	// Asynq server
 	srv := worker.NewServer(cfg.RedisURL)
@@ -132,6 +161,31 @@ func main() {
 	mux.HandleFunc(worker.TypeGenerateRichInstructions, processor.HandleGenerateRichInstructions)
 	mux.HandleFunc(worker.TypeCleanupJobs, processor.HandleCleanupJobs)
 	mux.HandleFunc(worker.TypeProcessBulkImport, processor.HandleProcessBulkImport)
+	mux.HandleFunc(worker.TypeBackfillEmbeddings, processor.HandleBackfillEmbeddings)
+	mux.HandleFunc(worker.TypeGarbageCollectStorage, processor.HandleGarbageCollectStorage)
+	mux.HandleFunc(worker.TypeRegenerateRecipe, processor.HandleRegenerateRecipe)
+	mux.HandleFunc(worker.TypeExportRecipes, processor.HandleExportRecipes)
+	mux.HandleFunc(worker.TypeImportRecipeFile, processor.HandleImportRecipeFile)
+	mux.HandleFunc(worker.TypeDeliverWebhook, processor.HandleDeliverWebhook)
+	mux.HandleFunc(worker.TypeExportAccount, processor.HandleExportAccount)
+	mux.HandleFunc(worker.TypeEraseAccount, processor.HandleEraseAccount)
+
+	// Periodic tasks. Every scheduler enqueues every periodic task, so only
+	// one worker process is started with -scheduler.
+	scheduler := worker.NewScheduler(cfg.RedisURL)
+	if *runScheduler && cfg.EmbeddingBackfillSchedule != "off" {
+		backfillTask, err := worker.NewBackfillEmbeddingsTask(worker.BackfillEmbeddingsPayload{})
+		if err != nil {
+			log.Fatalf("Failed to create embedding backfill task: %v", err)
+		}
+		if _, err := scheduler.Register(cfg.EmbeddingBackfillSchedule, backfillTask); err != nil {
+			log.Fatalf("Failed to schedule embedding backfill: %v", err)
+		}
+		slog.Info("Scheduled embedding backfill", "schedule", cfg.EmbeddingBackfillSchedule)
+	}
+	if err := scheduler.Start(); err != nil {
+		log.Fatalf("Failed to start scheduler: %v", err)
+	}
 
 	// Handle shutdown
 	sigChan := make(chan os.Signal, 1)
@@ -140,6 +194,7 @@ func main() {
 	go func() {
 		<-sigChan
 		slog.Info("Shutting down worker...")
+		scheduler.Shutdown()
 		srv.Shutdown()
 	}()
 
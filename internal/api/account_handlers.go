@@ -0,0 +1,187 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/export"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+var (
+	errAccountExportNotFound = apperrors.NewNotFoundError("Account export not found", "ACCOUNT_EXPORT_NOT_FOUND", "")
+	errErasureNotFound       = apperrors.NewNotFoundError("No account erasure requested", "ERASURE_NOT_FOUND", "")
+	errErasureInProgress     = apperrors.NewConflictError("Account erasure already in progress", "ERASURE_IN_PROGRESS", "Check its progress at GET /api/v1/me/erasure.")
+)
+
+// HandleCreateAccountExport starts an export of everything stored about the
+// user as a zip archive: their profile, recipes and images, import history,
+// favorites, shares, webhooks and activity.
+func (s *Server) HandleCreateAccountExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	exp, err := s.db.CreateAccountExport(r.Context(), parseUUID(userID))
+	if err != nil {
+		slog.Error("Failed to create account export", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to create account export"))
+		return
+	}
+	exportID := uuid.UUID(exp.ID.Bytes).String()
+	middleware.SetAuditAction(r.Context(), "account.export", "account_export", exportID)
+
+	task, err := worker.NewExportAccountTask(worker.ExportAccountPayload{ExportID: exportID})
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(accountExportResponse(exp))
+}
+
+func (s *Server) HandleGetAccountExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	exportID, err := uuid.Parse(chi.URLParam(r, "exportID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_EXPORT_ID", "Invalid export ID"))
+		return
+	}
+
+	exp, err := s.db.GetAccountExport(r.Context(), parseUUID(exportID.String()))
+	if err != nil || uuid.UUID(exp.UserID.Bytes).String() != userID {
+		writeError(w, r, errAccountExportNotFound)
+		return
+	}
+
+	response := accountExportResponse(exp)
+	if exp.Status == worker.AccountRequestCompleted && exp.StoragePath.Valid && s.exports != nil {
+		downloadURL, err := s.exports.CreateSignedURL(r.Context(), export.Bucket, exp.StoragePath.String, exportURLExpiry)
+		if err != nil {
+			slog.Error("Failed to sign account export URL", "error", err, "export_id", response.ID)
+			writeError(w, r, internalError("Failed to create download link"))
+			return
+		}
+		response.DownloadURL = downloadURL
+		response.ExpiresAt = time.Now().Add(exportURLExpiry).Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleEraseAccount starts erasing the user's data in the background. It
+// cannot be undone: recipes, the images no one else uses, exports, import
+// history, favorites, shares, webhooks and plan are deleted and the profile
+// is cleared. The audit log is kept.
+func (s *Server) HandleEraseAccount(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	latest, err := s.db.GetLatestAccountErasure(r.Context(), parseUUID(userID))
+	if err == nil && (latest.Status == worker.AccountRequestQueued || latest.Status == worker.AccountRequestExecuting) {
+		writeError(w, r, errErasureInProgress)
+		return
+	}
+
+	erasure, err := s.db.CreateAccountErasure(r.Context(), parseUUID(userID))
+	if err != nil {
+		// Another request started an erasure since the check above
+		if isUniqueViolation(err) {
+			writeError(w, r, errErasureInProgress)
+			return
+		}
+		slog.Error("Failed to create account erasure", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to create account erasure"))
+		return
+	}
+	erasureID := uuid.UUID(erasure.ID.Bytes).String()
+	middleware.SetAuditAction(r.Context(), "account.erase", "account_erasure", erasureID)
+
+	task, err := worker.NewEraseAccountTask(worker.EraseAccountPayload{ErasureID: erasureID})
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(accountErasureResponse(erasure))
+}
+
+// HandleGetAccountErasure returns the user's most recent erasure, which is
+// kept as the record that it was carried out.
+func (s *Server) HandleGetAccountErasure(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	erasure, err := s.db.GetLatestAccountErasure(r.Context(), parseUUID(userID))
+	if err != nil {
+		writeError(w, r, errErasureNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(accountErasureResponse(erasure))
+}
+
+func accountExportResponse(exp generated.AccountExport) AccountExportResponse {
+	response := AccountExportResponse{
+		ID:        uuid.UUID(exp.ID.Bytes).String(),
+		Status:    exp.Status,
+		Error:     apperrors.ParseDetail(exp.Error),
+		CreatedAt: exp.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if exp.CompletedAt.Valid {
+		response.CompletedAt = exp.CompletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
+
+func accountErasureResponse(erasure generated.AccountErasure) AccountErasureResponse {
+	response := AccountErasureResponse{
+		ID:        uuid.UUID(erasure.ID.Bytes).String(),
+		Status:    erasure.Status,
+		Error:     apperrors.ParseDetail(erasure.Error),
+		CreatedAt: erasure.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if len(erasure.Summary) > 0 {
+		json.Unmarshal(erasure.Summary, &response.Summary)
+	}
+	if erasure.CompletedAt.Valid {
+		response.CompletedAt = erasure.CompletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
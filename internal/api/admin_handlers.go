@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/openai"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// errBackfillQueued is returned when a backfill with the same options is
+// already queued.
+var errBackfillQueued = apperrors.NewConflictError("An embedding backfill with these options is already queued", "EMBEDDING_BACKFILL_QUEUED", "Check its progress at GET /api/v1/admin/embeddings/backfill.")
+
+// EmbeddingBackfillRequest optionally overrides the backfill task defaults.
+type EmbeddingBackfillRequest struct {
+	BatchSize         int `json:"batch_size,omitempty"`
+	MaxBatches        int `json:"max_batches,omitempty"`
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+}
+
+// HandleTriggerEmbeddingBackfill enqueues an embedding backfill run.
+func (s *Server) HandleTriggerEmbeddingBackfill(w http.ResponseWriter, r *http.Request) {
+	middleware.SetAuditAction(r.Context(), "embedding_backfill.start", "", "")
+
+	var req EmbeddingBackfillRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, r, errInvalidBody)
+			return
+		}
+	}
+	if req.BatchSize < 0 || req.MaxBatches < 0 || req.RequestsPerMinute < 0 {
+		writeError(w, r, invalidRequest("INVALID_BACKFILL_OPTIONS", "batch_size, max_batches and requests_per_minute must not be negative"))
+		return
+	}
+
+	task, err := worker.NewBackfillEmbeddingsTask(worker.BackfillEmbeddingsPayload{
+		BatchSize:         req.BatchSize,
+		MaxBatches:        req.MaxBatches,
+		RequestsPerMinute: req.RequestsPerMinute,
+	})
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	info, err := s.asynqClient.Enqueue(task)
+	if errors.Is(err, asynq.ErrDuplicateTask) {
+		writeError(w, r, errBackfillQueued)
+		return
+	}
+	if err != nil {
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	progress, err := s.embeddingBackfillProgress(r)
+	if err != nil {
+		slog.Error("Failed to get embedding backfill progress", "error", err)
+	}
+
+	slog.Info("Embedding backfill triggered", "task_id", info.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(EmbeddingBackfillResponse{
+		TaskID:   info.ID,
+		Status:   "queued",
+		Progress: progress,
+	})
+}
+
+// HandleEmbeddingBackfillStatus reports embedding backfill progress.
+func (s *Server) HandleEmbeddingBackfillStatus(w http.ResponseWriter, r *http.Request) {
+	progress, err := s.embeddingBackfillProgress(r)
+	if err != nil {
+		slog.Error("Failed to get embedding backfill progress", "error", err)
+		writeError(w, r, internalError("Failed to get backfill progress"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(progress)
+}
+
+func (s *Server) embeddingBackfillProgress(r *http.Request) (EmbeddingBackfillProgress, error) {
+	progress := EmbeddingBackfillProgress{
+		Model:   openai.EmbeddingModel,
+		Version: worker.EmbeddingVersion,
+	}
+
+	stats, err := s.db.GetEmbeddingBackfillStats(r.Context(), generated.GetEmbeddingBackfillStatsParams{
+		Column1: openai.EmbeddingModel,
+		Column2: worker.EmbeddingVersion,
+	})
+	if err != nil {
+		return progress, err
+	}
+
+	progress.Total = stats.Total
+	progress.Missing = stats.Missing
+	progress.Outdated = stats.Outdated
+	progress.UpToDate = stats.Total - stats.Missing - stats.Outdated
+	progress.PercentComplete = 100
+	if stats.Total > 0 {
+		progress.PercentComplete = float64(progress.UpToDate) / float64(stats.Total) * 100
+	}
+	return progress, nil
+}
+
+type SetUserPlanRequest struct {
+	Plan string `json:"plan"`
+}
+
+// HandleSetUserPlan moves a user to another plan tier, which sets their
+// import quotas.
+func (s *Server) HandleSetUserPlan(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(chi.URLParam(r, "userID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_USER_ID", "Invalid user ID"))
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "user_plan.set", "user", userID.String())
+
+	var req SetUserPlanRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+	if req.Plan == "" {
+		writeError(w, r, invalidRequest("MISSING_PLAN", "plan is required"))
+		return
+	}
+
+	if _, err := s.db.GetPlanQuota(r.Context(), req.Plan); err != nil {
+		writeError(w, r, invalidRequest("UNKNOWN_PLAN", fmt.Sprintf("Unknown plan %q", req.Plan)))
+		return
+	}
+
+	plan, err := s.db.SetUserPlan(r.Context(), generated.SetUserPlanParams{
+		UserID: parseUUID(userID.String()),
+		Plan:   req.Plan,
+	})
+	if err != nil {
+		slog.Error("Failed to set user plan", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to set user plan"))
+		return
+	}
+
+	slog.Info("User plan changed", "user_id", userID, "plan", plan.Plan)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(UserPlanResponse{
+		UserID:    userID.String(),
+		Plan:      plan.Plan,
+		UpdatedAt: plan.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	})
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hibiken/asynq"
+	"github.com/socialchef/remy/internal/config"
+	apperrors "github.com/socialchef/remy/internal/errors"
+)
+
+type fakeInspector struct {
+	queues map[string]*asynq.QueueInfo
+	dead   map[string][]*asynq.TaskInfo
+	ran    []string
+}
+
+func (f *fakeInspector) Queues() ([]string, error) {
+	var names []string
+	for name := range f.queues {
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+func (f *fakeInspector) GetQueueInfo(queue string) (*asynq.QueueInfo, error) {
+	info, ok := f.queues[queue]
+	if !ok {
+		return nil, asynq.ErrQueueNotFound
+	}
+	return info, nil
+}
+
+func (f *fakeInspector) ListArchivedTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error) {
+	if _, ok := f.queues[queue]; !ok {
+		return nil, asynq.ErrQueueNotFound
+	}
+	return f.dead[queue], nil
+}
+
+func (f *fakeInspector) RunTask(queue, id string) error {
+	for _, task := range f.dead[queue] {
+		if task.ID == id {
+			f.ran = append(f.ran, id)
+			return nil
+		}
+	}
+	return asynq.ErrTaskNotFound
+}
+
+func (f *fakeInspector) DeleteTask(queue, id string) error {
+	return f.RunTask(queue, id)
+}
+
+func withURLParams(r *http.Request, params map[string]string) *http.Request {
+	rctx := chi.NewRouteContext()
+	for k, v := range params {
+		rctx.URLParams.Add(k, v)
+	}
+	return r.WithContext(context.WithValue(r.Context(), chi.RouteCtxKey, rctx))
+}
+
+func decodeErrorCode(t *testing.T, rr *httptest.ResponseRecorder) string {
+	t.Helper()
+	var body apperrors.Response
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("expected JSON error body: %v", err)
+	}
+	return body.Error.Code
+}
+
+func newTestInspector() *fakeInspector {
+	return &fakeInspector{
+		queues: map[string]*asynq.QueueInfo{
+			"default": {Queue: "default", Size: 3, Pending: 2, Active: 1, Archived: 1, Processed: 40, Failed: 2, Latency: 1500 * time.Millisecond},
+		},
+		dead: map[string][]*asynq.TaskInfo{
+			"default": {{
+				ID:           "task-1",
+				Type:         "process:recipe",
+				Payload:      []byte(`{"job_id":"job-1"}`),
+				LastErr:      "scrape failed",
+				LastFailedAt: time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC),
+				Retried:      25,
+				MaxRetry:     25,
+			}},
+		},
+	}
+}
+
+func TestHandleAdminListQueues(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetQueueInspector(newTestInspector())
+
+	rr := httptest.NewRecorder()
+	srv.HandleAdminListQueues(rr, httptest.NewRequest("GET", "/api/admin/queues", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rr.Code)
+	}
+	var response QueuesResponse
+	if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if len(response.Queues) != 1 {
+		t.Fatalf("expected 1 queue, got %d", len(response.Queues))
+	}
+	q := response.Queues[0]
+	if q.Queue != "default" || q.Pending != 2 || q.FailedToday != 2 || q.LatencySeconds != 1.5 {
+		t.Errorf("unexpected queue stats %+v", q)
+	}
+}
+
+func TestHandleAdminListQueues_NoInspector(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	rr := httptest.NewRecorder()
+	srv.HandleAdminListQueues(rr, httptest.NewRequest("GET", "/api/admin/queues", nil))
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status 503, got %d", rr.Code)
+	}
+}
+
+func TestHandleAdminListDeadTasks(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetQueueInspector(newTestInspector())
+
+	tests := []struct {
+		name           string
+		queue          string
+		query          string
+		expectedStatus int
+		expectedCode   string
+	}{
+		{name: "Lists archived tasks", queue: "default", expectedStatus: http.StatusOK},
+		{name: "Unknown queue", queue: "missing", expectedStatus: http.StatusNotFound, expectedCode: "QUEUE_NOT_FOUND"},
+		{name: "Invalid page size", queue: "default", query: "?page_size=1000", expectedStatus: http.StatusBadRequest, expectedCode: "INVALID_PAGE_SIZE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := withURLParams(httptest.NewRequest("GET", "/api/admin/queues/"+tt.queue+"/dead"+tt.query, nil), map[string]string{"queue": tt.queue})
+			rr := httptest.NewRecorder()
+			srv.HandleAdminListDeadTasks(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Fatalf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if tt.expectedCode != "" {
+				if code := decodeErrorCode(t, rr); code != tt.expectedCode {
+					t.Errorf("expected code %s, got %s", tt.expectedCode, code)
+				}
+				return
+			}
+
+			var response DeadTasksResponse
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if len(response.Tasks) != 1 || response.Tasks[0].LastError != "scrape failed" || string(response.Tasks[0].Payload) != `{"job_id":"job-1"}` {
+				t.Errorf("unexpected dead tasks %+v", response.Tasks)
+			}
+		})
+	}
+}
+
+func TestHandleAdminRunDeadTask(t *testing.T) {
+	inspector := newTestInspector()
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetQueueInspector(inspector)
+
+	req := withURLParams(httptest.NewRequest("POST", "/", nil), map[string]string{"queue": "default", "taskID": "task-1"})
+	rr := httptest.NewRecorder()
+	srv.HandleAdminRunDeadTask(rr, req)
+	if rr.Code != http.StatusAccepted {
+		t.Errorf("expected status 202, got %d", rr.Code)
+	}
+	if len(inspector.ran) != 1 {
+		t.Errorf("expected the task to be run")
+	}
+
+	req = withURLParams(httptest.NewRequest("POST", "/", nil), map[string]string{"queue": "default", "taskID": "task-2"})
+	rr = httptest.NewRecorder()
+	srv.HandleAdminRunDeadTask(rr, req)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rr.Code)
+	}
+}
+
+func TestHandleAdminRunMaintenance_UnknownTask(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	req := withURLParams(httptest.NewRequest("POST", "/", nil), map[string]string{"task": "reindex"})
+	rr := httptest.NewRecorder()
+	srv.HandleAdminRunMaintenance(rr, req)
+
+	if rr.Code != http.StatusNotFound {
+		t.Fatalf("expected status 404, got %d", rr.Code)
+	}
+	if code := decodeErrorCode(t, rr); code != "MAINTENANCE_TASK_NOT_FOUND" {
+		t.Errorf("expected code MAINTENANCE_TASK_NOT_FOUND, got %s", code)
+	}
+}
+
+func TestHandleAdminListImportJobs_InvalidFilters(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	tests := []struct {
+		query        string
+		expectedCode string
+	}{
+		{"?status=DONE", "INVALID_STATUS"},
+		{"?user_id=nope", "INVALID_USER_ID"},
+		{"?before=yesterday", "INVALID_BEFORE"},
+		{"?limit=0", "INVALID_LIMIT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			srv.HandleAdminListImportJobs(rr, httptest.NewRequest("GET", "/api/admin/jobs"+tt.query, nil))
+
+			if rr.Code != http.StatusBadRequest {
+				t.Fatalf("expected status 400, got %d", rr.Code)
+			}
+			if code := decodeErrorCode(t, rr); code != tt.expectedCode {
+				t.Errorf("expected code %s, got %s", tt.expectedCode, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,355 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+const (
+	defaultListLimit      = 50
+	maxListLimit          = 200
+	defaultJobStatsWindow = 24 * time.Hour
+)
+
+// importJobStatuses are the statuses of recipe_import_jobs.
+var importJobStatuses = []string{"QUEUED", "EXECUTING", "COMPLETED", "FAILED", "CRASHED", "TIMED_OUT", "CANCELED"}
+
+// retryableJobStatuses are the import job statuses an operator may retry.
+var retryableJobStatuses = []string{"FAILED", "CRASHED", "TIMED_OUT", "CANCELED"}
+
+// HandleAdminListImportJobs lists import jobs across users, newest first.
+// Filters: status, user_id, origin and bulk_job_id; pages with before and
+// limit.
+func (s *Server) HandleAdminListImportJobs(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := generated.ListImportJobsParams{
+		Origin:    optionalText(query.Get("origin")),
+		BulkJobID: optionalText(query.Get("bulk_job_id")),
+	}
+
+	var appErr *apperrors.AppError
+	if params.Status, appErr = parseJobStatusFilter(query.Get("status"), importJobStatuses); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.UserID, appErr = parseUserIDFilter(query.Get("user_id")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Before, appErr = parseBeforeFilter(query.Get("before")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Limit, appErr = parseListLimit(query.Get("limit")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+
+	jobs, err := s.db.ListImportJobs(r.Context(), params)
+	if err != nil {
+		slog.Error("Failed to list import jobs", "error", err)
+		writeError(w, r, internalError("Failed to list import jobs"))
+		return
+	}
+
+	response := AdminImportJobsResponse{Jobs: make([]AdminImportJob, len(jobs))}
+	for i, job := range jobs {
+		response.Jobs[i] = adminImportJob(job)
+	}
+	if len(jobs) == int(params.Limit) {
+		response.NextBefore = jobs[len(jobs)-1].CreatedAt.Time.Format(time.RFC3339Nano)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAdminListBulkImports lists bulk imports across users, newest first.
+// Filters: status and user_id; pages with before and limit.
+func (s *Server) HandleAdminListBulkImports(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var params generated.ListBulkImportJobsParams
+
+	var appErr *apperrors.AppError
+	if params.Status, appErr = parseJobStatusFilter(query.Get("status"), []string{"QUEUED", "EXECUTING", "COMPLETED", "FAILED", "CANCELED"}); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.UserID, appErr = parseUserIDFilter(query.Get("user_id")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Before, appErr = parseBeforeFilter(query.Get("before")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Limit, appErr = parseListLimit(query.Get("limit")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+
+	jobs, err := s.db.ListBulkImportJobs(r.Context(), params)
+	if err != nil {
+		slog.Error("Failed to list bulk import jobs", "error", err)
+		writeError(w, r, internalError("Failed to list bulk imports"))
+		return
+	}
+
+	response := AdminBulkImportsResponse{BulkImports: make([]AdminBulkImport, len(jobs))}
+	for i, job := range jobs {
+		response.BulkImports[i] = AdminBulkImport{
+			BulkJobID:      job.JobID,
+			UserID:         uuid.UUID(job.UserID.Bytes).String(),
+			Status:         job.Status,
+			TotalURLs:      int(job.TotalUrls),
+			ProcessedCount: int(job.ProcessedCount.Int32),
+			SuccessCount:   int(job.SuccessCount.Int32),
+			FailedCount:    int(job.FailedCount.Int32),
+			CreatedAt:      job.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+			UpdatedAt:      job.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+		}
+	}
+	if len(jobs) == int(params.Limit) {
+		response.NextBefore = jobs[len(jobs)-1].CreatedAt.Time.Format(time.RFC3339Nano)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAdminImportJobStats counts import outcomes per origin over a window
+// (since, a duration such as 24h; default 24h), showing which providers are
+// failing.
+func (s *Server) HandleAdminImportJobStats(w http.ResponseWriter, r *http.Request) {
+	window := defaultJobStatsWindow
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			writeError(w, r, invalidRequest("INVALID_SINCE", "since must be a positive duration such as 24h"))
+			return
+		}
+		window = d
+	}
+	since := time.Now().Add(-window)
+
+	rows, err := s.db.CountImportJobsByOrigin(r.Context(), pgtype.Timestamptz{Time: since, Valid: true})
+	if err != nil {
+		slog.Error("Failed to count import jobs", "error", err)
+		writeError(w, r, internalError("Failed to count import jobs"))
+		return
+	}
+
+	response := AdminJobStatsResponse{
+		Since:   since.UTC().Format("2006-01-02T15:04:05Z07:00"),
+		Origins: []OriginJobStats{},
+	}
+	for _, row := range rows {
+		n := len(response.Origins)
+		if n == 0 || response.Origins[n-1].Origin != row.Origin {
+			response.Origins = append(response.Origins, OriginJobStats{Origin: row.Origin, ByStatus: map[string]int64{}})
+			n++
+		}
+		stats := &response.Origins[n-1]
+		stats.ByStatus[row.Status] = row.Count
+		stats.Total += row.Count
+		switch row.Status {
+		case "COMPLETED":
+			stats.Completed += row.Count
+		case "FAILED", "CRASHED", "TIMED_OUT":
+			stats.Failed += row.Count
+		case "QUEUED", "EXECUTING":
+			stats.Pending += row.Count
+		}
+	}
+	for i := range response.Origins {
+		stats := &response.Origins[i]
+		if finished := stats.Completed + stats.Failed; finished > 0 {
+			stats.FailureRate = float64(stats.Failed) / float64(finished)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAdminRetryImportJob queues a failed, crashed, timed out or canceled
+// URL import again under the same job ID. Manual and file imports cannot be
+// retried: their input is not stored with the job.
+func (s *Server) HandleAdminRetryImportJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	middleware.SetAuditAction(r.Context(), "import.retry", "import_job", jobID)
+	job, err := s.db.GetImportJobByJobID(r.Context(), jobID)
+	if err != nil {
+		writeError(w, r, errImportJobNotFound)
+		return
+	}
+
+	if !slices.Contains(retryableJobStatuses, job.Status) {
+		writeError(w, r, apperrors.NewConflictError(fmt.Sprintf("Cannot retry job with status: %s", job.Status), "JOB_NOT_RETRYABLE", "Only failed, crashed, timed out or canceled jobs can be retried."))
+		return
+	}
+	if job.Origin == "manual" || job.Origin == "file" {
+		writeError(w, r, apperrors.NewConflictError("Manual and file imports cannot be retried", "JOB_NOT_RETRYABLE", "Ask the user to import the recipe again."))
+		return
+	}
+
+	userID := uuid.UUID(job.UserID.Bytes).String()
+
+	// The bulk import already counted this job's result, so the retry is not
+	// linked to it
+	task, err := worker.NewProcessRecipeTask(worker.ProcessRecipePayload{
+		JobID:  job.JobID,
+		URL:    job.Url,
+		UserID: userID,
+	})
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if err := s.db.UpdateImportJobStatus(r.Context(), generated.UpdateImportJobStatusParams{
+		JobID:        job.JobID,
+		Status:       "QUEUED",
+		ProgressStep: pgtype.Text{String: "Retrying", Valid: true},
+	}); err != nil {
+		slog.Error("Failed to reset import job", "error", err, "job_id", job.JobID)
+		writeError(w, r, internalError("Failed to reset import job"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		slog.Error("Failed to enqueue import retry", "error", err, "job_id", job.JobID)
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	slog.Info("Import job retried", "job_id", job.JobID, "user_id", userID, "admin", middleware.GetAdmin(r.Context()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(AdminJobActionResponse{JobID: job.JobID, Status: "QUEUED"})
+}
+
+// HandleAdminCancelImportJob cancels a queued or running import job. Queued
+// jobs are skipped by the worker; a job already running is marked canceled
+// but its current run is not interrupted.
+func (s *Server) HandleAdminCancelImportJob(w http.ResponseWriter, r *http.Request) {
+	jobID := chi.URLParam(r, "jobID")
+	middleware.SetAuditAction(r.Context(), "import.cancel", "import_job", jobID)
+	job, err := s.db.GetImportJobByJobID(r.Context(), jobID)
+	if err != nil {
+		writeError(w, r, errImportJobNotFound)
+		return
+	}
+
+	if job.Status != "QUEUED" && job.Status != "EXECUTING" {
+		writeError(w, r, apperrors.NewConflictError(fmt.Sprintf("Cannot cancel job with status: %s", job.Status), "JOB_NOT_CANCELABLE", "Only queued or running jobs can be canceled."))
+		return
+	}
+
+	canceled := apperrors.NewCanceledError("Import canceled by an operator", "CANCELED_BY_OPERATOR")
+	if err := s.db.UpdateImportJobStatus(r.Context(), generated.UpdateImportJobStatusParams{
+		JobID:        job.JobID,
+		Status:       "CANCELED",
+		ProgressStep: pgtype.Text{String: "Canceled", Valid: true},
+		Error:        canceled.JSON(),
+	}); err != nil {
+		slog.Error("Failed to cancel import job", "error", err, "job_id", job.JobID)
+		writeError(w, r, internalError("Failed to cancel job"))
+		return
+	}
+
+	userID := uuid.UUID(job.UserID.Bytes).String()
+	if s.progress != nil {
+		if err := s.progress.Broadcast(userID, worker.ProgressUpdate{
+			JobID:   job.JobID,
+			Status:  "canceled",
+			Message: canceled.Message,
+		}); err != nil {
+			slog.Warn("Failed to broadcast import cancellation", "error", err, "job_id", job.JobID)
+		}
+	}
+
+	slog.Info("Import job canceled", "job_id", job.JobID, "user_id", userID, "admin", middleware.GetAdmin(r.Context()))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AdminJobActionResponse{JobID: job.JobID, Status: "CANCELED"})
+}
+
+func adminImportJob(job generated.RecipeImportJob) AdminImportJob {
+	return AdminImportJob{
+		ID:           uuid.UUID(job.ID.Bytes).String(),
+		JobID:        job.JobID,
+		UserID:       uuid.UUID(job.UserID.Bytes).String(),
+		URL:          job.Url,
+		Origin:       job.Origin,
+		Status:       job.Status,
+		ProgressStep: job.ProgressStep.String,
+		BulkJobID:    job.BulkJobID.String,
+		Error:        apperrors.ParseDetail(job.Error),
+		CreatedAt:    job.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+		UpdatedAt:    job.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func optionalText(s string) pgtype.Text {
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+func parseJobStatusFilter(v string, statuses []string) (pgtype.Text, *apperrors.AppError) {
+	if v == "" {
+		return pgtype.Text{}, nil
+	}
+	status := strings.ToUpper(v)
+	if !slices.Contains(statuses, status) {
+		return pgtype.Text{}, invalidRequest("INVALID_STATUS", "status must be one of "+strings.Join(statuses, ", "))
+	}
+	return pgtype.Text{String: status, Valid: true}, nil
+}
+
+func parseUserIDFilter(v string) (pgtype.UUID, *apperrors.AppError) {
+	if v == "" {
+		return pgtype.UUID{}, nil
+	}
+	id, err := uuid.Parse(v)
+	if err != nil {
+		return pgtype.UUID{}, invalidRequest("INVALID_USER_ID", "Invalid user ID")
+	}
+	return pgtype.UUID{Bytes: id, Valid: true}, nil
+}
+
+func parseBeforeFilter(v string) (pgtype.Timestamptz, *apperrors.AppError) {
+	if v == "" {
+		return pgtype.Timestamptz{}, nil
+	}
+	before, err := time.Parse(time.RFC3339Nano, v)
+	if err != nil {
+		return pgtype.Timestamptz{}, invalidRequest("INVALID_BEFORE", "before must be an RFC 3339 timestamp")
+	}
+	return pgtype.Timestamptz{Time: before, Valid: true}, nil
+}
+
+func parseListLimit(v string) (int32, *apperrors.AppError) {
+	if v == "" {
+		return defaultListLimit, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 || n > maxListLimit {
+		return 0, invalidRequest("INVALID_LIMIT", fmt.Sprintf("limit must be between 1 and %d", maxListLimit))
+	}
+	return int32(n), nil
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/hibiken/asynq"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+const (
+	defaultDeadTasksPageSize = 50
+	maxDeadTasksPageSize     = 200
+)
+
+// QueueInspector reads and manages the task queues; *asynq.Inspector
+// satisfies it.
+type QueueInspector interface {
+	Queues() ([]string, error)
+	GetQueueInfo(queue string) (*asynq.QueueInfo, error)
+	ListArchivedTasks(queue string, opts ...asynq.ListOption) ([]*asynq.TaskInfo, error)
+	RunTask(queue, id string) error
+	DeleteTask(queue, id string) error
+}
+
+// SetQueueInspector enables the admin queue routes.
+func (s *Server) SetQueueInspector(inspector QueueInspector) {
+	s.inspector = inspector
+}
+
+// maintenanceTasks are the tasks operators can start from the admin API.
+var maintenanceTasks = map[string]func() (*asynq.Task, error){
+	"cleanup-jobs": func() (*asynq.Task, error) {
+		return worker.NewCleanupJobsTask(), nil
+	},
+	"storage-gc": func() (*asynq.Task, error) {
+		return worker.NewGarbageCollectStorageTask(worker.GarbageCollectStoragePayload{})
+	},
+	"embedding-backfill": func() (*asynq.Task, error) {
+		return worker.NewBackfillEmbeddingsTask(worker.BackfillEmbeddingsPayload{})
+	},
+}
+
+// HandleAdminListQueues reports the depth and daily throughput of every
+// task queue.
+func (s *Server) HandleAdminListQueues(w http.ResponseWriter, r *http.Request) {
+	if s.inspector == nil {
+		writeError(w, r, errQueuesUnavailable)
+		return
+	}
+
+	queues, err := s.inspector.Queues()
+	if err != nil {
+		slog.Error("Failed to list queues", "error", err)
+		writeError(w, r, internalError("Failed to list queues"))
+		return
+	}
+
+	response := QueuesResponse{Queues: make([]QueueStats, 0, len(queues))}
+	for _, queue := range queues {
+		info, err := s.inspector.GetQueueInfo(queue)
+		if err != nil {
+			slog.Error("Failed to get queue info", "error", err, "queue", queue)
+			writeError(w, r, internalError("Failed to get queue info"))
+			return
+		}
+		response.Queues = append(response.Queues, QueueStats{
+			Queue:          info.Queue,
+			Paused:         info.Paused,
+			Size:           info.Size,
+			Pending:        info.Pending,
+			Active:         info.Active,
+			Scheduled:      info.Scheduled,
+			Retry:          info.Retry,
+			Archived:       info.Archived,
+			Completed:      info.Completed,
+			ProcessedToday: info.Processed,
+			FailedToday:    info.Failed,
+			LatencySeconds: info.Latency.Seconds(),
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAdminListDeadTasks lists a queue's archived tasks, paged with page
+// and page_size.
+func (s *Server) HandleAdminListDeadTasks(w http.ResponseWriter, r *http.Request) {
+	if s.inspector == nil {
+		writeError(w, r, errQueuesUnavailable)
+		return
+	}
+
+	queue := chi.URLParam(r, "queue")
+	page, pageSize := 1, defaultDeadTasksPageSize
+	if v := r.URL.Query().Get("page"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			writeError(w, r, invalidRequest("INVALID_PAGE", "page must be a positive number"))
+			return
+		}
+		page = n
+	}
+	if v := r.URL.Query().Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxDeadTasksPageSize {
+			writeError(w, r, invalidRequest("INVALID_PAGE_SIZE", fmt.Sprintf("page_size must be between 1 and %d", maxDeadTasksPageSize)))
+			return
+		}
+		pageSize = n
+	}
+
+	tasks, err := s.inspector.ListArchivedTasks(queue, asynq.Page(page), asynq.PageSize(pageSize))
+	if err != nil {
+		writeError(w, r, queueError(err, "Failed to list dead tasks"))
+		return
+	}
+
+	response := DeadTasksResponse{Queue: queue, Tasks: make([]DeadTask, len(tasks))}
+	for i, task := range tasks {
+		dead := DeadTask{
+			ID:        task.ID,
+			Type:      task.Type,
+			LastError: task.LastErr,
+			Retried:   task.Retried,
+			MaxRetry:  task.MaxRetry,
+		}
+		if json.Valid(task.Payload) {
+			dead.Payload = task.Payload
+		}
+		if !task.LastFailedAt.IsZero() {
+			dead.LastFailedAt = task.LastFailedAt.Format("2006-01-02T15:04:05Z07:00")
+		}
+		response.Tasks[i] = dead
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleAdminRunDeadTask moves an archived task back to pending.
+func (s *Server) HandleAdminRunDeadTask(w http.ResponseWriter, r *http.Request) {
+	if s.inspector == nil {
+		writeError(w, r, errQueuesUnavailable)
+		return
+	}
+
+	queue, taskID := chi.URLParam(r, "queue"), chi.URLParam(r, "taskID")
+	middleware.SetAuditAction(r.Context(), "task.run", "task", taskID)
+	if err := s.inspector.RunTask(queue, taskID); err != nil {
+		writeError(w, r, queueError(err, "Failed to run task"))
+		return
+	}
+
+	slog.Info("Dead task requeued", "queue", queue, "task_id", taskID, "admin", middleware.GetAdmin(r.Context()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{
+		"task_id": taskID,
+		"status":  "pending",
+	})
+}
+
+// HandleAdminDeleteDeadTask deletes an archived task.
+func (s *Server) HandleAdminDeleteDeadTask(w http.ResponseWriter, r *http.Request) {
+	if s.inspector == nil {
+		writeError(w, r, errQueuesUnavailable)
+		return
+	}
+
+	queue, taskID := chi.URLParam(r, "queue"), chi.URLParam(r, "taskID")
+	middleware.SetAuditAction(r.Context(), "task.delete", "task", taskID)
+	if err := s.inspector.DeleteTask(queue, taskID); err != nil {
+		writeError(w, r, queueError(err, "Failed to delete task"))
+		return
+	}
+
+	slog.Info("Dead task deleted", "queue", queue, "task_id", taskID, "admin", middleware.GetAdmin(r.Context()))
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleAdminRunMaintenance enqueues a maintenance task now instead of
+// waiting for its schedule.
+func (s *Server) HandleAdminRunMaintenance(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "task")
+	middleware.SetAuditAction(r.Context(), "maintenance.run", "maintenance_task", name)
+	newTask, ok := maintenanceTasks[name]
+	if !ok {
+		writeError(w, r, apperrors.NewNotFoundError(fmt.Sprintf("Unknown maintenance task %q", name), "MAINTENANCE_TASK_NOT_FOUND", "Use cleanup-jobs, storage-gc or embedding-backfill."))
+		return
+	}
+
+	task, err := newTask()
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	info, err := s.asynqClient.Enqueue(task)
+	if errors.Is(err, asynq.ErrDuplicateTask) {
+		writeError(w, r, apperrors.NewConflictError(fmt.Sprintf("Maintenance task %q is already queued", name), "MAINTENANCE_TASK_QUEUED", "Wait for the queued run to finish."))
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to enqueue maintenance task", "error", err, "task", name)
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	slog.Info("Maintenance task triggered", "task", name, "task_id", info.ID, "admin", middleware.GetAdmin(r.Context()))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(MaintenanceTaskResponse{
+		Task:   name,
+		TaskID: info.ID,
+		Status: "queued",
+	})
+}
+
+// queueError maps inspector errors to API errors.
+func queueError(err error, message string) *apperrors.AppError {
+	switch {
+	case errors.Is(err, asynq.ErrQueueNotFound):
+		return errQueueNotFound
+	case errors.Is(err, asynq.ErrTaskNotFound):
+		return errTaskNotFound
+	}
+	slog.Error(message, "error", err)
+	return internalError(message)
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+)
+
+// HandleListActivity lists what was done as the user, by them or by a
+// service acting for them, newest first. Pages with before and limit.
+func (s *Server) HandleListActivity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	params := generated.ListUserAuditEventsParams{UserID: parseUUID(userID)}
+	var appErr *apperrors.AppError
+	if params.Before, appErr = parseBeforeFilter(r.URL.Query().Get("before")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Limit, appErr = parseListLimit(r.URL.Query().Get("limit")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+
+	events, err := s.db.ListUserAuditEvents(r.Context(), params)
+	if err != nil {
+		slog.Error("Failed to list activity", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to list activity"))
+		return
+	}
+
+	writeAuditEvents(w, events, params.Limit)
+}
+
+// HandleAdminListAuditEvents lists the audit log across users, newest
+// first. Filters: user_id, actor, action, resource_type and resource_id;
+// pages with before and limit.
+func (s *Server) HandleAdminListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	params := generated.ListAuditEventsParams{
+		Actor:        optionalText(query.Get("actor")),
+		Action:       optionalText(query.Get("action")),
+		ResourceType: optionalText(query.Get("resource_type")),
+		ResourceID:   optionalText(query.Get("resource_id")),
+	}
+
+	var appErr *apperrors.AppError
+	if params.UserID, appErr = parseUserIDFilter(query.Get("user_id")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Before, appErr = parseBeforeFilter(query.Get("before")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+	if params.Limit, appErr = parseListLimit(query.Get("limit")); appErr != nil {
+		writeError(w, r, appErr)
+		return
+	}
+
+	events, err := s.db.ListAuditEvents(r.Context(), params)
+	if err != nil {
+		slog.Error("Failed to list audit events", "error", err)
+		writeError(w, r, internalError("Failed to list audit events"))
+		return
+	}
+
+	writeAuditEvents(w, events, params.Limit)
+}
+
+func writeAuditEvents(w http.ResponseWriter, events []generated.AuditEvent, limit int32) {
+	response := AuditEventsResponse{Events: make([]AuditEventResponse, len(events))}
+	for i, event := range events {
+		response.Events[i] = auditEventResponse(event)
+	}
+	if len(events) == int(limit) {
+		response.NextBefore = events[len(events)-1].CreatedAt.Time.Format(time.RFC3339Nano)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func auditEventResponse(event generated.AuditEvent) AuditEventResponse {
+	response := AuditEventResponse{
+		ID:           uuid.UUID(event.ID.Bytes).String(),
+		Actor:        event.Actor,
+		Impersonator: event.Impersonator.String,
+		Action:       event.Action,
+		ResourceType: event.ResourceType.String,
+		ResourceID:   event.ResourceID.String,
+		Method:       event.Method,
+		Path:         event.Path,
+		RequestID:    event.RequestID.String,
+		Outcome:      event.Outcome,
+		StatusCode:   event.StatusCode,
+		CreatedAt:    event.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if event.UserID.Valid {
+		response.UserID = uuid.UUID(event.UserID.Bytes).String()
+	}
+	return response
+}
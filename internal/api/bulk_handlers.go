@@ -2,6 +2,7 @@ package api
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -9,8 +10,10 @@ import (
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
 	"github.com/socialchef/remy/internal/middleware"
 	"github.com/socialchef/remy/internal/worker"
 )
@@ -25,90 +28,69 @@ type BulkImportRecipeRequest struct {
 	URLs []string `json:"urls"`
 }
 
-type BulkImportRecipeResponse struct {
-	BulkJobID string `json:"bulk_job_id"`
-	TotalURLs int    `json:"total_urls"`
-	Status    string `json:"status"`
-}
-
-type BulkImportResultItem struct {
-	URL      string `json:"url"`
-	Status   string `json:"status"`
-	RecipeID string `json:"recipe_id,omitempty"`
-	Error    string `json:"error,omitempty"`
-}
-
-type BulkImportStatusResponse struct {
-	BulkJobID string                 `json:"bulk_job_id"`
-	Status    string                 `json:"status"`
-	Progress  BulkImportProgress     `json:"progress"`
-	Results   []BulkImportResultItem `json:"results,omitempty"`
-	CreatedAt string                 `json:"created_at"`
-	UpdatedAt string                 `json:"updated_at"`
-}
-
-type BulkImportProgress struct {
-	Total     int `json:"total"`
-	Processed int `json:"processed"`
-	Success   int `json:"success"`
-	Failed    int `json:"failed"`
-}
-
-type UserBulkImportSummary struct {
-	BulkJobID    string `json:"bulk_job_id"`
-	Status       string `json:"status"`
-	TotalURLs    int    `json:"total_urls"`
-	SuccessCount int    `json:"success_count"`
-	FailedCount  int    `json:"failed_count"`
-	CreatedAt    string `json:"created_at"`
-}
-
-type UserBulkImportsResponse struct {
-	Jobs []UserBulkImportSummary `json:"jobs"`
-}
-
 func (s *Server) HandleBulkImportRecipe(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
+	idem, ok := s.beginIdempotent(w, r, userID, "bulk-import")
+	if !ok {
+		return
+	}
+	defer idem.finish()
+	w = idem
+
 	var req BulkImportRecipeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidBody)
 		return
 	}
 
 	if len(req.URLs) == 0 {
-		http.Error(w, "At least one URL is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_URLS", "At least one URL is required"))
 		return
 	}
 
 	if len(req.URLs) > MaxURLsPerBulkImport {
-		http.Error(w, fmt.Sprintf("Maximum %d URLs allowed per bulk import", MaxURLsPerBulkImport), http.StatusBadRequest)
+		writeError(w, r, invalidRequest("TOO_MANY_URLS", fmt.Sprintf("Maximum %d URLs allowed per bulk import", MaxURLsPerBulkImport)))
+		return
+	}
+
+	dedupedURLs := deduplicateURLs(req.URLs)
+	if len(dedupedURLs) == 0 {
+		writeError(w, r, invalidRequest("NO_VALID_URLS", "No valid URLs provided"))
 		return
 	}
 
+	if idem.key != "" {
+		// A retry whose stored response is gone finds its job here, before
+		// it counts against the concurrency limit and the quota
+		if job, err := s.db.GetBulkImportJobByJobID(r.Context(), idem.jobID); err == nil {
+			s.resumeBulkImportJob(w, r, job, userID, dedupedURLs)
+			return
+		}
+	}
+
 	activeCount, err := s.db.GetUserActiveBulkImportCount(r.Context(), parseUUID(userID))
 	if err != nil {
 		slog.Error("Failed to check active bulk import count", "error", err, "user_id", userID)
-		http.Error(w, "Failed to check rate limit", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to check rate limit"))
 		return
 	}
 
 	if activeCount >= MaxConcurrentBulkJobs {
-		http.Error(w, fmt.Sprintf("Maximum %d concurrent bulk imports allowed. Please wait for existing imports to complete.", MaxConcurrentBulkJobs), http.StatusTooManyRequests)
+		writeError(w, r, errTooManyBulkJobs)
 		return
 	}
 
-	dedupedURLs := deduplicateURLs(req.URLs)
-	if len(dedupedURLs) == 0 {
-		http.Error(w, "No valid URLs provided", http.StatusBadRequest)
+	if !s.checkImportQuota(w, r, userID, len(dedupedURLs)) {
 		return
 	}
 
-	bulkJobID := uuid.New().String()
+	bulkJobID := idem.jobID
+	middleware.SetAuditAction(r.Context(), "bulk_import.create", "bulk_import", bulkJobID)
 	id := uuid.New().String()
 
 	_, err = s.db.CreateBulkImportJob(r.Context(), generated.CreateBulkImportJobParams{
@@ -118,9 +100,20 @@ func (s *Server) HandleBulkImportRecipe(w http.ResponseWriter, r *http.Request)
 		TotalUrls: int32(len(dedupedURLs)),
 		Status:    "QUEUED",
 	})
+	if isUniqueViolation(err) {
+		// A concurrent retry created the job for this Idempotency-Key first
+		job, err := s.db.GetBulkImportJobByJobID(r.Context(), bulkJobID)
+		if err != nil {
+			slog.Error("Failed to load existing bulk import job", "error", err, "bulk_job_id", bulkJobID)
+			writeError(w, r, internalError("Failed to create bulk import job"))
+			return
+		}
+		s.resumeBulkImportJob(w, r, job, userID, dedupedURLs)
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to create bulk import job", "error", err, "user_id", userID, "bulk_job_id", bulkJobID)
-		http.Error(w, "Failed to create bulk import job", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to create bulk import job"))
 		return
 	}
 
@@ -131,13 +124,13 @@ func (s *Server) HandleBulkImportRecipe(w http.ResponseWriter, r *http.Request)
 	})
 	if err != nil {
 		slog.Error("Failed to create bulk import task", "error", err, "bulk_job_id", bulkJobID)
-		http.Error(w, "Failed to create task", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to create task"))
 		return
 	}
 
-	if _, err := s.asynqClient.Enqueue(task, worker.Queue(BulkImportQueue)); err != nil {
+	if _, err := s.asynqClient.Enqueue(task, worker.Queue(BulkImportQueue), asynq.TaskID(bulkJobID)); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
 		slog.Error("Failed to enqueue bulk import task", "error", err, "bulk_job_id", bulkJobID)
-		http.Error(w, "Failed to enqueue task", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to enqueue task"))
 		return
 	}
 
@@ -150,27 +143,63 @@ func (s *Server) HandleBulkImportRecipe(w http.ResponseWriter, r *http.Request)
 	})
 }
 
+// resumeBulkImportJob answers a retried Idempotency-Key whose bulk job
+// already exists with the response of the request that created it,
+// enqueueing its task again while the job is still QUEUED.
+func (s *Server) resumeBulkImportJob(w http.ResponseWriter, r *http.Request, job generated.BulkImportJob, userID string, urls []string) {
+	if job.UserID.Bytes != parseUUID(userID).Bytes || int(job.TotalUrls) != len(urls) {
+		writeError(w, r, errIdempotencyKeyReused)
+		return
+	}
+
+	if job.Status == "QUEUED" {
+		task, err := worker.NewProcessBulkImportTask(worker.ProcessBulkImportPayload{
+			BulkJobID: job.JobID,
+			URLs:      urls,
+			UserID:    userID,
+		})
+		if err != nil {
+			slog.Error("Failed to create bulk import task", "error", err, "bulk_job_id", job.JobID)
+			writeError(w, r, internalError("Failed to create task"))
+			return
+		}
+		if _, err := s.asynqClient.Enqueue(task, worker.Queue(BulkImportQueue), asynq.TaskID(job.JobID)); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			slog.Error("Failed to enqueue bulk import task", "error", err, "bulk_job_id", job.JobID)
+			writeError(w, r, internalError("Failed to enqueue task"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(BulkImportRecipeResponse{
+		BulkJobID: job.JobID,
+		TotalURLs: int(job.TotalUrls),
+		Status:    "QUEUED",
+	})
+}
+
 func (s *Server) HandleBulkImportStatus(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	bulkJobID := chi.URLParam(r, "bulkJobID")
 	if bulkJobID == "" {
-		http.Error(w, "bulkJobID is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_BULK_JOB_ID", "bulkJobID is required"))
 		return
 	}
 
 	job, err := s.db.GetBulkImportJobByJobID(r.Context(), bulkJobID)
 	if err != nil {
-		http.Error(w, "Bulk import job not found", http.StatusNotFound)
+		writeError(w, r, errBulkJobNotFound)
 		return
 	}
 
 	if job.UserID.Bytes != parseUUID(userID).Bytes {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
@@ -202,10 +231,13 @@ func (s *Server) HandleBulkImportStatus(w http.ResponseWriter, r *http.Request)
 						if recipeID, ok := result["recipe_id"].(string); ok {
 							item.RecipeID = recipeID
 						}
+						if name, ok := result["name"].(string); ok {
+							item.Name = name
+						}
 					}
 				}
 				if ij.Error != nil {
-					item.Error = string(ij.Error)
+					item.Error = apperrors.ParseDetail(ij.Error)
 				}
 				response.Results[i] = item
 			}
@@ -219,14 +251,14 @@ func (s *Server) HandleBulkImportStatus(w http.ResponseWriter, r *http.Request)
 func (s *Server) HandleListUserBulkImports(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	jobs, err := s.db.GetBulkImportJobsByUser(r.Context(), parseUUID(userID))
 	if err != nil {
 		slog.Error("Failed to fetch bulk import jobs", "error", err, "user_id", userID)
-		http.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to fetch jobs"))
 		return
 	}
 
@@ -252,38 +284,55 @@ func (s *Server) HandleListUserBulkImports(w http.ResponseWriter, r *http.Reques
 func (s *Server) HandleCancelBulkImport(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	bulkJobID := chi.URLParam(r, "bulkJobID")
 	if bulkJobID == "" {
-		http.Error(w, "bulkJobID is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_BULK_JOB_ID", "bulkJobID is required"))
 		return
 	}
+	middleware.SetAuditAction(r.Context(), "bulk_import.cancel", "bulk_import", bulkJobID)
 
 	job, err := s.db.GetBulkImportJobByJobID(r.Context(), bulkJobID)
 	if err != nil {
-		http.Error(w, "Bulk import job not found", http.StatusNotFound)
+		writeError(w, r, errBulkJobNotFound)
 		return
 	}
 
 	if job.UserID.Bytes != parseUUID(userID).Bytes {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	if job.Status != "QUEUED" && job.Status != "EXECUTING" {
-		http.Error(w, fmt.Sprintf("Cannot cancel job with status: %s", job.Status), http.StatusBadRequest)
+		writeError(w, r, apperrors.NewConflictError(fmt.Sprintf("Cannot cancel job with status: %s", job.Status), "JOB_NOT_CANCELABLE", "Only queued or running bulk imports can be canceled.").WithStatus(http.StatusBadRequest))
 		return
 	}
 
 	if err := s.db.CancelBulkImportJob(r.Context(), bulkJobID); err != nil {
 		slog.Error("Failed to cancel bulk import job", "error", err, "bulk_job_id", bulkJobID)
-		http.Error(w, "Failed to cancel job", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to cancel job"))
 		return
 	}
 
+	if s.progress != nil {
+		if err := s.progress.Broadcast(userID, worker.ProgressUpdate{
+			BulkJobID: bulkJobID,
+			Status:    "CANCELED",
+			Message:   "Bulk import canceled",
+			Progress: &worker.BulkProgress{
+				Total:     int(job.TotalUrls),
+				Processed: int(job.ProcessedCount.Int32),
+				Success:   int(job.SuccessCount.Int32),
+				Failed:    int(job.FailedCount.Int32),
+			},
+		}); err != nil {
+			slog.Warn("Failed to broadcast bulk import cancellation", "error", err, "bulk_job_id", bulkJobID)
+		}
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{
 		"bulk_job_id": bulkJobID,
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+)
+
+// Errors shared by many handlers
+var (
+	errUnauthorized      = apperrors.NewUnauthorizedError("Unauthorized", "UNAUTHORIZED", "Sign in again and retry the request.")
+	errInvalidBody       = apperrors.NewValidationError("Invalid request body", "INVALID_REQUEST_BODY", "Send a JSON body with the documented fields.")
+	errInvalidMultipart  = apperrors.NewValidationError("Invalid multipart form", "INVALID_MULTIPART_FORM", "Send the upload as multipart/form-data.")
+	errRecipeNotFound    = apperrors.NewNotFoundError("Recipe not found", "RECIPE_NOT_FOUND", "")
+	errBulkJobNotFound   = apperrors.NewNotFoundError("Bulk import job not found", "BULK_IMPORT_NOT_FOUND", "")
+	errShareLinkNotFound = apperrors.NewNotFoundError("Share link not found", "SHARE_LINK_NOT_FOUND", "")
+	errWebhookNotFound   = apperrors.NewNotFoundError("Webhook not found", "WEBHOOK_NOT_FOUND", "")
+	errRevisionNotFound  = apperrors.NewNotFoundError("Revision not found", "REVISION_NOT_FOUND", "")
+	errImportJobNotFound = apperrors.NewNotFoundError("Job not found", "JOB_NOT_FOUND", "")
+	errQueueNotFound     = apperrors.NewNotFoundError("Queue not found", "QUEUE_NOT_FOUND", "")
+	errTaskNotFound      = apperrors.NewNotFoundError("Task not found", "TASK_NOT_FOUND", "")
+	errQueuesUnavailable = apperrors.NewUnavailableError("Queue inspection is not configured", "QUEUES_UNAVAILABLE", "")
+	errTooManyBulkJobs   = apperrors.NewRateLimitError(
+		fmt.Sprintf("Maximum %d concurrent bulk imports allowed", MaxConcurrentBulkJobs),
+		"TOO_MANY_BULK_IMPORTS",
+		"Wait for your running bulk imports to complete, or cancel one.",
+	)
+)
+
+// writeError responds with err as the JSON error envelope.
+func writeError(w http.ResponseWriter, r *http.Request, err *apperrors.AppError) {
+	middleware.WriteError(w, r, err)
+}
+
+// invalidRequest is a 400 for a request that fails validation.
+func invalidRequest(code, message string) *apperrors.AppError {
+	return apperrors.NewValidationError(message, code, "")
+}
+
+// internalError is a 500 whose message says what failed without the cause,
+// which handlers log instead.
+func internalError(message string) *apperrors.AppError {
+	return apperrors.NewInternalError(message, "INTERNAL_ERROR", nil)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/export"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// exportURLExpiry is how long a bulk export download link stays valid.
+const exportURLExpiry = time.Hour
+
+type CreateRecipeExportRequest struct {
+	Format string `json:"format,omitempty"`
+}
+
+// HandleExportRecipe returns a recipe in the format given by the format
+// query parameter: jsonld (the default), paprika, mealie, markdown or html.
+func (s *Server) HandleExportRecipe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	format, err := export.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_EXPORT_FORMAT", err.Error()))
+		return
+	}
+
+	existing, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+	recipeID := uuid.UUID(existing.ID.Bytes).String()
+
+	rec, err := export.NewLoader(s.db, s.recipeImageURL).Load(r.Context(), existing.ID)
+	if err != nil {
+		slog.Error("Failed to load recipe for export", "error", err, "recipe_id", recipeID)
+		writeError(w, r, internalError("Failed to load recipe"))
+		return
+	}
+
+	doc, err := export.Render(format, rec)
+	if err != nil {
+		slog.Error("Failed to export recipe", "error", err, "recipe_id", recipeID, "format", format)
+		writeError(w, r, internalError("Failed to export recipe"))
+		return
+	}
+
+	// The HTML card opens in the browser for printing; the rest download
+	disposition := "attachment"
+	if format == export.FormatHTML {
+		disposition = "inline"
+	}
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("%s; filename=%q", disposition, export.Slug(rec.Name)+doc.Extension))
+	w.Write(doc.Data)
+}
+
+// HandleCreateRecipeExport starts a bulk export of all of the user's recipes
+// as a zip archive.
+func (s *Server) HandleCreateRecipeExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	var req CreateRecipeExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+	format, err := export.ParseFormat(req.Format)
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_EXPORT_FORMAT", err.Error()))
+		return
+	}
+
+	exp, err := s.db.CreateRecipeExport(r.Context(), generated.CreateRecipeExportParams{
+		UserID: parseUUID(userID),
+		Format: string(format),
+	})
+	if err != nil {
+		slog.Error("Failed to create export", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to create export"))
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "export.create", "recipe_export", uuid.UUID(exp.ID.Bytes).String())
+
+	task, err := worker.NewExportRecipesTask(worker.ExportRecipesPayload{
+		ExportID: uuid.UUID(exp.ID.Bytes).String(),
+	})
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(recipeExportResponse(exp))
+}
+
+func (s *Server) HandleGetRecipeExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	exportID, err := uuid.Parse(chi.URLParam(r, "exportID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_EXPORT_ID", "Invalid export ID"))
+		return
+	}
+
+	exp, err := s.db.GetRecipeExport(r.Context(), parseUUID(exportID.String()))
+	if err != nil || uuid.UUID(exp.UserID.Bytes).String() != userID {
+		writeError(w, r, apperrors.NewNotFoundError("Export not found", "EXPORT_NOT_FOUND", ""))
+		return
+	}
+
+	response := recipeExportResponse(exp)
+	if exp.Status == "COMPLETED" && exp.StoragePath.Valid && s.exports != nil {
+		downloadURL, err := s.exports.CreateSignedURL(r.Context(), export.Bucket, exp.StoragePath.String, exportURLExpiry)
+		if err != nil {
+			slog.Error("Failed to sign export URL", "error", err, "export_id", response.ID)
+			writeError(w, r, internalError("Failed to create download link"))
+			return
+		}
+		response.DownloadURL = downloadURL
+		response.ExpiresAt = time.Now().Add(exportURLExpiry).Format("2006-01-02T15:04:05Z07:00")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func recipeExportResponse(exp generated.RecipeExport) RecipeExportResponse {
+	response := RecipeExportResponse{
+		ID:          uuid.UUID(exp.ID.Bytes).String(),
+		Format:      exp.Format,
+		Status:      exp.Status,
+		RecipeCount: exp.RecipeCount,
+		Error:       apperrors.ParseDetail(exp.Error),
+		CreatedAt:   exp.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if exp.CompletedAt.Valid {
+		response.CompletedAt = exp.CompletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
+
+// recipeImageURL returns the public URL of an image in the recipes bucket.
+func (s *Server) recipeImageURL(storagePath string) string {
+	return fmt.Sprintf("%s/storage/v1/object/public/recipes/%s", s.cfg.SupabaseURL, storagePath)
+}
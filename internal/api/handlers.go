@@ -1,19 +1,26 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/socialchef/remy/internal/config"
 	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
 	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/idempotency"
 	"github.com/socialchef/remy/internal/services/search"
 	"github.com/socialchef/remy/internal/worker"
 )
@@ -23,6 +30,54 @@ type Server struct {
 	db          *generated.Queries
 	asynqClient *asynq.Client
 	search      *search.Client
+	tx          TxBeginner
+	images      ImageUploader
+	exports     ExportStore
+	imports     ImportStore
+	progress    ProgressStream
+	inspector   QueueInspector
+	idempotency IdempotencyStore
+
+	// streamsClosed ends open event streams when the server shuts down
+	streamsClosed chan struct{}
+	closeStreams  sync.Once
+}
+
+// TxBeginner starts database transactions; *pgxpool.Pool satisfies it.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+}
+
+// ImageUploader stores uploaded images; *storage.Client satisfies it.
+type ImageUploader interface {
+	UploadImageWithHash(ctx context.Context, bucket, path, sourceURL string, data []byte) (string, error)
+}
+
+// ExportStore hands out download links for export archives; *storage.Client
+// satisfies it.
+type ExportStore interface {
+	CreateSignedURL(ctx context.Context, bucket, path string, expiresIn time.Duration) (string, error)
+}
+
+// ImportStore keeps uploaded export files until the worker imports them;
+// *storage.Client satisfies it.
+type ImportStore interface {
+	UploadObject(ctx context.Context, bucket, path string, data []byte, contentType string) error
+}
+
+// ProgressStream publishes and streams import progress events;
+// *worker.ProgressStream satisfies it.
+type ProgressStream interface {
+	Broadcast(userID string, update worker.ProgressUpdate) error
+	Subscribe(ctx context.Context, userID, lastEventID string) (<-chan worker.ProgressEvent, error)
+}
+
+// IdempotencyStore remembers responses to requests sent with an
+// Idempotency-Key; *idempotency.Store satisfies it.
+type IdempotencyStore interface {
+	Begin(ctx context.Context, key, fingerprint string) (*idempotency.Response, error)
+	Complete(ctx context.Context, key string, resp idempotency.Response) error
+	Release(ctx context.Context, key string) error
 }
 
 func NewServer(cfg *config.Config, db *generated.Queries, asynqClient *asynq.Client, searchClient *search.Client) *Server {
@@ -31,7 +86,67 @@ func NewServer(cfg *config.Config, db *generated.Queries, asynqClient *asynq.Cli
 		db:          db,
 		asynqClient: asynqClient,
 		search:      searchClient,
+
+		streamsClosed: make(chan struct{}),
+	}
+}
+
+// SetTxBeginner enables transactions for handlers that write several rows.
+// Without it those writes run directly against the queries connection.
+func (s *Server) SetTxBeginner(tx TxBeginner) {
+	s.tx = tx
+}
+
+// SetImageUploader enables recipe creation from uploaded photos.
+func (s *Server) SetImageUploader(images ImageUploader) {
+	s.images = images
+}
+
+// SetExportStore enables download links for bulk recipe exports.
+func (s *Server) SetExportStore(exports ExportStore) {
+	s.exports = exports
+}
+
+// SetImportStore enables bulk imports from recipe manager export files.
+func (s *Server) SetImportStore(imports ImportStore) {
+	s.imports = imports
+}
+
+// SetProgressStream enables the server-sent events progress stream.
+func (s *Server) SetProgressStream(progress ProgressStream) {
+	s.progress = progress
+}
+
+// SetIdempotencyStore enables replaying responses to import requests
+// repeated with the same Idempotency-Key. Without it repeats are still
+// refused rather than queueing a second job.
+func (s *Server) SetIdempotencyStore(store IdempotencyStore) {
+	s.idempotency = store
+}
+
+// CloseStreams ends open progress streams, which would otherwise hold up a
+// graceful shutdown. Clients reconnect to another instance and resume from
+// the last event they saw.
+func (s *Server) CloseStreams() {
+	s.closeStreams.Do(func() { close(s.streamsClosed) })
+}
+
+// withTx runs fn with queries bound to a transaction, committing if fn
+// succeeds.
+func (s *Server) withTx(ctx context.Context, fn func(q *generated.Queries) error) error {
+	if s.tx == nil {
+		return fn(s.db)
+	}
+	tx, err := s.tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.db.WithTx(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
 }
 
 func parseUUID(s string) pgtype.UUID {
@@ -46,26 +161,41 @@ type ImportRecipeRequest struct {
 	URL string `json:"url"`
 }
 
-type ImportRecipeResponse struct {
-	JobID string `json:"job_id"`
-	URL   string `json:"url"`
-}
-
 func (s *Server) HandleImportRecipe(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
+	idem, ok := s.beginIdempotent(w, r, userID, "import")
+	if !ok {
+		return
+	}
+	defer idem.finish()
+	w = idem
+
 	var req ImportRecipeRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidBody)
 		return
 	}
 
 	if req.URL == "" {
-		http.Error(w, "URL is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_URL", "URL is required"))
+		return
+	}
+
+	if idem.key != "" {
+		// A retry whose stored response is gone (Redis was down or the key
+		// expired) finds its job here, before it counts against the quota
+		if job, err := s.db.GetImportJobByJobID(r.Context(), idem.jobID); err == nil {
+			s.resumeImportJob(w, r, job, userID, req.URL)
+			return
+		}
+	}
+
+	if !s.checkImportQuota(w, r, userID, 1) {
 		return
 	}
 
@@ -77,7 +207,8 @@ func (s *Server) HandleImportRecipe(w http.ResponseWriter, r *http.Request) {
 
 	// Generate separate IDs: database ID and job/task ID
 	id := uuid.New().String()
-	jobID := uuid.New().String()
+	jobID := idem.jobID
+	middleware.SetAuditAction(r.Context(), "import.create", "import_job", jobID)
 
 	_, err := s.db.CreateImportJob(r.Context(), generated.CreateImportJobParams{
 		ID:     parseUUID(id),
@@ -87,9 +218,20 @@ func (s *Server) HandleImportRecipe(w http.ResponseWriter, r *http.Request) {
 		Origin: origin,
 		Status: "QUEUED",
 	})
+	if isUniqueViolation(err) {
+		// A concurrent retry created the job for this Idempotency-Key first
+		job, err := s.db.GetImportJobByJobID(r.Context(), jobID)
+		if err != nil {
+			slog.Error("Failed to load existing import job", "error", err, "job_id", jobID)
+			writeError(w, r, internalError("Failed to create import job"))
+			return
+		}
+		s.resumeImportJob(w, r, job, userID, req.URL)
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to create import job", "error", err, "user_id", userID, "job_id", jobID)
-		http.Error(w, "Failed to create import job", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to create import job"))
 		return
 	}
 
@@ -99,12 +241,12 @@ func (s *Server) HandleImportRecipe(w http.ResponseWriter, r *http.Request) {
 		UserID: userID,
 	})
 	if err != nil {
-		http.Error(w, "Failed to create task", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to create task"))
 		return
 	}
 
-	if _, err := s.asynqClient.Enqueue(task); err != nil {
-		http.Error(w, "Failed to enqueue task", http.StatusInternalServerError)
+	if _, err := s.asynqClient.Enqueue(task, asynq.TaskID(jobID)); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+		writeError(w, r, internalError("Failed to enqueue task"))
 		return
 	}
 
@@ -116,36 +258,60 @@ func (s *Server) HandleImportRecipe(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-type JobStatusResponse struct {
-	ID           string `json:"id"`
-	Status       string `json:"status"`
-	ProgressStep string `json:"progress_step,omitempty"`
-	Error        string `json:"error,omitempty"`
-	CreatedAt    string `json:"created_at"`
-	UpdatedAt    string `json:"updated_at"`
+// resumeImportJob answers a retried Idempotency-Key whose job already exists
+// with the response of the request that created it. A job still QUEUED may
+// never have been enqueued, so its task is enqueued again under the same ID.
+func (s *Server) resumeImportJob(w http.ResponseWriter, r *http.Request, job generated.RecipeImportJob, userID, url string) {
+	if job.UserID.Bytes != parseUUID(userID).Bytes || job.Url != url {
+		writeError(w, r, errIdempotencyKeyReused)
+		return
+	}
+
+	if job.Status == "QUEUED" {
+		task, err := worker.NewProcessRecipeTask(worker.ProcessRecipePayload{
+			JobID:  job.JobID,
+			URL:    job.Url,
+			UserID: userID,
+		})
+		if err != nil {
+			writeError(w, r, internalError("Failed to create task"))
+			return
+		}
+		if _, err := s.asynqClient.Enqueue(task, asynq.TaskID(job.JobID)); err != nil && !errors.Is(err, asynq.ErrTaskIDConflict) {
+			writeError(w, r, internalError("Failed to enqueue task"))
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(ImportRecipeResponse{
+		JobID: job.JobID,
+		URL:   job.Url,
+	})
 }
 
 func (s *Server) HandleJobStatus(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
-	jobID := r.URL.Query().Get("job_id")
+	jobID := pathOrQuery(r, "jobID", "job_id")
 	if jobID == "" {
-		http.Error(w, "job_id is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_JOB_ID", "job_id is required"))
 		return
 	}
 
 	job, err := s.db.GetImportJobByJobID(r.Context(), jobID)
 	if err != nil {
-		http.Error(w, "Job not found", http.StatusNotFound)
+		writeError(w, r, apperrors.NewNotFoundError("Job not found", "JOB_NOT_FOUND", "Check the job_id returned when the import was started."))
 		return
 	}
 
 	if job.UserID.Bytes != parseUUID(userID).Bytes {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
@@ -154,26 +320,22 @@ func (s *Server) HandleJobStatus(w http.ResponseWriter, r *http.Request) {
 		ID:           uuid.UUID(job.ID.Bytes).String(),
 		Status:       job.Status,
 		ProgressStep: job.ProgressStep.String,
-		Error:        string(job.Error),
+		Error:        apperrors.ParseDetail(job.Error),
 		CreatedAt:    job.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:    job.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 	})
 }
 
-type UserImportStatusResponse struct {
-	Jobs []JobStatusResponse `json:"jobs"`
-}
-
 func (s *Server) HandleUserImportStatus(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	jobs, err := s.db.GetImportJobsByUser(r.Context(), parseUUID(userID))
 	if err != nil {
-		http.Error(w, "Failed to fetch jobs", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to fetch jobs"))
 		return
 	}
 
@@ -186,7 +348,7 @@ func (s *Server) HandleUserImportStatus(w http.ResponseWriter, r *http.Request)
 			ID:           uuid.UUID(job.ID.Bytes).String(),
 			Status:       job.Status,
 			ProgressStep: job.ProgressStep.String,
-			Error:        string(job.Error),
+			Error:        apperrors.ParseDetail(job.Error),
 			CreatedAt:    job.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 			UpdatedAt:    job.UpdatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
 		}
@@ -203,12 +365,12 @@ type GenerateEmbeddingRequest struct {
 func (s *Server) HandleGenerateEmbedding(w http.ResponseWriter, r *http.Request) {
 	var req GenerateEmbeddingRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidBody)
 		return
 	}
 
 	if req.RecipeID == "" {
-		http.Error(w, "recipe_id is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_RECIPE_ID", "recipe_id is required"))
 		return
 	}
 
@@ -216,12 +378,12 @@ func (s *Server) HandleGenerateEmbedding(w http.ResponseWriter, r *http.Request)
 		RecipeID: req.RecipeID,
 	})
 	if err != nil {
-		http.Error(w, "Failed to create task", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to create task"))
 		return
 	}
 
 	if _, err := s.asynqClient.Enqueue(task); err != nil {
-		http.Error(w, "Failed to enqueue task", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to enqueue task"))
 		return
 	}
 
@@ -230,27 +392,23 @@ func (s *Server) HandleGenerateEmbedding(w http.ResponseWriter, r *http.Request)
 	json.NewEncoder(w).Encode(map[string]string{"status": "queued"})
 }
 
-type InstructionIngredientsCountResponse struct {
-	Count int `json:"count"`
-}
-
 func (s *Server) HandleGetInstructionIngredientsCount(w http.ResponseWriter, r *http.Request) {
 	_, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
-	recipeID := r.URL.Query().Get("recipe_id")
+	recipeID := pathOrQuery(r, "recipeID", "recipe_id")
 	if recipeID == "" {
-		http.Error(w, "recipe_id is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_RECIPE_ID", "recipe_id is required"))
 		return
 	}
 
 	ingredients, err := s.db.GetInstructionIngredientsByRecipe(r.Context(), parseUUID(recipeID))
 	if err != nil {
 		slog.Error("Failed to get instruction ingredients", "error", err, "recipe_id", recipeID)
-		http.Error(w, "Failed to get instruction ingredients", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to get instruction ingredients"))
 		return
 	}
 
@@ -260,125 +418,34 @@ func (s *Server) HandleGetInstructionIngredientsCount(w http.ResponseWriter, r *
 	})
 }
 
-type Timer struct {
-	DurationSeconds int    `json:"duration_seconds"`
-	DurationText    string `json:"duration_text"`
-	Label           string `json:"label"`
-	Type            string `json:"type"`
-	Category        string `json:"category"`
-}
-
-type StepIngredientDetail struct {
-	ID            string `json:"id"`
-	Name          string `json:"name"`
-	StepQuantity  string `json:"step_quantity"`  // from instruction_ingredients
-	TotalQuantity string `json:"total_quantity"` // from recipe_ingredients
-	Unit          string `json:"unit"`           // from recipe_ingredients
-}
-
-type StepDetail struct {
-	StepNumber      int32                  `json:"step_number"`
-	Instruction     string                 `json:"instruction"`
-	InstructionRich string                 `json:"instruction_rich"`
-	Ingredients     []StepIngredientDetail `json:"ingredients"`
-	Timers          []Timer                `json:"timers"`
-}
-
-type RecipeStepsResponse struct {
-	RecipeID   string       `json:"recipe_id"`
-	TotalSteps int          `json:"total_steps"`
-	Steps      []StepDetail `json:"steps"`
-}
-
-type PartSteps struct {
-	PartID       string       `json:"part_id"`
-	PartName     string       `json:"part_name"`
-	IsOptional   bool         `json:"is_optional"`
-	DisplayOrder int32        `json:"display_order"`
-	Steps        []StepDetail `json:"steps"`
-}
-
-type RecipeStepsWithPartsResponse struct {
-	RecipeID   string       `json:"recipe_id"`
-	TotalSteps int          `json:"total_steps"`
-	HasParts   bool         `json:"has_parts"`
-	Parts      []PartSteps  `json:"parts,omitempty"`
-	Steps      []StepDetail `json:"steps,omitempty"`
-}
-
-type PartIngredient struct {
-	ID               string `json:"id"`
-	Name             string `json:"name"`
-	Quantity         string `json:"quantity,omitempty"`
-	TotalQuantity    string `json:"total_quantity,omitempty"`
-	Unit             string `json:"unit,omitempty"`
-	OriginalQuantity string `json:"original_quantity,omitempty"`
-	OriginalUnit     string `json:"original_unit,omitempty"`
-}
-
-type PartInstruction struct {
-	ID              string  `json:"id"`
-	StepNumber      int32   `json:"step_number"`
-	Instruction     string  `json:"instruction"`
-	InstructionRich string  `json:"instruction_rich,omitempty"`
-	TimerData       []Timer `json:"timers,omitempty"`
-}
-
-type RecipePartDetail struct {
-	ID           string            `json:"id"`
-	Name         string            `json:"name"`
-	Description  string            `json:"description,omitempty"`
-	DisplayOrder int32             `json:"display_order"`
-	IsOptional   bool              `json:"is_optional"`
-	PrepTime     *int32            `json:"prep_time,omitempty"`
-	CookingTime  *int32            `json:"cooking_time,omitempty"`
-	Ingredients  []PartIngredient  `json:"ingredients,omitempty"`
-	Instructions []PartInstruction `json:"instructions,omitempty"`
-}
-
-type RecipeResponse struct {
-	ID                  string             `json:"id"`
-	RecipeName          string             `json:"recipe_name"`
-	Description         string             `json:"description,omitempty"`
-	PrepTime            *int32             `json:"prep_time,omitempty"`
-	CookingTime         *int32             `json:"cooking_time,omitempty"`
-	TotalTime           *int32             `json:"total_time,omitempty"`
-	OriginalServingSize *int32             `json:"original_serving_size,omitempty"`
-	DifficultyRating    *int16             `json:"difficulty_rating,omitempty"`
-	FocusedDiet         string             `json:"focused_diet,omitempty"`
-	EstimatedCalories   *int32             `json:"estimated_calories,omitempty"`
-	Origin              string             `json:"origin"`
-	Url                 string             `json:"url,omitempty"`
-	Language            string             `json:"language,omitempty"`
-	CreatedBy           string             `json:"created_by"`
-	OwnerID             string             `json:"owner_id,omitempty"`
-	ThumbnailID         string             `json:"thumbnail_id,omitempty"`
-	IngredientNames     []string           `json:"ingredient_names,omitempty"`
-	CreatedAt           string             `json:"created_at"`
-	UpdatedAt           string             `json:"updated_at"`
-	Parts               []RecipePartDetail `json:"parts,omitempty"`
-}
-
 func (s *Server) HandleGetRecipe(w http.ResponseWriter, r *http.Request) {
 	_, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	recipeID := chi.URLParam(r, "recipeID")
 	if recipeID == "" {
-		http.Error(w, "recipe_id is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_RECIPE_ID", "recipe_id is required"))
 		return
 	}
 
 	result, err := s.db.GetRecipeWithParts(r.Context(), parseUUID(recipeID))
 	if err != nil {
 		slog.Error("Failed to get recipe", "error", err, "recipe_id", recipeID)
-		http.Error(w, "Recipe not found", http.StatusNotFound)
+		writeError(w, r, errRecipeNotFound)
 		return
 	}
 
+	response := newRecipeResponse(result)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// newRecipeResponse converts a recipe with its parts for the API.
+func newRecipeResponse(result generated.GetRecipeWithPartsRow) RecipeResponse {
 	response := RecipeResponse{
 		ID:              uuid.UUID(result.ID.Bytes).String(),
 		RecipeName:      result.RecipeName,
@@ -423,33 +490,32 @@ func (s *Server) HandleGetRecipe(w http.ResponseWriter, r *http.Request) {
 	if result.Parts != nil {
 		var parts []RecipePartDetail
 		if err := json.Unmarshal(result.Parts.([]byte), &parts); err != nil {
-			slog.Error("Failed to unmarshal parts", "error", err, "recipe_id", recipeID)
+			slog.Error("Failed to unmarshal parts", "error", err, "recipe_id", response.ID)
 		} else {
 			response.Parts = parts
 		}
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(response)
+	return response
 }
 
 func (s *Server) HandleGetRecipeSteps(w http.ResponseWriter, r *http.Request) {
 	_, ok := middleware.GetUserID(r.Context())
 	if !ok {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		writeError(w, r, errUnauthorized)
 		return
 	}
 
 	recipeID := chi.URLParam(r, "recipeID")
 	if recipeID == "" {
-		http.Error(w, "recipe_id is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_RECIPE_ID", "recipe_id is required"))
 		return
 	}
 
 	result, err := s.db.GetRecipeWithParts(r.Context(), parseUUID(recipeID))
 	if err != nil {
 		slog.Error("Failed to get recipe", "error", err, "recipe_id", recipeID)
-		http.Error(w, "Recipe not found", http.StatusNotFound)
+		writeError(w, r, errRecipeNotFound)
 		return
 	}
 
@@ -467,14 +533,14 @@ func (s *Server) handleGetRecipeStepsFlat(w http.ResponseWriter, r *http.Request
 	instructions, err := s.db.GetInstructionsByRecipe(r.Context(), recipeUUID)
 	if err != nil {
 		slog.Error("Failed to get instructions", "error", err, "recipe_id", recipeID)
-		http.Error(w, "Failed to get instructions", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to get instructions"))
 		return
 	}
 
 	recipeIngredients, err := s.db.GetIngredientsByRecipe(r.Context(), recipeUUID)
 	if err != nil {
 		slog.Error("Failed to get recipe ingredients", "error", err, "recipe_id", recipeID)
-		http.Error(w, "Failed to get recipe ingredients", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to get recipe ingredients"))
 		return
 	}
 
@@ -489,7 +555,7 @@ func (s *Server) handleGetRecipeStepsFlat(w http.ResponseWriter, r *http.Request
 		instIngredients, err := s.db.GetInstructionIngredientsByInstruction(r.Context(), inst.ID)
 		if err != nil {
 			slog.Error("Failed to get instruction ingredients", "error", err, "instruction_id", inst.ID)
-			http.Error(w, "Failed to get instruction ingredients", http.StatusInternalServerError)
+			writeError(w, r, internalError("Failed to get instruction ingredients"))
 			return
 		}
 
@@ -570,18 +636,18 @@ func (s *Server) handleGetRecipeStepsWithParts(w http.ResponseWriter, r *http.Re
 		partsJSON, err = json.Marshal(v)
 		if err != nil {
 			slog.Error("Failed to marshal parts", "error", err, "recipe_id", recipeID)
-			http.Error(w, "Failed to process recipe parts", http.StatusInternalServerError)
+			writeError(w, r, internalError("Failed to process recipe parts"))
 			return
 		}
 	default:
 		slog.Error("Unexpected type for parts", "type", fmt.Sprintf("%T", result.Parts), "recipe_id", recipeID)
-		http.Error(w, "Failed to process recipe parts", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to process recipe parts"))
 		return
 	}
 
 	if err := json.Unmarshal(partsJSON, &partsData); err != nil {
 		slog.Error("Failed to unmarshal parts", "error", err, "recipe_id", recipeID)
-		http.Error(w, "Failed to process recipe parts", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to process recipe parts"))
 		return
 	}
 
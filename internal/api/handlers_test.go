@@ -4,13 +4,21 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
 	"github.com/socialchef/remy/internal/config"
+	apperrors "github.com/socialchef/remy/internal/errors"
 	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/idempotency"
+	"github.com/socialchef/remy/internal/services/share"
+	"github.com/socialchef/remy/internal/worker"
 )
 
 func withUserID(ctx context.Context, userID string) context.Context {
@@ -50,6 +58,36 @@ func TestHandleJobStatus_MissingJobID(t *testing.T) {
 	}
 }
 
+func TestErrorResponse_Envelope(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/recipe-status", nil)
+	req.Header.Set(middleware.RequestIDHeader, "req-42")
+	req = req.WithContext(withUserID(req.Context(), uuid.New().String()))
+	rr := httptest.NewRecorder()
+
+	middleware.RequestID(http.HandlerFunc(srv.HandleJobStatus)).ServeHTTP(rr, req)
+
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %q", ct)
+	}
+
+	var body apperrors.Response
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode error body: %v", err)
+	}
+	if body.Error.Code != "MISSING_JOB_ID" {
+		t.Errorf("expected code MISSING_JOB_ID, got %s", body.Error.Code)
+	}
+	if body.Error.Type != apperrors.ErrorTypeValidation {
+		t.Errorf("expected type %s, got %s", apperrors.ErrorTypeValidation, body.Error.Type)
+	}
+	if body.Error.RequestID != "req-42" {
+		t.Errorf("expected request ID req-42, got %s", body.Error.RequestID)
+	}
+}
+
 func TestHandleUserImportStatus_Unauthorized(t *testing.T) {
 	cfg := &config.Config{}
 	srv := NewServer(cfg, nil, nil, nil)
@@ -257,3 +295,677 @@ func TestHandleGetRecipe_DatabaseError(t *testing.T) {
 	}()
 	srv.HandleGetRecipe(rr, req)
 }
+
+func TestHandleTriggerEmbeddingBackfill_InvalidBody(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/embeddings/backfill", bytes.NewReader([]byte("{invalid")))
+	rr := httptest.NewRecorder()
+
+	srv.HandleTriggerEmbeddingBackfill(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleTriggerEmbeddingBackfill_NegativeBatchSize(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	jsonBody, _ := json.Marshal(EmbeddingBackfillRequest{BatchSize: -1})
+	req := httptest.NewRequest("POST", "/api/admin/embeddings/backfill", bytes.NewReader(jsonBody))
+	rr := httptest.NewRecorder()
+
+	srv.HandleTriggerEmbeddingBackfill(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleSearchSemantic_InvalidMode(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	jsonBody, _ := json.Marshal(SearchRequest{Query: "vegan air fryer snack", Mode: "sum"})
+	req := httptest.NewRequest("POST", "/api/v1/search/semantic", bytes.NewReader(jsonBody))
+	rr := httptest.NewRecorder()
+
+	srv.HandleSearchSemantic(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleUpdateRecipe_Unauthorized(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/recipes/test-id", bytes.NewReader([]byte(`{"recipe_name":"Soup"}`)))
+	rr := httptest.NewRecorder()
+
+	srv.HandleUpdateRecipe(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestHandleUpdateRecipe_InvalidBody(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("PATCH", "/api/recipes/test-id", bytes.NewReader([]byte("invalid json")))
+	req = req.WithContext(withUserID(req.Context(), uuid.New().String()))
+	rr := httptest.NewRecorder()
+
+	srv.HandleUpdateRecipe(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleRestoreRecipeRevision_InvalidRevision(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/recipes/test-id/revisions/latest/restore", nil)
+	req = req.WithContext(withUserID(req.Context(), uuid.New().String()))
+	rr := httptest.NewRecorder()
+
+	srv.HandleRestoreRecipeRevision(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleDeleteRecipe_Unauthorized(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("DELETE", "/api/recipes/test-id", nil)
+	rr := httptest.NewRecorder()
+
+	srv.HandleDeleteRecipe(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestHandleRegenerateRecipe_Unauthorized(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/recipes/test-id/regenerate", nil)
+	rr := httptest.NewRecorder()
+
+	srv.HandleRegenerateRecipe(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestHandleRegenerateRecipe_InvalidRequest(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"unknown mode", `{"mode":"merge"}`},
+		{"unknown provider", `{"provider":"mistral"}`},
+		{"unavailable prompt version", `{"prompt_version":99}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/recipes/test-id/regenerate", bytes.NewBufferString(tt.body))
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleRegenerateRecipe(rr, req)
+
+			if rr.Code != http.StatusBadRequest {
+				t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleImportRecipeFromText_Unauthorized(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/recipe/from-text", bytes.NewBufferString(`{"text":"Pancakes"}`))
+	rr := httptest.NewRecorder()
+
+	srv.HandleImportRecipeFromText(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestHandleImportRecipeFromText_InvalidText(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		text     string
+		wantCode int
+	}{
+		{"empty", "   ", http.StatusBadRequest},
+		{"too long", strings.Repeat("a", maxManualTextLength+1), http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(ImportRecipeFromTextRequest{Text: tt.text})
+			req := httptest.NewRequest("POST", "/api/recipe/from-text", bytes.NewBuffer(body))
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleImportRecipeFromText(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleImportRecipeFromImage_InvalidUpload(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		field    string
+		content  []byte
+		wantCode int
+	}{
+		{"missing image", "photo", []byte("\x89PNG\r\n\x1a\n"), http.StatusBadRequest},
+		{"not an image", "image", []byte("just some text"), http.StatusUnsupportedMediaType},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			part, _ := writer.CreateFormFile(tt.field, "recipe.png")
+			part.Write(tt.content)
+			writer.Close()
+
+			req := httptest.NewRequest("POST", "/api/recipe/from-image", &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleImportRecipeFromImage(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleExportRecipe_InvalidFormat(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/recipes/test-id/export?format=pdf", nil)
+	req = req.WithContext(withUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+
+	srv.HandleExportRecipe(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleCreateRecipeExport_Unauthorized(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/exports", bytes.NewBufferString(`{"format":"markdown"}`))
+	rr := httptest.NewRecorder()
+
+	srv.HandleCreateRecipeExport(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
+
+func TestHandleCreateRecipeExport_InvalidFormat(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	req := httptest.NewRequest("POST", "/api/exports", bytes.NewBufferString(`{"format":"pdf"}`))
+	req = req.WithContext(withUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+
+	srv.HandleCreateRecipeExport(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestHandleBulkImportFile_InvalidUpload(t *testing.T) {
+	cfg := &config.Config{}
+	srv := NewServer(cfg, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		format   string
+		field    string
+		content  []byte
+		wantCode int
+	}{
+		{"unknown format", "cookbook", "file", []byte(`{"name":"Soup"}`), http.StatusBadRequest},
+		{"missing file", "", "upload", []byte(`{"name":"Soup"}`), http.StatusBadRequest},
+		{"unreadable file", "", "file", []byte("just some text"), http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body bytes.Buffer
+			writer := multipart.NewWriter(&body)
+			writer.WriteField("format", tt.format)
+			part, _ := writer.CreateFormFile(tt.field, "export.json")
+			part.Write(tt.content)
+			writer.Close()
+
+			req := httptest.NewRequest("POST", "/api/bulk-import/file", &body)
+			req.Header.Set("Content-Type", writer.FormDataContentType())
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleBulkImportFile(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleCreateRecipeShare_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		secret   string
+		body     string
+		wantCode int
+	}{
+		{"expiry too long", "secret", `{"expires_in_days": 31}`, http.StatusBadRequest},
+		{"negative expiry", "secret", `{"expires_in_days": -1}`, http.StatusBadRequest},
+		{"sharing disabled", "", `{}`, http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer(&config.Config{ShareLinkSecret: tt.secret}, nil, nil, nil)
+
+			req := httptest.NewRequest("POST", "/api/recipes/test-id/share", bytes.NewBufferString(tt.body))
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleCreateRecipeShare(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleGetSharedRecipe_InvalidToken(t *testing.T) {
+	srv := NewServer(&config.Config{ShareLinkSecret: "secret"}, nil, nil, nil)
+
+	for _, token := range []string{"not-a-token", share.Sign("other-secret", uuid.New())} {
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("token", token)
+		req := httptest.NewRequest("GET", "/r/"+token, nil)
+		req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+		rr := httptest.NewRecorder()
+
+		srv.HandleGetSharedRecipe(rr, req)
+
+		if rr.Code != http.StatusNotFound {
+			t.Errorf("token %q: expected status %d, got %d", token, http.StatusNotFound, rr.Code)
+		}
+	}
+}
+
+func TestShareURL(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/recipes/test-id/share", nil)
+	req.Host = "api.example.com"
+	req.Header.Set("X-Forwarded-Proto", "https")
+
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	if got := srv.shareURL(req, "tok"); got != "https://api.example.com/r/tok" {
+		t.Errorf("unexpected URL from request: %s", got)
+	}
+
+	srv = NewServer(&config.Config{PublicBaseURL: "https://remy.example.com"}, nil, nil, nil)
+	if got := srv.shareURL(req, "tok"); got != "https://remy.example.com/r/tok" {
+		t.Errorf("unexpected URL from config: %s", got)
+	}
+}
+
+type fakeProgressStream struct {
+	events      []worker.ProgressEvent
+	lastEventID string
+	// keepOpen leaves the stream open after the events, like a live one
+	keepOpen bool
+}
+
+func (f *fakeProgressStream) Broadcast(userID string, update worker.ProgressUpdate) error {
+	return nil
+}
+
+func (f *fakeProgressStream) Subscribe(ctx context.Context, userID, lastEventID string) (<-chan worker.ProgressEvent, error) {
+	f.lastEventID = lastEventID
+	events := make(chan worker.ProgressEvent, len(f.events))
+	for _, event := range f.events {
+		events <- event
+	}
+	if !f.keepOpen {
+		close(events)
+	}
+	return events, nil
+}
+
+func TestHandleImportsStream(t *testing.T) {
+	stream := &fakeProgressStream{events: []worker.ProgressEvent{
+		{ID: "1-0", Update: worker.ProgressUpdate{JobID: "job-1", Status: "EXECUTING", Message: "Scraping"}},
+		{ID: "2-0", Update: worker.ProgressUpdate{BulkJobID: "bulk-1", Status: "COMPLETED", Progress: &worker.BulkProgress{Total: 2, Processed: 2, Success: 2}}},
+	}}
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetProgressStream(stream)
+
+	req := httptest.NewRequest("GET", "/api/imports/stream", nil)
+	req.Header.Set("Last-Event-ID", "0-5")
+	req = req.WithContext(withUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+
+	srv.HandleImportsStream(rr, req)
+
+	if got := rr.Header().Get("Content-Type"); got != "text/event-stream" {
+		t.Errorf("expected an event stream, got %q", got)
+	}
+	if stream.lastEventID != "0-5" {
+		t.Errorf("expected Last-Event-ID to be passed on, got %q", stream.lastEventID)
+	}
+	body := rr.Body.String()
+	for _, want := range []string{
+		"id: 1-0\nevent: progress\ndata: {\"job_id\":\"job-1\",\"status\":\"EXECUTING\",\"message\":\"Scraping\"}\n\n",
+		"id: 2-0\nevent: bulk_progress\n",
+		`"progress":{"total":2,"processed":2,"success":2,"failed":0}`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("expected stream to contain %q, got %q", want, body)
+		}
+	}
+}
+
+func TestHandleImportsStream_ClosedOnShutdown(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetProgressStream(&fakeProgressStream{keepOpen: true})
+	srv.CloseStreams()
+
+	req := httptest.NewRequest("GET", "/api/imports/stream", nil)
+	req = req.WithContext(withUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+
+	done := make(chan struct{})
+	go func() {
+		srv.HandleImportsStream(rr, req)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected the stream to end when the server shuts down")
+	}
+}
+
+func TestHandleImportsStream_Unavailable(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/imports/stream", nil)
+	req = req.WithContext(withUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+
+	srv.HandleImportsStream(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected status %d, got %d", http.StatusServiceUnavailable, rr.Code)
+	}
+}
+
+func TestHandleCreateWebhook_InvalidRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		env      string
+		body     string
+		wantCode int
+	}{
+		{"invalid body", "production", `{`, http.StatusBadRequest},
+		{"relative url", "production", `{"url": "/hooks", "events": ["import.completed"]}`, http.StatusBadRequest},
+		{"plain http", "production", `{"url": "http://example.com/hooks", "events": ["import.completed"]}`, http.StatusBadRequest},
+		{"loopback host", "production", `{"url": "https://localhost/hooks", "events": ["import.completed"]}`, http.StatusBadRequest},
+		{"metadata address", "production", `{"url": "https://169.254.169.254/latest", "events": ["import.completed"]}`, http.StatusBadRequest},
+		{"private ipv6 address", "production", `{"url": "https://[fdaa::3]/hooks", "events": ["import.completed"]}`, http.StatusBadRequest},
+		{"no events", "development", `{"url": "https://example.com/hooks", "events": []}`, http.StatusBadRequest},
+		{"unknown event", "development", `{"url": "http://localhost/hooks", "events": ["recipe.deleted"]}`, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer(&config.Config{Env: tt.env}, nil, nil, nil)
+
+			req := httptest.NewRequest("POST", "/api/webhooks", bytes.NewBufferString(tt.body))
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleCreateWebhook(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Errorf("expected status %d, got %d", tt.wantCode, rr.Code)
+			}
+		})
+	}
+}
+
+func TestHandleWebhooks_Unauthorized(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	handlers := map[string]http.HandlerFunc{
+		"create":     srv.HandleCreateWebhook,
+		"list":       srv.HandleListWebhooks,
+		"delete":     srv.HandleDeleteWebhook,
+		"deliveries": srv.HandleListWebhookDeliveries,
+		"redeliver":  srv.HandleRedeliverWebhook,
+	}
+	for name, handler := range handlers {
+		req := httptest.NewRequest("GET", "/api/webhooks", nil)
+		rr := httptest.NewRecorder()
+
+		handler(rr, req)
+
+		if rr.Code != http.StatusUnauthorized {
+			t.Errorf("%s: expected status %d, got %d", name, http.StatusUnauthorized, rr.Code)
+		}
+	}
+}
+
+func TestHandleRedeliverWebhook_InvalidID(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("webhookID", "55555555-5555-5555-5555-555555555555")
+	rctx.URLParams.Add("deliveryID", "not-a-uuid")
+
+	req := httptest.NewRequest("POST", "/api/webhooks/55555555-5555-5555-5555-555555555555/deliveries/not-a-uuid/redeliver", nil)
+	req = req.WithContext(context.WithValue(withUserID(req.Context(), "user-123"), chi.RouteCtxKey, rctx))
+	rr := httptest.NewRecorder()
+
+	srv.HandleRedeliverWebhook(rr, req)
+
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status %d, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
+
+func TestNormalizeWebhookEvents(t *testing.T) {
+	events, err := normalizeWebhookEvents([]string{"import.completed", "recipe.updated", "import.completed"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 || events[0] != "import.completed" || events[1] != "recipe.updated" {
+		t.Errorf("expected duplicates to be dropped, got %v", events)
+	}
+}
+
+type fakeIdempotencyStore struct {
+	stored   *idempotency.Response
+	err      error
+	key      string
+	released bool
+}
+
+func (f *fakeIdempotencyStore) Begin(ctx context.Context, key, fingerprint string) (*idempotency.Response, error) {
+	f.key = key
+	return f.stored, f.err
+}
+
+func (f *fakeIdempotencyStore) Complete(ctx context.Context, key string, resp idempotency.Response) error {
+	f.stored = &resp
+	return nil
+}
+
+func (f *fakeIdempotencyStore) Release(ctx context.Context, key string) error {
+	f.released = true
+	return nil
+}
+
+func TestHandleImportRecipe_IdempotentReplay(t *testing.T) {
+	store := &fakeIdempotencyStore{stored: &idempotency.Response{
+		Status:      http.StatusAccepted,
+		ContentType: "application/json",
+		Body:        []byte(`{"job_id":"job-1","url":"https://instagram.com/p/test"}`),
+	}}
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetIdempotencyStore(store)
+
+	req := httptest.NewRequest("POST", "/api/recipe", strings.NewReader(`{"url":"https://instagram.com/p/test"}`))
+	req.Header.Set(IdempotencyKeyHeader, "retry-1")
+	req = req.WithContext(withUserID(req.Context(), "user-123"))
+	rr := httptest.NewRecorder()
+
+	srv.HandleImportRecipe(rr, req)
+
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected status %d, got %d", http.StatusAccepted, rr.Code)
+	}
+	if rr.Header().Get(IdempotentReplayedHeader) != "true" {
+		t.Error("expected the response to be marked as replayed")
+	}
+	if rr.Body.String() != string(store.stored.Body) {
+		t.Errorf("expected the stored body, got %s", rr.Body.String())
+	}
+	if store.key != "user-123:import:retry-1" {
+		t.Errorf("expected the key to be scoped to the user and route, got %q", store.key)
+	}
+}
+
+func TestHandleBulkImportRecipe_IdempotencyErrors(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		err        error
+		wantStatus int
+	}{
+		{name: "different request", key: "retry-1", err: idempotency.ErrMismatch, wantStatus: http.StatusUnprocessableEntity},
+		{name: "in progress", key: "retry-1", err: idempotency.ErrInProgress, wantStatus: http.StatusConflict},
+		{name: "key too long", key: strings.Repeat("k", 256), wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := NewServer(&config.Config{}, nil, nil, nil)
+			srv.SetIdempotencyStore(&fakeIdempotencyStore{err: tt.err})
+
+			req := httptest.NewRequest("POST", "/api/bulk-import", strings.NewReader(`{"urls":["https://instagram.com/p/test"]}`))
+			req.Header.Set(IdempotencyKeyHeader, tt.key)
+			req = req.WithContext(withUserID(req.Context(), "user-123"))
+			rr := httptest.NewRecorder()
+
+			srv.HandleBulkImportRecipe(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestIdempotentRequest_Finish(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantStored   bool
+		wantReleased bool
+	}{
+		{name: "accepted", status: http.StatusAccepted, wantStored: true},
+		{name: "rejected", status: http.StatusTooManyRequests, wantReleased: true},
+		{name: "failed", status: http.StatusInternalServerError, wantReleased: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeIdempotencyStore{}
+			srv := NewServer(&config.Config{}, nil, nil, nil)
+			srv.SetIdempotencyStore(store)
+
+			req := httptest.NewRequest("POST", "/api/recipe", strings.NewReader(`{}`))
+			req.Header.Set(IdempotencyKeyHeader, "retry-1")
+			rr := httptest.NewRecorder()
+
+			idem, ok := srv.beginIdempotent(rr, req, "user-123", "import")
+			if !ok {
+				t.Fatal("expected the request to be handled")
+			}
+			if idem.jobID != uuid.NewSHA1(jobIDNamespace, []byte("user-123:import:retry-1")).String() {
+				t.Errorf("expected the job ID to be derived from the key, got %s", idem.jobID)
+			}
+			idem.WriteHeader(tt.status)
+			idem.Write([]byte(`{"job_id":"job-1"}`))
+			idem.finish()
+
+			if (store.stored != nil) != tt.wantStored {
+				t.Errorf("expected stored %v, got %+v", tt.wantStored, store.stored)
+			}
+			if store.released != tt.wantReleased {
+				t.Errorf("expected released %v, got %v", tt.wantReleased, store.released)
+			}
+			if tt.wantStored && string(store.stored.Body) != `{"job_id":"job-1"}` {
+				t.Errorf("expected the response body to be stored, got %s", store.stored.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/idempotency"
+)
+
+const (
+	// IdempotencyKeyHeader lets clients retry job-creating requests without
+	// creating the job twice.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed for a repeated key.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+var (
+	errIdempotencyKeyTooLong = invalidRequest("INVALID_IDEMPOTENCY_KEY", "Idempotency-Key must be at most 255 characters")
+	errIdempotencyKeyReused  = apperrors.NewValidationError(
+		"Idempotency-Key was already used with a different request",
+		"IDEMPOTENCY_KEY_REUSED",
+		"Use a new key for each new request.",
+	).WithStatus(http.StatusUnprocessableEntity)
+	errIdempotencyKeyInUse = apperrors.NewConflictError(
+		"A request with this Idempotency-Key is still being handled",
+		"IDEMPOTENCY_KEY_IN_USE",
+		"Retry shortly to get its response.",
+	)
+)
+
+// jobIDNamespace derives job IDs from idempotency keys.
+var jobIDNamespace = uuid.MustParse("5f0c8f2e-8a55-4d4f-9b1e-3c6f1f3f7a2d")
+
+// idempotentRequest handles a job-creating request once per Idempotency-Key
+// and records its response for repeats.
+type idempotentRequest struct {
+	http.ResponseWriter
+	store       IdempotencyStore
+	ctx         context.Context
+	key         string
+	fingerprint string
+	jobID       string
+	status      int
+	body        bytes.Buffer
+}
+
+// beginIdempotent starts handling a request that creates a job, such as an
+// import, where scope names the kind of job. Requests with an Idempotency-Key get a job ID derived from it,
+// so a repeat can never queue a second job. A repeat of a completed request
+// gets the stored response and ok is false, as it is when the request is
+// rejected; the response has been written in both cases. Otherwise the
+// handler writes its response to the returned request and calls finish.
+func (s *Server) beginIdempotent(w http.ResponseWriter, r *http.Request, userID, scope string) (*idempotentRequest, bool) {
+	req := &idempotentRequest{ResponseWriter: w, ctx: context.WithoutCancel(r.Context())}
+
+	key := r.Header.Get(IdempotencyKeyHeader)
+	if key == "" {
+		req.jobID = uuid.New().String()
+		return req, true
+	}
+	if len(key) > maxIdempotencyKeyLength {
+		writeError(w, r, errIdempotencyKeyTooLong)
+		return nil, false
+	}
+	req.key = userID + ":" + scope + ":" + key
+	req.jobID = uuid.NewSHA1(jobIDNamespace, []byte(req.key)).String()
+	if s.idempotency == nil {
+		return req, true
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeError(w, r, errInvalidBody)
+		return nil, false
+	}
+	r.Body = io.NopCloser(bytes.NewReader(body))
+
+	req.fingerprint = idempotency.Fingerprint([]byte(scope), body)
+	stored, err := s.idempotency.Begin(r.Context(), req.key, req.fingerprint)
+	switch {
+	case errors.Is(err, idempotency.ErrMismatch):
+		writeError(w, r, errIdempotencyKeyReused)
+		return nil, false
+	case errors.Is(err, idempotency.ErrInProgress):
+		writeError(w, r, errIdempotencyKeyInUse)
+		return nil, false
+	case err != nil:
+		// Without Redis the derived job ID still prevents duplicate jobs
+		slog.Error("Idempotency check failed", "error", err, "user_id", userID)
+		return req, true
+	case stored != nil:
+		if stored.ContentType != "" {
+			w.Header().Set("Content-Type", stored.ContentType)
+		}
+		w.Header().Set(IdempotentReplayedHeader, "true")
+		w.WriteHeader(stored.Status)
+		w.Write(stored.Body)
+		return nil, false
+	}
+
+	req.store = s.idempotency
+	return req, true
+}
+
+func (r *idempotentRequest) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *idempotentRequest) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	if r.store != nil {
+		r.body.Write(b)
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *idempotentRequest) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// finish stores a successful response for repeats of the key. Failed
+// requests release the key, so the client can retry them.
+func (r *idempotentRequest) finish() {
+	if r.store == nil {
+		return
+	}
+	if r.status < 200 || r.status >= 300 {
+		if err := r.store.Release(r.ctx, r.key); err != nil {
+			slog.Error("Failed to release idempotency key", "error", err)
+		}
+		return
+	}
+	err := r.store.Complete(r.ctx, r.key, idempotency.Response{
+		Fingerprint: r.fingerprint,
+		Status:      r.status,
+		ContentType: r.Header().Get("Content-Type"),
+		Body:        r.body.Bytes(),
+	})
+	if err != nil {
+		slog.Error("Failed to store idempotent response", "error", err)
+	}
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation.
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
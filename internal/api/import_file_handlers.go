@@ -0,0 +1,166 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/importer"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// maxImportFileBytes limits uploaded export files
+const maxImportFileBytes = 100 << 20
+
+// MaxRecipesPerFileImport limits how many recipes one export file may hold
+const MaxRecipesPerFileImport = 2000
+
+// HandleBulkImportFile imports an export file from another recipe manager,
+// sent as the "file" field of a multipart form. The optional "format" field
+// is one of paprika, mealie, whisk or jsonld and is detected when omitted;
+// "enrich" set to true suggests categories and generates rich instructions.
+// Progress and per-recipe results are reported like URL bulk imports.
+func (s *Server) HandleBulkImportFile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	// Leave room for the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxImportFileBytes+1<<20)
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, r, apperrors.NewValidationError("File is too large", "FILE_TOO_LARGE", "").WithStatus(http.StatusRequestEntityTooLarge))
+			return
+		}
+		writeError(w, r, errInvalidMultipart)
+		return
+	}
+
+	format, err := importer.ParseFormat(r.FormValue("format"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_IMPORT_FORMAT", err.Error()))
+		return
+	}
+
+	enrich := false
+	if v := r.FormValue("enrich"); v != "" {
+		enrich, err = strconv.ParseBool(v)
+		if err != nil {
+			writeError(w, r, invalidRequest("INVALID_ENRICH", "enrich must be true or false"))
+			return
+		}
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		writeError(w, r, invalidRequest("MISSING_FILE", "file is required"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileBytes+1))
+	if err != nil {
+		writeError(w, r, invalidRequest("UNREADABLE_FILE", "Failed to read file"))
+		return
+	}
+	if len(data) > maxImportFileBytes {
+		writeError(w, r, apperrors.NewValidationError("File is too large", "FILE_TOO_LARGE", "").WithStatus(http.StatusRequestEntityTooLarge))
+		return
+	}
+
+	// Parsed here only to reject unreadable files and count the recipes; the
+	// worker parses the stored file again
+	filename := path.Base(header.Filename)
+	items, err := importer.Parse(format, filename, data)
+	if err != nil {
+		writeError(w, r, apperrors.NewValidationError(fmt.Sprintf("Could not read export file: %v", err), "UNREADABLE_IMPORT_FILE", "Check the file is an export from a supported recipe manager.").WithStatus(http.StatusUnprocessableEntity))
+		return
+	}
+	if len(items) > MaxRecipesPerFileImport {
+		writeError(w, r, invalidRequest("TOO_MANY_RECIPES", fmt.Sprintf("Maximum %d recipes allowed per file import", MaxRecipesPerFileImport)))
+		return
+	}
+
+	if s.imports == nil {
+		writeError(w, r, apperrors.NewUnavailableError("File imports are not available", "FILE_IMPORTS_UNAVAILABLE", ""))
+		return
+	}
+
+	activeCount, err := s.db.GetUserActiveBulkImportCount(r.Context(), parseUUID(userID))
+	if err != nil {
+		slog.Error("Failed to check active bulk import count", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to check rate limit"))
+		return
+	}
+
+	if activeCount >= MaxConcurrentBulkJobs {
+		writeError(w, r, errTooManyBulkJobs)
+		return
+	}
+
+	if !s.checkImportQuota(w, r, userID, len(items)) {
+		return
+	}
+
+	bulkJobID := uuid.New().String()
+	middleware.SetAuditAction(r.Context(), "bulk_import.create", "bulk_import", bulkJobID)
+	storagePath := fmt.Sprintf("%s/%s/%s", userID, bulkJobID, filename)
+	if err := s.imports.UploadObject(r.Context(), importer.Bucket, storagePath, data, "application/octet-stream"); err != nil {
+		slog.Error("Failed to upload import file", "error", err, "user_id", userID, "bulk_job_id", bulkJobID)
+		writeError(w, r, internalError("Failed to upload file"))
+		return
+	}
+
+	_, err = s.db.CreateBulkImportJob(r.Context(), generated.CreateBulkImportJobParams{
+		ID:        parseUUID(uuid.New().String()),
+		JobID:     bulkJobID,
+		UserID:    parseUUID(userID),
+		TotalUrls: int32(len(items)),
+		Status:    "QUEUED",
+	})
+	if err != nil {
+		slog.Error("Failed to create bulk import job", "error", err, "user_id", userID, "bulk_job_id", bulkJobID)
+		writeError(w, r, internalError("Failed to create bulk import job"))
+		return
+	}
+
+	task, err := worker.NewImportRecipeFileTask(worker.ImportRecipeFilePayload{
+		BulkJobID:   bulkJobID,
+		UserID:      userID,
+		StoragePath: storagePath,
+		Filename:    filename,
+		Format:      string(format),
+		Enrich:      enrich,
+	})
+	if err != nil {
+		slog.Error("Failed to create file import task", "error", err, "bulk_job_id", bulkJobID)
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		slog.Error("Failed to enqueue file import task", "error", err, "bulk_job_id", bulkJobID)
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(BulkImportRecipeResponse{
+		BulkJobID: bulkJobID,
+		TotalURLs: len(items),
+		Status:    "QUEUED",
+	})
+}
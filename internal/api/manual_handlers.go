@@ -0,0 +1,175 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/storage"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// Limits for manual recipe sources
+const (
+	maxManualTextLength = 20000
+	maxManualImageBytes = 10 << 20
+)
+
+// manualImageTypes are the photo formats the worker can read and store.
+var manualImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+}
+
+type ImportRecipeFromTextRequest struct {
+	Text string `json:"text"`
+}
+
+// HandleImportRecipeFromText creates a recipe from pasted text, such as a
+// note or a recipe copied from a message.
+func (s *Server) HandleImportRecipeFromText(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	var req ImportRecipeFromTextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		writeError(w, r, invalidRequest("MISSING_TEXT", "text is required"))
+		return
+	}
+	if utf8.RuneCountInString(text) > maxManualTextLength {
+		writeError(w, r, apperrors.NewValidationError(fmt.Sprintf("text must be at most %d characters", maxManualTextLength), "TEXT_TOO_LONG", "").WithStatus(http.StatusRequestEntityTooLarge))
+		return
+	}
+
+	if !s.checkImportQuota(w, r, userID, 1) {
+		return
+	}
+
+	s.enqueueManualImport(w, r, userID, worker.ProcessRecipePayload{Text: text})
+}
+
+// HandleImportRecipeFromImage creates a recipe from an uploaded photo or
+// screenshot, sent as the "image" field of a multipart form. An optional
+// "text" field adds notes to what is read from the photo.
+func (s *Server) HandleImportRecipeFromImage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	// Leave room for the other form fields
+	r.Body = http.MaxBytesReader(w, r.Body, maxManualImageBytes+1<<20)
+	if err := r.ParseMultipartForm(maxManualImageBytes); err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeError(w, r, apperrors.NewValidationError("Image is too large", "IMAGE_TOO_LARGE", "").WithStatus(http.StatusRequestEntityTooLarge))
+			return
+		}
+		writeError(w, r, errInvalidMultipart)
+		return
+	}
+
+	file, _, err := r.FormFile("image")
+	if err != nil {
+		writeError(w, r, invalidRequest("MISSING_IMAGE", "image is required"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxManualImageBytes+1))
+	if err != nil {
+		writeError(w, r, invalidRequest("UNREADABLE_IMAGE", "Failed to read image"))
+		return
+	}
+	if len(data) > maxManualImageBytes {
+		writeError(w, r, apperrors.NewValidationError("Image is too large", "IMAGE_TOO_LARGE", "").WithStatus(http.StatusRequestEntityTooLarge))
+		return
+	}
+	if !manualImageTypes[http.DetectContentType(data)] {
+		writeError(w, r, apperrors.NewValidationError("Image must be a JPEG or PNG", "UNSUPPORTED_IMAGE_TYPE", "").WithStatus(http.StatusUnsupportedMediaType))
+		return
+	}
+
+	text := strings.TrimSpace(r.FormValue("text"))
+	if utf8.RuneCountInString(text) > maxManualTextLength {
+		writeError(w, r, apperrors.NewValidationError(fmt.Sprintf("text must be at most %d characters", maxManualTextLength), "TEXT_TOO_LONG", "").WithStatus(http.StatusRequestEntityTooLarge))
+		return
+	}
+
+	if s.images == nil {
+		writeError(w, r, apperrors.NewUnavailableError("Image uploads are not available", "IMAGE_UPLOADS_UNAVAILABLE", ""))
+		return
+	}
+
+	if !s.checkImportQuota(w, r, userID, 1) {
+		return
+	}
+
+	// Stored like scraped post images, so the worker reuses it as the recipe image
+	path := fmt.Sprintf("post_images/%s", storage.HashContent(data))
+	imageURL, err := s.images.UploadImageWithHash(r.Context(), "recipes", path, "", data)
+	if err != nil {
+		slog.Error("Failed to upload recipe photo", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to upload image"))
+		return
+	}
+
+	s.enqueueManualImport(w, r, userID, worker.ProcessRecipePayload{Text: text, ImageURL: imageURL})
+}
+
+// enqueueManualImport creates an import job for a manual recipe and queues
+// it on the same processing path as URL imports.
+func (s *Server) enqueueManualImport(w http.ResponseWriter, r *http.Request, userID string, payload worker.ProcessRecipePayload) {
+	jobID := uuid.New().String()
+	middleware.SetAuditAction(r.Context(), "import.create", "import_job", jobID)
+
+	_, err := s.db.CreateImportJob(r.Context(), generated.CreateImportJobParams{
+		ID:     parseUUID(uuid.New().String()),
+		JobID:  jobID,
+		UserID: parseUUID(userID),
+		Url:    "",
+		Origin: string(generated.RecipeOriginManual),
+		Status: "QUEUED",
+	})
+	if err != nil {
+		slog.Error("Failed to create import job", "error", err, "user_id", userID, "job_id", jobID)
+		writeError(w, r, internalError("Failed to create import job"))
+		return
+	}
+
+	payload.JobID = jobID
+	payload.UserID = userID
+	task, err := worker.NewProcessRecipeTask(payload)
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(ImportRecipeResponse{JobID: jobID})
+}
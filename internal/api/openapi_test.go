@@ -0,0 +1,257 @@
+package api
+
+import (
+	"encoding/json"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/openapi"
+	"github.com/socialchef/remy/internal/services/search"
+)
+
+// contractTypes are the Go types behind the spec's top-level component
+// schemas. Nested types are checked through the schemas that reference them.
+var contractTypes = map[string]any{
+	"ErrorResponse":                       apperrors.Response{},
+	"ImportRecipeRequest":                 ImportRecipeRequest{},
+	"ImportRecipeResponse":                ImportRecipeResponse{},
+	"ImportRecipeFromTextRequest":         ImportRecipeFromTextRequest{},
+	"JobStatusResponse":                   JobStatusResponse{},
+	"UserImportStatusResponse":            UserImportStatusResponse{},
+	"GenerateEmbeddingRequest":            GenerateEmbeddingRequest{},
+	"InstructionIngredientsCountResponse": InstructionIngredientsCountResponse{},
+	"RecipeResponse":                      RecipeResponse{},
+	"RecipeStepsWithPartsResponse":        RecipeStepsWithPartsResponse{},
+	"BulkImportRecipeRequest":             BulkImportRecipeRequest{},
+	"BulkImportRecipeResponse":            BulkImportRecipeResponse{},
+	"BulkImportStatusResponse":            BulkImportStatusResponse{},
+	"UserBulkImportsResponse":             UserBulkImportsResponse{},
+	"CreateRecipeExportRequest":           CreateRecipeExportRequest{},
+	"RecipeExportResponse":                RecipeExportResponse{},
+	"RecipeEditRequest":                   RecipeEditRequest{},
+	"RecipeEditResponse":                  RecipeEditResponse{},
+	"RecipeRevisionsResponse":             RecipeRevisionsResponse{},
+	"RecipeRevisionResponse":              RecipeRevisionResponse{},
+	"RegenerateRecipeRequest":             RegenerateRecipeRequest{},
+	"RecipeRegenerationResponse":          RecipeRegenerationResponse{},
+	"SearchRequest":                       SearchRequest{},
+	"SearchResult":                        search.SearchResult{},
+	"CreateRecipeShareRequest":            CreateRecipeShareRequest{},
+	"RecipeShareResponse":                 RecipeShareResponse{},
+	"RecipeSharesResponse":                RecipeSharesResponse{},
+	"SharedRecipeResponse":                SharedRecipeResponse{},
+	"UsageResponse":                       UsageResponse{},
+	"CreateWebhookRequest":                CreateWebhookRequest{},
+	"WebhookResponse":                     WebhookResponse{},
+	"WebhooksResponse":                    WebhooksResponse{},
+	"WebhookDeliveryResponse":             WebhookDeliveryResponse{},
+	"WebhookDeliveriesResponse":           WebhookDeliveriesResponse{},
+	"EmbeddingBackfillRequest":            EmbeddingBackfillRequest{},
+	"EmbeddingBackfillResponse":           EmbeddingBackfillResponse{},
+	"EmbeddingBackfillProgress":           EmbeddingBackfillProgress{},
+	"SetUserPlanRequest":                  SetUserPlanRequest{},
+	"UserPlanResponse":                    UserPlanResponse{},
+	"AdminImportJobsResponse":             AdminImportJobsResponse{},
+	"AdminBulkImportsResponse":            AdminBulkImportsResponse{},
+	"AdminJobStatsResponse":               AdminJobStatsResponse{},
+	"AdminJobActionResponse":              AdminJobActionResponse{},
+	"QueuesResponse":                      QueuesResponse{},
+	"DeadTasksResponse":                   DeadTasksResponse{},
+	"MaintenanceTaskResponse":             MaintenanceTaskResponse{},
+	"AuditEventsResponse":                 AuditEventsResponse{},
+	"AccountExportResponse":               AccountExportResponse{},
+	"AccountErasureResponse":              AccountErasureResponse{},
+}
+
+func loadSpec(t *testing.T) *openapi.Spec {
+	t.Helper()
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	return spec
+}
+
+// TestOpenAPIContract_Schemas fails when a request or response struct and
+// its schema in openapi.json drift apart: a field added, removed, renamed,
+// made optional or given another type on one side only.
+func TestOpenAPIContract_Schemas(t *testing.T) {
+	spec := loadSpec(t)
+	c := contractChecker{t: t, spec: spec, checked: map[string]bool{}}
+
+	for name, value := range contractTypes {
+		schema := spec.Schema(name)
+		if schema == nil {
+			t.Errorf("openapi.json has no schema %s", name)
+			continue
+		}
+		c.checkStruct(name, schema, reflect.TypeOf(value))
+	}
+
+	for name := range spec.Components.Schemas {
+		if !c.checked[name] {
+			t.Errorf("schema %s is not checked against a Go type; add it to contractTypes", name)
+		}
+	}
+}
+
+// TestOpenAPIContract_Operations fails when a handler has no operation in
+// openapi.json, or the spec describes an operation no handler implements.
+func TestOpenAPIContract_Operations(t *testing.T) {
+	spec := loadSpec(t)
+	ops := spec.Operations()
+
+	var handlers []string
+	serverType := reflect.TypeOf(&Server{})
+	for i := 0; i < serverType.NumMethod(); i++ {
+		if name, ok := strings.CutPrefix(serverType.Method(i).Name, "Handle"); ok {
+			handlers = append(handlers, name)
+			if ops[name] == nil {
+				t.Errorf("Handle%s has no operation %q in openapi.json", name, name)
+			}
+		}
+	}
+	for id := range ops {
+		if !slices.Contains(handlers, id) {
+			t.Errorf("operation %q has no Handle%s method", id, id)
+		}
+	}
+}
+
+// TestOpenAPIContract_Limits keeps the limits the validation middleware
+// enforces in step with the handlers' own.
+func TestOpenAPIContract_Limits(t *testing.T) {
+	spec := loadSpec(t)
+
+	urls := spec.Schema("BulkImportRecipeRequest").Properties["urls"]
+	if urls.MaxItems == nil || *urls.MaxItems != MaxURLsPerBulkImport {
+		t.Errorf("BulkImportRecipeRequest.urls maxItems should be %d", MaxURLsPerBulkImport)
+	}
+	text := spec.Schema("ImportRecipeFromTextRequest").Properties["text"]
+	if text.MaxLength == nil || *text.MaxLength != maxManualTextLength {
+		t.Errorf("ImportRecipeFromTextRequest.text maxLength should be %d", maxManualTextLength)
+	}
+	expiry := spec.Schema("CreateRecipeShareRequest").Properties["expires_in_days"]
+	if expiry.Maximum == nil || *expiry.Maximum != maxShareExpiryDays {
+		t.Errorf("CreateRecipeShareRequest.expires_in_days maximum should be %d", maxShareExpiryDays)
+	}
+}
+
+type contractChecker struct {
+	t       *testing.T
+	spec    *openapi.Spec
+	checked map[string]bool
+}
+
+func (c *contractChecker) checkStruct(name string, schema *openapi.Schema, typ reflect.Type) {
+	if c.checked[name] {
+		return
+	}
+	c.checked[name] = true
+
+	var fields, required []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		jsonName, opts, _ := strings.Cut(tag, ",")
+		if jsonName == "" {
+			jsonName = field.Name
+		}
+		omitempty := strings.Contains(opts, "omitempty")
+		fields = append(fields, jsonName)
+		if !omitempty {
+			required = append(required, jsonName)
+		}
+
+		property, ok := schema.Properties[jsonName]
+		if !ok {
+			c.t.Errorf("%s: field %s is missing from openapi.json", name, jsonName)
+			continue
+		}
+		nullable := field.Type.Kind() == reflect.Pointer && !omitempty
+		c.checkType(name+"."+jsonName, property, field.Type, nullable)
+	}
+
+	for property := range schema.Properties {
+		if !slices.Contains(fields, property) {
+			c.t.Errorf("%s: openapi.json has property %s the Go type does not", name, property)
+		}
+	}
+	specRequired := slices.Clone(schema.Required)
+	sort.Strings(specRequired)
+	sort.Strings(required)
+	if !slices.Equal(specRequired, required) {
+		c.t.Errorf("%s: required should be %v (fields without omitempty), openapi.json has %v", name, required, specRequired)
+	}
+}
+
+func (c *contractChecker) checkType(path string, schema *openapi.Schema, typ reflect.Type, nullable bool) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == reflect.TypeOf(json.RawMessage{}) {
+		if len(schema.Type) > 0 || schema.Ref != "" {
+			c.t.Errorf("%s: raw JSON should have an empty schema", path)
+		}
+		return
+	}
+
+	if schema.Ref != "" {
+		name := strings.TrimPrefix(schema.Ref, "#/components/schemas/")
+		if typ.Kind() != reflect.Struct {
+			c.t.Errorf("%s: references %s but is a %s", path, name, typ)
+			return
+		}
+		c.checkStruct(name, c.spec.Resolve(schema), typ)
+		return
+	}
+
+	var want string
+	switch typ.Kind() {
+	case reflect.String:
+		want = "string"
+	case reflect.Bool:
+		want = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		want = "integer"
+	case reflect.Float32, reflect.Float64:
+		want = "number"
+	case reflect.Slice, reflect.Array:
+		want = "array"
+	case reflect.Map:
+		want = "object"
+	default:
+		c.t.Errorf("%s: %s should reference a component schema", path, typ)
+		return
+	}
+	if !slices.Contains(schema.Type, want) {
+		c.t.Errorf("%s: type should be %s, openapi.json has %v", path, want, schema.Type)
+	}
+	if nullable != slices.Contains(schema.Type, "null") {
+		c.t.Errorf("%s: nullable should be %v", path, nullable)
+	}
+
+	switch want {
+	case "array":
+		if schema.Items == nil {
+			c.t.Errorf("%s: array has no items schema", path)
+			return
+		}
+		c.checkType(path+"[]", schema.Items, typ.Elem(), false)
+	case "object":
+		var values *openapi.Schema
+		if err := json.Unmarshal(schema.AdditionalProperties, &values); err != nil || values == nil {
+			c.t.Errorf("%s: map has no additionalProperties schema", path)
+			return
+		}
+		c.checkType(path+"{}", values, typ.Elem(), false)
+	}
+}
@@ -0,0 +1,446 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/revision"
+	"github.com/socialchef/remy/internal/services/webhook"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// errInvalidEdit wraps snapshot validation errors so they map to 400.
+var errInvalidEdit = errors.New("invalid edit")
+
+func (s *Server) HandleUpdateRecipe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "recipe.update", "recipe", chi.URLParam(r, "recipeID"))
+
+	var req RecipeEditRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	s.editRecipe(w, r, recipe, parseUUID(userID), revisionEdit{
+		action:  RevisionActionEdit,
+		message: req.Message,
+		next:    req.apply,
+	})
+}
+
+func (s *Server) HandleListRecipeRevisions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	revisions, err := s.db.ListRecipeRevisions(r.Context(), recipe.ID)
+	if err != nil {
+		slog.Error("Failed to list recipe revisions", "error", err, "recipe_id", chi.URLParam(r, "recipeID"))
+		writeError(w, r, internalError("Failed to list revisions"))
+		return
+	}
+
+	response := RecipeRevisionsResponse{Revisions: make([]RecipeRevisionResponse, len(revisions))}
+	for i, rev := range revisions {
+		response.Revisions[i] = revisionResponse(generated.RecipeRevision{
+			ID:             rev.ID,
+			RecipeID:       rev.RecipeID,
+			RevisionNumber: rev.RevisionNumber,
+			AuthorID:       rev.AuthorID,
+			Action:         rev.Action,
+			RestoredFrom:   rev.RestoredFrom,
+			Diff:           rev.Diff,
+			Message:        rev.Message,
+			CreatedAt:      rev.CreatedAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) HandleGetRecipeRevision(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	revisionNumber, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil || revisionNumber < 1 {
+		writeError(w, r, invalidRequest("INVALID_REVISION", "Invalid revision number"))
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	rev, err := s.db.GetRecipeRevision(r.Context(), generated.GetRecipeRevisionParams{
+		RecipeID:       recipe.ID,
+		RevisionNumber: int32(revisionNumber),
+	})
+	if err != nil {
+		writeError(w, r, errRevisionNotFound)
+		return
+	}
+
+	response := revisionResponse(rev)
+	var snap RecipeSnapshot
+	if err := json.Unmarshal(rev.Snapshot, &snap); err != nil {
+		slog.Error("Failed to unmarshal revision snapshot", "error", err, "revision_id", uuid.UUID(rev.ID.Bytes).String())
+		writeError(w, r, internalError("Failed to read revision"))
+		return
+	}
+	response.Snapshot = &snap
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleRestoreRecipeRevision makes an earlier revision's snapshot the
+// current recipe. The restore is itself recorded as a new revision, so it
+// can be undone like any other edit.
+func (s *Server) HandleRestoreRecipeRevision(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "recipe.restore", "recipe", chi.URLParam(r, "recipeID"))
+
+	revisionNumber, err := strconv.Atoi(chi.URLParam(r, "revision"))
+	if err != nil || revisionNumber < 1 {
+		writeError(w, r, invalidRequest("INVALID_REVISION", "Invalid revision number"))
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	rev, err := s.db.GetRecipeRevision(r.Context(), generated.GetRecipeRevisionParams{
+		RecipeID:       recipe.ID,
+		RevisionNumber: int32(revisionNumber),
+	})
+	if err != nil {
+		writeError(w, r, errRevisionNotFound)
+		return
+	}
+
+	var snap RecipeSnapshot
+	if err := json.Unmarshal(rev.Snapshot, &snap); err != nil {
+		slog.Error("Failed to unmarshal revision snapshot", "error", err, "revision_id", uuid.UUID(rev.ID.Bytes).String())
+		writeError(w, r, internalError("Failed to read revision"))
+		return
+	}
+
+	s.editRecipe(w, r, recipe, parseUUID(userID), revisionEdit{
+		action:       RevisionActionRestore,
+		restoredFrom: pgtype.Int4{Int32: rev.RevisionNumber, Valid: true},
+		message:      fmt.Sprintf("Restored revision %d", rev.RevisionNumber),
+		next:         func(RecipeSnapshot) RecipeSnapshot { return snap },
+	})
+}
+
+// HandleDeleteRecipe deletes a recipe and, through cascades, its parts,
+// ingredients, instructions, categories, embeddings and revisions. Its
+// stored images lose a reference each; images left unreferenced are removed
+// from storage by a garbage collection task.
+func (s *Server) HandleDeleteRecipe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "recipe.delete", "recipe", chi.URLParam(r, "recipeID"))
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+	recipeID := uuid.UUID(recipe.ID.Bytes).String()
+
+	var contentHashes []string
+	err := s.withTx(r.Context(), func(q *generated.Queries) error {
+		images, err := q.GetImagesByRecipe(r.Context(), recipe.ID)
+		if err != nil {
+			return fmt.Errorf("failed to get images: %w", err)
+		}
+		for _, image := range images {
+			contentHashes = append(contentHashes, image.ContentHash)
+		}
+
+		if err := q.DeleteRecipeImages(r.Context(), recipe.ID); err != nil {
+			return fmt.Errorf("failed to delete recipe images: %w", err)
+		}
+		if err := q.DeleteRecipe(r.Context(), generated.DeleteRecipeParams{
+			ID:        recipe.ID,
+			CreatedBy: recipe.CreatedBy,
+		}); err != nil {
+			return fmt.Errorf("failed to delete recipe: %w", err)
+		}
+		if len(contentHashes) > 0 {
+			if err := q.DecrementStoredImageReferences(r.Context(), contentHashes); err != nil {
+				return fmt.Errorf("failed to release stored images: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		slog.Error("Failed to delete recipe", "error", err, "recipe_id", recipeID)
+		writeError(w, r, internalError("Failed to delete recipe"))
+		return
+	}
+
+	if len(contentHashes) > 0 && s.asynqClient != nil {
+		task, err := worker.NewGarbageCollectStorageTask(worker.GarbageCollectStoragePayload{ContentHashes: contentHashes})
+		if err == nil {
+			_, err = s.asynqClient.Enqueue(task)
+		}
+		if err != nil {
+			slog.Error("Failed to enqueue storage garbage collection", "error", err, "recipe_id", recipeID)
+		}
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ownedRecipe loads the recipe named in the URL, writing an error response
+// unless it exists and was created by userID.
+func (s *Server) ownedRecipe(w http.ResponseWriter, r *http.Request, userID string) (generated.Recipe, bool) {
+	recipeID := chi.URLParam(r, "recipeID")
+	if recipeID == "" {
+		writeError(w, r, invalidRequest("MISSING_RECIPE_ID", "recipe_id is required"))
+		return generated.Recipe{}, false
+	}
+
+	recipe, err := s.db.GetRecipe(r.Context(), parseUUID(recipeID))
+	if err != nil {
+		writeError(w, r, errRecipeNotFound)
+		return generated.Recipe{}, false
+	}
+
+	if recipe.CreatedBy.Bytes != parseUUID(userID).Bytes {
+		writeError(w, r, errUnauthorized)
+		return generated.Recipe{}, false
+	}
+	return recipe, true
+}
+
+type revisionEdit struct {
+	action       string
+	restoredFrom pgtype.Int4
+	message      string
+	next         func(RecipeSnapshot) RecipeSnapshot
+}
+
+// editRecipe saves edit.next(current) as the recipe content and records a
+// revision, all in one transaction. The recipe row is locked first, so
+// concurrent edits apply one after the other to the latest content. The
+// first edit of a recipe also records its imported state as revision 1 so it
+// can be restored later.
+func (s *Server) editRecipe(w http.ResponseWriter, r *http.Request, recipe generated.Recipe, authorID pgtype.UUID, edit revisionEdit) {
+	recipeID := uuid.UUID(recipe.ID.Bytes).String()
+
+	var (
+		saved       *generated.RecipeRevision
+		result      RecipeSnapshot
+		missingRich bool
+	)
+	err := s.withTx(r.Context(), func(q *generated.Queries) error {
+		recipe, err := q.LockRecipe(r.Context(), recipe.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock recipe: %w", err)
+		}
+		current, err := revision.Load(r.Context(), q, recipe)
+		if err != nil {
+			return err
+		}
+		result = current
+
+		next := edit.next(current)
+		if err := next.Validate(); err != nil {
+			return fmt.Errorf("%w: %v", errInvalidEdit, err)
+		}
+
+		changes, err := revision.Diff(current, next)
+		if err != nil {
+			return err
+		}
+		if len(changes) == 0 {
+			return nil
+		}
+
+		if err := revision.RecordOriginal(r.Context(), q, recipe, current); err != nil {
+			return err
+		}
+
+		_, err = q.UpdateRecipe(r.Context(), generated.UpdateRecipeParams{
+			ID:                  recipe.ID,
+			RecipeName:          next.RecipeName,
+			Description:         pgtype.Text{String: next.Description, Valid: next.Description != ""},
+			PrepTime:            int4Value(next.PrepTime),
+			CookingTime:         int4Value(next.CookingTime),
+			OriginalServingSize: int4Value(next.OriginalServingSize),
+			DifficultyRating:    int2Value(next.DifficultyRating),
+			Origin:              recipe.Origin,
+			Url:                 recipe.Url,
+			OwnerID:             recipe.OwnerID,
+			ThumbnailID:         recipe.ThumbnailID,
+			CreatedBy:           recipe.CreatedBy,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update recipe: %w", err)
+		}
+
+		if revision.ContentChanged(changes) {
+			missingRich, err = revision.SaveContent(r.Context(), q, recipe.ID, next)
+			if err != nil {
+				return err
+			}
+		}
+
+		snapshot, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		diff, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		rev, err := q.CreateRecipeRevision(r.Context(), generated.CreateRecipeRevisionParams{
+			RecipeID:     recipe.ID,
+			AuthorID:     authorID,
+			Action:       edit.action,
+			RestoredFrom: edit.restoredFrom,
+			Snapshot:     snapshot,
+			Diff:         diff,
+			Message:      pgtype.Text{String: edit.message, Valid: edit.message != ""},
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create revision: %w", err)
+		}
+		saved = &rev
+		result = next
+		return nil
+	})
+	if errors.Is(err, errInvalidEdit) {
+		writeError(w, r, invalidRequest("INVALID_EDIT", err.Error()))
+		return
+	}
+	if err != nil {
+		slog.Error("Failed to edit recipe", "error", err, "recipe_id", recipeID)
+		writeError(w, r, internalError("Failed to edit recipe"))
+		return
+	}
+
+	response := RecipeEditResponse{Recipe: result}
+	if saved != nil {
+		rev := revisionResponse(*saved)
+		response.Revision = &rev
+		s.enqueueRecipeRefresh(recipeID, missingRich)
+
+		if err := worker.EmitWebhookEvent(r.Context(), s.db, s.asynqClient, uuid.UUID(recipe.CreatedBy.Bytes).String(), webhook.EventRecipeUpdated, webhook.RecipeData{
+			RecipeID: recipeID,
+			Action:   saved.Action,
+			Revision: saved.RevisionNumber,
+		}); err != nil {
+			slog.Error("Failed to emit recipe webhook", "error", err, "recipe_id", recipeID)
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// enqueueRecipeRefresh re-embeds an edited recipe and, if any steps lost
+// their rich text, regenerates it. Failures are logged: the edit itself has
+// already been committed.
+func (s *Server) enqueueRecipeRefresh(recipeID string, regenerateRich bool) {
+	if s.asynqClient == nil {
+		return
+	}
+
+	task, err := worker.NewGenerateEmbeddingTask(worker.GenerateEmbeddingPayload{RecipeID: recipeID})
+	if err == nil {
+		_, err = s.asynqClient.Enqueue(task)
+	}
+	if err != nil {
+		slog.Error("Failed to enqueue embedding for edited recipe", "error", err, "recipe_id", recipeID)
+	}
+
+	if !regenerateRich {
+		return
+	}
+	task, err = worker.NewGenerateRichInstructionsTask(worker.GenerateRichInstructionsPayload{RecipeID: recipeID})
+	if err == nil {
+		_, err = s.asynqClient.Enqueue(task)
+	}
+	if err != nil {
+		slog.Error("Failed to enqueue rich instructions for edited recipe", "error", err, "recipe_id", recipeID)
+	}
+}
+
+func revisionResponse(rev generated.RecipeRevision) RecipeRevisionResponse {
+	response := RecipeRevisionResponse{
+		ID:             uuid.UUID(rev.ID.Bytes).String(),
+		RevisionNumber: rev.RevisionNumber,
+		AuthorID:       uuid.UUID(rev.AuthorID.Bytes).String(),
+		Action:         rev.Action,
+		Message:        rev.Message.String,
+		Diff:           []RevisionChange{},
+		CreatedAt:      rev.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if rev.RestoredFrom.Valid {
+		response.RestoredFrom = &rev.RestoredFrom.Int32
+	}
+	if len(rev.Diff) > 0 {
+		if err := json.Unmarshal(rev.Diff, &response.Diff); err != nil {
+			slog.Error("Failed to unmarshal revision diff", "error", err, "revision_id", response.ID)
+		}
+	}
+	return response
+}
+
+func int4Value(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func int2Value(v *int16) pgtype.Int2 {
+	if v == nil {
+		return pgtype.Int2{}
+	}
+	return pgtype.Int2{Int16: *v, Valid: true}
+}
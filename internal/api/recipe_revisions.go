@@ -0,0 +1,75 @@
+package api
+
+import (
+	"strings"
+
+	"github.com/socialchef/remy/internal/services/revision"
+)
+
+// Revision actions
+const (
+	RevisionActionOriginal   = revision.ActionOriginal
+	RevisionActionEdit       = revision.ActionEdit
+	RevisionActionRestore    = revision.ActionRestore
+	RevisionActionRegenerate = revision.ActionRegenerate
+)
+
+// Recipe snapshots and their diffs are shared with the worker, which
+// records a revision when regeneration replaces a recipe.
+type (
+	RecipeSnapshot         = revision.Snapshot
+	SnapshotPart           = revision.Part
+	SnapshotIngredient     = revision.Ingredient
+	SnapshotInstruction    = revision.Instruction
+	SnapshotStepIngredient = revision.StepIngredient
+	RevisionChange         = revision.Change
+)
+
+// RecipeEditRequest is a partial update: fields left out keep their current
+// value. Parts, Ingredients and Instructions replace the whole list.
+type RecipeEditRequest struct {
+	RecipeName          *string                `json:"recipe_name,omitempty"`
+	Description         *string                `json:"description,omitempty"`
+	PrepTime            *int32                 `json:"prep_time,omitempty"`
+	CookingTime         *int32                 `json:"cooking_time,omitempty"`
+	OriginalServingSize *int32                 `json:"original_serving_size,omitempty"`
+	DifficultyRating    *int16                 `json:"difficulty_rating,omitempty"`
+	Parts               *[]SnapshotPart        `json:"parts,omitempty"`
+	Ingredients         *[]SnapshotIngredient  `json:"ingredients,omitempty"`
+	Instructions        *[]SnapshotInstruction `json:"instructions,omitempty"`
+	// Message is an optional note stored with the revision.
+	Message string `json:"message,omitempty"`
+}
+
+// apply returns base with the request's fields applied.
+func (req RecipeEditRequest) apply(base RecipeSnapshot) RecipeSnapshot {
+	next := base
+	if req.RecipeName != nil {
+		next.RecipeName = strings.TrimSpace(*req.RecipeName)
+	}
+	if req.Description != nil {
+		next.Description = *req.Description
+	}
+	if req.PrepTime != nil {
+		next.PrepTime = req.PrepTime
+	}
+	if req.CookingTime != nil {
+		next.CookingTime = req.CookingTime
+	}
+	if req.OriginalServingSize != nil {
+		next.OriginalServingSize = req.OriginalServingSize
+	}
+	if req.DifficultyRating != nil {
+		next.DifficultyRating = req.DifficultyRating
+	}
+	if req.Parts != nil {
+		next.Parts = *req.Parts
+	}
+	if req.Ingredients != nil {
+		next.Ingredients = *req.Ingredients
+	}
+	if req.Instructions != nil {
+		next.Instructions = *req.Instructions
+	}
+	return next
+}
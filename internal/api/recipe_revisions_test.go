@@ -0,0 +1,37 @@
+package api
+
+import (
+	"testing"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func TestRecipeEditRequest_Apply(t *testing.T) {
+	base := RecipeSnapshot{
+		RecipeName:  "Tomato Soup",
+		Description: "Quick soup",
+		PrepTime:    int32Ptr(10),
+		Ingredients: []SnapshotIngredient{{Name: "tomato", Quantity: "4"}},
+	}
+
+	name := "  Roasted Tomato Soup "
+	req := RecipeEditRequest{
+		RecipeName:  &name,
+		CookingTime: int32Ptr(30),
+	}
+
+	next := req.apply(base)
+
+	if next.RecipeName != "Roasted Tomato Soup" {
+		t.Errorf("expected trimmed name, got %q", next.RecipeName)
+	}
+	if next.Description != "Quick soup" || *next.PrepTime != 10 {
+		t.Error("expected fields left out of the request to be kept")
+	}
+	if next.CookingTime == nil || *next.CookingTime != 30 {
+		t.Error("expected cooking_time to be set")
+	}
+	if len(next.Ingredients) != 1 {
+		t.Errorf("expected ingredients to be kept, got %d", len(next.Ingredients))
+	}
+}
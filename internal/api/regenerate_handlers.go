@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/ai"
+	"github.com/socialchef/remy/internal/services/recipe"
+	"github.com/socialchef/remy/internal/services/revision"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// RegenerateRecipeRequest selects how a recipe is regenerated. Every field is
+// optional: the default is a draft from the configured provider and the
+// current prompt version.
+type RegenerateRecipeRequest struct {
+	Mode          string `json:"mode,omitempty"`
+	Provider      string `json:"provider,omitempty"`
+	PromptVersion int32  `json:"prompt_version,omitempty"`
+}
+
+// validate fills in defaults and checks the request can be run.
+func (req *RegenerateRecipeRequest) validate() error {
+	switch req.Mode {
+	case "":
+		req.Mode = worker.RegenerationModeDraft
+	case worker.RegenerationModeDraft, worker.RegenerationModeReplace:
+	default:
+		return fmt.Errorf("mode must be %q or %q", worker.RegenerationModeDraft, worker.RegenerationModeReplace)
+	}
+
+	switch recipe.ProviderType(req.Provider) {
+	case "", recipe.ProviderGroq, recipe.ProviderCerebras, recipe.ProviderOpenAI:
+	default:
+		return fmt.Errorf("unknown provider %q", req.Provider)
+	}
+
+	if req.PromptVersion == 0 {
+		req.PromptVersion = ai.RecipePromptVersion
+	}
+	if !ai.HasRecipePromptVersion(req.PromptVersion) {
+		return fmt.Errorf("prompt_version %d is not available; available versions: %v", req.PromptVersion, ai.RecipePromptVersions())
+	}
+	return nil
+}
+
+// HandleRegenerateRecipe reruns recipe generation from the recipe's stored
+// raw data. A replacing regeneration is recorded as a revision by the
+// worker, so it can be undone by restoring the one before it.
+func (s *Server) HandleRegenerateRecipe(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "recipe.regenerate", "recipe", chi.URLParam(r, "recipeID"))
+
+	var req RegenerateRecipeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+	if err := req.validate(); err != nil {
+		writeError(w, r, invalidRequest("INVALID_REGENERATION", err.Error()))
+		return
+	}
+
+	existing, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+	recipeID := uuid.UUID(existing.ID.Bytes).String()
+
+	if _, err := s.db.GetRecipeRawData(r.Context(), existing.ID); err != nil {
+		writeError(w, r, apperrors.NewConflictError("Recipe has no stored source data to regenerate from", "NO_SOURCE_DATA", "Edit the recipe instead."))
+		return
+	}
+
+	if !s.checkImportQuota(w, r, userID, 1) {
+		return
+	}
+
+	regen, err := s.db.CreateRecipeRegeneration(r.Context(), generated.CreateRecipeRegenerationParams{
+		RecipeID:      existing.ID,
+		RequestedBy:   parseUUID(userID),
+		Mode:          req.Mode,
+		Provider:      req.Provider,
+		PromptVersion: req.PromptVersion,
+	})
+	if err != nil {
+		slog.Error("Failed to create regeneration", "error", err, "recipe_id", recipeID)
+		writeError(w, r, internalError("Failed to regenerate recipe"))
+		return
+	}
+
+	task, err := worker.NewRegenerateRecipeTask(worker.RegenerateRecipePayload{
+		RegenerationID: uuid.UUID(regen.ID.Bytes).String(),
+	})
+	if err != nil {
+		writeError(w, r, internalError("Failed to create task"))
+		return
+	}
+
+	if _, err := s.asynqClient.Enqueue(task); err != nil {
+		writeError(w, r, internalError("Failed to enqueue task"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(regenerationResponse(regen))
+}
+
+func (s *Server) HandleGetRecipeRegeneration(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	regenerationID, err := uuid.Parse(chi.URLParam(r, "regenerationID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_REGENERATION_ID", "Invalid regeneration ID"))
+		return
+	}
+
+	existing, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	regen, err := s.db.GetRecipeRegeneration(r.Context(), parseUUID(regenerationID.String()))
+	if err != nil || regen.RecipeID.Bytes != existing.ID.Bytes {
+		writeError(w, r, apperrors.NewNotFoundError("Regeneration not found", "REGENERATION_NOT_FOUND", ""))
+		return
+	}
+
+	response := regenerationResponse(regen)
+	if len(regen.Draft) > 0 {
+		response.Generated = regen.Draft
+	}
+
+	if regen.Mode == worker.RegenerationModeDraft && regen.Status == "COMPLETED" && len(regen.Draft) > 0 {
+		var generatedRecipe recipe.Recipe
+		if err := json.Unmarshal(regen.Draft, &generatedRecipe); err != nil {
+			slog.Error("Failed to unmarshal regeneration draft", "error", err, "regeneration_id", response.ID)
+			writeError(w, r, internalError("Failed to read regeneration"))
+			return
+		}
+		draft := revision.FromGenerated(generatedRecipe)
+
+		current, err := revision.Load(r.Context(), s.db, existing)
+		if err != nil {
+			slog.Error("Failed to load recipe", "error", err, "recipe_id", response.RecipeID)
+			writeError(w, r, internalError("Failed to load recipe"))
+			return
+		}
+
+		diff, err := revision.Diff(current, draft)
+		if err != nil {
+			writeError(w, r, internalError("Failed to compare recipes"))
+			return
+		}
+		response.Current = &current
+		response.Draft = &draft
+		response.Diff = diff
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func regenerationResponse(regen generated.RecipeRegeneration) RecipeRegenerationResponse {
+	response := RecipeRegenerationResponse{
+		ID:            uuid.UUID(regen.ID.Bytes).String(),
+		RecipeID:      uuid.UUID(regen.RecipeID.Bytes).String(),
+		Mode:          regen.Mode,
+		Provider:      regen.Provider,
+		PromptVersion: regen.PromptVersion,
+		Status:        regen.Status,
+		Error:         apperrors.ParseDetail(regen.Error),
+		CreatedAt:     regen.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if regen.CompletedAt.Valid {
+		response.CompletedAt = regen.CompletedAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
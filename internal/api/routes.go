@@ -0,0 +1,95 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/openapi"
+)
+
+// V1Prefix is the path prefix of version 1 of the API.
+const V1Prefix = "/api/v1"
+
+// The routes from before /api/v1 were deprecated when it shipped and are
+// removed after the sunset date.
+var (
+	LegacyDeprecatedAt = time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	LegacySunset       = time.Date(2027, 4, 18, 0, 0, 0, 0, time.UTC)
+)
+
+// V1Router registers routes under /api/v1. A route whose operation in
+// openapi.json has an x-legacy-path is also served at that path, as a
+// deprecated alias, so the spec is the one place aliases are listed.
+type V1Router struct {
+	r    chi.Router
+	spec *openapi.Spec
+}
+
+// NewV1Router registers routes on r, looking up their operations in spec.
+func NewV1Router(r chi.Router, spec *openapi.Spec) V1Router {
+	return V1Router{r: r, spec: spec}
+}
+
+// Use appends middleware to the router's stack, as chi.Router.Use does.
+func (v V1Router) Use(middlewares ...func(http.Handler) http.Handler) {
+	v.r.Use(middlewares...)
+}
+
+// With returns a router whose routes also run middlewares.
+func (v V1Router) With(middlewares ...func(http.Handler) http.Handler) V1Router {
+	return V1Router{r: v.r.With(middlewares...), spec: v.spec}
+}
+
+// Group registers routes with their own middleware stack.
+func (v V1Router) Group(fn func(v V1Router)) {
+	v.r.Group(func(r chi.Router) {
+		fn(V1Router{r: r, spec: v.spec})
+	})
+}
+
+// Method registers handler for method at /api/v1 + path, and at the legacy
+// path of its operation. It panics when openapi.json does not describe the
+// route, as chi does for invalid routes, so the two cannot drift apart.
+func (v V1Router) Method(method, path string, handler http.HandlerFunc) {
+	pattern := V1Prefix + path
+	op := v.spec.Operation(method, pattern)
+	if op == nil {
+		panic(fmt.Sprintf("api: %s %s is not described in openapi.json", method, pattern))
+	}
+	v.r.Method(method, pattern, handler)
+	if op.LegacyPath != "" {
+		v.r.With(middleware.Deprecated(pattern, LegacyDeprecatedAt, LegacySunset)).Method(method, op.LegacyPath, handler)
+	}
+}
+
+func (v V1Router) Get(path string, handler http.HandlerFunc) {
+	v.Method(http.MethodGet, path, handler)
+}
+
+func (v V1Router) Post(path string, handler http.HandlerFunc) {
+	v.Method(http.MethodPost, path, handler)
+}
+
+func (v V1Router) Put(path string, handler http.HandlerFunc) {
+	v.Method(http.MethodPut, path, handler)
+}
+
+func (v V1Router) Patch(path string, handler http.HandlerFunc) {
+	v.Method(http.MethodPatch, path, handler)
+}
+
+func (v V1Router) Delete(path string, handler http.HandlerFunc) {
+	v.Method(http.MethodDelete, path, handler)
+}
+
+// pathOrQuery returns the path parameter param, or on legacy routes, which
+// took it in the query string, the query parameter legacy.
+func pathOrQuery(r *http.Request, param, legacy string) string {
+	if value := chi.URLParam(r, param); value != "" {
+		return value
+	}
+	return r.URL.Query().Get(legacy)
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/openapi"
+)
+
+func TestV1Router_LegacyAliases(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	var gotJobID string
+	r := chi.NewRouter()
+	NewV1Router(r, spec).Get("/imports/{jobID}", func(w http.ResponseWriter, r *http.Request) {
+		gotJobID = pathOrQuery(r, "jobID", "job_id")
+	})
+
+	tests := []struct {
+		name           string
+		path           string
+		wantDeprecated bool
+	}{
+		{name: "v1", path: "/api/v1/imports/job-1"},
+		{name: "legacy", path: "/api/recipe-status?job_id=job-1", wantDeprecated: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotJobID = ""
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, httptest.NewRequest("GET", tt.path, nil))
+
+			if rr.Code != http.StatusOK {
+				t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+			}
+			if gotJobID != "job-1" {
+				t.Errorf("expected job ID %q, got %q", "job-1", gotJobID)
+			}
+			if got := rr.Header().Get(middleware.DeprecationHeader) != ""; got != tt.wantDeprecated {
+				t.Errorf("expected deprecated %v, got %v", tt.wantDeprecated, got)
+			}
+			if tt.wantDeprecated && rr.Header().Get(middleware.SunsetHeader) == "" {
+				t.Error("expected a Sunset header on the legacy route")
+			}
+		})
+	}
+}
+
+func TestV1Router_PanicsOnUndescribedRoute(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("failed to load spec: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a route missing from openapi.json to panic")
+		}
+	}()
+	NewV1Router(chi.NewRouter(), spec).Get("/not-in-spec", func(w http.ResponseWriter, r *http.Request) {})
+}
@@ -11,6 +11,10 @@ type SearchRequest struct {
 	Query         string  `json:"query"`
 	Limit         int32   `json:"limit,omitempty"`
 	MinSimilarity float64 `json:"min_similarity,omitempty"`
+	// Mode selects the semantic search strategy: "single" (default) matches
+	// the recipe embedding, "max_sim" the best of each recipe's per-facet
+	// embeddings.
+	Mode string `json:"mode,omitempty"`
 	// Filter fields
 	Cuisine    []string `json:"cuisine,omitempty"`
 	MealType   []string `json:"meal_type,omitempty"`
@@ -23,12 +27,12 @@ type SearchRequest struct {
 func (s *Server) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	var req SearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidBody)
 		return
 	}
 
 	if req.Query == "" {
-		http.Error(w, "query is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_QUERY", "query is required"))
 		return
 	}
 
@@ -42,7 +46,7 @@ func (s *Server) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	results, err := s.search.SearchHybrid(r.Context(), req.Query, limit)
 	if err != nil {
 		slog.Error("SearchHybrid failed", "error", err, "query", req.Query)
-		http.Error(w, "Failed to perform search: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to perform search"))
 		return
 	}
 
@@ -62,16 +66,27 @@ func (s *Server) HandleSearch(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(results)
 }
 
+// Semantic search modes
+const (
+	SemanticModeSingle = "single"
+	SemanticModeMaxSim = "max_sim"
+)
+
 // HandleSearchSemantic performs semantic (vector) search
 func (s *Server) HandleSearchSemantic(w http.ResponseWriter, r *http.Request) {
 	var req SearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidBody)
 		return
 	}
 
 	if req.Query == "" {
-		http.Error(w, "query is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_QUERY", "query is required"))
+		return
+	}
+
+	if req.Mode != "" && req.Mode != SemanticModeSingle && req.Mode != SemanticModeMaxSim {
+		writeError(w, r, invalidRequest("INVALID_MODE", "mode must be \"single\" or \"max_sim\""))
 		return
 	}
 
@@ -82,9 +97,14 @@ func (s *Server) HandleSearchSemantic(w http.ResponseWriter, r *http.Request) {
 		limit = 50 // Max limit
 	}
 
-	results, err := s.search.SearchSemantic(r.Context(), req.Query, limit)
+	search := s.search.SearchSemantic
+	if req.Mode == SemanticModeMaxSim {
+		search = s.search.SearchMaxSim
+	}
+
+	results, err := search(r.Context(), req.Query, limit)
 	if err != nil {
-		http.Error(w, "Failed to perform search", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to perform search"))
 		return
 	}
 
@@ -96,12 +116,12 @@ func (s *Server) HandleSearchSemantic(w http.ResponseWriter, r *http.Request) {
 func (s *Server) HandleSearchByName(w http.ResponseWriter, r *http.Request) {
 	var req SearchRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, r, errInvalidBody)
 		return
 	}
 
 	if req.Query == "" {
-		http.Error(w, "query is required", http.StatusBadRequest)
+		writeError(w, r, invalidRequest("MISSING_QUERY", "query is required"))
 		return
 	}
 
@@ -114,7 +134,7 @@ func (s *Server) HandleSearchByName(w http.ResponseWriter, r *http.Request) {
 
 	results, err := s.search.SearchByName(r.Context(), req.Query, limit)
 	if err != nil {
-		http.Error(w, "Failed to perform search", http.StatusInternalServerError)
+		writeError(w, r, internalError("Failed to perform search"))
 		return
 	}
 
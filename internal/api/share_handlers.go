@@ -0,0 +1,258 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/export"
+	"github.com/socialchef/remy/internal/services/search"
+	"github.com/socialchef/remy/internal/services/share"
+)
+
+// Share link lifetimes, in days
+const (
+	defaultShareExpiryDays = 7
+	maxShareExpiryDays     = 30
+)
+
+// shareSiteName is shown as the site in link previews
+const shareSiteName = "SocialChef"
+
+type CreateRecipeShareRequest struct {
+	// ExpiresInDays defaults to 7 and may be at most 30
+	ExpiresInDays int `json:"expires_in_days,omitempty"`
+}
+
+// HandleCreateRecipeShare creates a public link to one of the user's
+// recipes.
+func (s *Server) HandleCreateRecipeShare(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "share.create", "recipe", chi.URLParam(r, "recipeID"))
+
+	var req CreateRecipeShareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultShareExpiryDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > maxShareExpiryDays {
+		writeError(w, r, invalidRequest("INVALID_EXPIRY", fmt.Sprintf("expires_in_days must be between 1 and %d", maxShareExpiryDays)))
+		return
+	}
+
+	if s.cfg.ShareLinkSecret == "" {
+		writeError(w, r, apperrors.NewUnavailableError("Share links are not available", "SHARE_LINKS_UNAVAILABLE", ""))
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	expiresAt := time.Now().Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+	rs, err := s.db.CreateRecipeShare(r.Context(), generated.CreateRecipeShareParams{
+		RecipeID:  recipe.ID,
+		CreatedBy: parseUUID(userID),
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		slog.Error("Failed to create share link", "error", err, "recipe_id", uuid.UUID(recipe.ID.Bytes).String())
+		writeError(w, r, internalError("Failed to create share link"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(s.recipeShareResponse(r, rs))
+}
+
+// HandleListRecipeShares lists a recipe's links that are still active.
+func (s *Server) HandleListRecipeShares(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	shares, err := s.db.ListActiveRecipeShares(r.Context(), recipe.ID)
+	if err != nil {
+		slog.Error("Failed to list share links", "error", err, "recipe_id", uuid.UUID(recipe.ID.Bytes).String())
+		writeError(w, r, internalError("Failed to list share links"))
+		return
+	}
+
+	response := RecipeSharesResponse{Shares: make([]RecipeShareResponse, len(shares))}
+	for i, rs := range shares {
+		response.Shares[i] = s.recipeShareResponse(r, rs)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// HandleRevokeRecipeShare revokes a share link; it stops resolving at once.
+func (s *Server) HandleRevokeRecipeShare(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "share.revoke", "recipe_share", chi.URLParam(r, "shareID"))
+
+	shareID, err := uuid.Parse(chi.URLParam(r, "shareID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_SHARE_ID", "Invalid share ID"))
+		return
+	}
+
+	recipe, ok := s.ownedRecipe(w, r, userID)
+	if !ok {
+		return
+	}
+
+	if _, err := s.db.RevokeRecipeShare(r.Context(), generated.RevokeRecipeShareParams{
+		ID:       parseUUID(shareID.String()),
+		RecipeID: recipe.ID,
+	}); err != nil {
+		writeError(w, r, errShareLinkNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleGetSharedRecipe serves the recipe behind a share link without
+// authentication. Browsers and link preview crawlers get an HTML recipe card
+// with OpenGraph and Twitter card tags; clients asking for JSON through the
+// Accept header or ?format=json get the recipe as JSON.
+func (s *Server) HandleGetSharedRecipe(w http.ResponseWriter, r *http.Request) {
+	token := chi.URLParam(r, "token")
+	shareID, err := share.Verify(s.cfg.ShareLinkSecret, token)
+	if err != nil {
+		writeError(w, r, errShareLinkNotFound)
+		return
+	}
+
+	rs, err := s.db.GetRecipeShare(r.Context(), parseUUID(shareID.String()))
+	if err != nil {
+		writeError(w, r, errShareLinkNotFound)
+		return
+	}
+	if rs.RevokedAt.Valid || !rs.ExpiresAt.Time.After(time.Now()) {
+		writeError(w, r, apperrors.NewNotFoundError("Share link has expired", "SHARE_LINK_EXPIRED", "Ask the owner for a new link.").WithStatus(http.StatusGone))
+		return
+	}
+
+	if err := s.db.RecordRecipeShareView(r.Context(), rs.ID); err != nil {
+		slog.Warn("Failed to record share link view", "error", err, "share_id", shareID.String())
+	}
+
+	thumbnailURL := search.ThumbnailURL(s.cfg, rs.ThumbnailStoragePath.String)
+
+	// Shared pages must not outlive a revoked link in shared caches
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("X-Robots-Tag", "noindex")
+
+	if wantsJSON(r) {
+		result, err := s.db.GetRecipeWithParts(r.Context(), rs.RecipeID)
+		if err != nil {
+			writeError(w, r, errRecipeNotFound)
+			return
+		}
+		recipe := newRecipeResponse(result)
+		// Viewers have no account; don't expose the owner's user ID
+		recipe.CreatedBy = ""
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(SharedRecipeResponse{
+			Recipe:       recipe,
+			ThumbnailURL: thumbnailURL,
+			ExpiresAt:    rs.ExpiresAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+		})
+		return
+	}
+
+	rec, err := export.NewLoader(s.db, s.recipeImageURL).Load(r.Context(), rs.RecipeID)
+	if err != nil {
+		slog.Error("Failed to load shared recipe", "error", err, "share_id", shareID.String())
+		writeError(w, r, internalError("Failed to load recipe"))
+		return
+	}
+	if thumbnailURL != "" {
+		rec.ImageURL = thumbnailURL
+	}
+
+	doc, err := export.RenderSharePage(rec, export.PageMeta{
+		URL:      s.shareURL(r, token),
+		SiteName: shareSiteName,
+	})
+	if err != nil {
+		slog.Error("Failed to render shared recipe", "error", err, "share_id", shareID.String())
+		writeError(w, r, internalError("Failed to render recipe"))
+		return
+	}
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Write(doc.Data)
+}
+
+func (s *Server) recipeShareResponse(r *http.Request, rs generated.RecipeShare) RecipeShareResponse {
+	token := share.Sign(s.cfg.ShareLinkSecret, uuid.UUID(rs.ID.Bytes))
+	return RecipeShareResponse{
+		ID:        uuid.UUID(rs.ID.Bytes).String(),
+		URL:       s.shareURL(r, token),
+		Token:     token,
+		ViewCount: rs.ViewCount,
+		ExpiresAt: rs.ExpiresAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+		CreatedAt: rs.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+// shareURL returns the public URL of a share link, on PUBLIC_BASE_URL or
+// else the host the request came in on.
+func (s *Server) shareURL(r *http.Request, token string) string {
+	base := s.cfg.PublicBaseURL
+	if base == "" {
+		scheme := "https"
+		if proto := r.Header.Get("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		} else if r.TLS == nil {
+			scheme = "http"
+		}
+		base = scheme + "://" + r.Host
+	}
+	return base + "/r/" + token
+}
+
+// wantsJSON reports whether a request asks for JSON rather than a web page.
+func wantsJSON(r *http.Request) bool {
+	if r.URL.Query().Get("format") == "json" {
+		return true
+	}
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
@@ -0,0 +1,104 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// streamHeartbeatInterval keeps idle streams open through proxies that
+// close quiet connections.
+const streamHeartbeatInterval = 25 * time.Second
+
+// streamRetryMillis tells clients how long to wait before reconnecting.
+const streamRetryMillis = 3000
+
+// HandleImportsStream streams the user's import and bulk import progress as
+// server-sent events. Import job updates are "progress" events and bulk
+// import updates "bulk_progress" events, both with the worker's
+// ProgressUpdate as data. Clients resume after a disconnect by sending the
+// last event ID in the Last-Event-ID header, or the last_event_id query
+// parameter where they can't set headers.
+func (s *Server) HandleImportsStream(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	if s.progress == nil {
+		writeError(w, r, apperrors.NewUnavailableError("Progress streaming is not available", "PROGRESS_STREAM_UNAVAILABLE", "Poll GET /api/v1/imports/{jobID} instead."))
+		return
+	}
+
+	// The response controller reaches through middleware that wraps the
+	// writer without implementing http.Flusher itself
+	rc := http.NewResponseController(w)
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	events, err := s.progress.Subscribe(r.Context(), userID, lastEventID)
+	if err != nil {
+		slog.Error("Failed to subscribe to progress events", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to open progress stream"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	// Stop nginx style proxies from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", streamRetryMillis)
+	if err := rc.Flush(); err != nil {
+		slog.Error("Failed to flush progress stream", "error", err, "user_id", userID)
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-s.streamsClosed:
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			rc.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := writeProgressEvent(w, event); err != nil {
+				slog.Warn("Failed to write progress event", "error", err, "user_id", userID)
+				return
+			}
+			rc.Flush()
+		}
+	}
+}
+
+func writeProgressEvent(w http.ResponseWriter, event worker.ProgressEvent) error {
+	data, err := json.Marshal(event.Update)
+	if err != nil {
+		return err
+	}
+	name := "progress"
+	if event.Update.JobID == "" && event.Update.BulkJobID != "" {
+		name = "bulk_progress"
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, name, data)
+	return err
+}
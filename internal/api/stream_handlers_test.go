@@ -0,0 +1,67 @@
+package api
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/riandyrn/otelchi"
+	otelchimetric "github.com/riandyrn/otelchi/metric"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/sentry"
+	"go.opentelemetry.io/otel"
+)
+
+// TestHandleImportsStream_ThroughMiddleware streams through the wrappers
+// cmd/server puts around every route, which must keep the writer flushable.
+func TestHandleImportsStream_ThroughMiddleware(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+	srv.SetProgressStream(&fakeProgressStream{keepOpen: true})
+	defer srv.CloseStreams()
+
+	r := chi.NewRouter()
+	r.Use(middleware.NoWriteTimeout("/api/v1/imports/stream"))
+	r.Use(middleware.RequestID)
+	r.Use(sentry.HTTPMiddleware)
+	r.Use(otelchi.Middleware("socialchef-server", otelchi.WithChiRoutes(r)))
+	metricCfg := otelchimetric.NewBaseConfig("socialchef-server", otelchimetric.WithMeterProvider(otel.GetMeterProvider()))
+	r.Use(otelchimetric.NewRequestDurationMillis(metricCfg))
+	r.Use(otelchimetric.NewRequestInFlight(metricCfg))
+	r.Use(otelchimetric.NewResponseSizeBytes(metricCfg))
+	r.Use(middleware.SecurityHeaders(config.SecurityConfig{}))
+	r.Use(middleware.LimitBody(1 << 20))
+	r.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(withUserID(r.Context(), "user-123")))
+		})
+	})
+	r.Get("/api/v1/imports/stream", srv.HandleImportsStream)
+
+	ts := httptest.NewServer(r)
+	defer ts.Close()
+
+	client := ts.Client()
+	client.Timeout = 2 * time.Second
+	resp, err := client.Get(ts.URL + "/api/v1/imports/stream")
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	// The stream stays open, so the first line only arrives if it was flushed
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	if err != nil {
+		t.Fatalf("expected the retry line to be flushed: %v", err)
+	}
+	if !strings.HasPrefix(line, "retry: ") {
+		t.Errorf("expected the retry line, got %q", line)
+	}
+}
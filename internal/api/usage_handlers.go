@@ -0,0 +1,168 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+)
+
+// Import quota response headers. They are left out for unlimited plans.
+const (
+	QuotaDailyLimitHeader       = "X-Quota-Daily-Limit"
+	QuotaDailyRemainingHeader   = "X-Quota-Daily-Remaining"
+	QuotaMonthlyLimitHeader     = "X-Quota-Monthly-Limit"
+	QuotaMonthlyRemainingHeader = "X-Quota-Monthly-Remaining"
+)
+
+// HandleGetUsage reports the user's plan, import quota usage and rate
+// limits.
+func (s *Server) HandleGetUsage(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+
+	plan, usage, err := s.importUsage(r.Context(), userID, time.Now())
+	if err != nil {
+		slog.Error("Failed to load import usage", "error", err, "user_id", userID)
+		writeError(w, r, internalError("Failed to load usage"))
+		return
+	}
+
+	response := UsageResponse{
+		Plan:    plan,
+		Imports: usage,
+	}
+	if s.cfg.RateLimit.Enabled {
+		response.RateLimits = &RateLimitsResponse{
+			Requests: RateLimitInfo{PerMinute: s.cfg.RateLimit.RequestsPerMinute, Burst: s.cfg.RateLimit.Burst},
+			Imports:  RateLimitInfo{PerMinute: s.cfg.RateLimit.ImportsPerMinute, Burst: s.cfg.RateLimit.ImportBurst},
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// checkImportQuota reports whether the user may start n more imports, and
+// responds with 429 when they may not. Quota headers are set either way.
+// Quotas are not enforced when usage cannot be loaded, so a database hiccup
+// does not block imports.
+func (s *Server) checkImportQuota(w http.ResponseWriter, r *http.Request, userID string, n int) bool {
+	now := time.Now()
+	_, usage, err := s.importUsage(r.Context(), userID, now)
+	if err != nil {
+		slog.Error("Failed to check import quota", "error", err, "user_id", userID)
+		return true
+	}
+
+	appErr, window := usage.exceeded(n)
+	if appErr == nil {
+		setQuotaHeaders(w, usage, n)
+		return true
+	}
+
+	setQuotaHeaders(w, usage, 0)
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(window.resets.Sub(now).Seconds()))))
+	writeError(w, r, appErr)
+	return false
+}
+
+// importUsage loads the user's plan and their imports this day and month.
+func (s *Server) importUsage(ctx context.Context, userID string, now time.Time) (string, ImportUsage, error) {
+	quota, err := s.db.GetUserPlanQuota(ctx, parseUUID(userID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The user's plan, or the free plan, has no quota row
+		quota = generated.PlanQuota{Plan: "free"}
+	} else if err != nil {
+		return "", ImportUsage{}, fmt.Errorf("failed to get plan quota: %w", err)
+	}
+
+	dayStart, monthStart := quotaPeriods(now)
+	counts, err := s.db.CountUserImports(ctx, generated.CountUserImportsParams{
+		UserID:     parseUUID(userID),
+		MonthStart: pgtype.Timestamptz{Time: monthStart, Valid: true},
+		DayStart:   pgtype.Timestamptz{Time: dayStart, Valid: true},
+	})
+	if err != nil {
+		return "", ImportUsage{}, fmt.Errorf("failed to count imports: %w", err)
+	}
+
+	return quota.Plan, newImportUsage(quota, counts, now), nil
+}
+
+// quotaPeriods returns the start of the UTC day and month containing now.
+func quotaPeriods(now time.Time) (dayStart, monthStart time.Time) {
+	now = now.UTC()
+	dayStart = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return dayStart, monthStart
+}
+
+func newImportUsage(quota generated.PlanQuota, counts generated.CountUserImportsRow, now time.Time) ImportUsage {
+	dayStart, monthStart := quotaPeriods(now)
+	return ImportUsage{
+		Daily:   newQuotaWindow(quota.DailyImports, counts.Daily, dayStart.AddDate(0, 0, 1)),
+		Monthly: newQuotaWindow(quota.MonthlyImports, counts.Monthly, monthStart.AddDate(0, 1, 0)),
+	}
+}
+
+func newQuotaWindow(limit pgtype.Int4, used int64, resets time.Time) QuotaWindow {
+	window := QuotaWindow{
+		Used:     int(used),
+		ResetsAt: resets.Format("2006-01-02T15:04:05Z07:00"),
+		resets:   resets,
+	}
+	if limit.Valid {
+		l := int(limit.Int32)
+		remaining := max(0, l-window.Used)
+		window.Limit = &l
+		window.Remaining = &remaining
+	}
+	return window
+}
+
+// exceeded returns the error for the window n more imports would exceed,
+// checking the month first as its wait is the longer one.
+func (u ImportUsage) exceeded(n int) (*apperrors.AppError, QuotaWindow) {
+	if u.Monthly.Remaining != nil && *u.Monthly.Remaining < n {
+		return apperrors.NewRateLimitError(
+			fmt.Sprintf("Monthly import quota of %d reached", *u.Monthly.Limit),
+			"MONTHLY_IMPORT_QUOTA_EXCEEDED",
+			"Wait for the quota to reset or upgrade your plan.",
+		), u.Monthly
+	}
+	if u.Daily.Remaining != nil && *u.Daily.Remaining < n {
+		return apperrors.NewRateLimitError(
+			fmt.Sprintf("Daily import quota of %d reached", *u.Daily.Limit),
+			"DAILY_IMPORT_QUOTA_EXCEEDED",
+			"Wait for the quota to reset or upgrade your plan.",
+		), u.Daily
+	}
+	return nil, QuotaWindow{}
+}
+
+// setQuotaHeaders reports the quota left after n more imports.
+func setQuotaHeaders(w http.ResponseWriter, usage ImportUsage, n int) {
+	if usage.Daily.Limit != nil {
+		w.Header().Set(QuotaDailyLimitHeader, strconv.Itoa(*usage.Daily.Limit))
+		w.Header().Set(QuotaDailyRemainingHeader, strconv.Itoa(max(0, *usage.Daily.Remaining-n)))
+	}
+	if usage.Monthly.Limit != nil {
+		w.Header().Set(QuotaMonthlyLimitHeader, strconv.Itoa(*usage.Monthly.Limit))
+		w.Header().Set(QuotaMonthlyRemainingHeader, strconv.Itoa(max(0, *usage.Monthly.Remaining-n)))
+	}
+}
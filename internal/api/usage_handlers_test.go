@@ -0,0 +1,122 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+func TestQuotaPeriods(t *testing.T) {
+	now := time.Date(2026, 10, 18, 23, 30, 0, 0, time.FixedZone("CEST", 2*60*60))
+
+	dayStart, monthStart := quotaPeriods(now)
+
+	if want := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC); !dayStart.Equal(want) {
+		t.Errorf("expected day start %v, got %v", want, dayStart)
+	}
+	if want := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC); !monthStart.Equal(want) {
+		t.Errorf("expected month start %v, got %v", want, monthStart)
+	}
+}
+
+func TestImportUsage_Exceeded(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	quota := generated.PlanQuota{
+		Plan:           "free",
+		DailyImports:   pgtype.Int4{Int32: 10, Valid: true},
+		MonthlyImports: pgtype.Int4{Int32: 100, Valid: true},
+	}
+
+	tests := []struct {
+		name         string
+		daily        int64
+		monthly      int64
+		n            int
+		expectedCode string
+	}{
+		{name: "Within quota", daily: 3, monthly: 40, n: 1},
+		{name: "Last daily import", daily: 9, monthly: 40, n: 1},
+		{name: "Daily quota reached", daily: 10, monthly: 40, n: 1, expectedCode: "DAILY_IMPORT_QUOTA_EXCEEDED"},
+		{name: "Bulk import over daily quota", daily: 5, monthly: 40, n: 6, expectedCode: "DAILY_IMPORT_QUOTA_EXCEEDED"},
+		{name: "Monthly quota reached", daily: 10, monthly: 100, n: 1, expectedCode: "MONTHLY_IMPORT_QUOTA_EXCEEDED"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			usage := newImportUsage(quota, generated.CountUserImportsRow{Daily: tt.daily, Monthly: tt.monthly}, now)
+			appErr, _ := usage.exceeded(tt.n)
+
+			if tt.expectedCode == "" {
+				if appErr != nil {
+					t.Errorf("expected no error, got %v", appErr)
+				}
+				return
+			}
+			if appErr == nil || appErr.ErrorCode != tt.expectedCode {
+				t.Fatalf("expected %s, got %v", tt.expectedCode, appErr)
+			}
+			if appErr.StatusCode != http.StatusTooManyRequests {
+				t.Errorf("expected status 429, got %d", appErr.StatusCode)
+			}
+		})
+	}
+}
+
+func TestImportUsage_Unlimited(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	usage := newImportUsage(generated.PlanQuota{Plan: "unlimited"}, generated.CountUserImportsRow{Daily: 500, Monthly: 5000}, now)
+
+	if appErr, _ := usage.exceeded(100); appErr != nil {
+		t.Errorf("expected no limit, got %v", appErr)
+	}
+	if usage.Daily.Limit != nil || usage.Daily.Used != 500 {
+		t.Errorf("expected unlimited daily window with 500 used, got %+v", usage.Daily)
+	}
+	if usage.Monthly.ResetsAt != "2026-11-01T00:00:00Z" {
+		t.Errorf("expected monthly reset 2026-11-01T00:00:00Z, got %s", usage.Monthly.ResetsAt)
+	}
+
+	rr := httptest.NewRecorder()
+	setQuotaHeaders(rr, usage, 1)
+	if rr.Header().Get(QuotaDailyLimitHeader) != "" {
+		t.Errorf("expected no quota headers for unlimited plans")
+	}
+}
+
+func TestSetQuotaHeaders(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	quota := generated.PlanQuota{
+		Plan:           "free",
+		DailyImports:   pgtype.Int4{Int32: 10, Valid: true},
+		MonthlyImports: pgtype.Int4{Int32: 100, Valid: true},
+	}
+	usage := newImportUsage(quota, generated.CountUserImportsRow{Daily: 4, Monthly: 40}, now)
+
+	rr := httptest.NewRecorder()
+	setQuotaHeaders(rr, usage, 2)
+
+	if got := rr.Header().Get(QuotaDailyRemainingHeader); got != "4" {
+		t.Errorf("expected 4 daily imports remaining, got %s", got)
+	}
+	if got := rr.Header().Get(QuotaMonthlyRemainingHeader); got != "58" {
+		t.Errorf("expected 58 monthly imports remaining, got %s", got)
+	}
+}
+
+func TestHandleGetUsage_Unauthorized(t *testing.T) {
+	srv := NewServer(&config.Config{}, nil, nil, nil)
+
+	req := httptest.NewRequest("GET", "/api/me/usage", nil)
+	rr := httptest.NewRecorder()
+
+	srv.HandleGetUsage(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d, got %d", http.StatusUnauthorized, rr.Code)
+	}
+}
@@ -0,0 +1,493 @@
+package api
+
+import (
+	"encoding/json"
+	"time"
+
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/revision"
+)
+
+// Response bodies of /api/v1. Clients depend on these shapes, so within v1
+// they only gain optional fields: renaming, removing or retyping a field
+// belongs in a new version's file, served under its own prefix.
+
+type ImportRecipeResponse struct {
+	JobID string `json:"job_id"`
+	URL   string `json:"url"`
+}
+
+type JobStatusResponse struct {
+	ID           string            `json:"id"`
+	Status       string            `json:"status"`
+	ProgressStep string            `json:"progress_step,omitempty"`
+	Error        *apperrors.Detail `json:"error,omitempty"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+}
+
+type UserImportStatusResponse struct {
+	Jobs []JobStatusResponse `json:"jobs"`
+}
+
+type InstructionIngredientsCountResponse struct {
+	Count int `json:"count"`
+}
+
+type Timer = revision.Timer
+
+type StepIngredientDetail struct {
+	ID            string `json:"id"`
+	Name          string `json:"name"`
+	StepQuantity  string `json:"step_quantity"`  // from instruction_ingredients
+	TotalQuantity string `json:"total_quantity"` // from recipe_ingredients
+	Unit          string `json:"unit"`           // from recipe_ingredients
+}
+
+type StepDetail struct {
+	StepNumber      int32                  `json:"step_number"`
+	Instruction     string                 `json:"instruction"`
+	InstructionRich string                 `json:"instruction_rich"`
+	Ingredients     []StepIngredientDetail `json:"ingredients"`
+	Timers          []Timer                `json:"timers"`
+}
+
+type RecipeStepsResponse struct {
+	RecipeID   string       `json:"recipe_id"`
+	TotalSteps int          `json:"total_steps"`
+	Steps      []StepDetail `json:"steps"`
+}
+
+type PartSteps struct {
+	PartID       string       `json:"part_id"`
+	PartName     string       `json:"part_name"`
+	IsOptional   bool         `json:"is_optional"`
+	DisplayOrder int32        `json:"display_order"`
+	Steps        []StepDetail `json:"steps"`
+}
+
+type RecipeStepsWithPartsResponse struct {
+	RecipeID   string       `json:"recipe_id"`
+	TotalSteps int          `json:"total_steps"`
+	HasParts   bool         `json:"has_parts"`
+	Parts      []PartSteps  `json:"parts,omitempty"`
+	Steps      []StepDetail `json:"steps,omitempty"`
+}
+
+type PartIngredient struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	Quantity         string `json:"quantity,omitempty"`
+	TotalQuantity    string `json:"total_quantity,omitempty"`
+	Unit             string `json:"unit,omitempty"`
+	OriginalQuantity string `json:"original_quantity,omitempty"`
+	OriginalUnit     string `json:"original_unit,omitempty"`
+}
+
+type PartInstruction struct {
+	ID              string  `json:"id"`
+	StepNumber      int32   `json:"step_number"`
+	Instruction     string  `json:"instruction"`
+	InstructionRich string  `json:"instruction_rich,omitempty"`
+	TimerData       []Timer `json:"timers,omitempty"`
+}
+
+type RecipePartDetail struct {
+	ID           string            `json:"id"`
+	Name         string            `json:"name"`
+	Description  string            `json:"description,omitempty"`
+	DisplayOrder int32             `json:"display_order"`
+	IsOptional   bool              `json:"is_optional"`
+	PrepTime     *int32            `json:"prep_time,omitempty"`
+	CookingTime  *int32            `json:"cooking_time,omitempty"`
+	Ingredients  []PartIngredient  `json:"ingredients,omitempty"`
+	Instructions []PartInstruction `json:"instructions,omitempty"`
+}
+
+type RecipeResponse struct {
+	ID                  string             `json:"id"`
+	RecipeName          string             `json:"recipe_name"`
+	Description         string             `json:"description,omitempty"`
+	PrepTime            *int32             `json:"prep_time,omitempty"`
+	CookingTime         *int32             `json:"cooking_time,omitempty"`
+	TotalTime           *int32             `json:"total_time,omitempty"`
+	OriginalServingSize *int32             `json:"original_serving_size,omitempty"`
+	DifficultyRating    *int16             `json:"difficulty_rating,omitempty"`
+	FocusedDiet         string             `json:"focused_diet,omitempty"`
+	EstimatedCalories   *int32             `json:"estimated_calories,omitempty"`
+	Origin              string             `json:"origin"`
+	Url                 string             `json:"url,omitempty"`
+	Language            string             `json:"language,omitempty"`
+	CreatedBy           string             `json:"created_by"`
+	OwnerID             string             `json:"owner_id,omitempty"`
+	ThumbnailID         string             `json:"thumbnail_id,omitempty"`
+	IngredientNames     []string           `json:"ingredient_names,omitempty"`
+	CreatedAt           string             `json:"created_at"`
+	UpdatedAt           string             `json:"updated_at"`
+	Parts               []RecipePartDetail `json:"parts,omitempty"`
+}
+
+type BulkImportRecipeResponse struct {
+	BulkJobID string `json:"bulk_job_id"`
+	TotalURLs int    `json:"total_urls"`
+	Status    string `json:"status"`
+}
+
+type BulkImportResultItem struct {
+	URL string `json:"url"`
+	// Name is the recipe name for file imports, whose recipes may have no URL
+	Name     string            `json:"name,omitempty"`
+	Status   string            `json:"status"`
+	RecipeID string            `json:"recipe_id,omitempty"`
+	Error    *apperrors.Detail `json:"error,omitempty"`
+}
+
+type BulkImportStatusResponse struct {
+	BulkJobID string                 `json:"bulk_job_id"`
+	Status    string                 `json:"status"`
+	Progress  BulkImportProgress     `json:"progress"`
+	Results   []BulkImportResultItem `json:"results,omitempty"`
+	CreatedAt string                 `json:"created_at"`
+	UpdatedAt string                 `json:"updated_at"`
+}
+
+type BulkImportProgress struct {
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Success   int `json:"success"`
+	Failed    int `json:"failed"`
+}
+
+type UserBulkImportSummary struct {
+	BulkJobID    string `json:"bulk_job_id"`
+	Status       string `json:"status"`
+	TotalURLs    int    `json:"total_urls"`
+	SuccessCount int    `json:"success_count"`
+	FailedCount  int    `json:"failed_count"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type UserBulkImportsResponse struct {
+	Jobs []UserBulkImportSummary `json:"jobs"`
+}
+
+type RecipeRevisionResponse struct {
+	ID             string           `json:"id"`
+	RevisionNumber int32            `json:"revision_number"`
+	AuthorID       string           `json:"author_id"`
+	Action         string           `json:"action"`
+	RestoredFrom   *int32           `json:"restored_from,omitempty"`
+	Message        string           `json:"message,omitempty"`
+	Diff           []RevisionChange `json:"diff"`
+	Snapshot       *RecipeSnapshot  `json:"snapshot,omitempty"`
+	CreatedAt      string           `json:"created_at"`
+}
+
+type RecipeRevisionsResponse struct {
+	Revisions []RecipeRevisionResponse `json:"revisions"`
+}
+
+// RecipeEditResponse is returned by edits and restores. Revision is omitted
+// when the edit changed nothing.
+type RecipeEditResponse struct {
+	Revision *RecipeRevisionResponse `json:"revision,omitempty"`
+	Recipe   RecipeSnapshot          `json:"recipe"`
+}
+
+// RecipeRegenerationResponse describes a regeneration. Completed drafts
+// include the current recipe, the draft and the changes between them.
+type RecipeRegenerationResponse struct {
+	ID            string            `json:"id"`
+	RecipeID      string            `json:"recipe_id"`
+	Mode          string            `json:"mode"`
+	Provider      string            `json:"provider,omitempty"`
+	PromptVersion int32             `json:"prompt_version"`
+	Status        string            `json:"status"`
+	Error         *apperrors.Detail `json:"error,omitempty"`
+	Current       *RecipeSnapshot   `json:"current,omitempty"`
+	Draft         *RecipeSnapshot   `json:"draft,omitempty"`
+	Diff          []RevisionChange  `json:"diff,omitempty"`
+	// Generated is the full generated recipe, including categories and
+	// rich instructions
+	Generated   json.RawMessage `json:"generated,omitempty"`
+	CreatedAt   string          `json:"created_at"`
+	CompletedAt string          `json:"completed_at,omitempty"`
+}
+
+// RecipeExportResponse describes a bulk export. Completed exports include a
+// download link that expires after an hour; fetch the export again for a
+// new one.
+type RecipeExportResponse struct {
+	ID          string            `json:"id"`
+	Format      string            `json:"format"`
+	Status      string            `json:"status"`
+	RecipeCount int32             `json:"recipe_count"`
+	Error       *apperrors.Detail `json:"error,omitempty"`
+	DownloadURL string            `json:"download_url,omitempty"`
+	ExpiresAt   string            `json:"expires_at,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	CompletedAt string            `json:"completed_at,omitempty"`
+}
+
+// RecipeShareResponse describes a share link. Anyone with the URL can view
+// the recipe until the link expires or is revoked.
+type RecipeShareResponse struct {
+	ID        string `json:"id"`
+	URL       string `json:"url"`
+	Token     string `json:"token"`
+	ViewCount int32  `json:"view_count"`
+	ExpiresAt string `json:"expires_at"`
+	CreatedAt string `json:"created_at"`
+}
+
+type RecipeSharesResponse struct {
+	Shares []RecipeShareResponse `json:"shares"`
+}
+
+// SharedRecipeResponse is the recipe behind a share link.
+type SharedRecipeResponse struct {
+	Recipe       RecipeResponse `json:"recipe"`
+	ThumbnailURL string         `json:"thumbnail_url,omitempty"`
+	ExpiresAt    string         `json:"expires_at"`
+}
+
+// QuotaWindow is a user's import allowance for the current UTC day or
+// month. Limit and Remaining are null on unlimited plans.
+type QuotaWindow struct {
+	Limit     *int   `json:"limit"`
+	Used      int    `json:"used"`
+	Remaining *int   `json:"remaining"`
+	ResetsAt  string `json:"resets_at"`
+
+	resets time.Time
+}
+
+// ImportUsage reports a user's imports against their plan's quotas. File
+// imports do not count, as they make no scraping or AI calls.
+type ImportUsage struct {
+	Daily   QuotaWindow `json:"daily"`
+	Monthly QuotaWindow `json:"monthly"`
+}
+
+type RateLimitInfo struct {
+	PerMinute int `json:"per_minute"`
+	Burst     int `json:"burst"`
+}
+
+type RateLimitsResponse struct {
+	Requests RateLimitInfo `json:"requests"`
+	Imports  RateLimitInfo `json:"imports"`
+}
+
+type UsageResponse struct {
+	Plan       string              `json:"plan"`
+	Imports    ImportUsage         `json:"imports"`
+	RateLimits *RateLimitsResponse `json:"rate_limits,omitempty"`
+}
+
+// WebhookResponse describes a webhook subscription. Secret is only returned
+// when the subscription is created.
+type WebhookResponse struct {
+	ID          string   `json:"id"`
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description,omitempty"`
+	Active      bool     `json:"active"`
+	Secret      string   `json:"secret,omitempty"`
+	CreatedAt   string   `json:"created_at"`
+}
+
+type WebhooksResponse struct {
+	Webhooks []WebhookResponse `json:"webhooks"`
+}
+
+// WebhookDeliveryResponse is one entry of a subscription's delivery log.
+type WebhookDeliveryResponse struct {
+	ID             string            `json:"id"`
+	EventID        string            `json:"event_id"`
+	Event          string            `json:"event"`
+	Status         string            `json:"status"`
+	Attempts       int32             `json:"attempts"`
+	ResponseStatus int32             `json:"response_status,omitempty"`
+	Error          *apperrors.Detail `json:"error,omitempty"`
+	RedeliveryOf   string            `json:"redelivery_of,omitempty"`
+	DeliveredAt    string            `json:"delivered_at,omitempty"`
+	CreatedAt      string            `json:"created_at"`
+}
+
+type WebhookDeliveriesResponse struct {
+	Deliveries []WebhookDeliveryResponse `json:"deliveries"`
+}
+
+// AccountExportResponse describes an export of everything stored about the
+// user. Completed exports include a download link that expires after an
+// hour; fetch the export again for a new one.
+type AccountExportResponse struct {
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Error       *apperrors.Detail `json:"error,omitempty"`
+	DownloadURL string            `json:"download_url,omitempty"`
+	ExpiresAt   string            `json:"expires_at,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	CompletedAt string            `json:"completed_at,omitempty"`
+}
+
+// AccountErasureResponse describes an erasure of the user's data. Summary
+// counts what was removed, by kind, once it completes.
+type AccountErasureResponse struct {
+	ID          string            `json:"id"`
+	Status      string            `json:"status"`
+	Summary     map[string]int64  `json:"summary,omitempty"`
+	Error       *apperrors.Detail `json:"error,omitempty"`
+	CreatedAt   string            `json:"created_at"`
+	CompletedAt string            `json:"completed_at,omitempty"`
+}
+
+// AuditEventResponse is one data-changing request from the audit log.
+// Impersonator is the service that acted for the user, if any.
+type AuditEventResponse struct {
+	ID           string `json:"id"`
+	UserID       string `json:"user_id,omitempty"`
+	Actor        string `json:"actor"`
+	Impersonator string `json:"impersonator,omitempty"`
+	Action       string `json:"action"`
+	ResourceType string `json:"resource_type,omitempty"`
+	ResourceID   string `json:"resource_id,omitempty"`
+	Method       string `json:"method"`
+	Path         string `json:"path"`
+	RequestID    string `json:"request_id,omitempty"`
+	Outcome      string `json:"outcome"`
+	StatusCode   int32  `json:"status_code"`
+	CreatedAt    string `json:"created_at"`
+}
+
+type AuditEventsResponse struct {
+	Events []AuditEventResponse `json:"events"`
+	// NextBefore is the `before` value for the next page, empty on the last
+	NextBefore string `json:"next_before,omitempty"`
+}
+
+// EmbeddingBackfillProgress reports how much of the corpus is embedded with
+// the current model and composition version.
+type EmbeddingBackfillProgress struct {
+	Model           string  `json:"model"`
+	Version         int     `json:"version"`
+	Total           int64   `json:"total"`
+	UpToDate        int64   `json:"up_to_date"`
+	Outdated        int64   `json:"outdated"`
+	Missing         int64   `json:"missing"`
+	PercentComplete float64 `json:"percent_complete"`
+}
+
+type EmbeddingBackfillResponse struct {
+	TaskID   string                    `json:"task_id"`
+	Status   string                    `json:"status"`
+	Progress EmbeddingBackfillProgress `json:"progress"`
+}
+
+type UserPlanResponse struct {
+	UserID    string `json:"user_id"`
+	Plan      string `json:"plan"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type AdminImportJob struct {
+	ID           string            `json:"id"`
+	JobID        string            `json:"job_id"`
+	UserID       string            `json:"user_id"`
+	URL          string            `json:"url"`
+	Origin       string            `json:"origin"`
+	Status       string            `json:"status"`
+	ProgressStep string            `json:"progress_step,omitempty"`
+	BulkJobID    string            `json:"bulk_job_id,omitempty"`
+	Error        *apperrors.Detail `json:"error,omitempty"`
+	CreatedAt    string            `json:"created_at"`
+	UpdatedAt    string            `json:"updated_at"`
+}
+
+type AdminImportJobsResponse struct {
+	Jobs []AdminImportJob `json:"jobs"`
+	// NextBefore is the `before` value for the next page, empty on the last
+	NextBefore string `json:"next_before,omitempty"`
+}
+
+type AdminBulkImport struct {
+	BulkJobID      string `json:"bulk_job_id"`
+	UserID         string `json:"user_id"`
+	Status         string `json:"status"`
+	TotalURLs      int    `json:"total_urls"`
+	ProcessedCount int    `json:"processed_count"`
+	SuccessCount   int    `json:"success_count"`
+	FailedCount    int    `json:"failed_count"`
+	CreatedAt      string `json:"created_at"`
+	UpdatedAt      string `json:"updated_at"`
+}
+
+type AdminBulkImportsResponse struct {
+	BulkImports []AdminBulkImport `json:"bulk_imports"`
+	NextBefore  string            `json:"next_before,omitempty"`
+}
+
+// OriginJobStats summarises import outcomes for one origin, as a view of
+// provider health.
+type OriginJobStats struct {
+	Origin    string           `json:"origin"`
+	Total     int64            `json:"total"`
+	Completed int64            `json:"completed"`
+	Failed    int64            `json:"failed"`
+	Pending   int64            `json:"pending"`
+	ByStatus  map[string]int64 `json:"by_status"`
+	// FailureRate is failed over finished jobs, 0 when none finished
+	FailureRate float64 `json:"failure_rate"`
+}
+
+type AdminJobStatsResponse struct {
+	Since   string           `json:"since"`
+	Origins []OriginJobStats `json:"origins"`
+}
+
+type AdminJobActionResponse struct {
+	JobID  string `json:"job_id"`
+	Status string `json:"status"`
+}
+
+type QueueStats struct {
+	Queue          string  `json:"queue"`
+	Paused         bool    `json:"paused"`
+	Size           int     `json:"size"`
+	Pending        int     `json:"pending"`
+	Active         int     `json:"active"`
+	Scheduled      int     `json:"scheduled"`
+	Retry          int     `json:"retry"`
+	Archived       int     `json:"archived"`
+	Completed      int     `json:"completed"`
+	ProcessedToday int     `json:"processed_today"`
+	FailedToday    int     `json:"failed_today"`
+	LatencySeconds float64 `json:"latency_seconds"`
+}
+
+type QueuesResponse struct {
+	Queues []QueueStats `json:"queues"`
+}
+
+// DeadTask is a task that used up its retries and was archived.
+type DeadTask struct {
+	ID           string          `json:"id"`
+	Type         string          `json:"type"`
+	Payload      json.RawMessage `json:"payload,omitempty"`
+	LastError    string          `json:"last_error"`
+	LastFailedAt string          `json:"last_failed_at,omitempty"`
+	Retried      int             `json:"retried"`
+	MaxRetry     int             `json:"max_retry"`
+}
+
+type DeadTasksResponse struct {
+	Queue string     `json:"queue"`
+	Tasks []DeadTask `json:"tasks"`
+}
+
+type MaintenanceTaskResponse struct {
+	Task   string `json:"task"`
+	TaskID string `json:"task_id"`
+	Status string `json:"status"`
+}
@@ -0,0 +1,370 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/middleware"
+	"github.com/socialchef/remy/internal/services/webhook"
+	"github.com/socialchef/remy/internal/worker"
+)
+
+// Delivery log page sizes
+const (
+	defaultWebhookDeliveriesLimit = 50
+	maxWebhookDeliveriesLimit     = 200
+)
+
+type CreateWebhookRequest struct {
+	URL         string   `json:"url"`
+	Events      []string `json:"events"`
+	Description string   `json:"description,omitempty"`
+}
+
+// HandleCreateWebhook subscribes a URL to events on the user's imports and
+// recipes.
+func (s *Server) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	s.createWebhook(w, r, parseUUID(userID))
+}
+
+// HandleListWebhooks lists the user's webhook subscriptions.
+func (s *Server) HandleListWebhooks(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	s.listWebhooks(w, r, parseUUID(userID))
+}
+
+// HandleDeleteWebhook removes one of the user's subscriptions and its
+// delivery log.
+func (s *Server) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	s.deleteWebhook(w, r, parseUUID(userID))
+}
+
+// HandleListWebhookDeliveries lists the latest deliveries of one of the
+// user's subscriptions, newest first.
+func (s *Server) HandleListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	s.listWebhookDeliveries(w, r, parseUUID(userID))
+}
+
+// HandleRedeliverWebhook sends a logged event again as a new delivery.
+func (s *Server) HandleRedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserID(r.Context())
+	if !ok {
+		writeError(w, r, errUnauthorized)
+		return
+	}
+	s.redeliverWebhook(w, r, parseUUID(userID))
+}
+
+// Service wide subscriptions receive the events of every user. They are
+// managed through the admin API for sibling services that would otherwise
+// poll for results.
+
+// HandleCreateServiceWebhook creates a service wide subscription.
+func (s *Server) HandleCreateServiceWebhook(w http.ResponseWriter, r *http.Request) {
+	s.createWebhook(w, r, pgtype.UUID{})
+}
+
+// HandleListServiceWebhooks lists the service wide subscriptions.
+func (s *Server) HandleListServiceWebhooks(w http.ResponseWriter, r *http.Request) {
+	s.listWebhooks(w, r, pgtype.UUID{})
+}
+
+// HandleDeleteServiceWebhook removes a service wide subscription.
+func (s *Server) HandleDeleteServiceWebhook(w http.ResponseWriter, r *http.Request) {
+	s.deleteWebhook(w, r, pgtype.UUID{})
+}
+
+// HandleListServiceWebhookDeliveries lists a service wide subscription's
+// deliveries.
+func (s *Server) HandleListServiceWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	s.listWebhookDeliveries(w, r, pgtype.UUID{})
+}
+
+// HandleRedeliverServiceWebhook redelivers an event to a service wide
+// subscription.
+func (s *Server) HandleRedeliverServiceWebhook(w http.ResponseWriter, r *http.Request) {
+	s.redeliverWebhook(w, r, pgtype.UUID{})
+}
+
+func (s *Server) createWebhook(w http.ResponseWriter, r *http.Request, owner pgtype.UUID) {
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, r, errInvalidBody)
+		return
+	}
+	if err := s.validateWebhookURL(r.Context(), req.URL); err != nil {
+		writeError(w, r, invalidRequest("INVALID_WEBHOOK_URL", err.Error()))
+		return
+	}
+	events, err := normalizeWebhookEvents(req.Events)
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_WEBHOOK_EVENTS", err.Error()))
+		return
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		writeError(w, r, internalError("Failed to create webhook secret"))
+		return
+	}
+
+	sub, err := s.db.CreateWebhookSubscription(r.Context(), generated.CreateWebhookSubscriptionParams{
+		UserID:      owner,
+		Url:         req.URL,
+		Secret:      secret,
+		Events:      events,
+		Description: pgtype.Text{String: req.Description, Valid: req.Description != ""},
+	})
+	if err != nil {
+		slog.Error("Failed to create webhook", "error", err)
+		writeError(w, r, internalError("Failed to create webhook"))
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "webhook.create", "webhook", uuid.UUID(sub.ID.Bytes).String())
+
+	response := webhookResponse(sub)
+	response.Secret = sub.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) listWebhooks(w http.ResponseWriter, r *http.Request, owner pgtype.UUID) {
+	subs, err := s.db.ListWebhookSubscriptions(r.Context(), owner)
+	if err != nil {
+		slog.Error("Failed to list webhooks", "error", err)
+		writeError(w, r, internalError("Failed to list webhooks"))
+		return
+	}
+
+	response := WebhooksResponse{Webhooks: make([]WebhookResponse, len(subs))}
+	for i, sub := range subs {
+		response.Webhooks[i] = webhookResponse(sub)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) deleteWebhook(w http.ResponseWriter, r *http.Request, owner pgtype.UUID) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_WEBHOOK_ID", "Invalid webhook ID"))
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "webhook.delete", "webhook", webhookID.String())
+
+	if _, err := s.db.DeleteWebhookSubscription(r.Context(), generated.DeleteWebhookSubscriptionParams{
+		ID:     parseUUID(webhookID.String()),
+		UserID: owner,
+	}); err != nil {
+		writeError(w, r, errWebhookNotFound)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *Server) listWebhookDeliveries(w http.ResponseWriter, r *http.Request, owner pgtype.UUID) {
+	limit := defaultWebhookDeliveriesLimit
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxWebhookDeliveriesLimit {
+			writeError(w, r, invalidRequest("INVALID_LIMIT", fmt.Sprintf("limit must be between 1 and %d", maxWebhookDeliveriesLimit)))
+			return
+		}
+		limit = n
+	}
+
+	sub, ok := s.ownedWebhook(w, r, owner)
+	if !ok {
+		return
+	}
+
+	deliveries, err := s.db.ListWebhookDeliveries(r.Context(), generated.ListWebhookDeliveriesParams{
+		SubscriptionID: sub.ID,
+		Limit:          int32(limit),
+	})
+	if err != nil {
+		slog.Error("Failed to list webhook deliveries", "error", err, "webhook_id", uuid.UUID(sub.ID.Bytes).String())
+		writeError(w, r, internalError("Failed to list webhook deliveries"))
+		return
+	}
+
+	response := WebhookDeliveriesResponse{Deliveries: make([]WebhookDeliveryResponse, len(deliveries))}
+	for i, d := range deliveries {
+		response.Deliveries[i] = webhookDeliveryResponse(d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func (s *Server) redeliverWebhook(w http.ResponseWriter, r *http.Request, owner pgtype.UUID) {
+	deliveryID, err := uuid.Parse(chi.URLParam(r, "deliveryID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_DELIVERY_ID", "Invalid delivery ID"))
+		return
+	}
+	middleware.SetAuditAction(r.Context(), "webhook.redeliver", "webhook_delivery", deliveryID.String())
+
+	sub, ok := s.ownedWebhook(w, r, owner)
+	if !ok {
+		return
+	}
+
+	original, err := s.db.GetWebhookDelivery(r.Context(), parseUUID(deliveryID.String()))
+	if err != nil || original.SubscriptionID != sub.ID {
+		writeError(w, r, apperrors.NewNotFoundError("Delivery not found", "WEBHOOK_DELIVERY_NOT_FOUND", ""))
+		return
+	}
+
+	// The redelivery keeps the event ID so receivers can tell it is the same
+	// event
+	delivery, err := s.db.CreateWebhookDelivery(r.Context(), generated.CreateWebhookDeliveryParams{
+		SubscriptionID: sub.ID,
+		EventID:        original.EventID,
+		Event:          original.Event,
+		Payload:        original.Payload,
+		RedeliveryOf:   original.ID,
+	})
+	if err != nil {
+		slog.Error("Failed to create webhook redelivery", "error", err, "delivery_id", deliveryID.String())
+		writeError(w, r, internalError("Failed to redeliver webhook"))
+		return
+	}
+
+	if err := worker.EnqueueWebhookDelivery(s.asynqClient, delivery.ID); err != nil {
+		slog.Error("Failed to enqueue webhook redelivery", "error", err, "delivery_id", deliveryID.String())
+		writeError(w, r, internalError("Failed to redeliver webhook"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(webhookDeliveryResponse(delivery))
+}
+
+// ownedWebhook loads the subscription in the webhookID URL parameter and
+// checks it belongs to owner, writing the error response if not.
+func (s *Server) ownedWebhook(w http.ResponseWriter, r *http.Request, owner pgtype.UUID) (generated.WebhookSubscription, bool) {
+	webhookID, err := uuid.Parse(chi.URLParam(r, "webhookID"))
+	if err != nil {
+		writeError(w, r, invalidRequest("INVALID_WEBHOOK_ID", "Invalid webhook ID"))
+		return generated.WebhookSubscription{}, false
+	}
+
+	sub, err := s.db.GetWebhookSubscription(r.Context(), parseUUID(webhookID.String()))
+	if err != nil || sub.UserID != owner {
+		writeError(w, r, errWebhookNotFound)
+		return generated.WebhookSubscription{}, false
+	}
+	return sub, true
+}
+
+// validateWebhookURL requires an absolute https URL whose host resolves to
+// public addresses only. Development allows plain http and local receivers.
+func (s *Server) validateWebhookURL(ctx context.Context, raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return fmt.Errorf("url must be an absolute URL")
+	}
+	if s.cfg.Env == "development" {
+		if u.Scheme != "https" && u.Scheme != "http" {
+			return fmt.Errorf("url must use https")
+		}
+		return nil
+	}
+	if u.Scheme != "https" {
+		return fmt.Errorf("url must use https")
+	}
+	if err := webhook.CheckHost(ctx, u.Hostname()); err != nil {
+		if errors.Is(err, webhook.ErrPrivateAddress) {
+			return fmt.Errorf("url must point to a public address")
+		}
+		return fmt.Errorf("url host could not be resolved")
+	}
+	return nil
+}
+
+// normalizeWebhookEvents checks the requested events and drops duplicates.
+func normalizeWebhookEvents(events []string) ([]string, error) {
+	if len(events) == 0 {
+		return nil, fmt.Errorf("events must name at least one of: %s", strings.Join(webhook.Events, ", "))
+	}
+	seen := make(map[string]bool, len(events))
+	var result []string
+	for _, event := range events {
+		if !webhook.IsEvent(event) {
+			return nil, fmt.Errorf("unknown event %q, expected one of: %s", event, strings.Join(webhook.Events, ", "))
+		}
+		if !seen[event] {
+			seen[event] = true
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}
+
+func webhookResponse(sub generated.WebhookSubscription) WebhookResponse {
+	return WebhookResponse{
+		ID:          uuid.UUID(sub.ID.Bytes).String(),
+		URL:         sub.Url,
+		Events:      sub.Events,
+		Description: sub.Description.String,
+		Active:      sub.Active,
+		CreatedAt:   sub.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
+
+func webhookDeliveryResponse(d generated.WebhookDelivery) WebhookDeliveryResponse {
+	response := WebhookDeliveryResponse{
+		ID:             uuid.UUID(d.ID.Bytes).String(),
+		EventID:        uuid.UUID(d.EventID.Bytes).String(),
+		Event:          d.Event,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		ResponseStatus: d.ResponseStatus.Int32,
+		Error:          apperrors.ParseDetail(d.Error),
+		CreatedAt:      d.CreatedAt.Time.Format("2006-01-02T15:04:05Z07:00"),
+	}
+	if d.RedeliveryOf.Valid {
+		response.RedeliveryOf = uuid.UUID(d.RedeliveryOf.Bytes).String()
+	}
+	if d.DeliveredAt.Valid {
+		response.DeliveredAt = d.DeliveredAt.Time.Format("2006-01-02T15:04:05Z07:00")
+	}
+	return response
+}
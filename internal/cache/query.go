@@ -0,0 +1,149 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"log/slog"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/socialchef/remy/internal/metrics"
+)
+
+// Default TTLs for search query caching. Embeddings are deterministic for a
+// given model, so they live longer than LLM query expansions.
+const (
+	QueryExpansionTTL = 24 * time.Hour
+	QueryEmbeddingTTL = 7 * 24 * time.Hour
+)
+
+// Cache names reported on the cache hit/miss metrics.
+const (
+	metricQueryExpansion = "query_expansion"
+	metricQueryEmbedding = "query_embedding"
+)
+
+// QueryCache provides Redis-backed caching for search query expansions and
+// query embeddings, keyed by normalized query and model.
+type QueryCache struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewQueryCache creates a new query cache with the given Redis client.
+func NewQueryCache(client *redis.Client) *QueryCache {
+	return &QueryCache{
+		client: client,
+		prefix: "search:",
+	}
+}
+
+// NormalizeQuery lower-cases a query and collapses whitespace so trivially
+// different spellings of the same query share a cache entry.
+func NormalizeQuery(query string) string {
+	return strings.Join(strings.Fields(strings.ToLower(query)), " ")
+}
+
+// makeKey creates a cache key from the kind, model and normalized query.
+func (c *QueryCache) makeKey(kind, model, query string) string {
+	hash := sha256.Sum256([]byte(NormalizeQuery(query)))
+	return fmt.Sprintf("%s%s:%s:%x", c.prefix, kind, model, hash)
+}
+
+// GetExpansion retrieves a cached query expansion. The second return value
+// reports whether the cache had an entry.
+func (c *QueryCache) GetExpansion(ctx context.Context, query, model string) (string, bool) {
+	if c == nil || c.client == nil {
+		return "", false
+	}
+
+	data, err := c.client.Get(ctx, c.makeKey("expansion", model, query)).Result()
+	if err != nil {
+		if err != redis.Nil {
+			slog.Warn("Redis cache get failed", "error", err)
+		}
+		metrics.RecordCacheLookup(ctx, metricQueryExpansion, false)
+		return "", false
+	}
+
+	metrics.RecordCacheLookup(ctx, metricQueryExpansion, true)
+	return data, true
+}
+
+// SetExpansion stores a query expansion with the given TTL.
+func (c *QueryCache) SetExpansion(ctx context.Context, query, model, expansion string, ttl time.Duration) error {
+	if c == nil || c.client == nil {
+		return nil
+	}
+
+	if err := c.client.Set(ctx, c.makeKey("expansion", model, query), expansion, ttl).Err(); err != nil {
+		slog.Warn("Redis cache set failed", "error", err)
+	}
+
+	return nil
+}
+
+// GetEmbedding retrieves a cached query embedding. The second return value
+// reports whether the cache had a usable entry.
+func (c *QueryCache) GetEmbedding(ctx context.Context, query, model string) ([]float32, bool) {
+	if c == nil || c.client == nil {
+		return nil, false
+	}
+
+	data, err := c.client.Get(ctx, c.makeKey("embedding", model, query)).Bytes()
+	if err != nil {
+		if err != redis.Nil {
+			slog.Warn("Redis cache get failed", "error", err)
+		}
+		metrics.RecordCacheLookup(ctx, metricQueryEmbedding, false)
+		return nil, false
+	}
+
+	embedding, err := decodeEmbedding(data)
+	if err != nil {
+		slog.Warn("Failed to decode cached embedding", "error", err)
+		metrics.RecordCacheLookup(ctx, metricQueryEmbedding, false)
+		return nil, false
+	}
+
+	metrics.RecordCacheLookup(ctx, metricQueryEmbedding, true)
+	return embedding, true
+}
+
+// SetEmbedding stores a query embedding with the given TTL.
+func (c *QueryCache) SetEmbedding(ctx context.Context, query, model string, embedding []float32, ttl time.Duration) error {
+	if c == nil || c.client == nil {
+		return nil
+	}
+
+	if err := c.client.Set(ctx, c.makeKey("embedding", model, query), encodeEmbedding(embedding), ttl).Err(); err != nil {
+		slog.Warn("Redis cache set failed", "error", err)
+	}
+
+	return nil
+}
+
+// encodeEmbedding packs an embedding as little-endian float32s, which is
+// roughly a third of the size of its JSON encoding.
+func encodeEmbedding(embedding []float32) []byte {
+	buf := make([]byte, 4*len(embedding))
+	for i, v := range embedding {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(v))
+	}
+	return buf
+}
+
+func decodeEmbedding(data []byte) ([]float32, error) {
+	if len(data) == 0 || len(data)%4 != 0 {
+		return nil, fmt.Errorf("invalid embedding length %d", len(data))
+	}
+	embedding := make([]float32, len(data)/4)
+	for i := range embedding {
+		embedding[i] = math.Float32frombits(binary.LittleEndian.Uint32(data[4*i:]))
+	}
+	return embedding, nil
+}
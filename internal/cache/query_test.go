@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNormalizeQuery(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{query: "Chicken Curry", want: "chicken curry"},
+		{query: "  pannenkoeken   met\tspek ", want: "pannenkoeken met spek"},
+		{query: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeQuery(tt.query); got != tt.want {
+			t.Errorf("NormalizeQuery(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestQueryCacheKeys(t *testing.T) {
+	c := NewQueryCache(nil)
+
+	if c.makeKey("embedding", "m1", "Chicken  Curry") != c.makeKey("embedding", "m1", "chicken curry") {
+		t.Error("equivalent queries should share a key")
+	}
+	if c.makeKey("embedding", "m1", "chicken curry") == c.makeKey("embedding", "m2", "chicken curry") {
+		t.Error("different models should not share a key")
+	}
+	if c.makeKey("embedding", "m1", "chicken curry") == c.makeKey("expansion", "m1", "chicken curry") {
+		t.Error("expansions and embeddings should not share a key")
+	}
+}
+
+func TestEmbeddingEncoding(t *testing.T) {
+	embedding := []float32{0.5, -1.25, 3e-7, 0}
+
+	decoded, err := decodeEmbedding(encodeEmbedding(embedding))
+	if err != nil {
+		t.Fatalf("decodeEmbedding returned error: %v", err)
+	}
+	if len(decoded) != len(embedding) {
+		t.Fatalf("expected %d values, got %d", len(embedding), len(decoded))
+	}
+	for i := range embedding {
+		if decoded[i] != embedding[i] {
+			t.Errorf("value %d = %v, want %v", i, decoded[i], embedding[i])
+		}
+	}
+
+	if _, err := decodeEmbedding([]byte{1, 2, 3}); err == nil {
+		t.Error("expected error for truncated embedding")
+	}
+}
+
+func TestQueryCacheWithoutRedis(t *testing.T) {
+	ctx := context.Background()
+	c := NewQueryCache(nil)
+
+	if err := c.SetExpansion(ctx, "q", "m", "expanded", QueryExpansionTTL); err != nil {
+		t.Errorf("SetExpansion returned error: %v", err)
+	}
+	if _, ok := c.GetExpansion(ctx, "q", "m"); ok {
+		t.Error("expected a miss without a Redis client")
+	}
+	if _, ok := c.GetEmbedding(ctx, "q", "m"); ok {
+		t.Error("expected a miss without a Redis client")
+	}
+}
@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"gopkg.in/yaml.v3"
 	"os"
+	"slices"
+	"strings"
+	"time"
 )
 
 type Config struct {
@@ -24,8 +27,23 @@ type Config struct {
 	// that present it in `Authorization: Bearer <token>` plus an
 	// `X-On-Behalf-Of: <user-uuid>` header bypass JWT verification and
 	// impersonate the target user.
+	//
+	// Deprecated: services should use scoped service API keys (cmd/apikey);
+	// the token is still accepted with every scope.
 	InternalServiceToken string
 
+	// AdminAPIToken guards the /api/admin routes. Operators present it as
+	// `Authorization: Bearer <token>`; the admin API is disabled when unset.
+	AdminAPIToken string
+
+	// ShareLinkSecret signs the tokens in public recipe share links; sharing
+	// is disabled when unset. Rotating it invalidates every existing link.
+	ShareLinkSecret string
+	// PublicBaseURL is the externally visible origin of the API, such as
+	// https://remy.example.com, used in share links and their previews.
+	// When unset it is taken from the request.
+	PublicBaseURL string
+
 	RedisURL string
 
 	OpenAIKey   string
@@ -45,8 +63,18 @@ type Config struct {
 
 	Port string
 
+	// EmbeddingBackfillSchedule is the cron spec for the worker's scheduled
+	// embedding backfill; "off" disables it.
+	EmbeddingBackfillSchedule string
+
 	Transcription    TranscriptionConfig
 	RecipeGeneration RecipeGenerationConfig
+	Search           SearchConfig
+	Embedding        EmbeddingConfig
+	RateLimit        RateLimitConfig
+	Server           ServerConfig
+	CORS             CORSConfig
+	Security         SecurityConfig
 }
 
 type TranscriptionConfig struct {
@@ -61,6 +89,119 @@ type RecipeGenerationConfig struct {
 	FallbackProvider string `yaml:"fallback_provider"`
 }
 
+// SearchConfig holds the ranking knobs for hybrid recipe search. Tune these
+// with `go run ./cmd/searcheval` before changing them in production.
+type SearchConfig struct {
+	// RankingStrategy is "linear" (weighted score blend) or "rrf"
+	// (reciprocal rank fusion of the vector and text rankings).
+	RankingStrategy string `yaml:"ranking_strategy"`
+	// VectorWeight and TextWeight weigh the vector and text signals. They
+	// are pointers so an explicit 0 turns a signal off instead of falling
+	// back to the default.
+	VectorWeight *float64 `yaml:"vector_weight"`
+	TextWeight   *float64 `yaml:"text_weight"`
+	// RRFK is the rank offset k in 1/(k+rank); higher values flatten the
+	// contribution of top-ranked results.
+	RRFK int `yaml:"rrf_k"`
+	// RerankWeight is the share of the LLM relevance score in the reranked
+	// score; the hybrid score gets the remainder. An explicit 0 keeps the
+	// hybrid order.
+	RerankWeight *float64 `yaml:"rerank_weight"`
+	// TwoPhaseTextThreshold is the minimum text similarity for a result to
+	// count as a strong keyword match in two-phase search.
+	TwoPhaseTextThreshold float64 `yaml:"two_phase_text_threshold"`
+	// TwoPhaseMinMatches is how many strong keyword matches let two-phase
+	// search skip the embedding lookup.
+	TwoPhaseMinMatches int `yaml:"two_phase_min_matches"`
+}
+
+// EmbeddingConfig controls how recipe embedding documents are composed.
+// Changing it only affects recipes embedded afterwards; trigger a backfill
+// after bumping worker.EmbeddingVersion to re-embed the rest.
+type EmbeddingConfig struct {
+	// Facets lists the recipe facets included in the overview document:
+	// cuisine, meal_types, occasions, diet, equipment, parts, ingredients and
+	// instructions. Name and description are always included.
+	Facets []string `yaml:"facets"`
+	// MaxInstructionSteps caps how many instruction steps are embedded, as
+	// later steps rarely add signal and long documents dilute it.
+	MaxInstructionSteps int `yaml:"max_instruction_steps"`
+	// MultiVector also embeds a separate ingredients document per recipe,
+	// which max-sim search scores alongside the overview.
+	MultiVector bool `yaml:"multi_vector"`
+}
+
+// RateLimitConfig sets the per-minute token bucket limits on the API.
+// Requests are limited per user; requests authenticated with the internal
+// service token share one service-wide bucket.
+type RateLimitConfig struct {
+	// Enabled is false when RATE_LIMIT_ENABLED=false
+	Enabled bool `yaml:"-"`
+	// RequestsPerMinute and Burst apply to every authenticated route
+	RequestsPerMinute int `yaml:"requests_per_minute"`
+	Burst             int `yaml:"burst"`
+	// ImportsPerMinute and ImportBurst additionally apply to the routes that
+	// start imports, which call paid scraping and AI providers
+	ImportsPerMinute int `yaml:"imports_per_minute"`
+	ImportBurst      int `yaml:"import_burst"`
+	// ServiceRequestsPerMinute and ServiceBurst apply to service token
+	// callers, which act for many users
+	ServiceRequestsPerMinute int `yaml:"service_requests_per_minute"`
+	ServiceBurst             int `yaml:"service_burst"`
+}
+
+// ServerConfig sets the HTTP server's timeouts and readiness checks.
+type ServerConfig struct {
+	// ReadTimeout bounds reading a request, body included
+	ReadTimeout time.Duration `yaml:"read_timeout"`
+	// WriteTimeout bounds writing a response; the progress stream is exempt
+	WriteTimeout time.Duration `yaml:"write_timeout"`
+	// IdleTimeout closes keep-alive connections left unused this long
+	IdleTimeout time.Duration `yaml:"idle_timeout"`
+	// ShutdownTimeout is how long in-flight requests get to finish after
+	// SIGTERM before their connections are closed
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout"`
+	// DrainPeriod is how long the server keeps serving after SIGTERM with
+	// /readyz failing, so load balancers see the failed probe and stop
+	// routing to it before it stops accepting connections. It should be
+	// longer than the probe interval.
+	DrainPeriod time.Duration `yaml:"drain_period"`
+	// ReadinessCacheTTL is how long /readyz reuses its last check results
+	ReadinessCacheTTL time.Duration `yaml:"readiness_cache_ttl"`
+	// CheckProviders adds AI provider reachability to /readyz. Providers are
+	// reported but do not make the server unready.
+	CheckProviders bool `yaml:"check_providers"`
+}
+
+// CORSConfig sets which browser origins may call the API. It only restrains
+// browsers; the apps and services calling the API directly are unaffected.
+type CORSConfig struct {
+	// AllowedOrigins lists origins such as https://app.example.com, where one
+	// "*" may stand for any text, as in https://*.example.com. No origins
+	// disables CORS. CORS_ALLOWED_ORIGINS, a comma-separated list, overrides it.
+	AllowedOrigins []string `yaml:"allowed_origins"`
+	AllowedMethods []string `yaml:"allowed_methods"`
+	AllowedHeaders []string `yaml:"allowed_headers"`
+	// AllowCredentials lets browsers send cookies; the API authenticates with
+	// the Authorization header, which does not need it. It cannot be combined
+	// with origins containing "*".
+	AllowCredentials bool `yaml:"allow_credentials"`
+	// MaxAge is how long browsers may cache a preflight response
+	MaxAge time.Duration `yaml:"max_age"`
+}
+
+// SecurityConfig sets the security headers sent with every response and the
+// size limit on request bodies.
+type SecurityConfig struct {
+	// HSTSMaxAge is the max-age of Strict-Transport-Security; 0 omits it
+	HSTSMaxAge time.Duration `yaml:"hsts_max_age"`
+	// ContentSecurityPolicy is sent with HTML pages, such as share links
+	ContentSecurityPolicy string `yaml:"content_security_policy"`
+	// MaxBodyBytes caps JSON request bodies; file uploads have their own
+	// limits
+	MaxBodyBytes int64 `yaml:"max_body_bytes"`
+}
+
 func Load() (*Config, error) {
 	cfg := &Config{
 		Env:                      os.Getenv("ENV"),
@@ -73,6 +214,9 @@ func Load() (*Config, error) {
 		SupabaseServiceRoleKey:   os.Getenv("SUPABASE_SERVICE_ROLE_KEY"),
 		RecipeStorageBucket:      os.Getenv("RECIPE_STORAGE_BUCKET"),
 		InternalServiceToken:     os.Getenv("INTERNAL_SERVICE_TOKEN"),
+		AdminAPIToken:            os.Getenv("ADMIN_API_TOKEN"),
+		ShareLinkSecret:          os.Getenv("SHARE_LINK_SECRET"),
+		PublicBaseURL:            strings.TrimSuffix(os.Getenv("PUBLIC_BASE_URL"), "/"),
 		RedisURL:                 os.Getenv("REDIS_URL"),
 		OpenAIKey:                os.Getenv("OPENAI_API_KEY"),
 		GroqKey:                  os.Getenv("GROQ_API_KEY"),
@@ -87,12 +231,19 @@ func Load() (*Config, error) {
 		OtelExporterOTLPHeaders:  os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"),
 		SentryDSN:                os.Getenv("SENTRY_DSN"),
 		Port:                     os.Getenv("PORT"),
+
+		EmbeddingBackfillSchedule: os.Getenv("EMBEDDING_BACKFILL_SCHEDULE"),
+
+		RateLimit: RateLimitConfig{Enabled: os.Getenv("RATE_LIMIT_ENABLED") != "false"},
 	}
 
 	// Load from YAML file if available
 	if err := cfg.LoadFromYAML("config.yaml"); err != nil {
 		return nil, fmt.Errorf("failed to load YAML config: %w", err)
 	}
+	if origins := os.Getenv("CORS_ALLOWED_ORIGINS"); origins != "" {
+		cfg.CORS.AllowedOrigins = splitList(origins)
+	}
 
 	// Set defaults
 	if cfg.Env == "" {
@@ -110,6 +261,9 @@ func Load() (*Config, error) {
 	if cfg.RecipeStorageBucket == "" {
 		cfg.RecipeStorageBucket = "recipes"
 	}
+	if cfg.EmbeddingBackfillSchedule == "" {
+		cfg.EmbeddingBackfillSchedule = "@every 6h"
+	}
 
 	// Set transcription defaults
 	cfg.SetTranscriptionDefaults()
@@ -117,6 +271,22 @@ func Load() (*Config, error) {
 	// Set recipe generation defaults
 	cfg.SetRecipeGenerationDefaults()
 
+	// Set search ranking defaults
+	cfg.SetSearchDefaults()
+
+	// Set embedding document defaults
+	cfg.SetEmbeddingDefaults()
+
+	// Set rate limit defaults
+	cfg.SetRateLimitDefaults()
+
+	// Set HTTP server defaults
+	cfg.SetServerDefaults()
+
+	// Set CORS and security header defaults, which depend on the environment
+	cfg.SetCORSDefaults()
+	cfg.SetSecurityDefaults()
+
 	if err := cfg.validate(); err != nil {
 		return nil, err
 	}
@@ -148,6 +318,12 @@ func (c *Config) LoadFromYAML(path string) error {
 	var yamlConfig struct {
 		Transcription    TranscriptionConfig    `yaml:"transcription"`
 		RecipeGeneration RecipeGenerationConfig `yaml:"recipe_generation"`
+		Search           SearchConfig           `yaml:"search"`
+		Embedding        EmbeddingConfig        `yaml:"embedding"`
+		RateLimit        RateLimitConfig        `yaml:"rate_limit"`
+		Server           ServerConfig           `yaml:"server"`
+		CORS             CORSConfig             `yaml:"cors"`
+		Security         SecurityConfig         `yaml:"security"`
 	}
 
 	if err := yaml.Unmarshal(data, &yamlConfig); err != nil {
@@ -176,6 +352,112 @@ func (c *Config) LoadFromYAML(path string) error {
 		c.RecipeGeneration.FallbackProvider = yamlConfig.RecipeGeneration.FallbackProvider
 	}
 
+	// Apply search ranking config; zero values keep the defaults, except
+	// for weights, which apply whenever their key is present
+	if yamlConfig.Search.RankingStrategy != "" {
+		c.Search.RankingStrategy = yamlConfig.Search.RankingStrategy
+	}
+	if yamlConfig.Search.VectorWeight != nil {
+		c.Search.VectorWeight = yamlConfig.Search.VectorWeight
+	}
+	if yamlConfig.Search.TextWeight != nil {
+		c.Search.TextWeight = yamlConfig.Search.TextWeight
+	}
+	if yamlConfig.Search.RRFK > 0 {
+		c.Search.RRFK = yamlConfig.Search.RRFK
+	}
+	if yamlConfig.Search.RerankWeight != nil {
+		c.Search.RerankWeight = yamlConfig.Search.RerankWeight
+	}
+	if yamlConfig.Search.TwoPhaseTextThreshold > 0 {
+		c.Search.TwoPhaseTextThreshold = yamlConfig.Search.TwoPhaseTextThreshold
+	}
+	if yamlConfig.Search.TwoPhaseMinMatches > 0 {
+		c.Search.TwoPhaseMinMatches = yamlConfig.Search.TwoPhaseMinMatches
+	}
+
+	// Apply embedding document config
+	if len(yamlConfig.Embedding.Facets) > 0 {
+		c.Embedding.Facets = yamlConfig.Embedding.Facets
+	}
+	if yamlConfig.Embedding.MaxInstructionSteps > 0 {
+		c.Embedding.MaxInstructionSteps = yamlConfig.Embedding.MaxInstructionSteps
+	}
+	if yamlConfig.Embedding.MultiVector {
+		c.Embedding.MultiVector = yamlConfig.Embedding.MultiVector
+	}
+
+	// Apply rate limit config; zero values keep the defaults
+	if yamlConfig.RateLimit.RequestsPerMinute > 0 {
+		c.RateLimit.RequestsPerMinute = yamlConfig.RateLimit.RequestsPerMinute
+	}
+	if yamlConfig.RateLimit.Burst > 0 {
+		c.RateLimit.Burst = yamlConfig.RateLimit.Burst
+	}
+	if yamlConfig.RateLimit.ImportsPerMinute > 0 {
+		c.RateLimit.ImportsPerMinute = yamlConfig.RateLimit.ImportsPerMinute
+	}
+	if yamlConfig.RateLimit.ImportBurst > 0 {
+		c.RateLimit.ImportBurst = yamlConfig.RateLimit.ImportBurst
+	}
+	if yamlConfig.RateLimit.ServiceRequestsPerMinute > 0 {
+		c.RateLimit.ServiceRequestsPerMinute = yamlConfig.RateLimit.ServiceRequestsPerMinute
+	}
+	if yamlConfig.RateLimit.ServiceBurst > 0 {
+		c.RateLimit.ServiceBurst = yamlConfig.RateLimit.ServiceBurst
+	}
+
+	// Apply server config; zero values keep the defaults
+	if yamlConfig.Server.ReadTimeout > 0 {
+		c.Server.ReadTimeout = yamlConfig.Server.ReadTimeout
+	}
+	if yamlConfig.Server.WriteTimeout > 0 {
+		c.Server.WriteTimeout = yamlConfig.Server.WriteTimeout
+	}
+	if yamlConfig.Server.IdleTimeout > 0 {
+		c.Server.IdleTimeout = yamlConfig.Server.IdleTimeout
+	}
+	if yamlConfig.Server.ShutdownTimeout > 0 {
+		c.Server.ShutdownTimeout = yamlConfig.Server.ShutdownTimeout
+	}
+	if yamlConfig.Server.DrainPeriod > 0 {
+		c.Server.DrainPeriod = yamlConfig.Server.DrainPeriod
+	}
+	if yamlConfig.Server.ReadinessCacheTTL > 0 {
+		c.Server.ReadinessCacheTTL = yamlConfig.Server.ReadinessCacheTTL
+	}
+	if yamlConfig.Server.CheckProviders {
+		c.Server.CheckProviders = yamlConfig.Server.CheckProviders
+	}
+
+	// Apply CORS config
+	if len(yamlConfig.CORS.AllowedOrigins) > 0 {
+		c.CORS.AllowedOrigins = yamlConfig.CORS.AllowedOrigins
+	}
+	if len(yamlConfig.CORS.AllowedMethods) > 0 {
+		c.CORS.AllowedMethods = yamlConfig.CORS.AllowedMethods
+	}
+	if len(yamlConfig.CORS.AllowedHeaders) > 0 {
+		c.CORS.AllowedHeaders = yamlConfig.CORS.AllowedHeaders
+	}
+	if yamlConfig.CORS.AllowCredentials {
+		c.CORS.AllowCredentials = yamlConfig.CORS.AllowCredentials
+	}
+	if yamlConfig.CORS.MaxAge > 0 {
+		c.CORS.MaxAge = yamlConfig.CORS.MaxAge
+	}
+
+	// Apply security config
+	if yamlConfig.Security.HSTSMaxAge > 0 {
+		c.Security.HSTSMaxAge = yamlConfig.Security.HSTSMaxAge
+	}
+	if yamlConfig.Security.ContentSecurityPolicy != "" {
+		c.Security.ContentSecurityPolicy = yamlConfig.Security.ContentSecurityPolicy
+	}
+	if yamlConfig.Security.MaxBodyBytes > 0 {
+		c.Security.MaxBodyBytes = yamlConfig.Security.MaxBodyBytes
+	}
+
 	return nil
 }
 
@@ -203,6 +485,144 @@ func (c *Config) SetRecipeGenerationDefaults() {
 	}
 }
 
+// SetSearchDefaults fills in the ranking values search shipped with: a 0.7/0.3
+// vector/text blend, a 0.4 reranker share and a 0.8 text similarity cut-off
+// (0.24 on the old 0.3-weighted scale) for two-phase search.
+func (c *Config) SetSearchDefaults() {
+	if c.Search.RankingStrategy == "" {
+		c.Search.RankingStrategy = "linear"
+	}
+	if c.Search.VectorWeight == nil {
+		c.Search.VectorWeight = Float64(0.7)
+	}
+	if c.Search.TextWeight == nil {
+		c.Search.TextWeight = Float64(0.3)
+	}
+	if c.Search.RRFK == 0 {
+		c.Search.RRFK = 60
+	}
+	if c.Search.RerankWeight == nil {
+		c.Search.RerankWeight = Float64(0.4)
+	}
+	if c.Search.TwoPhaseTextThreshold == 0 {
+		c.Search.TwoPhaseTextThreshold = 0.8
+	}
+	if c.Search.TwoPhaseMinMatches == 0 {
+		c.Search.TwoPhaseMinMatches = 3
+	}
+}
+
+// Float64 returns a pointer to v, for setting optional weights in code.
+func Float64(v float64) *float64 {
+	return &v
+}
+
+// EmbeddingFacets lists every facet the embedding document builder knows.
+var EmbeddingFacets = []string{"cuisine", "meal_types", "occasions", "diet", "equipment", "parts", "ingredients", "instructions"}
+
+// SetEmbeddingDefaults includes every facet and embeds up to 10 instruction
+// steps.
+func (c *Config) SetEmbeddingDefaults() {
+	if len(c.Embedding.Facets) == 0 {
+		c.Embedding.Facets = append([]string(nil), EmbeddingFacets...)
+	}
+	if c.Embedding.MaxInstructionSteps == 0 {
+		c.Embedding.MaxInstructionSteps = 10
+	}
+}
+
+// SetRateLimitDefaults allows 120 requests a minute with bursts of 30, 10
+// imports a minute with bursts of 5, and 1200 requests a minute with bursts
+// of 300 for service callers.
+func (c *Config) SetRateLimitDefaults() {
+	if c.RateLimit.RequestsPerMinute == 0 {
+		c.RateLimit.RequestsPerMinute = 120
+	}
+	if c.RateLimit.Burst == 0 {
+		c.RateLimit.Burst = 30
+	}
+	if c.RateLimit.ImportsPerMinute == 0 {
+		c.RateLimit.ImportsPerMinute = 10
+	}
+	if c.RateLimit.ImportBurst == 0 {
+		c.RateLimit.ImportBurst = 5
+	}
+	if c.RateLimit.ServiceRequestsPerMinute == 0 {
+		c.RateLimit.ServiceRequestsPerMinute = 1200
+	}
+	if c.RateLimit.ServiceBurst == 0 {
+		c.RateLimit.ServiceBurst = 300
+	}
+}
+
+// SetServerDefaults allows 30 seconds to read a request and 60 to write a
+// response, closes idle connections after 2 minutes, keeps serving for 15
+// seconds after failing readiness on shutdown, then gives in-flight requests
+// up to 25 seconds, and caches readiness results for 5 seconds.
+func (c *Config) SetServerDefaults() {
+	if c.Server.ReadTimeout == 0 {
+		c.Server.ReadTimeout = 30 * time.Second
+	}
+	if c.Server.WriteTimeout == 0 {
+		c.Server.WriteTimeout = 60 * time.Second
+	}
+	if c.Server.IdleTimeout == 0 {
+		c.Server.IdleTimeout = 2 * time.Minute
+	}
+	if c.Server.ShutdownTimeout == 0 {
+		c.Server.ShutdownTimeout = 25 * time.Second
+	}
+	if c.Server.DrainPeriod == 0 {
+		c.Server.DrainPeriod = 15 * time.Second
+	}
+	if c.Server.ReadinessCacheTTL == 0 {
+		c.Server.ReadinessCacheTTL = 5 * time.Second
+	}
+}
+
+// SetCORSDefaults allows any localhost origin in development and no origins
+// elsewhere, the usual methods, and the request headers the API reads.
+// Browsers may cache preflight responses for 10 minutes.
+func (c *Config) SetCORSDefaults() {
+	if len(c.CORS.AllowedOrigins) == 0 && c.Env == "development" {
+		c.CORS.AllowedOrigins = []string{"http://localhost:*", "http://127.0.0.1:*"}
+	}
+	if len(c.CORS.AllowedMethods) == 0 {
+		c.CORS.AllowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
+	}
+	if len(c.CORS.AllowedHeaders) == 0 {
+		c.CORS.AllowedHeaders = []string{"Accept", "Authorization", "Content-Type", "X-API-Key", "X-On-Behalf-Of", "X-Request-ID", "Idempotency-Key"}
+	}
+	if c.CORS.MaxAge == 0 {
+		c.CORS.MaxAge = 10 * time.Minute
+	}
+}
+
+// SetSecurityDefaults pins HTTPS for a year outside development, lets HTML
+// pages load only inline styles and images, and caps JSON bodies at 1 MiB.
+func (c *Config) SetSecurityDefaults() {
+	if c.Security.HSTSMaxAge == 0 && c.Env != "development" {
+		c.Security.HSTSMaxAge = 365 * 24 * time.Hour
+	}
+	if c.Security.ContentSecurityPolicy == "" {
+		c.Security.ContentSecurityPolicy = "default-src 'none'; img-src https: data:; style-src 'unsafe-inline'; base-uri 'none'; form-action 'none'; frame-ancestors 'none'"
+	}
+	if c.Security.MaxBodyBytes == 0 {
+		c.Security.MaxBodyBytes = 1 << 20
+	}
+}
+
+// splitList splits a comma-separated list, dropping empty items.
+func splitList(list string) []string {
+	var items []string
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 func (c *Config) validate() error {
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
@@ -213,5 +633,37 @@ func (c *Config) validate() error {
 	if c.RedisURL == "" {
 		return fmt.Errorf("REDIS_URL is required")
 	}
+	switch c.Search.RankingStrategy {
+	case "linear", "rrf":
+	default:
+		return fmt.Errorf("search.ranking_strategy must be \"linear\" or \"rrf\", got %q", c.Search.RankingStrategy)
+	}
+	if w := c.Search.RerankWeight; w != nil && (*w < 0 || *w > 1) {
+		return fmt.Errorf("search.rerank_weight must be between 0 and 1, got %v", *w)
+	}
+	if w := c.Search.VectorWeight; w != nil && *w < 0 {
+		return fmt.Errorf("search.vector_weight must not be negative, got %v", *w)
+	}
+	if w := c.Search.TextWeight; w != nil && *w < 0 {
+		return fmt.Errorf("search.text_weight must not be negative, got %v", *w)
+	}
+	if c.Search.VectorWeight != nil && c.Search.TextWeight != nil && *c.Search.VectorWeight+*c.Search.TextWeight == 0 {
+		return fmt.Errorf("search.vector_weight and search.text_weight cannot both be 0")
+	}
+	// go-chi/cors treats an origin containing "*" anywhere as a wildcard, so
+	// a pattern such as https://*.example.com would send credentials to any
+	// matching subdomain
+	if c.CORS.AllowCredentials {
+		for _, origin := range c.CORS.AllowedOrigins {
+			if strings.Contains(origin, "*") {
+				return fmt.Errorf("cors.allowed_origins cannot contain a wildcard (%q) when cors.allow_credentials is set", origin)
+			}
+		}
+	}
+	for _, facet := range c.Embedding.Facets {
+		if !slices.Contains(EmbeddingFacets, facet) {
+			return fmt.Errorf("embedding.facets: unknown facet %q", facet)
+		}
+	}
 	return nil
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 )
 
 func TestLoadTranscriptionConfig(t *testing.T) {
@@ -120,3 +121,322 @@ func TestLoadTranscriptionConfigInvalidYAML(t *testing.T) {
 		t.Error("Expected error for invalid YAML, got nil")
 	}
 }
+
+func TestLoadSearchConfig(t *testing.T) {
+	configContent := `search:
+  ranking_strategy: rrf
+  vector_weight: 0.5
+  text_weight: 0.5
+  rrf_k: 30`
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "test_config_search.yaml")
+
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg := &Config{}
+	cfg.SetSearchDefaults()
+	err = cfg.LoadFromYAML(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+
+	if cfg.Search.RankingStrategy != "rrf" {
+		t.Errorf("Expected ranking_strategy to be 'rrf', got '%s'", cfg.Search.RankingStrategy)
+	}
+	if *cfg.Search.VectorWeight != 0.5 || *cfg.Search.TextWeight != 0.5 {
+		t.Errorf("Expected weights 0.5/0.5, got %v/%v", *cfg.Search.VectorWeight, *cfg.Search.TextWeight)
+	}
+	if cfg.Search.RRFK != 30 {
+		t.Errorf("Expected rrf_k to be 30, got %d", cfg.Search.RRFK)
+	}
+	// Unset fields keep their defaults
+	if *cfg.Search.RerankWeight != 0.4 {
+		t.Errorf("Expected rerank_weight to be 0.4 (default), got %v", *cfg.Search.RerankWeight)
+	}
+	if cfg.Search.TwoPhaseMinMatches != 3 {
+		t.Errorf("Expected two_phase_min_matches to be 3 (default), got %d", cfg.Search.TwoPhaseMinMatches)
+	}
+}
+
+func TestLoadSearchConfig_ZeroWeights(t *testing.T) {
+	configContent := `search:
+  text_weight: 0
+  rerank_weight: 0`
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "test_config_search_zero.yaml")
+
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg := &Config{}
+	cfg.SetSearchDefaults()
+	err = cfg.LoadFromYAML(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+	// Defaults are applied again after loading, as Load does
+	cfg.SetSearchDefaults()
+
+	if *cfg.Search.TextWeight != 0 {
+		t.Errorf("Expected text_weight to be 0, got %v", *cfg.Search.TextWeight)
+	}
+	if *cfg.Search.RerankWeight != 0 {
+		t.Errorf("Expected rerank_weight to be 0, got %v", *cfg.Search.RerankWeight)
+	}
+	if *cfg.Search.VectorWeight != 0.7 {
+		t.Errorf("Expected vector_weight to be 0.7 (default), got %v", *cfg.Search.VectorWeight)
+	}
+}
+
+func TestValidateSearchRankingStrategy(t *testing.T) {
+	cfg := &Config{
+		DatabaseURL: "postgres://localhost/remy",
+		SupabaseURL: "http://localhost:54321",
+		RedisURL:    "redis://localhost:6379",
+	}
+	cfg.SetSearchDefaults()
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected defaults to be valid, got: %v", err)
+	}
+
+	cfg.Search.RankingStrategy = "bm25"
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for unknown ranking strategy, got nil")
+	}
+}
+
+func TestValidateSearchWeights(t *testing.T) {
+	cfg := &Config{
+		DatabaseURL: "postgres://localhost/remy",
+		SupabaseURL: "http://localhost:54321",
+		RedisURL:    "redis://localhost:6379",
+	}
+	cfg.SetSearchDefaults()
+
+	cfg.Search.TextWeight = Float64(0)
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected a zero text weight to be valid, got: %v", err)
+	}
+
+	cfg.Search.VectorWeight = Float64(0)
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error when both weights are 0, got nil")
+	}
+
+	cfg.Search.VectorWeight = Float64(-0.5)
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for a negative vector weight, got nil")
+	}
+}
+
+func TestLoadEmbeddingConfig(t *testing.T) {
+	configContent := `embedding:
+  facets: [cuisine, diet, ingredients]
+  multi_vector: true`
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "test_config_embedding.yaml")
+
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg := &Config{}
+	cfg.SetEmbeddingDefaults()
+	err = cfg.LoadFromYAML(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+
+	if len(cfg.Embedding.Facets) != 3 || cfg.Embedding.Facets[1] != "diet" {
+		t.Errorf("Expected facets [cuisine diet ingredients], got %v", cfg.Embedding.Facets)
+	}
+	if !cfg.Embedding.MultiVector {
+		t.Error("Expected multi_vector to be true")
+	}
+	// Unset fields keep their defaults
+	if cfg.Embedding.MaxInstructionSteps != 10 {
+		t.Errorf("Expected max_instruction_steps to be 10 (default), got %d", cfg.Embedding.MaxInstructionSteps)
+	}
+}
+
+func TestValidateEmbeddingFacets(t *testing.T) {
+	cfg := &Config{
+		DatabaseURL: "postgres://localhost/remy",
+		SupabaseURL: "http://localhost:54321",
+		RedisURL:    "redis://localhost:6379",
+	}
+	cfg.SetSearchDefaults()
+	cfg.SetEmbeddingDefaults()
+	if err := cfg.validate(); err != nil {
+		t.Fatalf("Expected defaults to be valid, got: %v", err)
+	}
+
+	cfg.Embedding.Facets = []string{"cuisine", "allergens"}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected error for unknown embedding facet, got nil")
+	}
+}
+
+func TestLoadRateLimitConfig(t *testing.T) {
+	configContent := `rate_limit:
+  requests_per_minute: 60
+  import_burst: 2`
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "test_config_rate_limit.yaml")
+
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg := &Config{}
+	cfg.SetRateLimitDefaults()
+	err = cfg.LoadFromYAML(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+
+	if cfg.RateLimit.RequestsPerMinute != 60 {
+		t.Errorf("Expected requests_per_minute to be 60, got %d", cfg.RateLimit.RequestsPerMinute)
+	}
+	if cfg.RateLimit.ImportBurst != 2 {
+		t.Errorf("Expected import_burst to be 2, got %d", cfg.RateLimit.ImportBurst)
+	}
+	// Unset fields keep their defaults
+	if cfg.RateLimit.Burst != 30 {
+		t.Errorf("Expected burst to be 30 (default), got %d", cfg.RateLimit.Burst)
+	}
+	if cfg.RateLimit.ImportsPerMinute != 10 {
+		t.Errorf("Expected imports_per_minute to be 10 (default), got %d", cfg.RateLimit.ImportsPerMinute)
+	}
+}
+
+func TestLoadServerConfig(t *testing.T) {
+	configContent := `server:
+  write_timeout: 90s
+  drain_period: 20s
+  check_providers: true`
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "test_config_server.yaml")
+
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg := &Config{}
+	cfg.SetServerDefaults()
+	err = cfg.LoadFromYAML(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+
+	if cfg.Server.WriteTimeout != 90*time.Second {
+		t.Errorf("Expected write_timeout to be 90s, got %s", cfg.Server.WriteTimeout)
+	}
+	if cfg.Server.DrainPeriod != 20*time.Second {
+		t.Errorf("Expected drain_period to be 20s, got %s", cfg.Server.DrainPeriod)
+	}
+	if !cfg.Server.CheckProviders {
+		t.Error("Expected check_providers to be true")
+	}
+	// Unset fields keep their defaults
+	if cfg.Server.ShutdownTimeout != 25*time.Second {
+		t.Errorf("Expected shutdown_timeout to be 25s (default), got %s", cfg.Server.ShutdownTimeout)
+	}
+}
+
+func TestLoadCORSConfig(t *testing.T) {
+	configContent := `cors:
+  allowed_origins: ["https://app.socialchef.app", "https://*.socialchef.app"]
+security:
+  max_body_bytes: 2097152`
+
+	tempDir := t.TempDir()
+	configPath := filepath.Join(tempDir, "test_config_cors.yaml")
+
+	err := os.WriteFile(configPath, []byte(configContent), 0644)
+	if err != nil {
+		t.Fatalf("Failed to create test config file: %v", err)
+	}
+
+	cfg := &Config{Env: "production"}
+	err = cfg.LoadFromYAML(configPath)
+	if err != nil {
+		t.Fatalf("Failed to load YAML config: %v", err)
+	}
+	cfg.SetCORSDefaults()
+	cfg.SetSecurityDefaults()
+
+	if len(cfg.CORS.AllowedOrigins) != 2 || cfg.CORS.AllowedOrigins[1] != "https://*.socialchef.app" {
+		t.Errorf("Expected the configured origins, got %v", cfg.CORS.AllowedOrigins)
+	}
+	if cfg.Security.MaxBodyBytes != 2<<20 {
+		t.Errorf("Expected max_body_bytes to be 2 MiB, got %d", cfg.Security.MaxBodyBytes)
+	}
+	// Unset fields keep their defaults
+	if cfg.Security.HSTSMaxAge != 365*24*time.Hour {
+		t.Errorf("Expected a year of HSTS outside development, got %s", cfg.Security.HSTSMaxAge)
+	}
+	if len(cfg.CORS.AllowedMethods) == 0 || cfg.CORS.MaxAge != 10*time.Minute {
+		t.Errorf("Expected default methods and max age, got %v and %s", cfg.CORS.AllowedMethods, cfg.CORS.MaxAge)
+	}
+}
+
+func TestCORSDefaults_DependOnEnvironment(t *testing.T) {
+	dev := &Config{Env: "development"}
+	dev.SetCORSDefaults()
+	dev.SetSecurityDefaults()
+	if len(dev.CORS.AllowedOrigins) == 0 {
+		t.Error("Expected development to allow localhost origins")
+	}
+	if dev.Security.HSTSMaxAge != 0 {
+		t.Errorf("Expected no HSTS in development, got %s", dev.Security.HSTSMaxAge)
+	}
+
+	prod := &Config{Env: "production"}
+	prod.SetCORSDefaults()
+	if len(prod.CORS.AllowedOrigins) != 0 {
+		t.Errorf("Expected no origins by default in production, got %v", prod.CORS.AllowedOrigins)
+	}
+}
+
+func TestValidate_RejectsWildcardOriginWithCredentials(t *testing.T) {
+	cfg := &Config{
+		DatabaseURL: "postgres://localhost/remy",
+		SupabaseURL: "https://example.supabase.co",
+		RedisURL:    "redis://localhost:6379",
+		Search:      SearchConfig{RankingStrategy: "linear"},
+		CORS:        CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+	}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected an error for a wildcard origin with credentials")
+	}
+
+	cfg.CORS.AllowedOrigins = []string{"https://socialchef.app", "https://*.socialchef.app"}
+	if err := cfg.validate(); err == nil {
+		t.Error("Expected an error for a wildcard subdomain origin with credentials")
+	}
+
+	cfg.CORS.AllowedOrigins = []string{"https://socialchef.app"}
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected an exact origin with credentials to be valid, got %v", err)
+	}
+
+	cfg.CORS.AllowedOrigins = []string{"https://*.socialchef.app"}
+	cfg.CORS.AllowCredentials = false
+	if err := cfg.validate(); err != nil {
+		t.Errorf("Expected a wildcard origin without credentials to be valid, got %v", err)
+	}
+}
@@ -221,6 +221,59 @@ func (q *Queries) IncrementBulkImportCounters(ctx context.Context, arg Increment
 	return err
 }
 
+const listBulkImportJobs = `-- name: ListBulkImportJobs :many
+SELECT id, job_id, user_id, total_urls, processed_count, success_count, failed_count, status, summary, created_at, updated_at FROM bulk_import_jobs
+WHERE ($1::text IS NULL OR status = $1)
+AND ($2::uuid IS NULL OR user_id = $2)
+AND ($3::timestamptz IS NULL OR created_at < $3)
+ORDER BY created_at DESC
+LIMIT $4
+`
+
+type ListBulkImportJobsParams struct {
+	Status pgtype.Text
+	UserID pgtype.UUID
+	Before pgtype.Timestamptz
+	Limit  int32
+}
+
+func (q *Queries) ListBulkImportJobs(ctx context.Context, arg ListBulkImportJobsParams) ([]BulkImportJob, error) {
+	rows, err := q.db.Query(ctx, listBulkImportJobs,
+		arg.Status,
+		arg.UserID,
+		arg.Before,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []BulkImportJob
+	for rows.Next() {
+		var i BulkImportJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.TotalUrls,
+			&i.ProcessedCount,
+			&i.SuccessCount,
+			&i.FailedCount,
+			&i.Status,
+			&i.Summary,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateBulkImportJobStatus = `-- name: UpdateBulkImportJobStatus :exec
 UPDATE bulk_import_jobs 
 SET 
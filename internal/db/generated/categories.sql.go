@@ -91,6 +91,24 @@ func (q *Queries) AddRecipeOccasion(ctx context.Context, arg AddRecipeOccasionPa
 	return err
 }
 
+const clearRecipeCategories = `-- name: ClearRecipeCategories :exec
+WITH cuisine AS (
+    DELETE FROM recipe_cuisine_categories WHERE recipe_id = $1
+), meal AS (
+    DELETE FROM recipe_meal_types WHERE recipe_id = $1
+), occasion AS (
+    DELETE FROM recipe_occasions WHERE recipe_id = $1
+), diet AS (
+    DELETE FROM recipe_dietary_restrictions WHERE recipe_id = $1
+)
+DELETE FROM recipe_equipment WHERE recipe_id = $1
+`
+
+func (q *Queries) ClearRecipeCategories(ctx context.Context, recipeID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, clearRecipeCategories, recipeID)
+	return err
+}
+
 const getCuisineCategoriesByUser = `-- name: GetCuisineCategoriesByUser :many
 SELECT DISTINCT cc.name 
 FROM cuisine_categories cc
@@ -305,3 +323,58 @@ func (q *Queries) GetOrCreateOccasion(ctx context.Context, name string) (pgtype.
 	err := row.Scan(&id)
 	return id, err
 }
+
+const getRecipeCategoryNames = `-- name: GetRecipeCategoryNames :one
+SELECT
+    ARRAY(
+        SELECT cc.name FROM cuisine_categories cc
+        JOIN recipe_cuisine_categories rcc ON cc.id = rcc.cuisine_category_id
+        WHERE rcc.recipe_id = $1
+        ORDER BY cc.name
+    )::text[] AS cuisine_categories,
+    ARRAY(
+        SELECT mt.name FROM meal_types mt
+        JOIN recipe_meal_types rmt ON mt.id = rmt.meal_type_id
+        WHERE rmt.recipe_id = $1
+        ORDER BY mt.name
+    )::text[] AS meal_types,
+    ARRAY(
+        SELECT o.name FROM occasions o
+        JOIN recipe_occasions ro ON o.id = ro.occasion_id
+        WHERE ro.recipe_id = $1
+        ORDER BY o.name
+    )::text[] AS occasions,
+    ARRAY(
+        SELECT dr.name FROM dietary_restrictions dr
+        JOIN recipe_dietary_restrictions rdr ON dr.id = rdr.dietary_restriction_id
+        WHERE rdr.recipe_id = $1
+        ORDER BY dr.name
+    )::text[] AS dietary_restrictions,
+    ARRAY(
+        SELECT e.name FROM equipment e
+        JOIN recipe_equipment re ON e.id = re.equipment_id
+        WHERE re.recipe_id = $1
+        ORDER BY e.name
+    )::text[] AS equipment
+`
+
+type GetRecipeCategoryNamesRow struct {
+	CuisineCategories   []string
+	MealTypes           []string
+	Occasions           []string
+	DietaryRestrictions []string
+	Equipment           []string
+}
+
+func (q *Queries) GetRecipeCategoryNames(ctx context.Context, recipeID pgtype.UUID) (GetRecipeCategoryNamesRow, error) {
+	row := q.db.QueryRow(ctx, getRecipeCategoryNames, recipeID)
+	var i GetRecipeCategoryNamesRow
+	err := row.Scan(
+		&i.CuisineCategories,
+		&i.MealTypes,
+		&i.Occasions,
+		&i.DietaryRestrictions,
+		&i.Equipment,
+	)
+	return i, err
+}
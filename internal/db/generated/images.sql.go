@@ -12,6 +12,9 @@ import (
 )
 
 const createRecipeImage = `-- name: CreateRecipeImage :one
+WITH referenced AS (
+    UPDATE stored_images SET reference_count = reference_count + 1 WHERE id = $2
+)
 INSERT INTO recipe_images (
     recipe_id, stored_image_id, image_type
 ) VALUES (
@@ -43,7 +46,7 @@ INSERT INTO stored_images (
     id, content_hash, storage_path
 ) VALUES (
     $1, $2, $3
-) RETURNING id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at
+) RETURNING id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at, reference_count
 `
 
 type CreateStoredImageParams struct {
@@ -65,10 +68,26 @@ func (q *Queries) CreateStoredImage(ctx context.Context, arg CreateStoredImagePa
 		&i.Height,
 		&i.FileSize,
 		&i.CreatedAt,
+		&i.ReferenceCount,
 	)
 	return i, err
 }
 
+const decrementStoredImageReferences = `-- name: DecrementStoredImageReferences :exec
+UPDATE stored_images si
+SET reference_count = GREATEST(si.reference_count - released.refs, 0)
+FROM (
+    SELECT hash, COUNT(*) AS refs FROM unnest($1::text[]) AS hash GROUP BY hash
+) released
+WHERE si.content_hash = released.hash
+`
+
+// Releases one reference per occurrence of a hash in the list.
+func (q *Queries) DecrementStoredImageReferences(ctx context.Context, dollar_1 []string) error {
+	_, err := q.db.Exec(ctx, decrementStoredImageReferences, dollar_1)
+	return err
+}
+
 const deleteRecipeImages = `-- name: DeleteRecipeImages :exec
 DELETE FROM recipe_images WHERE recipe_id = $1
 `
@@ -78,8 +97,54 @@ func (q *Queries) DeleteRecipeImages(ctx context.Context, recipeID pgtype.UUID)
 	return err
 }
 
+const deleteUnreferencedStoredImages = `-- name: DeleteUnreferencedStoredImages :many
+DELETE FROM stored_images si
+WHERE si.reference_count <= 0
+  AND (
+    si.content_hash = ANY($1::text[])
+    OR (cardinality($1::text[]) = 0 AND si.created_at < NOW() - INTERVAL '1 hour')
+  )
+  AND NOT EXISTS (SELECT 1 FROM recipe_images ri WHERE ri.stored_image_id = si.id)
+  AND NOT EXISTS (SELECT 1 FROM social_media_owners o WHERE o.profile_pic_stored_image_id = si.id::text)
+RETURNING id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at, reference_count
+`
+
+// Deletes stored images that no recipe image or owner avatar uses. With an
+// empty hash list every unreferenced image older than an hour is deleted;
+// the grace period covers imports between upload and linking the image.
+func (q *Queries) DeleteUnreferencedStoredImages(ctx context.Context, dollar_1 []string) ([]StoredImage, error) {
+	rows, err := q.db.Query(ctx, deleteUnreferencedStoredImages, dollar_1)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []StoredImage
+	for rows.Next() {
+		var i StoredImage
+		if err := rows.Scan(
+			&i.ID,
+			&i.StoragePath,
+			&i.SourceUrl,
+			&i.ContentHash,
+			&i.MimeType,
+			&i.Width,
+			&i.Height,
+			&i.FileSize,
+			&i.CreatedAt,
+			&i.ReferenceCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getImagesByRecipe = `-- name: GetImagesByRecipe :many
-SELECT si.id, si.storage_path, si.source_url, si.content_hash, si.mime_type, si.width, si.height, si.file_size, si.created_at 
+SELECT si.id, si.storage_path, si.source_url, si.content_hash, si.mime_type, si.width, si.height, si.file_size, si.created_at, si.reference_count 
 FROM stored_images si 
 JOIN recipe_images ri ON si.id = ri.stored_image_id 
 WHERE ri.recipe_id = $1
@@ -104,6 +169,7 @@ func (q *Queries) GetImagesByRecipe(ctx context.Context, recipeID pgtype.UUID) (
 			&i.Height,
 			&i.FileSize,
 			&i.CreatedAt,
+			&i.ReferenceCount,
 		); err != nil {
 			return nil, err
 		}
@@ -116,7 +182,7 @@ func (q *Queries) GetImagesByRecipe(ctx context.Context, recipeID pgtype.UUID) (
 }
 
 const getStoredImageByHash = `-- name: GetStoredImageByHash :one
-SELECT id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at FROM stored_images WHERE content_hash = $1
+SELECT id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at, reference_count FROM stored_images WHERE content_hash = $1
 `
 
 func (q *Queries) GetStoredImageByHash(ctx context.Context, contentHash string) (StoredImage, error) {
@@ -132,6 +198,45 @@ func (q *Queries) GetStoredImageByHash(ctx context.Context, contentHash string)
 		&i.Height,
 		&i.FileSize,
 		&i.CreatedAt,
+		&i.ReferenceCount,
 	)
 	return i, err
 }
+
+const restoreStoredImage = `-- name: RestoreStoredImage :exec
+INSERT INTO stored_images (
+    id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at, reference_count
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, 0
+) ON CONFLICT DO NOTHING
+`
+
+type RestoreStoredImageParams struct {
+	ID          pgtype.UUID
+	StoragePath string
+	SourceUrl   string
+	ContentHash string
+	MimeType    pgtype.Text
+	Width       pgtype.Int4
+	Height      pgtype.Int4
+	FileSize    pgtype.Int8
+	CreatedAt   pgtype.Timestamptz
+}
+
+// Puts back a stored image whose blob could not be deleted, unreferenced,
+// so the next garbage collection finds it again. An image stored again
+// under the same hash in the meantime is kept.
+func (q *Queries) RestoreStoredImage(ctx context.Context, arg RestoreStoredImageParams) error {
+	_, err := q.db.Exec(ctx, restoreStoredImage,
+		arg.ID,
+		arg.StoragePath,
+		arg.SourceUrl,
+		arg.ContentHash,
+		arg.MimeType,
+		arg.Width,
+		arg.Height,
+		arg.FileSize,
+		arg.CreatedAt,
+	)
+	return err
+}
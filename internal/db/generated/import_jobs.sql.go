@@ -11,6 +11,44 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countImportJobsByOrigin = `-- name: CountImportJobsByOrigin :many
+SELECT origin, status, COUNT(*) AS count
+FROM recipe_import_jobs
+WHERE created_at >= $1::timestamptz
+GROUP BY origin, status
+ORDER BY origin, status
+`
+
+type CountImportJobsByOriginRow struct {
+	Origin string
+	Status string
+	Count  int64
+}
+
+func (q *Queries) CountImportJobsByOrigin(ctx context.Context, since pgtype.Timestamptz) ([]CountImportJobsByOriginRow, error) {
+	rows, err := q.db.Query(ctx, countImportJobsByOrigin, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []CountImportJobsByOriginRow
+	for rows.Next() {
+		var i CountImportJobsByOriginRow
+		if err := rows.Scan(
+			&i.Origin,
+			&i.Status,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createImportJob = `-- name: CreateImportJob :one
 INSERT INTO recipe_import_jobs (
     id, job_id, user_id, url, origin, status
@@ -170,6 +208,86 @@ func (q *Queries) GetImportJobsByUser(ctx context.Context, userID pgtype.UUID) (
 	return items, nil
 }
 
+const listImportJobs = `-- name: ListImportJobs :many
+SELECT id, job_id, user_id, url, origin, status, progress_step, progress_message, result, error, completed_at, created_at, updated_at, bulk_job_id FROM recipe_import_jobs
+WHERE ($1::text IS NULL OR status = $1)
+AND ($2::uuid IS NULL OR user_id = $2)
+AND ($3::text IS NULL OR origin = $3)
+AND ($4::text IS NULL OR bulk_job_id = $4)
+AND ($5::timestamptz IS NULL OR created_at < $5)
+ORDER BY created_at DESC
+LIMIT $6
+`
+
+type ListImportJobsParams struct {
+	Status    pgtype.Text
+	UserID    pgtype.UUID
+	Origin    pgtype.Text
+	BulkJobID pgtype.Text
+	Before    pgtype.Timestamptz
+	Limit     int32
+}
+
+func (q *Queries) ListImportJobs(ctx context.Context, arg ListImportJobsParams) ([]RecipeImportJob, error) {
+	rows, err := q.db.Query(ctx, listImportJobs,
+		arg.Status,
+		arg.UserID,
+		arg.Origin,
+		arg.BulkJobID,
+		arg.Before,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []RecipeImportJob
+	for rows.Next() {
+		var i RecipeImportJob
+		if err := rows.Scan(
+			&i.ID,
+			&i.JobID,
+			&i.UserID,
+			&i.Url,
+			&i.Origin,
+			&i.Status,
+			&i.ProgressStep,
+			&i.ProgressMessage,
+			&i.Result,
+			&i.Error,
+			&i.CompletedAt,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.BulkJobID,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setImportJobResult = `-- name: SetImportJobResult :exec
+UPDATE recipe_import_jobs
+SET
+    result = $2,
+    updated_at = NOW()
+WHERE job_id = $1
+`
+
+type SetImportJobResultParams struct {
+	JobID  string
+	Result []byte
+}
+
+func (q *Queries) SetImportJobResult(ctx context.Context, arg SetImportJobResultParams) error {
+	_, err := q.db.Exec(ctx, setImportJobResult, arg.JobID, arg.Result)
+	return err
+}
+
 const updateImportJobStatus = `-- name: UpdateImportJobStatus :exec
 UPDATE recipe_import_jobs 
 SET 
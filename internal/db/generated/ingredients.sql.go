@@ -77,7 +77,7 @@ func (q *Queries) DeleteIngredientsByRecipe(ctx context.Context, recipeID pgtype
 }
 
 const getIngredientsByRecipe = `-- name: GetIngredientsByRecipe :many
-SELECT id, recipe_id, quantity, total_quantity, unit, original_quantity, original_unit, name, part_id, created_at FROM recipe_ingredients WHERE recipe_id = $1
+SELECT id, recipe_id, quantity, total_quantity, unit, original_quantity, original_unit, name, part_id, created_at FROM recipe_ingredients WHERE recipe_id = $1 ORDER BY created_at, id
 `
 
 func (q *Queries) GetIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]RecipeIngredient, error) {
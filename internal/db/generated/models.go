@@ -60,6 +60,8 @@ const (
 	RecipeOriginInstagram RecipeOrigin = "instagram"
 	RecipeOriginTiktok    RecipeOrigin = "tiktok"
 	RecipeOriginFirecrawl RecipeOrigin = "firecrawl"
+	RecipeOriginManual    RecipeOrigin = "manual"
+	RecipeOriginFile      RecipeOrigin = "file"
 )
 
 func (e *RecipeOrigin) Scan(src interface{}) error {
@@ -139,6 +141,45 @@ func (ns NullSocialMediaPlatform) Value() (driver.Value, error) {
 	return string(ns.SocialMediaPlatform), nil
 }
 
+type AccountErasure struct {
+	ID          pgtype.UUID
+	UserID      pgtype.UUID
+	Status      string
+	Summary     []byte
+	Error       []byte
+	CompletedAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	ImageHashes []string
+}
+
+type AccountExport struct {
+	ID          pgtype.UUID
+	UserID      pgtype.UUID
+	Status      string
+	StoragePath pgtype.Text
+	Error       []byte
+	CompletedAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+type AuditEvent struct {
+	ID           pgtype.UUID
+	UserID       pgtype.UUID
+	Actor        string
+	Impersonator pgtype.Text
+	Action       string
+	ResourceType pgtype.Text
+	ResourceID   pgtype.Text
+	Method       string
+	Path         string
+	RequestID    pgtype.Text
+	Outcome      string
+	StatusCode   int32
+	CreatedAt    pgtype.Timestamptz
+}
+
 type BulkImportJob struct {
 	ID             pgtype.UUID
 	JobID          string
@@ -191,6 +232,14 @@ type Occasion struct {
 	CreatedAt pgtype.Timestamptz
 }
 
+type PlanQuota struct {
+	Plan           string
+	DailyImports   pgtype.Int4
+	MonthlyImports pgtype.Int4
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
 type Profile struct {
 	ID              pgtype.UUID
 	Email           pgtype.Text
@@ -226,6 +275,8 @@ type Recipe struct {
 	IngredientNames     []string
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	EmbeddingModel      pgtype.Text
+	EmbeddingVersion    pgtype.Int4
 }
 
 type RecipeCuisineCategory struct {
@@ -240,12 +291,35 @@ type RecipeDietaryRestriction struct {
 	CreatedAt            pgtype.Timestamptz
 }
 
+type RecipeEmbedding struct {
+	RecipeID  pgtype.UUID
+	Kind      string
+	Embedding pgvector_go.Vector
+	Model     string
+	Version   int32
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
 type RecipeEquipment struct {
 	RecipeID    pgtype.UUID
 	EquipmentID pgtype.UUID
 	CreatedAt   pgtype.Timestamptz
 }
 
+type RecipeExport struct {
+	ID          pgtype.UUID
+	UserID      pgtype.UUID
+	Format      string
+	Status      string
+	RecipeCount int32
+	StoragePath pgtype.Text
+	Error       []byte
+	CompletedAt pgtype.Timestamptz
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
 type RecipeImage struct {
 	ID            pgtype.UUID
 	RecipeID      pgtype.UUID
@@ -350,6 +424,58 @@ type RecipeRawDatum struct {
 	UpdatedAt      pgtype.Timestamptz
 }
 
+type RecipeRegeneration struct {
+	ID            pgtype.UUID
+	RecipeID      pgtype.UUID
+	RequestedBy   pgtype.UUID
+	Mode          string
+	Provider      string
+	PromptVersion int32
+	Status        string
+	Draft         []byte
+	Error         []byte
+	CompletedAt   pgtype.Timestamptz
+	CreatedAt     pgtype.Timestamptz
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type RecipeRevision struct {
+	ID             pgtype.UUID
+	RecipeID       pgtype.UUID
+	RevisionNumber int32
+	AuthorID       pgtype.UUID
+	Action         string
+	RestoredFrom   pgtype.Int4
+	Snapshot       []byte
+	Diff           []byte
+	Message        pgtype.Text
+	CreatedAt      pgtype.Timestamptz
+}
+
+type RecipeShare struct {
+	ID           pgtype.UUID
+	RecipeID     pgtype.UUID
+	CreatedBy    pgtype.UUID
+	ExpiresAt    pgtype.Timestamptz
+	RevokedAt    pgtype.Timestamptz
+	ViewCount    int32
+	LastViewedAt pgtype.Timestamptz
+	CreatedAt    pgtype.Timestamptz
+}
+
+type ServiceApiKey struct {
+	ID             pgtype.UUID
+	Name           string
+	KeyPrefix      string
+	KeyHash        string
+	Scopes         []string
+	AllowedUserIds []pgtype.UUID
+	ExpiresAt      pgtype.Timestamptz
+	LastUsedAt     pgtype.Timestamptz
+	RevokedAt      pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+}
+
 type SocialMediaOwner struct {
 	ID                      pgtype.UUID
 	Username                string
@@ -361,13 +487,56 @@ type SocialMediaOwner struct {
 }
 
 type StoredImage struct {
+	ID             pgtype.UUID
+	StoragePath    string
+	SourceUrl      string
+	ContentHash    string
+	MimeType       pgtype.Text
+	Width          pgtype.Int4
+	Height         pgtype.Int4
+	FileSize       pgtype.Int8
+	CreatedAt      pgtype.Timestamptz
+	ReferenceCount int32
+}
+
+type UserFavorite struct {
+	ID        pgtype.UUID
+	UserID    pgtype.UUID
+	RecipeID  pgtype.UUID
+	CreatedAt pgtype.Timestamptz
+}
+
+type UserPlan struct {
+	UserID    pgtype.UUID
+	Plan      string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type WebhookDelivery struct {
+	ID             pgtype.UUID
+	SubscriptionID pgtype.UUID
+	EventID        pgtype.UUID
+	Event          string
+	Payload        []byte
+	Status         string
+	Attempts       int32
+	ResponseStatus pgtype.Int4
+	Error          []byte
+	RedeliveryOf   pgtype.UUID
+	DeliveredAt    pgtype.Timestamptz
+	CreatedAt      pgtype.Timestamptz
+	UpdatedAt      pgtype.Timestamptz
+}
+
+type WebhookSubscription struct {
 	ID          pgtype.UUID
-	StoragePath string
-	SourceUrl   string
-	ContentHash string
-	MimeType    pgtype.Text
-	Width       pgtype.Int4
-	Height      pgtype.Int4
-	FileSize    pgtype.Int8
+	UserID      pgtype.UUID
+	Url         string
+	Secret      string
+	Events      []string
+	Description pgtype.Text
+	Active      bool
 	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
 }
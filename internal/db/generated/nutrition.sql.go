@@ -49,6 +49,15 @@ func (q *Queries) CreateNutrition(ctx context.Context, arg CreateNutritionParams
 	return i, err
 }
 
+const deleteNutritionByRecipe = `-- name: DeleteNutritionByRecipe :exec
+DELETE FROM recipe_nutrition WHERE recipe_id = $1
+`
+
+func (q *Queries) DeleteNutritionByRecipe(ctx context.Context, recipeID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteNutritionByRecipe, recipeID)
+	return err
+}
+
 const getNutritionByRecipe = `-- name: GetNutritionByRecipe :one
 SELECT id, recipe_id, protein, carbs, fat, fiber, created_at, updated_at FROM recipe_nutrition WHERE recipe_id = $1
 `
@@ -17,7 +17,7 @@ INSERT INTO recipes (
     id, created_by, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, owner_id, thumbnail_id, language
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16
-) RETURNING id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at
+) RETURNING id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at, embedding_model, embedding_version
 `
 
 type CreateRecipeParams struct {
@@ -81,6 +81,8 @@ func (q *Queries) CreateRecipe(ctx context.Context, arg CreateRecipeParams) (Rec
 		&i.IngredientNames,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingVersion,
 	)
 	return i, err
 }
@@ -215,7 +217,7 @@ func (q *Queries) DeleteRecipeParts(ctx context.Context, recipeID pgtype.UUID) e
 }
 
 const getRecipe = `-- name: GetRecipe :one
-SELECT id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at FROM recipes WHERE id = $1
+SELECT id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at, embedding_model, embedding_version FROM recipes WHERE id = $1
 `
 
 func (q *Queries) GetRecipe(ctx context.Context, id pgtype.UUID) (Recipe, error) {
@@ -243,10 +245,36 @@ func (q *Queries) GetRecipe(ctx context.Context, id pgtype.UUID) (Recipe, error)
 		&i.IngredientNames,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingVersion,
 	)
 	return i, err
 }
 
+const getRecipeIDsByUser = `-- name: GetRecipeIDsByUser :many
+SELECT id FROM recipes WHERE created_by = $1 ORDER BY created_at
+`
+
+func (q *Queries) GetRecipeIDsByUser(ctx context.Context, createdBy pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getRecipeIDsByUser, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRecipeParts = `-- name: GetRecipeParts :many
 SELECT id, recipe_id, name, description, display_order, is_optional, prep_time, cooking_time, created_at FROM recipe_parts WHERE recipe_id = $1 ORDER BY display_order
 `
@@ -281,8 +309,36 @@ func (q *Queries) GetRecipeParts(ctx context.Context, recipeID pgtype.UUID) ([]R
 	return items, nil
 }
 
+const getRecipeRawData = `-- name: GetRecipeRawData :one
+SELECT id, recipe_id, origin, source_url, raw_data, caption, transcript, video_url, thumbnail_url, images, scraped_at, processed_at, scraper_version, scraper_config, created_at, updated_at FROM recipe_raw_data WHERE recipe_id = $1
+`
+
+func (q *Queries) GetRecipeRawData(ctx context.Context, recipeID pgtype.UUID) (RecipeRawDatum, error) {
+	row := q.db.QueryRow(ctx, getRecipeRawData, recipeID)
+	var i RecipeRawDatum
+	err := row.Scan(
+		&i.ID,
+		&i.RecipeID,
+		&i.Origin,
+		&i.SourceUrl,
+		&i.RawData,
+		&i.Caption,
+		&i.Transcript,
+		&i.VideoUrl,
+		&i.ThumbnailUrl,
+		&i.Images,
+		&i.ScrapedAt,
+		&i.ProcessedAt,
+		&i.ScraperVersion,
+		&i.ScraperConfig,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getRecipeWithParts = `-- name: GetRecipeWithParts :one
-SELECT r.id, r.recipe_name, r.description, r.prep_time, r.cooking_time, r.total_time, r.original_serving_size, r.difficulty_rating, r.focused_diet, r.estimated_calories, r.origin, r.url, r.language, r.created_by, r.owner_id, r.thumbnail_id, r.embedding, r.search_vector, r.ingredient_names, r.created_at, r.updated_at,
+SELECT r.id, r.recipe_name, r.description, r.prep_time, r.cooking_time, r.total_time, r.original_serving_size, r.difficulty_rating, r.focused_diet, r.estimated_calories, r.origin, r.url, r.language, r.created_by, r.owner_id, r.thumbnail_id, r.embedding, r.search_vector, r.ingredient_names, r.created_at, r.updated_at, r.embedding_model, r.embedding_version,
        CASE
            WHEN COUNT(p.id) = 0 THEN NULL
            ELSE json_agg(json_build_object(
@@ -330,6 +386,8 @@ type GetRecipeWithPartsRow struct {
 	IngredientNames     []string
 	CreatedAt           pgtype.Timestamptz
 	UpdatedAt           pgtype.Timestamptz
+	EmbeddingModel      pgtype.Text
+	EmbeddingVersion    pgtype.Int4
 	Parts               interface{}
 }
 
@@ -358,13 +416,15 @@ func (q *Queries) GetRecipeWithParts(ctx context.Context, id pgtype.UUID) (GetRe
 		&i.IngredientNames,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingVersion,
 		&i.Parts,
 	)
 	return i, err
 }
 
 const getRecipesByUser = `-- name: GetRecipesByUser :many
-SELECT id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at FROM recipes WHERE created_by = $1 ORDER BY created_at DESC
+SELECT id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at, embedding_model, embedding_version FROM recipes WHERE created_by = $1 ORDER BY created_at DESC
 `
 
 func (q *Queries) GetRecipesByUser(ctx context.Context, createdBy pgtype.UUID) ([]Recipe, error) {
@@ -398,6 +458,8 @@ func (q *Queries) GetRecipesByUser(ctx context.Context, createdBy pgtype.UUID) (
 			&i.IngredientNames,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.EmbeddingModel,
+			&i.EmbeddingVersion,
 		); err != nil {
 			return nil, err
 		}
@@ -409,6 +471,56 @@ func (q *Queries) GetRecipesByUser(ctx context.Context, createdBy pgtype.UUID) (
 	return items, nil
 }
 
+const lockRecipe = `-- name: LockRecipe :one
+SELECT id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at, embedding_model, embedding_version FROM recipes WHERE id = $1 FOR UPDATE
+`
+
+// Loads the recipe and locks its row until the transaction ends, so content
+// writes and revisions of one recipe run one at a time.
+func (q *Queries) LockRecipe(ctx context.Context, id pgtype.UUID) (Recipe, error) {
+	row := q.db.QueryRow(ctx, lockRecipe, id)
+	var i Recipe
+	err := row.Scan(
+		&i.ID,
+		&i.RecipeName,
+		&i.Description,
+		&i.PrepTime,
+		&i.CookingTime,
+		&i.TotalTime,
+		&i.OriginalServingSize,
+		&i.DifficultyRating,
+		&i.FocusedDiet,
+		&i.EstimatedCalories,
+		&i.Origin,
+		&i.Url,
+		&i.Language,
+		&i.CreatedBy,
+		&i.OwnerID,
+		&i.ThumbnailID,
+		&i.Embedding,
+		&i.SearchVector,
+		&i.IngredientNames,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingVersion,
+	)
+	return i, err
+}
+
+const refreshRecipeIngredientNames = `-- name: RefreshRecipeIngredientNames :exec
+UPDATE recipes
+SET ingredient_names = ARRAY(
+    SELECT DISTINCT ri.name FROM recipe_ingredients ri WHERE ri.recipe_id = $1 ORDER BY ri.name
+)
+WHERE id = $1
+`
+
+func (q *Queries) RefreshRecipeIngredientNames(ctx context.Context, recipeID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, refreshRecipeIngredientNames, recipeID)
+	return err
+}
+
 const updateRecipe = `-- name: UpdateRecipe :one
 UPDATE recipes 
 SET 
@@ -416,6 +528,10 @@ SET
     description = $3, 
     prep_time = $4, 
     cooking_time = $5, 
+    total_time = CASE
+        WHEN prep_time IS NOT DISTINCT FROM $4 AND cooking_time IS NOT DISTINCT FROM $5 THEN total_time
+        ELSE COALESCE($4, 0) + COALESCE($5, 0)
+    END,
     original_serving_size = $6, 
     difficulty_rating = $7, 
     origin = $8,
@@ -424,7 +540,7 @@ SET
     thumbnail_id = $11,
     updated_at = NOW()
 WHERE id = $1 AND created_by = $12
-RETURNING id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at
+RETURNING id, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, language, created_by, owner_id, thumbnail_id, embedding, search_vector, ingredient_names, created_at, updated_at, embedding_model, embedding_version
 `
 
 type UpdateRecipeParams struct {
@@ -480,21 +596,80 @@ func (q *Queries) UpdateRecipe(ctx context.Context, arg UpdateRecipeParams) (Rec
 		&i.IngredientNames,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.EmbeddingModel,
+		&i.EmbeddingVersion,
 	)
 	return i, err
 }
 
 const updateRecipeEmbedding = `-- name: UpdateRecipeEmbedding :exec
-UPDATE recipes SET embedding = $2, updated_at = NOW() WHERE id = $1
+UPDATE recipes
+SET embedding = $2, embedding_model = $3, embedding_version = $4, updated_at = NOW()
+WHERE id = $1
 `
 
 type UpdateRecipeEmbeddingParams struct {
-	ID        pgtype.UUID
-	Embedding *pgvector_go.Vector
+	ID               pgtype.UUID
+	Embedding        *pgvector_go.Vector
+	EmbeddingModel   pgtype.Text
+	EmbeddingVersion pgtype.Int4
 }
 
 func (q *Queries) UpdateRecipeEmbedding(ctx context.Context, arg UpdateRecipeEmbeddingParams) error {
-	_, err := q.db.Exec(ctx, updateRecipeEmbedding, arg.ID, arg.Embedding)
+	_, err := q.db.Exec(ctx, updateRecipeEmbedding,
+		arg.ID,
+		arg.Embedding,
+		arg.EmbeddingModel,
+		arg.EmbeddingVersion,
+	)
+	return err
+}
+
+const updateRecipeGeneratedFields = `-- name: UpdateRecipeGeneratedFields :exec
+UPDATE recipes
+SET
+    recipe_name = $2,
+    description = $3,
+    prep_time = $4,
+    cooking_time = $5,
+    total_time = $6,
+    original_serving_size = $7,
+    difficulty_rating = $8,
+    focused_diet = $9,
+    estimated_calories = $10,
+    language = $11,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateRecipeGeneratedFieldsParams struct {
+	ID                  pgtype.UUID
+	RecipeName          string
+	Description         pgtype.Text
+	PrepTime            pgtype.Int4
+	CookingTime         pgtype.Int4
+	TotalTime           pgtype.Int4
+	OriginalServingSize pgtype.Int4
+	DifficultyRating    pgtype.Int2
+	FocusedDiet         pgtype.Text
+	EstimatedCalories   pgtype.Int4
+	Language            pgtype.Text
+}
+
+func (q *Queries) UpdateRecipeGeneratedFields(ctx context.Context, arg UpdateRecipeGeneratedFieldsParams) error {
+	_, err := q.db.Exec(ctx, updateRecipeGeneratedFields,
+		arg.ID,
+		arg.RecipeName,
+		arg.Description,
+		arg.PrepTime,
+		arg.CookingTime,
+		arg.TotalTime,
+		arg.OriginalServingSize,
+		arg.DifficultyRating,
+		arg.FocusedDiet,
+		arg.EstimatedCalories,
+		arg.Language,
+	)
 	return err
 }
 
@@ -12,6 +12,88 @@ import (
 	"github.com/pgvector/pgvector-go"
 )
 
+const getEmbeddingBackfillStats = `-- name: GetEmbeddingBackfillStats :one
+SELECT
+    COUNT(*) as total,
+    COUNT(*) FILTER (WHERE embedding IS NULL) as missing,
+    COUNT(*) FILTER (
+        WHERE embedding IS NOT NULL
+          AND (embedding_model IS DISTINCT FROM $1::text OR embedding_version IS DISTINCT FROM $2::integer)
+    ) as outdated
+FROM recipes
+`
+
+type GetEmbeddingBackfillStatsParams struct {
+	Column1 string
+	Column2 int32
+}
+
+type GetEmbeddingBackfillStatsRow struct {
+	Total    int64
+	Missing  int64
+	Outdated int64
+}
+
+func (q *Queries) GetEmbeddingBackfillStats(ctx context.Context, arg GetEmbeddingBackfillStatsParams) (GetEmbeddingBackfillStatsRow, error) {
+	row := q.db.QueryRow(ctx, getEmbeddingBackfillStats, arg.Column1, arg.Column2)
+	var i GetEmbeddingBackfillStatsRow
+	err := row.Scan(&i.Total, &i.Missing, &i.Outdated)
+	return i, err
+}
+
+const getRecipesNeedingEmbedding = `-- name: GetRecipesNeedingEmbedding :many
+SELECT id, recipe_name, description
+FROM recipes
+WHERE id > $1
+  AND (
+    embedding IS NULL
+    OR embedding_model IS DISTINCT FROM $2::text
+    OR embedding_version IS DISTINCT FROM $3::integer
+  )
+ORDER BY id
+LIMIT $4
+`
+
+type GetRecipesNeedingEmbeddingParams struct {
+	ID      pgtype.UUID
+	Column2 string
+	Column3 int32
+	Limit   int32
+}
+
+type GetRecipesNeedingEmbeddingRow struct {
+	ID          pgtype.UUID
+	RecipeName  string
+	Description pgtype.Text
+}
+
+// Keyset-paged list of recipes whose embedding is missing or was produced by
+// a different model or text composition version.
+func (q *Queries) GetRecipesNeedingEmbedding(ctx context.Context, arg GetRecipesNeedingEmbeddingParams) ([]GetRecipesNeedingEmbeddingRow, error) {
+	rows, err := q.db.Query(ctx, getRecipesNeedingEmbedding,
+		arg.ID,
+		arg.Column2,
+		arg.Column3,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRecipesNeedingEmbeddingRow
+	for rows.Next() {
+		var i GetRecipesNeedingEmbeddingRow
+		if err := rows.Scan(&i.ID, &i.RecipeName, &i.Description); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getRecipesWithoutEmbeddings = `-- name: GetRecipesWithoutEmbeddings :many
 SELECT id, recipe_name, description 
 FROM recipes 
@@ -175,6 +257,97 @@ func (q *Queries) SearchRecipesByIngredient(ctx context.Context, arg SearchRecip
 	return items, nil
 }
 
+const searchRecipesByMaxSim = `-- name: SearchRecipesByMaxSim :many
+WITH candidates AS (
+    SELECT
+        re.recipe_id,
+        re.kind,
+        re.embedding <=> $2::vector AS distance
+    FROM recipe_embeddings re
+    ORDER BY re.embedding <=> $2::vector
+    LIMIT $1 * 2
+),
+best AS (
+    SELECT DISTINCT ON (c.recipe_id)
+        c.recipe_id,
+        c.kind,
+        1 - c.distance AS similarity
+    FROM candidates c
+    ORDER BY c.recipe_id, c.distance
+)
+SELECT
+    r.id,
+    r.recipe_name,
+    r.description,
+    COALESCE(
+        array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as cuisine_categories,
+    COALESCE(
+        array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as meal_types,
+    CAST(b.kind AS text) as matched_kind,
+    CAST(b.similarity AS float8) as similarity
+FROM best b
+JOIN recipes r ON r.id = b.recipe_id
+LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
+LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
+LEFT JOIN recipe_meal_types rmt ON r.id = rmt.recipe_id
+LEFT JOIN meal_types mt ON rmt.meal_type_id = mt.id
+GROUP BY r.id, r.recipe_name, r.description, b.kind, b.similarity
+ORDER BY b.similarity DESC
+LIMIT $1
+`
+
+type SearchRecipesByMaxSimParams struct {
+	Limit   int32
+	Column2 pgvector.Vector
+}
+
+type SearchRecipesByMaxSimRow struct {
+	ID                pgtype.UUID
+	RecipeName        string
+	Description       pgtype.Text
+	CuisineCategories interface{}
+	MealTypes         interface{}
+	MatchedKind       string
+	Similarity        float64
+}
+
+// Scores each recipe by its best-matching document embedding (overview or
+// ingredients), so a query that only matches one facet still ranks well.
+// The nearest documents are taken from the HNSW index first. A recipe has at
+// most two documents, so the nearest $1 * 2 hold the best document of every
+// recipe in the top $1.
+func (q *Queries) SearchRecipesByMaxSim(ctx context.Context, arg SearchRecipesByMaxSimParams) ([]SearchRecipesByMaxSimRow, error) {
+	rows, err := q.db.Query(ctx, searchRecipesByMaxSim, arg.Limit, arg.Column2)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchRecipesByMaxSimRow
+	for rows.Next() {
+		var i SearchRecipesByMaxSimRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RecipeName,
+			&i.Description,
+			&i.CuisineCategories,
+			&i.MealTypes,
+			&i.MatchedKind,
+			&i.Similarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchRecipesByName = `-- name: SearchRecipesByName :many
 SELECT
     r.id,
@@ -295,6 +468,196 @@ func (q *Queries) SearchRecipesByName(ctx context.Context, arg SearchRecipesByNa
 	return items, nil
 }
 
+const searchRecipesByTextRank = `-- name: SearchRecipesByTextRank :many
+WITH hits AS (
+    SELECT id FROM recipes
+    WHERE search_vector @@ plainto_tsquery($4::regconfig, $3)
+    ORDER BY ts_rank(search_vector, plainto_tsquery($4::regconfig, $3)) DESC
+    LIMIT $1
+)
+SELECT
+    r.id,
+    r.recipe_name,
+    r.description,
+    r.owner_id,
+    smo.username as owner_username,
+    si.storage_path as thumbnail_storage_path,
+    COALESCE(
+        array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as cuisine_categories,
+    COALESCE(
+        array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as meal_types,
+    CAST(COALESCE(1 - (r.embedding <=> $2::vector), 0) AS float8) as vector_similarity,
+    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0) as float8) as text_similarity
+FROM hits h
+JOIN recipes r ON r.id = h.id
+LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
+LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
+LEFT JOIN recipe_meal_types rmt ON r.id = rmt.recipe_id
+LEFT JOIN meal_types mt ON rmt.meal_type_id = mt.id
+LEFT JOIN social_media_owners smo ON r.owner_id = smo.id
+LEFT JOIN recipe_images ri ON r.id = ri.recipe_id AND ri.image_type = 'full'
+LEFT JOIN stored_images si ON ri.stored_image_id = si.id
+GROUP BY r.id, r.recipe_name, r.description, r.owner_id, smo.username, si.storage_path, r.embedding, r.search_vector
+ORDER BY text_similarity DESC
+`
+
+type SearchRecipesByTextRankParams struct {
+	Limit          int32
+	Column2        pgvector.Vector
+	PlaintoTsquery string
+	Column4        string
+}
+
+type SearchRecipesByTextRankRow struct {
+	ID                   pgtype.UUID
+	RecipeName           string
+	Description          pgtype.Text
+	OwnerID              pgtype.UUID
+	OwnerUsername        pgtype.Text
+	ThumbnailStoragePath pgtype.Text
+	CuisineCategories    interface{}
+	MealTypes            interface{}
+	VectorSimilarity     float64
+	TextSimilarity       float64
+}
+
+// The recipes ranked highest by full-text match alone, for reciprocal rank
+// fusion. Recipes without an embedding are included with no vector similarity.
+func (q *Queries) SearchRecipesByTextRank(ctx context.Context, arg SearchRecipesByTextRankParams) ([]SearchRecipesByTextRankRow, error) {
+	rows, err := q.db.Query(ctx, searchRecipesByTextRank,
+		arg.Limit,
+		arg.Column2,
+		arg.PlaintoTsquery,
+		arg.Column4,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchRecipesByTextRankRow
+	for rows.Next() {
+		var i SearchRecipesByTextRankRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RecipeName,
+			&i.Description,
+			&i.OwnerID,
+			&i.OwnerUsername,
+			&i.ThumbnailStoragePath,
+			&i.CuisineCategories,
+			&i.MealTypes,
+			&i.VectorSimilarity,
+			&i.TextSimilarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchRecipesByVectorRank = `-- name: SearchRecipesByVectorRank :many
+WITH hits AS (
+    SELECT id FROM recipes
+    WHERE embedding IS NOT NULL
+    ORDER BY embedding <=> $2::vector
+    LIMIT $1
+)
+SELECT
+    r.id,
+    r.recipe_name,
+    r.description,
+    r.owner_id,
+    smo.username as owner_username,
+    si.storage_path as thumbnail_storage_path,
+    COALESCE(
+        array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as cuisine_categories,
+    COALESCE(
+        array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as meal_types,
+    CAST(COALESCE(1 - (r.embedding <=> $2::vector), 0) AS float8) as vector_similarity,
+    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0) as float8) as text_similarity
+FROM hits h
+JOIN recipes r ON r.id = h.id
+LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
+LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
+LEFT JOIN recipe_meal_types rmt ON r.id = rmt.recipe_id
+LEFT JOIN meal_types mt ON rmt.meal_type_id = mt.id
+LEFT JOIN social_media_owners smo ON r.owner_id = smo.id
+LEFT JOIN recipe_images ri ON r.id = ri.recipe_id AND ri.image_type = 'full'
+LEFT JOIN stored_images si ON ri.stored_image_id = si.id
+GROUP BY r.id, r.recipe_name, r.description, r.owner_id, smo.username, si.storage_path, r.embedding, r.search_vector
+ORDER BY vector_similarity DESC
+`
+
+type SearchRecipesByVectorRankParams struct {
+	Limit          int32
+	Column2        pgvector.Vector
+	PlaintoTsquery string
+	Column4        string
+}
+
+type SearchRecipesByVectorRankRow struct {
+	ID                   pgtype.UUID
+	RecipeName           string
+	Description          pgtype.Text
+	OwnerID              pgtype.UUID
+	OwnerUsername        pgtype.Text
+	ThumbnailStoragePath pgtype.Text
+	CuisineCategories    interface{}
+	MealTypes            interface{}
+	VectorSimilarity     float64
+	TextSimilarity       float64
+}
+
+// The recipes nearest the query embedding, for reciprocal rank fusion. The
+// text similarity is returned too so fused results carry both scores.
+func (q *Queries) SearchRecipesByVectorRank(ctx context.Context, arg SearchRecipesByVectorRankParams) ([]SearchRecipesByVectorRankRow, error) {
+	rows, err := q.db.Query(ctx, searchRecipesByVectorRank,
+		arg.Limit,
+		arg.Column2,
+		arg.PlaintoTsquery,
+		arg.Column4,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchRecipesByVectorRankRow
+	for rows.Next() {
+		var i SearchRecipesByVectorRankRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.RecipeName,
+			&i.Description,
+			&i.OwnerID,
+			&i.OwnerUsername,
+			&i.ThumbnailStoragePath,
+			&i.CuisineCategories,
+			&i.MealTypes,
+			&i.VectorSimilarity,
+			&i.TextSimilarity,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchRecipesHybrid = `-- name: SearchRecipesHybrid :many
 SELECT
     r.id,
@@ -313,12 +676,12 @@ SELECT
     ) as meal_types,
     -- Vector similarity score (0-1)
     CAST(1 - (r.embedding <=> $2::vector) AS float8) as vector_similarity,
-    -- Text search score (0-1)
-    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0) as float8) as text_similarity,
-    -- Combined hybrid score
+    -- Text search score (0-1), using the query language's text search config
+    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0) as float8) as text_similarity,
+    -- Combined hybrid score, weighted by the configured vector ($5) and text ($6) weights
     CAST(
-        0.7 * CAST(1 - (r.embedding <=> $2::vector) AS float8) +
-        0.3 * COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0)
+        $5::float8 * CAST(1 - (r.embedding <=> $2::vector) AS float8) +
+        $6::float8 * COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0)
         AS float8
     ) as hybrid_score
 FROM recipes r
@@ -339,6 +702,9 @@ type SearchRecipesHybridParams struct {
 	Limit          int32
 	Column2        pgvector.Vector
 	PlaintoTsquery string
+	Column4        string
+	Column5        float64
+	Column6        float64
 }
 
 type SearchRecipesHybridRow struct {
@@ -356,7 +722,14 @@ type SearchRecipesHybridRow struct {
 }
 
 func (q *Queries) SearchRecipesHybrid(ctx context.Context, arg SearchRecipesHybridParams) ([]SearchRecipesHybridRow, error) {
-	rows, err := q.db.Query(ctx, searchRecipesHybrid, arg.Limit, arg.Column2, arg.PlaintoTsquery)
+	rows, err := q.db.Query(ctx, searchRecipesHybrid,
+		arg.Limit,
+		arg.Column2,
+		arg.PlaintoTsquery,
+		arg.Column4,
+		arg.Column5,
+		arg.Column6,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -395,8 +768,8 @@ SELECT
     COALESCE(array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL), ARRAY[]::text[]) as cuisine_categories,
     COALESCE(array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL), ARRAY[]::text[]) as meal_types,
     CAST(1 - (r.embedding <=> $2::vector) AS float8) as vector_similarity,
-    COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0) as text_rank,
-    CAST(0.7 * CAST(1 - (r.embedding <=> $2::vector) AS float8) + 0.3 * COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0) AS float8) as hybrid_score
+    COALESCE(ts_rank(r.search_vector, plainto_tsquery($7::regconfig, $3)), 0) as text_rank,
+    CAST(0.7 * CAST(1 - (r.embedding <=> $2::vector) AS float8) + 0.3 * COALESCE(ts_rank(r.search_vector, plainto_tsquery($7::regconfig, $3)), 0) AS float8) as hybrid_score
 FROM recipes r
 LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
 LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
@@ -418,6 +791,7 @@ type SearchRecipesHybridWithFiltersParams struct {
 	Column4        []string
 	Column5        []string
 	Column6        interface{}
+	Column7        string
 }
 
 type SearchRecipesHybridWithFiltersRow struct {
@@ -439,6 +813,7 @@ func (q *Queries) SearchRecipesHybridWithFilters(ctx context.Context, arg Search
 		arg.Column4,
 		arg.Column5,
 		arg.Column6,
+		arg.Column7,
 	)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,134 @@
+-- name: CreateAccountExport :one
+INSERT INTO account_exports (user_id) VALUES ($1) RETURNING *;
+
+-- name: GetAccountExport :one
+SELECT * FROM account_exports WHERE id = $1;
+
+-- name: UpdateAccountExportStatus :exec
+UPDATE account_exports
+SET
+    status = $2,
+    storage_path = $3,
+    error = $4,
+    completed_at = CASE WHEN $2 IN ('COMPLETED', 'FAILED') THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetAccountData :one
+-- Everything stored about a user apart from their recipes, one JSON document
+-- per table. Webhook signing secrets are left out.
+SELECT
+    (SELECT to_jsonb(p) FROM profiles p WHERE p.id = @user_id)::jsonb AS profile,
+    (SELECT to_jsonb(up) FROM user_plans up WHERE up.user_id = @user_id)::jsonb AS plan,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(j) ORDER BY j.created_at), '[]') FROM recipe_import_jobs j WHERE j.user_id = @user_id)::jsonb AS import_jobs,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(b) ORDER BY b.created_at), '[]') FROM bulk_import_jobs b WHERE b.user_id = @user_id)::jsonb AS bulk_imports,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(f) ORDER BY f.created_at), '[]') FROM user_favorites f WHERE f.user_id = @user_id)::jsonb AS favorites,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(s) ORDER BY s.created_at), '[]') FROM recipe_shares s WHERE s.created_by = @user_id)::jsonb AS shares,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(e) ORDER BY e.created_at), '[]') FROM recipe_exports e WHERE e.user_id = @user_id)::jsonb AS recipe_exports,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(w) - 'secret' ORDER BY w.created_at), '[]') FROM webhook_subscriptions w WHERE w.user_id = @user_id)::jsonb AS webhooks,
+    (SELECT COALESCE(jsonb_agg(to_jsonb(a) ORDER BY a.created_at), '[]') FROM audit_events a WHERE a.user_id = @user_id)::jsonb AS activity;
+
+-- name: CreateAccountErasure :one
+INSERT INTO account_erasures (user_id) VALUES ($1) RETURNING *;
+
+-- name: GetAccountErasure :one
+SELECT * FROM account_erasures WHERE id = $1;
+
+-- name: GetLatestAccountErasure :one
+SELECT * FROM account_erasures
+WHERE user_id = $1
+ORDER BY created_at DESC
+LIMIT 1;
+
+-- name: UpdateAccountErasureStatus :exec
+UPDATE account_erasures
+SET
+    status = $2,
+    summary = $3,
+    error = $4,
+    completed_at = CASE WHEN $2 IN ('COMPLETED', 'FAILED') THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: AddAccountErasureImageHashes :one
+-- Records the content hashes of images an erasure released and returns all
+-- those it has recorded, so a retry still collects them.
+UPDATE account_erasures
+SET
+    image_hashes = ARRAY(SELECT DISTINCT hash FROM unnest(image_hashes || @image_hashes::text[]) AS hash),
+    updated_at = NOW()
+WHERE id = @id
+RETURNING image_hashes;
+
+-- name: ListExportPathsByUser :many
+-- Lists the storage paths of a user's recipe and account export archives.
+SELECT storage_path FROM recipe_exports WHERE user_id = $1 AND storage_path IS NOT NULL
+UNION ALL
+SELECT storage_path FROM account_exports WHERE user_id = $1 AND storage_path IS NOT NULL;
+
+-- name: DeleteRecipesByUser :one
+-- Deletes every recipe a user created and releases the stored images they
+-- used, returning how many recipes went and the content hashes of the
+-- released images for garbage collection.
+WITH released AS (
+    DELETE FROM recipe_images ri
+    USING recipes r
+    WHERE ri.recipe_id = r.id AND r.created_by = $1
+    RETURNING ri.stored_image_id
+), deleted AS (
+    DELETE FROM recipes WHERE created_by = $1 RETURNING id
+), decremented AS (
+    UPDATE stored_images si
+    SET reference_count = GREATEST(si.reference_count - counts.refs, 0)
+    FROM (
+        SELECT stored_image_id, COUNT(*) AS refs FROM released GROUP BY stored_image_id
+    ) counts
+    WHERE si.id = counts.stored_image_id
+    RETURNING si.content_hash
+)
+SELECT
+    (SELECT COUNT(*) FROM deleted) AS recipes,
+    COALESCE((SELECT array_agg(content_hash) FROM decremented), '{}')::text[] AS content_hashes;
+
+-- name: DeleteExportsByUser :many
+-- Deletes a user's recipe and account exports, returning the storage paths
+-- of their archives.
+WITH recipe_exports_deleted AS (
+    DELETE FROM recipe_exports WHERE user_id = $1 RETURNING storage_path
+), account_exports_deleted AS (
+    DELETE FROM account_exports WHERE user_id = $1 RETURNING storage_path
+)
+SELECT storage_path FROM recipe_exports_deleted
+UNION ALL
+SELECT storage_path FROM account_exports_deleted;
+
+-- name: EraseUserRecords :one
+-- Deletes a user's import history, favorites, shares, webhooks and plan and
+-- clears the personal details from their profile, returning the number of
+-- rows removed from each table. Audit events are kept.
+WITH import_jobs AS (
+    DELETE FROM recipe_import_jobs WHERE user_id = $1 RETURNING id
+), bulk_imports AS (
+    DELETE FROM bulk_import_jobs WHERE user_id = $1 RETURNING id
+), favorites AS (
+    DELETE FROM user_favorites WHERE user_id = $1 RETURNING id
+), shares AS (
+    DELETE FROM recipe_shares WHERE created_by = $1 RETURNING id
+), webhooks AS (
+    DELETE FROM webhook_subscriptions WHERE user_id = $1 RETURNING id
+), plans AS (
+    DELETE FROM user_plans WHERE user_id = $1 RETURNING user_id
+), profile AS (
+    UPDATE profiles
+    SET email = NULL, first_name = NULL, last_name = NULL, username = NULL, avatar_url = NULL, updated_at = NOW()
+    WHERE id = $1
+    RETURNING id
+)
+SELECT
+    (SELECT COUNT(*) FROM import_jobs) AS import_jobs,
+    (SELECT COUNT(*) FROM bulk_imports) AS bulk_imports,
+    (SELECT COUNT(*) FROM favorites) AS favorites,
+    (SELECT COUNT(*) FROM shares) AS shares,
+    (SELECT COUNT(*) FROM webhooks) AS webhooks,
+    (SELECT COUNT(*) FROM plans) AS plans,
+    (SELECT COUNT(*) FROM profile) AS profiles;
@@ -0,0 +1,25 @@
+-- name: CreateAuditEvent :exec
+INSERT INTO audit_events (
+    user_id, actor, impersonator, action, resource_type, resource_id,
+    method, path, request_id, outcome, status_code
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+);
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+AND (sqlc.narg('actor')::text IS NULL OR actor = sqlc.narg('actor'))
+AND (sqlc.narg('action')::text IS NULL OR action = sqlc.narg('action'))
+AND (sqlc.narg('resource_type')::text IS NULL OR resource_type = sqlc.narg('resource_type'))
+AND (sqlc.narg('resource_id')::text IS NULL OR resource_id = sqlc.narg('resource_id'))
+AND (sqlc.narg('before')::timestamptz IS NULL OR created_at < sqlc.narg('before'))
+ORDER BY created_at DESC
+LIMIT @limit;
+
+-- name: ListUserAuditEvents :many
+SELECT * FROM audit_events
+WHERE user_id = @user_id
+AND (sqlc.narg('before')::timestamptz IS NULL OR created_at < sqlc.narg('before'))
+ORDER BY created_at DESC
+LIMIT @limit;
@@ -51,3 +51,11 @@ SELECT * FROM recipe_import_jobs WHERE bulk_job_id = $1 ORDER BY created_at ASC;
 UPDATE recipe_import_jobs 
 SET bulk_job_id = $2
 WHERE job_id = $1;
+
+-- name: ListBulkImportJobs :many
+SELECT * FROM bulk_import_jobs
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+AND (sqlc.narg('before')::timestamptz IS NULL OR created_at < sqlc.narg('before'))
+ORDER BY created_at DESC
+LIMIT @limit;
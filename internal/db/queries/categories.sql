@@ -91,4 +91,48 @@ FROM equipment e
 JOIN recipe_equipment re ON e.id = re.equipment_id
 JOIN recipes r ON re.recipe_id = r.id
 WHERE r.created_by = $1
-ORDER BY e.name;
\ No newline at end of file
+ORDER BY e.name;
+-- name: GetRecipeCategoryNames :one
+SELECT
+    ARRAY(
+        SELECT cc.name FROM cuisine_categories cc
+        JOIN recipe_cuisine_categories rcc ON cc.id = rcc.cuisine_category_id
+        WHERE rcc.recipe_id = $1
+        ORDER BY cc.name
+    )::text[] AS cuisine_categories,
+    ARRAY(
+        SELECT mt.name FROM meal_types mt
+        JOIN recipe_meal_types rmt ON mt.id = rmt.meal_type_id
+        WHERE rmt.recipe_id = $1
+        ORDER BY mt.name
+    )::text[] AS meal_types,
+    ARRAY(
+        SELECT o.name FROM occasions o
+        JOIN recipe_occasions ro ON o.id = ro.occasion_id
+        WHERE ro.recipe_id = $1
+        ORDER BY o.name
+    )::text[] AS occasions,
+    ARRAY(
+        SELECT dr.name FROM dietary_restrictions dr
+        JOIN recipe_dietary_restrictions rdr ON dr.id = rdr.dietary_restriction_id
+        WHERE rdr.recipe_id = $1
+        ORDER BY dr.name
+    )::text[] AS dietary_restrictions,
+    ARRAY(
+        SELECT e.name FROM equipment e
+        JOIN recipe_equipment re ON e.id = re.equipment_id
+        WHERE re.recipe_id = $1
+        ORDER BY e.name
+    )::text[] AS equipment;
+
+-- name: ClearRecipeCategories :exec
+WITH cuisine AS (
+    DELETE FROM recipe_cuisine_categories WHERE recipe_id = $1
+), meal AS (
+    DELETE FROM recipe_meal_types WHERE recipe_id = $1
+), occasion AS (
+    DELETE FROM recipe_occasions WHERE recipe_id = $1
+), diet AS (
+    DELETE FROM recipe_dietary_restrictions WHERE recipe_id = $1
+)
+DELETE FROM recipe_equipment WHERE recipe_id = $1;
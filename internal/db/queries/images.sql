@@ -9,6 +9,9 @@ INSERT INTO stored_images (
 ) RETURNING *;
 
 -- name: CreateRecipeImage :one
+WITH referenced AS (
+    UPDATE stored_images SET reference_count = reference_count + 1 WHERE id = $2
+)
 INSERT INTO recipe_images (
     recipe_id, stored_image_id, image_type
 ) VALUES (
@@ -23,3 +26,36 @@ WHERE ri.recipe_id = $1;
 
 -- name: DeleteRecipeImages :exec
 DELETE FROM recipe_images WHERE recipe_id = $1;
+
+-- name: DecrementStoredImageReferences :exec
+-- Releases one reference per occurrence of a hash in the list.
+UPDATE stored_images si
+SET reference_count = GREATEST(si.reference_count - released.refs, 0)
+FROM (
+    SELECT hash, COUNT(*) AS refs FROM unnest($1::text[]) AS hash GROUP BY hash
+) released
+WHERE si.content_hash = released.hash;
+
+-- name: DeleteUnreferencedStoredImages :many
+-- Deletes stored images that no recipe image or owner avatar uses. With an
+-- empty hash list every unreferenced image older than an hour is deleted;
+-- the grace period covers imports between upload and linking the image.
+DELETE FROM stored_images si
+WHERE si.reference_count <= 0
+  AND (
+    si.content_hash = ANY($1::text[])
+    OR (cardinality($1::text[]) = 0 AND si.created_at < NOW() - INTERVAL '1 hour')
+  )
+  AND NOT EXISTS (SELECT 1 FROM recipe_images ri WHERE ri.stored_image_id = si.id)
+  AND NOT EXISTS (SELECT 1 FROM social_media_owners o WHERE o.profile_pic_stored_image_id = si.id::text)
+RETURNING *;
+
+-- name: RestoreStoredImage :exec
+-- Puts back a stored image whose blob could not be deleted, unreferenced,
+-- so the next garbage collection finds it again. An image stored again
+-- under the same hash in the meantime is kept.
+INSERT INTO stored_images (
+    id, storage_path, source_url, content_hash, mime_type, width, height, file_size, created_at, reference_count
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, 0
+) ON CONFLICT DO NOTHING;
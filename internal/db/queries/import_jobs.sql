@@ -23,7 +23,12 @@ SET
     updated_at = NOW()
 WHERE job_id = $1;
 
-
+-- name: SetImportJobResult :exec
+UPDATE recipe_import_jobs
+SET
+    result = $2,
+    updated_at = NOW()
+WHERE job_id = $1;
 
 -- name: DeleteOldImportJobs :exec
 DELETE FROM recipe_import_jobs 
@@ -34,3 +39,20 @@ AND created_at < NOW() - INTERVAL '7 days';
 DELETE FROM recipe_import_jobs 
 WHERE status IN ('QUEUED', 'EXECUTING')
 AND created_at < NOW() - INTERVAL '24 hours';
+
+-- name: ListImportJobs :many
+SELECT * FROM recipe_import_jobs
+WHERE (sqlc.narg('status')::text IS NULL OR status = sqlc.narg('status'))
+AND (sqlc.narg('user_id')::uuid IS NULL OR user_id = sqlc.narg('user_id'))
+AND (sqlc.narg('origin')::text IS NULL OR origin = sqlc.narg('origin'))
+AND (sqlc.narg('bulk_job_id')::text IS NULL OR bulk_job_id = sqlc.narg('bulk_job_id'))
+AND (sqlc.narg('before')::timestamptz IS NULL OR created_at < sqlc.narg('before'))
+ORDER BY created_at DESC
+LIMIT @limit;
+
+-- name: CountImportJobsByOrigin :many
+SELECT origin, status, COUNT(*) AS count
+FROM recipe_import_jobs
+WHERE created_at >= @since::timestamptz
+GROUP BY origin, status
+ORDER BY origin, status;
@@ -1,5 +1,5 @@
 -- name: GetIngredientsByRecipe :many
-SELECT * FROM recipe_ingredients WHERE recipe_id = $1;
+SELECT * FROM recipe_ingredients WHERE recipe_id = $1 ORDER BY created_at, id;
 
 -- name: GetIngredientsByRecipeAndPart :many
 SELECT * FROM recipe_ingredients WHERE recipe_id = $1 AND part_id = $2;
@@ -18,3 +18,6 @@ SET
     updated_at = NOW()
 WHERE recipe_id = $1
 RETURNING *;
+
+-- name: DeleteNutritionByRecipe :exec
+DELETE FROM recipe_nutrition WHERE recipe_id = $1;
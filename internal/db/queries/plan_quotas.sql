@@ -0,0 +1,27 @@
+-- name: GetPlanQuota :one
+SELECT * FROM plan_quotas WHERE plan = $1;
+
+-- name: GetUserPlanQuota :one
+SELECT pq.* FROM plan_quotas pq
+WHERE pq.plan = COALESCE((SELECT up.plan FROM user_plans up WHERE up.user_id = $1), 'free');
+
+-- name: SetUserPlan :one
+INSERT INTO user_plans (user_id, plan)
+VALUES ($1, $2)
+ON CONFLICT (user_id) DO UPDATE SET plan = EXCLUDED.plan, updated_at = NOW()
+RETURNING *;
+
+-- name: CountUserImports :one
+-- Counts what uses the user's import quota: imports, including every recipe
+-- of a file import, and regenerations.
+WITH usage AS (
+    SELECT created_at FROM recipe_import_jobs
+    WHERE user_id = @user_id AND created_at >= @month_start::timestamptz
+    UNION ALL
+    SELECT created_at FROM recipe_regenerations
+    WHERE requested_by = @user_id AND created_at >= @month_start::timestamptz
+)
+SELECT
+    COUNT(*) FILTER (WHERE created_at >= @day_start::timestamptz) AS daily,
+    COUNT(*) AS monthly
+FROM usage;
@@ -0,0 +1,14 @@
+-- name: UpsertRecipeEmbedding :exec
+INSERT INTO recipe_embeddings (recipe_id, kind, embedding, model, version)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (recipe_id, kind) DO UPDATE SET
+    embedding = EXCLUDED.embedding,
+    model = EXCLUDED.model,
+    version = EXCLUDED.version,
+    updated_at = NOW();
+
+-- name: DeleteStaleRecipeEmbeddings :exec
+-- Removes document kinds the current embedding config no longer produces.
+DELETE FROM recipe_embeddings
+WHERE recipe_id = $1
+  AND NOT (kind = ANY($2::text[]));
@@ -0,0 +1,20 @@
+-- name: CreateRecipeExport :one
+INSERT INTO recipe_exports (
+    user_id, format
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetRecipeExport :one
+SELECT * FROM recipe_exports WHERE id = $1;
+
+-- name: UpdateRecipeExportStatus :exec
+UPDATE recipe_exports
+SET
+    status = $2,
+    recipe_count = $3,
+    storage_path = $4,
+    error = $5,
+    completed_at = CASE WHEN $2 IN ('COMPLETED', 'FAILED') THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,19 @@
+-- name: CreateRecipeRegeneration :one
+INSERT INTO recipe_regenerations (
+    recipe_id, requested_by, mode, provider, prompt_version
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetRecipeRegeneration :one
+SELECT * FROM recipe_regenerations WHERE id = $1;
+
+-- name: UpdateRecipeRegenerationStatus :exec
+UPDATE recipe_regenerations
+SET
+    status = $2,
+    draft = $3,
+    error = $4,
+    completed_at = CASE WHEN $2 IN ('COMPLETED', 'FAILED') THEN NOW() ELSE completed_at END,
+    updated_at = NOW()
+WHERE id = $1;
@@ -0,0 +1,22 @@
+-- name: CountRecipeRevisions :one
+SELECT COUNT(*) FROM recipe_revisions WHERE recipe_id = $1;
+
+-- name: CreateRecipeRevision :one
+-- Numbers the revision after the recipe's latest. Callers lock the recipe row
+-- with LockRecipe first so concurrent revisions don't take the same number.
+INSERT INTO recipe_revisions (
+    recipe_id, revision_number, author_id, action, restored_from, snapshot, diff, message
+) VALUES (
+    $1,
+    COALESCE((SELECT MAX(rr.revision_number) FROM recipe_revisions rr WHERE rr.recipe_id = $1), 0) + 1,
+    $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: GetRecipeRevision :one
+SELECT * FROM recipe_revisions WHERE recipe_id = $1 AND revision_number = $2;
+
+-- name: ListRecipeRevisions :many
+SELECT id, recipe_id, revision_number, author_id, action, restored_from, diff, message, created_at
+FROM recipe_revisions
+WHERE recipe_id = $1
+ORDER BY revision_number DESC;
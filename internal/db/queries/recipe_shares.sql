@@ -0,0 +1,30 @@
+-- name: CreateRecipeShare :one
+INSERT INTO recipe_shares (
+    recipe_id, created_by, expires_at
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetRecipeShare :one
+SELECT rs.*, si.storage_path AS thumbnail_storage_path
+FROM recipe_shares rs
+LEFT JOIN recipe_images ri ON ri.recipe_id = rs.recipe_id AND ri.image_type = 'full'
+LEFT JOIN stored_images si ON ri.stored_image_id = si.id
+WHERE rs.id = $1
+LIMIT 1;
+
+-- name: ListActiveRecipeShares :many
+SELECT * FROM recipe_shares
+WHERE recipe_id = $1 AND revoked_at IS NULL AND expires_at > NOW()
+ORDER BY created_at DESC;
+
+-- name: RecordRecipeShareView :exec
+UPDATE recipe_shares
+SET view_count = view_count + 1, last_viewed_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeRecipeShare :one
+UPDATE recipe_shares
+SET revoked_at = NOW()
+WHERE id = $1 AND recipe_id = $2 AND revoked_at IS NULL
+RETURNING *;
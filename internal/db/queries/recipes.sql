@@ -1,9 +1,17 @@
 -- name: GetRecipe :one
 SELECT * FROM recipes WHERE id = $1;
 
+-- name: LockRecipe :one
+-- Loads the recipe and locks its row until the transaction ends, so content
+-- writes and revisions of one recipe run one at a time.
+SELECT * FROM recipes WHERE id = $1 FOR UPDATE;
+
 -- name: GetRecipesByUser :many
 SELECT * FROM recipes WHERE created_by = $1 ORDER BY created_at DESC;
 
+-- name: GetRecipeIDsByUser :many
+SELECT id FROM recipes WHERE created_by = $1 ORDER BY created_at;
+
 -- name: CreateRecipe :one
 INSERT INTO recipes (
     id, created_by, recipe_name, description, prep_time, cooking_time, total_time, original_serving_size, difficulty_rating, focused_diet, estimated_calories, origin, url, owner_id, thumbnail_id, language
@@ -18,6 +26,10 @@ SET
     description = $3, 
     prep_time = $4, 
     cooking_time = $5, 
+    total_time = CASE
+        WHEN prep_time IS NOT DISTINCT FROM $4 AND cooking_time IS NOT DISTINCT FROM $5 THEN total_time
+        ELSE COALESCE($4, 0) + COALESCE($5, 0)
+    END,
     original_serving_size = $6, 
     difficulty_rating = $7, 
     origin = $8,
@@ -31,11 +43,20 @@ RETURNING *;
 -- name: DeleteRecipe :exec
 DELETE FROM recipes WHERE id = $1 AND created_by = $2;
 
+-- name: RefreshRecipeIngredientNames :exec
+UPDATE recipes
+SET ingredient_names = ARRAY(
+    SELECT DISTINCT ri.name FROM recipe_ingredients ri WHERE ri.recipe_id = $1 ORDER BY ri.name
+)
+WHERE id = $1;
+
 -- name: UpdateRecipeThumbnail :exec
 UPDATE recipes SET thumbnail_id = $2, updated_at = NOW() WHERE id = $1;
 
 -- name: UpdateRecipeEmbedding :exec
-UPDATE recipes SET embedding = $2, updated_at = NOW() WHERE id = $1;
+UPDATE recipes
+SET embedding = $2, embedding_model = $3, embedding_version = $4, updated_at = NOW()
+WHERE id = $1;
 
 -- Recipe Parts Queries
 -- name: CreateRecipePart :one
@@ -83,3 +104,22 @@ INSERT INTO recipe_raw_data (
 ) VALUES (
     $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
+
+-- name: GetRecipeRawData :one
+SELECT * FROM recipe_raw_data WHERE recipe_id = $1;
+
+-- name: UpdateRecipeGeneratedFields :exec
+UPDATE recipes
+SET
+    recipe_name = $2,
+    description = $3,
+    prep_time = $4,
+    cooking_time = $5,
+    total_time = $6,
+    original_serving_size = $7,
+    difficulty_rating = $8,
+    focused_diet = $9,
+    estimated_calories = $10,
+    language = $11,
+    updated_at = NOW()
+WHERE id = $1;
@@ -6,13 +6,13 @@ CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 CREATE EXTENSION IF NOT EXISTS vector;
 
 -- Enums
-CREATE TYPE recipe_origin AS ENUM ('instagram', 'tiktok', 'firecrawl');
+CREATE TYPE recipe_origin AS ENUM ('instagram', 'tiktok', 'firecrawl', 'manual', 'file');
 CREATE TYPE social_media_platform AS ENUM ('instagram', 'tiktok');
 CREATE TYPE measurement_unit AS ENUM ('metric', 'imperial');
 
 -- Profiles table
 CREATE TABLE profiles (
-    id UUID PRIMARY KEY REFERENCES auth.users(id),
+    id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
     email TEXT,
     first_name TEXT,
     last_name TEXT,
@@ -46,7 +46,9 @@ CREATE TABLE recipes (
     search_vector tsvector,
     ingredient_names text[],
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
-    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    embedding_model TEXT,
+    embedding_version INTEGER
 );
 -- Recipe parts table
 CREATE TABLE recipe_parts (
@@ -72,7 +74,7 @@ CREATE TABLE recipe_ingredients (
     original_unit TEXT,
     name TEXT NOT NULL,
     part_id UUID REFERENCES recipe_parts(id) ON DELETE CASCADE,
-    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT clock_timestamp()
 );
 
 -- Recipe instructions table
@@ -123,6 +125,7 @@ CREATE TABLE stored_images (
     height INTEGER,
     file_size BIGINT,
     created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    reference_count INTEGER NOT NULL DEFAULT 0,
     UNIQUE(content_hash)
 );
 
@@ -141,7 +144,7 @@ CREATE TABLE recipe_import_jobs (
     job_id TEXT NOT NULL UNIQUE,
     user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
     url TEXT NOT NULL,
-    origin TEXT NOT NULL CHECK (origin IN ('instagram', 'tiktok')),
+    origin TEXT NOT NULL CHECK (origin IN ('instagram', 'tiktok', 'youtube', 'firecrawl', 'manual', 'file')),
     status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED', 'CRASHED', 'TIMED_OUT', 'CANCELED')),
     progress_step TEXT,
     progress_message TEXT,
@@ -183,6 +186,7 @@ CREATE INDEX idx_recipe_instructions_timer_data ON recipe_instructions USING GIN
 CREATE INDEX idx_social_media_owners_platform ON social_media_owners(platform);
 CREATE INDEX idx_recipe_images_recipe_id ON recipe_images(recipe_id);
 CREATE INDEX idx_stored_images_content_hash ON stored_images(content_hash);
+CREATE INDEX idx_stored_images_unreferenced ON stored_images(created_at) WHERE reference_count <= 0;
 CREATE INDEX idx_recipe_images_stored_image_id ON recipe_images(stored_image_id);
 CREATE INDEX idx_recipe_import_jobs_user_id ON recipe_import_jobs(user_id);
 CREATE INDEX idx_recipe_import_jobs_job_id ON recipe_import_jobs(job_id);
@@ -315,3 +319,203 @@ CREATE TABLE IF NOT EXISTS recipe_raw_data (
 
 CREATE INDEX IF NOT EXISTS idx_recipe_raw_data_recipe_id ON recipe_raw_data(recipe_id);
 CREATE INDEX IF NOT EXISTS idx_recipe_raw_data_origin ON recipe_raw_data(origin);
+
+-- Per-facet recipe embeddings for max-sim semantic search
+CREATE TABLE IF NOT EXISTS recipe_embeddings (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    embedding vector(1536) NOT NULL,
+    model TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, kind)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_embeddings_embedding ON recipe_embeddings USING hnsw (embedding vector_cosine_ops);
+
+-- Recipe revision history
+CREATE TABLE IF NOT EXISTS recipe_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    revision_number INTEGER NOT NULL,
+    author_id UUID NOT NULL REFERENCES auth.users(id),
+    action TEXT NOT NULL CHECK (action IN ('original', 'edit', 'restore')),
+    restored_from INTEGER,
+    snapshot JSONB NOT NULL,
+    diff JSONB NOT NULL DEFAULT '[]'::jsonb,
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (recipe_id, revision_number)
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_revisions_recipe_id ON recipe_revisions(recipe_id);
+
+-- Recipe regenerations
+CREATE TABLE IF NOT EXISTS recipe_regenerations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('draft', 'replace')),
+    provider TEXT NOT NULL,
+    prompt_version INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    draft JSONB,
+    error JSONB,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_regenerations_recipe_id ON recipe_regenerations(recipe_id);
+CREATE INDEX IF NOT EXISTS idx_recipe_regenerations_requested_by_created ON recipe_regenerations(requested_by, created_at);
+
+-- Recipe exports
+CREATE TABLE IF NOT EXISTS recipe_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    format TEXT NOT NULL CHECK (format IN ('jsonld', 'paprika', 'mealie', 'markdown', 'html')),
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    recipe_count INTEGER NOT NULL DEFAULT 0,
+    storage_path TEXT,
+    error JSONB,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_exports_user_id ON recipe_exports(user_id);
+
+-- Recipe share links
+CREATE TABLE IF NOT EXISTS recipe_shares (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_shares_recipe_id ON recipe_shares(recipe_id);
+
+-- Outgoing webhooks
+CREATE TABLE IF NOT EXISTS webhook_subscriptions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID REFERENCES auth.users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    description TEXT,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_subscriptions_user_id ON webhook_subscriptions(user_id);
+
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id) ON DELETE CASCADE,
+    event_id UUID NOT NULL,
+    event TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status TEXT NOT NULL DEFAULT 'PENDING' CHECK (status IN ('PENDING', 'SUCCEEDED', 'FAILED')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    response_status INTEGER,
+    error JSONB,
+    redelivery_of UUID REFERENCES webhook_deliveries(id) ON DELETE SET NULL,
+    delivered_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscription_id ON webhook_deliveries(subscription_id, created_at DESC);
+
+-- Plan import quotas
+CREATE TABLE IF NOT EXISTS plan_quotas (
+    plan TEXT PRIMARY KEY,
+    daily_imports INTEGER,
+    monthly_imports INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS user_plans (
+    user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+    plan TEXT NOT NULL REFERENCES plan_quotas(plan),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_import_jobs_user_created ON recipe_import_jobs(user_id, created_at);
+
+-- Service API keys
+CREATE TABLE IF NOT EXISTS service_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    allowed_user_ids UUID[],
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Audit events
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    actor TEXT NOT NULL,
+    impersonator TEXT,
+    action TEXT NOT NULL,
+    resource_type TEXT,
+    resource_id TEXT,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    request_id TEXT,
+    outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure')),
+    status_code INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+-- User favorites
+CREATE TABLE IF NOT EXISTS user_favorites (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE(user_id, recipe_id)
+);
+
+-- Account data requests
+CREATE TABLE IF NOT EXISTS account_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    storage_path TEXT,
+    error JSONB,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_exports_user_id ON account_exports(user_id);
+
+CREATE TABLE IF NOT EXISTS account_erasures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    summary JSONB,
+    error JSONB,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    image_hashes TEXT[] NOT NULL DEFAULT '{}'
+);
+
+CREATE INDEX IF NOT EXISTS idx_account_erasures_user_id ON account_erasures(user_id, created_at DESC);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_erasures_active ON account_erasures(user_id) WHERE status IN ('QUEUED', 'EXECUTING');
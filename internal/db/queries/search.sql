@@ -74,12 +74,159 @@ GROUP BY r.id, r.recipe_name, r.description, r.embedding
 ORDER BY r.embedding <=> $2::vector
 LIMIT $1;
 
+-- name: SearchRecipesByMaxSim :many
+-- Scores each recipe by its best-matching document embedding (overview or
+-- ingredients), so a query that only matches one facet still ranks well.
+-- The nearest documents are taken from the HNSW index first. A recipe has at
+-- most two documents, so the nearest $1 * 2 hold the best document of every
+-- recipe in the top $1.
+WITH candidates AS (
+    SELECT
+        re.recipe_id,
+        re.kind,
+        re.embedding <=> $2::vector AS distance
+    FROM recipe_embeddings re
+    ORDER BY re.embedding <=> $2::vector
+    LIMIT $1 * 2
+),
+best AS (
+    SELECT DISTINCT ON (c.recipe_id)
+        c.recipe_id,
+        c.kind,
+        1 - c.distance AS similarity
+    FROM candidates c
+    ORDER BY c.recipe_id, c.distance
+)
+SELECT
+    r.id,
+    r.recipe_name,
+    r.description,
+    COALESCE(
+        array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as cuisine_categories,
+    COALESCE(
+        array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as meal_types,
+    CAST(b.kind AS text) as matched_kind,
+    CAST(b.similarity AS float8) as similarity
+FROM best b
+JOIN recipes r ON r.id = b.recipe_id
+LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
+LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
+LEFT JOIN recipe_meal_types rmt ON r.id = rmt.recipe_id
+LEFT JOIN meal_types mt ON rmt.meal_type_id = mt.id
+GROUP BY r.id, r.recipe_name, r.description, b.kind, b.similarity
+ORDER BY b.similarity DESC
+LIMIT $1;
+
 -- name: GetRecipesWithoutEmbeddings :many
 SELECT id, recipe_name, description 
 FROM recipes 
 WHERE embedding IS NULL 
 LIMIT $1;
 
+-- name: GetRecipesNeedingEmbedding :many
+-- Keyset-paged list of recipes whose embedding is missing or was produced by
+-- a different model or text composition version.
+SELECT id, recipe_name, description
+FROM recipes
+WHERE id > $1
+  AND (
+    embedding IS NULL
+    OR embedding_model IS DISTINCT FROM $2::text
+    OR embedding_version IS DISTINCT FROM $3::integer
+  )
+ORDER BY id
+LIMIT $4;
+
+-- name: GetEmbeddingBackfillStats :one
+SELECT
+    COUNT(*) as total,
+    COUNT(*) FILTER (WHERE embedding IS NULL) as missing,
+    COUNT(*) FILTER (
+        WHERE embedding IS NOT NULL
+          AND (embedding_model IS DISTINCT FROM $1::text OR embedding_version IS DISTINCT FROM $2::integer)
+    ) as outdated
+FROM recipes;
+
+
+-- name: SearchRecipesByTextRank :many
+-- The recipes ranked highest by full-text match alone, for reciprocal rank
+-- fusion. Recipes without an embedding are included with no vector similarity.
+WITH hits AS (
+    SELECT id FROM recipes
+    WHERE search_vector @@ plainto_tsquery($4::regconfig, $3)
+    ORDER BY ts_rank(search_vector, plainto_tsquery($4::regconfig, $3)) DESC
+    LIMIT $1
+)
+SELECT
+    r.id,
+    r.recipe_name,
+    r.description,
+    r.owner_id,
+    smo.username as owner_username,
+    si.storage_path as thumbnail_storage_path,
+    COALESCE(
+        array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as cuisine_categories,
+    COALESCE(
+        array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as meal_types,
+    CAST(COALESCE(1 - (r.embedding <=> $2::vector), 0) AS float8) as vector_similarity,
+    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0) as float8) as text_similarity
+FROM hits h
+JOIN recipes r ON r.id = h.id
+LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
+LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
+LEFT JOIN recipe_meal_types rmt ON r.id = rmt.recipe_id
+LEFT JOIN meal_types mt ON rmt.meal_type_id = mt.id
+LEFT JOIN social_media_owners smo ON r.owner_id = smo.id
+LEFT JOIN recipe_images ri ON r.id = ri.recipe_id AND ri.image_type = 'full'
+LEFT JOIN stored_images si ON ri.stored_image_id = si.id
+GROUP BY r.id, r.recipe_name, r.description, r.owner_id, smo.username, si.storage_path, r.embedding, r.search_vector
+ORDER BY text_similarity DESC;
+
+-- name: SearchRecipesByVectorRank :many
+-- The recipes nearest the query embedding, for reciprocal rank fusion. The
+-- text similarity is returned too so fused results carry both scores.
+WITH hits AS (
+    SELECT id FROM recipes
+    WHERE embedding IS NOT NULL
+    ORDER BY embedding <=> $2::vector
+    LIMIT $1
+)
+SELECT
+    r.id,
+    r.recipe_name,
+    r.description,
+    r.owner_id,
+    smo.username as owner_username,
+    si.storage_path as thumbnail_storage_path,
+    COALESCE(
+        array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as cuisine_categories,
+    COALESCE(
+        array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL),
+        ARRAY[]::text[]
+    ) as meal_types,
+    CAST(COALESCE(1 - (r.embedding <=> $2::vector), 0) AS float8) as vector_similarity,
+    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0) as float8) as text_similarity
+FROM hits h
+JOIN recipes r ON r.id = h.id
+LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
+LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
+LEFT JOIN recipe_meal_types rmt ON r.id = rmt.recipe_id
+LEFT JOIN meal_types mt ON rmt.meal_type_id = mt.id
+LEFT JOIN social_media_owners smo ON r.owner_id = smo.id
+LEFT JOIN recipe_images ri ON r.id = ri.recipe_id AND ri.image_type = 'full'
+LEFT JOIN stored_images si ON ri.stored_image_id = si.id
+GROUP BY r.id, r.recipe_name, r.description, r.owner_id, smo.username, si.storage_path, r.embedding, r.search_vector
+ORDER BY vector_similarity DESC;
 
 -- name: SearchRecipesHybrid :many
 SELECT
@@ -99,12 +246,12 @@ SELECT
     ) as meal_types,
     -- Vector similarity score (0-1)
     CAST(1 - (r.embedding <=> $2::vector) AS float8) as vector_similarity,
-    -- Text search score (0-1)
-    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0) as float8) as text_similarity,
-    -- Combined hybrid score
+    -- Text search score (0-1), using the query language's text search config
+    CAST(COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0) as float8) as text_similarity,
+    -- Combined hybrid score, weighted by the configured vector ($5) and text ($6) weights
     CAST(
-        0.7 * CAST(1 - (r.embedding <=> $2::vector) AS float8) +
-        0.3 * COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0)
+        $5::float8 * CAST(1 - (r.embedding <=> $2::vector) AS float8) +
+        $6::float8 * COALESCE(ts_rank(r.search_vector, plainto_tsquery($4::regconfig, $3)), 0)
         AS float8
     ) as hybrid_score
 FROM recipes r
@@ -127,8 +274,8 @@ SELECT
     COALESCE(array_agg(DISTINCT cc.name) FILTER (WHERE cc.name IS NOT NULL), ARRAY[]::text[]) as cuisine_categories,
     COALESCE(array_agg(DISTINCT mt.name) FILTER (WHERE mt.name IS NOT NULL), ARRAY[]::text[]) as meal_types,
     CAST(1 - (r.embedding <=> $2::vector) AS float8) as vector_similarity,
-    COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0) as text_rank,
-    CAST(0.7 * CAST(1 - (r.embedding <=> $2::vector) AS float8) + 0.3 * COALESCE(ts_rank(r.search_vector, plainto_tsquery('english', $3)), 0) AS float8) as hybrid_score
+    COALESCE(ts_rank(r.search_vector, plainto_tsquery($7::regconfig, $3)), 0) as text_rank,
+    CAST(0.7 * CAST(1 - (r.embedding <=> $2::vector) AS float8) + 0.3 * COALESCE(ts_rank(r.search_vector, plainto_tsquery($7::regconfig, $3)), 0) AS float8) as hybrid_score
 FROM recipes r
 LEFT JOIN recipe_cuisine_categories rcc ON r.id = rcc.recipe_id
 LEFT JOIN cuisine_categories cc ON rcc.cuisine_category_id = cc.id
@@ -0,0 +1,25 @@
+-- name: CreateServiceAPIKey :one
+INSERT INTO service_api_keys (
+    name, key_prefix, key_hash, scopes, allowed_user_ids, expires_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetServiceAPIKeyByHash :one
+SELECT * FROM service_api_keys
+WHERE key_hash = $1 AND revoked_at IS NULL;
+
+-- name: ListServiceAPIKeys :many
+SELECT * FROM service_api_keys
+ORDER BY created_at DESC;
+
+-- name: RevokeServiceAPIKey :one
+UPDATE service_api_keys
+SET revoked_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL
+RETURNING *;
+
+-- name: TouchServiceAPIKey :exec
+UPDATE service_api_keys
+SET last_used_at = NOW()
+WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute');
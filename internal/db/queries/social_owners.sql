@@ -3,6 +3,9 @@ SELECT * FROM social_media_owners
 WHERE origin_id = $1 AND platform = $2;
 
 -- name: CreateSocialMediaOwner :one
+WITH referenced AS (
+    UPDATE stored_images SET reference_count = reference_count + 1 WHERE id::text = $2
+)
 INSERT INTO social_media_owners (
     username, profile_pic_stored_image_id, origin_id, platform
 ) VALUES (
@@ -0,0 +1,51 @@
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    user_id, url, secret, events, description
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptions :many
+SELECT * FROM webhook_subscriptions
+WHERE user_id IS NOT DISTINCT FROM $1
+ORDER BY created_at DESC;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+SELECT * FROM webhook_subscriptions
+WHERE active AND (user_id = @user_id OR user_id IS NULL) AND @event::text = ANY(events);
+
+-- name: DeleteWebhookSubscription :one
+DELETE FROM webhook_subscriptions
+WHERE id = $1 AND user_id IS NOT DISTINCT FROM $2
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    subscription_id, event_id, event, payload, redelivery_of
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE subscription_id = $1
+ORDER BY created_at DESC
+LIMIT $2;
+
+-- name: UpdateWebhookDeliveryResult :exec
+UPDATE webhook_deliveries
+SET status = @status,
+    attempts = attempts + @attempts::int,
+    response_status = @response_status,
+    error = @error,
+    delivered_at = CASE WHEN @status = 'SUCCEEDED' THEN NOW() ELSE delivered_at END,
+    updated_at = NOW()
+WHERE id = @id;
@@ -0,0 +1,83 @@
+package errors
+
+import (
+	"encoding/json"
+	stderrors "errors"
+	"net/http"
+)
+
+// Detail is the client facing part of an AppError. It is the body of API
+// error responses and how failed jobs store their error.
+type Detail struct {
+	Code      string    `json:"code"`
+	Type      ErrorType `json:"type"`
+	Message   string    `json:"message"`
+	Recovery  string    `json:"recovery_suggestion,omitempty"`
+	RequestID string    `json:"request_id,omitempty"`
+}
+
+// Response is the JSON body of every API error response.
+type Response struct {
+	Error Detail `json:"error"`
+}
+
+// Detail returns what clients may see of the error. The wrapped cause is
+// left out: it can hold internal details and belongs in the logs.
+func (e *AppError) Detail() Detail {
+	return Detail{
+		Code:     e.ErrorCode,
+		Type:     e.Type,
+		Message:  e.Message,
+		Recovery: e.Recovery,
+	}
+}
+
+// JSON encodes the error's Detail for storing on a failed job.
+func (e *AppError) JSON() []byte {
+	data, _ := json.Marshal(e.Detail())
+	return data
+}
+
+// WriteJSON responds with the error as a JSON Response.
+func WriteJSON(w http.ResponseWriter, requestID string, err *AppError) {
+	detail := err.Detail()
+	detail.RequestID = requestID
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(err.StatusCode)
+	json.NewEncoder(w).Encode(Response{Error: detail})
+}
+
+// FromError returns the AppError in err's chain, or fallback when there is
+// none.
+func FromError(err error, fallback *AppError) *AppError {
+	var appErr *AppError
+	if stderrors.As(err, &appErr) {
+		return appErr
+	}
+	return fallback
+}
+
+// ParseDetail reads a job error stored by JSON. Errors stored as free text
+// before jobs recorded structured errors are returned as internal errors
+// with the text as message.
+func ParseDetail(raw []byte) *Detail {
+	if len(raw) == 0 {
+		return nil
+	}
+	var detail Detail
+	if err := json.Unmarshal(raw, &detail); err == nil && detail.Code != "" {
+		return &detail
+	}
+	message := string(raw)
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		message = text
+	}
+	return &Detail{
+		Code:    "IMPORT_FAILED",
+		Type:    ErrorTypeInternal,
+		Message: message,
+	}
+}
@@ -0,0 +1,95 @@
+package errors
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteJSON(t *testing.T) {
+	rr := httptest.NewRecorder()
+	WriteJSON(rr, "req-123", NewNotFoundError("Recipe not found", "RECIPE_NOT_FOUND", "Check the recipe ID"))
+
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rr.Code)
+	}
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("expected application/json, got %q", ct)
+	}
+
+	var body Response
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	want := Detail{
+		Code:      "RECIPE_NOT_FOUND",
+		Type:      ErrorTypeNotFound,
+		Message:   "Recipe not found",
+		Recovery:  "Check the recipe ID",
+		RequestID: "req-123",
+	}
+	if body.Error != want {
+		t.Errorf("expected %+v, got %+v", want, body.Error)
+	}
+}
+
+func TestAppError_JSONOmitsCause(t *testing.T) {
+	err := NewInternalError("Failed to save recipe", "RECIPE_SAVE_FAILED", errors.New("pq: connection refused"))
+	if strings.Contains(string(err.JSON()), "connection refused") {
+		t.Errorf("stored error leaks its cause: %s", err.JSON())
+	}
+}
+
+func TestFromError(t *testing.T) {
+	fallback := NewInternalError("fallback", "FALLBACK", nil)
+	appErr := NewScraperError("Post is private", "PRIVATE_POST", nil)
+
+	if got := FromError(fmt.Errorf("scrape: %w", appErr), fallback); got != appErr {
+		t.Errorf("expected wrapped AppError, got %v", got)
+	}
+	if got := FromError(errors.New("plain"), fallback); got != fallback {
+		t.Errorf("expected fallback, got %v", got)
+	}
+}
+
+func TestParseDetail(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  []byte
+		want *Detail
+	}{
+		{
+			name: "empty",
+			raw:  nil,
+			want: nil,
+		},
+		{
+			name: "structured",
+			raw:  NewTranscriptionError("Transcription failed", "TRANSCRIPTION_FAILED", nil).JSON(),
+			want: &Detail{Code: "TRANSCRIPTION_FAILED", Type: ErrorTypeTranscription, Message: "Transcription failed", Recovery: "Try providing a clearer video or audio source."},
+		},
+		{
+			name: "legacy text",
+			raw:  []byte("Instagram scrape failed: timeout"),
+			want: &Detail{Code: "IMPORT_FAILED", Type: ErrorTypeInternal, Message: "Instagram scrape failed: timeout"},
+		},
+		{
+			name: "legacy JSON string",
+			raw:  []byte(`"Recipe generation failed"`),
+			want: &Detail{Code: "IMPORT_FAILED", Type: ErrorTypeInternal, Message: "Recipe generation failed"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseDetail(tt.raw)
+			if (got == nil) != (tt.want == nil) || (got != nil && *got != *tt.want) {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
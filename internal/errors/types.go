@@ -16,6 +16,11 @@ const (
 	ErrorTypeRateLimit        ErrorType = "RATE_LIMIT_ERROR"
 	ErrorTypeNotFound         ErrorType = "NOT_FOUND_ERROR"
 	ErrorTypeInternal         ErrorType = "INTERNAL_ERROR"
+	ErrorTypeUnauthorized     ErrorType = "UNAUTHORIZED_ERROR"
+	ErrorTypeForbidden        ErrorType = "FORBIDDEN_ERROR"
+	ErrorTypeConflict         ErrorType = "CONFLICT_ERROR"
+	ErrorTypeUnavailable      ErrorType = "UNAVAILABLE_ERROR"
+	ErrorTypeCanceled         ErrorType = "CANCELED_ERROR"
 )
 
 // AppError represents a structured error for the application
@@ -135,3 +140,86 @@ func NewRecipeGenerationError(message string, errorCode string, err error) *AppE
 		Err:           err,
 	}
 }
+
+// NewUnauthorizedError creates a new authentication error (401)
+func NewUnauthorizedError(message string, errorCode string, suggestion string) *AppError {
+	return &AppError{
+		Type:          ErrorTypeUnauthorized,
+		Message:       message,
+		StatusCode:    http.StatusUnauthorized,
+		ErrorCode:     errorCode,
+		IsOperational: true,
+		Recovery:      suggestion,
+	}
+}
+
+// NewForbiddenError creates a new authorization error (403)
+func NewForbiddenError(message string, errorCode string, suggestion string) *AppError {
+	return &AppError{
+		Type:          ErrorTypeForbidden,
+		Message:       message,
+		StatusCode:    http.StatusForbidden,
+		ErrorCode:     errorCode,
+		IsOperational: true,
+		Recovery:      suggestion,
+	}
+}
+
+// NewConflictError creates a new error for requests that clash with the
+// current state of a resource (409)
+func NewConflictError(message string, errorCode string, suggestion string) *AppError {
+	return &AppError{
+		Type:          ErrorTypeConflict,
+		Message:       message,
+		StatusCode:    http.StatusConflict,
+		ErrorCode:     errorCode,
+		IsOperational: true,
+		Recovery:      suggestion,
+	}
+}
+
+// NewUnavailableError creates a new error for features that are disabled or
+// dependencies that are down (503)
+func NewUnavailableError(message string, errorCode string, suggestion string) *AppError {
+	return &AppError{
+		Type:          ErrorTypeUnavailable,
+		Message:       message,
+		StatusCode:    http.StatusServiceUnavailable,
+		ErrorCode:     errorCode,
+		IsOperational: true,
+		Recovery:      suggestion,
+	}
+}
+
+// NewCanceledError creates a new error for jobs stopped by the user (409)
+func NewCanceledError(message string, errorCode string) *AppError {
+	return &AppError{
+		Type:          ErrorTypeCanceled,
+		Message:       message,
+		StatusCode:    http.StatusConflict,
+		ErrorCode:     errorCode,
+		IsOperational: true,
+	}
+}
+
+// NewInternalError creates a new unexpected error (500). The message is
+// shown to clients, so the cause belongs in err rather than the message.
+func NewInternalError(message string, errorCode string, err error) *AppError {
+	return &AppError{
+		Type:          ErrorTypeInternal,
+		Message:       message,
+		StatusCode:    http.StatusInternalServerError,
+		ErrorCode:     errorCode,
+		IsOperational: false,
+		Recovery:      "Try again later. If the problem persists, contact support.",
+		Err:           err,
+	}
+}
+
+// WithStatus returns a copy of the error with another HTTP status, for
+// errors such as 413 or 422 that share a type with a more common status.
+func (e *AppError) WithStatus(statusCode int) *AppError {
+	c := *e
+	c.StatusCode = statusCode
+	return &c
+}
@@ -0,0 +1,190 @@
+// Package health serves the server's liveness and readiness endpoints.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Report statuses
+const (
+	StatusOK          = "ok"
+	StatusDegraded    = "degraded"
+	StatusUnavailable = "unavailable"
+	StatusDraining    = "draining"
+	StatusFailed      = "failed"
+)
+
+// checkTimeout bounds each dependency check, so a hung dependency cannot
+// stall readiness probes.
+const checkTimeout = 2 * time.Second
+
+// Check reports whether a dependency can be used.
+type Check func(ctx context.Context) error
+
+// CheckResult is the outcome of one dependency check. Errors are logged
+// rather than reported, as the endpoints are public.
+type CheckResult struct {
+	Status    string `json:"status"`
+	Required  bool   `json:"required"`
+	LatencyMS int64  `json:"latency_ms"`
+}
+
+// Report is the readiness of the server and its dependencies.
+type Report struct {
+	Status    string                 `json:"status"`
+	Checks    map[string]CheckResult `json:"checks"`
+	CheckedAt string                 `json:"checked_at"`
+}
+
+type namedCheck struct {
+	name     string
+	check    Check
+	required bool
+}
+
+// Checker runs dependency checks for readiness probes, reusing results for
+// a while so frequent probes don't load the dependencies.
+type Checker struct {
+	checks   []namedCheck
+	ttl      time.Duration
+	now      func() time.Time
+	draining atomic.Bool
+
+	mu     sync.Mutex
+	cached *Report
+	expiry time.Time
+}
+
+// NewChecker creates a checker that reuses results for ttl.
+func NewChecker(ttl time.Duration) *Checker {
+	return &Checker{ttl: ttl, now: time.Now}
+}
+
+// Require adds a dependency the server cannot serve requests without.
+func (c *Checker) Require(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check, required: true})
+}
+
+// Optional adds a dependency that is reported without making the server
+// unready, such as an AI provider that imports fall back from.
+func (c *Checker) Optional(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Drain makes the server unready, so load balancers stop routing to it
+// while in-flight requests finish.
+func (c *Checker) Drain() {
+	c.draining.Store(true)
+}
+
+// Check returns the readiness report, running the checks when the cached
+// one has expired.
+func (c *Checker) Check(ctx context.Context) Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cached == nil || !c.now().Before(c.expiry) {
+		// A probe giving up must not fail the results other probes reuse
+		report := c.run(context.WithoutCancel(ctx))
+		c.cached = &report
+		c.expiry = c.now().Add(c.ttl)
+	}
+
+	report := *c.cached
+	if c.draining.Load() {
+		report.Status = StatusDraining
+	}
+	return report
+}
+
+// run checks every dependency at once.
+func (c *Checker) run(ctx context.Context) Report {
+	results := make([]CheckResult, len(c.checks))
+	var wg sync.WaitGroup
+	for i, nc := range c.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+			defer cancel()
+
+			start := c.now()
+			err := nc.check(ctx)
+			results[i] = CheckResult{
+				Status:    StatusOK,
+				Required:  nc.required,
+				LatencyMS: c.now().Sub(start).Milliseconds(),
+			}
+			if err != nil {
+				slog.Warn("Health check failed", "check", nc.name, "error", err)
+				results[i].Status = StatusFailed
+			}
+		}()
+	}
+	wg.Wait()
+
+	report := Report{
+		Status:    StatusOK,
+		Checks:    make(map[string]CheckResult, len(c.checks)),
+		CheckedAt: c.now().UTC().Format("2006-01-02T15:04:05Z07:00"),
+	}
+	for i, nc := range c.checks {
+		report.Checks[nc.name] = results[i]
+		if results[i].Status == StatusOK {
+			continue
+		}
+		if nc.required {
+			report.Status = StatusUnavailable
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+	return report
+}
+
+// HandleLive reports that the process is serving requests. It checks no
+// dependencies, so an outage never gets healthy instances restarted.
+func (c *Checker) HandleLive(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"status": StatusOK})
+}
+
+// HandleReady reports whether the server can serve requests, with 503 when
+// a required dependency is down or the server is shutting down.
+func (c *Checker) HandleReady(w http.ResponseWriter, r *http.Request) {
+	report := c.Check(r.Context())
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if report.Status == StatusUnavailable || report.Status == StatusDraining {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(report)
+}
+
+// HTTPCheck checks that url can be reached; any response below 500 counts,
+// as it shows the service is up even when the request is unauthorized.
+func HTTPCheck(client *http.Client, url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+		}
+		return nil
+	}
+}
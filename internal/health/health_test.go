@@ -0,0 +1,107 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func up(ctx context.Context) error   { return nil }
+func down(ctx context.Context) error { return errors.New("connection refused") }
+
+func TestHandleReady(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   Check
+		optional   Check
+		drain      bool
+		wantStatus int
+		wantReport string
+	}{
+		{name: "ready", required: up, optional: up, wantStatus: http.StatusOK, wantReport: StatusOK},
+		{name: "provider down", required: up, optional: down, wantStatus: http.StatusOK, wantReport: StatusDegraded},
+		{name: "database down", required: down, optional: up, wantStatus: http.StatusServiceUnavailable, wantReport: StatusUnavailable},
+		{name: "draining", required: up, optional: up, drain: true, wantStatus: http.StatusServiceUnavailable, wantReport: StatusDraining},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewChecker(time.Minute)
+			checker.Require("database", tt.required)
+			checker.Optional("openai", tt.optional)
+			if tt.drain {
+				checker.Drain()
+			}
+
+			rr := httptest.NewRecorder()
+			checker.HandleReady(rr, httptest.NewRequest("GET", "/readyz", nil))
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+			var report Report
+			if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+				t.Fatalf("invalid report: %v", err)
+			}
+			if report.Status != tt.wantReport {
+				t.Errorf("expected report status %q, got %q", tt.wantReport, report.Status)
+			}
+			if len(report.Checks) != 2 || !report.Checks["database"].Required || report.Checks["openai"].Required {
+				t.Errorf("expected both checks to be reported, got %+v", report.Checks)
+			}
+		})
+	}
+}
+
+func TestChecker_CachesResults(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	calls := 0
+	checker := NewChecker(5 * time.Second)
+	checker.now = func() time.Time { return now }
+	checker.Require("redis", func(ctx context.Context) error {
+		calls++
+		return nil
+	})
+
+	checker.Check(context.Background())
+	now = now.Add(4 * time.Second)
+	checker.Check(context.Background())
+	if calls != 1 {
+		t.Errorf("expected the cached result to be reused, got %d checks", calls)
+	}
+
+	now = now.Add(time.Second)
+	checker.Check(context.Background())
+	if calls != 2 {
+		t.Errorf("expected an expired result to be checked again, got %d checks", calls)
+	}
+}
+
+func TestHTTPCheck(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized},
+		{name: "server error", status: http.StatusBadGateway, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			err := HTTPCheck(srv.Client(), srv.URL)(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
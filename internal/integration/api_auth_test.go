@@ -347,7 +347,7 @@ func TestAuthMiddleware_ValidToken(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			token := createTestToken(cfg.SupabaseJWTSecret, cfg.SupabaseURL, tt.userID)
 
-			handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				userID, ok := middleware.GetUserID(r.Context())
 				if !ok {
 					t.Error("expected userID in context but not found")
@@ -408,7 +408,7 @@ func TestAuthMiddleware_InvalidToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				w.WriteHeader(http.StatusOK)
 			}))
 
@@ -435,7 +435,7 @@ func TestAuthMiddleware_ExpiredToken(t *testing.T) {
 
 	token := createExpiredToken(cfg.SupabaseJWTSecret, cfg.SupabaseURL, "user-123")
 
-	handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -458,7 +458,7 @@ func TestAuthMiddleware_InvalidSignature(t *testing.T) {
 
 	token := createInvalidSignatureToken(cfg.SupabaseURL, "user-123")
 
-	handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -482,7 +482,7 @@ func TestAuthMiddleware_InvalidIssuer(t *testing.T) {
 	// Create token with wrong issuer
 	token := createTestToken(cfg.SupabaseJWTSecret, "https://wrong.supabase.co", "user-123")
 
-	handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -511,7 +511,7 @@ func TestAuthMiddleware_MissingSubClaim(t *testing.T) {
 	})
 	tokenString, _ := token.SignedString([]byte(cfg.SupabaseJWTSecret))
 
-	handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
@@ -626,7 +626,7 @@ func TestFullAuthFlow_Success(t *testing.T) {
 	token := createTestToken(cfg.SupabaseJWTSecret, cfg.SupabaseURL, userID)
 
 	// Create a handler chain with auth middleware
-	authHandler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	authHandler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// After auth middleware, the user ID should be in context
 		ctxUserID, ok := middleware.GetUserID(r.Context())
 		if !ok {
@@ -660,7 +660,7 @@ func TestFullAuthFlow_Failure(t *testing.T) {
 	// Test with expired token
 	token := createExpiredToken(cfg.SupabaseJWTSecret, cfg.SupabaseURL, "user-123")
 
-	handler := middleware.AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	handler := middleware.AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusOK)
 	}))
 
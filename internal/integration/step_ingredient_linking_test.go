@@ -346,6 +346,22 @@ func (m *MockStorageClientFixed) UploadImageWithHash(ctx context.Context, bucket
 	return "https://storage.example.com/" + path, nil
 }
 
+func (m *MockStorageClientFixed) DeleteObject(ctx context.Context, bucket, path string) error {
+	return nil
+}
+
+func (m *MockStorageClientFixed) UploadObject(ctx context.Context, bucket, path string, data []byte, contentType string) error {
+	return nil
+}
+
+func (m *MockStorageClientFixed) DownloadObject(ctx context.Context, bucket, path string) ([]byte, error) {
+	return nil, nil
+}
+
+func (m *MockStorageClientFixed) GetPublicURL(bucket, path string) string {
+	return "https://storage.example.com/" + path
+}
+
 func (m *MockStorageClientFixed) GetImageByHash(ctx context.Context, hash string) (*storage.ExistingImageResponse, error) {
 	return &storage.ExistingImageResponse{
 		ID:          uuid.New().String(),
@@ -1,7 +1,10 @@
 package metrics
 
 import (
+	"context"
+
 	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/metric"
 )
 
@@ -21,6 +24,13 @@ var (
 
 	// Provider fallback metrics
 	ProviderFallbackTotal metric.Int64Counter
+
+	// Cache metrics
+	CacheHitsTotal   metric.Int64Counter
+	CacheMissesTotal metric.Int64Counter
+
+	// API metrics
+	LegacyRouteRequestsTotal metric.Int64Counter
 )
 
 func Init() error {
@@ -87,5 +97,60 @@ func Init() error {
 		return err
 	}
 
+	// Cache metrics
+	CacheHitsTotal, err = meter.Int64Counter(
+		"cache.hits.total",
+		metric.WithDescription("Total number of cache hits"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	CacheMissesTotal, err = meter.Int64Counter(
+		"cache.misses.total",
+		metric.WithDescription("Total number of cache misses"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
+	// API metrics
+	LegacyRouteRequestsTotal, err = meter.Int64Counter(
+		"http.legacy_route.requests.total",
+		metric.WithDescription("Total number of requests to deprecated pre-v1 routes"),
+		metric.WithUnit("1"),
+	)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
+
+// RecordCacheLookup counts a hit or miss for the named cache. It is a no-op
+// until Init has run, so caches can be used from tests and tools.
+func RecordCacheLookup(ctx context.Context, cache string, hit bool) {
+	counter := CacheMissesTotal
+	if hit {
+		counter = CacheHitsTotal
+	}
+	if counter == nil {
+		return
+	}
+	counter.Add(ctx, 1, metric.WithAttributes(attribute.String("cache", cache)))
+}
+
+// RecordLegacyRoute counts a request to a deprecated route, by method and
+// route pattern, to show which old routes clients still call. It is a no-op
+// until Init has run.
+func RecordLegacyRoute(ctx context.Context, method, route string) {
+	if LegacyRouteRequestsTotal == nil {
+		return
+	}
+	LegacyRouteRequestsTotal.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("method", method),
+		attribute.String("route", route),
+	))
+}
@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/socialchef/remy/internal/config"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/apikey"
+)
+
+// AdminKey holds who made an admin request, see GetAdmin.
+const AdminKey contextKey = "admin"
+
+// AdminRole is the `app_metadata.role` that makes a Supabase user an
+// operator. app_metadata can only be changed with the service role key.
+const AdminRole = "admin"
+
+var (
+	errAdminDisabled     = apperrors.NewForbiddenError("Admin API is disabled", "ADMIN_API_DISABLED", "Set ADMIN_API_TOKEN to enable it.")
+	errMissingAdminToken = apperrors.NewUnauthorizedError("Missing admin token", "MISSING_ADMIN_TOKEN", "Send the admin token as `Authorization: Bearer <token>`.")
+	errInvalidAdminToken = apperrors.NewUnauthorizedError("Invalid admin token", "INVALID_ADMIN_TOKEN", "")
+	errNotAdmin          = apperrors.NewForbiddenError("Admin role required", "ADMIN_ROLE_REQUIRED", "")
+)
+
+// AdminMiddleware restricts a route group to operators. They authenticate
+// with `Authorization: Bearer <token>` holding one of:
+//
+//   - the admin API token (ADMIN_API_TOKEN)
+//   - a service API key with the admin scope
+//   - a Supabase access token of a user whose `app_metadata.role` is admin
+//
+// keys may be nil, which disables service API keys. When no method is
+// configured the admin API is disabled and every request is rejected.
+func AdminMiddleware(cfg *config.Config, keys ServiceKeyStore) func(http.Handler) http.Handler {
+	verifyToken := newTokenVerifier(cfg)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if cfg.AdminAPIToken == "" && keys == nil && cfg.SupabaseURL == "" {
+				WriteError(w, r, errAdminDisabled)
+				return
+			}
+
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			token = strings.TrimSpace(token)
+			if !ok || token == "" {
+				WriteError(w, r, errMissingAdminToken)
+				return
+			}
+
+			var admin string
+			switch {
+			case cfg.AdminAPIToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(cfg.AdminAPIToken)) == 1:
+				admin = "admin-token"
+
+			case keys != nil && apikey.IsKey(token):
+				caller, _, appErr := authenticateServiceKey(r.Context(), cfg, keys, token)
+				if appErr != nil {
+					WriteError(w, r, appErr)
+					return
+				}
+				if !apikey.HasScope(caller.scopes, apikey.ScopeAdmin) {
+					WriteError(w, r, errNotAdmin)
+					return
+				}
+				admin = "service:" + caller.name
+
+			case cfg.SupabaseURL != "" && strings.Count(token, ".") == 2:
+				claims, appErr := verifyToken(token)
+				if appErr != nil {
+					WriteError(w, r, appErr)
+					return
+				}
+				userID, _ := claims["sub"].(string)
+				appMetadata, _ := claims["app_metadata"].(map[string]interface{})
+				if role, _ := appMetadata["role"].(string); role != AdminRole || userID == "" {
+					WriteError(w, r, errNotAdmin)
+					return
+				}
+				admin = "user:" + userID
+
+			default:
+				WriteError(w, r, errInvalidAdminToken)
+				return
+			}
+
+			slog.Info("Admin request", "admin", admin, "method", r.Method, "path", r.URL.Path, "request_id", GetRequestID(r.Context()))
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), AdminKey, admin)))
+		})
+	}
+}
+
+// GetAdmin returns who made an admin request: "admin-token",
+// "service:<key name>" or "user:<user id>".
+func GetAdmin(ctx context.Context) string {
+	admin, _ := ctx.Value(AdminKey).(string)
+	return admin
+}
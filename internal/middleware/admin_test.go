@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/apikey"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		adminToken     string
+		authHeader     string
+		expectedStatus int
+	}{
+		{
+			name:           "Admin API disabled",
+			adminToken:     "",
+			authHeader:     "Bearer anything",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Missing token",
+			adminToken:     "admin-secret",
+			authHeader:     "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Wrong token",
+			adminToken:     "admin-secret",
+			authHeader:     "Bearer not-the-secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Not a bearer token",
+			adminToken:     "admin-secret",
+			authHeader:     "admin-secret",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Valid token",
+			adminToken:     "admin-secret",
+			authHeader:     "Bearer admin-secret",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{AdminAPIToken: tt.adminToken}
+			handler := AdminMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/api/admin/embeddings/backfill", nil)
+			if tt.authHeader != "" {
+				req.Header.Set("Authorization", tt.authHeader)
+			}
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestAdminMiddleware_RoleAndServiceKey(t *testing.T) {
+	secret := "test-secret"
+	supabaseURL := "https://test.supabase.co"
+	cfg := &config.Config{
+		SupabaseURL:       supabaseURL,
+		SupabaseJWTSecret: secret,
+	}
+
+	userToken := func(appMetadata map[string]interface{}) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"sub":          "11111111-2222-3333-4444-555555555555",
+			"iss":          supabaseURL + "/auth/v1",
+			"exp":          time.Now().Add(time.Hour).Unix(),
+			"app_metadata": appMetadata,
+		})
+		s, _ := token.SignedString([]byte(secret))
+		return s
+	}
+
+	store := &fakeKeyStore{keys: map[string]generated.ServiceApiKey{}}
+	newKey := func(scopes ...string) string {
+		k, _ := apikey.Generate()
+		store.keys[k.Hash] = generated.ServiceApiKey{
+			ID:     pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Name:   "ops",
+			Scopes: scopes,
+		}
+		return k.Secret
+	}
+
+	tests := []struct {
+		name           string
+		token          string
+		expectedStatus int
+		expectedAdmin  string
+	}{
+		{
+			name:           "Admin role",
+			token:          userToken(map[string]interface{}{"role": "admin"}),
+			expectedStatus: http.StatusOK,
+			expectedAdmin:  "user:11111111-2222-3333-4444-555555555555",
+		},
+		{
+			name:           "User without admin role",
+			token:          userToken(map[string]interface{}{"provider": "email"}),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Service key with admin scope",
+			token:          newKey(apikey.ScopeAdmin),
+			expectedStatus: http.StatusOK,
+			expectedAdmin:  "service:ops",
+		},
+		{
+			name:           "Service key without admin scope",
+			token:          newKey(apikey.ScopeImportWrite),
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "Unknown service key",
+			token:          apikey.Prefix + "unknown-key-value",
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AdminMiddleware(cfg, store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if admin := GetAdmin(r.Context()); admin != tt.expectedAdmin {
+					t.Errorf("expected admin %q, got %q", tt.expectedAdmin, admin)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/api/admin/queues", nil)
+			req.Header.Set("Authorization", "Bearer "+tt.token)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d; body=%s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+}
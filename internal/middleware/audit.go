@@ -0,0 +1,141 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+// auditKey holds the audit entry handlers fill in for the request.
+const auditKey contextKey = "audit"
+
+// Audit outcomes
+const (
+	AuditOutcomeSuccess = "success"
+	AuditOutcomeFailure = "failure"
+)
+
+// AuditStore appends audit events; *generated.Queries satisfies it.
+type AuditStore interface {
+	CreateAuditEvent(ctx context.Context, arg generated.CreateAuditEventParams) error
+}
+
+// auditEntry is what a handler says about the request it served.
+type auditEntry struct {
+	action       string
+	resourceType string
+	resourceID   string
+}
+
+// Audit records every data-changing request (POST, PUT, PATCH and DELETE)
+// in audit_events after the handler has responded: who made it, the service
+// acting for them, the action and resource, the request ID and whether it
+// succeeded. Handlers name the action and resource with SetAuditAction;
+// otherwise the action is the method and route pattern. It must run after
+// authentication. A nil store disables auditing.
+func Audit(store AuditStore) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if store == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+			default:
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			entry := &auditEntry{}
+			recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), auditKey, entry)))
+
+			event := newAuditEvent(r, entry, recorder.status)
+			if err := store.CreateAuditEvent(context.WithoutCancel(r.Context()), event); err != nil {
+				slog.Error("Failed to record audit event", "error", err,
+					"action", event.Action, "actor", event.Actor, "request_id", event.RequestID.String)
+			}
+		})
+	}
+}
+
+// SetAuditAction names the action a handler took, such as "import.create",
+// and the resource it acted on. It does nothing outside Audit.
+func SetAuditAction(ctx context.Context, action, resourceType, resourceID string) {
+	entry, ok := ctx.Value(auditKey).(*auditEntry)
+	if !ok {
+		return
+	}
+	entry.action = action
+	entry.resourceType = resourceType
+	entry.resourceID = resourceID
+}
+
+// newAuditEvent describes the request as an audit event. Admins are
+// recorded as the admin; users as themselves, with the service that acted
+// for them through X-On-Behalf-Of as impersonator.
+func newAuditEvent(r *http.Request, entry *auditEntry, status int) generated.CreateAuditEventParams {
+	ctx := r.Context()
+	event := generated.CreateAuditEventParams{
+		Action:       entry.action,
+		ResourceType: pgtype.Text{String: entry.resourceType, Valid: entry.resourceType != ""},
+		ResourceID:   pgtype.Text{String: entry.resourceID, Valid: entry.resourceID != ""},
+		Method:       r.Method,
+		Path:         r.URL.Path,
+		StatusCode:   int32(status),
+		Outcome:      AuditOutcomeSuccess,
+	}
+	if event.Action == "" {
+		pattern := r.URL.Path
+		if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+			pattern = rctx.RoutePattern()
+		}
+		event.Action = r.Method + " " + pattern
+	}
+	if status >= http.StatusBadRequest {
+		event.Outcome = AuditOutcomeFailure
+	}
+	if id := GetRequestID(ctx); id != "" {
+		event.RequestID = pgtype.Text{String: id, Valid: true}
+	}
+
+	userID, _ := GetUserID(ctx)
+	if admin := GetAdmin(ctx); admin != "" {
+		event.Actor = admin
+		userID = strings.TrimPrefix(admin, "user:")
+	} else {
+		event.Actor = "user:" + userID
+		if service, ok := GetService(ctx); ok {
+			event.Impersonator = pgtype.Text{String: "service:" + service, Valid: true}
+		}
+	}
+	if id, err := uuid.Parse(userID); err == nil {
+		event.UserID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+	return event
+}
+
+// statusRecorder remembers the status code a handler responded with.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
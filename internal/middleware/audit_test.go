@@ -0,0 +1,150 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+type fakeAuditStore struct {
+	events []generated.CreateAuditEventParams
+}
+
+func (f *fakeAuditStore) CreateAuditEvent(ctx context.Context, arg generated.CreateAuditEventParams) error {
+	f.events = append(f.events, arg)
+	return nil
+}
+
+func TestAudit(t *testing.T) {
+	userID := uuid.New().String()
+
+	tests := []struct {
+		name             string
+		method           string
+		ctx              func(context.Context) context.Context
+		status           int
+		named            bool
+		wantRecorded     bool
+		wantActor        string
+		wantImpersonator string
+		wantAction       string
+		wantOutcome      string
+	}{
+		{
+			name:         "User import",
+			method:       http.MethodPost,
+			ctx:          func(ctx context.Context) context.Context { return context.WithValue(ctx, UserIDKey, userID) },
+			status:       http.StatusAccepted,
+			named:        true,
+			wantRecorded: true,
+			wantActor:    "user:" + userID,
+			wantAction:   "bulk_import.cancel",
+			wantOutcome:  AuditOutcomeSuccess,
+		},
+		{
+			name:   "Service acting for a user",
+			method: http.MethodDelete,
+			ctx: func(ctx context.Context) context.Context {
+				ctx = context.WithValue(ctx, UserIDKey, userID)
+				return context.WithValue(ctx, ServiceKey, "meal-planner")
+			},
+			status:           http.StatusOK,
+			named:            true,
+			wantRecorded:     true,
+			wantActor:        "user:" + userID,
+			wantImpersonator: "service:meal-planner",
+			wantAction:       "bulk_import.cancel",
+			wantOutcome:      AuditOutcomeSuccess,
+		},
+		{
+			name:         "Failed request without a named action",
+			method:       http.MethodDelete,
+			ctx:          func(ctx context.Context) context.Context { return context.WithValue(ctx, UserIDKey, userID) },
+			status:       http.StatusNotFound,
+			wantRecorded: true,
+			wantActor:    "user:" + userID,
+			wantAction:   "DELETE /api/bulk-import/{bulkJobID}",
+			wantOutcome:  AuditOutcomeFailure,
+		},
+		{
+			name:         "Admin",
+			method:       http.MethodDelete,
+			ctx:          func(ctx context.Context) context.Context { return context.WithValue(ctx, AdminKey, "admin-token") },
+			status:       http.StatusOK,
+			named:        true,
+			wantRecorded: true,
+			wantActor:    "admin-token",
+			wantAction:   "bulk_import.cancel",
+			wantOutcome:  AuditOutcomeSuccess,
+		},
+		{
+			name:   "Read request",
+			method: http.MethodGet,
+			ctx:    func(ctx context.Context) context.Context { return context.WithValue(ctx, UserIDKey, userID) },
+			status: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeAuditStore{}
+			r := chi.NewRouter()
+			r.Use(RequestID)
+			r.Use(func(next http.Handler) http.Handler {
+				return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					next.ServeHTTP(w, r.WithContext(tt.ctx(r.Context())))
+				})
+			})
+			r.Group(func(r chi.Router) {
+				r.Use(Audit(store))
+				r.MethodFunc(tt.method, "/api/bulk-import/{bulkJobID}", func(w http.ResponseWriter, r *http.Request) {
+					if tt.named {
+						SetAuditAction(r.Context(), "bulk_import.cancel", "bulk_import", chi.URLParam(r, "bulkJobID"))
+					}
+					w.WriteHeader(tt.status)
+				})
+			})
+
+			req := httptest.NewRequest(tt.method, "/api/bulk-import/job-1", nil)
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if !tt.wantRecorded {
+				if len(store.events) != 0 {
+					t.Fatalf("expected no audit event, got %+v", store.events)
+				}
+				return
+			}
+			if len(store.events) != 1 {
+				t.Fatalf("expected 1 audit event, got %d", len(store.events))
+			}
+			event := store.events[0]
+			if event.Actor != tt.wantActor {
+				t.Errorf("actor = %q, expected %q", event.Actor, tt.wantActor)
+			}
+			if event.Impersonator.String != tt.wantImpersonator {
+				t.Errorf("impersonator = %q, expected %q", event.Impersonator.String, tt.wantImpersonator)
+			}
+			if event.Action != tt.wantAction {
+				t.Errorf("action = %q, expected %q", event.Action, tt.wantAction)
+			}
+			if event.Outcome != tt.wantOutcome || event.StatusCode != int32(tt.status) {
+				t.Errorf("outcome = %s (%d), expected %s (%d)", event.Outcome, event.StatusCode, tt.wantOutcome, tt.status)
+			}
+			if event.RequestID.String != rr.Header().Get(RequestIDHeader) {
+				t.Errorf("request ID = %q, expected the response's %q", event.RequestID.String, rr.Header().Get(RequestIDHeader))
+			}
+			if tt.named && event.ResourceID.String != "job-1" {
+				t.Errorf("resource ID = %q, expected job-1", event.ResourceID.String)
+			}
+			if wantUser := tt.wantActor != "admin-token"; event.UserID.Valid != wantUser {
+				t.Errorf("user ID set = %v, expected %v", event.UserID.Valid, wantUser)
+			}
+		})
+	}
+}
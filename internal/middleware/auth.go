@@ -5,7 +5,6 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rsa"
-	"crypto/subtle"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
@@ -18,12 +17,19 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/socialchef/remy/internal/config"
+	apperrors "github.com/socialchef/remy/internal/errors"
 )
 
 type contextKey string
 
 const UserIDKey contextKey = "userID"
 
+// ServiceKey marks requests authenticated with the internal service token.
+const ServiceKey contextKey = "service"
+
+// internalService names callers holding the internal service token.
+const internalService = "internal"
+
 type JWKSKey struct {
 	Kty string `json:"kty"`
 	Alg string `json:"alg"`
@@ -236,136 +242,155 @@ func parseECPublicKey(crv, xStr, yStr string) (*ecdsa.PublicKey, error) {
 // uuid so we don't write arbitrary strings into Supabase user_id columns.
 var uuidRegex = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
 
-func AuthMiddleware(cfg *config.Config) func(http.Handler) http.Handler {
-	var jwksManager *JWKSManager
-	if cfg.SupabaseURL != "" {
-		jwksManager = NewJWKSManager(cfg.SupabaseURL, cfg.SupabaseAnonKey)
-	}
+// Authentication failures. Token validation errors are not passed on to the
+// caller: they only tell an attacker which check failed.
+var (
+	errUnauthorized         = apperrors.NewUnauthorizedError("Unauthorized", "UNAUTHORIZED", "Sign in again and retry the request.")
+	errInvalidAPIKey        = apperrors.NewUnauthorizedError("Invalid X-API-Key", "INVALID_API_KEY", "")
+	errMissingOnBehalfOf    = apperrors.NewUnauthorizedError("Service token requires X-On-Behalf-Of header", "MISSING_ON_BEHALF_OF", "Send the user UUID in X-On-Behalf-Of.")
+	errInvalidOnBehalfOf    = apperrors.NewUnauthorizedError("X-On-Behalf-Of must be a user UUID", "INVALID_ON_BEHALF_OF", "Send the user UUID in X-On-Behalf-Of.")
+	errMissingAuthorization = apperrors.NewUnauthorizedError("Missing Authorization header", "MISSING_AUTHORIZATION", "Send the access token as `Authorization: Bearer <token>`.")
+	errInvalidAuthorization = apperrors.NewUnauthorizedError("Invalid Authorization header format", "INVALID_AUTHORIZATION_HEADER", "Send the access token as `Authorization: Bearer <token>`.")
+	errInvalidToken         = apperrors.NewUnauthorizedError("Invalid or expired token", "INVALID_TOKEN", "Sign in again and retry the request.")
+	errInvalidIssuer        = apperrors.NewUnauthorizedError("Token was not issued by this project", "INVALID_TOKEN_ISSUER", "Sign in again and retry the request.")
+)
+
+// AuthMiddleware authenticates users by Supabase JWT, and sibling services
+// by service API key (see cmd/apikey) or the legacy INTERNAL_SERVICE_TOKEN.
+// Services act for the user in `X-On-Behalf-Of`. keys may be nil, which
+// disables service API keys.
+func AuthMiddleware(cfg *config.Config, keys ServiceKeyStore) func(http.Handler) http.Handler {
+	verifyToken := newTokenVerifier(cfg)
 
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			// Sibling services (e.g. Sous) may send the shared secret in `X-API-Key`
-			// instead of `Authorization: Bearer` so a hex string is not mistaken for
-			// a JWT. Same rules as the Bearer service-token path: requires
+			// Sibling services (e.g. Sous) may send their key in `X-API-Key`
+			// instead of `Authorization: Bearer` so it is not mistaken for a
+			// JWT. Same rules as the Bearer service path: requires
 			// `X-On-Behalf-Of: <user uuid>`.
-			if cfg.InternalServiceToken != "" {
+			if keys != nil || cfg.InternalServiceToken != "" {
 				xKey := strings.TrimSpace(r.Header.Get("X-API-Key"))
 				if xKey != "" {
-					if subtle.ConstantTimeCompare([]byte(xKey), []byte(cfg.InternalServiceToken)) != 1 {
-						http.Error(w, "Unauthorized: invalid X-API-Key", http.StatusUnauthorized)
-						return
-					}
-					onBehalfOf := strings.TrimSpace(r.Header.Get("X-On-Behalf-Of"))
-					if onBehalfOf == "" {
-						http.Error(w, "Unauthorized: service token requires X-On-Behalf-Of header", http.StatusUnauthorized)
-						return
+					caller, ok, appErr := authenticateServiceKey(r.Context(), cfg, keys, xKey)
+					if appErr == nil && !ok {
+						appErr = errInvalidAPIKey
 					}
-					if !uuidRegex.MatchString(onBehalfOf) {
-						http.Error(w, "Unauthorized: X-On-Behalf-Of must be a user UUID", http.StatusUnauthorized)
+					if appErr != nil {
+						WriteError(w, r, appErr)
 						return
 					}
-					ctx := context.WithValue(r.Context(), UserIDKey, onBehalfOf)
-					next.ServeHTTP(w, r.WithContext(ctx))
+					serveAsService(w, r, next, caller)
 					return
 				}
 			}
 
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				http.Error(w, "Unauthorized: Missing Authorization header", http.StatusUnauthorized)
+				WriteError(w, r, errMissingAuthorization)
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				http.Error(w, "Unauthorized: Invalid Authorization header format", http.StatusUnauthorized)
+				WriteError(w, r, errInvalidAuthorization)
 				return
 			}
 
 			tokenString := parts[1]
 
-			// Service-token auth path: trusted sibling services (e.g. Sous)
-			// present the shared secret plus `X-On-Behalf-Of: <uuid>` to
-			// impersonate the target user. We short-circuit JWT parsing.
-			if cfg.InternalServiceToken != "" && subtle.ConstantTimeCompare([]byte(tokenString), []byte(cfg.InternalServiceToken)) == 1 {
-				onBehalfOf := strings.TrimSpace(r.Header.Get("X-On-Behalf-Of"))
-				if onBehalfOf == "" {
-					http.Error(w, "Unauthorized: service token requires X-On-Behalf-Of header", http.StatusUnauthorized)
-					return
-				}
-				if !uuidRegex.MatchString(onBehalfOf) {
-					http.Error(w, "Unauthorized: X-On-Behalf-Of must be a user UUID", http.StatusUnauthorized)
-					return
-				}
-				ctx := context.WithValue(r.Context(), UserIDKey, onBehalfOf)
-				next.ServeHTTP(w, r.WithContext(ctx))
+			// Service auth path: trusted sibling services present their key
+			// plus `X-On-Behalf-Of: <uuid>` to impersonate the target user. We
+			// short-circuit JWT parsing.
+			caller, ok, appErr := authenticateServiceKey(r.Context(), cfg, keys, tokenString)
+			if appErr != nil {
+				WriteError(w, r, appErr)
+				return
+			}
+			if ok {
+				serveAsService(w, r, next, caller)
 				return
 			}
 
-			token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-				switch token.Method.(type) {
-				case *jwt.SigningMethodRSA:
-					if jwksManager == nil {
-						return nil, fmt.Errorf("JWKS not configured")
-					}
+			claims, appErr := verifyToken(tokenString)
+			if appErr != nil {
+				WriteError(w, r, appErr)
+				return
+			}
 
-					kid, ok := token.Header["kid"].(string)
-					if !ok {
-						return nil, fmt.Errorf("RSA token missing kid header")
-					}
+			userID, ok := claims["sub"].(string)
+			if !ok || userID == "" {
+				WriteError(w, r, errInvalidToken)
+				return
+			}
 
-					return jwksManager.GetRSAKey(kid)
+			ctx := context.WithValue(r.Context(), UserIDKey, userID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
 
-				case *jwt.SigningMethodECDSA:
-					if jwksManager == nil {
-						return nil, fmt.Errorf("JWKS not configured")
-					}
+// newTokenVerifier returns a function checking a Supabase access token's
+// signature, expiry and issuer, and returning its claims.
+func newTokenVerifier(cfg *config.Config) func(tokenString string) (jwt.MapClaims, *apperrors.AppError) {
+	var jwksManager *JWKSManager
+	if cfg.SupabaseURL != "" {
+		jwksManager = NewJWKSManager(cfg.SupabaseURL, cfg.SupabaseAnonKey)
+	}
 
-					kid, ok := token.Header["kid"].(string)
-					if !ok {
-						return nil, fmt.Errorf("ECDSA token missing kid header")
-					}
+	return func(tokenString string) (jwt.MapClaims, *apperrors.AppError) {
+		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+			switch token.Method.(type) {
+			case *jwt.SigningMethodRSA:
+				if jwksManager == nil {
+					return nil, fmt.Errorf("JWKS not configured")
+				}
 
-					return jwksManager.GetECKey(kid)
+				kid, ok := token.Header["kid"].(string)
+				if !ok {
+					return nil, fmt.Errorf("RSA token missing kid header")
+				}
 
-				case *jwt.SigningMethodHMAC:
-					if cfg.SupabaseJWTSecret == "" {
-						return nil, fmt.Errorf("JWT secret not configured")
-					}
-					return []byte(cfg.SupabaseJWTSecret), nil
+				return jwksManager.GetRSAKey(kid)
 
-				default:
-					return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+			case *jwt.SigningMethodECDSA:
+				if jwksManager == nil {
+					return nil, fmt.Errorf("JWKS not configured")
 				}
-			})
 
-			if err != nil || !token.Valid {
-				http.Error(w, fmt.Sprintf("Unauthorized: %v", err), http.StatusUnauthorized)
-				return
-			}
+				kid, ok := token.Header["kid"].(string)
+				if !ok {
+					return nil, fmt.Errorf("ECDSA token missing kid header")
+				}
 
-			claims, ok := token.Claims.(jwt.MapClaims)
-			if !ok {
-				http.Error(w, "Unauthorized: Invalid claims", http.StatusUnauthorized)
-				return
-			}
+				return jwksManager.GetECKey(kid)
 
-			iss, _ := claims["iss"].(string)
-			expectedIss := cfg.SupabaseURL + "/auth/v1"
-			if iss != expectedIss {
-				http.Error(w, "Unauthorized: Invalid issuer", http.StatusUnauthorized)
-				return
-			}
+			case *jwt.SigningMethodHMAC:
+				if cfg.SupabaseJWTSecret == "" {
+					return nil, fmt.Errorf("JWT secret not configured")
+				}
+				return []byte(cfg.SupabaseJWTSecret), nil
 
-			userID, ok := claims["sub"].(string)
-			if !ok || userID == "" {
-				http.Error(w, "Unauthorized: Missing sub claim", http.StatusUnauthorized)
-				return
+			default:
+				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 			}
-
-			ctx := context.WithValue(r.Context(), UserIDKey, userID)
-			next.ServeHTTP(w, r.WithContext(ctx))
 		})
+
+		if err != nil || !token.Valid {
+			return nil, errInvalidToken
+		}
+
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
+			return nil, errInvalidToken
+		}
+
+		iss, _ := claims["iss"].(string)
+		expectedIss := cfg.SupabaseURL + "/auth/v1"
+		if iss != expectedIss {
+			return nil, errInvalidIssuer
+		}
+
+		return claims, nil
 	}
 }
 
@@ -374,10 +399,17 @@ func GetUserID(ctx context.Context) (string, bool) {
 	return userID, ok
 }
 
+// GetService returns the calling service for requests authenticated with a
+// service token.
+func GetService(ctx context.Context) (string, bool) {
+	service, ok := ctx.Value(ServiceKey).(string)
+	return service, ok
+}
+
 func RequireAuth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if _, ok := GetUserID(r.Context()); !ok {
-			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			WriteError(w, r, errUnauthorized)
 			return
 		}
 		next.ServeHTTP(w, r)
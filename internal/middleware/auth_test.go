@@ -1,6 +1,7 @@
 package middleware
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -8,6 +9,7 @@ import (
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/socialchef/remy/internal/config"
+	apperrors "github.com/socialchef/remy/internal/errors"
 )
 
 func TestAuthMiddleware(t *testing.T) {
@@ -28,22 +30,26 @@ func TestAuthMiddleware(t *testing.T) {
 		name           string
 		authHeader     string
 		expectedStatus int
+		expectedCode   string
 		expectedUserID string
 	}{
 		{
 			name:           "Missing Authorization header",
 			authHeader:     "",
 			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "MISSING_AUTHORIZATION",
 		},
 		{
 			name:           "Invalid Authorization header format",
 			authHeader:     "Bearer",
 			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_AUTHORIZATION_HEADER",
 		},
 		{
 			name:           "Invalid token format",
 			authHeader:     "Bearer invalid-token",
 			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
 		},
 		{
 			name: "Expired token",
@@ -53,6 +59,7 @@ func TestAuthMiddleware(t *testing.T) {
 				"exp": time.Now().Add(-time.Hour).Unix(),
 			}),
 			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
 		},
 		{
 			name: "Invalid signature",
@@ -66,6 +73,7 @@ func TestAuthMiddleware(t *testing.T) {
 				return tokenString
 			}(),
 			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN",
 		},
 		{
 			name: "Invalid issuer",
@@ -75,6 +83,7 @@ func TestAuthMiddleware(t *testing.T) {
 				"exp": time.Now().Add(time.Hour).Unix(),
 			}),
 			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_TOKEN_ISSUER",
 		},
 		{
 			name: "Valid token",
@@ -90,7 +99,7 @@ func TestAuthMiddleware(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				userID, ok := GetUserID(r.Context())
 				if !ok {
 					t.Error("expected userID in context")
@@ -112,6 +121,15 @@ func TestAuthMiddleware(t *testing.T) {
 			if rr.Code != tt.expectedStatus {
 				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
 			}
+			if tt.expectedCode != "" {
+				var body apperrors.Response
+				if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+					t.Fatalf("expected JSON error body: %v", err)
+				}
+				if body.Error.Code != tt.expectedCode {
+					t.Errorf("expected code %s, got %s", tt.expectedCode, body.Error.Code)
+				}
+			}
 		})
 	}
 }
@@ -172,7 +190,7 @@ func TestAuthMiddlewareXAPIKeyServiceToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				userID, ok := GetUserID(r.Context())
 				if !ok {
 					t.Error("expected userID in context")
@@ -249,7 +267,7 @@ func TestAuthMiddlewareServiceToken(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			handler := AuthMiddleware(cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			handler := AuthMiddleware(cfg, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 				userID, ok := GetUserID(r.Context())
 				if !ok {
 					t.Error("expected userID in context")
@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/socialchef/remy/internal/metrics"
+)
+
+// Headers marking a deprecated route (RFC 9745 and RFC 8594)
+const (
+	DeprecationHeader = "Deprecation"
+	SunsetHeader      = "Sunset"
+)
+
+// Deprecated marks responses from a route that successor replaces: the
+// Deprecation header carries when it was deprecated, Sunset when it will be
+// removed and Link the successor. The successor's parameters are filled from
+// the request's path parameters or, for legacy routes that took them in the
+// query string, from the query parameter of the same name in snake case
+// ({jobID} from job_id); when one is missing the Link is left out. Requests
+// are counted by route so removal can wait for clients to move.
+func Deprecated(successor string, deprecatedAt, sunset time.Time) func(http.Handler) http.Handler {
+	deprecation := fmt.Sprintf("@%d", deprecatedAt.Unix())
+	sunsetDate := sunset.UTC().Format(http.TimeFormat)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			route := r.URL.Path
+			if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+				route = rctx.RoutePattern()
+			}
+			metrics.RecordLegacyRoute(r.Context(), r.Method, route)
+
+			w.Header().Set(DeprecationHeader, deprecation)
+			w.Header().Set(SunsetHeader, sunsetDate)
+			if link, ok := successorLink(r, successor); ok {
+				w.Header().Add("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, link))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// successorLink fills the {name} parameters of successor for request r. It
+// reports false when a parameter has no value in the request.
+func successorLink(r *http.Request, successor string) (string, bool) {
+	var link strings.Builder
+	rest := successor
+	for {
+		start := strings.IndexByte(rest, '{')
+		if start < 0 {
+			link.WriteString(rest)
+			return link.String(), true
+		}
+		end := strings.IndexByte(rest[start:], '}')
+		if end < 0 {
+			link.WriteString(rest)
+			return link.String(), true
+		}
+		name := rest[start+1 : start+end]
+
+		value := chi.URLParam(r, name)
+		if value == "" {
+			value = r.URL.Query().Get(snakeCase(name))
+		}
+		if value == "" {
+			return "", false
+		}
+		link.WriteString(rest[:start])
+		link.WriteString(url.PathEscape(value))
+		rest = rest[start+end+1:]
+	}
+}
+
+// snakeCase converts a parameter name such as bulkJobID to bulk_job_id.
+func snakeCase(name string) string {
+	var b strings.Builder
+	for i, c := range name {
+		if unicode.IsUpper(c) {
+			if i > 0 && !unicode.IsUpper(rune(name[i-1])) {
+				b.WriteByte('_')
+			}
+			c = unicode.ToLower(c)
+		}
+		b.WriteRune(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDeprecated(t *testing.T) {
+	deprecatedAt := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2027, 4, 18, 0, 0, 0, 0, time.UTC)
+
+	r := chi.NewRouter()
+	r.With(Deprecated("/api/v1/bulk-imports/{bulkJobID}", deprecatedAt, sunset)).
+		Get("/api/bulk-import/{bulkJobID}", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/api/bulk-import/job-1", nil))
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if got := rr.Header().Get(DeprecationHeader); got != "@1792281600" {
+		t.Errorf("expected the deprecation date, got %q", got)
+	}
+	if got := rr.Header().Get(SunsetHeader); got != "Sun, 18 Apr 2027 00:00:00 GMT" {
+		t.Errorf("expected the sunset date, got %q", got)
+	}
+	if got := rr.Header().Get("Link"); got != `</api/v1/bulk-imports/job-1>; rel="successor-version"` {
+		t.Errorf("expected a link to the successor, got %q", got)
+	}
+}
+
+func TestDeprecated_QueryParameterLegacyRoute(t *testing.T) {
+	r := chi.NewRouter()
+	r.With(Deprecated("/api/v1/imports/{jobID}", time.Now(), time.Now())).
+		Get("/api/recipe-status", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		})
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/api/recipe-status?job_id=job-1", nil))
+	if got := rr.Header().Get("Link"); got != `</api/v1/imports/job-1>; rel="successor-version"` {
+		t.Errorf("expected the successor filled from the query string, got %q", got)
+	}
+
+	// Without the parameter there is no successor to link to
+	rr = httptest.NewRecorder()
+	r.ServeHTTP(rr, httptest.NewRequest("GET", "/api/recipe-status", nil))
+	if got := rr.Header().Get("Link"); got != "" {
+		t.Errorf("expected no Link, got %q", got)
+	}
+	if rr.Header().Get(DeprecationHeader) == "" {
+		t.Error("expected the Deprecation header without a Link")
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for name, want := range map[string]string{
+		"jobID":     "job_id",
+		"recipeID":  "recipe_id",
+		"bulkJobID": "bulk_job_id",
+		"revision":  "revision",
+	} {
+		if got := snakeCase(name); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"context"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/ratelimit"
+)
+
+// Rate limit response headers
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"
+	RateLimitRemainingHeader = "X-RateLimit-Remaining"
+	RateLimitResetHeader     = "X-RateLimit-Reset"
+)
+
+var errRateLimited = apperrors.NewRateLimitError("Too many requests", "RATE_LIMITED", "Wait for the time in the Retry-After header and retry.")
+
+// RateLimiter takes tokens from named buckets; *ratelimit.Limiter satisfies
+// it.
+type RateLimiter interface {
+	Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+}
+
+// RateLimit limits authenticated requests with a token bucket per user.
+// Service token callers share one bucket per service with serviceLimit, as
+// they act for many users. name keeps the buckets of different limits apart.
+// A nil limiter disables limiting.
+func RateLimit(limiter RateLimiter, name string, userLimit, serviceLimit ratelimit.Limit) func(http.Handler) http.Handler {
+	return rateLimit(limiter, func(r *http.Request) (string, ratelimit.Limit) {
+		if service, ok := GetService(r.Context()); ok {
+			return name + ":service:" + service, serviceLimit
+		}
+		userID, _ := GetUserID(r.Context())
+		return name + ":user:" + userID, userLimit
+	})
+}
+
+// UserRateLimit limits authenticated requests with a token bucket per user,
+// including users that service token callers act for.
+func UserRateLimit(limiter RateLimiter, name string, limit ratelimit.Limit) func(http.Handler) http.Handler {
+	return rateLimit(limiter, func(r *http.Request) (string, ratelimit.Limit) {
+		userID, _ := GetUserID(r.Context())
+		return name + ":user:" + userID, limit
+	})
+}
+
+func rateLimit(limiter RateLimiter, bucket func(r *http.Request) (string, ratelimit.Limit)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		if limiter == nil {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, limit := bucket(r)
+			result, err := limiter.Allow(r.Context(), key, limit)
+			if err != nil {
+				// Fail open: an unavailable Redis should not take the API down
+				slog.Warn("Rate limit check failed", "error", err, "key", key)
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set(RateLimitLimitHeader, strconv.Itoa(result.Limit))
+			w.Header().Set(RateLimitRemainingHeader, strconv.Itoa(result.Remaining))
+			w.Header().Set(RateLimitResetHeader, strconv.Itoa(seconds(result.ResetAfter)))
+			if !result.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(max(1, seconds(result.RetryAfter))))
+				WriteError(w, r, errRateLimited)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds rounds d up to whole seconds, as used by Retry-After.
+func seconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/socialchef/remy/internal/services/ratelimit"
+)
+
+type fakeLimiter struct {
+	result ratelimit.Result
+	err    error
+	keys   []string
+	limits []ratelimit.Limit
+}
+
+func (f *fakeLimiter) Allow(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	f.keys = append(f.keys, key)
+	f.limits = append(f.limits, limit)
+	return f.result, f.err
+}
+
+func TestRateLimit(t *testing.T) {
+	userLimit := ratelimit.PerMinute(60, 10)
+	serviceLimit := ratelimit.PerMinute(600, 100)
+
+	tests := []struct {
+		name           string
+		service        bool
+		result         ratelimit.Result
+		err            error
+		expectedStatus int
+		expectedKey    string
+		expectedLimit  ratelimit.Limit
+		retryAfter     string
+	}{
+		{
+			name:           "Allowed",
+			result:         ratelimit.Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second},
+			expectedStatus: http.StatusOK,
+			expectedKey:    "api:user:user-123",
+			expectedLimit:  userLimit,
+		},
+		{
+			name:           "Limited",
+			result:         ratelimit.Result{Limit: 10, RetryAfter: 1500 * time.Millisecond, ResetAfter: 10 * time.Second},
+			expectedStatus: http.StatusTooManyRequests,
+			expectedKey:    "api:user:user-123",
+			expectedLimit:  userLimit,
+			retryAfter:     "2",
+		},
+		{
+			name:           "Service caller",
+			service:        true,
+			result:         ratelimit.Result{Allowed: true, Limit: 100, Remaining: 99},
+			expectedStatus: http.StatusOK,
+			expectedKey:    "api:service:internal",
+			expectedLimit:  serviceLimit,
+		},
+		{
+			name:           "Limiter error fails open",
+			err:            errors.New("connection refused"),
+			expectedStatus: http.StatusOK,
+			expectedKey:    "api:user:user-123",
+			expectedLimit:  userLimit,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limiter := &fakeLimiter{result: tt.result, err: tt.err}
+			handler := RateLimit(limiter, "api", userLimit, serviceLimit)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			ctx := context.WithValue(context.Background(), UserIDKey, "user-123")
+			if tt.service {
+				ctx = context.WithValue(ctx, ServiceKey, internalService)
+			}
+			req := httptest.NewRequest("POST", "/api/recipe", nil).WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+			if len(limiter.keys) != 1 || limiter.keys[0] != tt.expectedKey {
+				t.Errorf("expected key %s, got %v", tt.expectedKey, limiter.keys)
+			}
+			if limiter.limits[0] != tt.expectedLimit {
+				t.Errorf("expected limit %+v, got %+v", tt.expectedLimit, limiter.limits[0])
+			}
+			if got := rr.Header().Get("Retry-After"); got != tt.retryAfter {
+				t.Errorf("expected Retry-After %q, got %q", tt.retryAfter, got)
+			}
+		})
+	}
+}
+
+func TestUserRateLimitKeysServiceCallersByUser(t *testing.T) {
+	limiter := &fakeLimiter{result: ratelimit.Result{Allowed: true}}
+	handler := UserRateLimit(limiter, "import", ratelimit.PerMinute(10, 5))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	ctx := context.WithValue(context.Background(), UserIDKey, "user-123")
+	ctx = context.WithValue(ctx, ServiceKey, internalService)
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/api/recipe", nil).WithContext(ctx))
+
+	if len(limiter.keys) != 1 || limiter.keys[0] != "import:user:user-123" {
+		t.Errorf("expected key import:user:user-123, got %v", limiter.keys)
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	called := false
+	handler := RateLimit(nil, "api", ratelimit.PerMinute(1, 1), ratelimit.PerMinute(1, 1))(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/api/recipes", nil))
+
+	if !called {
+		t.Error("expected request to pass through without a limiter")
+	}
+}
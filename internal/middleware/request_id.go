@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+
+	"github.com/google/uuid"
+	apperrors "github.com/socialchef/remy/internal/errors"
+)
+
+const RequestIDKey contextKey = "requestID"
+
+// RequestIDHeader carries the request ID in both directions.
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDPattern limits client supplied IDs to short, log safe values.
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID gives every request an ID: the caller's X-Request-ID when it is
+// a plausible ID, or a new UUID. The ID is echoed in the response header and
+// included in error responses so reports can be matched to logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !requestIDPattern.MatchString(id) {
+			id = uuid.New().String()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), RequestIDKey, id)))
+	})
+}
+
+// GetRequestID returns the request's ID, or "" outside RequestID.
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(RequestIDKey).(string)
+	return id
+}
+
+// WriteError responds with err as the JSON error envelope, tagged with the
+// request ID.
+func WriteError(w http.ResponseWriter, r *http.Request, err *apperrors.AppError) {
+	apperrors.WriteJSON(w, GetRequestID(r.Context()), err)
+}
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestRequestID(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "No ID", incoming: "", keep: false},
+		{name: "Valid ID", incoming: "mobile-7f3a:42", keep: true},
+		{name: "ID with spaces", incoming: "not an id", keep: false},
+		{name: "ID too long", incoming: strings.Repeat("a", 129), keep: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen string
+			handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				seen = GetRequestID(r.Context())
+			}))
+
+			req := httptest.NewRequest("GET", "/api/recipes", nil)
+			if tt.incoming != "" {
+				req.Header.Set(RequestIDHeader, tt.incoming)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if got := rr.Header().Get(RequestIDHeader); got != seen {
+				t.Errorf("response header %q does not match context ID %q", got, seen)
+			}
+			if tt.keep && seen != tt.incoming {
+				t.Errorf("expected incoming ID %q, got %q", tt.incoming, seen)
+			}
+			if !tt.keep {
+				if _, err := uuid.Parse(seen); err != nil {
+					t.Errorf("expected a generated UUID, got %q", seen)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/socialchef/remy/internal/config"
+)
+
+func TestSecurityHeaders(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.SecurityConfig
+		wantHSTS string
+	}{
+		{name: "production", cfg: config.SecurityConfig{HSTSMaxAge: 365 * 24 * time.Hour, ContentSecurityPolicy: "default-src 'none'"}, wantHSTS: "max-age=31536000; includeSubDomains"},
+		{name: "development", cfg: config.SecurityConfig{ContentSecurityPolicy: "default-src 'none'"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := SecurityHeaders(tt.cfg)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, httptest.NewRequest("GET", "/r/token", nil))
+
+			if got := rr.Header().Get("X-Content-Type-Options"); got != "nosniff" {
+				t.Errorf("expected nosniff, got %q", got)
+			}
+			if got := rr.Header().Get("Strict-Transport-Security"); got != tt.wantHSTS {
+				t.Errorf("expected HSTS %q, got %q", tt.wantHSTS, got)
+			}
+			if got := rr.Header().Get("Content-Security-Policy"); got != "default-src 'none'" {
+				t.Errorf("expected the configured CSP, got %q", got)
+			}
+		})
+	}
+}
+
+func TestLimitBody(t *testing.T) {
+	handler := LimitBody(16, "/api/v1/imports/image")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := io.ReadAll(r.Body); err != nil {
+			if isBodyTooLarge(err) {
+				WriteError(w, r, errBodyTooLarge)
+				return
+			}
+			t.Fatalf("unexpected read error: %v", err)
+		}
+	}))
+
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		chunked     bool
+		wantStatus  int
+	}{
+		{name: "within limit", path: "/api/v1/imports", contentType: "application/json", body: `{"url":"x"}`, wantStatus: http.StatusOK},
+		{name: "content length over limit", path: "/api/v1/imports", contentType: "application/json", body: `{"url":"https://example.com"}`, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "chunked over limit", path: "/api/v1/imports", contentType: "application/json", body: `{"url":"https://example.com"}`, chunked: true, wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "multipart header on a JSON route", path: "/api/v1/imports", contentType: "multipart/form-data; boundary=x", body: strings.Repeat("a", 64), wantStatus: http.StatusRequestEntityTooLarge},
+		{name: "upload route is left to the handler", path: "/api/v1/imports/image", contentType: "multipart/form-data; boundary=x", body: strings.Repeat("a", 64), wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.chunked {
+				req.ContentLength = -1
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
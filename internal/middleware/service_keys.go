@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/apikey"
+)
+
+// serviceScopesKey holds the scopes of a service API key request.
+const serviceScopesKey contextKey = "serviceScopes"
+
+var (
+	errAPIKeyExpired    = apperrors.NewUnauthorizedError("API key has expired", "API_KEY_EXPIRED", "Mint a new key with `go run ./cmd/apikey create`.")
+	errUserNotAllowed   = apperrors.NewForbiddenError("API key may not act for this user", "USER_NOT_ALLOWED", "")
+	errAuthUnavailable  = apperrors.NewUnavailableError("Authentication is temporarily unavailable", "AUTH_UNAVAILABLE", "Retry the request shortly.")
+	errUserTokenNeeded  = apperrors.NewForbiddenError("Only the user can make this request", "USER_TOKEN_REQUIRED", "Sign in as the user; services cannot act for them here.")
+	legacyTokenWarnOnce sync.Once
+)
+
+// ServiceKeyStore looks up service API keys; *generated.Queries satisfies it.
+type ServiceKeyStore interface {
+	GetServiceAPIKeyByHash(ctx context.Context, keyHash string) (generated.ServiceApiKey, error)
+	TouchServiceAPIKey(ctx context.Context, id pgtype.UUID) error
+}
+
+// serviceCaller is an authenticated sibling service.
+type serviceCaller struct {
+	name         string
+	keyID        string
+	scopes       []string
+	allowedUsers []pgtype.UUID
+}
+
+// authenticateServiceKey resolves a service API key or the legacy shared
+// token. ok is false when secret is neither, so it can be tried as a JWT.
+func authenticateServiceKey(ctx context.Context, cfg *config.Config, keys ServiceKeyStore, secret string) (caller serviceCaller, ok bool, appErr *apperrors.AppError) {
+	if keys != nil && apikey.IsKey(secret) {
+		key, err := keys.GetServiceAPIKeyByHash(ctx, apikey.Hash(secret))
+		if errors.Is(err, pgx.ErrNoRows) {
+			return serviceCaller{}, true, errInvalidAPIKey
+		}
+		if err != nil {
+			slog.Error("Failed to look up service API key", "error", err)
+			return serviceCaller{}, true, errAuthUnavailable
+		}
+		if key.ExpiresAt.Valid && key.ExpiresAt.Time.Before(time.Now()) {
+			return serviceCaller{}, true, errAPIKeyExpired
+		}
+		if err := keys.TouchServiceAPIKey(ctx, key.ID); err != nil {
+			slog.Warn("Failed to record service API key use", "error", err, "key_id", uuid.UUID(key.ID.Bytes).String())
+		}
+		return serviceCaller{
+			name:         key.Name,
+			keyID:        uuid.UUID(key.ID.Bytes).String(),
+			scopes:       key.Scopes,
+			allowedUsers: key.AllowedUserIds,
+		}, true, nil
+	}
+
+	// The shared INTERNAL_SERVICE_TOKEN predates scoped keys and keeps full
+	// rights until sibling services have moved to their own keys
+	if cfg.InternalServiceToken != "" && subtle.ConstantTimeCompare([]byte(secret), []byte(cfg.InternalServiceToken)) == 1 {
+		legacyTokenWarnOnce.Do(func() {
+			slog.Warn("INTERNAL_SERVICE_TOKEN is deprecated; mint scoped keys with cmd/apikey")
+		})
+		return serviceCaller{name: internalService, scopes: apikey.Scopes}, true, nil
+	}
+
+	return serviceCaller{}, false, nil
+}
+
+// serveAsService runs next as the user named in X-On-Behalf-Of, after
+// checking the caller may act for them, and audit logs the impersonation.
+func serveAsService(w http.ResponseWriter, r *http.Request, next http.Handler, caller serviceCaller) {
+	onBehalfOf := strings.TrimSpace(r.Header.Get("X-On-Behalf-Of"))
+	if onBehalfOf == "" {
+		WriteError(w, r, errMissingOnBehalfOf)
+		return
+	}
+	if !uuidRegex.MatchString(onBehalfOf) {
+		WriteError(w, r, errInvalidOnBehalfOf)
+		return
+	}
+	if !caller.mayActFor(onBehalfOf) {
+		slog.Warn("Service impersonation denied",
+			"service", caller.name, "key_id", caller.keyID, "user_id", onBehalfOf,
+			"method", r.Method, "path", r.URL.Path, "request_id", GetRequestID(r.Context()))
+		WriteError(w, r, errUserNotAllowed)
+		return
+	}
+
+	slog.Info("Service impersonation",
+		"service", caller.name, "key_id", caller.keyID, "user_id", onBehalfOf,
+		"method", r.Method, "path", r.URL.Path, "request_id", GetRequestID(r.Context()))
+
+	ctx := context.WithValue(r.Context(), UserIDKey, onBehalfOf)
+	ctx = context.WithValue(ctx, ServiceKey, caller.name)
+	ctx = context.WithValue(ctx, serviceScopesKey, caller.scopes)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// mayActFor reports whether the caller may impersonate userID. Keys without
+// allowed users may act for anyone.
+func (c serviceCaller) mayActFor(userID string) bool {
+	if len(c.allowedUsers) == 0 {
+		return true
+	}
+	id, err := uuid.Parse(userID)
+	if err != nil {
+		return false
+	}
+	for _, allowed := range c.allowedUsers {
+		if allowed.Valid && uuid.UUID(allowed.Bytes) == id {
+			return true
+		}
+	}
+	return false
+}
+
+// RequireScope rejects service API key requests whose key lacks scope. User
+// requests are not restricted.
+func RequireScope(scope string) func(http.Handler) http.Handler {
+	errMissingScope := apperrors.NewForbiddenError("API key lacks the "+scope+" scope", "INSUFFICIENT_SCOPE", "Mint a key with the "+scope+" scope.")
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			scopes, ok := r.Context().Value(serviceScopesKey).([]string)
+			if ok && !apikey.HasScope(scopes, scope) {
+				WriteError(w, r, errMissingScope)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireUser rejects requests made by services, with or without
+// X-On-Behalf-Of, for routes only the user themself may call.
+func RequireUser(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := GetService(r.Context()); ok {
+			WriteError(w, r, errUserTokenNeeded)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,242 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/apikey"
+)
+
+type fakeKeyStore struct {
+	keys    map[string]generated.ServiceApiKey
+	touched int
+}
+
+func (f *fakeKeyStore) GetServiceAPIKeyByHash(ctx context.Context, keyHash string) (generated.ServiceApiKey, error) {
+	key, ok := f.keys[keyHash]
+	if !ok {
+		return generated.ServiceApiKey{}, pgx.ErrNoRows
+	}
+	return key, nil
+}
+
+func (f *fakeKeyStore) TouchServiceAPIKey(ctx context.Context, id pgtype.UUID) error {
+	f.touched++
+	return nil
+}
+
+func TestAuthMiddlewareServiceAPIKey(t *testing.T) {
+	cfg := &config.Config{
+		SupabaseURL:       "https://test.supabase.co",
+		SupabaseJWTSecret: "test-secret",
+	}
+
+	allowedUser := uuid.New()
+	otherUser := uuid.New()
+
+	newKey := func(name string, scopes []string, users []uuid.UUID, expiresAt time.Time) (string, generated.ServiceApiKey) {
+		k, err := apikey.Generate()
+		if err != nil {
+			t.Fatalf("Generate: %v", err)
+		}
+		row := generated.ServiceApiKey{
+			ID:     pgtype.UUID{Bytes: uuid.New(), Valid: true},
+			Name:   name,
+			Scopes: scopes,
+		}
+		for _, u := range users {
+			row.AllowedUserIds = append(row.AllowedUserIds, pgtype.UUID{Bytes: u, Valid: true})
+		}
+		if !expiresAt.IsZero() {
+			row.ExpiresAt = pgtype.Timestamptz{Time: expiresAt, Valid: true}
+		}
+		return k.Secret, row
+	}
+
+	store := &fakeKeyStore{keys: map[string]generated.ServiceApiKey{}}
+	add := func(secret string, row generated.ServiceApiKey) string {
+		store.keys[apikey.Hash(secret)] = row
+		return secret
+	}
+	sous := add(newKey("sous", []string{apikey.ScopeImportWrite}, nil, time.Time{}))
+	restricted := add(newKey("pantry", []string{apikey.ScopeImportWrite}, []uuid.UUID{allowedUser}, time.Time{}))
+	expired := add(newKey("old", []string{apikey.ScopeImportWrite}, nil, time.Now().Add(-time.Hour)))
+	unknown, _ := newKey("unknown", nil, nil, time.Time{})
+
+	tests := []struct {
+		name            string
+		header          string
+		secret          string
+		onBehalfOf      string
+		expectedStatus  int
+		expectedCode    string
+		expectedService string
+	}{
+		{
+			name:            "Bearer key impersonates user",
+			header:          "Authorization",
+			secret:          sous,
+			onBehalfOf:      otherUser.String(),
+			expectedStatus:  http.StatusOK,
+			expectedService: "sous",
+		},
+		{
+			name:            "X-API-Key key impersonates user",
+			header:          "X-API-Key",
+			secret:          sous,
+			onBehalfOf:      otherUser.String(),
+			expectedStatus:  http.StatusOK,
+			expectedService: "sous",
+		},
+		{
+			name:           "Unknown key is rejected",
+			header:         "Authorization",
+			secret:         unknown,
+			onBehalfOf:     otherUser.String(),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "INVALID_API_KEY",
+		},
+		{
+			name:           "Expired key is rejected",
+			header:         "X-API-Key",
+			secret:         expired,
+			onBehalfOf:     otherUser.String(),
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "API_KEY_EXPIRED",
+		},
+		{
+			name:            "Restricted key acts for allowed user",
+			header:          "Authorization",
+			secret:          restricted,
+			onBehalfOf:      allowedUser.String(),
+			expectedStatus:  http.StatusOK,
+			expectedService: "pantry",
+		},
+		{
+			name:           "Restricted key cannot act for other users",
+			header:         "Authorization",
+			secret:         restricted,
+			onBehalfOf:     otherUser.String(),
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   "USER_NOT_ALLOWED",
+		},
+		{
+			name:           "Key without X-On-Behalf-Of is rejected",
+			header:         "Authorization",
+			secret:         sous,
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   "MISSING_ON_BEHALF_OF",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := AuthMiddleware(cfg, store)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				userID, _ := GetUserID(r.Context())
+				if userID != tt.onBehalfOf {
+					t.Errorf("expected userID %q, got %q", tt.onBehalfOf, userID)
+				}
+				if service, _ := GetService(r.Context()); service != tt.expectedService {
+					t.Errorf("expected service %q, got %q", tt.expectedService, service)
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.header == "Authorization" {
+				req.Header.Set("Authorization", "Bearer "+tt.secret)
+			} else {
+				req.Header.Set(tt.header, tt.secret)
+			}
+			if tt.onBehalfOf != "" {
+				req.Header.Set("X-On-Behalf-Of", tt.onBehalfOf)
+			}
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d; body=%s", tt.expectedStatus, rr.Code, rr.Body.String())
+			}
+			if tt.expectedCode != "" {
+				var body apperrors.Response
+				if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+					t.Fatalf("expected JSON error body: %v", err)
+				}
+				if body.Error.Code != tt.expectedCode {
+					t.Errorf("expected code %s, got %s", tt.expectedCode, body.Error.Code)
+				}
+			}
+		})
+	}
+
+	if store.touched == 0 {
+		t.Error("expected accepted keys to record their use")
+	}
+}
+
+func TestRequireScope(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RequireScope(apikey.ScopeRecipeRead)(ok)
+
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		expectedStatus int
+	}{
+		{"User request", context.WithValue(context.Background(), UserIDKey, "user-123"), http.StatusOK},
+		{"Key with scope", context.WithValue(context.Background(), serviceScopesKey, []string{apikey.ScopeRecipeRead}), http.StatusOK},
+		{"Write scope implies read", context.WithValue(context.Background(), serviceScopesKey, []string{apikey.ScopeRecipeWrite}), http.StatusOK},
+		{"Key without scope", context.WithValue(context.Background(), serviceScopesKey, []string{apikey.ScopeImportWrite}), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil).WithContext(tt.ctx)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestRequireUser(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	handler := RequireUser(ok)
+
+	userCtx := context.WithValue(context.Background(), UserIDKey, "user-123")
+	tests := []struct {
+		name           string
+		ctx            context.Context
+		expectedStatus int
+	}{
+		{"User request", userCtx, http.StatusOK},
+		{"Service acting for the user", context.WithValue(userCtx, ServiceKey, "meal-planner"), http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("DELETE", "/", nil).WithContext(tt.ctx)
+			rr := httptest.NewRecorder()
+			handler.ServeHTTP(rr, req)
+			if rr.Code != tt.expectedStatus {
+				t.Errorf("expected status %d, got %d", tt.expectedStatus, rr.Code)
+			}
+		})
+	}
+}
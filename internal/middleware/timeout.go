@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// NoWriteTimeout lifts the server's write timeout for long-lived responses
+// such as event streams, which would otherwise be cut off mid-stream. It
+// must come before any middleware that wraps the response writer.
+func NoWriteTimeout(paths ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if slices.Contains(paths, r.URL.Path) {
+				if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+					slog.Warn("Failed to lift write timeout", "error", err, "path", r.URL.Path)
+				}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
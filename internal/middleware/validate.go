@@ -0,0 +1,54 @@
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/openapi"
+)
+
+var errUnreadableBody = apperrors.NewValidationError("Failed to read request body", "INVALID_REQUEST_BODY", "")
+
+// ValidateRequest checks JSON request bodies against the operation spec
+// describes for the matched route, rejecting unknown fields and values
+// outside the documented limits. It must run after routing, inside a route
+// group, so the route pattern is known. Routes the spec does not describe
+// and operations without a JSON body pass through unchecked. Bodies over
+// maxBytes are rejected with a 413 without being read further.
+func ValidateRequest(spec *openapi.Spec, maxBytes int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rctx := chi.RouteContext(r.Context())
+			if rctx == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			op := spec.Operation(r.Method, rctx.RoutePattern())
+			if op == nil || op.JSONBody() == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBytes))
+			r.Body.Close()
+			if isBodyTooLarge(err) {
+				WriteError(w, r, errBodyTooLarge)
+				return
+			}
+			if err != nil {
+				WriteError(w, r, errUnreadableBody)
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			if err := op.ValidateBody(body); err != nil {
+				WriteError(w, r, apperrors.NewValidationError(err.Error(), "REQUEST_VALIDATION_FAILED", "Check the request body against /openapi.json."))
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package middleware
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	apperrors "github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/openapi"
+)
+
+func TestValidateRequest(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	var received string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received = string(body)
+		w.WriteHeader(http.StatusAccepted)
+	}
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(ValidateRequest(spec, 1<<20))
+		r.Post("/api/v1/imports", handler)
+		r.Post("/api/v1/recipes/{recipeID}/shares", handler)
+		r.Post("/api/recipe", handler)
+		r.Post("/api/unspecified", handler)
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		body     string
+		wantCode int
+	}{
+		{name: "Valid body", path: "/api/v1/imports", body: `{"url": "https://www.instagram.com/p/abc/"}`, wantCode: http.StatusAccepted},
+		{name: "Unknown field", path: "/api/v1/imports", body: `{"url": "https://x.test", "priority": 1}`, wantCode: http.StatusBadRequest},
+		{name: "Path parameter route", path: "/api/v1/recipes/123/shares", body: `{"expires_in_days": 90}`, wantCode: http.StatusBadRequest},
+		{name: "Legacy route", path: "/api/recipe", body: `{"url": "https://x.test", "priority": 1}`, wantCode: http.StatusBadRequest},
+		{name: "Route not in spec", path: "/api/unspecified", body: `{"anything": true}`, wantCode: http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received = ""
+			req := httptest.NewRequest("POST", tt.path, strings.NewReader(tt.body))
+			rr := httptest.NewRecorder()
+			r.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantCode {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantCode, rr.Code, rr.Body.String())
+			}
+			if tt.wantCode != http.StatusBadRequest {
+				if received != tt.body {
+					t.Errorf("handler read %q, expected the original body", received)
+				}
+				return
+			}
+			var resp apperrors.Response
+			if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+				t.Fatalf("decode error response: %v", err)
+			}
+			if resp.Error.Code != "REQUEST_VALIDATION_FAILED" {
+				t.Errorf("expected REQUEST_VALIDATION_FAILED, got %s", resp.Error.Code)
+			}
+			if received != "" {
+				t.Error("handler should not run for an invalid body")
+			}
+		})
+	}
+}
+
+func TestValidateRequest_BodyTooLarge(t *testing.T) {
+	spec, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	// Without LimitBody in front, ValidateRequest bounds the read itself
+	r := chi.NewRouter()
+	r.Group(func(r chi.Router) {
+		r.Use(ValidateRequest(spec, 16))
+		r.Post("/api/v1/imports", func(w http.ResponseWriter, r *http.Request) {
+			t.Error("handler should not run for an oversized body")
+		})
+	})
+
+	req := httptest.NewRequest("POST", "/api/v1/imports", strings.NewReader(`{"url": "https://www.instagram.com/p/abc/"}`))
+	req.ContentLength = -1
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected status %d, got %d", http.StatusRequestEntityTooLarge, rr.Code)
+	}
+	var resp apperrors.Response
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	if resp.Error.Code != "REQUEST_TOO_LARGE" {
+		t.Errorf("expected REQUEST_TOO_LARGE, got %s", resp.Error.Code)
+	}
+}
@@ -0,0 +1,148 @@
+// Package openapi holds the OpenAPI description of the HTTP API and checks
+// request bodies against it.
+package openapi
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+//go:embed openapi.json
+var document []byte
+
+// Spec is a parsed OpenAPI document.
+type Spec struct {
+	raw        []byte
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components struct {
+		Schemas map[string]*Schema `json:"schemas"`
+	} `json:"components"`
+
+	// legacy indexes operations by their x-legacy-path
+	legacy map[string]map[string]*Operation
+}
+
+// Operation is one method of a path.
+type Operation struct {
+	ID          string               `json:"operationId"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+	// LegacyPath is the path the operation was served at before /api/v1,
+	// still served as a deprecated alias
+	LegacyPath string `json:"x-legacy-path,omitempty"`
+
+	spec *Spec
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Content map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+// Load parses the embedded API description.
+func Load() (*Spec, error) {
+	var spec Spec
+	if err := json.Unmarshal(document, &spec); err != nil {
+		return nil, fmt.Errorf("parse openapi.json: %w", err)
+	}
+	spec.raw = document
+	spec.legacy = make(map[string]map[string]*Operation)
+	for _, methods := range spec.Paths {
+		for method, op := range methods {
+			op.spec = &spec
+			if op.LegacyPath == "" {
+				continue
+			}
+			if spec.legacy[op.LegacyPath] == nil {
+				spec.legacy[op.LegacyPath] = make(map[string]*Operation)
+			}
+			spec.legacy[op.LegacyPath][method] = op
+		}
+	}
+	return &spec, nil
+}
+
+// ServeHTTP serves the document as /openapi.json.
+func (s *Spec) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(s.raw)
+}
+
+// Operation returns the operation for a method and a route pattern such as
+// /api/v1/recipes/{recipeID}, or nil when the spec does not describe it.
+// Deprecated legacy paths resolve to the operation that replaced them.
+func (s *Spec) Operation(method, pattern string) *Operation {
+	method = strings.ToLower(method)
+	if op := s.Paths[pattern][method]; op != nil {
+		return op
+	}
+	return s.legacy[pattern][method]
+}
+
+// Operations returns every operation by its ID.
+func (s *Spec) Operations() map[string]*Operation {
+	ops := make(map[string]*Operation)
+	for _, methods := range s.Paths {
+		for _, op := range methods {
+			ops[op.ID] = op
+		}
+	}
+	return ops
+}
+
+// Schema returns a component schema by name, or nil.
+func (s *Spec) Schema(name string) *Schema {
+	return s.Components.Schemas[name]
+}
+
+// Resolve follows schema's $ref, if any.
+func (s *Spec) Resolve(schema *Schema) *Schema {
+	for schema != nil && schema.Ref != "" {
+		schema = s.Schema(strings.TrimPrefix(schema.Ref, "#/components/schemas/"))
+	}
+	return schema
+}
+
+// JSONBody returns the schema of the operation's JSON request body, or nil
+// when it takes no JSON body.
+func (o *Operation) JSONBody() *Schema {
+	if o.RequestBody == nil {
+		return nil
+	}
+	return o.RequestBody.Content["application/json"].Schema
+}
+
+// ValidateBody checks a JSON request body against the operation. An empty
+// body is accepted when the body is optional.
+func (o *Operation) ValidateBody(body []byte) error {
+	schema := o.JSONBody()
+	if schema == nil {
+		return nil
+	}
+	if len(bytes.TrimSpace(body)) == 0 {
+		if o.RequestBody.Required {
+			return &ValidationError{Message: "request body is required"}
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return &ValidationError{Message: "request body is not valid JSON"}
+	}
+	return o.spec.validate(schema, "", value)
+}
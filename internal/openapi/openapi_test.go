@@ -0,0 +1,96 @@
+package openapi
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestValidateBody(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	urls := func(n int) string {
+		list := make([]string, n)
+		for i := range list {
+			list[i] = fmt.Sprintf("%q", fmt.Sprintf("https://www.instagram.com/p/%d/", i))
+		}
+		return `{"urls": [` + strings.Join(list, ",") + `]}`
+	}
+
+	tests := []struct {
+		name    string
+		method  string
+		pattern string
+		body    string
+		wantErr string
+	}{
+		{name: "Valid import", method: "POST", pattern: "/api/v1/imports", body: `{"url": "https://www.instagram.com/p/abc/"}`},
+		{name: "Unknown field", method: "POST", pattern: "/api/v1/imports", body: `{"url": "https://x.test", "urll": "typo"}`, wantErr: "urll: is not a known field"},
+		{name: "Missing field", method: "POST", pattern: "/api/v1/imports", body: `{}`, wantErr: "url: is required"},
+		{name: "Empty string", method: "POST", pattern: "/api/v1/imports", body: `{"url": ""}`, wantErr: "url: must not be empty"},
+		{name: "Wrong type", method: "POST", pattern: "/api/v1/imports", body: `{"url": 42}`, wantErr: "url: must be string"},
+		{name: "Missing required body", method: "POST", pattern: "/api/v1/imports", body: "", wantErr: "request body is required"},
+		{name: "Invalid JSON", method: "POST", pattern: "/api/v1/imports", body: `{"url":`, wantErr: "request body is not valid JSON"},
+		{name: "Bulk at limit", method: "POST", pattern: "/api/v1/bulk-imports", body: urls(50)},
+		{name: "Bulk over limit", method: "POST", pattern: "/api/v1/bulk-imports", body: urls(51), wantErr: "urls: must have at most 50 items"},
+		{name: "Bulk empty", method: "POST", pattern: "/api/v1/bulk-imports", body: `{"urls": []}`, wantErr: "urls: must not be empty"},
+		{name: "Bulk item type", method: "POST", pattern: "/api/v1/bulk-imports", body: `{"urls": ["https://x.test", 3]}`, wantErr: "urls[1]: must be string"},
+		{name: "Optional body left out", method: "POST", pattern: "/api/v1/recipes/{recipeID}/shares", body: ""},
+		{name: "Integer above maximum", method: "POST", pattern: "/api/v1/recipes/{recipeID}/shares", body: `{"expires_in_days": 31}`, wantErr: "expires_in_days: must be at most 30"},
+		{name: "Fractional integer", method: "POST", pattern: "/api/v1/recipes/{recipeID}/shares", body: `{"expires_in_days": 1.5}`, wantErr: "expires_in_days: must be integer"},
+		{name: "Enum", method: "POST", pattern: "/api/v1/recipes/{recipeID}/regenerate", body: `{"mode": "overwrite"}`, wantErr: `mode: must be one of "draft", "replace"`},
+		{name: "Enum items", method: "POST", pattern: "/api/v1/webhooks", body: `{"url": "https://x.test", "events": ["import.started"]}`, wantErr: "events[0]: must be one of"},
+		{name: "Nested schema", method: "PATCH", pattern: "/api/v1/recipes/{recipeID}", body: `{"parts": [{"name": "Dough", "ingredients": [{"name": "Flour", "amount": "1"}]}]}`, wantErr: "parts[0].ingredients[0].amount: is not a known field"},
+		{name: "Nested valid", method: "PATCH", pattern: "/api/v1/recipes/{recipeID}", body: `{"difficulty_rating": 2, "instructions": [{"instruction": "Mix", "timers": [{"duration_seconds": 300, "duration_text": "5 minutes", "label": "Rest", "type": "duration", "category": "passive"}]}]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			op := spec.Operation(tt.method, tt.pattern)
+			if op == nil {
+				t.Fatalf("no operation for %s %s", tt.method, tt.pattern)
+			}
+			err := op.ValidateBody([]byte(tt.body))
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+				t.Errorf("expected error %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestOperation(t *testing.T) {
+	spec, err := Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if op := spec.Operation("get", "/api/v1/recipes/{recipeID}"); op == nil || op.ID != "GetRecipe" {
+		t.Errorf("expected GetRecipe, got %+v", op)
+	}
+	if op := spec.Operation("GET", "/api/unknown"); op != nil {
+		t.Errorf("expected no operation, got %s", op.ID)
+	}
+	if op := spec.Operation("POST", "/api/v1/imports/image"); op == nil || op.JSONBody() != nil {
+		t.Error("expected the multipart upload to have no JSON body")
+	}
+
+	// Legacy paths resolve to the v1 operation that replaced them, by method
+	if op := spec.Operation("POST", "/api/recipe"); op == nil || op.ID != "ImportRecipe" {
+		t.Errorf("expected the legacy path to resolve to ImportRecipe, got %+v", op)
+	}
+	if op := spec.Operation("DELETE", "/api/bulk-import/{bulkJobID}"); op == nil || op.ID != "CancelBulkImport" {
+		t.Errorf("expected the legacy path to resolve to CancelBulkImport, got %+v", op)
+	}
+	if op := spec.Operation("GET", "/api/recipe"); op != nil {
+		t.Errorf("expected no legacy operation for another method, got %s", op.ID)
+	}
+}
@@ -0,0 +1,190 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is the subset of JSON Schema the API description uses.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 Types              `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties json.RawMessage    `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// Types is a schema's type: a single name, or a list such as
+// ["integer", "null"] for nullable values.
+type Types []string
+
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*t = Types{name}
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal(data, &names); err != nil {
+		return err
+	}
+	*t = names
+	return nil
+}
+
+// Closed reports whether the schema rejects properties it does not list.
+func (s *Schema) Closed() bool {
+	return string(s.AdditionalProperties) == "false"
+}
+
+// ValidationError says which field of a request body is invalid and why.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// validate checks a value decoded with UseNumber against schema. path names
+// the value in errors, e.g. "urls[3]".
+func (s *Spec) validate(schema *Schema, path string, value any) error {
+	schema = s.Resolve(schema)
+	if schema == nil {
+		return nil
+	}
+	fail := func(format string, args ...any) error {
+		return &ValidationError{Field: path, Message: fmt.Sprintf(format, args...)}
+	}
+
+	if len(schema.Type) > 0 && !slices.ContainsFunc(schema.Type, func(t string) bool { return hasType(value, t) }) {
+		return fail("must be %s", strings.Join(schema.Type, " or "))
+	}
+	if len(schema.Enum) > 0 && !slices.ContainsFunc(schema.Enum, func(e any) bool { return fmt.Sprint(e) == fmt.Sprint(value) }) {
+		return fail("must be one of %s", joinEnum(schema.Enum))
+	}
+
+	switch v := value.(type) {
+	case string:
+		n := utf8.RuneCountInString(v)
+		if schema.MinLength != nil && n < *schema.MinLength {
+			if *schema.MinLength == 1 {
+				return fail("must not be empty")
+			}
+			return fail("must be at least %d characters", *schema.MinLength)
+		}
+		if schema.MaxLength != nil && n > *schema.MaxLength {
+			return fail("must be at most %d characters", *schema.MaxLength)
+		}
+	case json.Number:
+		f, _ := v.Float64()
+		if schema.Minimum != nil && f < *schema.Minimum {
+			return fail("must be at least %v", *schema.Minimum)
+		}
+		if schema.Maximum != nil && f > *schema.Maximum {
+			return fail("must be at most %v", *schema.Maximum)
+		}
+	case []any:
+		if schema.MinItems != nil && len(v) < *schema.MinItems {
+			if *schema.MinItems == 1 {
+				return fail("must not be empty")
+			}
+			return fail("must have at least %d items", *schema.MinItems)
+		}
+		if schema.MaxItems != nil && len(v) > *schema.MaxItems {
+			return fail("must have at most %d items", *schema.MaxItems)
+		}
+		for i, item := range v {
+			if err := s.validate(schema.Items, fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		for _, name := range schema.Required {
+			if _, ok := v[name]; !ok {
+				return &ValidationError{Field: joinPath(path, name), Message: "is required"}
+			}
+		}
+		var additional *Schema
+		if len(schema.AdditionalProperties) > 0 && !schema.Closed() {
+			json.Unmarshal(schema.AdditionalProperties, &additional)
+		}
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			property, ok := schema.Properties[name]
+			switch {
+			case ok:
+			case schema.Closed():
+				return &ValidationError{Field: joinPath(path, name), Message: "is not a known field"}
+			default:
+				property = additional
+			}
+			if err := s.validate(property, joinPath(path, name), v[name]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// hasType reports whether a decoded JSON value is of the named JSON Schema
+// type.
+func hasType(value any, name string) bool {
+	switch v := value.(type) {
+	case nil:
+		return name == "null"
+	case bool:
+		return name == "boolean"
+	case string:
+		return name == "string"
+	case json.Number:
+		if name == "number" {
+			return true
+		}
+		if name != "integer" {
+			return false
+		}
+		f, ok := new(big.Float).SetString(v.String())
+		return ok && f.IsInt()
+	case []any:
+		return name == "array"
+	case map[string]any:
+		return name == "object"
+	}
+	return false
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func joinEnum(values []any) string {
+	names := make([]string, len(values))
+	for i, v := range values {
+		names[i] = fmt.Sprintf("%q", fmt.Sprint(v))
+	}
+	return strings.Join(names, ", ")
+}
@@ -1,7 +1,9 @@
 package ai
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 )
 
@@ -541,12 +543,76 @@ This recipe comes from TikTok. Keep in mind:
 - Measurements may be estimated or visual ("eyeball it", "about this much")
 - Videos often skip detailed measurements - infer from visual cues in transcript
 - Multiple recipe variations may be mentioned quickly
+</PLATFORM_CONTEXT>`
+	case "manual":
+		return `<PLATFORM_CONTEXT>
+This recipe was pasted or photographed by the user, not scraped from a post. Keep in mind:
+- The text may be a note, a recipe card, a cookbook page or a screenshot transcribed from a photo
+- There is no video transcript - the text is the only source
+- Formatting may be lost: ingredient lists and steps can run together on a few lines
+- Text transcribed from a photo may contain recognition errors - correct obvious misspellings of ingredients and units
 </PLATFORM_CONTEXT>`
 	default:
 		return ""
 	}
 }
 
+// ImageTextPrompt asks a vision model to transcribe a photographed recipe
+// into plain text for BuildRecipePrompt.
+const ImageTextPrompt = `Transcribe the recipe in this image into plain text.
+Include the title, servings, times, every ingredient with its quantity and unit, and every step, in the order they appear.
+Copy the text as written and in its original language; do not translate, summarize or add anything.
+If the image does not contain a recipe, reply with an empty response.`
+
+// RecipePromptVersion is the version of the recipe extraction prompt. Bump it
+// when BuildRecipePrompt changes so old imports can be regenerated with it.
+const RecipePromptVersion = 1
+
+// recipePrompts builds every recipe prompt version that can still be run.
+// When bumping RecipePromptVersion, keep the builder of the previous version
+// here so recipes can be regenerated with the prompt they were made with.
+var recipePrompts = map[int32]func(platform string) string{
+	RecipePromptVersion: BuildRecipePrompt,
+}
+
+// RecipePromptVersions returns the recipe prompt versions that can be run,
+// oldest first.
+func RecipePromptVersions() []int32 {
+	versions := make([]int32, 0, len(recipePrompts))
+	for v := range recipePrompts {
+		versions = append(versions, v)
+	}
+	slices.Sort(versions)
+	return versions
+}
+
+// HasRecipePromptVersion reports whether a recipe prompt version can be run.
+func HasRecipePromptVersion(version int32) bool {
+	_, ok := recipePrompts[version]
+	return ok
+}
+
+type contextKey string
+
+const recipePromptVersionKey contextKey = "ai.recipe_prompt_version"
+
+// WithRecipePromptVersion returns a context in which RecipePrompt builds the
+// given prompt version instead of the current one.
+func WithRecipePromptVersion(ctx context.Context, version int32) context.Context {
+	return context.WithValue(ctx, recipePromptVersionKey, version)
+}
+
+// RecipePrompt builds the recipe extraction prompt for a platform, using the
+// version set with WithRecipePromptVersion or the current one.
+func RecipePrompt(ctx context.Context, platform string) string {
+	if version, ok := ctx.Value(recipePromptVersionKey).(int32); ok {
+		if build, ok := recipePrompts[version]; ok {
+			return build(platform)
+		}
+	}
+	return BuildRecipePrompt(platform)
+}
+
 // BuildRecipePrompt builds a recipe extraction prompt with optional platform-specific context
 func BuildRecipePrompt(platform string) string {
 	var sb strings.Builder
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"context"
 	"strings"
 	"testing"
 )
@@ -80,6 +81,30 @@ func TestBuildRecipePrompt(t *testing.T) {
 	}
 }
 
+func TestRecipePrompt_Versions(t *testing.T) {
+	versions := RecipePromptVersions()
+	if len(versions) == 0 || versions[len(versions)-1] != RecipePromptVersion {
+		t.Fatalf("expected the current version %d last, got %v", RecipePromptVersion, versions)
+	}
+	for _, v := range versions {
+		if !HasRecipePromptVersion(v) {
+			t.Errorf("expected version %d to be available", v)
+		}
+	}
+	if HasRecipePromptVersion(99) {
+		t.Error("expected version 99 to be unavailable")
+	}
+
+	current := BuildRecipePrompt("instagram")
+	if got := RecipePrompt(context.Background(), "instagram"); got != current {
+		t.Error("expected the current prompt without a version on the context")
+	}
+	ctx := WithRecipePromptVersion(context.Background(), RecipePromptVersion)
+	if got := RecipePrompt(ctx, "instagram"); got != current {
+		t.Error("expected the current prompt for the current version")
+	}
+}
+
 func TestGetPlatformContext(t *testing.T) {
 	tests := []struct {
 		name     string
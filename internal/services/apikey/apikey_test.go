@@ -0,0 +1,82 @@
+package apikey
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	key, err := Generate()
+	if err != nil {
+		t.Fatalf("Generate failed: %v", err)
+	}
+
+	if !IsKey(key.Secret) {
+		t.Errorf("expected %q to be recognised as a key", key.Secret)
+	}
+	if !strings.HasPrefix(key.Secret, key.DisplayPrefix) || len(key.DisplayPrefix) != prefixLength {
+		t.Errorf("unexpected display prefix %q for %q", key.DisplayPrefix, key.Secret)
+	}
+	if key.Hash != Hash(key.Secret) || strings.Contains(key.Hash, key.Secret) {
+		t.Errorf("unexpected hash %q", key.Hash)
+	}
+
+	other, _ := Generate()
+	if other.Secret == key.Secret {
+		t.Error("expected distinct keys")
+	}
+}
+
+func TestIsKey(t *testing.T) {
+	tests := map[string]bool{
+		"rmy_sk_" + strings.Repeat("a", 43): true,
+		"rmy_sk_abc":                        false,
+		"eyJhbGciOiJIUzI1NiJ9.e30.sig":      false,
+		"":                                  false,
+	}
+	for s, want := range tests {
+		if got := IsKey(s); got != want {
+			t.Errorf("IsKey(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
+
+func TestParseScopes(t *testing.T) {
+	scopes, err := ParseScopes([]string{"import:write", " search:read", "import:write"})
+	if err != nil {
+		t.Fatalf("ParseScopes failed: %v", err)
+	}
+	if strings.Join(scopes, ",") != "import:write,search:read" {
+		t.Errorf("unexpected scopes %v", scopes)
+	}
+
+	if _, err := ParseScopes([]string{"superuser"}); err == nil {
+		t.Error("expected error for unknown scope")
+	}
+	if _, err := ParseScopes([]string{ScopeAdmin}); err != nil {
+		t.Errorf("expected admin scope to be accepted: %v", err)
+	}
+	if _, err := ParseScopes(nil); err == nil {
+		t.Error("expected error for no scopes")
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	granted := []string{ScopeImportWrite, ScopeSearchRead}
+
+	tests := []struct {
+		scope string
+		want  bool
+	}{
+		{ScopeImportWrite, true},
+		{ScopeImportRead, true},
+		{ScopeSearchRead, true},
+		{ScopeRecipeRead, false},
+		{ScopeWebhookWrite, false},
+	}
+	for _, tt := range tests {
+		if got := HasScope(granted, tt.scope); got != tt.want {
+			t.Errorf("HasScope(%v, %q) = %v, want %v", granted, tt.scope, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Package authadmin manages Supabase Auth users through the admin API.
+package authadmin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+type Client struct {
+	supabaseURL string
+	serviceKey  string
+	httpClient  *http.Client
+}
+
+func NewClient(supabaseURL, serviceKey string) *Client {
+	return &Client{
+		supabaseURL: supabaseURL,
+		serviceKey:  serviceKey,
+		httpClient:  &http.Client{},
+	}
+}
+
+// DeleteUser deletes an auth user, and with it their email and sign-in
+// identities. Deleting a user that does not exist is not an error.
+func (c *Client) DeleteUser(ctx context.Context, userID string) error {
+	deleteURL := fmt.Sprintf("%s/auth/v1/admin/users/%s", c.supabaseURL, userID)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.serviceKey)
+	req.Header.Set("apikey", c.serviceKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete auth user: %s", string(body))
+	}
+
+	return nil
+}
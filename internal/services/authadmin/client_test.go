@@ -0,0 +1,46 @@
+package authadmin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDeleteUser(t *testing.T) {
+	var gotPath, gotAuth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotAuth = r.Method+" "+r.URL.Path, r.Header.Get("Authorization")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "service-key").DeleteUser(context.Background(), "user-1"); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if gotPath != "DELETE /auth/v1/admin/users/user-1" || gotAuth != "Bearer service-key" {
+		t.Errorf("unexpected request %q with authorization %q", gotPath, gotAuth)
+	}
+}
+
+func TestDeleteUser_MissingUserIsNotAnError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"msg":"User not found"}`, http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "service-key").DeleteUser(context.Background(), "user-1"); err != nil {
+		t.Errorf("expected a missing user to count as deleted, got %v", err)
+	}
+}
+
+func TestDeleteUser_Failure(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"msg":"Database error deleting user"}`, http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if err := NewClient(srv.URL, "service-key").DeleteUser(context.Background(), "user-1"); err == nil {
+		t.Error("expected an error")
+	}
+}
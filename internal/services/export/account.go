@@ -0,0 +1,92 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// AccountArchive is everything stored about one user, answering a data
+// subject access request.
+type AccountArchive struct {
+	UserID     string
+	ExportedAt time.Time
+	// Records holds the user's rows from each table as JSON, keyed by name
+	Records map[string]json.RawMessage
+	Recipes []*Recipe
+	// Images holds the user's recipe images, keyed by storage path
+	Images map[string][]byte
+}
+
+// accountManifest is account.json, the archive's table of contents.
+type accountManifest struct {
+	UserID     string   `json:"user_id"`
+	ExportedAt string   `json:"exported_at"`
+	Records    []string `json:"records"`
+	Recipes    int      `json:"recipes"`
+	Images     int      `json:"images"`
+}
+
+// WriteAccountArchive writes the archive as a zip: account.json describing
+// it, one JSON file per table under data/, the recipes as JSON-LD under
+// recipes/ and their images under images/.
+func WriteAccountArchive(w io.Writer, archive *AccountArchive) error {
+	zw := zip.NewWriter(w)
+
+	names := slices.Sorted(maps.Keys(archive.Records))
+	manifest, err := json.MarshalIndent(accountManifest{
+		UserID:     archive.UserID,
+		ExportedAt: archive.ExportedAt.UTC().Format(time.RFC3339),
+		Records:    names,
+		Recipes:    len(archive.Recipes),
+		Images:     len(archive.Images),
+	}, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := writeFile(zw, "account.json", manifest); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		var data bytes.Buffer
+		record := archive.Records[name]
+		if len(record) == 0 {
+			record = json.RawMessage("null")
+		}
+		if err := json.Indent(&data, record, "", "  "); err != nil {
+			return fmt.Errorf("invalid %s record: %w", name, err)
+		}
+		if err := writeFile(zw, "data/"+name+".json", data.Bytes()); err != nil {
+			return err
+		}
+	}
+
+	if err := writeRecipes(zw, "recipes/", FormatJSONLD, archive.Recipes); err != nil {
+		return err
+	}
+
+	for _, path := range slices.Sorted(maps.Keys(archive.Images)) {
+		if err := writeFile(zw, "images/"+path, archive.Images[path]); err != nil {
+			return err
+		}
+	}
+
+	return zw.Close()
+}
+
+func writeFile(zw *zip.Writer, name string, data []byte) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := f.Write(data); err != nil {
+		return fmt.Errorf("failed to write %s: %w", name, err)
+	}
+	return nil
+}
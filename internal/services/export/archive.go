@@ -0,0 +1,57 @@
+package export
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+)
+
+// Bucket is the private storage bucket bulk exports are written to.
+const Bucket = "exports"
+
+// ArchiveExtension returns the file extension of a bulk export. Paprika
+// imports a zip of recipes under its own extension.
+func ArchiveExtension(format Format) string {
+	if format == FormatPaprika {
+		return ".paprikarecipes"
+	}
+	return ".zip"
+}
+
+// WriteArchive writes recipes as a zip archive with one file per recipe,
+// named after the recipe.
+func WriteArchive(w io.Writer, format Format, recipes []*Recipe) error {
+	zw := zip.NewWriter(w)
+	if err := writeRecipes(zw, "", format, recipes); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeRecipes adds one file per recipe under dir, named after the recipe.
+func writeRecipes(zw *zip.Writer, dir string, format Format, recipes []*Recipe) error {
+	used := map[string]int{}
+
+	for _, r := range recipes {
+		doc, err := Render(format, r)
+		if err != nil {
+			return fmt.Errorf("failed to render recipe %s: %w", r.ID, err)
+		}
+
+		name := Slug(r.Name)
+		used[name]++
+		if n := used[name]; n > 1 {
+			name = fmt.Sprintf("%s-%d", name, n)
+		}
+
+		f, err := zw.Create(dir + name + doc.Extension)
+		if err != nil {
+			return fmt.Errorf("failed to add recipe %s: %w", r.ID, err)
+		}
+		if _, err := f.Write(doc.Data); err != nil {
+			return fmt.Errorf("failed to write recipe %s: %w", r.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,332 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+func testRecipe() *Recipe {
+	return &Recipe{
+		ID:          "3f1b2c4d-0000-4000-8000-000000000001",
+		Name:        "Pizza Night",
+		Description: "Crispy pizza",
+		PrepTime:    20,
+		CookingTime: 70,
+		TotalTime:   90,
+		Servings:    4,
+		SourceURL:   "https://www.example.com/pizza",
+		Categories:  Categories{Cuisines: []string{"Italian"}, DietaryRestrictions: []string{"vegetarian"}},
+		Nutrition:   &Nutrition{Protein: 20, Carbs: 80, Fat: 15, Fiber: 4},
+		Sections: []Section{
+			{
+				Name:        "Dough",
+				Ingredients: []Ingredient{{ID: "ing-1", Name: "flour", Quantity: "500", Unit: "g"}},
+				Steps: []Step{{
+					Number:      1,
+					Text:        "Knead the dough",
+					Timers:      []recipe.Timer{{DurationSeconds: 600, DurationText: "10 minutes", Label: "Knead"}},
+					Ingredients: []StepIngredient{{IngredientID: "ing-1", Name: "flour", Quantity: "500 g"}},
+				}},
+			},
+			{
+				Name:        "Sauce",
+				Optional:    true,
+				Ingredients: []Ingredient{{ID: "ing-2", Name: "tomatoes", Quantity: "400", Unit: "g"}},
+				Steps:       []Step{{Number: 1, Text: "Simmer the tomatoes"}},
+			},
+		},
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	if f, err := ParseFormat(""); err != nil || f != FormatJSONLD {
+		t.Errorf("expected empty format to default to JSON-LD, got %q, %v", f, err)
+	}
+	if f, err := ParseFormat("mealie"); err != nil || f != FormatMealie {
+		t.Errorf("expected mealie, got %q, %v", f, err)
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestIsoDuration(t *testing.T) {
+	tests := map[int]string{0: "", 45: "PT45M", 60: "PT1H", 90: "PT1H30M"}
+	for minutes, want := range tests {
+		if got := isoDuration(minutes); got != want {
+			t.Errorf("isoDuration(%d) = %q, want %q", minutes, got, want)
+		}
+	}
+}
+
+func TestRenderJSONLD(t *testing.T) {
+	doc, err := Render(FormatJSONLD, testRecipe())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out map[string]interface{}
+	if err := json.Unmarshal(doc.Data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out["@type"] != "Recipe" || out["totalTime"] != "PT1H30M" || out["recipeYield"] != "4 servings" {
+		t.Errorf("unexpected recipe fields: %v", out)
+	}
+
+	sections := out["recipeInstructions"].([]interface{})
+	if len(sections) != 2 {
+		t.Fatalf("expected 2 sections, got %d", len(sections))
+	}
+	dough := sections[0].(map[string]interface{})
+	if dough["@type"] != "HowToSection" || dough["name"] != "Dough" {
+		t.Errorf("expected a Dough HowToSection, got %v", dough)
+	}
+	step := dough["itemListElement"].([]interface{})[0].(map[string]interface{})
+	if step["timeRequired"] != "PT10M" {
+		t.Errorf("expected step timer as timeRequired, got %v", step["timeRequired"])
+	}
+	if supply := step["supply"].([]interface{}); len(supply) != 1 || supply[0] != "500 g flour" {
+		t.Errorf("expected step ingredients as supply, got %v", supply)
+	}
+}
+
+func TestRenderJSONLD_WithoutParts(t *testing.T) {
+	r := testRecipe()
+	r.Sections = r.Sections[:1]
+	r.Sections[0].Name = ""
+
+	doc, err := Render(FormatJSONLD, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out struct {
+		RecipeInstructions []map[string]interface{} `json:"recipeInstructions"`
+	}
+	if err := json.Unmarshal(doc.Data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.RecipeInstructions) != 1 || out.RecipeInstructions[0]["@type"] != "HowToStep" {
+		t.Errorf("expected plain steps, got %v", out.RecipeInstructions)
+	}
+}
+
+func TestRenderPaprika(t *testing.T) {
+	doc, err := Render(FormatPaprika, testRecipe())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if doc.Extension != ".paprikarecipe" {
+		t.Errorf("unexpected extension %q", doc.Extension)
+	}
+
+	zr, err := gzip.NewReader(bytes.NewReader(doc.Data))
+	if err != nil {
+		t.Fatalf("expected gzipped data: %v", err)
+	}
+	data, _ := io.ReadAll(zr)
+	var out paprikaRecipe
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if out.Ingredients != "Dough:\n500 g flour\nSauce:\n400 g tomatoes" {
+		t.Errorf("unexpected ingredients %q", out.Ingredients)
+	}
+	if !strings.Contains(out.Directions, "Knead the dough (Knead: 10 minutes)") {
+		t.Errorf("expected timers in directions, got %q", out.Directions)
+	}
+	if out.Source != "example.com" || out.TotalTime != "1 hr 30 min" {
+		t.Errorf("unexpected fields: %+v", out)
+	}
+}
+
+func TestRenderMealie(t *testing.T) {
+	doc, err := Render(FormatMealie, testRecipe())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var out mealieRecipe
+	if err := json.Unmarshal(doc.Data, &out); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(out.RecipeIngredient) != 2 || out.RecipeIngredient[1].Title != "Sauce" {
+		t.Errorf("expected a Sauce ingredient section, got %+v", out.RecipeIngredient)
+	}
+	first := out.RecipeInstructions[0]
+	if first.Title != "Dough" || len(first.IngredientReferences) != 1 || first.IngredientReferences[0].ReferenceID != "ing-1" {
+		t.Errorf("expected step to reference its ingredient, got %+v", first)
+	}
+	if _, err := uuid.Parse(first.ID); err != nil {
+		t.Errorf("expected instruction id to be a uuid, got %q", first.ID)
+	}
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	doc, err := Render(FormatMarkdown, testRecipe())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	md := string(doc.Data)
+	for _, want := range []string{"# Pizza Night", "### Sauce (optional)", "- 500 g flour", "1. Knead the dough", "⏱ Knead: 10 minutes", "Uses: 500 g flour"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("expected markdown to contain %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	r := testRecipe()
+	r.Name = "Pizza <Night>"
+
+	doc, err := Render(FormatHTML, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(doc.Data)
+	if !strings.Contains(html, "<h1>Pizza &lt;Night&gt;</h1>") {
+		t.Error("expected the recipe name to be escaped")
+	}
+	for _, want := range []string{"<h3>Dough</h3>", "<li>500 g flour</li>", "Knead: 10 minutes"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected card to contain %q", want)
+		}
+	}
+}
+
+func TestRenderSharePage(t *testing.T) {
+	r := testRecipe()
+	r.ImageURL = "https://storage.example.com/recipes/pizza.jpg"
+
+	doc, err := RenderSharePage(r, PageMeta{URL: "https://remy.example.com/r/abc", SiteName: "SocialChef"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	html := string(doc.Data)
+	for _, want := range []string{
+		`<meta property="og:title" content="Pizza Night">`,
+		`<meta property="og:url" content="https://remy.example.com/r/abc">`,
+		`<meta property="og:image" content="https://storage.example.com/recipes/pizza.jpg">`,
+		`<meta name="twitter:card" content="summary_large_image">`,
+		`<meta property="og:description" content="Crispy pizza">`,
+		"<li>500 g flour</li>",
+	} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected page to contain %q", want)
+		}
+	}
+
+	card, err := Render(FormatHTML, r)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if strings.Contains(string(card.Data), "og:title") {
+		t.Error("expected the exported card to have no preview tags")
+	}
+}
+
+func TestWriteArchive(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteArchive(&buf, FormatMarkdown, []*Recipe{testRecipe(), testRecipe()}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	if strings.Join(names, ",") != "pizza-night.md,pizza-night-2.md" {
+		t.Errorf("unexpected file names %v", names)
+	}
+}
+
+func TestWriteAccountArchive(t *testing.T) {
+	var buf bytes.Buffer
+	err := WriteAccountArchive(&buf, &AccountArchive{
+		UserID: "user-1",
+		Records: map[string]json.RawMessage{
+			"profile":   json.RawMessage(`{"id":"user-1","username":"chef"}`),
+			"favorites": json.RawMessage(`[]`),
+			"plan":      nil,
+		},
+		Recipes: []*Recipe{testRecipe()},
+		Images:  map[string][]byte{"user-1/pizza.jpg": []byte("jpeg")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := map[string]string{}
+	var names []string
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+		names = append(names, f.Name)
+	}
+	want := "account.json,data/favorites.json,data/plan.json,data/profile.json,recipes/pizza-night.jsonld,images/user-1/pizza.jpg"
+	if strings.Join(names, ",") != want {
+		t.Errorf("unexpected file names %v", names)
+	}
+	if files["data/plan.json"] != "null" {
+		t.Errorf("expected a missing record to be null, got %q", files["data/plan.json"])
+	}
+
+	var manifest accountManifest
+	if err := json.Unmarshal([]byte(files["account.json"]), &manifest); err != nil {
+		t.Fatalf("invalid account.json: %v", err)
+	}
+	if manifest.UserID != "user-1" || manifest.Recipes != 1 || manifest.Images != 1 || len(manifest.Records) != 3 {
+		t.Errorf("unexpected manifest %+v", manifest)
+	}
+}
+
+func TestBuildSections(t *testing.T) {
+	uid := func(b byte) pgtype.UUID { return pgtype.UUID{Bytes: [16]byte{b}, Valid: true} }
+
+	parts := []generated.RecipePart{{ID: uid(1), Name: "Dough"}}
+	ingredients := []generated.RecipeIngredient{
+		{ID: uid(10), Name: "flour", Quantity: pgtype.Text{String: "250", Valid: true}, TotalQuantity: pgtype.Text{String: "500", Valid: true}, Unit: pgtype.Text{String: "g", Valid: true}, PartID: uid(1)},
+		{ID: uid(11), Name: "basil"},
+	}
+	instructions := []generated.RecipeInstruction{
+		{ID: uid(20), StepNumber: 1, Instruction: "Knead", PartID: uid(1), TimerData: []byte(`[{"duration_seconds":600,"duration_text":"10 minutes"}]`)},
+		{ID: uid(21), StepNumber: 2, Instruction: "Garnish"},
+	}
+	links := []generated.InstructionIngredient{{InstructionID: uid(20), IngredientID: uid(10)}}
+
+	sections := buildSections(parts, ingredients, instructions, links)
+
+	if len(sections) != 2 || sections[0].Name != "" || sections[1].Name != "Dough" {
+		t.Fatalf("unexpected sections %+v", sections)
+	}
+	dough := sections[1]
+	if dough.Ingredients[0].Quantity != "500" {
+		t.Errorf("expected the original serving quantity, got %q", dough.Ingredients[0].Quantity)
+	}
+	step := dough.Steps[0]
+	if len(step.Timers) != 1 || step.Timers[0].DurationSeconds != 600 {
+		t.Errorf("expected timers, got %+v", step.Timers)
+	}
+	if len(step.Ingredients) != 1 || step.Ingredients[0].Quantity != "500 g" {
+		t.Errorf("expected step ingredient with the full amount, got %+v", step.Ingredients)
+	}
+}
@@ -0,0 +1,135 @@
+package export
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Format is a recipe export format.
+type Format string
+
+const (
+	FormatJSONLD   Format = "jsonld"
+	FormatPaprika  Format = "paprika"
+	FormatMealie   Format = "mealie"
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{FormatJSONLD, FormatPaprika, FormatMealie, FormatMarkdown, FormatHTML}
+
+// ParseFormat validates a format name. An empty name is JSON-LD.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return FormatJSONLD, nil
+	}
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown export format %q", name)
+}
+
+// Document is a rendered export.
+type Document struct {
+	Data        []byte
+	ContentType string
+	// Extension is the file extension, including the dot
+	Extension string
+}
+
+// Render writes a recipe in the given format.
+func Render(format Format, r *Recipe) (*Document, error) {
+	switch format {
+	case FormatJSONLD:
+		return renderJSONLD(r)
+	case FormatPaprika:
+		return renderPaprika(r)
+	case FormatMealie:
+		return renderMealie(r)
+	case FormatMarkdown:
+		return renderMarkdown(r)
+	case FormatHTML:
+		return renderHTML(r)
+	default:
+		return nil, fmt.Errorf("unknown export format %q", format)
+	}
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+// Slug turns a recipe name into a file name without an extension.
+func Slug(name string) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+	if slug == "" {
+		return "recipe"
+	}
+	if len(slug) > 80 {
+		slug = strings.TrimRight(slug[:80], "-")
+	}
+	return slug
+}
+
+// isoDuration formats minutes as an ISO 8601 duration, e.g. "PT1H30M".
+func isoDuration(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("PT%dM", m)
+	case m == 0:
+		return fmt.Sprintf("PT%dH", h)
+	default:
+		return fmt.Sprintf("PT%dH%dM", h, m)
+	}
+}
+
+// secondsDuration formats seconds as an ISO 8601 duration.
+func secondsDuration(seconds int) string {
+	if seconds <= 0 {
+		return ""
+	}
+	if seconds%60 == 0 {
+		return isoDuration(seconds / 60)
+	}
+	return fmt.Sprintf("PT%dS", seconds)
+}
+
+// humanDuration formats minutes for people, e.g. "1 hr 30 min".
+func humanDuration(minutes int) string {
+	if minutes <= 0 {
+		return ""
+	}
+	h, m := minutes/60, minutes%60
+	switch {
+	case h == 0:
+		return fmt.Sprintf("%d min", m)
+	case m == 0:
+		return fmt.Sprintf("%d hr", h)
+	default:
+		return fmt.Sprintf("%d hr %d min", h, m)
+	}
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, sep)
+}
+
+// timerText describes a step timer, e.g. "Simmer: 10 minutes".
+func timerText(label, duration string) string {
+	if label == "" {
+		return duration
+	}
+	return label + ": " + duration
+}
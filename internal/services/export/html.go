@@ -0,0 +1,135 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+)
+
+// recipeCard is a printable single page recipe card.
+var recipeCard = template.Must(template.New("card").Funcs(template.FuncMap{
+	"duration": humanDuration,
+	"timer":    timerText,
+	"join":     joinNonEmpty,
+}).Parse(`<!DOCTYPE html>
+<html lang="{{if .Language}}{{.Language}}{{else}}en{{end}}">
+<head>
+<meta charset="utf-8">
+<title>{{.Name}}</title>
+{{- with .Meta}}
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<link rel="canonical" href="{{.URL}}">
+<meta property="og:type" content="article">
+<meta property="og:title" content="{{$.Name}}">
+{{- if $.Description}}
+<meta property="og:description" content="{{$.Description}}">
+<meta name="description" content="{{$.Description}}">
+{{- end}}
+<meta property="og:url" content="{{.URL}}">
+{{- if .SiteName}}
+<meta property="og:site_name" content="{{.SiteName}}">
+{{- end}}
+{{- if $.ImageURL}}
+<meta property="og:image" content="{{$.ImageURL}}">
+<meta property="og:image:alt" content="{{$.Name}}">
+<meta name="twitter:card" content="summary_large_image">
+<meta name="twitter:image" content="{{$.ImageURL}}">
+{{- else}}
+<meta name="twitter:card" content="summary">
+{{- end}}
+<meta name="twitter:title" content="{{$.Name}}">
+{{- if $.Description}}
+<meta name="twitter:description" content="{{$.Description}}">
+{{- end}}
+{{- end}}
+<style>
+body { font-family: Georgia, serif; max-width: 46rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+h1 { margin-bottom: .25rem; }
+.meta { color: #555; font-size: .9rem; }
+.meta span + span::before { content: " · "; }
+img { max-width: 100%; max-height: 18rem; object-fit: cover; border-radius: .5rem; }
+.columns { display: grid; grid-template-columns: 1fr 2fr; gap: 2rem; }
+.step-extra { color: #555; font-size: .85rem; }
+.optional { color: #777; font-weight: normal; font-size: .85rem; }
+@media print { body { margin: 0; } a { color: inherit; text-decoration: none; } }
+</style>
+</head>
+<body>
+<h1>{{.Name}}</h1>
+<p class="meta">
+{{- if .Servings}}<span>{{.Servings}} servings</span>{{end}}
+{{- with duration .PrepTime}}<span>Prep {{.}}</span>{{end}}
+{{- with duration .CookingTime}}<span>Cook {{.}}</span>{{end}}
+{{- with duration .TotalTime}}<span>Total {{.}}</span>{{end}}
+</p>
+{{if .ImageURL}}<img src="{{.ImageURL}}" alt="{{.Name}}">{{end}}
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<div class="columns">
+<section>
+<h2>Ingredients</h2>
+{{range .Sections}}
+{{if $.HasParts}}<h3>{{$.Title .}}{{if .Optional}} <span class="optional">(optional)</span>{{end}}</h3>{{end}}
+<ul>
+{{range .Ingredients}}<li>{{.Text}}</li>
+{{end}}</ul>
+{{end}}
+</section>
+<section>
+<h2>Instructions</h2>
+{{range .Sections}}
+{{if $.HasParts}}<h3>{{$.Title .}}</h3>{{if .Description}}<p>{{.Description}}</p>{{end}}{{end}}
+<ol>
+{{range .Steps}}<li>{{.Text}}
+{{- if .Ingredients}}<div class="step-extra">Uses: {{range $i, $u := .Ingredients}}{{if $i}}, {{end}}{{join " " $u.Quantity $u.Name}}{{end}}</div>{{end}}
+{{- range .Timers}}{{if .DurationText}}<div class="step-extra">⏱ {{timer .Label .DurationText}}</div>{{end}}{{end}}</li>
+{{end}}</ol>
+{{end}}
+</section>
+</div>
+{{if or .Nutrition .EstimatedCalories}}
+<h2>Nutrition</h2>
+<p class="meta">
+{{- if .EstimatedCalories}}<span>{{.EstimatedCalories}} kcal</span>{{end}}
+{{- with .Nutrition}}<span>Protein {{.Protein}} g</span><span>Carbohydrates {{.Carbs}} g</span><span>Fat {{.Fat}} g</span><span>Fiber {{.Fiber}} g</span>{{end}}
+</p>
+{{end}}
+{{if .SourceURL}}<p class="meta">Source: <a href="{{.SourceURL}}">{{.SourceURL}}</a></p>{{end}}
+</body>
+</html>
+`))
+
+// htmlCard exposes the section title to the template.
+type htmlCard struct {
+	*Recipe
+	// Meta adds link preview tags for shared recipe pages
+	Meta *PageMeta
+}
+
+// PageMeta describes where a recipe card is published, for the OpenGraph
+// and Twitter card tags that messaging apps use to preview links.
+type PageMeta struct {
+	// URL is the canonical address of the page
+	URL      string
+	SiteName string
+}
+
+func (c htmlCard) Title(s Section) string { return c.sectionTitle(s) }
+
+func renderHTML(r *Recipe) (*Document, error) {
+	return renderCard(htmlCard{Recipe: r})
+}
+
+// RenderSharePage renders the recipe card as a public web page with link
+// preview tags. The preview image is the recipe's ImageURL.
+func RenderSharePage(r *Recipe, meta PageMeta) (*Document, error) {
+	return renderCard(htmlCard{Recipe: r, Meta: &meta})
+}
+
+func renderCard(card htmlCard) (*Document, error) {
+	var buf bytes.Buffer
+	if err := recipeCard.Execute(&buf, card); err != nil {
+		return nil, fmt.Errorf("failed to render recipe card: %w", err)
+	}
+	return &Document{Data: buf.Bytes(), ContentType: "text/html; charset=utf-8", Extension: ".html"}, nil
+}
@@ -0,0 +1,167 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// schema.org Recipe, see https://schema.org/Recipe
+type jsonLDRecipe struct {
+	Context            string           `json:"@context"`
+	Type               string           `json:"@type"`
+	Name               string           `json:"name"`
+	Description        string           `json:"description,omitempty"`
+	Image              string           `json:"image,omitempty"`
+	URL                string           `json:"url,omitempty"`
+	InLanguage         string           `json:"inLanguage,omitempty"`
+	DateCreated        string           `json:"dateCreated,omitempty"`
+	DateModified       string           `json:"dateModified,omitempty"`
+	PrepTime           string           `json:"prepTime,omitempty"`
+	CookTime           string           `json:"cookTime,omitempty"`
+	TotalTime          string           `json:"totalTime,omitempty"`
+	RecipeYield        string           `json:"recipeYield,omitempty"`
+	RecipeCuisine      []string         `json:"recipeCuisine,omitempty"`
+	RecipeCategory     []string         `json:"recipeCategory,omitempty"`
+	Keywords           string           `json:"keywords,omitempty"`
+	SuitableForDiet    []string         `json:"suitableForDiet,omitempty"`
+	Tool               []string         `json:"tool,omitempty"`
+	Nutrition          *jsonLDNutrition `json:"nutrition,omitempty"`
+	RecipeIngredient   []string         `json:"recipeIngredient"`
+	RecipeInstructions []interface{}    `json:"recipeInstructions"`
+}
+
+type jsonLDNutrition struct {
+	Type                string `json:"@type"`
+	Calories            string `json:"calories,omitempty"`
+	ProteinContent      string `json:"proteinContent,omitempty"`
+	CarbohydrateContent string `json:"carbohydrateContent,omitempty"`
+	FatContent          string `json:"fatContent,omitempty"`
+	FiberContent        string `json:"fiberContent,omitempty"`
+}
+
+type jsonLDSection struct {
+	Type            string       `json:"@type"`
+	Name            string       `json:"name"`
+	Description     string       `json:"description,omitempty"`
+	ItemListElement []jsonLDStep `json:"itemListElement"`
+}
+
+type jsonLDStep struct {
+	Type         string   `json:"@type"`
+	Position     int      `json:"position,omitempty"`
+	Text         string   `json:"text"`
+	TimeRequired string   `json:"timeRequired,omitempty"`
+	Supply       []string `json:"supply,omitempty"`
+}
+
+// dietURLs maps dietary restrictions to schema.org RestrictedDiet values.
+var dietURLs = map[string]string{
+	"vegan":       "https://schema.org/VeganDiet",
+	"vegetarian":  "https://schema.org/VegetarianDiet",
+	"gluten-free": "https://schema.org/GlutenFreeDiet",
+	"gluten free": "https://schema.org/GlutenFreeDiet",
+	"halal":       "https://schema.org/HalalDiet",
+	"kosher":      "https://schema.org/KosherDiet",
+	"low-fat":     "https://schema.org/LowFatDiet",
+	"low fat":     "https://schema.org/LowFatDiet",
+	"low-salt":    "https://schema.org/LowSaltDiet",
+	"low-lactose": "https://schema.org/LowLactoseDiet",
+	"diabetic":    "https://schema.org/DiabeticDiet",
+}
+
+func buildJSONLD(r *Recipe) jsonLDRecipe {
+	doc := jsonLDRecipe{
+		Context:          "https://schema.org",
+		Type:             "Recipe",
+		Name:             r.Name,
+		Description:      r.Description,
+		Image:            r.ImageURL,
+		URL:              r.SourceURL,
+		InLanguage:       r.Language,
+		PrepTime:         isoDuration(r.PrepTime),
+		CookTime:         isoDuration(r.CookingTime),
+		TotalTime:        isoDuration(r.TotalTime),
+		RecipeCuisine:    r.Categories.Cuisines,
+		RecipeCategory:   r.Categories.MealTypes,
+		Tool:             r.Categories.Equipment,
+		RecipeIngredient: []string{},
+	}
+	if !r.CreatedAt.IsZero() {
+		doc.DateCreated = r.CreatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if !r.UpdatedAt.IsZero() {
+		doc.DateModified = r.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if r.Servings > 0 {
+		doc.RecipeYield = fmt.Sprintf("%d servings", r.Servings)
+	}
+	doc.Keywords = joinNonEmpty(", ", append(append([]string{r.FocusedDiet}, r.Categories.Occasions...), r.Categories.DietaryRestrictions...)...)
+	for _, d := range r.Categories.DietaryRestrictions {
+		if u, ok := dietURLs[d]; ok {
+			doc.SuitableForDiet = append(doc.SuitableForDiet, u)
+		}
+	}
+
+	if r.Nutrition != nil || r.EstimatedCalories > 0 {
+		n := &jsonLDNutrition{Type: "NutritionInformation"}
+		if r.EstimatedCalories > 0 {
+			n.Calories = fmt.Sprintf("%d calories", r.EstimatedCalories)
+		}
+		if r.Nutrition != nil {
+			n.ProteinContent = grams(r.Nutrition.Protein)
+			n.CarbohydrateContent = grams(r.Nutrition.Carbs)
+			n.FatContent = grams(r.Nutrition.Fat)
+			n.FiberContent = grams(r.Nutrition.Fiber)
+		}
+		doc.Nutrition = n
+	}
+
+	for _, ing := range r.Ingredients() {
+		doc.RecipeIngredient = append(doc.RecipeIngredient, ing.Text())
+	}
+
+	doc.RecipeInstructions = []interface{}{}
+	for _, s := range r.Sections {
+		steps := []jsonLDStep{}
+		for i, step := range s.Steps {
+			st := jsonLDStep{Type: "HowToStep", Position: i + 1, Text: step.Text}
+			total := 0
+			for _, t := range step.Timers {
+				total += t.DurationSeconds
+			}
+			st.TimeRequired = secondsDuration(total)
+			for _, used := range step.Ingredients {
+				st.Supply = append(st.Supply, joinNonEmpty(" ", used.Quantity, used.Name))
+			}
+			steps = append(steps, st)
+		}
+		if !r.HasParts() {
+			for _, st := range steps {
+				doc.RecipeInstructions = append(doc.RecipeInstructions, st)
+			}
+			continue
+		}
+		doc.RecipeInstructions = append(doc.RecipeInstructions, jsonLDSection{
+			Type:            "HowToSection",
+			Name:            r.sectionTitle(s),
+			Description:     s.Description,
+			ItemListElement: steps,
+		})
+	}
+	return doc
+}
+
+func renderJSONLD(r *Recipe) (*Document, error) {
+	data, err := json.MarshalIndent(buildJSONLD(r), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal JSON-LD: %w", err)
+	}
+	return &Document{Data: data, ContentType: "application/ld+json", Extension: ".jsonld"}, nil
+}
+
+func grams(v float64) string {
+	if v <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%g g", v)
+}
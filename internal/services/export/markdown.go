@@ -0,0 +1,112 @@
+package export
+
+import (
+	"fmt"
+	"strings"
+)
+
+func renderMarkdown(r *Recipe) (*Document, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# %s\n\n", r.Name)
+	if r.ImageURL != "" {
+		fmt.Fprintf(&b, "![%s](%s)\n\n", r.Name, r.ImageURL)
+	}
+	if r.Description != "" {
+		fmt.Fprintf(&b, "%s\n\n", r.Description)
+	}
+
+	var facts []string
+	if r.Servings > 0 {
+		facts = append(facts, fmt.Sprintf("**Servings:** %d", r.Servings))
+	}
+	if d := humanDuration(r.PrepTime); d != "" {
+		facts = append(facts, "**Prep:** "+d)
+	}
+	if d := humanDuration(r.CookingTime); d != "" {
+		facts = append(facts, "**Cook:** "+d)
+	}
+	if d := humanDuration(r.TotalTime); d != "" {
+		facts = append(facts, "**Total:** "+d)
+	}
+	if len(facts) > 0 {
+		fmt.Fprintf(&b, "%s\n\n", strings.Join(facts, " · "))
+	}
+	if tags := categoryTags(r.Categories); tags != "" {
+		fmt.Fprintf(&b, "_%s_\n\n", tags)
+	}
+
+	b.WriteString("## Ingredients\n\n")
+	for _, s := range r.Sections {
+		if r.HasParts() {
+			fmt.Fprintf(&b, "### %s%s\n\n", r.sectionTitle(s), optionalSuffix(s))
+		}
+		for _, ing := range s.Ingredients {
+			fmt.Fprintf(&b, "- %s\n", ing.Text())
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("## Instructions\n\n")
+	for _, s := range r.Sections {
+		if r.HasParts() {
+			fmt.Fprintf(&b, "### %s%s\n\n", r.sectionTitle(s), optionalSuffix(s))
+			if s.Description != "" {
+				fmt.Fprintf(&b, "%s\n\n", s.Description)
+			}
+		}
+		for i, step := range s.Steps {
+			fmt.Fprintf(&b, "%d. %s\n", i+1, step.Text)
+			if len(step.Ingredients) > 0 {
+				var used []string
+				for _, u := range step.Ingredients {
+					used = append(used, joinNonEmpty(" ", u.Quantity, u.Name))
+				}
+				fmt.Fprintf(&b, "   - Uses: %s\n", strings.Join(used, ", "))
+			}
+			for _, t := range step.Timers {
+				if t.DurationText != "" {
+					fmt.Fprintf(&b, "   - ⏱ %s\n", timerText(t.Label, t.DurationText))
+				}
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	if n := r.Nutrition; n != nil || r.EstimatedCalories > 0 {
+		b.WriteString("## Nutrition\n\n")
+		if r.EstimatedCalories > 0 {
+			fmt.Fprintf(&b, "- Calories: %d\n", r.EstimatedCalories)
+		}
+		if n != nil {
+			fmt.Fprintf(&b, "- Protein: %g g\n- Carbohydrates: %g g\n- Fat: %g g\n- Fiber: %g g\n", n.Protein, n.Carbs, n.Fat, n.Fiber)
+		}
+		b.WriteString("\n")
+	}
+
+	if r.SourceURL != "" {
+		fmt.Fprintf(&b, "Source: <%s>\n", r.SourceURL)
+	}
+
+	return &Document{
+		Data:        []byte(strings.TrimRight(b.String(), "\n") + "\n"),
+		ContentType: "text/markdown; charset=utf-8",
+		Extension:   ".md",
+	}, nil
+}
+
+func optionalSuffix(s Section) string {
+	if s.Optional {
+		return " (optional)"
+	}
+	return ""
+}
+
+// categoryTags joins the recipe's categories into one line.
+func categoryTags(c Categories) string {
+	var all []string
+	for _, group := range [][]string{c.Cuisines, c.MealTypes, c.Occasions, c.DietaryRestrictions} {
+		all = append(all, group...)
+	}
+	return strings.Join(all, ", ")
+}
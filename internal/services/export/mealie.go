@@ -0,0 +1,175 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+// mealieRecipe follows the Mealie recipe schema. Tandoor imports the same
+// shape through its Mealie importer.
+type mealieRecipe struct {
+	ID                 string              `json:"id"`
+	Name               string              `json:"name"`
+	Slug               string              `json:"slug"`
+	Description        string              `json:"description"`
+	Image              string              `json:"image,omitempty"`
+	OrgURL             string              `json:"orgURL,omitempty"`
+	RecipeYield        string              `json:"recipeYield,omitempty"`
+	RecipeServings     int                 `json:"recipeServings,omitempty"`
+	PrepTime           string              `json:"prepTime,omitempty"`
+	PerformTime        string              `json:"performTime,omitempty"`
+	TotalTime          string              `json:"totalTime,omitempty"`
+	RecipeCategory     []mealieTag         `json:"recipeCategory"`
+	Tags               []mealieTag         `json:"tags"`
+	Tools              []mealieTag         `json:"tools"`
+	RecipeIngredient   []mealieIngredient  `json:"recipeIngredient"`
+	RecipeInstructions []mealieInstruction `json:"recipeInstructions"`
+	Nutrition          *mealieNutrition    `json:"nutrition,omitempty"`
+	DateAdded          string              `json:"dateAdded,omitempty"`
+	DateUpdated        string              `json:"dateUpdated,omitempty"`
+	Settings           mealieSettings      `json:"settings"`
+}
+
+type mealieTag struct {
+	Name string `json:"name"`
+	Slug string `json:"slug"`
+}
+
+type mealieIngredient struct {
+	// Title starts a new section in Mealie
+	Title         string `json:"title,omitempty"`
+	Note          string `json:"note"`
+	Display       string `json:"display"`
+	OriginalText  string `json:"originalText"`
+	Quantity      string `json:"quantity,omitempty"`
+	Unit          string `json:"unit,omitempty"`
+	Food          string `json:"food,omitempty"`
+	DisableAmount bool   `json:"disableAmount"`
+	ReferenceID   string `json:"referenceId"`
+}
+
+type mealieInstruction struct {
+	ID                   string                `json:"id"`
+	Title                string                `json:"title,omitempty"`
+	Text                 string                `json:"text"`
+	IngredientReferences []mealieIngredientRef `json:"ingredientReferences"`
+}
+
+type mealieIngredientRef struct {
+	ReferenceID string `json:"referenceId"`
+}
+
+type mealieNutrition struct {
+	Calories            string `json:"calories,omitempty"`
+	ProteinContent      string `json:"proteinContent,omitempty"`
+	CarbohydrateContent string `json:"carbohydrateContent,omitempty"`
+	FatContent          string `json:"fatContent,omitempty"`
+	FiberContent        string `json:"fiberContent,omitempty"`
+}
+
+type mealieSettings struct {
+	ShowNutrition bool `json:"showNutrition"`
+	DisableAmount bool `json:"disableAmount"`
+}
+
+func buildMealie(r *Recipe) mealieRecipe {
+	m := mealieRecipe{
+		ID:                 r.ID,
+		Name:               r.Name,
+		Slug:               Slug(r.Name),
+		Description:        r.Description,
+		Image:              r.ImageURL,
+		OrgURL:             r.SourceURL,
+		RecipeServings:     r.Servings,
+		PrepTime:           humanDuration(r.PrepTime),
+		PerformTime:        humanDuration(r.CookingTime),
+		TotalTime:          humanDuration(r.TotalTime),
+		RecipeCategory:     mealieTags(r.Categories.MealTypes),
+		Tags:               mealieTags(append(append(append([]string{}, r.Categories.Cuisines...), r.Categories.Occasions...), r.Categories.DietaryRestrictions...)),
+		Tools:              mealieTags(r.Categories.Equipment),
+		RecipeIngredient:   []mealieIngredient{},
+		RecipeInstructions: []mealieInstruction{},
+		Settings:           mealieSettings{ShowNutrition: r.Nutrition != nil, DisableAmount: true},
+	}
+	if r.Servings > 0 {
+		m.RecipeYield = fmt.Sprintf("%d servings", r.Servings)
+	}
+	if !r.CreatedAt.IsZero() {
+		m.DateAdded = r.CreatedAt.Format("2006-01-02")
+	}
+	if !r.UpdatedAt.IsZero() {
+		m.DateUpdated = r.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
+	}
+	if r.Nutrition != nil || r.EstimatedCalories > 0 {
+		n := &mealieNutrition{}
+		if r.EstimatedCalories > 0 {
+			n.Calories = fmt.Sprintf("%d", r.EstimatedCalories)
+		}
+		if r.Nutrition != nil {
+			n.ProteinContent = fmt.Sprintf("%g", r.Nutrition.Protein)
+			n.CarbohydrateContent = fmt.Sprintf("%g", r.Nutrition.Carbs)
+			n.FatContent = fmt.Sprintf("%g", r.Nutrition.Fat)
+			n.FiberContent = fmt.Sprintf("%g", r.Nutrition.Fiber)
+		}
+		m.Nutrition = n
+	}
+
+	for si, s := range r.Sections {
+		title := ""
+		if r.HasParts() {
+			title = r.sectionTitle(s)
+		}
+
+		for i, ing := range s.Ingredients {
+			item := mealieIngredient{
+				Note:         ing.Text(),
+				Display:      ing.Text(),
+				OriginalText: ing.Text(),
+				Quantity:     ing.Quantity,
+				Unit:         ing.Unit,
+				Food:         ing.Name,
+				// Quantities are free text, so Mealie shows the note as is
+				DisableAmount: true,
+				ReferenceID:   ing.ID,
+			}
+			if i == 0 {
+				item.Title = title
+			}
+			m.RecipeIngredient = append(m.RecipeIngredient, item)
+		}
+
+		for i, step := range s.Steps {
+			inst := mealieInstruction{
+				ID:                   uuid.NewSHA1(uuid.NameSpaceOID, []byte(fmt.Sprintf("%s/%d/%d", r.ID, si, i))).String(),
+				Text:                 stepWithTimers(step),
+				IngredientReferences: []mealieIngredientRef{},
+			}
+			if i == 0 {
+				inst.Title = title
+			}
+			for _, used := range step.Ingredients {
+				inst.IngredientReferences = append(inst.IngredientReferences, mealieIngredientRef{ReferenceID: used.IngredientID})
+			}
+			m.RecipeInstructions = append(m.RecipeInstructions, inst)
+		}
+	}
+	return m
+}
+
+func renderMealie(r *Recipe) (*Document, error) {
+	data, err := json.MarshalIndent(buildMealie(r), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Mealie recipe: %w", err)
+	}
+	return &Document{Data: data, ContentType: "application/json", Extension: ".json"}, nil
+}
+
+func mealieTags(names []string) []mealieTag {
+	tags := []mealieTag{}
+	for _, name := range names {
+		tags = append(tags, mealieTag{Name: name, Slug: Slug(name)})
+	}
+	return tags
+}
@@ -0,0 +1,153 @@
+package export
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// paprikaRecipe is the JSON inside a .paprikarecipe file, which Paprika
+// stores gzipped.
+type paprikaRecipe struct {
+	UID             string   `json:"uid"`
+	Name            string   `json:"name"`
+	Description     string   `json:"description"`
+	Ingredients     string   `json:"ingredients"`
+	Directions      string   `json:"directions"`
+	Notes           string   `json:"notes"`
+	NutritionalInfo string   `json:"nutritional_info"`
+	Servings        string   `json:"servings"`
+	PrepTime        string   `json:"prep_time"`
+	CookTime        string   `json:"cook_time"`
+	TotalTime       string   `json:"total_time"`
+	Difficulty      string   `json:"difficulty"`
+	Rating          int      `json:"rating"`
+	Categories      []string `json:"categories"`
+	Source          string   `json:"source"`
+	SourceURL       string   `json:"source_url"`
+	ImageURL        string   `json:"image_url"`
+	Created         string   `json:"created"`
+	Hash            string   `json:"hash"`
+}
+
+var paprikaDifficulty = map[int]string{1: "Easy", 2: "Medium", 3: "Hard"}
+
+// buildPaprika flattens the recipe into Paprika's plain text fields. Parts
+// become heading lines, which Paprika shows in bold, and step timers are
+// written into the step text so Paprika can detect them.
+func buildPaprika(r *Recipe) paprikaRecipe {
+	var ingredients, directions []string
+	for _, s := range r.Sections {
+		if r.HasParts() {
+			heading := r.sectionTitle(s) + ":"
+			ingredients = append(ingredients, heading)
+			directions = append(directions, heading)
+		}
+		for _, ing := range s.Ingredients {
+			ingredients = append(ingredients, ing.Text())
+		}
+		for _, step := range s.Steps {
+			directions = append(directions, stepWithTimers(step))
+		}
+	}
+
+	var nutrition []string
+	if r.EstimatedCalories > 0 {
+		nutrition = append(nutrition, fmt.Sprintf("Calories: %d", r.EstimatedCalories))
+	}
+	if n := r.Nutrition; n != nil {
+		nutrition = append(nutrition,
+			"Protein: "+grams(n.Protein),
+			"Carbohydrates: "+grams(n.Carbs),
+			"Fat: "+grams(n.Fat),
+			"Fiber: "+grams(n.Fiber),
+		)
+	}
+
+	categories := append(append([]string{}, r.Categories.Cuisines...), r.Categories.MealTypes...)
+	if categories == nil {
+		categories = []string{}
+	}
+
+	p := paprikaRecipe{
+		UID:             strings.ToUpper(r.ID),
+		Name:            r.Name,
+		Description:     r.Description,
+		Ingredients:     strings.Join(ingredients, "\n"),
+		Directions:      strings.Join(directions, "\n\n"),
+		Notes:           joinNonEmpty("\n", equipmentNote(r.Categories.Equipment), r.FocusedDiet),
+		NutritionalInfo: strings.Join(nutrition, "\n"),
+		PrepTime:        humanDuration(r.PrepTime),
+		CookTime:        humanDuration(r.CookingTime),
+		TotalTime:       humanDuration(r.TotalTime),
+		Difficulty:      paprikaDifficulty[r.Difficulty],
+		Categories:      categories,
+		Source:          sourceHost(r.SourceURL),
+		SourceURL:       r.SourceURL,
+		ImageURL:        r.ImageURL,
+	}
+	if r.Servings > 0 {
+		p.Servings = fmt.Sprintf("%d", r.Servings)
+	}
+	if !r.CreatedAt.IsZero() {
+		p.Created = r.CreatedAt.UTC().Format("2006-01-02 15:04:05")
+	}
+	sum := sha256.Sum256([]byte(p.Name + p.Ingredients + p.Directions))
+	p.Hash = hex.EncodeToString(sum[:])
+	return p
+}
+
+func renderPaprika(r *Recipe) (*Document, error) {
+	data, err := json.Marshal(buildPaprika(r))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal Paprika recipe: %w", err)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress Paprika recipe: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress Paprika recipe: %w", err)
+	}
+	return &Document{Data: buf.Bytes(), ContentType: "application/octet-stream", Extension: ".paprikarecipe"}, nil
+}
+
+// stepWithTimers returns the step text followed by its timers, e.g.
+// "Simmer the sauce. (Simmer: 10 minutes)".
+func stepWithTimers(step Step) string {
+	var timers []string
+	for _, t := range step.Timers {
+		if t.DurationText != "" {
+			timers = append(timers, timerText(t.Label, t.DurationText))
+		}
+	}
+	if len(timers) == 0 {
+		return step.Text
+	}
+	return fmt.Sprintf("%s (%s)", step.Text, strings.Join(timers, ", "))
+}
+
+func equipmentNote(equipment []string) string {
+	if len(equipment) == 0 {
+		return ""
+	}
+	return "Equipment: " + strings.Join(equipment, ", ")
+}
+
+// sourceHost returns the host of a source URL without "www.".
+func sourceHost(rawURL string) string {
+	host := rawURL
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+3:]
+	}
+	if i := strings.IndexAny(host, "/?#"); i >= 0 {
+		host = host[:i]
+	}
+	return strings.TrimPrefix(host, "www.")
+}
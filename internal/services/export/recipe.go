@@ -0,0 +1,308 @@
+package export
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+type DBQueries interface {
+	GetRecipe(ctx context.Context, id pgtype.UUID) (generated.Recipe, error)
+	GetRecipeParts(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipePart, error)
+	GetIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipeIngredient, error)
+	GetInstructionsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipeInstruction, error)
+	GetInstructionIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.InstructionIngredient, error)
+	GetRecipeCategoryNames(ctx context.Context, recipeID pgtype.UUID) (generated.GetRecipeCategoryNamesRow, error)
+	GetNutritionByRecipe(ctx context.Context, recipeID pgtype.UUID) (generated.RecipeNutrition, error)
+	GetImagesByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.StoredImage, error)
+}
+
+// Recipe is a saved recipe with everything the export formats need.
+type Recipe struct {
+	ID                string
+	Name              string
+	Description       string
+	PrepTime          int
+	CookingTime       int
+	TotalTime         int
+	Servings          int
+	Difficulty        int
+	FocusedDiet       string
+	EstimatedCalories int
+	Language          string
+	SourceURL         string
+	ImageURL          string
+	Categories        Categories
+	Nutrition         *Nutrition
+	// Sections holds the recipe content. A recipe without parts has a
+	// single section with an empty name.
+	Sections  []Section
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+type Categories struct {
+	Cuisines            []string
+	MealTypes           []string
+	Occasions           []string
+	DietaryRestrictions []string
+	Equipment           []string
+}
+
+// Nutrition is per serving, in grams.
+type Nutrition struct {
+	Protein float64
+	Carbs   float64
+	Fat     float64
+	Fiber   float64
+}
+
+type Section struct {
+	Name        string
+	Description string
+	Optional    bool
+	PrepTime    int
+	CookingTime int
+	Ingredients []Ingredient
+	Steps       []Step
+}
+
+type Ingredient struct {
+	ID       string
+	Name     string
+	Quantity string
+	Unit     string
+}
+
+// Text returns the ingredient as a single line, e.g. "200 g flour".
+func (i Ingredient) Text() string {
+	return joinNonEmpty(" ", i.Quantity, i.Unit, i.Name)
+}
+
+type Step struct {
+	Number      int
+	Text        string
+	Timers      []recipe.Timer
+	Ingredients []StepIngredient
+}
+
+// StepIngredient is an ingredient used in a step, with the amount the step
+// uses when it is only part of the total.
+type StepIngredient struct {
+	IngredientID string
+	Name         string
+	Quantity     string
+}
+
+// sectionTitle returns the section name, or the recipe name for the unnamed
+// section.
+func (r *Recipe) sectionTitle(s Section) string {
+	if s.Name != "" {
+		return s.Name
+	}
+	return r.Name
+}
+
+// HasParts reports whether the recipe is split into named parts.
+func (r *Recipe) HasParts() bool {
+	return len(r.Sections) > 1 || (len(r.Sections) == 1 && r.Sections[0].Name != "")
+}
+
+// Ingredients returns the ingredients of every section in order.
+func (r *Recipe) Ingredients() []Ingredient {
+	var all []Ingredient
+	for _, s := range r.Sections {
+		all = append(all, s.Ingredients...)
+	}
+	return all
+}
+
+// Loader reads saved recipes into the export model.
+type Loader struct {
+	db       DBQueries
+	imageURL func(storagePath string) string
+}
+
+// NewLoader creates a loader. imageURL turns a stored image path into the
+// URL exports link to.
+func NewLoader(db DBQueries, imageURL func(storagePath string) string) *Loader {
+	return &Loader{db: db, imageURL: imageURL}
+}
+
+// Load reads a recipe with its parts, ingredients, steps, categories,
+// nutrition and image.
+func (l *Loader) Load(ctx context.Context, id pgtype.UUID) (*Recipe, error) {
+	row, err := l.db.GetRecipe(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe: %w", err)
+	}
+
+	r := &Recipe{
+		ID:                uuid.UUID(row.ID.Bytes).String(),
+		Name:              row.RecipeName,
+		Description:       row.Description.String,
+		PrepTime:          int(row.PrepTime.Int32),
+		CookingTime:       int(row.CookingTime.Int32),
+		TotalTime:         int(row.TotalTime.Int32),
+		Servings:          int(row.OriginalServingSize.Int32),
+		Difficulty:        int(row.DifficultyRating.Int16),
+		FocusedDiet:       row.FocusedDiet.String,
+		EstimatedCalories: int(row.EstimatedCalories.Int32),
+		Language:          row.Language.String,
+		SourceURL:         row.Url,
+		CreatedAt:         row.CreatedAt.Time,
+		UpdatedAt:         row.UpdatedAt.Time,
+	}
+	if r.TotalTime == 0 {
+		r.TotalTime = r.PrepTime + r.CookingTime
+	}
+
+	parts, err := l.db.GetRecipeParts(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recipe parts: %w", err)
+	}
+	ingredients, err := l.db.GetIngredientsByRecipe(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ingredients: %w", err)
+	}
+	instructions, err := l.db.GetInstructionsByRecipe(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instructions: %w", err)
+	}
+	links, err := l.db.GetInstructionIngredientsByRecipe(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get step ingredients: %w", err)
+	}
+	r.Sections = buildSections(parts, ingredients, instructions, links)
+
+	categories, err := l.db.GetRecipeCategoryNames(ctx, id)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	r.Categories = Categories{
+		Cuisines:            categories.CuisineCategories,
+		MealTypes:           categories.MealTypes,
+		Occasions:           categories.Occasions,
+		DietaryRestrictions: categories.DietaryRestrictions,
+		Equipment:           categories.Equipment,
+	}
+
+	nutrition, err := l.db.GetNutritionByRecipe(ctx, id)
+	switch {
+	case err == nil:
+		r.Nutrition = &Nutrition{
+			Protein: numericToFloat(nutrition.Protein),
+			Carbs:   numericToFloat(nutrition.Carbs),
+			Fat:     numericToFloat(nutrition.Fat),
+			Fiber:   numericToFloat(nutrition.Fiber),
+		}
+	case !errors.Is(err, pgx.ErrNoRows):
+		return nil, fmt.Errorf("failed to get nutrition: %w", err)
+	}
+
+	images, err := l.db.GetImagesByRecipe(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get images: %w", err)
+	}
+	if len(images) > 0 && l.imageURL != nil {
+		r.ImageURL = l.imageURL(images[0].StoragePath)
+	}
+
+	return r, nil
+}
+
+// buildSections groups ingredients and steps by part, in part order. Content
+// without a part goes into a leading unnamed section.
+func buildSections(parts []generated.RecipePart, ingredients []generated.RecipeIngredient, instructions []generated.RecipeInstruction, links []generated.InstructionIngredient) []Section {
+	sections := []Section{{}}
+	index := map[[16]byte]int{}
+	for _, p := range parts {
+		index[p.ID.Bytes] = len(sections)
+		sections = append(sections, Section{
+			Name:        p.Name,
+			Description: p.Description.String,
+			Optional:    p.IsOptional,
+			PrepTime:    int(p.PrepTime.Int32),
+			CookingTime: int(p.CookingTime.Int32),
+		})
+	}
+	sectionOf := func(partID pgtype.UUID) int {
+		if !partID.Valid {
+			return 0
+		}
+		if i, ok := index[partID.Bytes]; ok {
+			return i
+		}
+		return 0
+	}
+
+	names := map[[16]byte]Ingredient{}
+	for _, ing := range ingredients {
+		// Exports are for the original serving size
+		quantity := ing.TotalQuantity.String
+		if quantity == "" {
+			quantity = ing.Quantity.String
+		}
+		item := Ingredient{
+			ID:       uuid.UUID(ing.ID.Bytes).String(),
+			Name:     ing.Name,
+			Quantity: quantity,
+			Unit:     ing.Unit.String,
+		}
+		names[ing.ID.Bytes] = item
+		i := sectionOf(ing.PartID)
+		sections[i].Ingredients = append(sections[i].Ingredients, item)
+	}
+
+	used := map[[16]byte][]StepIngredient{}
+	for _, link := range links {
+		ing, ok := names[link.IngredientID.Bytes]
+		if !ok {
+			continue
+		}
+		quantity := link.StepQuantity.String
+		if quantity == "" {
+			quantity = joinNonEmpty(" ", ing.Quantity, ing.Unit)
+		}
+		used[link.InstructionID.Bytes] = append(used[link.InstructionID.Bytes], StepIngredient{
+			IngredientID: ing.ID,
+			Name:         ing.Name,
+			Quantity:     quantity,
+		})
+	}
+
+	for _, inst := range instructions {
+		step := Step{
+			Number:      int(inst.StepNumber),
+			Text:        inst.Instruction,
+			Ingredients: used[inst.ID.Bytes],
+		}
+		if len(inst.TimerData) > 0 {
+			// Timers are optional extras, so a malformed value is dropped
+			_ = json.Unmarshal(inst.TimerData, &step.Timers)
+		}
+		i := sectionOf(inst.PartID)
+		sections[i].Steps = append(sections[i].Steps, step)
+	}
+
+	if len(sections[0].Ingredients) == 0 && len(sections[0].Steps) == 0 && len(sections) > 1 {
+		sections = sections[1:]
+	}
+	return sections
+}
+
+func numericToFloat(n pgtype.Numeric) float64 {
+	f, err := n.Float64Value()
+	if err != nil || !f.Valid {
+		return 0
+	}
+	return f.Float64
+}
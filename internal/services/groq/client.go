@@ -166,7 +166,7 @@ func (c *Client) GenerateRecipe(ctx context.Context, description, transcript, pl
 	if platform == "firecrawl" {
 		systemPrompt = ai.BuildFirecrawlPrompt()
 	} else {
-		systemPrompt = ai.BuildRecipePrompt(platform)
+		systemPrompt = ai.RecipePrompt(ctx, platform)
 	}
 
 	userContent := description
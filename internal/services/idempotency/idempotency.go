@@ -0,0 +1,131 @@
+// Package idempotency remembers the responses to requests sent with an
+// Idempotency-Key header, so clients can safely retry them.
+package idempotency
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// TTL is how long a response is replayed for repeats of its key
+	TTL = 24 * time.Hour
+	// lockTTL bounds how long a key stays claimed by a request that never
+	// completes, for example because the server stopped
+	lockTTL = time.Minute
+)
+
+var (
+	// ErrMismatch means the key was first used with a different request.
+	ErrMismatch = errors.New("idempotency key reused with a different request")
+	// ErrInProgress means the first request with the key has not finished.
+	ErrInProgress = errors.New("idempotent request in progress")
+)
+
+// Response is a stored response to a request.
+type Response struct {
+	Fingerprint string `json:"fingerprint"`
+	// Status is zero while the first request is still being handled
+	Status      int    `json:"status,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Body        []byte `json:"body,omitempty"`
+}
+
+// Fingerprint identifies a request by its parts, such as the route and
+// body, so a reused key can be told apart from a retry.
+func Fingerprint(parts ...[]byte) string {
+	h := sha256.New()
+	for _, part := range parts {
+		fmt.Fprintf(h, "%d:", len(part))
+		h.Write(part)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Store keeps responses in Redis, so retries are recognized by any API
+// instance.
+type Store struct {
+	client *redis.Client
+	prefix string
+}
+
+// NewStore creates a store with the given Redis client.
+func NewStore(client *redis.Client) *Store {
+	return &Store{
+		client: client,
+		prefix: "idempotency:",
+	}
+}
+
+// Begin claims key for a request with the given fingerprint. It returns nil
+// when the request should be handled, and the stored response when it is a
+// repeat of a request that completed. A repeat with another fingerprint
+// fails with ErrMismatch, and one that arrives while the first request is
+// still being handled fails with ErrInProgress.
+func (s *Store) Begin(ctx context.Context, key, fingerprint string) (*Response, error) {
+	pending, err := json.Marshal(Response{Fingerprint: fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	claimed, err := s.client.SetNX(ctx, s.prefix+key, pending, lockTTL).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+	if claimed {
+		return nil, nil
+	}
+
+	data, err := s.client.Get(ctx, s.prefix+key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		// The claim expired between the two commands
+		return nil, ErrInProgress
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load idempotency key: %w", err)
+	}
+
+	var stored Response
+	if err := json.Unmarshal(data, &stored); err != nil {
+		return nil, fmt.Errorf("invalid idempotency record: %w", err)
+	}
+	return check(&stored, fingerprint)
+}
+
+// check decides what a repeat request gets given the stored response.
+func check(stored *Response, fingerprint string) (*Response, error) {
+	if stored.Fingerprint != fingerprint {
+		return nil, ErrMismatch
+	}
+	if stored.Status == 0 {
+		return nil, ErrInProgress
+	}
+	return stored, nil
+}
+
+// Complete stores the response to the request that claimed key.
+func (s *Store) Complete(ctx context.Context, key string, resp Response) error {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Set(ctx, s.prefix+key, data, TTL).Err(); err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+// Release frees key after a request that should not be replayed, such as
+// one that failed, so the client can retry it.
+func (s *Store) Release(ctx context.Context, key string) error {
+	if err := s.client.Del(ctx, s.prefix+key).Err(); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
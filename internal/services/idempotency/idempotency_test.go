@@ -0,0 +1,47 @@
+package idempotency
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	if Fingerprint([]byte("import"), []byte(`{"url":"a"}`)) != Fingerprint([]byte("import"), []byte(`{"url":"a"}`)) {
+		t.Error("expected equal requests to have the same fingerprint")
+	}
+	if Fingerprint([]byte("import"), []byte(`{"url":"a"}`)) == Fingerprint([]byte("import"), []byte(`{"url":"b"}`)) {
+		t.Error("expected different bodies to have different fingerprints")
+	}
+	if Fingerprint([]byte("ab"), []byte("c")) == Fingerprint([]byte("a"), []byte("bc")) {
+		t.Error("expected part boundaries to change the fingerprint")
+	}
+}
+
+func TestCheck(t *testing.T) {
+	completed := &Response{Fingerprint: "abc", Status: 202, Body: []byte(`{"job_id":"1"}`)}
+
+	tests := []struct {
+		name        string
+		stored      *Response
+		fingerprint string
+		want        *Response
+		wantErr     error
+	}{
+		{name: "replay", stored: completed, fingerprint: "abc", want: completed},
+		{name: "different request", stored: completed, fingerprint: "def", wantErr: ErrMismatch},
+		{name: "in progress", stored: &Response{Fingerprint: "abc"}, fingerprint: "abc", wantErr: ErrInProgress},
+		{name: "different request in progress", stored: &Response{Fingerprint: "abc"}, fingerprint: "def", wantErr: ErrMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := check(tt.stored, tt.fingerprint)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+// Package importer reads recipe libraries exported by other recipe managers
+// into recipes that can be saved without generating them with an LLM.
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// Format is the recipe manager an export file comes from.
+type Format string
+
+const (
+	FormatPaprika Format = "paprika"
+	FormatMealie  Format = "mealie"
+	FormatWhisk   Format = "whisk"
+	FormatJSONLD  Format = "jsonld"
+)
+
+// Formats lists every supported format.
+var Formats = []Format{FormatPaprika, FormatMealie, FormatWhisk, FormatJSONLD}
+
+// Bucket is the private storage bucket uploaded export files wait in until
+// the worker reads them.
+const Bucket = "imports"
+
+// Limits on what a single export file may expand to. maxTotalBytes counts
+// every archive entry and gzipped Paprika recipe, so a small, highly
+// compressed upload can't exhaust memory.
+const (
+	maxEntries    = 2000
+	maxEntryBytes = 50 << 20
+)
+
+var maxTotalBytes int64 = 250 << 20
+
+var (
+	ErrUnknownFormat = errors.New("unrecognized export file")
+	ErrNoRecipes     = errors.New("no recipes found in export file")
+	ErrTooLarge      = fmt.Errorf("export file expands to more than %d MB", maxTotalBytes>>20)
+)
+
+// Item is one recipe read from an export file. Items that could not be read
+// carry the error instead of a recipe, so they can be reported per item.
+type Item struct {
+	// Name identifies the item in results: the recipe name, or the file it
+	// came from when it could not be read
+	Name      string
+	Recipe    *recipe.Recipe
+	SourceURL string
+	ImageURL  string
+	// Image is a photo embedded in the export, such as Paprika's photo_data
+	Image []byte
+	Err   error
+}
+
+// ParseFormat validates a format name. An empty name asks Parse to detect
+// the format.
+func ParseFormat(name string) (Format, error) {
+	if name == "" {
+		return "", nil
+	}
+	for _, f := range Formats {
+		if string(f) == name {
+			return f, nil
+		}
+	}
+	return "", fmt.Errorf("unknown import format %q", name)
+}
+
+// file is a single file from an upload, which may be a zip archive.
+type file struct {
+	name string
+	data []byte
+	// err is set when a gzipped entry could not be expanded
+	err error
+}
+
+// Parse reads the recipes in an export file. The file is a single export
+// (JSON, or a Paprika recipe) or a zip archive of them. With an empty format
+// the format is detected from the file.
+func Parse(format Format, filename string, data []byte) ([]Item, error) {
+	files, err := readFiles(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == "" {
+		format = detectFormat(files)
+		if format == "" {
+			return nil, ErrUnknownFormat
+		}
+	}
+
+	var items []Item
+	for _, f := range files {
+		switch format {
+		case FormatPaprika:
+			items = append(items, parsePaprikaFile(f)...)
+		case FormatMealie:
+			items = append(items, parseMealieFile(f, files)...)
+		case FormatWhisk:
+			items = append(items, parseWhiskFile(f)...)
+		case FormatJSONLD:
+			items = append(items, parseJSONLDFile(f)...)
+		default:
+			return nil, fmt.Errorf("unknown import format %q", format)
+		}
+	}
+
+	if len(items) == 0 {
+		return nil, ErrNoRecipes
+	}
+	return items, nil
+}
+
+// readFiles expands a zip archive into its files, and gunzips the gzipped
+// recipes Paprika stores. Paprika's .paprikarecipes archives are zip files
+// too.
+func readFiles(filename string, data []byte) ([]file, error) {
+	remaining := maxTotalBytes
+	if !bytes.HasPrefix(data, []byte("PK\x03\x04")) {
+		f, err := gunzip(filename, data, &remaining)
+		if err != nil {
+			return nil, err
+		}
+		return []file{f}, nil
+	}
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid zip archive: %w", err)
+	}
+	if len(zr.File) > maxEntries {
+		return nil, fmt.Errorf("archive has more than %d files", maxEntries)
+	}
+	var declared uint64
+	for _, zf := range zr.File {
+		declared += zf.UncompressedSize64
+	}
+	if declared > uint64(maxTotalBytes) {
+		return nil, ErrTooLarge
+	}
+
+	var files []file
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || strings.HasPrefix(path.Base(zf.Name), ".") || strings.HasPrefix(zf.Name, "__MACOSX/") {
+			continue
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", zf.Name, err)
+		}
+		// The declared sizes can lie, so the reads are capped as well
+		content, err := readLimited(zf.Name, rc, &remaining)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		f, err := gunzip(zf.Name, content, &remaining)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, f)
+	}
+	return files, nil
+}
+
+// gunzip expands data when it is gzipped and returns it unchanged otherwise.
+// A member that can't be expanded carries its error, to be reported as a
+// failed item; only running out of budget fails the whole export.
+func gunzip(name string, data []byte, remaining *int64) (file, error) {
+	if !bytes.HasPrefix(data, []byte{0x1f, 0x8b}) {
+		return file{name: name, data: data}, nil
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return file{name: name, err: err}, nil
+	}
+	defer zr.Close()
+	content, err := readLimited(name, zr, remaining)
+	if errors.Is(err, ErrTooLarge) {
+		return file{}, err
+	}
+	return file{name: name, data: content, err: err}, nil
+}
+
+// readLimited reads up to maxEntryBytes from r, counting them against the
+// bytes remaining for the whole export.
+func readLimited(name string, r io.Reader, remaining *int64) ([]byte, error) {
+	limit := min(int64(maxEntryBytes), *remaining)
+	content, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	if int64(len(content)) > limit {
+		if limit < maxEntryBytes {
+			return nil, ErrTooLarge
+		}
+		return nil, fmt.Errorf("%s is too large", name)
+	}
+	*remaining -= int64(len(content))
+	return content, nil
+}
+
+// detectFormat guesses the format from file names and the shape of the
+// first JSON document.
+func detectFormat(files []file) Format {
+	for _, f := range files {
+		if strings.HasSuffix(strings.ToLower(f.name), ".paprikarecipe") {
+			return FormatPaprika
+		}
+	}
+	for _, f := range files {
+		if !isJSONFile(f) {
+			continue
+		}
+		var doc interface{}
+		if err := json.Unmarshal(f.data, &doc); err != nil {
+			continue
+		}
+		if format := detectJSONFormat(doc); format != "" {
+			return format
+		}
+	}
+	return ""
+}
+
+func detectJSONFormat(doc interface{}) Format {
+	switch v := doc.(type) {
+	case []interface{}:
+		if len(v) > 0 {
+			return detectJSONFormat(v[0])
+		}
+	case map[string]interface{}:
+		if _, ok := v["@context"]; ok {
+			return FormatJSONLD
+		}
+		if _, ok := v["@graph"]; ok {
+			return FormatJSONLD
+		}
+		if _, ok := v["@type"]; ok {
+			return FormatJSONLD
+		}
+		if _, ok := v["recipeIngredient"]; ok {
+			return FormatMealie
+		}
+		if _, ok := v["ingredients"]; ok {
+			if _, ok := v["directions"]; ok {
+				return FormatPaprika
+			}
+			return FormatWhisk
+		}
+		for _, key := range []string{"recipes", "items"} {
+			if list, ok := v[key]; ok {
+				return detectJSONFormat(list)
+			}
+		}
+	}
+	return ""
+}
+
+func isJSONFile(f file) bool {
+	name := strings.ToLower(f.name)
+	if strings.HasSuffix(name, ".json") || strings.HasSuffix(name, ".jsonld") {
+		return true
+	}
+	trimmed := bytes.TrimSpace(f.data)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[')
+}
+
+// failed returns an item for a file or entry that could not be read.
+func failed(name string, err error) Item {
+	return Item{Name: name, Err: err}
+}
+
+// newItem validates a parsed recipe and wraps it as an item.
+func newItem(fallbackName string, r *recipe.Recipe, sourceURL, imageURL string) Item {
+	name := r.RecipeName
+	if name == "" {
+		name = fallbackName
+	}
+	item := Item{Name: name, Recipe: r, SourceURL: sourceURL, ImageURL: imageURL}
+	switch {
+	case r.RecipeName == "":
+		item.Err = errors.New("recipe has no name")
+	case len(r.FlattenIngredients()) == 0 && len(r.FlattenInstructions()) == 0:
+		item.Err = errors.New("recipe has no ingredients or instructions")
+	}
+	if item.Err != nil {
+		item.Recipe = nil
+	}
+	return item
+}
+
+// Text renders an imported recipe as plain text, stored as its raw caption
+// so that the recipe can be regenerated later like any other.
+func Text(r *recipe.Recipe) string {
+	var b strings.Builder
+	b.WriteString(r.RecipeName + "\n")
+	if r.Description != "" {
+		b.WriteString("\n" + r.Description + "\n")
+	}
+
+	b.WriteString("\nIngredients:\n")
+	for _, ing := range r.FlattenIngredients() {
+		b.WriteString("- " + strings.Join(strings.Fields(string(ing.Quantity)+" "+ing.Unit+" "+ing.Name), " ") + "\n")
+	}
+
+	b.WriteString("\nInstructions:\n")
+	for i, inst := range r.FlattenInstructions() {
+		fmt.Fprintf(&b, "%d. %s\n", i+1, inst.Instruction)
+	}
+	return b.String()
+}
@@ -0,0 +1,308 @@
+package importer
+
+import (
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/socialchef/remy/internal/services/export"
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+func exportedRecipe() *export.Recipe {
+	return &export.Recipe{
+		ID:          "3f1b2c4d-0000-4000-8000-000000000001",
+		Name:        "Pizza Night",
+		Description: "Crispy pizza",
+		PrepTime:    20,
+		CookingTime: 70,
+		TotalTime:   90,
+		Servings:    4,
+		SourceURL:   "https://www.example.com/pizza",
+		Categories:  export.Categories{Cuisines: []string{"Italian"}, MealTypes: []string{"Dinner"}, DietaryRestrictions: []string{"vegetarian"}},
+		Nutrition:   &export.Nutrition{Protein: 20, Carbs: 80, Fat: 15, Fiber: 4},
+		Sections: []export.Section{
+			{
+				Name:        "Dough",
+				Ingredients: []export.Ingredient{{ID: "ing-1", Name: "flour", Quantity: "500", Unit: "g"}},
+				Steps: []export.Step{{
+					Number:      1,
+					Text:        "Knead the dough",
+					Timers:      []recipe.Timer{{DurationSeconds: 600, DurationText: "10 minutes", Label: "Knead"}},
+					Ingredients: []export.StepIngredient{{IngredientID: "ing-1", Name: "flour", Quantity: "500 g"}},
+				}},
+			},
+			{
+				Name:        "Sauce",
+				Ingredients: []export.Ingredient{{ID: "ing-2", Name: "tomatoes", Quantity: "400", Unit: "g"}},
+				Steps:       []export.Step{{Number: 1, Text: "Simmer the tomatoes"}},
+			},
+		},
+	}
+}
+
+func exportArchive(t *testing.T, format export.Format) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := export.WriteArchive(&buf, format, []*export.Recipe{exportedRecipe()}); err != nil {
+		t.Fatalf("failed to write archive: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// assertRoundTrip checks that a recipe exported by us comes back with its
+// parts, quantities and timers.
+func assertRoundTrip(t *testing.T, items []Item) *recipe.Recipe {
+	t.Helper()
+	if len(items) != 1 || items[0].Err != nil {
+		t.Fatalf("expected one recipe, got %+v", items)
+	}
+	r := items[0].Recipe
+	if r.RecipeName != "Pizza Night" || r.TotalTime == nil || *r.TotalTime != 90 || r.OriginalServings == nil || *r.OriginalServings != 4 {
+		t.Errorf("unexpected recipe fields: %+v", r)
+	}
+	if len(r.Parts) != 2 || r.Parts[0].Name != "Dough" || r.Parts[1].Name != "Sauce" {
+		t.Fatalf("expected Dough and Sauce parts, got %+v", r.Parts)
+	}
+	flour := r.Parts[0].Ingredients[0]
+	if flour.Name != "flour" || flour.Quantity != "500" || flour.Unit != "g" {
+		t.Errorf("unexpected ingredient %+v", flour)
+	}
+	if len(r.Ingredients) != 2 || len(r.Instructions) != 2 {
+		t.Errorf("expected flattened ingredients and instructions, got %d and %d", len(r.Ingredients), len(r.Instructions))
+	}
+	if r.Nutrition.Protein != 20 {
+		t.Errorf("expected nutrition, got %+v", r.Nutrition)
+	}
+	return r
+}
+
+func TestParse_PaprikaArchive(t *testing.T) {
+	items, err := Parse("", "export.paprikarecipes", exportArchive(t, export.FormatPaprika))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := assertRoundTrip(t, items)
+
+	knead := r.Parts[0].Instructions[0]
+	if knead.Instruction != "Knead the dough" {
+		t.Errorf("expected the timer suffix to be removed, got %q", knead.Instruction)
+	}
+	if len(knead.TimerData) != 1 || knead.TimerData[0].Label != "Knead" || knead.TimerData[0].DurationSeconds != 600 {
+		t.Errorf("expected a labelled timer, got %+v", knead.TimerData)
+	}
+	if items[0].SourceURL != "https://www.example.com/pizza" {
+		t.Errorf("unexpected source URL %q", items[0].SourceURL)
+	}
+}
+
+func TestParse_MealieArchive(t *testing.T) {
+	items, err := Parse("", "export.zip", exportArchive(t, export.FormatMealie))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := assertRoundTrip(t, items)
+
+	used := r.Parts[0].Instructions[0].IngredientsUsed
+	if len(used) != 1 || used[0].IngredientName != "flour" || used[0].QuantityUsed != "500 g" {
+		t.Errorf("expected the step to use the flour, got %+v", used)
+	}
+}
+
+func TestParse_JSONLD(t *testing.T) {
+	items, err := Parse("", "export.zip", exportArchive(t, export.FormatJSONLD))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r := assertRoundTrip(t, items)
+
+	if len(r.Parts[0].Ingredients) != 2 || r.Parts[0].Ingredients[1].Name != "tomatoes" {
+		t.Errorf("expected ingredients no step uses to go in the first part, got %+v", r.Parts[0].Ingredients)
+	}
+	if len(r.DietaryRestrictions) != 1 || r.DietaryRestrictions[0] != "vegetarian" {
+		t.Errorf("expected diets from suitableForDiet, got %v", r.DietaryRestrictions)
+	}
+}
+
+func TestParse_JSONLDGraph(t *testing.T) {
+	data := []byte(`{
+		"@context": "https://schema.org",
+		"@graph": [
+			{"@type": "WebPage", "name": "Blog"},
+			{
+				"@type": ["Recipe"],
+				"name": "Pancakes",
+				"recipeYield": ["4", "4 pancakes"],
+				"image": {"@type": "ImageObject", "url": "https://example.com/p.jpg"},
+				"recipeIngredient": ["1 ½ cups flour", "2 eggs", "Salt to taste"],
+				"recipeInstructions": [{"@type": "HowToStep", "text": "Mix everything."}, "Fry for 2-3 minutes per side."]
+			}
+		]
+	}`)
+
+	items, err := Parse("", "recipe.json", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 1 || items[0].Err != nil {
+		t.Fatalf("expected one recipe, got %+v", items)
+	}
+	r := items[0].Recipe
+	if items[0].ImageURL != "https://example.com/p.jpg" || *r.OriginalServings != 4 {
+		t.Errorf("unexpected fields: %+v", items[0])
+	}
+	if r.Ingredients[0].Quantity != "1.5" || r.Ingredients[0].Unit != "cups" || r.Ingredients[2].Name != "Salt to taste" {
+		t.Errorf("unexpected ingredients %+v", r.Ingredients)
+	}
+	if timers := r.Instructions[1].TimerData; len(timers) != 1 || timers[0].DurationSeconds != 180 {
+		t.Errorf("expected a 3 minute timer, got %+v", timers)
+	}
+}
+
+func TestParse_Whisk(t *testing.T) {
+	data := []byte(`{"recipes": [
+		{
+			"title": "Salad",
+			"servings": 2,
+			"ingredients": [{"text": "1 head lettuce"}, {"amount": 2, "unit": "tbsp", "name": "olive oil"}],
+			"instructions": ["Wash the lettuce", "Dress it"],
+			"sourceUrl": "https://example.com/salad"
+		},
+		{"title": "Empty"}
+	]}`)
+
+	items, err := Parse(FormatWhisk, "whisk.json", data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 {
+		t.Fatalf("expected two items, got %d", len(items))
+	}
+	salad := items[0].Recipe
+	if salad == nil || salad.RecipeName != "Salad" || len(salad.Ingredients) != 2 || salad.Ingredients[1].Unit != "tbsp" {
+		t.Errorf("unexpected recipe %+v", salad)
+	}
+	if items[1].Err == nil || items[1].Name != "Empty" {
+		t.Errorf("expected the empty recipe to fail, got %+v", items[1])
+	}
+}
+
+func TestParse_UnknownFormat(t *testing.T) {
+	if _, err := Parse("", "notes.txt", []byte("just some text")); err != ErrUnknownFormat {
+		t.Errorf("expected ErrUnknownFormat, got %v", err)
+	}
+}
+
+func TestParseIngredientLine(t *testing.T) {
+	tests := []struct {
+		line, quantity, unit, name string
+	}{
+		{"500 g flour", "500", "g", "flour"},
+		{"1 1/2 cups of sugar", "1.5", "cups", "sugar"},
+		{"½ tsp salt", "0.5", "tsp", "salt"},
+		{"2-3 cloves garlic", "2-3", "cloves", "garlic"},
+		{"3 eggs", "3", "", "eggs"},
+		{"Pepper", "", "", "Pepper"},
+	}
+	for _, tt := range tests {
+		ing := parseIngredientLine(tt.line)
+		if string(ing.Quantity) != tt.quantity || ing.Unit != tt.unit || ing.Name != tt.name {
+			t.Errorf("parseIngredientLine(%q) = %q %q %q", tt.line, ing.Quantity, ing.Unit, ing.Name)
+		}
+	}
+}
+
+func TestParseMinutes(t *testing.T) {
+	tests := map[string]int{"PT1H30M": 90, "45": 45, "1 hr 30 min": 90, "20 minutes": 20, "P0DT0H15M": 15}
+	for s, want := range tests {
+		if got := parseMinutes(s); got == nil || *got != want {
+			t.Errorf("parseMinutes(%q) = %v, want %d", s, got, want)
+		}
+	}
+	if got := parseMinutes("soon"); got != nil {
+		t.Errorf("expected nil for unreadable duration, got %d", *got)
+	}
+}
+
+func TestText(t *testing.T) {
+	r := &recipe.Recipe{
+		RecipeName:   "Toast",
+		Ingredients:  []recipe.Ingredient{newIngredient("2", "slices", "bread")},
+		Instructions: []recipe.Instruction{{StepNumber: 1, Instruction: "Toast the bread"}},
+	}
+	text := Text(r)
+	for _, want := range []string{"Toast\n", "- 2 slices bread", "1. Toast the bread"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("expected %q in %q", want, text)
+		}
+	}
+}
+
+func gzipped(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("failed to gzip: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestParse_RejectsArchivesExpandingPastTheLimit(t *testing.T) {
+	defer func(limit int64) { maxTotalBytes = limit }(maxTotalBytes)
+	maxTotalBytes = 1 << 20
+
+	t.Run("declared size", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		w, err := zw.CreateRaw(&zip.FileHeader{Name: "bomb.json", Method: zip.Store, UncompressedSize64: 2 << 20})
+		if err != nil {
+			t.Fatalf("failed to create entry: %v", err)
+		}
+		w.Write([]byte("{}"))
+		zw.Close()
+
+		if _, err := Parse("", "export.zip", buf.Bytes()); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("expected ErrTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("gzipped members", func(t *testing.T) {
+		// Each member is small in the archive but expands to 512 KiB
+		member := gzipped(t, bytes.Repeat([]byte(" "), 512<<10))
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for i := 0; i < 3; i++ {
+			w, _ := zw.Create(fmt.Sprintf("recipe-%d.paprikarecipe", i))
+			w.Write(member)
+		}
+		zw.Close()
+
+		if _, err := Parse("", "export.paprikarecipes", buf.Bytes()); !errors.Is(err, ErrTooLarge) {
+			t.Errorf("expected ErrTooLarge, got %v", err)
+		}
+	})
+}
+
+func TestParse_CorruptPaprikaMemberFailsItsItem(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("good.paprikarecipe")
+	w.Write(gzipped(t, []byte(`{"name": "Soup", "ingredients": "water", "directions": "Boil"}`)))
+	w, _ = zw.Create("bad.paprikarecipe")
+	w.Write([]byte{0x1f, 0x8b, 0x00})
+	zw.Close()
+
+	items, err := Parse("", "export.paprikarecipes", buf.Bytes())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(items) != 2 || items[0].Err != nil || items[1].Err == nil {
+		t.Errorf("expected one recipe and one failed item, got %+v", items)
+	}
+}
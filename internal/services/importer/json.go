@@ -0,0 +1,80 @@
+package importer
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// flexString reads the loosely typed values export files use for a single
+// text: a string, a number, an object with a name, text or url, or a list
+// of those, of which the first is used.
+type flexString string
+
+func (s *flexString) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*s = flexString(textOf(v))
+	return nil
+}
+
+// flexStrings reads a list of texts that may also be a single value or a
+// comma separated string.
+type flexStrings []string
+
+func (s *flexStrings) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch t := v.(type) {
+	case []interface{}:
+		var items []string
+		for _, item := range t {
+			if text := textOf(item); text != "" {
+				items = append(items, text)
+			}
+		}
+		*s = items
+	case string:
+		*s = splitList(t)
+	default:
+		if text := textOf(t); text != "" {
+			*s = []string{text}
+		}
+	}
+	return nil
+}
+
+// textOf returns the text of a loosely typed JSON value.
+func textOf(v interface{}) string {
+	switch t := v.(type) {
+	case string:
+		return strings.TrimSpace(t)
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64)
+	case map[string]interface{}:
+		for _, key := range []string{"name", "text", "url", "@id"} {
+			if text := textOf(t[key]); text != "" {
+				return text
+			}
+		}
+	case []interface{}:
+		for _, item := range t {
+			if text := textOf(item); text != "" {
+				return text
+			}
+		}
+	}
+	return ""
+}
+
+// parseNumber reads the leading number of a value such as "20 g" or
+// "350 calories".
+func parseNumber(s string) float64 {
+	m := numberPattern.FindString(strings.ReplaceAll(s, ",", "."))
+	v, _ := strconv.ParseFloat(m, 64)
+	return v
+}
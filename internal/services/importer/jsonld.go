@@ -0,0 +1,297 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// jsonLDRecipe reads a schema.org Recipe, see https://schema.org/Recipe.
+type jsonLDRecipe struct {
+	Name               flexString       `json:"name"`
+	Description        flexString       `json:"description"`
+	Image              flexString       `json:"image"`
+	URL                flexString       `json:"url"`
+	InLanguage         flexString       `json:"inLanguage"`
+	PrepTime           flexString       `json:"prepTime"`
+	CookTime           flexString       `json:"cookTime"`
+	TotalTime          flexString       `json:"totalTime"`
+	RecipeYield        flexString       `json:"recipeYield"`
+	RecipeCuisine      flexStrings      `json:"recipeCuisine"`
+	RecipeCategory     flexStrings      `json:"recipeCategory"`
+	SuitableForDiet    flexStrings      `json:"suitableForDiet"`
+	Tool               flexStrings      `json:"tool"`
+	Nutrition          *jsonLDNutrition `json:"nutrition"`
+	RecipeIngredient   flexLines        `json:"recipeIngredient"`
+	Ingredients        flexLines        `json:"ingredients"`
+	RecipeInstructions json.RawMessage  `json:"recipeInstructions"`
+}
+
+type jsonLDNutrition struct {
+	Calories            flexString `json:"calories"`
+	ProteinContent      flexString `json:"proteinContent"`
+	CarbohydrateContent flexString `json:"carbohydrateContent"`
+	FatContent          flexString `json:"fatContent"`
+	FiberContent        flexString `json:"fiberContent"`
+}
+
+// jsonLDStep is a HowToStep, or a HowToSection with its steps in
+// itemListElement.
+type jsonLDStep struct {
+	Name            flexString   `json:"name"`
+	Text            flexString   `json:"text"`
+	TimeRequired    flexString   `json:"timeRequired"`
+	Supply          flexLines    `json:"supply"`
+	ItemListElement []jsonLDStep `json:"itemListElement"`
+}
+
+func (s *jsonLDStep) UnmarshalJSON(data []byte) error {
+	// Steps may be plain text
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*s = jsonLDStep{Text: flexString(text)}
+		return nil
+	}
+	type plain jsonLDStep
+	return json.Unmarshal(data, (*plain)(s))
+}
+
+func parseJSONLDFile(f file) []Item {
+	if !isJSONFile(f) {
+		return nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(f.data, &doc); err != nil {
+		return []Item{failed(path.Base(f.name), fmt.Errorf("invalid JSON-LD: %w", err))}
+	}
+
+	var items []Item
+	for _, node := range recipeNodes(doc) {
+		data, _ := json.Marshal(node)
+		var j jsonLDRecipe
+		if err := json.Unmarshal(data, &j); err != nil {
+			items = append(items, failed(path.Base(f.name), fmt.Errorf("invalid JSON-LD recipe: %w", err)))
+			continue
+		}
+		items = append(items, j.item(path.Base(f.name)))
+	}
+	return items
+}
+
+// recipeNodes finds the Recipe objects in a document, which may be a
+// recipe, a list of them, or a @graph that also holds other types.
+func recipeNodes(doc interface{}) []interface{} {
+	switch v := doc.(type) {
+	case []interface{}:
+		var nodes []interface{}
+		for _, item := range v {
+			nodes = append(nodes, recipeNodes(item)...)
+		}
+		return nodes
+	case map[string]interface{}:
+		if graph, ok := v["@graph"]; ok {
+			return recipeNodes(graph)
+		}
+		if isRecipeType(v["@type"]) {
+			return []interface{}{v}
+		}
+	}
+	return nil
+}
+
+func isRecipeType(t interface{}) bool {
+	switch v := t.(type) {
+	case string:
+		return v == "Recipe" || strings.HasSuffix(v, "/Recipe")
+	case []interface{}:
+		for _, item := range v {
+			if isRecipeType(item) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (j jsonLDRecipe) item(filename string) Item {
+	r := &recipe.Recipe{
+		RecipeName:          firstOf(j.Name),
+		Description:         firstOf(j.Description),
+		PrepTime:            parseMinutes(string(j.PrepTime)),
+		CookingTime:         parseMinutes(string(j.CookTime)),
+		TotalTime:           parseMinutes(string(j.TotalTime)),
+		OriginalServings:    parseServings(string(j.RecipeYield)),
+		CuisineCategories:   j.RecipeCuisine,
+		MealTypes:           j.RecipeCategory,
+		DietaryRestrictions: dietNames(j.SuitableForDiet),
+		Equipment:           j.Tool,
+		Language:            firstOf(j.InLanguage),
+	}
+	if n := j.Nutrition; n != nil {
+		r.EstimatedCalories = positive(int(parseNumber(string(n.Calories))))
+		r.Nutrition = recipe.Nutrition{
+			Protein: parseNumber(string(n.ProteinContent)),
+			Carbs:   parseNumber(string(n.CarbohydrateContent)),
+			Fat:     parseNumber(string(n.FatContent)),
+			Fiber:   parseNumber(string(n.FiberContent)),
+		}
+	}
+
+	ingredientLines := j.RecipeIngredient
+	if len(ingredientLines) == 0 {
+		ingredientLines = j.Ingredients
+	}
+	var ingredients []recipe.Ingredient
+	for _, line := range ingredientLines {
+		ingredients = append(ingredients, parseIngredientLine(line))
+	}
+
+	steps, err := j.steps()
+	if err != nil {
+		return failed(firstOf(j.Name, flexString(filename)), fmt.Errorf("invalid recipe instructions: %w", err))
+	}
+	buildJSONLDContent(r, ingredients, steps)
+
+	return newItem(filename, r, firstOf(j.URL), firstOf(j.Image))
+}
+
+// steps reads recipeInstructions, which may be a block of text or a list
+// of steps and sections.
+func (j jsonLDRecipe) steps() ([]jsonLDStep, error) {
+	if len(j.RecipeInstructions) == 0 || string(j.RecipeInstructions) == "null" {
+		return nil, nil
+	}
+
+	var text string
+	if err := json.Unmarshal(j.RecipeInstructions, &text); err == nil {
+		var steps []jsonLDStep
+		for _, line := range splitLines(text) {
+			steps = append(steps, jsonLDStep{Text: flexString(line)})
+		}
+		return steps, nil
+	}
+
+	var steps []jsonLDStep
+	if err := json.Unmarshal(j.RecipeInstructions, &steps); err != nil {
+		return nil, err
+	}
+	return steps, nil
+}
+
+// buildJSONLDContent adds the ingredients and steps. HowToSections become
+// recipe parts; since schema.org lists ingredients for the whole recipe,
+// each ingredient goes in the first part with a step that uses it.
+func buildJSONLDContent(r *recipe.Recipe, ingredients []recipe.Ingredient, steps []jsonLDStep) {
+	hasSections := false
+	for _, s := range steps {
+		hasSections = hasSections || len(s.ItemListElement) > 0
+	}
+
+	if !hasSections {
+		r.Ingredients = ingredients
+		for _, s := range steps {
+			if inst, ok := s.instruction(len(r.Instructions) + 1); ok {
+				r.Instructions = append(r.Instructions, inst)
+			}
+		}
+		return
+	}
+
+	parts := newPartBuilder(r)
+	for _, s := range steps {
+		if len(s.ItemListElement) == 0 {
+			// A step outside any section; its name is a step title
+			p := parts.part("")
+			if inst, ok := s.instruction(len(p.Instructions) + 1); ok {
+				p.Instructions = append(p.Instructions, inst)
+			}
+			continue
+		}
+		p := parts.part(string(s.Name))
+		for _, child := range s.ItemListElement {
+			if inst, ok := child.instruction(len(p.Instructions) + 1); ok {
+				p.Instructions = append(p.Instructions, inst)
+			}
+		}
+	}
+
+	for _, ing := range ingredients {
+		target := 0
+		for i, p := range r.Parts {
+			if partUses(p, ing.Name) {
+				target = i
+				break
+			}
+		}
+		r.Parts[target].Ingredients = append(r.Parts[target].Ingredients, ing)
+	}
+	flattenParts(r)
+}
+
+func partUses(p recipe.RecipePart, name string) bool {
+	for _, inst := range p.Instructions {
+		for _, used := range inst.IngredientsUsed {
+			if strings.EqualFold(used.IngredientName, name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// instruction converts a HowToStep, using its supply as the ingredients the
+// step uses and timeRequired as its timer when the text names none.
+func (s jsonLDStep) instruction(number int) (recipe.Instruction, bool) {
+	text := firstOf(s.Text, s.Name)
+	steps := newInstructions([]string{text})
+	if len(steps) == 0 {
+		return recipe.Instruction{}, false
+	}
+	inst := steps[0]
+	inst.StepNumber = number
+
+	if len(inst.TimerData) == 0 {
+		if minutes := parseMinutes(string(s.TimeRequired)); minutes != nil {
+			inst.TimerData = []recipe.Timer{{
+				DurationSeconds: *minutes * 60,
+				DurationText:    fmt.Sprintf("%d minutes", *minutes),
+				Type:            "cooking",
+				Category:        "passive",
+			}}
+		}
+	}
+
+	for _, supply := range s.Supply {
+		ing := parseIngredientLine(supply)
+		inst.IngredientsUsed = append(inst.IngredientsUsed, recipe.StepIngredient{
+			IngredientName: ing.Name,
+			QuantityUsed:   strings.TrimSpace(string(ing.Quantity) + " " + ing.Unit),
+		})
+	}
+	return inst, true
+}
+
+// dietNames turns schema.org RestrictedDiet values such as
+// "https://schema.org/GlutenFreeDiet" into names such as "gluten-free".
+func dietNames(diets []string) []string {
+	var names []string
+	for _, d := range diets {
+		d = strings.TrimSuffix(d[strings.LastIndex(d, "/")+1:], "Diet")
+		var b strings.Builder
+		for i, c := range d {
+			if unicode.IsUpper(c) && i > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(unicode.ToLower(c))
+		}
+		if b.Len() > 0 {
+			names = append(names, b.String())
+		}
+	}
+	return names
+}
@@ -0,0 +1,244 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// mealieRecipe follows the Mealie recipe schema. A Mealie export is a zip
+// archive with recipes/<slug>/<slug>.json and the recipe's images next to
+// it; single recipe JSON files and API list responses work too.
+type mealieRecipe struct {
+	Name               string              `json:"name"`
+	Description        string              `json:"description"`
+	OrgURL             string              `json:"orgURL"`
+	Image              flexString          `json:"image"`
+	RecipeYield        flexString          `json:"recipeYield"`
+	RecipeServings     float64             `json:"recipeServings"`
+	PrepTime           flexString          `json:"prepTime"`
+	PerformTime        flexString          `json:"performTime"`
+	CookTime           flexString          `json:"cookTime"`
+	TotalTime          flexString          `json:"totalTime"`
+	RecipeCategory     flexStrings         `json:"recipeCategory"`
+	Tools              flexStrings         `json:"tools"`
+	RecipeIngredient   []mealieIngredient  `json:"recipeIngredient"`
+	RecipeInstructions []mealieInstruction `json:"recipeInstructions"`
+	Nutrition          *mealieNutrition    `json:"nutrition"`
+}
+
+type mealieIngredient struct {
+	// Title starts a new section
+	Title         string     `json:"title"`
+	Note          string     `json:"note"`
+	Display       string     `json:"display"`
+	OriginalText  string     `json:"originalText"`
+	Quantity      flexString `json:"quantity"`
+	Unit          flexString `json:"unit"`
+	Food          flexString `json:"food"`
+	DisableAmount bool       `json:"disableAmount"`
+	ReferenceID   string     `json:"referenceId"`
+}
+
+type mealieInstruction struct {
+	Title                string `json:"title"`
+	Text                 string `json:"text"`
+	IngredientReferences []struct {
+		ReferenceID string `json:"referenceId"`
+	} `json:"ingredientReferences"`
+}
+
+type mealieNutrition struct {
+	Calories            flexString `json:"calories"`
+	ProteinContent      flexString `json:"proteinContent"`
+	CarbohydrateContent flexString `json:"carbohydrateContent"`
+	FatContent          flexString `json:"fatContent"`
+	FiberContent        flexString `json:"fiberContent"`
+}
+
+func parseMealieFile(f file, files []file) []Item {
+	if !isJSONFile(f) {
+		return nil
+	}
+
+	recipes, err := decodeRecipeList[mealieRecipe](f.data)
+	if err != nil {
+		return []Item{failed(path.Base(f.name), fmt.Errorf("invalid Mealie recipe: %w", err))}
+	}
+
+	items := make([]Item, 0, len(recipes))
+	for _, m := range recipes {
+		item := m.item(path.Base(f.name))
+		if item.Err == nil {
+			item.Image = mealieImage(f.name, files)
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (m mealieRecipe) item(filename string) Item {
+	r := &recipe.Recipe{
+		RecipeName:  strings.TrimSpace(m.Name),
+		Description: strings.TrimSpace(m.Description),
+		PrepTime:    parseMinutes(string(m.PrepTime)),
+		CookingTime: parseMinutes(string(m.PerformTime)),
+		TotalTime:   parseMinutes(string(m.TotalTime)),
+		MealTypes:   m.RecipeCategory,
+		Equipment:   m.Tools,
+	}
+	if r.CookingTime == nil {
+		r.CookingTime = parseMinutes(string(m.CookTime))
+	}
+	if m.RecipeServings > 0 {
+		r.OriginalServings = positive(int(m.RecipeServings))
+	} else {
+		r.OriginalServings = parseServings(string(m.RecipeYield))
+	}
+	if n := m.Nutrition; n != nil {
+		r.EstimatedCalories = positive(int(parseNumber(string(n.Calories))))
+		r.Nutrition = recipe.Nutrition{
+			Protein: parseNumber(string(n.ProteinContent)),
+			Carbs:   parseNumber(string(n.CarbohydrateContent)),
+			Fat:     parseNumber(string(n.FatContent)),
+			Fiber:   parseNumber(string(n.FiberContent)),
+		}
+	}
+	m.buildContent(r)
+
+	// Only link the image when it is a URL; exported images are files in
+	// the archive
+	imageURL := string(m.Image)
+	if !strings.HasPrefix(imageURL, "http") {
+		imageURL = ""
+	}
+	return newItem(filename, r, strings.TrimSpace(m.OrgURL), imageURL)
+}
+
+// buildContent adds the ingredients and steps. Titles start sections, which
+// become recipe parts, and ingredient references become the ingredients
+// used in each step.
+func (m mealieRecipe) buildContent(r *recipe.Recipe) {
+	hasTitles := false
+	for _, ing := range m.RecipeIngredient {
+		hasTitles = hasTitles || ing.Title != ""
+	}
+	for _, inst := range m.RecipeInstructions {
+		hasTitles = hasTitles || inst.Title != ""
+	}
+
+	parts := newPartBuilder(r)
+	references := map[string]recipe.Ingredient{}
+
+	title := ""
+	for _, mi := range m.RecipeIngredient {
+		if mi.Title != "" {
+			title = mi.Title
+		}
+		ing := mi.ingredient()
+		if ing.Name == "" {
+			continue
+		}
+		if mi.ReferenceID != "" {
+			references[mi.ReferenceID] = ing
+		}
+		if hasTitles {
+			p := parts.part(title)
+			p.Ingredients = append(p.Ingredients, ing)
+		} else {
+			r.Ingredients = append(r.Ingredients, ing)
+		}
+	}
+
+	title = ""
+	for _, mi := range m.RecipeInstructions {
+		if mi.Title != "" {
+			title = mi.Title
+		}
+		steps := newInstructions([]string{mi.Text})
+		if len(steps) == 0 {
+			continue
+		}
+		step := steps[0]
+		for _, ref := range mi.IngredientReferences {
+			if ing, ok := references[ref.ReferenceID]; ok {
+				step.IngredientsUsed = append(step.IngredientsUsed, recipe.StepIngredient{
+					IngredientName: ing.Name,
+					QuantityUsed:   strings.TrimSpace(string(ing.Quantity) + " " + ing.Unit),
+				})
+			}
+		}
+		if hasTitles {
+			p := parts.part(title)
+			p.Instructions = append(p.Instructions, step)
+		} else {
+			step.StepNumber = len(r.Instructions) + 1
+			r.Instructions = append(r.Instructions, step)
+		}
+	}
+	flattenParts(r)
+}
+
+// ingredient uses Mealie's parsed food, quantity and unit when it has them
+// and parses the ingredient text otherwise.
+func (mi mealieIngredient) ingredient() recipe.Ingredient {
+	if !mi.DisableAmount && mi.Food != "" {
+		quantity := string(mi.Quantity)
+		if quantity == "0" {
+			quantity = ""
+		}
+		return newIngredient(quantity, string(mi.Unit), strings.TrimSpace(string(mi.Food)+" "+mi.Note))
+	}
+	for _, text := range []string{mi.OriginalText, mi.Note, mi.Display} {
+		if text = strings.TrimSpace(text); text != "" {
+			return parseIngredientLine(text)
+		}
+	}
+	return recipe.Ingredient{}
+}
+
+// mealieImage returns the original image stored next to a recipe in a
+// Mealie export archive.
+func mealieImage(recipePath string, files []file) []byte {
+	prefix := path.Join(path.Dir(recipePath), "images", "original.")
+	for _, f := range files {
+		if strings.HasPrefix(f.name, prefix) {
+			return f.data
+		}
+	}
+	return nil
+}
+
+// decodeRecipeList decodes a single recipe, a list of recipes, or an object
+// listing them under "items" or "recipes".
+func decodeRecipeList[T any](data []byte) ([]T, error) {
+	data = bytes.TrimSpace(data)
+	if bytes.HasPrefix(data, []byte("[")) {
+		var list []T
+		err := json.Unmarshal(data, &list)
+		return list, err
+	}
+
+	var wrapper struct {
+		Items   []T `json:"items"`
+		Recipes []T `json:"recipes"`
+	}
+	if err := json.Unmarshal(data, &wrapper); err == nil {
+		if len(wrapper.Items) > 0 {
+			return wrapper.Items, nil
+		}
+		if len(wrapper.Recipes) > 0 {
+			return wrapper.Recipes, nil
+		}
+	}
+
+	var single T
+	if err := json.Unmarshal(data, &single); err != nil {
+		return nil, err
+	}
+	return []T{single}, nil
+}
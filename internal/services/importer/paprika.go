@@ -0,0 +1,121 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// paprikaRecipe is the JSON inside a .paprikarecipe file, which Paprika
+// stores gzipped. A .paprikarecipes export is a zip archive of them.
+type paprikaRecipe struct {
+	Name            string      `json:"name"`
+	Description     string      `json:"description"`
+	Ingredients     string      `json:"ingredients"`
+	Directions      string      `json:"directions"`
+	Notes           string      `json:"notes"`
+	NutritionalInfo string      `json:"nutritional_info"`
+	Servings        flexString  `json:"servings"`
+	PrepTime        flexString  `json:"prep_time"`
+	CookTime        flexString  `json:"cook_time"`
+	TotalTime       flexString  `json:"total_time"`
+	Difficulty      string      `json:"difficulty"`
+	Categories      flexStrings `json:"categories"`
+	SourceURL       string      `json:"source_url"`
+	ImageURL        string      `json:"image_url"`
+	PhotoData       string      `json:"photo_data"`
+}
+
+var paprikaDifficulty = map[string]int{"easy": 1, "medium": 2, "hard": 3}
+
+// parsePaprikaFile reads a Paprika recipe, which readFiles has already
+// gunzipped, or a JSON list of them.
+func parsePaprikaFile(f file) []Item {
+	if f.err != nil {
+		return []Item{failed(path.Base(f.name), fmt.Errorf("invalid Paprika recipe: %w", f.err))}
+	}
+	data := f.data
+	if !isJSONFile(f) {
+		return nil
+	}
+
+	var recipes []paprikaRecipe
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		if err := json.Unmarshal(data, &recipes); err != nil {
+			return []Item{failed(path.Base(f.name), fmt.Errorf("invalid Paprika recipe: %w", err))}
+		}
+	} else {
+		var p paprikaRecipe
+		if err := json.Unmarshal(data, &p); err != nil {
+			return []Item{failed(path.Base(f.name), fmt.Errorf("invalid Paprika recipe: %w", err))}
+		}
+		recipes = append(recipes, p)
+	}
+
+	items := make([]Item, 0, len(recipes))
+	for _, p := range recipes {
+		items = append(items, p.item(path.Base(f.name)))
+	}
+	return items
+}
+
+func (p paprikaRecipe) item(filename string) Item {
+	r := &recipe.Recipe{
+		RecipeName:       strings.TrimSpace(p.Name),
+		Description:      strings.TrimSpace(p.Description),
+		PrepTime:         parseMinutes(string(p.PrepTime)),
+		CookingTime:      parseMinutes(string(p.CookTime)),
+		TotalTime:        parseMinutes(string(p.TotalTime)),
+		OriginalServings: parseServings(string(p.Servings)),
+		MealTypes:        p.Categories,
+	}
+	if d, ok := paprikaDifficulty[strings.ToLower(p.Difficulty)]; ok {
+		r.DifficultyRating = &d
+	}
+	r.Nutrition, r.EstimatedCalories = parseNutritionText(p.NutritionalInfo)
+	for _, line := range splitLines(p.Notes) {
+		if equipment, ok := strings.CutPrefix(line, "Equipment: "); ok {
+			r.Equipment = splitList(equipment)
+		}
+	}
+	buildContent(r, splitLines(p.Ingredients), splitLines(p.Directions))
+
+	item := newItem(filename, r, strings.TrimSpace(p.SourceURL), strings.TrimSpace(p.ImageURL))
+	if item.Err == nil && p.PhotoData != "" {
+		if photo, err := base64.StdEncoding.DecodeString(p.PhotoData); err == nil {
+			item.Image = photo
+		}
+	}
+	return item
+}
+
+// parseNutritionText reads "Key: value" nutrition lines such as
+// "Protein: 20 g".
+func parseNutritionText(s string) (recipe.Nutrition, *int) {
+	var n recipe.Nutrition
+	var calories *int
+	for _, line := range splitLines(s) {
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "calories", "energy":
+			calories = positive(int(parseNumber(value)))
+		case "protein":
+			n.Protein = parseNumber(value)
+		case "carbohydrates", "carbs", "carbohydrate":
+			n.Carbs = parseNumber(value)
+		case "fat", "total fat":
+			n.Fat = parseNumber(value)
+		case "fiber", "fibre", "dietary fiber":
+			n.Fiber = parseNumber(value)
+		}
+	}
+	return n, calories
+}
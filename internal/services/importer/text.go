@@ -0,0 +1,404 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// units are the ingredient units recognised after a quantity, lower case.
+var units = map[string]bool{
+	"g": true, "gr": true, "gram": true, "grams": true, "kg": true, "kilogram": true, "kilograms": true,
+	"mg": true, "ml": true, "milliliter": true, "milliliters": true, "millilitre": true, "millilitres": true,
+	"cl": true, "dl": true, "l": true, "liter": true, "liters": true, "litre": true, "litres": true,
+	"tsp": true, "teaspoon": true, "teaspoons": true, "tbsp": true, "tablespoon": true, "tablespoons": true,
+	"el": true, "tl": true, "cup": true, "cups": true, "oz": true, "ounce": true, "ounces": true,
+	"lb": true, "lbs": true, "pound": true, "pounds": true, "pint": true, "pints": true, "quart": true, "quarts": true,
+	"clove": true, "cloves": true, "pinch": true, "pinches": true, "dash": true, "can": true, "cans": true,
+	"slice": true, "slices": true, "bunch": true, "bunches": true, "sprig": true, "sprigs": true,
+	"stick": true, "sticks": true, "piece": true, "pieces": true, "handful": true, "package": true, "packages": true,
+}
+
+var unicodeFractions = map[rune]string{
+	'¼': "1/4", '½': "1/2", '¾': "3/4", '⅓': "1/3", '⅔': "2/3", '⅛': "1/8", '⅜': "3/8", '⅝': "5/8", '⅞': "7/8",
+}
+
+// quantityPattern matches a leading quantity: a number, decimal, fraction,
+// mixed number or range.
+var quantityPattern = regexp.MustCompile(`^(\d+/\d+|\d+(?:[.,]\d+)?(?:\s+\d+/\d+)?)(?:\s*[-–]\s*(\d+/\d+|\d+(?:[.,]\d+)?))?`)
+
+// parseIngredientLine splits a free text ingredient such as "1 1/2 cups
+// flour" into quantity, unit and name. Lines without a leading quantity are
+// kept whole as the name.
+func parseIngredientLine(line string) recipe.Ingredient {
+	line = normalizeFractions(strings.TrimSpace(strings.TrimLeft(line, "-•* ")))
+
+	m := quantityPattern.FindStringSubmatch(line)
+	if m == nil {
+		return recipe.Ingredient{Name: line}
+	}
+
+	quantity := parseQuantity(m[1])
+	if m[2] != "" {
+		quantity = fmt.Sprintf("%s-%s", quantity, parseQuantity(m[2]))
+	}
+	rest := strings.TrimSpace(line[len(m[0]):])
+
+	var unit string
+	if fields := strings.Fields(rest); len(fields) > 1 {
+		candidate := strings.ToLower(strings.TrimSuffix(fields[0], "."))
+		if units[candidate] {
+			unit = candidate
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, fields[0]))
+			rest = strings.TrimSpace(strings.TrimPrefix(rest, "of "))
+		}
+	}
+	if rest == "" {
+		return recipe.Ingredient{Name: line}
+	}
+
+	return newIngredient(quantity, unit, rest)
+}
+
+// newIngredient sets every quantity field, since imported quantities are
+// always for the whole recipe.
+func newIngredient(quantity, unit, name string) recipe.Ingredient {
+	q := recipe.StringOrNumber(quantity)
+	return recipe.Ingredient{
+		OriginalQuantity: q,
+		OriginalUnit:     unit,
+		TotalQuantity:    q,
+		Quantity:         q,
+		Unit:             unit,
+		Name:             strings.TrimSpace(name),
+	}
+}
+
+func normalizeFractions(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		if frac, ok := unicodeFractions[r]; ok {
+			// "1½" is a mixed number
+			if b.Len() > 0 && unicode.IsDigit(rune(b.String()[b.Len()-1])) {
+				b.WriteByte(' ')
+			}
+			b.WriteString(frac)
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// parseQuantity converts fractions and mixed numbers to decimals so that
+// quantities can be scaled.
+func parseQuantity(s string) string {
+	s = strings.ReplaceAll(strings.TrimSpace(s), ",", ".")
+	var total float64
+	for _, part := range strings.Fields(s) {
+		if num, den, ok := strings.Cut(part, "/"); ok {
+			n, err1 := strconv.ParseFloat(num, 64)
+			d, err2 := strconv.ParseFloat(den, 64)
+			if err1 != nil || err2 != nil || d == 0 {
+				return s
+			}
+			total += n / d
+			continue
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return s
+		}
+		total += v
+	}
+	return strconv.FormatFloat(total, 'f', -1, 64)
+}
+
+var (
+	isoDurationPattern = regexp.MustCompile(`(?i)^P(?:(\d+)D)?T?(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?$`)
+	hoursPattern       = regexp.MustCompile(`(?i)(\d+(?:[.,]\d+)?)\s*(?:h|hr|hrs|hour|hours|uur|std)\b`)
+	minutesPattern     = regexp.MustCompile(`(?i)(\d+)\s*(?:m|min|mins|minute|minutes|minuten)\b`)
+)
+
+// parseMinutes reads a duration such as "PT1H30M", "1 hr 30 min" or "45"
+// in minutes. It returns nil when the duration is missing or unreadable.
+func parseMinutes(s string) *int {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+
+	if m := isoDurationPattern.FindStringSubmatch(s); m != nil && s != "P" && s != "PT" {
+		minutes := atoi(m[1])*24*60 + atoi(m[2])*60 + atoi(m[3]) + (atoi(m[4])+59)/60
+		return positive(minutes)
+	}
+
+	if n, err := strconv.Atoi(s); err == nil {
+		return positive(n)
+	}
+
+	minutes := 0
+	if m := hoursPattern.FindStringSubmatch(s); m != nil {
+		h, _ := strconv.ParseFloat(strings.ReplaceAll(m[1], ",", "."), 64)
+		minutes += int(h * 60)
+	}
+	if m := minutesPattern.FindStringSubmatch(s); m != nil {
+		minutes += atoi(m[1])
+	}
+	return positive(minutes)
+}
+
+var numberPattern = regexp.MustCompile(`\d+(?:\.\d+)?`)
+
+// parseServings reads the leading number of a yield such as "4 servings".
+func parseServings(s string) *int {
+	return positive(int(parseNumber(s)))
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func positive(n int) *int {
+	if n <= 0 {
+		return nil
+	}
+	return &n
+}
+
+// timerPattern finds durations such as "10 minutes", "1-2 hours" or
+// "30 sec" in instruction text.
+var timerPattern = regexp.MustCompile(`(?i)\b(\d+(?:[.,]\d+)?)(?:\s*(?:-|–|to)\s*(\d+(?:[.,]\d+)?))?\s*(seconds?|secs?|minutes?|mins?|hours?|hrs?)\b`)
+
+// extractTimers finds the durations mentioned in an instruction. Ranges use
+// the upper bound, so the timer does not go off early.
+func extractTimers(text string) []recipe.Timer {
+	var timers []recipe.Timer
+	for _, m := range timerPattern.FindAllStringSubmatch(text, -1) {
+		value := m[1]
+		if m[2] != "" {
+			value = m[2]
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", "."), 64)
+		if err != nil || amount <= 0 {
+			continue
+		}
+
+		unit := strings.ToLower(m[3])
+		seconds := amount
+		switch {
+		case strings.HasPrefix(unit, "h"):
+			seconds *= 3600
+		case strings.HasPrefix(unit, "m"):
+			seconds *= 60
+		}
+
+		timers = append(timers, recipe.Timer{
+			DurationSeconds: int(seconds),
+			DurationText:    m[0],
+			Type:            "cooking",
+			Category:        "passive",
+		})
+	}
+	return timers
+}
+
+// section is a run of ingredient or instruction lines under an optional
+// heading.
+type section struct {
+	name  string
+	lines []string
+}
+
+// splitSections groups text lines under headings. A heading is a short line
+// ending in a colon, the convention Paprika uses and our own exports follow.
+func splitSections(lines []string) []section {
+	var sections []section
+	current := section{}
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if isHeading(line) {
+			if current.name != "" || len(current.lines) > 0 {
+				sections = append(sections, current)
+			}
+			current = section{name: strings.TrimSpace(strings.TrimSuffix(line, ":"))}
+			continue
+		}
+		current.lines = append(current.lines, line)
+	}
+	if current.name != "" || len(current.lines) > 0 {
+		sections = append(sections, current)
+	}
+	return sections
+}
+
+func isHeading(line string) bool {
+	return strings.HasSuffix(line, ":") && len(line) <= 60 && !quantityPattern.MatchString(line)
+}
+
+// numberedStepPattern strips step numbering such as "1." or "Step 2:".
+var numberedStepPattern = regexp.MustCompile(`(?i)^(?:step\s*)?\d+[.):]\s+`)
+
+// newInstructions turns instruction text lines into numbered steps with
+// their timers.
+func newInstructions(lines []string) []recipe.Instruction {
+	instructions := make([]recipe.Instruction, 0, len(lines))
+	for _, line := range lines {
+		text := strings.TrimSpace(numberedStepPattern.ReplaceAllString(strings.TrimSpace(line), ""))
+		if text == "" {
+			continue
+		}
+		step := recipe.Instruction{StepNumber: len(instructions) + 1, Instruction: text}
+		if stripped, timers := splitTimerSuffix(text); timers != nil {
+			step.Instruction, step.TimerData = stripped, timers
+		} else {
+			step.TimerData = extractTimers(text)
+		}
+		instructions = append(instructions, step)
+	}
+	return instructions
+}
+
+// timerSuffixPattern matches a trailing timer list such as "(Simmer: 10
+// minutes, 5 minutes)", which our Paprika and Mealie exports write.
+var timerSuffixPattern = regexp.MustCompile(`\s*\(([^()]+)\)$`)
+
+// splitTimerSuffix removes a trailing timer list from a step and returns
+// its labelled timers. It returns nil timers unless every entry in the list
+// is a duration.
+func splitTimerSuffix(text string) (string, []recipe.Timer) {
+	m := timerSuffixPattern.FindStringSubmatchIndex(text)
+	if m == nil {
+		return text, nil
+	}
+
+	var timers []recipe.Timer
+	for _, entry := range strings.Split(text[m[2]:m[3]], ",") {
+		label, duration, ok := strings.Cut(strings.TrimSpace(entry), ": ")
+		if !ok {
+			label, duration = "", strings.TrimSpace(entry)
+		}
+		found := extractTimers(duration)
+		if len(found) != 1 || found[0].DurationText != duration {
+			return text, nil
+		}
+		found[0].Label = label
+		timers = append(timers, found[0])
+	}
+	return strings.TrimSpace(text[:m[0]]), timers
+}
+
+// buildContent fills the recipe's ingredients and instructions from text
+// lines. Headings in either list become recipe parts, matched up by name,
+// and the flattened lists are filled too.
+func buildContent(r *recipe.Recipe, ingredientLines, instructionLines []string) {
+	ingredientSections := splitSections(ingredientLines)
+	instructionSections := splitSections(instructionLines)
+
+	if !hasNamedSection(ingredientSections) && !hasNamedSection(instructionSections) {
+		for _, s := range ingredientSections {
+			for _, line := range s.lines {
+				r.Ingredients = append(r.Ingredients, parseIngredientLine(line))
+			}
+		}
+		for _, s := range instructionSections {
+			r.Instructions = append(r.Instructions, newInstructions(s.lines)...)
+		}
+		return
+	}
+
+	parts := newPartBuilder(r)
+	for _, s := range ingredientSections {
+		p := parts.part(s.name)
+		for _, line := range s.lines {
+			p.Ingredients = append(p.Ingredients, parseIngredientLine(line))
+		}
+	}
+	for _, s := range instructionSections {
+		p := parts.part(s.name)
+		p.Instructions = append(p.Instructions, newInstructions(s.lines)...)
+	}
+	flattenParts(r)
+}
+
+// partBuilder adds recipe parts by name, so ingredient and instruction
+// sections with the same heading end up in one part.
+type partBuilder struct {
+	r     *recipe.Recipe
+	index map[string]int
+}
+
+func newPartBuilder(r *recipe.Recipe) *partBuilder {
+	return &partBuilder{r: r, index: map[string]int{}}
+}
+
+// part returns the part with the given name, adding it if needed. Content
+// before the first heading goes in a part named after the recipe. The
+// pointer is only valid until the next call.
+func (b *partBuilder) part(name string) *recipe.RecipePart {
+	if name == "" {
+		name = b.r.RecipeName
+	}
+	key := strings.ToLower(name)
+	if i, ok := b.index[key]; ok {
+		return &b.r.Parts[i]
+	}
+	b.index[key] = len(b.r.Parts)
+	b.r.Parts = append(b.r.Parts, recipe.RecipePart{Name: name, DisplayOrder: len(b.r.Parts)})
+	return &b.r.Parts[len(b.r.Parts)-1]
+}
+
+func hasNamedSection(sections []section) bool {
+	for _, s := range sections {
+		if s.name != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// flattenParts numbers each part's steps from one and copies part
+// ingredients and instructions to the recipe, as generated recipes with
+// parts have them too.
+func flattenParts(r *recipe.Recipe) {
+	if !r.HasParts() {
+		return
+	}
+	for i := range r.Parts {
+		for j := range r.Parts[i].Instructions {
+			r.Parts[i].Instructions[j].StepNumber = j + 1
+		}
+	}
+	r.Ingredients = r.FlattenIngredients()
+	r.Instructions = r.FlattenInstructions()
+}
+
+// splitLines splits a block of text into trimmed, non-empty lines.
+func splitLines(s string) []string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// splitList splits a comma separated list such as Paprika categories.
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,130 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// whiskRecipe reads Whisk (Samsung Food) recipe exports. Whisk has no
+// documented export schema, so the common field names are all accepted and
+// ingredients and steps may be text, lists of text or lists of objects.
+type whiskRecipe struct {
+	Name         flexString  `json:"name"`
+	Title        flexString  `json:"title"`
+	Description  flexString  `json:"description"`
+	Ingredients  flexLines   `json:"ingredients"`
+	Instructions flexLines   `json:"instructions"`
+	Directions   flexLines   `json:"directions"`
+	Steps        flexLines   `json:"steps"`
+	Method       flexLines   `json:"method"`
+	Servings     flexString  `json:"servings"`
+	Yield        flexString  `json:"yield"`
+	PrepTime     flexString  `json:"prepTime"`
+	CookTime     flexString  `json:"cookTime"`
+	TotalTime    flexString  `json:"totalTime"`
+	Source       flexString  `json:"source"`
+	SourceURL    flexString  `json:"sourceUrl"`
+	URL          flexString  `json:"url"`
+	Image        flexString  `json:"image"`
+	ImageURL     flexString  `json:"imageUrl"`
+	Images       flexString  `json:"images"`
+	Tags         flexStrings `json:"tags"`
+}
+
+func parseWhiskFile(f file) []Item {
+	if !isJSONFile(f) {
+		return nil
+	}
+
+	recipes, err := decodeRecipeList[whiskRecipe](f.data)
+	if err != nil {
+		return []Item{failed(path.Base(f.name), fmt.Errorf("invalid Whisk recipe: %w", err))}
+	}
+
+	items := make([]Item, 0, len(recipes))
+	for _, w := range recipes {
+		items = append(items, w.item(path.Base(f.name)))
+	}
+	return items
+}
+
+func (w whiskRecipe) item(filename string) Item {
+	r := &recipe.Recipe{
+		RecipeName:       firstOf(w.Name, w.Title),
+		Description:      firstOf(w.Description),
+		PrepTime:         parseMinutes(string(w.PrepTime)),
+		CookingTime:      parseMinutes(string(w.CookTime)),
+		TotalTime:        parseMinutes(string(w.TotalTime)),
+		OriginalServings: parseServings(firstOf(w.Servings, w.Yield)),
+		MealTypes:        w.Tags,
+	}
+
+	instructions := w.Instructions
+	for _, alt := range []flexLines{w.Directions, w.Steps, w.Method} {
+		if len(instructions) == 0 {
+			instructions = alt
+		}
+	}
+	buildContent(r, w.Ingredients, instructions)
+
+	sourceURL := firstOf(w.SourceURL, w.URL)
+	if sourceURL == "" && strings.HasPrefix(string(w.Source), "http") {
+		sourceURL = string(w.Source)
+	}
+	return newItem(filename, r, sourceURL, firstOf(w.ImageURL, w.Image, w.Images))
+}
+
+// flexLines reads text lines from a block of text, a list of texts, or a
+// list of objects with the text or its quantity, unit and name.
+type flexLines []string
+
+func (l *flexLines) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*l = linesOf(v)
+	return nil
+}
+
+func linesOf(v interface{}) []string {
+	switch t := v.(type) {
+	case string:
+		return splitLines(t)
+	case []interface{}:
+		var lines []string
+		for _, item := range t {
+			lines = append(lines, linesOf(item)...)
+		}
+		return lines
+	case map[string]interface{}:
+		for _, key := range []string{"text", "originalText", "raw"} {
+			if text := textOf(t[key]); text != "" {
+				return []string{text}
+			}
+		}
+		name := textOf(t["name"])
+		if name == "" {
+			return nil
+		}
+		quantity := textOf(t["amount"])
+		if quantity == "" {
+			quantity = textOf(t["quantity"])
+		}
+		return []string{strings.Join(strings.Fields(quantity+" "+textOf(t["unit"])+" "+name), " ")}
+	}
+	return nil
+}
+
+func firstOf(values ...flexString) string {
+	for _, v := range values {
+		if s := strings.TrimSpace(string(v)); s != "" {
+			return s
+		}
+	}
+	return ""
+}
@@ -58,6 +58,20 @@ var (
 	ErrNoEmbedding = errors.New("no embedding returned")
 )
 
+// Models used for search; cache keys include them so a model change
+// never serves stale results.
+const (
+	EmbeddingModel  = "text-embedding-ada-002"
+	CompletionModel = "gpt-4o-mini"
+)
+
+// VisionModel transcribes recipe photos.
+const VisionModel = "gpt-4o-mini"
+
+// maxEmbeddingBatchSize caps the inputs per embeddings request, well below
+// the API limit so a single request stays within the token budget.
+const maxEmbeddingBatchSize = 100
+
 func NewClient(apiKey string) *Client {
 	return &Client{apiKey: apiKey}
 }
@@ -70,9 +84,38 @@ func (c *Client) GenerateEmbedding(ctx context.Context, text string) ([]float32,
 	return generateEmbeddingWithOpenAI(ctx, c.apiKey, text)
 }
 
+// GenerateEmbeddings embeds several texts, batching them into as few API
+// calls as possible. The result is in the same order as texts.
+func (c *Client) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	embeddings := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += maxEmbeddingBatchSize {
+		end := start + maxEmbeddingBatchSize
+		if end > len(texts) {
+			end = len(texts)
+		}
+
+		batch, err := callOpenAIEmbeddings(ctx, c.apiKey, EmbeddingModel, texts[start:end])
+		if err != nil {
+			return nil, err
+		}
+		embeddings = append(embeddings, batch...)
+	}
+	return embeddings, nil
+}
+
+// ExtractRecipeText transcribes the recipe in a photo into plain text. It
+// returns an empty string when the image holds no readable recipe.
+func (c *Client) ExtractRecipeText(ctx context.Context, image []byte, contentType string) (string, error) {
+	content, err := callOpenAIVision(ctx, c.apiKey, VisionModel, ai.ImageTextPrompt, image, contentType, 2000)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(content), nil
+}
+
 // Complete sends a completion request to OpenAI for general text completion
 func (c *Client) Complete(ctx context.Context, prompt string) (string, error) {
-	content, err := callOpenAIChat(ctx, c.apiKey, "gpt-4o-mini", "", prompt, false, 100, 0.3)
+	content, err := callOpenAIChat(ctx, c.apiKey, CompletionModel, "", prompt, false, 100, 0.3)
 	if err != nil {
 		return "", err
 	}
@@ -89,7 +132,7 @@ func generateRecipeWithOpenAI(ctx context.Context, apiKey, description, transcri
 		metrics.AIGenerationDuration.Record(ctx, duration, metric.WithAttributes(attribute.String("provider", "openai")))
 	}()
 
-	systemPrompt := ai.BuildRecipePrompt(platform)
+	systemPrompt := ai.RecipePrompt(ctx, platform)
 	userContent := description
 	if transcript != "" {
 		userContent += "\n\nVideo Transcript:\n" + transcript
@@ -163,7 +206,7 @@ func generateRecipeWithOpenAI(ctx context.Context, apiKey, description, transcri
 }
 
 func generateEmbeddingWithOpenAI(ctx context.Context, apiKey, text string) ([]float32, error) {
-	embedding, err := callOpenAIEmbedding(ctx, apiKey, "text-embedding-ada-002", text)
+	embedding, err := callOpenAIEmbedding(ctx, apiKey, EmbeddingModel, text)
 	if err != nil {
 		return nil, err
 	}
@@ -3,6 +3,7 @@ package openai
 import (
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"time"
@@ -29,6 +30,29 @@ type chatMessage struct {
 	Content string `json:"content"`
 }
 
+// visionMessage is a chat message whose content mixes text and images.
+type visionMessage struct {
+	Role    string          `json:"role"`
+	Content []visionContent `json:"content"`
+}
+
+type visionContent struct {
+	Type     string          `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	ImageURL *visionImageURL `json:"image_url,omitempty"`
+}
+
+type visionImageURL struct {
+	URL string `json:"url"`
+}
+
+type visionRequest struct {
+	Model       string          `json:"model"`
+	Messages    []visionMessage `json:"messages"`
+	MaxTokens   int             `json:"max_tokens,omitempty"`
+	Temperature float64         `json:"temperature,omitempty"`
+}
+
 type responseFormat struct {
 	Type string `json:"type"`
 }
@@ -42,12 +66,13 @@ type chatResponse struct {
 }
 
 type embeddingRequest struct {
-	Model string `json:"model"`
-	Input string `json:"input"`
+	Model string      `json:"model"`
+	Input interface{} `json:"input"` // string or []string
 }
 
 type embeddingResponse struct {
 	Data []struct {
+		Index     int       `json:"index"`
 		Embedding []float64 `json:"embedding"`
 	} `json:"data"`
 }
@@ -74,6 +99,36 @@ func callOpenAIChat(ctx context.Context, apiKey, model, systemPrompt, userConten
 		req.ResponseFormat = &responseFormat{Type: "json_object"}
 	}
 
+	return sendOpenAIChat(ctx, apiKey, req)
+}
+
+// callOpenAIVision sends a prompt with an inline image to a vision model.
+func callOpenAIVision(ctx context.Context, apiKey, model, prompt string, image []byte, contentType string, maxTokens int) (string, error) {
+	startTime := time.Now()
+	defer func() {
+		duration := time.Since(startTime).Seconds()
+		attrs := []attribute.KeyValue{attribute.String("provider", "openai")}
+		metrics.ExternalAPIDuration.Record(ctx, duration, metric.WithAttributes(attrs...))
+		metrics.ExternalAPICallsTotal.Add(ctx, 1, metric.WithAttributes(attrs...))
+	}()
+
+	dataURL := "data:" + contentType + ";base64," + base64.StdEncoding.EncodeToString(image)
+	req := visionRequest{
+		Model: model,
+		Messages: []visionMessage{{
+			Role: "user",
+			Content: []visionContent{
+				{Type: "text", Text: prompt},
+				{Type: "image_url", ImageURL: &visionImageURL{URL: dataURL}},
+			},
+		}},
+		MaxTokens: maxTokens,
+	}
+
+	return sendOpenAIChat(ctx, apiKey, req)
+}
+
+func sendOpenAIChat(ctx context.Context, apiKey string, req interface{}) (string, error) {
 	body, _ := json.Marshal(req)
 	httpReq, err := http.NewRequestWithContext(httpclient.WithProvider(ctx, "OpenAI"), "POST", "https://api.openai.com/v1/chat/completions", bytes.NewReader(body))
 	if err != nil {
@@ -110,6 +165,51 @@ func callOpenAIChat(ctx context.Context, apiKey, model, systemPrompt, userConten
 }
 
 func callOpenAIEmbedding(ctx context.Context, apiKey, model, text string) ([]float32, error) {
+	embResp, err := requestOpenAIEmbeddings(ctx, apiKey, model, text)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embResp.Data) == 0 {
+		return nil, ErrNoEmbedding
+	}
+
+	return toFloat32(embResp.Data[0].Embedding), nil
+}
+
+// callOpenAIEmbeddings embeds a batch of texts in a single request. The API
+// tags each embedding with the index of its input; results are returned in
+// input order.
+func callOpenAIEmbeddings(ctx context.Context, apiKey, model string, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	embResp, err := requestOpenAIEmbeddings(ctx, apiKey, model, texts)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("%w: got %d embeddings for %d inputs", ErrNoEmbedding, len(embResp.Data), len(texts))
+	}
+
+	embeddings := make([][]float32, len(texts))
+	for _, d := range embResp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("OpenAI returned embedding for unknown input index %d", d.Index)
+		}
+		embeddings[d.Index] = toFloat32(d.Embedding)
+	}
+	for i, e := range embeddings {
+		if len(e) == 0 {
+			return nil, fmt.Errorf("%w for input %d", ErrNoEmbedding, i)
+		}
+	}
+	return embeddings, nil
+}
+
+func requestOpenAIEmbeddings(ctx context.Context, apiKey, model string, input interface{}) (*embeddingResponse, error) {
 	startTime := time.Now()
 	defer func() {
 		duration := time.Since(startTime).Seconds()
@@ -120,7 +220,7 @@ func callOpenAIEmbedding(ctx context.Context, apiKey, model, text string) ([]flo
 
 	req := embeddingRequest{
 		Model: model,
-		Input: text,
+		Input: input,
 	}
 
 	body, _ := json.Marshal(req)
@@ -150,14 +250,13 @@ func callOpenAIEmbedding(ctx context.Context, apiKey, model, text string) ([]flo
 	if err := json.Unmarshal(respBody, &embResp); err != nil {
 		return nil, err
 	}
+	return &embResp, nil
+}
 
-	if len(embResp.Data) == 0 {
-		return nil, ErrNoEmbedding
-	}
-
-	embedding := make([]float32, len(embResp.Data[0].Embedding))
-	for i, v := range embResp.Data[0].Embedding {
-		embedding[i] = float32(v)
+func toFloat32(values []float64) []float32 {
+	out := make([]float32, len(values))
+	for i, v := range values {
+		out[i] = float32(v)
 	}
-	return embedding, nil
+	return out
 }
@@ -0,0 +1,136 @@
+// Package ratelimit implements token bucket rate limits shared by every API
+// instance through Redis.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Limit is a token bucket: Burst requests may be made at once, and the
+// bucket refills at Rate requests per Period.
+type Limit struct {
+	Rate   int
+	Period time.Duration
+	Burst  int
+}
+
+// PerMinute returns a limit of rate requests per minute with the given burst.
+func PerMinute(rate, burst int) Limit {
+	return Limit{Rate: rate, Period: time.Minute, Burst: burst}
+}
+
+// perMillisecond is the refill rate in tokens per millisecond.
+func (l Limit) perMillisecond() float64 {
+	return float64(l.Rate) / float64(l.Period.Milliseconds())
+}
+
+// Result is the outcome of taking a token from a bucket.
+type Result struct {
+	Allowed bool
+	// Limit is the bucket size, reported to clients as the request limit
+	Limit     int
+	Remaining int
+	// RetryAfter is how long until a token is available; zero when the
+	// request was allowed
+	RetryAfter time.Duration
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+}
+
+// takeScript refills the bucket for the time since it was last used, then
+// takes a token if one is available. Tokens are returned as a string because
+// Redis truncates Lua numbers to integers.
+var takeScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+local ttl = tonumber(ARGV[4])
+
+local state = redis.call('HMGET', KEYS[1], 'tokens', 'ts')
+local tokens = tonumber(state[1])
+local ts = tonumber(state[2])
+if tokens == nil or ts == nil then
+	tokens = burst
+	ts = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - ts) * rate)
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'ts', now)
+redis.call('PEXPIRE', KEYS[1], ttl)
+return {allowed, tostring(tokens)}
+`)
+
+// Limiter keeps token buckets in Redis, so limits hold across API
+// instances.
+type Limiter struct {
+	client *redis.Client
+	prefix string
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter with the given Redis client.
+func NewLimiter(client *redis.Client) *Limiter {
+	return &Limiter{
+		client: client,
+		prefix: "ratelimit:",
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token from the bucket named key.
+func (l *Limiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	if limit.Rate <= 0 || limit.Burst <= 0 || limit.Period <= 0 {
+		return Result{}, fmt.Errorf("invalid rate limit %+v", limit)
+	}
+
+	// An idle bucket is full again after refilling Burst tokens
+	ttl := time.Duration(float64(limit.Burst)/limit.perMillisecond()) * time.Millisecond
+	reply, err := takeScript.Run(ctx, l.client, []string{l.prefix + key},
+		limit.perMillisecond(), limit.Burst, l.now().UnixMilli(), ttl.Milliseconds()+1000,
+	).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("rate limit script failed: %w", err)
+	}
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected rate limit reply %v", reply)
+	}
+
+	allowed, _ := reply[0].(int64)
+	tokensStr, _ := reply[1].(string)
+	tokens, err := strconv.ParseFloat(tokensStr, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("invalid token count %q: %w", tokensStr, err)
+	}
+	return newResult(limit, allowed == 1, tokens), nil
+}
+
+// newResult describes a bucket left with tokens tokens after a request.
+func newResult(limit Limit, allowed bool, tokens float64) Result {
+	rate := limit.perMillisecond()
+	result := Result{
+		Allowed:    allowed,
+		Limit:      limit.Burst,
+		Remaining:  int(math.Floor(tokens)),
+		ResetAfter: millis((float64(limit.Burst) - tokens) / rate),
+	}
+	if !allowed {
+		result.RetryAfter = millis((1 - tokens) / rate)
+	}
+	return result
+}
+
+func millis(ms float64) time.Duration {
+	return time.Duration(math.Ceil(ms)) * time.Millisecond
+}
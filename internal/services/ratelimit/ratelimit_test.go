@@ -0,0 +1,44 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNewResult(t *testing.T) {
+	limit := PerMinute(60, 10)
+
+	tests := []struct {
+		name    string
+		allowed bool
+		tokens  float64
+		want    Result
+	}{
+		{
+			name:    "full bucket",
+			allowed: true,
+			tokens:  9,
+			want:    Result{Allowed: true, Limit: 10, Remaining: 9, ResetAfter: time.Second},
+		},
+		{
+			name:    "partial token",
+			allowed: true,
+			tokens:  2.5,
+			want:    Result{Allowed: true, Limit: 10, Remaining: 2, ResetAfter: 7500 * time.Millisecond},
+		},
+		{
+			name:    "empty bucket",
+			allowed: false,
+			tokens:  0.25,
+			want:    Result{Allowed: false, Limit: 10, Remaining: 0, RetryAfter: 750 * time.Millisecond, ResetAfter: 9750 * time.Millisecond},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := newResult(limit, tt.allowed, tt.tokens); got != tt.want {
+				t.Errorf("expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+}
@@ -39,7 +39,7 @@ func (p *CerebrasProvider) GenerateRecipe(ctx context.Context, description, tran
 		metrics.ExternalAPICallsTotal.Add(ctx, 1, metric.WithAttributes(attrs...))
 	}()
 
-	systemPrompt := ai.BuildRecipePrompt(platform)
+	systemPrompt := ai.RecipePrompt(ctx, platform)
 
 	userContent := description
 	if transcript != "" {
@@ -37,7 +37,7 @@ func (p *OpenAIProvider) GenerateRecipe(ctx context.Context, description, transc
 		metrics.ExternalAPICallsTotal.Add(ctx, 1, metric.WithAttributes(attrs...))
 	}()
 
-	systemPrompt := ai.BuildRecipePrompt(platform)
+	systemPrompt := ai.RecipePrompt(ctx, platform)
 
 	userContent := description
 	if transcript != "" {
@@ -0,0 +1,266 @@
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+// richText is a previously generated instruction_rich value, carried over
+// when an instruction's text is unchanged by an edit.
+type richText struct {
+	text    pgtype.Text
+	version pgtype.Int4
+}
+
+var ingredientPlaceholder = regexp.MustCompile(`\{\{ingredient:([0-9a-fA-F-]{36})\}\}`)
+
+// SaveContent replaces a recipe's parts, ingredients, instructions and
+// instruction-ingredient links with those in snap. Rich instruction text is
+// kept for steps whose text did not change, with its ingredient placeholders
+// pointed at the recreated ingredients; it reports whether any step is left
+// without rich text and needs regenerating.
+func SaveContent(ctx context.Context, q *generated.Queries, recipeID pgtype.UUID, snap Snapshot) (bool, error) {
+	w := contentWriter{
+		q:             q,
+		recipeID:      recipeID,
+		servings:      snap.OriginalServingSize,
+		rich:          make(map[string]richText),
+		oldKeys:       make(map[string]string),
+		ingredientIDs: make(map[string]pgtype.UUID),
+	}
+	if err := w.loadExisting(ctx); err != nil {
+		return false, err
+	}
+
+	// Instruction deletes cascade to instruction_ingredients
+	if err := q.DeleteInstructionsByRecipe(ctx, recipeID); err != nil {
+		return false, fmt.Errorf("failed to delete instructions: %w", err)
+	}
+	if err := q.DeleteIngredientsByRecipe(ctx, recipeID); err != nil {
+		return false, fmt.Errorf("failed to delete ingredients: %w", err)
+	}
+	if err := q.DeleteRecipeParts(ctx, recipeID); err != nil {
+		return false, fmt.Errorf("failed to delete parts: %w", err)
+	}
+
+	// Ingredients first, so rich text can refer to any of them
+	partIDs := make([]pgtype.UUID, len(snap.Parts))
+	if err := w.saveIngredients(ctx, pgtype.UUID{}, "", snap.Ingredients); err != nil {
+		return false, err
+	}
+	for i, part := range snap.Parts {
+		saved, err := q.CreateRecipePart(ctx, generated.CreateRecipePartParams{
+			RecipeID:     recipeID,
+			Name:         strings.TrimSpace(part.Name),
+			Description:  pgtype.Text{String: part.Description, Valid: part.Description != ""},
+			DisplayOrder: int32(i),
+			IsOptional:   part.IsOptional,
+			PrepTime:     int4Value(part.PrepTime),
+			CookingTime:  int4Value(part.CookingTime),
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to save part %q: %w", part.Name, err)
+		}
+		partIDs[i] = saved.ID
+		if err := w.saveIngredients(ctx, saved.ID, part.Name, part.Ingredients); err != nil {
+			return false, err
+		}
+	}
+
+	if err := w.saveInstructions(ctx, pgtype.UUID{}, "", snap.Instructions); err != nil {
+		return false, err
+	}
+	for i, part := range snap.Parts {
+		if err := w.saveInstructions(ctx, partIDs[i], part.Name, part.Instructions); err != nil {
+			return false, err
+		}
+	}
+
+	if err := q.RefreshRecipeIngredientNames(ctx, recipeID); err != nil {
+		return false, fmt.Errorf("failed to refresh ingredient names: %w", err)
+	}
+	return w.missingRich, nil
+}
+
+type contentWriter struct {
+	q        *generated.Queries
+	recipeID pgtype.UUID
+	servings *int32
+	// rich maps instruction text to its current rich text
+	rich map[string]richText
+	// oldKeys maps current ingredient IDs to their ingredientKey
+	oldKeys map[string]string
+	// ingredientIDs maps an ingredientKey to the recreated ingredient
+	ingredientIDs map[string]pgtype.UUID
+	missingRich   bool
+}
+
+// ingredientKey identifies an ingredient by part and name, which is what
+// survives an edit: the rows themselves are recreated with new IDs.
+func ingredientKey(partName, name string) string {
+	return normalizeName(partName) + "\x00" + normalizeName(name)
+}
+
+// loadExisting records the rich text and ingredient keys of the content
+// about to be replaced.
+func (w *contentWriter) loadExisting(ctx context.Context) error {
+	instructions, err := w.q.GetInstructionsByRecipe(ctx, w.recipeID)
+	if err != nil {
+		return fmt.Errorf("failed to get instructions: %w", err)
+	}
+	for _, inst := range instructions {
+		if inst.InstructionRich.Valid {
+			w.rich[inst.Instruction] = richText{text: inst.InstructionRich, version: inst.InstructionRichVersion}
+		}
+	}
+
+	parts, err := w.q.GetRecipeParts(ctx, w.recipeID)
+	if err != nil {
+		return fmt.Errorf("failed to get parts: %w", err)
+	}
+	partNames := make(map[pgtype.UUID]string, len(parts))
+	for _, p := range parts {
+		partNames[p.ID] = p.Name
+	}
+
+	ingredients, err := w.q.GetIngredientsByRecipe(ctx, w.recipeID)
+	if err != nil {
+		return fmt.Errorf("failed to get ingredients: %w", err)
+	}
+	for _, ing := range ingredients {
+		w.oldKeys[uuid.UUID(ing.ID.Bytes).String()] = ingredientKey(partNames[ing.PartID], ing.Name)
+	}
+	return nil
+}
+
+// saveIngredients writes the ingredients of one part (or of no part).
+func (w *contentWriter) saveIngredients(ctx context.Context, partID pgtype.UUID, partName string, ingredients []Ingredient) error {
+	for _, ing := range ingredients {
+		saved, err := w.q.CreateIngredient(ctx, generated.CreateIngredientParams{
+			RecipeID:         w.recipeID,
+			PartID:           partID,
+			Quantity:         pgtype.Text{String: perServingQuantity(ing.Quantity, w.servings), Valid: ing.Quantity != ""},
+			TotalQuantity:    pgtype.Text{String: ing.Quantity, Valid: ing.Quantity != ""},
+			Unit:             pgtype.Text{String: ing.Unit, Valid: ing.Unit != ""},
+			OriginalQuantity: pgtype.Text{String: ing.OriginalQuantity, Valid: ing.OriginalQuantity != ""},
+			OriginalUnit:     pgtype.Text{String: ing.OriginalUnit, Valid: ing.OriginalUnit != ""},
+			Name:             strings.TrimSpace(ing.Name),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save ingredient %q: %w", ing.Name, err)
+		}
+		key := ingredientKey(partName, ing.Name)
+		if _, ok := w.ingredientIDs[key]; !ok {
+			w.ingredientIDs[key] = saved.ID
+		}
+	}
+	return nil
+}
+
+// saveInstructions writes the steps of one part (or of no part) and links
+// each step to the ingredients it uses.
+func (w *contentWriter) saveInstructions(ctx context.Context, partID pgtype.UUID, partName string, instructions []Instruction) error {
+	for i, inst := range instructions {
+		var timerData []byte
+		if len(inst.Timers) > 0 {
+			data, err := json.Marshal(inst.Timers)
+			if err != nil {
+				return fmt.Errorf("failed to marshal timers: %w", err)
+			}
+			timerData = data
+		}
+
+		text := strings.TrimSpace(inst.Instruction)
+		rich, ok := w.carryOverRich(text)
+		if !ok {
+			w.missingRich = true
+		}
+
+		saved, err := w.q.CreateInstruction(ctx, generated.CreateInstructionParams{
+			RecipeID:               w.recipeID,
+			PartID:                 partID,
+			StepNumber:             int32(i + 1),
+			Instruction:            text,
+			TimerData:              timerData,
+			InstructionRich:        rich.text,
+			InstructionRichVersion: rich.version,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save step %d: %w", i+1, err)
+		}
+
+		linked := make(map[string]bool, len(inst.Ingredients))
+		for _, used := range inst.Ingredients {
+			key := ingredientKey(partName, used.Name)
+			if linked[key] {
+				continue
+			}
+			linked[key] = true
+			_, err := w.q.CreateInstructionIngredient(ctx, generated.CreateInstructionIngredientParams{
+				InstructionID: saved.ID,
+				IngredientID:  w.ingredientIDs[key],
+				StepQuantity:  pgtype.Text{String: used.Quantity, Valid: used.Quantity != ""},
+			})
+			if err != nil {
+				return fmt.Errorf("failed to link step %d to %q: %w", i+1, used.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// carryOverRich returns the existing rich text for an instruction with its
+// ingredient placeholders rewritten to the recreated ingredient IDs. It
+// reports false if there is none, or if a placeholder refers to an
+// ingredient that no longer exists.
+func (w *contentWriter) carryOverRich(text string) (richText, bool) {
+	prev, ok := w.rich[text]
+	if !ok {
+		return richText{}, false
+	}
+
+	resolved := true
+	rewritten := ingredientPlaceholder.ReplaceAllStringFunc(prev.text.String, func(match string) string {
+		oldID := ingredientPlaceholder.FindStringSubmatch(match)[1]
+		newID, ok := w.ingredientIDs[w.oldKeys[strings.ToLower(oldID)]]
+		if !ok {
+			resolved = false
+			return match
+		}
+		return "{{ingredient:" + uuid.UUID(newID.Bytes).String() + "}}"
+	})
+	if !resolved {
+		return richText{}, false
+	}
+	prev.text.String = rewritten
+	return prev, true
+}
+
+// perServingQuantity divides a numeric total quantity by the serving count,
+// the same way imported recipes are stored. Non-numeric quantities ("a
+// pinch") are kept as-is.
+func perServingQuantity(total string, servings *int32) string {
+	if servings == nil || *servings <= 0 {
+		return total
+	}
+	n, err := strconv.ParseFloat(total, 64)
+	if err != nil {
+		return total
+	}
+	return strconv.FormatFloat(n/float64(*servings), 'f', -1, 64)
+}
+
+func int4Value(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
@@ -0,0 +1,63 @@
+package revision
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+func TestPerServingQuantity(t *testing.T) {
+	tests := []struct {
+		total    string
+		servings *int32
+		expected string
+	}{
+		{"4", int32Ptr(2), "2"},
+		{"1", int32Ptr(4), "0.25"},
+		{"a pinch", int32Ptr(4), "a pinch"},
+		{"3", nil, "3"},
+		{"3", int32Ptr(0), "3"},
+	}
+
+	for _, tt := range tests {
+		if got := perServingQuantity(tt.total, tt.servings); got != tt.expected {
+			t.Errorf("perServingQuantity(%q) = %q, want %q", tt.total, got, tt.expected)
+		}
+	}
+}
+
+func TestContentWriter_CarryOverRich(t *testing.T) {
+	oldFlour := "11111111-1111-1111-1111-111111111111"
+	oldSalt := "22222222-2222-2222-2222-222222222222"
+	newFlour := "33333333-3333-3333-3333-333333333333"
+
+	w := contentWriter{
+		rich: map[string]richText{
+			"Sift the flour.": {text: pgtype.Text{String: "Sift the {{ingredient:" + oldFlour + "}}.", Valid: true}},
+			"Add the salt.":   {text: pgtype.Text{String: "Add the {{ingredient:" + oldSalt + "}}.", Valid: true}},
+		},
+		oldKeys: map[string]string{
+			oldFlour: ingredientKey("Dough", "flour"),
+			oldSalt:  ingredientKey("Dough", "salt"),
+		},
+		ingredientIDs: map[string]pgtype.UUID{
+			ingredientKey("dough", "Flour"): pgtype.UUID{Bytes: uuid.MustParse(newFlour), Valid: true},
+		},
+	}
+
+	rich, ok := w.carryOverRich("Sift the flour.")
+	if !ok {
+		t.Fatal("expected rich text to be carried over")
+	}
+	if rich.text.String != "Sift the {{ingredient:"+newFlour+"}}." {
+		t.Errorf("expected placeholder to point at the new ingredient, got %q", rich.text.String)
+	}
+
+	if _, ok := w.carryOverRich("Add the salt."); ok {
+		t.Error("expected rich text referring to a removed ingredient to be dropped")
+	}
+	if _, ok := w.carryOverRich("Knead."); ok {
+		t.Error("expected no rich text for a new instruction")
+	}
+}
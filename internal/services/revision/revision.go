@@ -0,0 +1,48 @@
+// Package revision keeps the history of a recipe's content: snapshots of
+// what can be edited, the changes between them, and the writes that replace
+// a recipe's content with a snapshot.
+package revision
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+// Revision actions
+const (
+	ActionOriginal   = "original"
+	ActionEdit       = "edit"
+	ActionRestore    = "restore"
+	ActionRegenerate = "regenerate"
+)
+
+// RecordOriginal stores the recipe as imported, attributed to its creator,
+// if it has no revisions yet.
+func RecordOriginal(ctx context.Context, q *generated.Queries, recipe generated.Recipe, current Snapshot) error {
+	count, err := q.CountRecipeRevisions(ctx, recipe.ID)
+	if err != nil {
+		return fmt.Errorf("failed to count revisions: %w", err)
+	}
+	if count > 0 {
+		return nil
+	}
+
+	snapshot, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+	_, err = q.CreateRecipeRevision(ctx, generated.CreateRecipeRevisionParams{
+		RecipeID: recipe.ID,
+		AuthorID: recipe.CreatedBy,
+		Action:   ActionOriginal,
+		Snapshot: snapshot,
+		Diff:     []byte("[]"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create original revision: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,389 @@
+package revision
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+// Snapshot is the editable content of a recipe. Every revision stores a
+// full snapshot so any revision can be restored as-is. Recipes split into
+// parts keep their ingredients and instructions under Parts; the top-level
+// lists hold those that belong to no part.
+type Snapshot struct {
+	RecipeName          string        `json:"recipe_name"`
+	Description         string        `json:"description,omitempty"`
+	PrepTime            *int32        `json:"prep_time,omitempty"`
+	CookingTime         *int32        `json:"cooking_time,omitempty"`
+	OriginalServingSize *int32        `json:"original_serving_size,omitempty"`
+	DifficultyRating    *int16        `json:"difficulty_rating,omitempty"`
+	Parts               []Part        `json:"parts,omitempty"`
+	Ingredients         []Ingredient  `json:"ingredients,omitempty"`
+	Instructions        []Instruction `json:"instructions,omitempty"`
+}
+
+type Part struct {
+	Name         string        `json:"name"`
+	Description  string        `json:"description,omitempty"`
+	IsOptional   bool          `json:"is_optional,omitempty"`
+	PrepTime     *int32        `json:"prep_time,omitempty"`
+	CookingTime  *int32        `json:"cooking_time,omitempty"`
+	Ingredients  []Ingredient  `json:"ingredients,omitempty"`
+	Instructions []Instruction `json:"instructions,omitempty"`
+}
+
+// Ingredient is an ingredient for the whole recipe; Quantity is the total
+// quantity; the per-serving quantity is derived from it on save.
+type Ingredient struct {
+	Name             string `json:"name"`
+	Quantity         string `json:"quantity,omitempty"`
+	Unit             string `json:"unit,omitempty"`
+	OriginalQuantity string `json:"original_quantity,omitempty"`
+	OriginalUnit     string `json:"original_unit,omitempty"`
+}
+
+// Instruction is a single step. Ingredients link the step to the recipe
+// ingredients it uses, by name, within the same part.
+type Instruction struct {
+	Instruction string           `json:"instruction"`
+	Timers      []Timer          `json:"timers,omitempty"`
+	Ingredients []StepIngredient `json:"ingredients,omitempty"`
+}
+
+type StepIngredient struct {
+	Name     string `json:"name"`
+	Quantity string `json:"quantity,omitempty"`
+}
+
+type Timer struct {
+	DurationSeconds int    `json:"duration_seconds"`
+	DurationText    string `json:"duration_text"`
+	Label           string `json:"label"`
+	Type            string `json:"type"`
+	Category        string `json:"category"`
+}
+
+// Change is one changed top-level field of a recipe snapshot.
+type Change struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old,omitempty"`
+	New   json.RawMessage `json:"new,omitempty"`
+}
+
+// Validate checks a snapshot is complete enough to save.
+func (snap Snapshot) Validate() error {
+	if snap.RecipeName == "" {
+		return fmt.Errorf("recipe_name is required")
+	}
+	if snap.DifficultyRating != nil && (*snap.DifficultyRating < 1 || *snap.DifficultyRating > 5) {
+		return fmt.Errorf("difficulty_rating must be between 1 and 5")
+	}
+	for _, v := range []*int32{snap.PrepTime, snap.CookingTime, snap.OriginalServingSize} {
+		if v != nil && *v < 0 {
+			return fmt.Errorf("times and servings must not be negative")
+		}
+	}
+	if err := validateSteps("recipe", snap.Ingredients, snap.Instructions); err != nil {
+		return err
+	}
+	for i, part := range snap.Parts {
+		if strings.TrimSpace(part.Name) == "" {
+			return fmt.Errorf("parts[%d].name is required", i)
+		}
+		if err := validateSteps(fmt.Sprintf("part %q", part.Name), part.Ingredients, part.Instructions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateSteps checks ingredient and instruction text and that every step
+// ingredient refers to an ingredient in the same scope.
+func validateSteps(scope string, ingredients []Ingredient, instructions []Instruction) error {
+	names := make(map[string]bool, len(ingredients))
+	for i, ing := range ingredients {
+		if strings.TrimSpace(ing.Name) == "" {
+			return fmt.Errorf("%s: ingredients[%d].name is required", scope, i)
+		}
+		names[normalizeName(ing.Name)] = true
+	}
+	for i, inst := range instructions {
+		if strings.TrimSpace(inst.Instruction) == "" {
+			return fmt.Errorf("%s: instructions[%d].instruction is required", scope, i)
+		}
+		for _, used := range inst.Ingredients {
+			if !names[normalizeName(used.Name)] {
+				return fmt.Errorf("%s: step %d uses unknown ingredient %q", scope, i+1, used.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// Diff lists the top-level fields that differ between two snapshots, in
+// field name order.
+func Diff(prev, next Snapshot) ([]Change, error) {
+	prevFields, err := snapshotFields(prev)
+	if err != nil {
+		return nil, err
+	}
+	nextFields, err := snapshotFields(next)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]bool, len(prevFields)+len(nextFields))
+	for k := range prevFields {
+		keys[k] = true
+	}
+	for k := range nextFields {
+		keys[k] = true
+	}
+	fields := make([]string, 0, len(keys))
+	for k := range keys {
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+
+	changes := []Change{}
+	for _, field := range fields {
+		if bytes.Equal(prevFields[field], nextFields[field]) {
+			continue
+		}
+		changes = append(changes, Change{Field: field, Old: prevFields[field], New: nextFields[field]})
+	}
+	return changes, nil
+}
+
+func snapshotFields(snap Snapshot) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+	return fields, nil
+}
+
+// ContentChanged reports whether a diff touches ingredients or instructions,
+// which are stored as rows rather than recipe columns. Servings count too:
+// per-serving ingredient quantities are derived from them.
+func ContentChanged(changes []Change) bool {
+	for _, c := range changes {
+		switch c.Field {
+		case "parts", "ingredients", "instructions", "original_serving_size":
+			return true
+		}
+	}
+	return false
+}
+
+// Load reads the editable content of a recipe.
+func Load(ctx context.Context, q *generated.Queries, recipe generated.Recipe) (Snapshot, error) {
+	parts, err := q.GetRecipeParts(ctx, recipe.ID)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to get parts: %w", err)
+	}
+	ingredients, err := q.GetIngredientsByRecipe(ctx, recipe.ID)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to get ingredients: %w", err)
+	}
+	instructions, err := q.GetInstructionsByRecipe(ctx, recipe.ID)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to get instructions: %w", err)
+	}
+	links, err := q.GetInstructionIngredientsByRecipe(ctx, recipe.ID)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to get instruction ingredients: %w", err)
+	}
+	return fromRows(recipe, parts, ingredients, instructions, links), nil
+}
+
+// fromRows assembles a snapshot from the stored rows of a recipe. Step
+// ingredient links to an ingredient outside the step's part are dropped, as
+// for generated recipes: older imports linked steps across parts, and the
+// snapshot could otherwise never be saved again.
+func fromRows(recipe generated.Recipe, parts []generated.RecipePart, ingredients []generated.RecipeIngredient, instructions []generated.RecipeInstruction, links []generated.InstructionIngredient) Snapshot {
+	snap := Snapshot{
+		RecipeName:          recipe.RecipeName,
+		Description:         recipe.Description.String,
+		PrepTime:            int4Ptr(recipe.PrepTime),
+		CookingTime:         int4Ptr(recipe.CookingTime),
+		OriginalServingSize: int4Ptr(recipe.OriginalServingSize),
+	}
+	if recipe.DifficultyRating.Valid {
+		snap.DifficultyRating = &recipe.DifficultyRating.Int16
+	}
+
+	byID := make(map[pgtype.UUID]generated.RecipeIngredient, len(ingredients))
+	for _, ing := range ingredients {
+		byID[ing.ID] = ing
+	}
+	instructionParts := make(map[pgtype.UUID]pgtype.UUID, len(instructions))
+	for _, inst := range instructions {
+		instructionParts[inst.ID] = inst.PartID
+	}
+	stepIngredients := make(map[pgtype.UUID][]StepIngredient)
+	for _, link := range links {
+		ing, ok := byID[link.IngredientID]
+		if !ok || ing.PartID != instructionParts[link.InstructionID] {
+			continue
+		}
+		stepIngredients[link.InstructionID] = append(stepIngredients[link.InstructionID], StepIngredient{
+			Name:     ing.Name,
+			Quantity: link.StepQuantity.String,
+		})
+	}
+
+	partIndex := make(map[pgtype.UUID]int, len(parts))
+	for i, p := range parts {
+		partIndex[p.ID] = i
+		snap.Parts = append(snap.Parts, Part{
+			Name:        p.Name,
+			Description: p.Description.String,
+			IsOptional:  p.IsOptional,
+			PrepTime:    int4Ptr(p.PrepTime),
+			CookingTime: int4Ptr(p.CookingTime),
+		})
+	}
+
+	for _, ing := range ingredients {
+		item := Ingredient{
+			Name:             ing.Name,
+			Quantity:         ing.TotalQuantity.String,
+			Unit:             ing.Unit.String,
+			OriginalQuantity: ing.OriginalQuantity.String,
+			OriginalUnit:     ing.OriginalUnit.String,
+		}
+		if i, ok := partIndex[ing.PartID]; ok && ing.PartID.Valid {
+			snap.Parts[i].Ingredients = append(snap.Parts[i].Ingredients, item)
+		} else {
+			snap.Ingredients = append(snap.Ingredients, item)
+		}
+	}
+
+	for _, inst := range instructions {
+		item := Instruction{
+			Instruction: inst.Instruction,
+			Ingredients: stepIngredients[inst.ID],
+		}
+		if len(inst.TimerData) > 0 {
+			if err := json.Unmarshal(inst.TimerData, &item.Timers); err != nil {
+				item.Timers = nil
+			}
+		}
+		if i, ok := partIndex[inst.PartID]; ok && inst.PartID.Valid {
+			snap.Parts[i].Instructions = append(snap.Parts[i].Instructions, item)
+		} else {
+			snap.Instructions = append(snap.Instructions, item)
+		}
+	}
+
+	return snap
+}
+
+// FromGenerated converts a generated recipe into a snapshot, so a draft can
+// be compared with the saved recipe or saved in its place. Step ingredients
+// that name no ingredient in the step's part are dropped, as they could not
+// be linked.
+func FromGenerated(r recipe.Recipe) Snapshot {
+	snap := Snapshot{
+		RecipeName:          r.RecipeName,
+		Description:         r.Description,
+		PrepTime:            intPtrToInt32(r.PrepTime),
+		CookingTime:         intPtrToInt32(r.CookingTime),
+		OriginalServingSize: intPtrToInt32(r.OriginalServings),
+	}
+	if r.DifficultyRating != nil {
+		rating := int16(*r.DifficultyRating)
+		snap.DifficultyRating = &rating
+	}
+
+	if !r.HasParts() {
+		snap.Ingredients = generatedIngredients(r.Ingredients)
+		snap.Instructions = generatedInstructions(r.Instructions, snap.Ingredients)
+		return snap
+	}
+
+	for _, part := range r.Parts {
+		ingredients := generatedIngredients(part.Ingredients)
+		snap.Parts = append(snap.Parts, Part{
+			Name:         part.Name,
+			Description:  part.Description,
+			IsOptional:   part.IsOptional,
+			PrepTime:     intPtrToInt32(part.PrepTime),
+			CookingTime:  intPtrToInt32(part.CookingTime),
+			Ingredients:  ingredients,
+			Instructions: generatedInstructions(part.Instructions, ingredients),
+		})
+	}
+	return snap
+}
+
+func generatedIngredients(ings []recipe.Ingredient) []Ingredient {
+	var items []Ingredient
+	for _, ing := range ings {
+		items = append(items, Ingredient{
+			Name:             ing.Name,
+			Quantity:         string(ing.Quantity),
+			Unit:             ing.Unit,
+			OriginalQuantity: string(ing.OriginalQuantity),
+			OriginalUnit:     ing.OriginalUnit,
+		})
+	}
+	return items
+}
+
+func generatedInstructions(insts []recipe.Instruction, ingredients []Ingredient) []Instruction {
+	names := make(map[string]bool, len(ingredients))
+	for _, ing := range ingredients {
+		names[normalizeName(ing.Name)] = true
+	}
+
+	var items []Instruction
+	for _, inst := range insts {
+		item := Instruction{Instruction: inst.Instruction}
+		for _, t := range inst.TimerData {
+			item.Timers = append(item.Timers, Timer(t))
+		}
+		for _, used := range inst.IngredientsUsed {
+			if !names[normalizeName(used.IngredientName)] {
+				continue
+			}
+			item.Ingredients = append(item.Ingredients, StepIngredient{
+				Name:     used.IngredientName,
+				Quantity: used.QuantityUsed,
+			})
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+func int4Ptr(v pgtype.Int4) *int32 {
+	if !v.Valid {
+		return nil
+	}
+	return &v.Int32
+}
+
+func intPtrToInt32(v *int) *int32 {
+	if v == nil {
+		return nil
+	}
+	n := int32(*v)
+	return &n
+}
@@ -0,0 +1,236 @@
+package revision
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/recipe"
+)
+
+func int32Ptr(v int32) *int32 { return &v }
+
+func TestSnapshot_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		snap    Snapshot
+		wantErr bool
+	}{
+		{
+			name: "valid",
+			snap: Snapshot{
+				RecipeName:  "Pancakes",
+				Ingredients: []Ingredient{{Name: "Flour"}},
+				Instructions: []Instruction{{
+					Instruction: "Mix.",
+					Ingredients: []StepIngredient{{Name: "flour"}},
+				}},
+			},
+		},
+		{
+			name:    "missing name",
+			snap:    Snapshot{},
+			wantErr: true,
+		},
+		{
+			name:    "difficulty out of range",
+			snap:    Snapshot{RecipeName: "Pancakes", DifficultyRating: func() *int16 { v := int16(6); return &v }()},
+			wantErr: true,
+		},
+		{
+			name: "step uses unknown ingredient",
+			snap: Snapshot{
+				RecipeName: "Pancakes",
+				Instructions: []Instruction{{
+					Instruction: "Mix.",
+					Ingredients: []StepIngredient{{Name: "milk"}},
+				}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "step uses ingredient from another part",
+			snap: Snapshot{
+				RecipeName: "Dumplings",
+				Parts: []Part{
+					{Name: "Dough", Ingredients: []Ingredient{{Name: "flour"}}},
+					{Name: "Filling", Instructions: []Instruction{{
+						Instruction: "Mix.",
+						Ingredients: []StepIngredient{{Name: "flour"}},
+					}}},
+				},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.snap.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	prev := Snapshot{
+		RecipeName:  "Tomato Soup",
+		PrepTime:    int32Ptr(10),
+		Ingredients: []Ingredient{{Name: "tomato", Quantity: "4"}},
+	}
+	next := prev
+	next.PrepTime = nil
+	next.Description = "Quick soup"
+	next.Ingredients = []Ingredient{{Name: "tomato", Quantity: "6"}}
+
+	changes, err := Diff(prev, next)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	fields := make([]string, len(changes))
+	for i, c := range changes {
+		fields[i] = c.Field
+	}
+	expected := []string{"description", "ingredients", "prep_time"}
+	if len(fields) != len(expected) {
+		t.Fatalf("expected changes %v, got %v", expected, fields)
+	}
+	for i := range expected {
+		if fields[i] != expected[i] {
+			t.Fatalf("expected changes %v, got %v", expected, fields)
+		}
+	}
+
+	if changes[2].New != nil || string(changes[2].Old) != "10" {
+		t.Errorf("expected prep_time removal, got old=%s new=%s", changes[2].Old, changes[2].New)
+	}
+	if !ContentChanged(changes) {
+		t.Error("expected an ingredients change to rewrite content")
+	}
+
+	unchanged, err := Diff(prev, prev)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(unchanged) != 0 {
+		t.Errorf("expected no changes, got %d", len(unchanged))
+	}
+
+	data, _ := json.Marshal(changes[0])
+	if string(data) != `{"field":"description","new":"Quick soup"}` {
+		t.Errorf("unexpected change encoding: %s", data)
+	}
+}
+
+func TestFromGenerated(t *testing.T) {
+	prep := 10
+	rating := 2
+	partID := "part-1"
+	generated := recipe.Recipe{
+		RecipeName:       "Pancakes",
+		PrepTime:         &prep,
+		DifficultyRating: &rating,
+		Parts: []recipe.RecipePart{{
+			ID:          partID,
+			Name:        "Batter",
+			Ingredients: []recipe.Ingredient{{Name: "flour", Quantity: "200", Unit: "g", PartID: &partID}},
+			Instructions: []recipe.Instruction{{
+				StepNumber:  1,
+				Instruction: "Whisk the flour",
+				TimerData:   []recipe.Timer{{DurationSeconds: 60, DurationText: "1 minute"}},
+				IngredientsUsed: []recipe.StepIngredient{
+					{IngredientName: "flour", QuantityUsed: "200 g"},
+					{IngredientName: "sugar", QuantityUsed: "1 tbsp"},
+				},
+			}},
+		}},
+		// Generated recipes with parts also carry the flattened lists
+		Ingredients: []recipe.Ingredient{{Name: "flour", Quantity: "200", Unit: "g", PartID: &partID}},
+	}
+
+	snap := FromGenerated(generated)
+
+	if snap.RecipeName != "Pancakes" || *snap.PrepTime != 10 || *snap.DifficultyRating != 2 {
+		t.Errorf("unexpected recipe fields: %+v", snap)
+	}
+	if len(snap.Ingredients) != 0 || len(snap.Instructions) != 0 {
+		t.Error("expected part content to stay under parts only")
+	}
+	if len(snap.Parts) != 1 {
+		t.Fatalf("expected 1 part, got %d", len(snap.Parts))
+	}
+	part := snap.Parts[0]
+	if len(part.Ingredients) != 1 || part.Ingredients[0].Quantity != "200" || part.Ingredients[0].Unit != "g" {
+		t.Errorf("unexpected part ingredients: %+v", part.Ingredients)
+	}
+	if len(part.Instructions) != 1 {
+		t.Fatalf("expected 1 instruction, got %d", len(part.Instructions))
+	}
+	step := part.Instructions[0]
+	if len(step.Timers) != 1 || step.Timers[0].DurationSeconds != 60 {
+		t.Errorf("expected timers to be kept, got %+v", step.Timers)
+	}
+	if len(step.Ingredients) != 1 || step.Ingredients[0].Name != "flour" || step.Ingredients[0].Quantity != "200 g" {
+		t.Errorf("expected only the part's ingredients to be linked, got %+v", step.Ingredients)
+	}
+	if err := snap.Validate(); err != nil {
+		t.Errorf("expected the snapshot to be valid, got %v", err)
+	}
+}
+
+func testUUID(b byte) pgtype.UUID {
+	return pgtype.UUID{Bytes: [16]byte{b}, Valid: true}
+}
+
+func TestFromRows_MultiPartRecipeStaysEditable(t *testing.T) {
+	batter, sauce := testUUID(1), testUUID(2)
+	flour, cream := testUUID(3), testUUID(4)
+	whisk, simmer := testUUID(5), testUUID(6)
+
+	parts := []generated.RecipePart{
+		{ID: batter, Name: "Batter"},
+		{ID: sauce, Name: "Sauce"},
+	}
+	ingredients := []generated.RecipeIngredient{
+		{ID: flour, PartID: batter, Name: "flour"},
+		{ID: cream, PartID: sauce, Name: "cream"},
+	}
+	instructions := []generated.RecipeInstruction{
+		{ID: whisk, PartID: batter, Instruction: "Whisk the flour"},
+		{ID: simmer, PartID: sauce, Instruction: "Simmer the cream"},
+	}
+	// Older imports matched step ingredients across parts, so the batter
+	// step may also be linked to the sauce's cream.
+	links := []generated.InstructionIngredient{
+		{InstructionID: whisk, IngredientID: flour},
+		{InstructionID: whisk, IngredientID: cream},
+		{InstructionID: simmer, IngredientID: cream},
+	}
+
+	current := fromRows(generated.Recipe{RecipeName: "Crepes"}, parts, ingredients, instructions, links)
+
+	steps := current.Parts[0].Instructions
+	if len(steps) != 1 || len(steps[0].Ingredients) != 1 || steps[0].Ingredients[0].Name != "flour" {
+		t.Errorf("expected the cross-part link to be dropped, got %+v", steps)
+	}
+	if got := current.Parts[1].Instructions[0].Ingredients; len(got) != 1 || got[0].Name != "cream" {
+		t.Errorf("expected the sauce step to keep its link, got %+v", got)
+	}
+
+	next := current
+	next.RecipeName = "Crêpes with cream"
+	if err := next.Validate(); err != nil {
+		t.Fatalf("expected a renamed multi-part recipe to be valid, got %v", err)
+	}
+	changes, err := Diff(current, next)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Field != "recipe_name" || ContentChanged(changes) {
+		t.Errorf("expected only the name to change, got %+v", changes)
+	}
+}
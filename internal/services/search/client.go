@@ -3,17 +3,23 @@ package search
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pgvector/pgvector-go"
+	"github.com/socialchef/remy/internal/cache"
 	"github.com/socialchef/remy/internal/config"
 	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/services/openai"
 )
 
 type DBQueries interface {
 	SearchRecipesByEmbedding(ctx context.Context, arg generated.SearchRecipesByEmbeddingParams) ([]generated.SearchRecipesByEmbeddingRow, error)
+	SearchRecipesByMaxSim(ctx context.Context, arg generated.SearchRecipesByMaxSimParams) ([]generated.SearchRecipesByMaxSimRow, error)
 	SearchRecipesByName(ctx context.Context, arg generated.SearchRecipesByNameParams) ([]generated.SearchRecipesByNameRow, error)
 	SearchRecipesHybrid(ctx context.Context, arg generated.SearchRecipesHybridParams) ([]generated.SearchRecipesHybridRow, error)
+	SearchRecipesByVectorRank(ctx context.Context, arg generated.SearchRecipesByVectorRankParams) ([]generated.SearchRecipesByVectorRankRow, error)
+	SearchRecipesByTextRank(ctx context.Context, arg generated.SearchRecipesByTextRankParams) ([]generated.SearchRecipesByTextRankRow, error)
 	SearchRecipesByIngredient(ctx context.Context, arg generated.SearchRecipesByIngredientParams) ([]generated.SearchRecipesByIngredientRow, error)
 }
 
@@ -22,6 +28,15 @@ type OpenAIClient interface {
 	Complete(ctx context.Context, prompt string) (string, error)
 }
 
+// QueryCache caches query expansions and embeddings across requests.
+// Lookups report a miss when the cache is unavailable.
+type QueryCache interface {
+	GetExpansion(ctx context.Context, query, model string) (string, bool)
+	SetExpansion(ctx context.Context, query, model, expansion string, ttl time.Duration) error
+	GetEmbedding(ctx context.Context, query, model string) ([]float32, bool)
+	SetEmbedding(ctx context.Context, query, model string, embedding []float32, ttl time.Duration) error
+}
+
 type SearchResult struct {
 	ID                string   `json:"id"`
 	RecipeName        string   `json:"recipe_name"`
@@ -35,28 +50,40 @@ type SearchResult struct {
 	HybridScore       float64  `json:"hybrid_score,omitempty"`
 	CuisineCategories []string `json:"cuisine_categories,omitempty"`
 	MealTypes         []string `json:"meal_types,omitempty"`
+	// MatchedEmbedding is the document kind (overview, ingredients) that
+	// matched best in max-sim search.
+	MatchedEmbedding string `json:"matched_embedding,omitempty"`
 }
 
 type Client struct {
 	db         DBQueries
 	openai     OpenAIClient
 	cfg        *config.Config
+	ranking    config.SearchConfig
 	classifier *QueryClassifier
 	reranker   *CrossEncoderReranker
 	expander   *QueryExpander
+	cache      QueryCache
 }
 
 func NewClient(db DBQueries, openai OpenAIClient, cfg *config.Config) *Client {
+	ranking := rankingConfig(cfg)
 	return &Client{
 		db:         db,
 		openai:     openai,
 		cfg:        cfg,
+		ranking:    ranking,
 		classifier: NewQueryClassifier(),
-		reranker:   NewCrossEncoderReranker(openai),
+		reranker:   NewCrossEncoderReranker(openai, *ranking.RerankWeight),
 		expander:   NewQueryExpander(openai),
 	}
 }
 
+// SetQueryCache enables caching of query expansions and embeddings.
+func (c *Client) SetQueryCache(cache QueryCache) {
+	c.cache = cache
+}
+
 func (c *Client) Search(ctx context.Context, query string, limit int32) ([]SearchResult, error) {
 	intent := c.classifier.Classify(query)
 
@@ -73,7 +100,7 @@ func (c *Client) Search(ctx context.Context, query string, limit int32) ([]Searc
 }
 
 func (c *Client) SearchSemantic(ctx context.Context, query string, limit int32) ([]SearchResult, error) {
-	embedding, err := c.openai.GenerateEmbedding(ctx, query)
+	embedding, err := c.queryEmbedding(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
@@ -101,6 +128,39 @@ func (c *Client) SearchSemantic(ctx context.Context, query string, limit int32)
 	return searchResults, nil
 }
 
+// SearchMaxSim performs semantic search across every embedding stored for a
+// recipe, scoring each recipe by its best-matching document. A query that
+// only matches the ingredients document still finds the recipe.
+func (c *Client) SearchMaxSim(ctx context.Context, query string, limit int32) ([]SearchResult, error) {
+	embedding, err := c.queryEmbedding(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	results, err := c.db.SearchRecipesByMaxSim(ctx, generated.SearchRecipesByMaxSimParams{
+		Limit:   limit,
+		Column2: pgvector.NewVector(embedding),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to search recipes: %w", err)
+	}
+
+	searchResults := make([]SearchResult, len(results))
+	for i, r := range results {
+		searchResults[i] = SearchResult{
+			ID:                pgUUIDToString(r.ID),
+			RecipeName:        r.RecipeName,
+			Description:       r.Description.String,
+			VectorSimilarity:  r.Similarity,
+			CuisineCategories: interfaceToStringSlice(r.CuisineCategories),
+			MealTypes:         interfaceToStringSlice(r.MealTypes),
+			MatchedEmbedding:  r.MatchedKind,
+		}
+	}
+
+	return searchResults, nil
+}
+
 func (c *Client) SearchByName(ctx context.Context, query string, limit int32) ([]SearchResult, error) {
 	results, err := c.db.SearchRecipesByName(ctx, generated.SearchRecipesByNameParams{
 		Similarity: query,
@@ -152,6 +212,9 @@ func (c *Client) SearchTwoPhase(ctx context.Context, query string, limit int32)
 		Limit:          limit,
 		Column2:        pgvector.NewVector(make([]float32, 1536)),
 		PlaintoTsquery: query,
+		Column4:        TextSearchConfig(DetectQueryLanguage(query)),
+		Column5:        *c.ranking.VectorWeight,
+		Column6:        *c.ranking.TextWeight,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search recipes: %w", err)
@@ -159,13 +222,12 @@ func (c *Client) SearchTwoPhase(ctx context.Context, query string, limit int32)
 
 	goodMatches := 0
 	for _, r := range results {
-		textScore := 0.3 * r.TextSimilarity
-		if textScore >= 0.24 {
+		if r.TextSimilarity >= c.ranking.TwoPhaseTextThreshold {
 			goodMatches++
 		}
 	}
 
-	if goodMatches >= 3 {
+	if goodMatches >= c.ranking.TwoPhaseMinMatches {
 		return c.convertHybridRows(results), nil
 	}
 
@@ -173,55 +235,148 @@ func (c *Client) SearchTwoPhase(ctx context.Context, query string, limit int32)
 }
 
 func (c *Client) SearchHybrid(ctx context.Context, query string, limit int32) ([]SearchResult, error) {
-	// Expand query
-	expandedQuery, err := c.expander.ExpandQuery(ctx, query)
+	expandedQuery := c.expandQuery(ctx, query)
+
+	embedding, err := c.queryEmbedding(ctx, expandedQuery)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
+	}
+
+	searchResults, err := c.rank(ctx, query, embedding, limit)
 	if err != nil {
-		expandedQuery = query // Fallback to original
+		return nil, err
 	}
 
-	embedding, err := c.openai.GenerateEmbedding(ctx, expandedQuery)
+	searchResults = c.diversifyResults(searchResults, 3)
+
+	rerankedResults, err := c.reranker.Rerank(ctx, query, searchResults, int(limit))
+	if err != nil {
+		return searchResults, nil
+	}
+
+	if int(limit) < len(rerankedResults) {
+		rerankedResults = rerankedResults[:limit]
+	}
+
+	return rerankedResults, nil
+}
+
+// RankHybrid returns the hybrid ranking of the configured strategy for the
+// query as written: without query expansion, diversification or reranking.
+// It lets cmd/searcheval compare ranking strategies on their own.
+func (c *Client) RankHybrid(ctx context.Context, query string, limit int32) ([]SearchResult, error) {
+	embedding, err := c.queryEmbedding(ctx, query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate query embedding: %w", err)
 	}
 
+	results, err := c.rank(ctx, query, embedding, limit)
+	if err != nil {
+		return nil, err
+	}
+	if int(limit) < len(results) {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// rank runs the configured ranking strategy. RRF returns more candidates
+// than the limit so the reranker has results to choose from.
+func (c *Client) rank(ctx context.Context, query string, embedding []float32, limit int32) ([]SearchResult, error) {
+	if c.ranking.RankingStrategy == RankingRRF {
+		return c.searchFused(ctx, query, embedding, limit*rrfCandidateMultiplier)
+	}
+	return c.searchLinear(ctx, query, embedding, limit)
+}
+
+// searchLinear ranks recipes by the weighted blend of vector and text
+// similarity. Text matching runs against the original query, stemmed with
+// the configuration of the language it is written in.
+func (c *Client) searchLinear(ctx context.Context, query string, embedding []float32, limit int32) ([]SearchResult, error) {
 	results, err := c.db.SearchRecipesHybrid(ctx, generated.SearchRecipesHybridParams{
 		Limit:          limit,
 		Column2:        pgvector.NewVector(embedding),
 		PlaintoTsquery: query,
+		Column4:        TextSearchConfig(DetectQueryLanguage(query)),
+		Column5:        *c.ranking.VectorWeight,
+		Column6:        *c.ranking.TextWeight,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("failed to search recipes: %w", err)
 	}
+	return c.convertHybridRows(results), nil
+}
 
-	searchResults := make([]SearchResult, len(results))
-	for i, r := range results {
-		searchResults[i] = SearchResult{
-			ID:                pgUUIDToString(r.ID),
-			RecipeName:        r.RecipeName,
-			Description:       r.Description.String,
-			ThumbnailURL:      c.buildThumbnailURL(r.ThumbnailStoragePath.String),
-			OwnerID:           pgUUIDToString(r.OwnerID),
-			OwnerUsername:     r.OwnerUsername.String,
-			VectorSimilarity:  r.VectorSimilarity,
-			TextSimilarity:    r.TextSimilarity,
-			HybridScore:       r.HybridScore,
-			CuisineCategories: interfaceToStringSlice(r.CuisineCategories),
-			MealTypes:         interfaceToStringSlice(r.MealTypes),
-		}
+// searchFused fetches the recipes nearest the embedding and the best text
+// matches as separate lists and fuses them by rank, so a strong keyword match
+// is found even when its embedding is far from the query.
+func (c *Client) searchFused(ctx context.Context, query string, embedding []float32, candidates int32) ([]SearchResult, error) {
+	params := generated.SearchRecipesByVectorRankParams{
+		Limit:          candidates,
+		Column2:        pgvector.NewVector(embedding),
+		PlaintoTsquery: query,
+		Column4:        TextSearchConfig(DetectQueryLanguage(query)),
 	}
 
-	searchResults = c.diversifyResults(searchResults, 3)
+	vectorRows, err := c.db.SearchRecipesByVectorRank(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search recipes by embedding: %w", err)
+	}
+	textRows, err := c.db.SearchRecipesByTextRank(ctx, generated.SearchRecipesByTextRankParams(params))
+	if err != nil {
+		return nil, fmt.Errorf("failed to search recipes by text: %w", err)
+	}
 
-	rerankedResults, err := c.reranker.Rerank(ctx, query, searchResults, int(limit))
+	vectorRanked := make([]SearchResult, len(vectorRows))
+	for i, r := range vectorRows {
+		vectorRanked[i] = c.convertRankedRow(r)
+	}
+	textRanked := make([]SearchResult, len(textRows))
+	for i, r := range textRows {
+		textRanked[i] = c.convertRankedRow(generated.SearchRecipesByVectorRankRow(r))
+	}
+
+	return fuseReciprocalRank(vectorRanked, textRanked, c.ranking.RRFK, *c.ranking.VectorWeight, *c.ranking.TextWeight), nil
+}
+
+// expandQuery returns the expanded query, served from cache when possible.
+// Expansion failures fall back to the original query and are not cached.
+func (c *Client) expandQuery(ctx context.Context, query string) string {
+	if c.cache != nil {
+		if expanded, ok := c.cache.GetExpansion(ctx, query, openai.CompletionModel); ok {
+			return expanded
+		}
+	}
+
+	expanded, err := c.expander.ExpandQuery(ctx, query)
 	if err != nil {
-		return searchResults, nil
+		return query
 	}
 
-	if int(limit) < len(rerankedResults) {
-		rerankedResults = rerankedResults[:limit]
+	if c.cache != nil {
+		c.cache.SetExpansion(ctx, query, openai.CompletionModel, expanded, cache.QueryExpansionTTL)
 	}
+	return expanded
+}
 
-	return rerankedResults, nil
+// queryEmbedding returns the embedding for a query, served from cache when
+// possible.
+func (c *Client) queryEmbedding(ctx context.Context, query string) ([]float32, error) {
+	if c.cache != nil {
+		if embedding, ok := c.cache.GetEmbedding(ctx, query, openai.EmbeddingModel); ok {
+			return embedding, nil
+		}
+	}
+
+	embedding, err := c.openai.GenerateEmbedding(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cache != nil {
+		c.cache.SetEmbedding(ctx, query, openai.EmbeddingModel, embedding, cache.QueryEmbeddingTTL)
+	}
+	return embedding, nil
 }
 
 func (c *Client) diversifyResults(results []SearchResult, maxPerCategory int) []SearchResult {
@@ -267,6 +422,23 @@ func (c *Client) convertHybridRows(rows []generated.SearchRecipesHybridRow) []Se
 	return results
 }
 
+// convertRankedRow converts a row of either ranked candidate list; the text
+// list's rows have the same fields.
+func (c *Client) convertRankedRow(r generated.SearchRecipesByVectorRankRow) SearchResult {
+	return SearchResult{
+		ID:                pgUUIDToString(r.ID),
+		RecipeName:        r.RecipeName,
+		Description:       r.Description.String,
+		ThumbnailURL:      c.buildThumbnailURL(r.ThumbnailStoragePath.String),
+		OwnerID:           pgUUIDToString(r.OwnerID),
+		OwnerUsername:     r.OwnerUsername.String,
+		VectorSimilarity:  r.VectorSimilarity,
+		TextSimilarity:    r.TextSimilarity,
+		CuisineCategories: interfaceToStringSlice(r.CuisineCategories),
+		MealTypes:         interfaceToStringSlice(r.MealTypes),
+	}
+}
+
 func pgUUIDToString(u pgtype.UUID) string {
 	if !u.Valid {
 		return ""
@@ -295,8 +467,14 @@ func interfaceToStringSlice(v interface{}) []string {
 }
 
 func (c *Client) buildThumbnailURL(storagePath string) string {
-	if storagePath == "" || c.cfg == nil {
+	return ThumbnailURL(c.cfg, storagePath)
+}
+
+// ThumbnailURL returns the public URL of a recipe thumbnail stored at
+// storagePath, or "" when there is none.
+func ThumbnailURL(cfg *config.Config, storagePath string) string {
+	if storagePath == "" || cfg == nil {
 		return ""
 	}
-	return fmt.Sprintf("%s/storage/v1/object/public/%s/%s", c.cfg.SupabaseURL, c.cfg.RecipeStorageBucket, storagePath)
+	return fmt.Sprintf("%s/storage/v1/object/public/%s/%s", cfg.SupabaseURL, cfg.RecipeStorageBucket, storagePath)
 }
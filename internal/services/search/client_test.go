@@ -0,0 +1,174 @@
+package search
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+// memoryCache is an in-memory QueryCache keyed by model and raw query
+type memoryCache struct {
+	expansions map[string]string
+	embeddings map[string][]float32
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{
+		expansions: make(map[string]string),
+		embeddings: make(map[string][]float32),
+	}
+}
+
+func (m *memoryCache) GetExpansion(ctx context.Context, query, model string) (string, bool) {
+	v, ok := m.expansions[model+":"+query]
+	return v, ok
+}
+
+func (m *memoryCache) SetExpansion(ctx context.Context, query, model, expansion string, ttl time.Duration) error {
+	m.expansions[model+":"+query] = expansion
+	return nil
+}
+
+func (m *memoryCache) GetEmbedding(ctx context.Context, query, model string) ([]float32, bool) {
+	v, ok := m.embeddings[model+":"+query]
+	return v, ok
+}
+
+func (m *memoryCache) SetEmbedding(ctx context.Context, query, model string, embedding []float32, ttl time.Duration) error {
+	m.embeddings[model+":"+query] = embedding
+	return nil
+}
+
+// countingOpenAI counts completion and embedding calls
+type countingOpenAI struct {
+	completions int
+	embeddings  int
+}
+
+func (c *countingOpenAI) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	c.embeddings++
+	return make([]float32, 1536), nil
+}
+
+func (c *countingOpenAI) Complete(ctx context.Context, prompt string) (string, error) {
+	c.completions++
+	return "expanded terms", nil
+}
+
+func TestSearchHybrid_CachesExpansionAndEmbedding(t *testing.T) {
+	ai := &countingOpenAI{}
+	qc := newMemoryCache()
+	client := NewClient(&captureDB{}, ai, nil)
+	client.SetQueryCache(qc)
+
+	for i := 0; i < 3; i++ {
+		if _, err := client.SearchHybrid(context.Background(), "chicken curry", 10); err != nil {
+			t.Fatalf("SearchHybrid returned error: %v", err)
+		}
+	}
+
+	if ai.completions != 1 {
+		t.Errorf("expected 1 expansion call, got %d", ai.completions)
+	}
+	if ai.embeddings != 1 {
+		t.Errorf("expected 1 embedding call, got %d", ai.embeddings)
+	}
+	if len(qc.expansions) != 1 || len(qc.embeddings) != 1 {
+		t.Errorf("expected one cached expansion and embedding, got %d and %d", len(qc.expansions), len(qc.embeddings))
+	}
+}
+
+func TestSearchHybrid_WithoutCache(t *testing.T) {
+	ai := &countingOpenAI{}
+	client := NewClient(&captureDB{}, ai, nil)
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.SearchHybrid(context.Background(), "chicken curry", 10); err != nil {
+			t.Fatalf("SearchHybrid returned error: %v", err)
+		}
+	}
+
+	if ai.completions != 2 || ai.embeddings != 2 {
+		t.Errorf("expected every search to call OpenAI, got %d completions and %d embeddings", ai.completions, ai.embeddings)
+	}
+}
+
+// maxSimDB returns canned max-sim rows
+type maxSimDB struct {
+	DBQueries
+	rows []generated.SearchRecipesByMaxSimRow
+}
+
+func (d *maxSimDB) SearchRecipesByMaxSim(ctx context.Context, arg generated.SearchRecipesByMaxSimParams) ([]generated.SearchRecipesByMaxSimRow, error) {
+	return d.rows, nil
+}
+
+func TestSearchMaxSim_ReportsMatchedEmbedding(t *testing.T) {
+	db := &maxSimDB{rows: []generated.SearchRecipesByMaxSimRow{{
+		ID:                pgtype.UUID{Bytes: [16]byte{1}, Valid: true},
+		RecipeName:        "Crispy Chickpeas",
+		CuisineCategories: []interface{}{"Indian"},
+		MatchedKind:       "ingredients",
+		Similarity:        0.82,
+	}}}
+	client := NewClient(db, stubOpenAI{}, nil)
+
+	results, err := client.SearchMaxSim(context.Background(), "chickpea snack", 10)
+	if err != nil {
+		t.Fatalf("SearchMaxSim returned error: %v", err)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result, got %d", len(results))
+	}
+	if results[0].MatchedEmbedding != "ingredients" || results[0].VectorSimilarity != 0.82 {
+		t.Errorf("unexpected result: %+v", results[0])
+	}
+	if len(results[0].CuisineCategories) != 1 || results[0].CuisineCategories[0] != "Indian" {
+		t.Errorf("expected cuisine categories [Indian], got %v", results[0].CuisineCategories)
+	}
+}
+
+// rankedDB returns canned vector-ranked and text-ranked candidate lists
+type rankedDB struct {
+	DBQueries
+	vector []generated.SearchRecipesByVectorRankRow
+	text   []generated.SearchRecipesByTextRankRow
+}
+
+func (d *rankedDB) SearchRecipesByVectorRank(ctx context.Context, arg generated.SearchRecipesByVectorRankParams) ([]generated.SearchRecipesByVectorRankRow, error) {
+	return d.vector, nil
+}
+
+func (d *rankedDB) SearchRecipesByTextRank(ctx context.Context, arg generated.SearchRecipesByTextRankParams) ([]generated.SearchRecipesByTextRankRow, error) {
+	return d.text, nil
+}
+
+func TestSearchHybrid_RRFFusesTextMatchesOutsideVectorList(t *testing.T) {
+	db := &rankedDB{
+		vector: []generated.SearchRecipesByVectorRankRow{
+			{ID: pgtype.UUID{Bytes: [16]byte{1}, Valid: true}, RecipeName: "Chicken Korma", VectorSimilarity: 0.8},
+		},
+		text: []generated.SearchRecipesByTextRankRow{
+			{ID: pgtype.UUID{Bytes: [16]byte{2}, Valid: true}, RecipeName: "Jerk Chicken", TextSimilarity: 0.6},
+		},
+	}
+	cfg := &config.Config{Search: config.SearchConfig{RankingStrategy: RankingRRF}}
+	client := NewClient(db, stubOpenAI{}, cfg)
+
+	results, err := client.SearchHybrid(context.Background(), "jerk chicken", 10)
+	if err != nil {
+		t.Fatalf("SearchHybrid returned error: %v", err)
+	}
+
+	names := make(map[string]bool)
+	for _, r := range results {
+		names[r.RecipeName] = true
+	}
+	if len(results) != 2 || !names["Chicken Korma"] || !names["Jerk Chicken"] {
+		t.Errorf("expected both candidate lists fused, got %+v", results)
+	}
+}
@@ -0,0 +1,112 @@
+package search
+
+import (
+	"math"
+	"sort"
+)
+
+// JudgedQuery is a search query with human relevance judgments, keyed by
+// recipe ID. Grades run from 1 (somewhat relevant) to 3 (perfect match);
+// recipes that are not listed count as irrelevant.
+type JudgedQuery struct {
+	Query     string         `json:"query"`
+	Judgments map[string]int `json:"judgments"`
+}
+
+// QueryMetrics holds the ranking quality of a single query at cut-off k.
+type QueryMetrics struct {
+	NDCG           float64 `json:"ndcg"`
+	ReciprocalRank float64 `json:"reciprocal_rank"`
+	Recall         float64 `json:"recall"`
+}
+
+// EvaluateRanking scores a ranked list of recipe IDs against the judgments,
+// looking only at the top k results.
+func EvaluateRanking(ranked []string, judgments map[string]int, k int) QueryMetrics {
+	top := ranked
+	if k > 0 && k < len(ranked) {
+		top = ranked[:k]
+	}
+
+	var metrics QueryMetrics
+	relevantFound := 0
+	for i, id := range top {
+		grade := judgments[id]
+		if grade <= 0 {
+			continue
+		}
+		relevantFound++
+		if metrics.ReciprocalRank == 0 {
+			metrics.ReciprocalRank = 1 / float64(i+1)
+		}
+	}
+
+	relevantTotal := 0
+	for _, grade := range judgments {
+		if grade > 0 {
+			relevantTotal++
+		}
+	}
+	if relevantTotal > 0 {
+		metrics.Recall = float64(relevantFound) / float64(relevantTotal)
+	}
+
+	ideal := idealDCG(judgments, k)
+	if ideal > 0 {
+		metrics.NDCG = dcg(top, judgments) / ideal
+	}
+
+	return metrics
+}
+
+// MeanMetrics averages per-query metrics.
+func MeanMetrics(all []QueryMetrics) QueryMetrics {
+	var mean QueryMetrics
+	if len(all) == 0 {
+		return mean
+	}
+	for _, m := range all {
+		mean.NDCG += m.NDCG
+		mean.ReciprocalRank += m.ReciprocalRank
+		mean.Recall += m.Recall
+	}
+	n := float64(len(all))
+	mean.NDCG /= n
+	mean.ReciprocalRank /= n
+	mean.Recall /= n
+	return mean
+}
+
+func dcg(ranked []string, judgments map[string]int) float64 {
+	score := 0.0
+	for i, id := range ranked {
+		score += gain(judgments[id]) / math.Log2(float64(i+2))
+	}
+	return score
+}
+
+func idealDCG(judgments map[string]int, k int) float64 {
+	grades := make([]int, 0, len(judgments))
+	for _, grade := range judgments {
+		if grade > 0 {
+			grades = append(grades, grade)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(grades)))
+	if k > 0 && len(grades) > k {
+		grades = grades[:k]
+	}
+
+	score := 0.0
+	for i, grade := range grades {
+		score += gain(grade) / math.Log2(float64(i+2))
+	}
+	return score
+}
+
+func gain(grade int) float64 {
+	if grade <= 0 {
+		return 0
+	}
+	return math.Pow(2, float64(grade)) - 1
+}
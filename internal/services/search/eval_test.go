@@ -0,0 +1,85 @@
+package search
+
+import (
+	"math"
+	"testing"
+)
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestEvaluateRanking(t *testing.T) {
+	judgments := map[string]int{"a": 3, "b": 1, "c": 2}
+
+	tests := []struct {
+		name       string
+		ranked     []string
+		k          int
+		wantNDCG   float64
+		wantRR     float64
+		wantRecall float64
+	}{
+		{
+			name:       "ideal order",
+			ranked:     []string{"a", "c", "b"},
+			k:          10,
+			wantNDCG:   1,
+			wantRR:     1,
+			wantRecall: 1,
+		},
+		{
+			name:       "first relevant at rank 2",
+			ranked:     []string{"x", "a"},
+			k:          10,
+			wantNDCG:   7 / math.Log2(3) / (7 + 3/math.Log2(3) + 1/math.Log2(4)),
+			wantRR:     0.5,
+			wantRecall: 1.0 / 3,
+		},
+		{
+			name:       "relevant result beyond cut-off",
+			ranked:     []string{"x", "y", "a"},
+			k:          2,
+			wantNDCG:   0,
+			wantRR:     0,
+			wantRecall: 0,
+		},
+		{
+			name:       "no results",
+			ranked:     nil,
+			k:          10,
+			wantNDCG:   0,
+			wantRR:     0,
+			wantRecall: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := EvaluateRanking(tt.ranked, judgments, tt.k)
+			if !almostEqual(got.NDCG, tt.wantNDCG) {
+				t.Errorf("NDCG = %v, want %v", got.NDCG, tt.wantNDCG)
+			}
+			if !almostEqual(got.ReciprocalRank, tt.wantRR) {
+				t.Errorf("ReciprocalRank = %v, want %v", got.ReciprocalRank, tt.wantRR)
+			}
+			if !almostEqual(got.Recall, tt.wantRecall) {
+				t.Errorf("Recall = %v, want %v", got.Recall, tt.wantRecall)
+			}
+		})
+	}
+}
+
+func TestMeanMetrics(t *testing.T) {
+	mean := MeanMetrics([]QueryMetrics{
+		{NDCG: 1, ReciprocalRank: 1, Recall: 1},
+		{NDCG: 0, ReciprocalRank: 0.5, Recall: 0},
+	})
+	if !almostEqual(mean.NDCG, 0.5) || !almostEqual(mean.ReciprocalRank, 0.75) || !almostEqual(mean.Recall, 0.5) {
+		t.Errorf("MeanMetrics = %+v", mean)
+	}
+
+	if empty := MeanMetrics(nil); empty != (QueryMetrics{}) {
+		t.Errorf("MeanMetrics(nil) = %+v, want zero value", empty)
+	}
+}
@@ -0,0 +1,104 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Language codes returned by DetectQueryLanguage
+const (
+	LanguageEnglish = "en"
+	LanguageDutch   = "nl"
+)
+
+// textSearchConfigs maps ISO 639-1 codes to Postgres text search configurations.
+// Must stay in sync with recipe_search_config() in the multilingual search migration.
+var textSearchConfigs = map[string]string{
+	"en": "english",
+	"nl": "dutch",
+	"de": "german",
+	"fr": "french",
+	"es": "spanish",
+	"it": "italian",
+	"pt": "portuguese",
+}
+
+// TextSearchConfig returns the Postgres regconfig name for a recipe language.
+// Empty input falls back to english (the recipes.language column default),
+// unknown languages use the non-stemming 'simple' configuration.
+func TextSearchConfig(language string) string {
+	language = strings.ToLower(strings.TrimSpace(language))
+	if language == "" {
+		return "english"
+	}
+	if cfg, ok := textSearchConfigs[language]; ok {
+		return cfg
+	}
+	return "simple"
+}
+
+// dutchMarkers are common Dutch function words and cooking terms that rarely
+// appear in English queries.
+var dutchMarkers = map[string]bool{
+	"met": true, "en": true, "van": true, "de": true, "het": true, "een": true,
+	"zonder": true, "voor": true, "uit": true, "op": true, "of": true, "snel": true,
+	"makkelijk": true, "gezond": true, "lekker": true, "recept": true, "recepten": true,
+	"kip": true, "kipfilet": true, "gehakt": true, "vis": true, "zalm": true,
+	"groente": true, "groenten": true, "aardappel": true, "aardappelen": true,
+	"ui": true, "uien": true, "knoflook": true, "boter": true, "kaas": true,
+	"ei": true, "eieren": true, "melk": true, "room": true, "bloem": true,
+	"suiker": true, "zout": true, "peper": true, "soep": true, "taart": true,
+	"appeltaart": true, "stamppot": true, "pannenkoek": true, "pannenkoeken": true,
+	"poffertjes": true, "oliebollen": true, "krentenbollen": true, "brood": true,
+	"broodjes": true, "saus": true, "rijst": true, "ovenschotel": true,
+	"vegetarisch": true, "ontbijt": true, "lunch": true, "avondeten": true,
+	"toetje": true, "nagerecht": true, "bakken": true, "koken": true,
+}
+
+// englishMarkers are common English function words and cooking terms.
+var englishMarkers = map[string]bool{
+	"with": true, "and": true, "the": true, "of": true, "for": true, "without": true,
+	"easy": true, "quick": true, "healthy": true, "recipe": true, "recipes": true,
+	"chicken": true, "beef": true, "fish": true, "salmon": true, "potato": true,
+	"potatoes": true, "onion": true, "garlic": true, "butter": true, "cheese": true,
+	"egg": true, "eggs": true, "milk": true, "cream": true, "flour": true,
+	"sugar": true, "salt": true, "pepper": true, "soup": true, "cake": true,
+	"pie": true, "bread": true, "sauce": true, "rice": true, "vegetarian": true,
+	"vegan": true, "breakfast": true, "dinner": true, "dessert": true, "snack": true,
+}
+
+// dutchSuffixes are word endings that are characteristic for Dutch nouns.
+// Letter pairs such as "oe" or "aa" are not used: English words like
+// "tomatoes" and "naan" have them too.
+var dutchSuffixes = []string{"tje", "tjes", "pje", "pjes", "je", "jes", "heid", "lijk"}
+
+// DetectQueryLanguage makes a best-effort guess of a search query's language.
+// Queries are short, so this is a lightweight marker-word heuristic rather than
+// a full language identifier. Only Dutch and English are distinguished; anything
+// inconclusive is treated as English.
+func DetectQueryLanguage(query string) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+
+	dutchScore, englishScore := 0, 0
+	for _, w := range words {
+		if dutchMarkers[w] {
+			dutchScore += 2
+		}
+		if englishMarkers[w] {
+			englishScore += 2
+		}
+		for _, suffix := range dutchSuffixes {
+			if len(w) > len(suffix)+2 && strings.HasSuffix(w, suffix) {
+				dutchScore++
+				break
+			}
+		}
+	}
+
+	if dutchScore > englishScore {
+		return LanguageDutch
+	}
+	return LanguageEnglish
+}
@@ -0,0 +1,111 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+func TestDetectQueryLanguage(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{name: "Dutch dish", query: "pannenkoeken", want: LanguageDutch},
+		{name: "Dutch phrase", query: "stamppot met rookworst", want: LanguageDutch},
+		{name: "Dutch diminutive", query: "broodjes met kaas", want: LanguageDutch},
+		{name: "Dutch question", query: "snelle soep zonder vlees", want: LanguageDutch},
+		{name: "Dutch ingredient", query: "rijst", want: LanguageDutch},
+		{name: "English dish", query: "chicken curry", want: LanguageEnglish},
+		{name: "English phrase", query: "easy pasta with garlic and butter", want: LanguageEnglish},
+		{name: "English oe", query: "tomatoes", want: LanguageEnglish},
+		{name: "English oe phrase", query: "roasted tomatoes", want: LanguageEnglish},
+		{name: "English aa", query: "naan", want: LanguageEnglish},
+		{name: "English oe in compound", query: "shoestring fries", want: LanguageEnglish},
+		{name: "Empty query", query: "", want: LanguageEnglish},
+		{name: "Punctuation only", query: "?!", want: LanguageEnglish},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DetectQueryLanguage(tt.query); got != tt.want {
+				t.Errorf("DetectQueryLanguage(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTextSearchConfig(t *testing.T) {
+	tests := []struct {
+		language string
+		want     string
+	}{
+		{language: "nl", want: "dutch"},
+		{language: "NL", want: "dutch"},
+		{language: "en", want: "english"},
+		{language: "", want: "english"},
+		{language: "de", want: "german"},
+		{language: "ja", want: "simple"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			if got := TextSearchConfig(tt.language); got != tt.want {
+				t.Errorf("TextSearchConfig(%q) = %q, want %q", tt.language, got, tt.want)
+			}
+		})
+	}
+}
+
+// captureDB records the hybrid search params it receives
+type captureDB struct {
+	DBQueries
+	hybridParams []generated.SearchRecipesHybridParams
+}
+
+func (d *captureDB) SearchRecipesHybrid(ctx context.Context, arg generated.SearchRecipesHybridParams) ([]generated.SearchRecipesHybridRow, error) {
+	d.hybridParams = append(d.hybridParams, arg)
+	return nil, nil
+}
+
+type stubOpenAI struct{}
+
+func (stubOpenAI) GenerateEmbedding(ctx context.Context, text string) ([]float32, error) {
+	return make([]float32, 1536), nil
+}
+
+func (stubOpenAI) Complete(ctx context.Context, prompt string) (string, error) {
+	return "", nil
+}
+
+func TestSearchHybrid_UsesQueryLanguageConfig(t *testing.T) {
+	tests := []struct {
+		query      string
+		wantConfig string
+	}{
+		{query: "pannenkoeken met spek", wantConfig: "dutch"},
+		{query: "pancakes with bacon", wantConfig: "english"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			db := &captureDB{}
+			client := NewClient(db, stubOpenAI{}, nil)
+
+			if _, err := client.SearchHybrid(context.Background(), tt.query, 10); err != nil {
+				t.Fatalf("SearchHybrid returned error: %v", err)
+			}
+			if len(db.hybridParams) != 1 {
+				t.Fatalf("expected 1 hybrid query, got %d", len(db.hybridParams))
+			}
+			if got := db.hybridParams[0].Column4; got != tt.wantConfig {
+				t.Errorf("text search config = %q, want %q", got, tt.wantConfig)
+			}
+			if got := db.hybridParams[0].PlaintoTsquery; got != tt.query {
+				t.Errorf("text query = %q, want original query %q", got, tt.query)
+			}
+		})
+	}
+}
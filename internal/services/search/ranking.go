@@ -0,0 +1,67 @@
+package search
+
+import (
+	"sort"
+
+	"github.com/socialchef/remy/internal/config"
+)
+
+// Ranking strategies for hybrid search
+const (
+	RankingLinear = "linear"
+	RankingRRF    = "rrf"
+)
+
+// rrfCandidateMultiplier sizes the vector and text candidate lists for RRF
+// relative to the results returned. Fusion only helps when results that rank
+// well on a single signal are present to be fused.
+const rrfCandidateMultiplier = 4
+
+// rankingConfig returns the search ranking settings, falling back to the
+// defaults when no config is provided.
+func rankingConfig(cfg *config.Config) config.SearchConfig {
+	var c config.Config
+	if cfg != nil {
+		c.Search = cfg.Search
+	}
+	c.SetSearchDefaults()
+	return c.Search
+}
+
+// fuseReciprocalRank fuses a vector-ranked and a text-ranked candidate list
+// with weighted reciprocal rank fusion: each result scores w/(k+rank) for its
+// rank in each list it appears in. Scores are normalised to 0-1 so they blend
+// with the reranker's LLM score the same way linear hybrid scores do.
+func fuseReciprocalRank(vectorRanked, textRanked []SearchResult, k int, vectorWeight, textWeight float64) []SearchResult {
+	if k <= 0 {
+		k = 60
+	}
+
+	fused := make([]SearchResult, 0, len(vectorRanked)+len(textRanked))
+	index := make(map[string]int, cap(fused))
+	add := func(ranked []SearchResult, weight float64) {
+		for rank, r := range ranked {
+			i, ok := index[r.ID]
+			if !ok {
+				i = len(fused)
+				index[r.ID] = i
+				r.HybridScore = 0
+				fused = append(fused, r)
+			}
+			fused[i].HybridScore += weight / float64(k+rank+1)
+		}
+	}
+	add(vectorRanked, vectorWeight)
+	add(textRanked, textWeight)
+
+	if maxScore := (vectorWeight + textWeight) / float64(k+1); maxScore > 0 {
+		for i := range fused {
+			fused[i].HybridScore /= maxScore
+		}
+	}
+
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].HybridScore > fused[j].HybridScore
+	})
+	return fused
+}
@@ -0,0 +1,72 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/socialchef/remy/internal/config"
+)
+
+func TestFuseReciprocalRank(t *testing.T) {
+	vectorRanked := []SearchResult{
+		{ID: "vector-only", VectorSimilarity: 0.9},
+		{ID: "both", VectorSimilarity: 0.8, TextSimilarity: 0.5},
+		{ID: "neither", VectorSimilarity: 0.5},
+	}
+	textRanked := []SearchResult{
+		{ID: "text-only", VectorSimilarity: 0.1, TextSimilarity: 0.9},
+		{ID: "both", VectorSimilarity: 0.8, TextSimilarity: 0.5},
+	}
+
+	fused := fuseReciprocalRank(vectorRanked, textRanked, 60, 1, 1)
+
+	if len(fused) != 4 {
+		t.Fatalf("expected 4 distinct results, got %d", len(fused))
+	}
+	// Ranked well on both signals beats ranked first on only one
+	if fused[0].ID != "both" {
+		t.Errorf("expected 'both' first, got %q", fused[0].ID)
+	}
+	// A text match missing from the vector list is still fused in
+	if fused[1].ID != "vector-only" && fused[1].ID != "text-only" {
+		t.Errorf("expected a single-list leader second, got %q", fused[1].ID)
+	}
+	for _, r := range fused {
+		if r.HybridScore <= 0 || r.HybridScore > 1 {
+			t.Errorf("score for %q = %v, want within (0, 1]", r.ID, r.HybridScore)
+		}
+	}
+	// Input slices are left untouched
+	if vectorRanked[1].HybridScore != 0 || textRanked[1].HybridScore != 0 {
+		t.Error("fuseReciprocalRank modified its input")
+	}
+}
+
+func TestFuseReciprocalRank_TextWeightZero(t *testing.T) {
+	vectorRanked := []SearchResult{{ID: "b"}, {ID: "a"}}
+	textRanked := []SearchResult{{ID: "a"}}
+
+	fused := fuseReciprocalRank(vectorRanked, textRanked, 60, 1, 0)
+	if fused[0].ID != "b" {
+		t.Errorf("expected vector ranking to win with zero text weight, got %q first", fused[0].ID)
+	}
+}
+
+func TestRankingConfig_Defaults(t *testing.T) {
+	got := rankingConfig(nil)
+	if got.RankingStrategy != RankingLinear || *got.VectorWeight != 0.7 || *got.TextWeight != 0.3 {
+		t.Errorf("unexpected defaults: %+v", got)
+	}
+
+	cfg := &config.Config{Search: config.SearchConfig{RankingStrategy: RankingRRF, TextWeight: config.Float64(0.5)}}
+	got = rankingConfig(cfg)
+	if got.RankingStrategy != RankingRRF || *got.TextWeight != 0.5 || *got.VectorWeight != 0.7 {
+		t.Errorf("config values not applied over defaults: %+v", got)
+	}
+
+	// An explicit zero weight is kept
+	cfg = &config.Config{Search: config.SearchConfig{TextWeight: config.Float64(0), RerankWeight: config.Float64(0)}}
+	got = rankingConfig(cfg)
+	if *got.TextWeight != 0 || *got.RerankWeight != 0 {
+		t.Errorf("zero weights replaced by defaults: text %v, rerank %v", *got.TextWeight, *got.RerankWeight)
+	}
+}
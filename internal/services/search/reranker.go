@@ -10,16 +10,22 @@ import (
 
 type CrossEncoderReranker struct {
 	openai OpenAIClient
+	// weight is the share of the LLM score in the blended score
+	weight float64
 }
 
-func NewCrossEncoderReranker(openai OpenAIClient) *CrossEncoderReranker {
-	return &CrossEncoderReranker{openai: openai}
+func NewCrossEncoderReranker(openai OpenAIClient, weight float64) *CrossEncoderReranker {
+	return &CrossEncoderReranker{openai: openai, weight: weight}
 }
 
 func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, results []SearchResult, topK int) ([]SearchResult, error) {
 	if len(results) <= topK {
 		return results, nil
 	}
+	// With no share for the LLM score the hybrid order stands
+	if r.weight == 0 {
+		return results[:topK], nil
+	}
 
 	candidates := results
 	if len(candidates) > 20 {
@@ -46,7 +52,7 @@ func (r *CrossEncoderReranker) Rerank(ctx context.Context, query string, results
 	for i := range candidates {
 		if i < len(scores) {
 			llmScore := float64(scores[i]) / 10.0
-			candidates[i].HybridScore = 0.6*candidates[i].HybridScore + 0.4*llmScore
+			candidates[i].HybridScore = (1-r.weight)*candidates[i].HybridScore + r.weight*llmScore
 		}
 	}
 
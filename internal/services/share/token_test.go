@@ -0,0 +1,44 @@
+package share
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestSignVerify(t *testing.T) {
+	id := uuid.New()
+	token := Sign("secret", id)
+
+	got, err := Verify("secret", token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != id {
+		t.Errorf("expected %s, got %s", id, got)
+	}
+}
+
+func TestVerify_Invalid(t *testing.T) {
+	id := uuid.New()
+	token := Sign("secret", id)
+	other := Sign("secret", uuid.New())
+
+	tests := map[string]struct {
+		secret, token string
+	}{
+		"wrong secret": {"other", token},
+		"no secret":    {"", token},
+		"no signature": {"secret", token[:22]},
+		"swapped id":   {"secret", other[:22] + token[22:]},
+		"truncated":    {"secret", token[:len(token)-2]},
+		"not base64":   {"secret", "!!!.???"},
+		"empty":        {"secret", ""},
+		"short id":     {"secret", "AAAA" + token[22:]},
+	}
+	for name, tt := range tests {
+		if _, err := Verify(tt.secret, tt.token); err != ErrInvalidToken {
+			t.Errorf("%s: expected ErrInvalidToken, got %v", name, err)
+		}
+	}
+}
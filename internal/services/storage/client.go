@@ -11,6 +11,8 @@ import (
 	"github.com/google/uuid"
 	"io"
 	"net/http"
+	"strings"
+	"time"
 )
 
 type Client struct {
@@ -40,11 +42,20 @@ type uploadResponse struct {
 }
 
 func (c *Client) UploadImage(ctx context.Context, bucket, path string, data []byte, contentType string) (string, error) {
+	if err := c.UploadObject(ctx, bucket, path, data, contentType); err != nil {
+		return "", err
+	}
+	return c.GetPublicURL(bucket, path), nil
+}
+
+// UploadObject stores data at path in a bucket, replacing any existing
+// object. Unlike UploadImage it works for private buckets.
+func (c *Client) UploadObject(ctx context.Context, bucket, path string, data []byte, contentType string) error {
 	uploadURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.supabaseURL, bucket, path)
 
 	req, err := http.NewRequestWithContext(ctx, "POST", uploadURL, bytes.NewReader(data))
 	if err != nil {
-		return "", err
+		return err
 	}
 	req.Header.Set("Authorization", "Bearer "+c.serviceKey)
 	req.Header.Set("Content-Type", contentType)
@@ -52,21 +63,179 @@ func (c *Client) UploadImage(ctx context.Context, bucket, path string, data []by
 
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		return "", err
+		return err
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode >= 400 {
 		body, _ := io.ReadAll(resp.Body)
-		return "", fmt.Errorf("%w: %s", ErrUploadFailed, string(body))
+		return fmt.Errorf("%w: %s", ErrUploadFailed, string(body))
 	}
 
 	var result uploadResponse
+	return json.NewDecoder(resp.Body).Decode(&result)
+}
+
+type signedURLResponse struct {
+	SignedURL string `json:"signedURL"`
+}
+
+// CreateSignedURL returns a URL that downloads a private object until it
+// expires.
+func (c *Client) CreateSignedURL(ctx context.Context, bucket, path string, expiresIn time.Duration) (string, error) {
+	signURL := fmt.Sprintf("%s/storage/v1/object/sign/%s/%s", c.supabaseURL, bucket, path)
+
+	body, err := json.Marshal(map[string]int{"expiresIn": int(expiresIn.Seconds())})
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", signURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.serviceKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("failed to sign object URL: %s", string(respBody))
+	}
+
+	var result signedURLResponse
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
 		return "", err
 	}
 
-	return c.GetPublicURL(bucket, path), nil
+	// The signed URL is relative to the storage API
+	return c.supabaseURL + "/storage/v1" + result.SignedURL, nil
+}
+
+// DownloadObject reads an object from a bucket, public or private.
+func (c *Client) DownloadObject(ctx context.Context, bucket, path string) ([]byte, error) {
+	downloadURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.supabaseURL, bucket, path)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.serviceKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("failed to download object: %s", string(body))
+	}
+
+	return io.ReadAll(resp.Body)
+}
+
+// DeleteObject removes an object from a bucket. Deleting an object that does
+// not exist is not an error.
+func (c *Client) DeleteObject(ctx context.Context, bucket, path string) error {
+	deleteURL := fmt.Sprintf("%s/storage/v1/object/%s/%s", c.supabaseURL, bucket, path)
+
+	req, err := http.NewRequestWithContext(ctx, "DELETE", deleteURL, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.serviceKey)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil
+	}
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("failed to delete object: %s", string(body))
+	}
+
+	return nil
+}
+
+// listPageSize is how many entries ListObjects asks for at a time.
+const listPageSize = 1000
+
+type listEntry struct {
+	Name string `json:"name"`
+	// ID is null for folders
+	ID *string `json:"id"`
+}
+
+// ListObjects returns the paths of the objects under prefix in a bucket,
+// including those in folders below it.
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	listURL := fmt.Sprintf("%s/storage/v1/object/list/%s", c.supabaseURL, bucket)
+
+	var paths []string
+	for offset := 0; ; offset += listPageSize {
+		body, err := json.Marshal(map[string]interface{}{
+			"prefix": prefix,
+			"limit":  listPageSize,
+			"offset": offset,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", listURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.serviceKey)
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		var entries []listEntry
+		if resp.StatusCode >= 400 {
+			respBody, _ := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			return nil, fmt.Errorf("failed to list objects: %s", string(respBody))
+		}
+		err = json.NewDecoder(resp.Body).Decode(&entries)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			path := strings.TrimSuffix(prefix, "/") + "/" + entry.Name
+			if prefix == "" {
+				path = entry.Name
+			}
+			if entry.ID != nil {
+				paths = append(paths, path)
+				continue
+			}
+			nested, err := c.ListObjects(ctx, bucket, path)
+			if err != nil {
+				return nil, err
+			}
+			paths = append(paths, nested...)
+		}
+		if len(entries) < listPageSize {
+			return paths, nil
+		}
+	}
 }
 
 func (c *Client) GetPublicURL(bucket, path string) string {
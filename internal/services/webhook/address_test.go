@@ -0,0 +1,82 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestIsPublic(t *testing.T) {
+	tests := []struct {
+		addr string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.0.0.5", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.100.100.200", false},
+		{"0.0.0.0", false},
+		{"fdaa::3", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"64:ff9b::a9fe:a9fe", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublic(netip.MustParseAddr(tt.addr)); got != tt.want {
+			t.Errorf("isPublic(%s) = %v, want %v", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestCheckHost(t *testing.T) {
+	for _, host := range []string{"localhost", "169.254.169.254", "fdaa::3"} {
+		if err := CheckHost(context.Background(), host); !errors.Is(err, ErrPrivateAddress) {
+			t.Errorf("CheckHost(%s) = %v, want ErrPrivateAddress", host, err)
+		}
+	}
+}
+
+func TestDeliver_RefusesPrivateAddresses(t *testing.T) {
+	var called bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(nil, fastRetry()).Deliver(context.Background(), srv.URL, "secret", "d1", EventImportCompleted, []byte(`{}`))
+	if !errors.Is(err, ErrPrivateAddress) {
+		t.Fatalf("expected ErrPrivateAddress, got %v", err)
+	}
+	if called || result.Attempts != 1 {
+		t.Errorf("expected a single refused attempt, got %+v", result)
+	}
+}
+
+func TestDeliver_DoesNotFollowRedirects(t *testing.T) {
+	var redirected bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/internal" {
+			redirected = true
+			return
+		}
+		http.Redirect(w, r, "/internal", http.StatusTemporaryRedirect)
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(NewHTTPClient(true), fastRetry()).Deliver(context.Background(), srv.URL, "secret", "d1", EventImportCompleted, []byte(`{}`))
+	if err == nil {
+		t.Fatal("expected the redirect to fail the delivery")
+	}
+	if redirected || result.StatusCode != http.StatusTemporaryRedirect {
+		t.Errorf("expected the redirect not to be followed, got %+v", result)
+	}
+}
@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/socialchef/remy/internal/utils"
+)
+
+// Client delivers signed events to subscriber URLs.
+type Client struct {
+	httpClient *http.Client
+	retry      utils.RetryConfig
+}
+
+// NewClient returns a client that retries failed deliveries with the given
+// backoff. Each attempt is bounded by the retry config's Timeout. A nil
+// httpClient means NewHTTPClient(false), which only reaches public addresses.
+func NewClient(httpClient *http.Client, retry utils.RetryConfig) *Client {
+	if httpClient == nil {
+		httpClient = NewHTTPClient(false)
+	}
+	return &Client{httpClient: httpClient, retry: retry}
+}
+
+// Result describes a delivery: how many attempts it took and the status of
+// the last response, zero if none was received.
+type Result struct {
+	Attempts   int
+	StatusCode int
+}
+
+// Deliver POSTs a signed event body to url, retrying network errors, 5xx
+// and 429 responses with exponential backoff. Any 2xx response succeeds.
+func (c *Client) Deliver(ctx context.Context, url, secret, deliveryID, event string, body []byte) (Result, error) {
+	var result Result
+	_, err := utils.WithRetry(ctx, func(ctx context.Context) (struct{}, error) {
+		result.Attempts++
+		status, err := c.post(ctx, url, secret, deliveryID, event, body)
+		result.StatusCode = status
+		return struct{}{}, err
+	}, c.retry)
+	return result, err
+}
+
+func (c *Client) post(ctx context.Context, url, secret, deliveryID, event string, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("invalid webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Remy-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderSignature, Sign(secret, time.Now(), body))
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// Drain a little of the body so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("webhook endpoint returned status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
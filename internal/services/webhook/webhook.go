@@ -0,0 +1,157 @@
+// Package webhook builds, signs and delivers outgoing webhook events.
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Events a subscription can receive
+const (
+	EventImportCompleted     = "import.completed"
+	EventImportFailed        = "import.failed"
+	EventBulkImportCompleted = "bulk_import.completed"
+	EventRecipeUpdated       = "recipe.updated"
+)
+
+// Events lists every event, in the order they are documented.
+var Events = []string{
+	EventImportCompleted,
+	EventImportFailed,
+	EventBulkImportCompleted,
+	EventRecipeUpdated,
+}
+
+// IsEvent reports whether name is an event subscriptions can receive.
+func IsEvent(name string) bool {
+	for _, event := range Events {
+		if event == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Delivery headers
+const (
+	HeaderEvent     = "X-Remy-Event"
+	HeaderDelivery  = "X-Remy-Delivery"
+	HeaderSignature = "X-Remy-Signature"
+)
+
+// Envelope is the JSON body of every delivery. ID identifies the event and
+// stays the same when it is redelivered, so receivers can deduplicate.
+type Envelope struct {
+	ID        string          `json:"id"`
+	Type      string          `json:"type"`
+	UserID    string          `json:"user_id"`
+	CreatedAt string          `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// ImportData is the data of import.completed and import.failed events.
+type ImportData struct {
+	JobID     string `json:"job_id"`
+	URL       string `json:"url,omitempty"`
+	RecipeID  string `json:"recipe_id,omitempty"`
+	BulkJobID string `json:"bulk_job_id,omitempty"`
+	Error     string `json:"error,omitempty"`
+	ErrorCode string `json:"error_code,omitempty"`
+}
+
+// BulkImportData is the data of bulk_import.completed events.
+type BulkImportData struct {
+	BulkJobID string `json:"bulk_job_id"`
+	Total     int    `json:"total"`
+	Success   int    `json:"success"`
+	Failed    int    `json:"failed"`
+}
+
+// RecipeData is the data of recipe.updated events. Action is the revision
+// action for edits and restores, or "regenerate".
+type RecipeData struct {
+	RecipeID string `json:"recipe_id"`
+	Action   string `json:"action"`
+	Revision int32  `json:"revision,omitempty"`
+}
+
+// NewEnvelope wraps an event's data with a new event ID.
+func NewEnvelope(event, userID string, data interface{}) (Envelope, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal webhook data: %w", err)
+	}
+	return Envelope{
+		ID:        uuid.New().String(),
+		Type:      event,
+		UserID:    userID,
+		CreatedAt: time.Now().UTC().Format("2006-01-02T15:04:05Z07:00"),
+		Data:      raw,
+	}, nil
+}
+
+// NewSecret returns a random signing secret for a subscription.
+func NewSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(b), nil
+}
+
+// ErrInvalidSignature is returned for signatures that don't match the body
+// or are too old.
+var ErrInvalidSignature = errors.New("invalid webhook signature")
+
+// Sign returns the X-Remy-Signature header for a body sent at t:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">". Including the
+// time stops old deliveries from being replayed.
+func Sign(secret string, t time.Time, body []byte) string {
+	ts := strconv.FormatInt(t.Unix(), 10)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac(secret, ts, body))
+}
+
+// Verify checks a signature header against the body, rejecting signatures
+// older than tolerance. Receivers can use it as a reference implementation.
+func Verify(secret, header string, body []byte, tolerance time.Duration, now time.Time) error {
+	var ts, sig string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			sig = value
+		}
+	}
+	unix, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+		return ErrInvalidSignature
+	}
+	expected, err := hex.DecodeString(sig)
+	if err != nil || !hmac.Equal(expected, mac(secret, ts, body)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func mac(secret, ts string, body []byte) []byte {
+	h := hmac.New(sha256.New, []byte(secret))
+	h.Write([]byte(ts))
+	h.Write([]byte("."))
+	h.Write(body)
+	return h.Sum(nil)
+}
@@ -0,0 +1,105 @@
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/socialchef/remy/internal/utils"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"id":"1"}`)
+	now := time.Now()
+	header := Sign("secret", now, body)
+
+	if err := Verify("secret", header, body, 5*time.Minute, now); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[string]struct {
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+	}{
+		"wrong secret":  {"other", header, body, now},
+		"changed body":  {"secret", header, []byte(`{"id":"2"}`), now},
+		"too old":       {"secret", header, body, now.Add(10 * time.Minute)},
+		"no timestamp":  {"secret", header[len("t=1234567890,"):], body, now},
+		"empty header":  {"secret", "", body, now},
+		"not hex":       {"secret", "t=1,v1=zz", body, time.Unix(1, 0)},
+		"swapped stamp": {"secret", "t=1" + header[len("t=1234567890"):], body, time.Unix(1, 0)},
+	}
+	for name, tt := range tests {
+		if err := Verify(tt.secret, tt.header, tt.body, 5*time.Minute, tt.now); err != ErrInvalidSignature {
+			t.Errorf("%s: expected ErrInvalidSignature, got %v", name, err)
+		}
+	}
+}
+
+func TestIsEvent(t *testing.T) {
+	if !IsEvent(EventRecipeUpdated) {
+		t.Errorf("expected %s to be an event", EventRecipeUpdated)
+	}
+	if IsEvent("recipe.deleted") {
+		t.Error("expected recipe.deleted not to be an event")
+	}
+}
+
+func fastRetry() utils.RetryConfig {
+	cfg := utils.WebhookRetryConfig()
+	cfg.MaxAttempts = 3
+	cfg.InitialDelay = time.Millisecond
+	cfg.MaxDelay = time.Millisecond
+	return cfg
+}
+
+func TestDeliver_RetriesServerErrors(t *testing.T) {
+	var calls int32
+	body := []byte(`{"id":"1"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		got, _ := io.ReadAll(r.Body)
+		if err := Verify("secret", r.Header.Get(HeaderSignature), got, time.Minute, time.Now()); err != nil {
+			t.Errorf("signature did not verify: %v", err)
+		}
+		if r.Header.Get(HeaderEvent) != EventImportCompleted || r.Header.Get(HeaderDelivery) != "d1" {
+			t.Errorf("unexpected headers: %v", r.Header)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(srv.Client(), fastRetry()).Deliver(context.Background(), srv.URL, "secret", "d1", EventImportCompleted, body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Attempts != 2 || result.StatusCode != http.StatusNoContent {
+		t.Errorf("expected 2 attempts ending in 204, got %+v", result)
+	}
+}
+
+func TestDeliver_ClientErrorIsNotRetried(t *testing.T) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusGone)
+	}))
+	defer srv.Close()
+
+	result, err := NewClient(srv.Client(), fastRetry()).Deliver(context.Background(), srv.URL, "secret", "d1", EventImportFailed, []byte(`{}`))
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if calls != 1 || result.Attempts != 1 || result.StatusCode != http.StatusGone {
+		t.Errorf("expected a single attempt ending in 410, got %d calls, %+v", calls, result)
+	}
+}
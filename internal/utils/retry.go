@@ -62,6 +62,29 @@ func FastRetryConfig() RetryConfig {
 	}
 }
 
+// WebhookRetryConfig returns a RetryConfig for delivering webhooks. Only
+// network errors, 5xx responses and 429s are retried; other 4xx responses
+// mean the receiver rejected the event.
+func WebhookRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:   5,
+		InitialDelay:  2 * time.Second,
+		MaxDelay:      30 * time.Second,
+		BackoffFactor: 2.0,
+		Timeout:       10 * time.Second,
+		RetryableErrors: []string{
+			"timeout",
+			"deadline exceeded",
+			"connection reset",
+			"connection refused",
+			"socket hang up",
+			"eof",
+			"status 5",   // 5xx responses
+			"status 429", // receiver is rate limiting
+		},
+	}
+}
+
 // IsRetryableError checks if the given error is retryable based on defined patterns.
 func IsRetryableError(err error, patterns []string) bool {
 	if err == nil {
@@ -0,0 +1,288 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/export"
+	"github.com/socialchef/remy/internal/services/importer"
+)
+
+// Account request statuses, shared by exports and erasures
+const (
+	AccountRequestQueued    = "QUEUED"
+	AccountRequestExecuting = "EXECUTING"
+	AccountRequestCompleted = "COMPLETED"
+	AccountRequestFailed    = "FAILED"
+)
+
+// HandleExportAccount builds a zip archive of everything stored about a
+// user, for a data subject access request, and stores it in the exports
+// bucket.
+func (p *RecipeProcessor) HandleExportAccount(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "export_account", status, duration)
+	}()
+
+	var payload ExportAccountPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	exp, err := p.db.GetAccountExport(ctx, parseUUID(payload.ExportID))
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("account export not found: %w", err)
+	}
+
+	p.updateAccountExport(ctx, exp.ID, AccountRequestExecuting, "", nil)
+
+	path, err := p.exportAccount(ctx, exp)
+	if err != nil {
+		status = "failure"
+		slog.Error("Account export failed", "error", err, "export_id", payload.ExportID)
+		p.updateAccountExport(ctx, exp.ID, AccountRequestFailed, "", errors.NewInternalError("Account export failed", "ACCOUNT_EXPORT_FAILED", err))
+		return err
+	}
+
+	p.updateAccountExport(ctx, exp.ID, AccountRequestCompleted, path, nil)
+	slog.Info("Account exported", "export_id", payload.ExportID)
+	return nil
+}
+
+// exportAccount writes the archive and returns its storage path.
+func (p *RecipeProcessor) exportAccount(ctx context.Context, exp generated.AccountExport) (string, error) {
+	data, err := p.db.GetAccountData(ctx, exp.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to load account data: %w", err)
+	}
+
+	ids, err := p.db.GetRecipeIDsByUser(ctx, exp.UserID)
+	if err != nil {
+		return "", fmt.Errorf("failed to list recipes: %w", err)
+	}
+
+	archive := &export.AccountArchive{
+		UserID:     pgUUIDToString(exp.UserID),
+		ExportedAt: time.Now(),
+		Records: map[string]json.RawMessage{
+			"profile":        data.Profile,
+			"plan":           data.Plan,
+			"import_jobs":    data.ImportJobs,
+			"bulk_imports":   data.BulkImports,
+			"favorites":      data.Favorites,
+			"shares":         data.Shares,
+			"recipe_exports": data.RecipeExports,
+			"webhooks":       data.Webhooks,
+			"activity":       data.Activity,
+		},
+		Recipes: make([]*export.Recipe, 0, len(ids)),
+		Images:  map[string][]byte{},
+	}
+
+	loader := export.NewLoader(p.db, func(storagePath string) string {
+		return p.storage.GetPublicURL("recipes", storagePath)
+	})
+	for _, id := range ids {
+		r, err := loader.Load(ctx, id)
+		if err != nil {
+			return "", fmt.Errorf("failed to load recipe %s: %w", pgUUIDToString(id), err)
+		}
+		archive.Recipes = append(archive.Recipes, r)
+
+		images, err := p.db.GetImagesByRecipe(ctx, id)
+		if err != nil {
+			return "", fmt.Errorf("failed to list images of recipe %s: %w", pgUUIDToString(id), err)
+		}
+		for _, image := range images {
+			if _, ok := archive.Images[image.StoragePath]; ok {
+				continue
+			}
+			blob, err := p.storage.DownloadObject(ctx, "recipes", image.StoragePath)
+			if err != nil {
+				return "", fmt.Errorf("failed to download image %s: %w", image.StoragePath, err)
+			}
+			archive.Images[image.StoragePath] = blob
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteAccountArchive(&buf, archive); err != nil {
+		return "", err
+	}
+
+	path := fmt.Sprintf("%s/account-%s.zip", pgUUIDToString(exp.UserID), pgUUIDToString(exp.ID))
+	if err := p.storage.UploadObject(ctx, export.Bucket, path, buf.Bytes(), "application/zip"); err != nil {
+		return "", fmt.Errorf("failed to upload account export: %w", err)
+	}
+	return path, nil
+}
+
+func (p *RecipeProcessor) updateAccountExport(ctx context.Context, id pgtype.UUID, status, path string, failure *errors.AppError) {
+	err := p.db.UpdateAccountExportStatus(ctx, generated.UpdateAccountExportStatusParams{
+		ID:          id,
+		Status:      status,
+		StoragePath: pgtype.Text{String: path, Valid: path != ""},
+		Error:       errorJSON(failure),
+	})
+	if err != nil {
+		slog.Error("Failed to update account export status", "error", err, "status", status)
+	}
+}
+
+// HandleEraseAccount removes a user's recipes, the images only they used,
+// their exports, pending import files and import history, favorites,
+// shares, webhooks and plan, and finally their auth user and profile.
+// Audit events are kept. The erasure row records what was removed once it
+// completes.
+//
+// Every step can be repeated, so a failed erasure is retried from the
+// start; any deletion that fails fails the attempt. The released images are
+// recorded on the erasure row, so a retry still collects them.
+func (p *RecipeProcessor) HandleEraseAccount(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "erase_account", status, duration)
+	}()
+
+	var payload EraseAccountPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	erasure, err := p.db.GetAccountErasure(ctx, parseUUID(payload.ErasureID))
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("account erasure not found: %w", err)
+	}
+	if erasure.Status == AccountRequestCompleted {
+		return nil
+	}
+
+	p.updateAccountErasure(ctx, erasure.ID, AccountRequestExecuting, nil, nil)
+
+	summary, err := p.eraseAccount(ctx, erasure)
+	if err != nil {
+		status = "failure"
+		slog.Error("Account erasure failed", "error", err, "erasure_id", payload.ErasureID)
+		p.updateAccountErasure(ctx, erasure.ID, AccountRequestFailed, nil, errors.NewInternalError("Account erasure failed", "ACCOUNT_ERASURE_FAILED", err))
+		return err
+	}
+
+	p.updateAccountErasure(ctx, erasure.ID, AccountRequestCompleted, summary, nil)
+	slog.Info("Account erased", "erasure_id", payload.ErasureID, "recipes", summary["recipes"], "images", summary["images"])
+	return nil
+}
+
+// eraseAccount does the erasure and returns the number of records and
+// images removed, by kind.
+func (p *RecipeProcessor) eraseAccount(ctx context.Context, erasure generated.AccountErasure) (map[string]int64, error) {
+	if p.auth == nil {
+		return nil, fmt.Errorf("no auth admin client configured")
+	}
+	userID := erasure.UserID
+
+	recipes, err := p.db.DeleteRecipesByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete recipes: %w", err)
+	}
+
+	hashes := erasure.ImageHashes
+	if len(recipes.ContentHashes) > 0 {
+		hashes, err = p.db.AddAccountErasureImageHashes(ctx, generated.AddAccountErasureImageHashesParams{
+			ImageHashes: recipes.ContentHashes,
+			ID:          erasure.ID,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to record released images: %w", err)
+		}
+	}
+
+	// An empty hash list would collect every unreferenced image
+	var images int
+	if len(hashes) > 0 {
+		if images, err = p.collectStoredImages(ctx, hashes); err != nil {
+			return nil, err
+		}
+	}
+
+	// Archives are deleted before their rows, so a retry still finds them
+	paths, err := p.db.ListExportPathsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list exports: %w", err)
+	}
+	for _, path := range paths {
+		if err := p.storage.DeleteObject(ctx, export.Bucket, path.String); err != nil {
+			return nil, fmt.Errorf("failed to delete export archive %s: %w", path.String, err)
+		}
+	}
+	exports, err := p.db.DeleteExportsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete exports: %w", err)
+	}
+
+	uploads, err := p.storage.ListObjects(ctx, importer.Bucket, pgUUIDToString(userID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list import files: %w", err)
+	}
+	for _, path := range uploads {
+		if err := p.storage.DeleteObject(ctx, importer.Bucket, path); err != nil {
+			return nil, fmt.Errorf("failed to delete import file %s: %w", path, err)
+		}
+	}
+
+	records, err := p.db.EraseUserRecords(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to erase records: %w", err)
+	}
+
+	// The profile goes with the auth user
+	if err := p.auth.DeleteUser(ctx, pgUUIDToString(userID)); err != nil {
+		return nil, fmt.Errorf("failed to delete auth user: %w", err)
+	}
+
+	return map[string]int64{
+		"recipes":      recipes.Recipes,
+		"images":       int64(images),
+		"exports":      int64(len(exports)),
+		"import_files": int64(len(uploads)),
+		"import_jobs":  records.ImportJobs,
+		"bulk_imports": records.BulkImports,
+		"favorites":    records.Favorites,
+		"shares":       records.Shares,
+		"webhooks":     records.Webhooks,
+		"plans":        records.Plans,
+		"profiles":     records.Profiles,
+	}, nil
+}
+
+func (p *RecipeProcessor) updateAccountErasure(ctx context.Context, id pgtype.UUID, status string, summary map[string]int64, failure *errors.AppError) {
+	var data []byte
+	if summary != nil {
+		data, _ = json.Marshal(summary)
+	}
+	err := p.db.UpdateAccountErasureStatus(ctx, generated.UpdateAccountErasureStatusParams{
+		ID:      id,
+		Status:  status,
+		Summary: data,
+		Error:   errorJSON(failure),
+	})
+	if err != nil {
+		slog.Error("Failed to update account erasure status", "error", err, "status", status)
+	}
+}
@@ -9,10 +9,36 @@ import (
 	"sync"
 )
 
+// ProgressUpdate reports the status of an import job, or of a bulk import
+// when BulkJobID is set and JobID is empty.
 type ProgressUpdate struct {
-	JobID   string `json:"job_id"`
-	Status  string `json:"status"`
-	Message string `json:"message"`
+	JobID     string `json:"job_id,omitempty"`
+	BulkJobID string `json:"bulk_job_id,omitempty"`
+	Status    string `json:"status"`
+	Message   string `json:"message"`
+	// Progress holds a bulk import's counters
+	Progress *BulkProgress `json:"progress,omitempty"`
+}
+
+type BulkProgress struct {
+	Total     int `json:"total"`
+	Processed int `json:"processed"`
+	Success   int `json:"success"`
+	Failed    int `json:"failed"`
+}
+
+// MultiBroadcaster sends each update to every broadcaster in turn and
+// returns the first error.
+type MultiBroadcaster []ProgressBroadcasterInterface
+
+func (m MultiBroadcaster) Broadcast(userID string, update ProgressUpdate) error {
+	var firstErr error
+	for _, b := range m {
+		if err := b.Broadcast(userID, update); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
 type ProgressBroadcaster struct {
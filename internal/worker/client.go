@@ -41,8 +41,9 @@ func ParseRedisURL(redisURL string) (asynq.RedisClientOpt, error) {
 	return opt, nil
 }
 
-// NewClient creates a new Asynq client for enqueueing tasks with OTel instrumentation
-func NewClient(redisURL string) *asynq.Client {
+// NewRedisClient creates a Redis client from a Redis URL, for features that
+// talk to Redis directly (e.g. caching) rather than through asynq.
+func NewRedisClient(redisURL string) *redis.Client {
 	opt, err := ParseRedisURL(redisURL)
 	if err != nil {
 		panic("failed to parse Redis URL: " + err.Error())
@@ -61,7 +62,12 @@ func NewClient(redisURL string) *asynq.Client {
 	// 	panic("failed to instrument Redis client: " + err.Error())
 	// }
 
-	return asynq.NewClientFromRedisClient(rdb)
+	return rdb
+}
+
+// NewClient creates a new Asynq client for enqueueing tasks with OTel instrumentation
+func NewClient(redisURL string) *asynq.Client {
+	return asynq.NewClientFromRedisClient(NewRedisClient(redisURL))
 }
 
 // Close closes the client connection
@@ -0,0 +1,189 @@
+package worker
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+)
+
+// Embedding document kinds. The overview document is always produced and is
+// also stored on recipes.embedding for hybrid search; the ingredients
+// document is only produced in multi-vector mode. SearchRecipesByMaxSim
+// sizes its candidate set for two kinds; update it when adding one.
+const (
+	EmbeddingKindOverview    = "overview"
+	EmbeddingKindIngredients = "ingredients"
+)
+
+// RecipeFacets holds everything an embedding document can be composed from.
+type RecipeFacets struct {
+	Name                string
+	Description         string
+	CuisineCategories   []string
+	MealTypes           []string
+	Occasions           []string
+	DietaryRestrictions []string
+	Equipment           []string
+	Parts               []generated.RecipePart
+	Ingredients         []generated.RecipeIngredient
+	Instructions        []generated.RecipeInstruction
+}
+
+// EmbeddingDocument is a piece of text to embed, tagged with its kind.
+type EmbeddingDocument struct {
+	Kind string
+	Text string
+}
+
+// EmbeddingDocumentBuilder composes structured embedding documents from
+// recipe facets. The labelled sections ("Diet: Vegan") let queries such as
+// "vegan air fryer snack" match on categories and equipment, not just on
+// the recipe name.
+type EmbeddingDocumentBuilder struct {
+	facets      map[string]bool
+	maxSteps    int
+	multiVector bool
+}
+
+// NewEmbeddingDocumentBuilder creates a builder for the given config,
+// falling back to the defaults for unset fields.
+func NewEmbeddingDocumentBuilder(cfg config.EmbeddingConfig) *EmbeddingDocumentBuilder {
+	c := config.Config{Embedding: cfg}
+	c.SetEmbeddingDefaults()
+
+	facets := make(map[string]bool, len(c.Embedding.Facets))
+	for _, f := range c.Embedding.Facets {
+		facets[f] = true
+	}
+	return &EmbeddingDocumentBuilder{
+		facets:      facets,
+		maxSteps:    c.Embedding.MaxInstructionSteps,
+		multiVector: c.Embedding.MultiVector,
+	}
+}
+
+// Build composes the embedding documents for a recipe, overview first.
+func (b *EmbeddingDocumentBuilder) Build(f RecipeFacets) []EmbeddingDocument {
+	docs := []EmbeddingDocument{{Kind: EmbeddingKindOverview, Text: b.overview(f)}}
+	if b.multiVector {
+		docs = append(docs, EmbeddingDocument{Kind: EmbeddingKindIngredients, Text: b.ingredients(f)})
+	}
+	return docs
+}
+
+func (b *EmbeddingDocumentBuilder) overview(f RecipeFacets) string {
+	var sb strings.Builder
+	writeSection(&sb, "Recipe", f.Name)
+	writeSection(&sb, "Description", f.Description)
+	if b.facets["cuisine"] {
+		writeSection(&sb, "Cuisine", strings.Join(f.CuisineCategories, ", "))
+	}
+	if b.facets["meal_types"] {
+		writeSection(&sb, "Meal type", strings.Join(f.MealTypes, ", "))
+	}
+	if b.facets["occasions"] {
+		writeSection(&sb, "Occasion", strings.Join(f.Occasions, ", "))
+	}
+	if b.facets["diet"] {
+		writeSection(&sb, "Diet", strings.Join(f.DietaryRestrictions, ", "))
+	}
+	if b.facets["equipment"] {
+		writeSection(&sb, "Equipment", strings.Join(f.Equipment, ", "))
+	}
+	if b.facets["parts"] && len(f.Parts) > 1 {
+		names := make([]string, len(f.Parts))
+		for i, p := range sortedParts(f.Parts) {
+			names[i] = p.Name
+		}
+		writeSection(&sb, "Parts", strings.Join(names, ", "))
+	}
+	if b.facets["ingredients"] {
+		writeSection(&sb, "Ingredients", strings.Join(ingredientNames(f.Ingredients), ", "))
+	}
+	if b.facets["instructions"] {
+		writeSection(&sb, "Instructions", b.instructionSteps(f.Instructions))
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// ingredients composes the ingredients document, grouping ingredients by
+// recipe part when the recipe has more than one.
+func (b *EmbeddingDocumentBuilder) ingredients(f RecipeFacets) string {
+	var sb strings.Builder
+	writeSection(&sb, "Recipe", f.Name)
+
+	if len(f.Parts) <= 1 {
+		writeSection(&sb, "Ingredients", strings.Join(ingredientNames(f.Ingredients), ", "))
+		return strings.TrimSpace(sb.String())
+	}
+
+	byPart := make(map[pgtype.UUID][]generated.RecipeIngredient)
+	var unassigned []generated.RecipeIngredient
+	for _, ing := range f.Ingredients {
+		if ing.PartID.Valid {
+			byPart[ing.PartID] = append(byPart[ing.PartID], ing)
+		} else {
+			unassigned = append(unassigned, ing)
+		}
+	}
+	for _, p := range sortedParts(f.Parts) {
+		writeSection(&sb, "Ingredients for "+p.Name, strings.Join(ingredientNames(byPart[p.ID]), ", "))
+	}
+	writeSection(&sb, "Ingredients", strings.Join(ingredientNames(unassigned), ", "))
+	return strings.TrimSpace(sb.String())
+}
+
+// instructionSteps numbers the first maxSteps instruction steps.
+func (b *EmbeddingDocumentBuilder) instructionSteps(instructions []generated.RecipeInstruction) string {
+	if len(instructions) > b.maxSteps {
+		instructions = instructions[:b.maxSteps]
+	}
+	steps := make([]string, 0, len(instructions))
+	for i, inst := range instructions {
+		text := strings.TrimSpace(inst.Instruction)
+		if text == "" {
+			continue
+		}
+		steps = append(steps, fmt.Sprintf("%d. %s", i+1, text))
+	}
+	return strings.Join(steps, " ")
+}
+
+func writeSection(sb *strings.Builder, label, value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return
+	}
+	sb.WriteString(label)
+	sb.WriteString(": ")
+	sb.WriteString(value)
+	sb.WriteString("\n")
+}
+
+// ingredientNames returns the distinct ingredient names in recipe order.
+func ingredientNames(ingredients []generated.RecipeIngredient) []string {
+	seen := make(map[string]bool, len(ingredients))
+	names := make([]string, 0, len(ingredients))
+	for _, ing := range ingredients {
+		name := strings.TrimSpace(ing.Name)
+		key := strings.ToLower(name)
+		if name == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		names = append(names, name)
+	}
+	return names
+}
+
+func sortedParts(parts []generated.RecipePart) []generated.RecipePart {
+	sorted := slices.Clone(parts)
+	slices.SortStableFunc(sorted, func(a, b generated.RecipePart) int {
+		return int(a.DisplayOrder - b.DisplayOrder)
+	})
+	return sorted
+}
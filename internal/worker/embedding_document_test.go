@@ -0,0 +1,90 @@
+package worker
+
+import (
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEmbeddingDocumentBuilder_Build(t *testing.T) {
+	dough := parseUUID("00000000-0000-0000-0000-000000000001")
+	filling := parseUUID("00000000-0000-0000-0000-000000000002")
+
+	facets := RecipeFacets{
+		Name:                "Vegan Dumplings",
+		Description:         "Steamed and crispy",
+		CuisineCategories:   []string{"Chinese"},
+		MealTypes:           []string{"Snack"},
+		DietaryRestrictions: []string{"Vegan"},
+		Equipment:           []string{"Air fryer", "Steamer"},
+		Parts: []generated.RecipePart{
+			{ID: filling, Name: "Filling", DisplayOrder: 2},
+			{ID: dough, Name: "Dough", DisplayOrder: 1},
+		},
+		Ingredients: []generated.RecipeIngredient{
+			{Name: "flour", PartID: dough},
+			{Name: "water", PartID: dough},
+			{Name: "tofu", PartID: filling},
+			{Name: "Water", PartID: filling},
+			{Name: "soy sauce", PartID: pgtype.UUID{}},
+		},
+		Instructions: []generated.RecipeInstruction{
+			{StepNumber: 1, Instruction: "Knead the dough."},
+			{StepNumber: 2, Instruction: "Fill the dumplings."},
+			{StepNumber: 3, Instruction: "Air fry until crisp."},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		cfg      config.EmbeddingConfig
+		expected []EmbeddingDocument
+	}{
+		{
+			name: "all facets",
+			cfg:  config.EmbeddingConfig{MaxInstructionSteps: 2},
+			expected: []EmbeddingDocument{{
+				Kind: EmbeddingKindOverview,
+				Text: "Recipe: Vegan Dumplings\n" +
+					"Description: Steamed and crispy\n" +
+					"Cuisine: Chinese\n" +
+					"Meal type: Snack\n" +
+					"Diet: Vegan\n" +
+					"Equipment: Air fryer, Steamer\n" +
+					"Parts: Dough, Filling\n" +
+					"Ingredients: flour, water, tofu, soy sauce\n" +
+					"Instructions: 1. Knead the dough. 2. Fill the dumplings.",
+			}},
+		},
+		{
+			name: "selected facets, multi-vector",
+			cfg:  config.EmbeddingConfig{Facets: []string{"diet", "equipment"}, MultiVector: true},
+			expected: []EmbeddingDocument{
+				{
+					Kind: EmbeddingKindOverview,
+					Text: "Recipe: Vegan Dumplings\n" +
+						"Description: Steamed and crispy\n" +
+						"Diet: Vegan\n" +
+						"Equipment: Air fryer, Steamer",
+				},
+				{
+					Kind: EmbeddingKindIngredients,
+					Text: "Recipe: Vegan Dumplings\n" +
+						"Ingredients for Dough: flour, water\n" +
+						"Ingredients for Filling: tofu, Water\n" +
+						"Ingredients: soy sauce",
+				},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			docs := NewEmbeddingDocumentBuilder(tt.cfg).Build(facets)
+			assert.Equal(t, tt.expected, docs)
+		})
+	}
+}
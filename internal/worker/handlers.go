@@ -1,8 +1,10 @@
 package worker
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	stderrors "errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -14,16 +16,23 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pgvector/pgvector-go"
+	"github.com/socialchef/remy/internal/config"
 	"github.com/socialchef/remy/internal/db/generated"
 	"github.com/socialchef/remy/internal/errors"
 	sentrylib "github.com/socialchef/remy/internal/sentry"
 	"github.com/socialchef/remy/internal/services/ai"
+	"github.com/socialchef/remy/internal/services/export"
 	"github.com/socialchef/remy/internal/services/groq"
+	"github.com/socialchef/remy/internal/services/importer"
+	"github.com/socialchef/remy/internal/services/openai"
 	"github.com/socialchef/remy/internal/services/recipe"
+	"github.com/socialchef/remy/internal/services/revision"
 	"github.com/socialchef/remy/internal/services/scraper"
 	"github.com/socialchef/remy/internal/services/storage"
+	"github.com/socialchef/remy/internal/services/webhook"
 	"github.com/socialchef/remy/internal/utils"
 	"github.com/socialchef/remy/internal/validation"
 )
@@ -31,12 +40,33 @@ import (
 type DBQueries interface {
 	CreateImportJob(ctx context.Context, arg generated.CreateImportJobParams) (generated.RecipeImportJob, error)
 	GetImportJob(ctx context.Context, id pgtype.UUID) (generated.RecipeImportJob, error)
+	GetImportJobByJobID(ctx context.Context, jobID string) (generated.RecipeImportJob, error)
 	GetImportJobsByUser(ctx context.Context, userID pgtype.UUID) ([]generated.RecipeImportJob, error)
 	UpdateImportJobStatus(ctx context.Context, arg generated.UpdateImportJobStatusParams) error
+	SetImportJobResult(ctx context.Context, arg generated.SetImportJobResultParams) error
 	CreateRecipe(ctx context.Context, arg generated.CreateRecipeParams) (generated.Recipe, error)
 	GetRecipe(ctx context.Context, id pgtype.UUID) (generated.Recipe, error)
 	UpdateRecipe(ctx context.Context, arg generated.UpdateRecipeParams) (generated.Recipe, error)
+	UpdateRecipeGeneratedFields(ctx context.Context, arg generated.UpdateRecipeGeneratedFieldsParams) error
+	RefreshRecipeIngredientNames(ctx context.Context, recipeID pgtype.UUID) error
 	CreateRecipeRawData(ctx context.Context, arg generated.CreateRecipeRawDataParams) (generated.RecipeRawDatum, error)
+	GetRecipeRawData(ctx context.Context, recipeID pgtype.UUID) (generated.RecipeRawDatum, error)
+	GetRecipeRegeneration(ctx context.Context, id pgtype.UUID) (generated.RecipeRegeneration, error)
+	UpdateRecipeRegenerationStatus(ctx context.Context, arg generated.UpdateRecipeRegenerationStatusParams) error
+	GetRecipeIDsByUser(ctx context.Context, createdBy pgtype.UUID) ([]pgtype.UUID, error)
+	GetRecipeExport(ctx context.Context, id pgtype.UUID) (generated.RecipeExport, error)
+	UpdateRecipeExportStatus(ctx context.Context, arg generated.UpdateRecipeExportStatusParams) error
+	// Account request methods
+	GetAccountExport(ctx context.Context, id pgtype.UUID) (generated.AccountExport, error)
+	UpdateAccountExportStatus(ctx context.Context, arg generated.UpdateAccountExportStatusParams) error
+	GetAccountData(ctx context.Context, userID pgtype.UUID) (generated.GetAccountDataRow, error)
+	GetAccountErasure(ctx context.Context, id pgtype.UUID) (generated.AccountErasure, error)
+	UpdateAccountErasureStatus(ctx context.Context, arg generated.UpdateAccountErasureStatusParams) error
+	DeleteRecipesByUser(ctx context.Context, createdBy pgtype.UUID) (generated.DeleteRecipesByUserRow, error)
+	ListExportPathsByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.Text, error)
+	DeleteExportsByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.Text, error)
+	AddAccountErasureImageHashes(ctx context.Context, arg generated.AddAccountErasureImageHashesParams) ([]string, error)
+	EraseUserRecords(ctx context.Context, userID pgtype.UUID) (generated.EraseUserRecordsRow, error)
 	CreateIngredient(ctx context.Context, arg generated.CreateIngredientParams) (generated.RecipeIngredient, error)
 	CreateInstruction(ctx context.Context, arg generated.CreateInstructionParams) (generated.RecipeInstruction, error)
 	UpdateInstructionRich(ctx context.Context, arg generated.UpdateInstructionRichParams) error
@@ -44,11 +74,22 @@ type DBQueries interface {
 	CreateInstructionIngredient(ctx context.Context, arg generated.CreateInstructionIngredientParams) (generated.InstructionIngredient, error)
 	GetIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipeIngredient, error)
 	GetInstructionsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipeInstruction, error)
+	GetInstructionIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.InstructionIngredient, error)
+	GetNutritionByRecipe(ctx context.Context, recipeID pgtype.UUID) (generated.RecipeNutrition, error)
+	GetImagesByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.StoredImage, error)
+	DeleteIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) error
+	DeleteInstructionsByRecipe(ctx context.Context, recipeID pgtype.UUID) error
+	DeleteNutritionByRecipe(ctx context.Context, recipeID pgtype.UUID) error
 	DeleteOldImportJobs(ctx context.Context) error
 	DeleteStaleImportJobs(ctx context.Context) error
 	CreateRecipeImage(ctx context.Context, arg generated.CreateRecipeImageParams) (generated.RecipeImage, error)
+	DeleteUnreferencedStoredImages(ctx context.Context, dollar_1 []string) ([]generated.StoredImage, error)
+	RestoreStoredImage(ctx context.Context, arg generated.RestoreStoredImageParams) error
 	UpdateRecipeThumbnail(ctx context.Context, arg generated.UpdateRecipeThumbnailParams) error
 	UpdateRecipeEmbedding(ctx context.Context, arg generated.UpdateRecipeEmbeddingParams) error
+	GetRecipesNeedingEmbedding(ctx context.Context, arg generated.GetRecipesNeedingEmbeddingParams) ([]generated.GetRecipesNeedingEmbeddingRow, error)
+	UpsertRecipeEmbedding(ctx context.Context, arg generated.UpsertRecipeEmbeddingParams) error
+	DeleteStaleRecipeEmbeddings(ctx context.Context, arg generated.DeleteStaleRecipeEmbeddingsParams) error
 	GetSocialMediaOwnerByOrigin(ctx context.Context, arg generated.GetSocialMediaOwnerByOriginParams) (generated.SocialMediaOwner, error)
 	CreateSocialMediaOwner(ctx context.Context, arg generated.CreateSocialMediaOwnerParams) (generated.SocialMediaOwner, error)
 	// Category methods
@@ -67,6 +108,8 @@ type DBQueries interface {
 	GetOccasionsByUser(ctx context.Context, userID pgtype.UUID) ([]string, error)
 	GetDietaryRestrictionsByUser(ctx context.Context, userID pgtype.UUID) ([]string, error)
 	GetEquipmentByUser(ctx context.Context, userID pgtype.UUID) ([]string, error)
+	GetRecipeCategoryNames(ctx context.Context, recipeID pgtype.UUID) (generated.GetRecipeCategoryNamesRow, error)
+	ClearRecipeCategories(ctx context.Context, recipeID pgtype.UUID) error
 	CreateBulkImportJob(ctx context.Context, arg generated.CreateBulkImportJobParams) (generated.BulkImportJob, error)
 	GetBulkImportJobByJobID(ctx context.Context, jobID string) (generated.BulkImportJob, error)
 	UpdateBulkImportJobStatus(ctx context.Context, arg generated.UpdateBulkImportJobStatusParams) error
@@ -75,6 +118,13 @@ type DBQueries interface {
 	// Recipe parts methods
 	CreateRecipePart(ctx context.Context, arg generated.CreateRecipePartParams) (generated.RecipePart, error)
 	GetRecipeParts(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipePart, error)
+	DeleteRecipeParts(ctx context.Context, recipeID pgtype.UUID) error
+	// Webhook methods
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg generated.ListWebhookSubscriptionsForEventParams) ([]generated.WebhookSubscription, error)
+	CreateWebhookDelivery(ctx context.Context, arg generated.CreateWebhookDeliveryParams) (generated.WebhookDelivery, error)
+	GetWebhookSubscription(ctx context.Context, id pgtype.UUID) (generated.WebhookSubscription, error)
+	GetWebhookDelivery(ctx context.Context, id pgtype.UUID) (generated.WebhookDelivery, error)
+	UpdateWebhookDeliveryResult(ctx context.Context, arg generated.UpdateWebhookDeliveryResultParams) error
 }
 
 type InstagramScraper interface {
@@ -93,6 +143,8 @@ type FirecrawlScraper interface {
 
 type OpenAIClient interface {
 	GenerateEmbedding(ctx context.Context, text string) ([]float32, error)
+	GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error)
+	ExtractRecipeText(ctx context.Context, image []byte, contentType string) (string, error)
 }
 
 type TranscriptionClient interface {
@@ -108,6 +160,16 @@ type GroqClient interface {
 type StorageClient interface {
 	UploadImageWithHash(ctx context.Context, bucket, path, sourceURL string, data []byte) (string, error)
 	GetImageByHash(ctx context.Context, hash string) (*storage.ExistingImageResponse, error)
+	DeleteObject(ctx context.Context, bucket, path string) error
+	UploadObject(ctx context.Context, bucket, path string, data []byte, contentType string) error
+	DownloadObject(ctx context.Context, bucket, path string) ([]byte, error)
+	ListObjects(ctx context.Context, bucket, prefix string) ([]string, error)
+	GetPublicURL(bucket, path string) string
+}
+
+// AuthAdmin deletes Supabase Auth users.
+type AuthAdmin interface {
+	DeleteUser(ctx context.Context, userID string) error
 }
 
 type ProgressBroadcasterInterface interface {
@@ -128,6 +190,19 @@ type RecipeProcessor struct {
 	broadcaster   ProgressBroadcasterInterface
 	metrics       *WorkerMetrics
 	asynqClient   *asynq.Client
+	embeddingDocs *EmbeddingDocumentBuilder
+	// providers are the recipe generation clients regeneration can pick by name
+	providers map[string]GroqClient
+	webhooks  *webhook.Client
+	tx        TxBeginner
+	// fileImages fetches the image URLs named in uploaded import files
+	fileImages *http.Client
+	auth       AuthAdmin
+}
+
+// TxBeginner starts database transactions; *pgxpool.Pool satisfies it.
+type TxBeginner interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 func NewRecipeProcessor(
@@ -159,7 +234,80 @@ func NewRecipeProcessor(
 		broadcaster:   broadcaster,
 		metrics:       metrics,
 		asynqClient:   asynqClient,
+		embeddingDocs: NewEmbeddingDocumentBuilder(config.EmbeddingConfig{}),
+		webhooks:      webhook.NewClient(nil, utils.WebhookRetryConfig()),
+		fileImages:    fileImageClient(),
+	}
+}
+
+// fileImageClient returns the client image URLs from import files are
+// fetched with. The URLs come from the user, so non-public addresses are
+// refused. Redirects are followed: every connection is checked as it is
+// dialed.
+func fileImageClient() *http.Client {
+	client := webhook.NewHTTPClient(false)
+	client.CheckRedirect = nil
+	client.Timeout = 30 * time.Second
+	return client
+}
+
+// SetEmbeddingConfig replaces the default embedding document composition.
+func (p *RecipeProcessor) SetEmbeddingConfig(cfg config.EmbeddingConfig) {
+	p.embeddingDocs = NewEmbeddingDocumentBuilder(cfg)
+}
+
+// SetRecipeProviders registers the recipe generation clients a regeneration
+// can request by provider name.
+func (p *RecipeProcessor) SetRecipeProviders(providers map[string]GroqClient) {
+	p.providers = providers
+}
+
+// SetTxBeginner lets the processor make multi-statement writes atomic.
+// Regeneration in replace mode needs it.
+func (p *RecipeProcessor) SetTxBeginner(tx TxBeginner) {
+	p.tx = tx
+}
+
+// SetAuthAdmin sets the client account erasure deletes auth users with.
+// Erasures fail without it.
+func (p *RecipeProcessor) SetAuthAdmin(auth AuthAdmin) {
+	p.auth = auth
+}
+
+// withTx runs fn with queries bound to a transaction, committing if fn
+// succeeds.
+func (p *RecipeProcessor) withTx(ctx context.Context, fn func(q *generated.Queries) error) error {
+	if p.tx == nil {
+		return stderrors.New("no transaction support configured")
+	}
+	tx, err := p.tx.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(generated.New(tx)); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// SetWebhookClient replaces the client webhooks are delivered with.
+func (p *RecipeProcessor) SetWebhookClient(client *webhook.Client) {
+	p.webhooks = client
+}
+
+// recipeClient returns the client for the named provider, or the default
+// client when no provider is named.
+func (p *RecipeProcessor) recipeClient(provider string) (GroqClient, error) {
+	if provider == "" {
+		return p.groq, nil
+	}
+	client, ok := p.providers[provider]
+	if !ok {
+		return nil, fmt.Errorf("unknown recipe provider %q", provider)
 	}
+	return client, nil
 }
 
 func parseUUID(s string) pgtype.UUID {
@@ -180,30 +328,7 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		p.metrics.RecordJob(ctx, "process_recipe", status, duration)
 
 		if payload.BulkJobID != "" {
-			successCount := int32(0)
-			failedCount := int32(0)
-			if status == "success" {
-				successCount = 1
-			} else {
-				failedCount = 1
-			}
-			if err := p.db.IncrementBulkImportCounters(ctx, generated.IncrementBulkImportCountersParams{
-				JobID:        payload.BulkJobID,
-				SuccessCount: pgtype.Int4{Int32: successCount, Valid: true},
-				FailedCount:  pgtype.Int4{Int32: failedCount, Valid: true},
-			}); err != nil {
-				slog.Error("Failed to increment bulk import counters", "error", err, "bulk_job_id", payload.BulkJobID)
-			}
-
-			job, err := p.db.GetBulkImportJobByJobID(ctx, payload.BulkJobID)
-			if err == nil && job.ProcessedCount.Int32+1 >= job.TotalUrls {
-				if err := p.db.UpdateBulkImportJobStatus(ctx, generated.UpdateBulkImportJobStatusParams{
-					JobID:  payload.BulkJobID,
-					Status: "COMPLETED",
-				}); err != nil {
-					slog.Error("Failed to complete bulk import job", "error", err, "bulk_job_id", payload.BulkJobID)
-				}
-			}
+			p.recordBulkResult(ctx, payload.BulkJobID, status == "success")
 		}
 	}()
 
@@ -216,6 +341,13 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 	userID := payload.UserID
 	url := payload.URL
 
+	// Operators can cancel queued jobs from the admin API
+	if job, err := p.db.GetImportJobByJobID(ctx, jobID); err == nil && job.Status == "CANCELED" {
+		slog.Info("Import job was canceled, skipping", "job_id", jobID)
+		status = "canceled"
+		return nil
+	}
+
 	slog.Info("Processing recipe", "job_id", jobID, "url", url)
 
 	p.updateProgress(ctx, jobID, userID, "EXECUTING", "Fetching post content...")
@@ -223,13 +355,35 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 	var caption, platform, imageURL, videoURL string
 	var ownerUsername, ownerAvatar, ownerID string
 
-	if scraper.IsInstagramURL(url) {
+	var transcript string
+	var imageData []byte
+
+	if payload.IsManual() {
+		platform = "manual"
+		caption = payload.Text
+		if payload.ImageURL != "" {
+			p.updateProgress(ctx, jobID, userID, "EXECUTING", "Reading recipe from photo...")
+			imageURL = payload.ImageURL
+			data, err := downloadImage(ctx, imageURL)
+			if err != nil {
+				status = "failure"
+				return p.failAttempt(ctx, jobID, userID, errors.NewScraperError("Failed to download photo", "PHOTO_DOWNLOAD_FAILED", err), err)
+			}
+			imageData = data
+
+			text, err := p.openai.ExtractRecipeText(ctx, data, http.DetectContentType(data))
+			if err != nil {
+				status = "failure"
+				return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewRecipeGenerationError("Failed to read photo", "PHOTO_READ_FAILED", err)), err)
+			}
+			caption = strings.TrimSpace(caption + "\n\n" + text)
+		}
+	} else if scraper.IsInstagramURL(url) {
 		platform = "instagram"
 		post, err := p.instagram.Scrape(ctx, url)
 		if err != nil {
 			status = "failure"
-			p.markFailed(ctx, jobID, userID, fmt.Sprintf("Instagram scrape failed: %v", err))
-			return err
+			return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewScraperError("Instagram scrape failed", "INSTAGRAM_SCRAPE_FAILED", err)), err)
 		}
 		caption = post.Caption
 		imageURL = post.ImageURL
@@ -243,8 +397,7 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		post, err := p.tiktok.Scrape(ctx, url)
 		if err != nil {
 			status = "failure"
-			p.markFailed(ctx, jobID, userID, fmt.Sprintf("TikTok scrape failed: %v", err))
-			return err
+			return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewScraperError("TikTok scrape failed", "TIKTOK_SCRAPE_FAILED", err)), err)
 		}
 		caption = post.Caption
 		imageURL = post.ThumbnailURL
@@ -258,8 +411,7 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		post, err := p.youtube.Scrape(ctx, url)
 		if err != nil {
 			status = "failure"
-			p.markFailed(ctx, jobID, userID, fmt.Sprintf("YouTube scrape failed: %v", err))
-			return err
+			return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewScraperError("YouTube scrape failed", "YOUTUBE_SCRAPE_FAILED", err)), err)
 		}
 		caption = post.Caption
 		imageURL = post.ThumbnailURL
@@ -274,12 +426,11 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		post, err := p.firecrawl.Scrape(ctx, url)
 		if err != nil {
 			if err == scraper.ErrUnsupportedSite {
-				p.markCanceled(ctx, jobID, userID, "This website is not supported for recipe import")
+				p.markCanceled(ctx, jobID, userID, errors.NewCanceledError("This website is not supported for recipe import", "UNSUPPORTED_SITE"))
 				return nil
 			}
 			status = "failure"
-			p.markFailed(ctx, jobID, userID, fmt.Sprintf("Firecrawl scrape failed: %v", err))
-			return err
+			return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewScraperError("Website scrape failed", "FIRECRAWL_SCRAPE_FAILED", err)), err)
 		}
 		caption = post.Caption
 		imageURL = post.ImageURL
@@ -290,24 +441,19 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 	} else {
 		// Firecrawl not enabled
 		status = "failure"
-		p.markFailed(ctx, jobID, userID, "Invalid URL: must be Instagram or TikTok (Firecrawl not enabled)")
-		return fmt.Errorf("invalid URL: Firecrawl not enabled")
+		return p.failAttempt(ctx, jobID, userID, errors.NewValidationError("Invalid URL: must be Instagram, TikTok or YouTube", "UNSUPPORTED_URL", "Import from Instagram, TikTok or YouTube."), fmt.Errorf("invalid URL: Firecrawl not enabled"))
 	}
 
 	validationResult := validation.QuickValidate(caption, "")
 	if !validationResult.IsValid {
 		status = "failure"
-		errMsg := fmt.Sprintf("Content validation failed: %s", validationResult.Reason)
-		p.markFailed(ctx, jobID, userID, errMsg)
-		return errors.NewValidationError(errMsg, "CONTENT_NOT_RECIPE", "")
+		appErr := errors.NewValidationError(fmt.Sprintf("Content validation failed: %s", validationResult.Reason), "CONTENT_NOT_RECIPE", "")
+		return p.failAttempt(ctx, jobID, userID, appErr, appErr)
 	}
 	slog.Info("Content validation passed", "confidence", string(validationResult.Confidence), "reason", validationResult.Reason)
 
-	var transcript string
-	var imageData []byte
-
 	// Run transcription and image download in parallel
-	if videoURL != "" || imageURL != "" {
+	if videoURL != "" || (imageURL != "" && imageData == nil) {
 		p.updateProgress(ctx, jobID, userID, "EXECUTING", "Processing video and image content...")
 
 		funcs := []ParallelFunc{}
@@ -325,7 +471,7 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		}
 
 		// Add image download function if imageURL exists
-		if imageURL != "" {
+		if imageURL != "" && imageData == nil {
 			funcs = append(funcs, func(ctx context.Context) error {
 				data, err := downloadImage(ctx, imageURL)
 				if err != nil {
@@ -344,8 +490,7 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 			// Check if this error is from transcription (videoURL != "" and we have a transcript error)
 			if videoURL != "" && err != nil && transcript == "" {
 				status = "failure"
-				p.markFailed(ctx, jobID, userID, fmt.Sprintf("Transcription failed: %v", err))
-				return err
+				return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewTranscriptionError("Transcription failed", "TRANSCRIPTION_FAILED", err)), err)
 			}
 		}
 
@@ -357,67 +502,22 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 	recipe, err := p.groq.GenerateRecipe(ctx, caption, transcript, platform)
 	if err != nil {
 		status = "failure"
-		p.markFailed(ctx, jobID, userID, fmt.Sprintf("Recipe generation failed: %v", err))
-		return err
+		return p.failAttempt(ctx, jobID, userID, errors.FromError(err, errors.NewRecipeGenerationError("Recipe generation failed", "RECIPE_GENERATION_FAILED", err)), err)
 	}
 
 	p.updateProgress(ctx, jobID, userID, "EXECUTING", "Generating categories with AI...")
 
-	categoryService := ai.NewCategoryService(p.db, p.groq)
-	categories, err := utils.WithRetry(ctx, func(ctx context.Context) (*ai.CategorySuggestions, error) {
-		return categoryService.SuggestCategories(ctx, ai.RecipeInfo{
-			Name:        recipe.RecipeName,
-			Description: recipe.Description,
-			Ingredients: extractIngredientNames(recipe.Ingredients),
-		}, userID)
-	}, utils.DefaultRetryConfig())
-	if err == nil {
-		recipe.CuisineCategories = categories.CuisineCategories
-		recipe.MealTypes = categories.MealTypes
-		recipe.Occasions = categories.Occasions
-		recipe.DietaryRestrictions = categories.DietaryRestrictions
-		recipe.Equipment = categories.Equipment
-	} else {
-		slog.Error("Category generation failed after retries", "error", err, "recipe_name", recipe.RecipeName)
-		sentrylib.CaptureError(err, map[string]string{
-			"recipe_name": recipe.RecipeName,
-			"component":   "category_generation",
-		})
-	}
-
-	validationConfig := validation.RecipeOutputValidationConfig{
-		MinIngredients:      2,
-		MinInstructions:     2,
-		MaxPlaceholderRatio: 0.2,
-	}
-
-	vRecipe := validation.Recipe{
-		RecipeName:          recipe.RecipeName,
-		Description:         recipe.Description,
-		PrepTime:            recipe.PrepTime,
-		CookingTime:         recipe.CookingTime,
-		TotalTime:           recipe.TotalTime,
-		OriginalServings:    recipe.OriginalServings,
-		DifficultyRating:    recipe.DifficultyRating,
-		FocusedDiet:         recipe.FocusedDiet,
-		EstimatedCalories:   recipe.EstimatedCalories,
-		Ingredients:         convertIngredients(recipe.Ingredients),
-		Instructions:        convertInstructions(recipe.Instructions),
-		Nutrition:           convertNutrition(recipe.Nutrition),
-		CuisineCategories:   recipe.CuisineCategories,
-		MealTypes:           recipe.MealTypes,
-		Occasions:           recipe.Occasions,
-		DietaryRestrictions: recipe.DietaryRestrictions,
-		Equipment:           recipe.Equipment,
-		Language:            recipe.Language,
-	}
+	p.applySuggestedCategories(ctx, p.groq, recipe, userID)
 
-	result := validation.ValidateRecipe(vRecipe, validationConfig)
+	result := validateGeneratedRecipe(recipe)
 	if !result.IsValid {
 		status = "failure"
-		errMsg := fmt.Sprintf("Recipe validation failed (quality score: %d): %s", result.QualityScore, strings.Join(result.Issues, ", "))
-		p.markFailed(ctx, jobID, userID, errMsg)
-		return errors.NewValidationError(errMsg, "LOW_QUALITY_RECIPE", "Try providing a more detailed video or transcript.")
+		appErr := errors.NewValidationError(
+			fmt.Sprintf("Recipe validation failed (quality score: %d): %s", result.QualityScore, strings.Join(result.Issues, ", ")),
+			"LOW_QUALITY_RECIPE",
+			"Try providing a more detailed video or transcript.",
+		)
+		return p.failAttempt(ctx, jobID, userID, appErr, appErr)
 	}
 	slog.Info("Recipe validation passed", "quality_score", result.QualityScore, "has_placeholders", result.HasPlaceholders)
 
@@ -480,6 +580,8 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		origin = generated.RecipeOriginTiktok
 	} else if platform == "youtube" {
 		origin = generated.RecipeOrigin("youtube")
+	} else if platform == "manual" {
+		origin = generated.RecipeOriginManual
 	} else {
 		origin = generated.RecipeOriginFirecrawl
 	}
@@ -504,8 +606,7 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 	})
 	if err != nil {
 		status = "failure"
-		p.markFailed(ctx, jobID, userID, fmt.Sprintf("Failed to save recipe: %v", err))
-		return err
+		return p.failAttempt(ctx, jobID, userID, errors.NewInternalError("Failed to save recipe", "RECIPE_SAVE_FAILED", err), err)
 	}
 
 	// Save raw data for comparison testing
@@ -544,223 +645,35 @@ func (p *RecipeProcessor) HandleProcessRecipe(ctx context.Context, t *asynq.Task
 		slog.Error("Failed to save raw data (non-critical)", "error", rawDataErr, "recipe_id", savedRecipe.ID)
 	}
 
-	// Save categories
-	cuisineSaved := 0
-	for _, cat := range recipe.CuisineCategories {
-		catID, err := p.db.GetOrCreateCuisineCategory(ctx, cat)
-		if err == nil {
-			err = p.db.AddRecipeCuisineCategory(ctx, generated.AddRecipeCuisineCategoryParams{
-				RecipeID:          savedRecipe.ID,
-				CuisineCategoryID: catID,
-			})
-			if err == nil {
-				cuisineSaved++
-			}
-		}
-	}
-	if cuisineSaved == 0 {
-		slog.Error("No cuisine categories persisted for recipe", "recipe_id", savedRecipe.ID, "recipe_name", recipe.RecipeName)
-		sentrylib.CaptureError(fmt.Errorf("no cuisine categories persisted for recipe %s", recipe.RecipeName), map[string]string{
-			"recipe_name": recipe.RecipeName,
-			"component":   "category_persistence",
-		})
-	}
-
-	for _, mt := range recipe.MealTypes {
-		mtID, err := p.db.GetOrCreateMealType(ctx, mt)
-		if err == nil {
-			p.db.AddRecipeMealType(ctx, generated.AddRecipeMealTypeParams{
-				RecipeID:   savedRecipe.ID,
-				MealTypeID: mtID,
-			})
-		}
-	}
-
-	for _, occ := range recipe.Occasions {
-		occID, err := p.db.GetOrCreateOccasion(ctx, occ)
-		if err == nil {
-			p.db.AddRecipeOccasion(ctx, generated.AddRecipeOccasionParams{
-				RecipeID:   savedRecipe.ID,
-				OccasionID: occID,
-			})
-		}
-	}
-
-	for _, dr := range recipe.DietaryRestrictions {
-		drID, err := p.db.GetOrCreateDietaryRestriction(ctx, dr)
-		if err == nil {
-			p.db.AddRecipeDietaryRestriction(ctx, generated.AddRecipeDietaryRestrictionParams{
-				RecipeID:             savedRecipe.ID,
-				DietaryRestrictionID: drID,
-			})
-		}
-	}
-
-	for _, eq := range recipe.Equipment {
-		eqID, err := p.db.GetOrCreateEquipment(ctx, eq)
-		if err == nil {
-			p.db.AddRecipeEquipment(ctx, generated.AddRecipeEquipmentParams{
-				RecipeID:    savedRecipe.ID,
-				EquipmentID: eqID,
-			})
-		}
+	if err := saveRecipeCategories(ctx, p.db, savedRecipe.ID, recipe); err != nil {
+		slog.Error("Failed to save some recipe categories", "error", err, "recipe_id", pgUUIDToString(savedRecipe.ID))
 	}
 
-	var savedIngredientIDs []string
-	var savedInstructions []generated.RecipeInstruction
-	var allIngredients []groq.Ingredient
-	var allInstructions []groq.Instruction
-
 	if recipe.HasParts() {
 		p.updateProgress(ctx, jobID, userID, "EXECUTING", "Saving recipe parts...")
-
-		for partIndex, part := range recipe.Parts {
-			var prepTime, cookTime pgtype.Int4
-			if part.PrepTime != nil {
-				prepTime = pgtype.Int4{Int32: int32(*part.PrepTime), Valid: true}
-			}
-			if part.CookingTime != nil {
-				cookTime = pgtype.Int4{Int32: int32(*part.CookingTime), Valid: true}
-			}
-
-			savedPart, err := p.db.CreateRecipePart(ctx, generated.CreateRecipePartParams{
-				RecipeID:     savedRecipe.ID,
-				Name:         part.Name,
-				Description:  pgtype.Text{String: part.Description, Valid: part.Description != ""},
-				DisplayOrder: int32(partIndex),
-				IsOptional:   part.IsOptional,
-				PrepTime:     prepTime,
-				CookingTime:  cookTime,
-			})
-			if err != nil {
-				slog.Error("Failed to save recipe part", "error", err, "part_name", part.Name)
-				continue
-			}
-
-			partIngredientIDs, err := p.saveIngredients(ctx, savedRecipe.ID, savedPart.ID, part.Ingredients, recipe.OriginalServings)
-			if err != nil {
-				slog.Error("Failed to save ingredients for part", "error", err, "part_name", part.Name)
-			}
-			savedIngredientIDs = append(savedIngredientIDs, partIngredientIDs...)
-			allIngredients = append(allIngredients, part.Ingredients...)
-
-			partInstructions, err := p.saveInstructions(ctx, savedRecipe.ID, savedPart.ID, part.Instructions, 1)
-			if err != nil {
-				slog.Error("Failed to save instructions for part", "error", err, "part_name", part.Name)
-			}
-			savedInstructions = append(savedInstructions, partInstructions...)
-			allInstructions = append(allInstructions, part.Instructions...)
-		}
-	} else {
-		var err error
-		savedIngredientIDs, err = p.saveIngredients(ctx, savedRecipe.ID, pgtype.UUID{}, recipe.Ingredients, recipe.OriginalServings)
-		if err != nil {
-			slog.Error("Failed to save ingredients", "error", err)
-		}
-
-		savedInstructions, err = p.saveInstructions(ctx, savedRecipe.ID, pgtype.UUID{}, recipe.Instructions, 1)
-		if err != nil {
-			slog.Error("Failed to save instructions", "error", err)
-		}
-
-		allIngredients = recipe.Ingredients
-		allInstructions = recipe.Instructions
-	}
-
-	if err := p.saveInstructionIngredients(ctx, savedInstructions, savedIngredientIDs, allIngredients, allInstructions); err != nil {
-		slog.Warn("Failed to save instruction-ingredient junction entries", "error", err, "recipe_name", recipe.RecipeName)
 	}
+	savedIngredientIDs, savedInstructions := p.saveRecipeContent(ctx, savedRecipe.ID, recipe)
 
 	p.updateProgress(ctx, jobID, userID, "EXECUTING", "Generating rich instruction formatting...")
 
-	for i := range recipe.Ingredients {
-		if i < len(savedIngredientIDs) && savedIngredientIDs[i] != "" {
-			recipe.Ingredients[i].ID = savedIngredientIDs[i]
-		}
-	}
-
-	richResp, err := p.groq.GenerateRichInstructions(ctx, recipe)
-	if err != nil {
-		slog.Warn("Failed to generate rich instructions, enqueueing retry", "error", err, "recipe_name", recipe.RecipeName)
-		p.enqueueRichInstructionsRetry(ctx, pgUUIDToString(savedRecipe.ID))
-	} else if richResp != nil {
-		for i, inst := range richResp.Instructions {
-			if i < len(savedInstructions) {
-				err := p.db.UpdateInstructionRich(ctx, generated.UpdateInstructionRichParams{
-					InstructionRich:        pgtype.Text{String: inst.InstructionRich, Valid: inst.InstructionRich != ""},
-					InstructionRichVersion: pgtype.Int4{Int32: int32(richResp.PromptVersion), Valid: richResp.PromptVersion > 0},
-					ID:                     savedInstructions[i].ID,
-				})
-				if err != nil {
-					slog.Error("Failed to update instruction with rich text", "error", err, "step", i+1)
-				}
-			}
-		}
-	}
-
-	if recipe.Nutrition.Protein > 0 || recipe.Nutrition.Carbs > 0 {
-		_, err := p.db.CreateNutrition(ctx, generated.CreateNutritionParams{
-			RecipeID: savedRecipe.ID,
-			Protein:  pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Protein * 100)), Exp: -2, Valid: true},
-			Carbs:    pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Carbs * 100)), Exp: -2, Valid: true},
-			Fat:      pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Fat * 100)), Exp: -2, Valid: true},
-			Fiber:    pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Fiber * 100)), Exp: -2, Valid: true},
-		})
-		if err != nil {
-			slog.Error("Failed to save nutrition", "error", err)
-		}
+	p.saveRichInstructions(ctx, p.groq, savedRecipe.ID, recipe, savedIngredientIDs, savedInstructions)
+	if err := saveNutrition(ctx, p.db, savedRecipe.ID, recipe); err != nil {
+		slog.Error("Failed to save nutrition", "error", err, "recipe_id", pgUUIDToString(savedRecipe.ID))
 	}
 
 	if imageURL != "" && imageData != nil {
 		p.updateProgress(ctx, jobID, userID, "EXECUTING", "Processing recipe image...")
-
-		hash := storage.HashContent(imageData)
-		path := fmt.Sprintf("post_images/%s", hash)
-		_, err := p.storage.UploadImageWithHash(ctx, "recipes", path, imageURL, imageData)
-		if err != nil {
-			slog.Error("Failed to upload image", "error", err)
-		} else {
-			existing, err := p.storage.GetImageByHash(ctx, hash)
-			if err != nil || existing == nil {
-				slog.Error("Failed to get stored image after upload", "error", err)
-			} else {
-				storedImageUUID := parseUUID(existing.ID)
-
-				recipeImage, err := p.db.CreateRecipeImage(ctx, generated.CreateRecipeImageParams{
-					RecipeID:      savedRecipe.ID,
-					StoredImageID: storedImageUUID,
-					ImageType:     "full",
-				})
-				if err != nil {
-					slog.Error("Failed to create recipe image record", "error", err)
-				} else {
-					err = p.db.UpdateRecipeThumbnail(ctx, generated.UpdateRecipeThumbnailParams{
-						ID:          savedRecipe.ID,
-						ThumbnailID: recipeImage.ID,
-					})
-					if err != nil {
-						slog.Error("Failed to update recipe thumbnail", "error", err)
-					}
-				}
-			}
-		}
-	}
-	// Enqueue embedding generation task
-	if p.asynqClient != nil {
-		embedTask, err := NewGenerateEmbeddingTask(GenerateEmbeddingPayload{
-			RecipeID: pgUUIDToString(savedRecipe.ID),
-		})
-		if err == nil {
-			_, err = p.asynqClient.Enqueue(embedTask)
-			if err != nil {
-				slog.Error("Failed to enqueue embedding task", "error", err)
-			} else {
-				slog.Info("Enqueued embedding task", "recipe_id", pgUUIDToString(savedRecipe.ID))
-			}
-		}
+		p.saveRecipeImage(ctx, savedRecipe.ID, imageURL, imageData)
 	}
+	p.enqueueEmbedding(pgUUIDToString(savedRecipe.ID))
 
 	p.updateProgress(ctx, jobID, userID, "COMPLETED", "Recipe saved successfully!")
+	p.emitWebhookEvent(ctx, userID, webhook.EventImportCompleted, webhook.ImportData{
+		JobID:     jobID,
+		URL:       url,
+		RecipeID:  pgUUIDToString(savedRecipe.ID),
+		BulkJobID: payload.BulkJobID,
+	})
 
 	return nil
 }
@@ -786,23 +699,19 @@ func (p *RecipeProcessor) HandleGenerateEmbedding(ctx context.Context, t *asynq.
 		return fmt.Errorf("recipe not found: %w", err)
 	}
 
-	text := recipe.RecipeName + " " + recipe.Description.String
-	ingredients, _ := p.db.GetIngredientsByRecipe(ctx, recipe.ID)
-	for _, ing := range ingredients {
-		text += " " + ing.Name
+	docs := p.embeddingDocs.Build(p.loadRecipeFacets(ctx, recipe.ID, recipe.RecipeName, recipe.Description.String))
+	texts := make([]string, len(docs))
+	for i, doc := range docs {
+		texts[i] = doc.Text
 	}
 
-	embedding, err := p.openai.GenerateEmbedding(ctx, text)
+	embeddings, err := p.openai.GenerateEmbeddings(ctx, texts)
 	if err != nil {
 		status = "failure"
 		return fmt.Errorf("failed to generate embedding: %w", err)
 	}
 
-	err = p.db.UpdateRecipeEmbedding(ctx, generated.UpdateRecipeEmbeddingParams{
-		ID:        recipe.ID,
-		Embedding: ptrVector(pgvector.NewVector(embedding)),
-	})
-	if err != nil {
+	if err := p.saveRecipeEmbeddings(ctx, recipe.ID, docs, embeddings); err != nil {
 		status = "failure"
 		return fmt.Errorf("failed to save embedding: %w", err)
 	}
@@ -811,34 +720,317 @@ func (p *RecipeProcessor) HandleGenerateEmbedding(ctx context.Context, t *asynq.
 	return nil
 }
 
-func (p *RecipeProcessor) HandleCleanupJobs(ctx context.Context, t *asynq.Task) error {
+// EmbeddingVersion identifies the document composition in
+// EmbeddingDocumentBuilder. Bump it whenever that composition changes so the
+// backfill task re-embeds every recipe.
+const EmbeddingVersion = 2
+
+// Embedding backfill defaults, used when the task payload leaves them unset.
+const (
+	defaultEmbeddingBackfillBatchSize  = 100
+	defaultEmbeddingBackfillMaxBatches = 10
+	defaultEmbeddingBackfillRPM        = 60
+)
+
+// HandleBackfillEmbeddings re-embeds recipes whose embedding is missing or
+// outdated (different model or EmbeddingVersion). It pages through recipes
+// in id order, one batched embeddings request per page, paced to stay under
+// the requests-per-minute budget. After MaxBatches pages it hands off to a
+// continuation task so a single run never holds a worker for long.
+func (p *RecipeProcessor) HandleBackfillEmbeddings(ctx context.Context, t *asynq.Task) error {
 	start := time.Now()
 	var status = "success"
 	defer func() {
 		duration := time.Since(start).Seconds()
-		p.metrics.RecordJob(ctx, "cleanup_jobs", status, duration)
+		p.metrics.RecordJob(ctx, "backfill_embeddings", status, duration)
 	}()
 
-	slog.Info("Running cleanup job")
-
-	err := p.db.DeleteOldImportJobs(ctx)
-	if err != nil {
-		status = "failure"
-		slog.Error("Failed to delete old import jobs", "error", err)
-		return err
+	var payload BackfillEmbeddingsPayload
+	if len(t.Payload()) > 0 {
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			status = "failure"
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
 	}
-
-	err = p.db.DeleteStaleImportJobs(ctx)
-	if err != nil {
-		status = "failure"
-		slog.Error("Failed to delete stale import jobs", "error", err)
-		return err
+	if payload.BatchSize <= 0 {
+		payload.BatchSize = defaultEmbeddingBackfillBatchSize
+	}
+	if payload.MaxBatches <= 0 {
+		payload.MaxBatches = defaultEmbeddingBackfillMaxBatches
+	}
+	if payload.RequestsPerMinute <= 0 {
+		payload.RequestsPerMinute = defaultEmbeddingBackfillRPM
+	}
+	interval := time.Minute / time.Duration(payload.RequestsPerMinute)
+
+	// The zero UUID sorts before every real id
+	cursor := pgtype.UUID{Valid: true}
+	if payload.AfterID != "" {
+		cursor = parseUUID(payload.AfterID)
+		if !cursor.Valid {
+			status = "failure"
+			return fmt.Errorf("invalid after_id: %s", payload.AfterID)
+		}
+	}
+
+	embedded := 0
+	for batch := 0; batch < payload.MaxBatches; batch++ {
+		if batch > 0 {
+			select {
+			case <-ctx.Done():
+				status = "failure"
+				return ctx.Err()
+			case <-time.After(interval):
+			}
+		}
+
+		recipes, err := p.db.GetRecipesNeedingEmbedding(ctx, generated.GetRecipesNeedingEmbeddingParams{
+			ID:      cursor,
+			Column2: openai.EmbeddingModel,
+			Column3: EmbeddingVersion,
+			Limit:   int32(payload.BatchSize),
+		})
+		if err != nil {
+			status = "failure"
+			return fmt.Errorf("failed to get recipes needing embeddings: %w", err)
+		}
+		if len(recipes) == 0 {
+			slog.Info("Embedding backfill completed", "embedded", embedded)
+			return nil
+		}
+
+		saved, err := p.embedRecipeBatch(ctx, recipes)
+		if err != nil {
+			status = "failure"
+			return err
+		}
+		embedded += saved
+		cursor = recipes[len(recipes)-1].ID
+
+		if len(recipes) < payload.BatchSize {
+			slog.Info("Embedding backfill completed", "embedded", embedded)
+			return nil
+		}
+	}
+
+	// More recipes may remain; continue from the cursor in a fresh task
+	payload.AfterID = pgUUIDToString(cursor)
+	next, err := NewBackfillEmbeddingsTask(payload, asynq.ProcessIn(interval))
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to create continuation task: %w", err)
+	}
+	if p.asynqClient == nil {
+		slog.Warn("Embedding backfill paused: no asynq client to enqueue continuation", "after_id", payload.AfterID)
+		return nil
+	}
+	if _, err := p.asynqClient.Enqueue(next); stderrors.Is(err, asynq.ErrDuplicateTask) {
+		slog.Info("Embedding backfill already continues from this cursor", "embedded", embedded, "after_id", payload.AfterID)
+		return nil
+	} else if err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to enqueue continuation task: %w", err)
+	}
+
+	slog.Info("Embedding backfill batch limit reached, continuing", "embedded", embedded, "after_id", payload.AfterID)
+	return nil
+}
+
+// embedRecipeBatch embeds a page of recipes with a single API request and
+// saves the results. Recipes that fail to save are logged and left for the
+// next backfill run; the number saved is returned.
+func (p *RecipeProcessor) embedRecipeBatch(ctx context.Context, recipes []generated.GetRecipesNeedingEmbeddingRow) (int, error) {
+	docs := make([][]EmbeddingDocument, len(recipes))
+	var texts []string
+	for i, r := range recipes {
+		docs[i] = p.embeddingDocs.Build(p.loadRecipeFacets(ctx, r.ID, r.RecipeName, r.Description.String))
+		for _, doc := range docs[i] {
+			texts = append(texts, doc.Text)
+		}
+	}
+
+	embeddings, err := p.openai.GenerateEmbeddings(ctx, texts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to generate embeddings: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return 0, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(embeddings))
+	}
+
+	saved := 0
+	offset := 0
+	for i, r := range recipes {
+		recipeEmbeddings := embeddings[offset : offset+len(docs[i])]
+		offset += len(docs[i])
+		if err := p.saveRecipeEmbeddings(ctx, r.ID, docs[i], recipeEmbeddings); err != nil {
+			slog.Error("Failed to save backfilled embedding", "recipe_id", pgUUIDToString(r.ID), "error", err)
+			continue
+		}
+		saved++
+	}
+	return saved, nil
+}
+
+// loadRecipeFacets gathers the categories, parts, ingredients and
+// instructions an embedding document is composed from. Facets that fail to
+// load are left empty rather than failing the embedding.
+func (p *RecipeProcessor) loadRecipeFacets(ctx context.Context, recipeID pgtype.UUID, name, description string) RecipeFacets {
+	facets := RecipeFacets{Name: name, Description: description}
+	if categories, err := p.db.GetRecipeCategoryNames(ctx, recipeID); err == nil {
+		facets.CuisineCategories = categories.CuisineCategories
+		facets.MealTypes = categories.MealTypes
+		facets.Occasions = categories.Occasions
+		facets.DietaryRestrictions = categories.DietaryRestrictions
+		facets.Equipment = categories.Equipment
+	}
+	facets.Parts, _ = p.db.GetRecipeParts(ctx, recipeID)
+	facets.Ingredients, _ = p.db.GetIngredientsByRecipe(ctx, recipeID)
+	facets.Instructions, _ = p.db.GetInstructionsByRecipe(ctx, recipeID)
+	return facets
+}
+
+// saveRecipeEmbeddings stores the overview embedding on the recipe and every
+// document embedding in recipe_embeddings, dropping kinds the current config
+// no longer produces.
+func (p *RecipeProcessor) saveRecipeEmbeddings(ctx context.Context, recipeID pgtype.UUID, docs []EmbeddingDocument, embeddings [][]float32) error {
+	if len(embeddings) != len(docs) {
+		return fmt.Errorf("expected %d embeddings, got %d", len(docs), len(embeddings))
+	}
+
+	if err := p.db.UpdateRecipeEmbedding(ctx, embeddingParams(recipeID, embeddings[0])); err != nil {
+		return err
+	}
+
+	kinds := make([]string, len(docs))
+	for i, doc := range docs {
+		kinds[i] = doc.Kind
+		err := p.db.UpsertRecipeEmbedding(ctx, generated.UpsertRecipeEmbeddingParams{
+			RecipeID:  recipeID,
+			Kind:      doc.Kind,
+			Embedding: pgvector.NewVector(embeddings[i]),
+			Model:     openai.EmbeddingModel,
+			Version:   EmbeddingVersion,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to save %s embedding: %w", doc.Kind, err)
+		}
+	}
+
+	return p.db.DeleteStaleRecipeEmbeddings(ctx, generated.DeleteStaleRecipeEmbeddingsParams{
+		RecipeID: recipeID,
+		Column2:  kinds,
+	})
+}
+
+// embeddingParams builds the update that stores an embedding together with
+// the model and composition version that produced it.
+func embeddingParams(recipeID pgtype.UUID, embedding []float32) generated.UpdateRecipeEmbeddingParams {
+	return generated.UpdateRecipeEmbeddingParams{
+		ID:               recipeID,
+		Embedding:        ptrVector(pgvector.NewVector(embedding)),
+		EmbeddingModel:   pgtype.Text{String: openai.EmbeddingModel, Valid: true},
+		EmbeddingVersion: pgtype.Int4{Int32: EmbeddingVersion, Valid: true},
+	}
+}
+
+func (p *RecipeProcessor) HandleCleanupJobs(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "cleanup_jobs", status, duration)
+	}()
+
+	slog.Info("Running cleanup job")
+
+	err := p.db.DeleteOldImportJobs(ctx)
+	if err != nil {
+		status = "failure"
+		slog.Error("Failed to delete old import jobs", "error", err)
+		return err
+	}
+
+	err = p.db.DeleteStaleImportJobs(ctx)
+	if err != nil {
+		status = "failure"
+		slog.Error("Failed to delete stale import jobs", "error", err)
+		return err
 	}
 
 	slog.Info("Cleanup job completed successfully")
 	return nil
 }
 
+// HandleGarbageCollectStorage deletes stored images that no recipe or social
+// media owner references any more, then removes their blobs from storage.
+// The stored_images rows are deleted first so an image re-used by a
+// concurrent import is never left pointing at a missing blob; a row whose
+// blob could not be deleted is put back and the task retried.
+func (p *RecipeProcessor) HandleGarbageCollectStorage(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "gc_storage", status, duration)
+	}()
+
+	var payload GarbageCollectStoragePayload
+	if len(t.Payload()) > 0 {
+		if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+			status = "failure"
+			return fmt.Errorf("failed to unmarshal payload: %w", err)
+		}
+	}
+	if payload.ContentHashes == nil {
+		payload.ContentHashes = []string{}
+	}
+
+	deleted, err := p.collectStoredImages(ctx, payload.ContentHashes)
+	if err != nil {
+		status = "failure"
+		return err
+	}
+
+	slog.Info("Storage garbage collection completed", "deleted", deleted)
+	return nil
+}
+
+// collectStoredImages deletes the unreferenced stored images among
+// contentHashes, or all of them when it is empty, and their blobs, and
+// returns how many were deleted. An image whose blob could not be deleted
+// is put back, and an error returned so the task is retried.
+func (p *RecipeProcessor) collectStoredImages(ctx context.Context, contentHashes []string) (int, error) {
+	images, err := p.db.DeleteUnreferencedStoredImages(ctx, contentHashes)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete unreferenced images: %w", err)
+	}
+
+	var errs []error
+	for _, image := range images {
+		err := p.storage.DeleteObject(ctx, "recipes", image.StoragePath)
+		if err == nil {
+			continue
+		}
+		slog.Error("Failed to delete image from storage", "error", err, "path", image.StoragePath, "content_hash", image.ContentHash)
+		errs = append(errs, fmt.Errorf("failed to delete image %s: %w", image.StoragePath, err))
+
+		if err := p.db.RestoreStoredImage(ctx, generated.RestoreStoredImageParams{
+			ID:          image.ID,
+			StoragePath: image.StoragePath,
+			SourceUrl:   image.SourceUrl,
+			ContentHash: image.ContentHash,
+			MimeType:    image.MimeType,
+			Width:       image.Width,
+			Height:      image.Height,
+			FileSize:    image.FileSize,
+			CreatedAt:   image.CreatedAt,
+		}); err != nil {
+			slog.Error("Failed to restore stored image", "error", err, "path", image.StoragePath, "content_hash", image.ContentHash)
+		}
+	}
+	return len(images) - len(errs), stderrors.Join(errs...)
+}
+
 func (p *RecipeProcessor) updateProgress(ctx context.Context, jobID, userID, status, message string) {
 	slog.Info("Progress update", "job_id", jobID, "status", status, "message", message)
 
@@ -860,40 +1052,94 @@ func (p *RecipeProcessor) updateProgress(ctx context.Context, jobID, userID, sta
 	}
 }
 
-func (p *RecipeProcessor) markFailed(ctx context.Context, jobID, userID, errorMsg string) {
-	slog.Error("Job failed", "job_id", jobID, "error", errorMsg)
+// markFailed stores appErr on the job as structured JSON and emits
+// import.failed, for failures that are not retried. Its wrapped cause is
+// only logged.
+func (p *RecipeProcessor) markFailed(ctx context.Context, jobID, userID string, appErr *errors.AppError) {
+	p.recordFailure(ctx, jobID, userID, appErr)
+	p.emitImportFailed(ctx, jobID, userID, appErr)
+}
+
+// failAttempt records a failed attempt at an import that asynq may retry
+// and returns err for the handler to return. Failures retrying can't fix
+// skip the remaining retries, and import.failed is only emitted when no
+// retry follows, so subscribers hear of each failed import once.
+func (p *RecipeProcessor) failAttempt(ctx context.Context, jobID, userID string, appErr *errors.AppError, err error) error {
+	if !appErr.IsRetryable() && !stderrors.Is(err, asynq.SkipRetry) {
+		err = fmt.Errorf("%w: %w", err, asynq.SkipRetry)
+	}
+	p.recordFailure(ctx, jobID, userID, appErr)
+	if finalAttempt(ctx, err) {
+		p.emitImportFailed(ctx, jobID, userID, appErr)
+	}
+	return err
+}
+
+// finalAttempt reports whether asynq will not retry a task that fails with
+// err. Outside asynq every attempt is the last.
+func finalAttempt(ctx context.Context, err error) bool {
+	if stderrors.Is(err, asynq.SkipRetry) {
+		return true
+	}
+	retried, ok := asynq.GetRetryCount(ctx)
+	if !ok {
+		return true
+	}
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return retried >= maxRetry
+}
+
+func (p *RecipeProcessor) recordFailure(ctx context.Context, jobID, userID string, appErr *errors.AppError) {
+	slog.Error("Job failed", "job_id", jobID, "error", appErr.Error())
 
 	p.db.UpdateImportJobStatus(ctx, generated.UpdateImportJobStatusParams{
 		JobID:        jobID,
 		Status:       "FAILED",
 		ProgressStep: pgtype.Text{String: "Failed", Valid: true},
-		Error:        []byte(errorMsg),
+		Error:        appErr.JSON(),
 	})
 
 	if p.broadcaster != nil {
 		p.broadcaster.Broadcast(userID, ProgressUpdate{
 			JobID:   jobID,
 			Status:  "failed",
-			Message: errorMsg,
+			Message: appErr.Message,
 		})
 	}
 }
 
-func (p *RecipeProcessor) markCanceled(ctx context.Context, jobID, userID, message string) {
-	slog.Info("Job canceled", "job_id", jobID, "reason", message)
+func (p *RecipeProcessor) emitImportFailed(ctx context.Context, jobID, userID string, appErr *errors.AppError) {
+	p.emitWebhookEvent(ctx, userID, webhook.EventImportFailed, webhook.ImportData{
+		JobID:     jobID,
+		Error:     appErr.Message,
+		ErrorCode: appErr.ErrorCode,
+	})
+}
+
+// errorJSON encodes a job's failure for its error column, or returns nil
+// when there is none.
+func errorJSON(appErr *errors.AppError) []byte {
+	if appErr == nil {
+		return nil
+	}
+	return appErr.JSON()
+}
+
+func (p *RecipeProcessor) markCanceled(ctx context.Context, jobID, userID string, appErr *errors.AppError) {
+	slog.Info("Job canceled", "job_id", jobID, "reason", appErr.Message)
 
 	p.db.UpdateImportJobStatus(ctx, generated.UpdateImportJobStatusParams{
 		JobID:        jobID,
 		Status:       "CANCELED",
 		ProgressStep: pgtype.Text{String: "Canceled", Valid: true},
-		Error:        []byte(message),
+		Error:        appErr.JSON(),
 	})
 
 	if p.broadcaster != nil {
 		p.broadcaster.Broadcast(userID, ProgressUpdate{
 			JobID:   jobID,
 			Status:  "canceled",
-			Message: message,
+			Message: appErr.Message,
 		})
 	}
 }
@@ -906,12 +1152,16 @@ func ptrToInt(i *int) int {
 }
 
 func downloadImage(ctx context.Context, url string) ([]byte, error) {
+	return downloadImageWith(ctx, http.DefaultClient, url)
+}
+
+func downloadImageWith(ctx context.Context, client *http.Client, url string) ([]byte, error) {
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := client.Do(req)
 	if err != nil {
 		return nil, err
 	}
@@ -1077,122 +1327,767 @@ func (p *RecipeProcessor) HandleGenerateRichInstructions(ctx context.Context, t
 	return nil
 }
 
-// HandleInstagramRetry handles retry attempts for failed Instagram scrapes.
-// It uses cached data if available and applies fast retry logic.
-func (p *RecipeProcessor) HandleInstagramRetry(ctx context.Context, t *asynq.Task) error {
+// Regeneration modes
+const (
+	RegenerationModeDraft   = "draft"
+	RegenerationModeReplace = "replace"
+)
+
+// HandleRegenerateRecipe reruns recipe generation, categories and rich
+// instructions from a recipe's stored raw data. Draft regenerations only
+// store the generated recipe for comparison; replace regenerations also
+// overwrite the recipe content in place.
+func (p *RecipeProcessor) HandleRegenerateRecipe(ctx context.Context, t *asynq.Task) error {
 	start := time.Now()
 	var status = "success"
 	defer func() {
 		duration := time.Since(start).Seconds()
-		p.metrics.RecordJob(ctx, "instagram_retry", status, duration)
+		p.metrics.RecordJob(ctx, "regenerate_recipe", status, duration)
 	}()
 
-	var payload InstagramRetryPayload
+	var payload RegenerateRecipePayload
 	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
 		status = "failure"
 		return fmt.Errorf("failed to unmarshal payload: %w", err)
 	}
 
-	jobID := payload.JobID
-	url := payload.URL
+	regen, err := p.db.GetRecipeRegeneration(ctx, parseUUID(payload.RegenerationID))
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("regeneration not found: %w", err)
+	}
 
-	slog.Info("Processing Instagram retry", "job_id", jobID, "url", url)
+	p.updateRegeneration(ctx, regen.ID, "EXECUTING", nil, nil)
 
-	// For now, just log the retry - full implementation would re-scrape
-	// This is a placeholder for the retry logic
-	slog.Info("Instagram retry processed", "job_id", jobID, "url", url)
+	draft, err := p.regenerateRecipe(ctx, regen)
+	if err != nil {
+		status = "failure"
+		slog.Error("Recipe regeneration failed", "error", err, "regeneration_id", payload.RegenerationID)
+		p.updateRegeneration(ctx, regen.ID, "FAILED", nil, errors.FromError(err, errors.NewRecipeGenerationError("Recipe regeneration failed", "REGENERATION_FAILED", err)))
+		// The failure is recorded on the regeneration; the user starts a new one
+		return fmt.Errorf("%v: %w", err, asynq.SkipRetry)
+	}
 
+	p.updateRegeneration(ctx, regen.ID, "COMPLETED", draft, nil)
+	slog.Info("Recipe regenerated", "regeneration_id", payload.RegenerationID, "mode", regen.Mode)
 	return nil
 }
 
-// HandleProcessBulkImport handles bulk import jobs by fanning out individual recipe tasks
-func (p *RecipeProcessor) HandleProcessBulkImport(ctx context.Context, t *asynq.Task) error {
-	start := time.Now()
-	var status = "success"
-	defer func() {
-		duration := time.Since(start).Seconds()
-		p.metrics.RecordJob(ctx, "process_bulk_import", status, duration)
-	}()
+// regenerateRecipe generates the recipe again and returns it as JSON.
+func (p *RecipeProcessor) regenerateRecipe(ctx context.Context, regen generated.RecipeRegeneration) ([]byte, error) {
+	if !ai.HasRecipePromptVersion(regen.PromptVersion) {
+		return nil, fmt.Errorf("prompt version %d is not available", regen.PromptVersion)
+	}
+	client, err := p.recipeClient(regen.Provider)
+	if err != nil {
+		return nil, err
+	}
 
-	var payload ProcessBulkImportPayload
-	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
-		status = "failure"
-		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	dbRecipe, err := p.db.GetRecipe(ctx, regen.RecipeID)
+	if err != nil {
+		return nil, fmt.Errorf("recipe not found: %w", err)
+	}
+	raw, err := p.db.GetRecipeRawData(ctx, regen.RecipeID)
+	if err != nil {
+		return nil, fmt.Errorf("raw data not found: %w", err)
 	}
 
-	bulkJobID := payload.BulkJobID
-	userID := payload.UserID
-	urls := payload.URLs
+	r, err := client.GenerateRecipe(ai.WithRecipePromptVersion(ctx, regen.PromptVersion), raw.Caption.String, raw.Transcript.String, raw.Origin)
+	if err != nil {
+		return nil, fmt.Errorf("recipe generation failed: %w", err)
+	}
 
-	slog.Info("Processing bulk import", "bulk_job_id", bulkJobID, "url_count", len(urls))
+	p.applySuggestedCategories(ctx, client, r, pgUUIDToString(dbRecipe.CreatedBy))
 
-	if err := p.db.UpdateBulkImportJobStatus(ctx, generated.UpdateBulkImportJobStatusParams{
-		JobID:  bulkJobID,
-		Status: "EXECUTING",
-	}); err != nil {
-		slog.Error("Failed to update bulk import status", "error", err, "bulk_job_id", bulkJobID)
+	if regen.Mode == RegenerationModeReplace {
+		revisionNumber, err := p.replaceRecipeContent(ctx, dbRecipe.ID, regen.RequestedBy, r)
+		if err != nil {
+			return nil, err
+		}
+		p.emitWebhookEvent(ctx, pgUUIDToString(dbRecipe.CreatedBy), webhook.EventRecipeUpdated, webhook.RecipeData{
+			RecipeID: pgUUIDToString(dbRecipe.ID),
+			Action:   revision.ActionRegenerate,
+			Revision: revisionNumber,
+		})
+	} else {
+		draftRichInstructions(ctx, client, r)
 	}
 
-	for _, url := range urls {
-		job, err := p.db.GetBulkImportJobByJobID(ctx, bulkJobID)
+	return json.Marshal(r)
+}
+
+// replaceRecipeContent overwrites the generated fields, categories,
+// nutrition and content of a saved recipe with a regenerated one, and records
+// the new content as a revision by authorID, in one transaction. Steps whose
+// text changed get their rich text regenerated afterwards. It returns the
+// new revision number, or 0 if the content did not change.
+func (p *RecipeProcessor) replaceRecipeContent(ctx context.Context, recipeID, authorID pgtype.UUID, r *groq.Recipe) (int32, error) {
+	result := validateGeneratedRecipe(r)
+	if !result.IsValid {
+		return 0, fmt.Errorf("recipe validation failed (quality score: %d): %s", result.QualityScore, strings.Join(result.Issues, ", "))
+	}
+	next := revision.FromGenerated(*r)
+	if err := next.Validate(); err != nil {
+		return 0, fmt.Errorf("regenerated recipe cannot be saved: %w", err)
+	}
+
+	var difficultyRating pgtype.Int2
+	if r.DifficultyRating != nil {
+		difficultyRating = pgtype.Int2{Int16: int16(*r.DifficultyRating), Valid: true}
+	}
+
+	var (
+		revisionNumber int32
+		missingRich    bool
+	)
+	err := p.withTx(ctx, func(q *generated.Queries) error {
+		// Serializes with edits, which lock the row the same way
+		recipe, err := q.LockRecipe(ctx, recipeID)
 		if err != nil {
-			slog.Error("Failed to check bulk import status", "error", err, "bulk_job_id", bulkJobID)
-			continue
+			return fmt.Errorf("recipe not found: %w", err)
 		}
-		if job.Status == "CANCELED" {
-			slog.Info("Bulk import was canceled, stopping", "bulk_job_id", bulkJobID)
-			break
+		current, err := revision.Load(ctx, q, recipe)
+		if err != nil {
+			return err
 		}
-
-		id := uuid.New().String()
-		jobID := uuid.New().String()
-
-		origin := "instagram"
-		if strings.Contains(url, "tiktok") {
-			origin = "tiktok"
-		} else if strings.Contains(url, "youtube") || strings.Contains(url, "youtu.be") {
-			origin = "youtube"
+		if err := revision.RecordOriginal(ctx, q, recipe, current); err != nil {
+			return err
 		}
 
-		_, err = p.db.CreateImportJob(ctx, generated.CreateImportJobParams{
-			ID:     parseUUID(id),
-			JobID:  jobID,
-			UserID: parseUUID(userID),
-			Url:    url,
-			Origin: origin,
-			Status: "QUEUED",
+		err = q.UpdateRecipeGeneratedFields(ctx, generated.UpdateRecipeGeneratedFieldsParams{
+			ID:                  recipeID,
+			RecipeName:          r.RecipeName,
+			Description:         pgtype.Text{String: r.Description, Valid: r.Description != ""},
+			PrepTime:            pgtype.Int4{Int32: int32(ptrToInt(r.PrepTime)), Valid: r.PrepTime != nil},
+			CookingTime:         pgtype.Int4{Int32: int32(ptrToInt(r.CookingTime)), Valid: r.CookingTime != nil},
+			TotalTime:           pgtype.Int4{Int32: int32(ptrToInt(r.TotalTime)), Valid: r.TotalTime != nil},
+			OriginalServingSize: pgtype.Int4{Int32: int32(ptrToInt(r.OriginalServings)), Valid: r.OriginalServings != nil},
+			DifficultyRating:    difficultyRating,
+			FocusedDiet:         pgtype.Text{String: r.FocusedDiet, Valid: r.FocusedDiet != ""},
+			EstimatedCalories:   pgtype.Int4{Int32: int32(ptrToInt(r.EstimatedCalories)), Valid: r.EstimatedCalories != nil},
+			Language:            pgtype.Text{String: r.Language, Valid: r.Language != ""},
 		})
 		if err != nil {
-			slog.Error("Failed to create import job for bulk", "error", err, "bulk_job_id", bulkJobID, "url", url)
-			continue
+			return fmt.Errorf("failed to update recipe: %w", err)
 		}
 
-		if err := p.db.UpdateImportJobWithBulkID(ctx, generated.UpdateImportJobWithBulkIDParams{
-			JobID:     jobID,
-			BulkJobID: pgtype.Text{String: bulkJobID, Valid: true},
-		}); err != nil {
-			slog.Error("Failed to link import job to bulk job", "error", err, "job_id", jobID, "bulk_job_id", bulkJobID)
+		if err := q.ClearRecipeCategories(ctx, recipeID); err != nil {
+			return fmt.Errorf("failed to clear categories: %w", err)
 		}
-
-		recipeTask, err := NewProcessRecipeTask(ProcessRecipePayload{
-			JobID:     jobID,
-			URL:       url,
-			UserID:    userID,
-			BulkJobID: bulkJobID,
-		})
+		if err := saveRecipeCategories(ctx, q, recipeID, r); err != nil {
+			return fmt.Errorf("failed to save categories: %w", err)
+		}
+		if err := q.DeleteNutritionByRecipe(ctx, recipeID); err != nil {
+			return fmt.Errorf("failed to clear nutrition: %w", err)
+		}
+		if err := saveNutrition(ctx, q, recipeID, r); err != nil {
+			return fmt.Errorf("failed to save nutrition: %w", err)
+		}
+		missingRich, err = revision.SaveContent(ctx, q, recipeID, next)
 		if err != nil {
-			slog.Error("Failed to create recipe task", "error", err, "job_id", jobID)
-			continue
+			return err
 		}
 
-		if _, err := p.asynqClient.Enqueue(recipeTask); err != nil {
-			slog.Error("Failed to enqueue recipe task", "error", err, "job_id", jobID)
+		changes, err := revision.Diff(current, next)
+		if err != nil {
+			return err
 		}
-	}
-
-	slog.Info("Bulk import fan-out completed", "bulk_job_id", bulkJobID, "url_count", len(urls))
-	return nil
-}
+		if len(changes) == 0 {
+			return nil
+		}
+		snapshot, err := json.Marshal(next)
+		if err != nil {
+			return err
+		}
+		diff, err := json.Marshal(changes)
+		if err != nil {
+			return err
+		}
+		rev, err := q.CreateRecipeRevision(ctx, generated.CreateRecipeRevisionParams{
+			RecipeID: recipeID,
+			AuthorID: authorID,
+			Action:   revision.ActionRegenerate,
+			Snapshot: snapshot,
+			Diff:     diff,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create revision: %w", err)
+		}
+		revisionNumber = rev.RevisionNumber
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if missingRich {
+		p.enqueueRichInstructionsRetry(ctx, pgUUIDToString(recipeID))
+	}
+	p.enqueueEmbedding(pgUUIDToString(recipeID))
+	return revisionNumber, nil
+}
+
+// draftRichInstructions fills in rich instruction text on an unsaved recipe.
+// Ingredients get temporary IDs so the placeholders in the rich text resolve
+// within the draft.
+func draftRichInstructions(ctx context.Context, client GroqClient, r *groq.Recipe) {
+	for i := range r.Ingredients {
+		r.Ingredients[i].ID = uuid.New().String()
+	}
+
+	richResp, err := client.GenerateRichInstructions(ctx, r)
+	if err != nil {
+		slog.Warn("Failed to generate rich instructions for draft", "error", err, "recipe_name", r.RecipeName)
+		return
+	}
+	if richResp == nil {
+		return
+	}
+
+	// Parts hold copies of the flattened instructions, in the same order
+	steps := make([]*groq.Instruction, 0, len(r.Instructions))
+	if r.HasParts() {
+		for i := range r.Parts {
+			for j := range r.Parts[i].Instructions {
+				steps = append(steps, &r.Parts[i].Instructions[j])
+			}
+		}
+	}
+	for i, inst := range richResp.Instructions {
+		if i < len(r.Instructions) {
+			r.Instructions[i].InstructionRich = inst.InstructionRich
+			r.Instructions[i].InstructionRichVersion = richResp.PromptVersion
+		}
+		if i < len(steps) {
+			steps[i].InstructionRich = inst.InstructionRich
+			steps[i].InstructionRichVersion = richResp.PromptVersion
+		}
+	}
+}
+
+func (p *RecipeProcessor) updateRegeneration(ctx context.Context, id pgtype.UUID, status string, draft []byte, failure *errors.AppError) {
+	err := p.db.UpdateRecipeRegenerationStatus(ctx, generated.UpdateRecipeRegenerationStatusParams{
+		ID:     id,
+		Status: status,
+		Draft:  draft,
+		Error:  errorJSON(failure),
+	})
+	if err != nil {
+		slog.Error("Failed to update regeneration status", "error", err, "status", status)
+	}
+}
+
+// HandleExportRecipes builds a zip archive of all of a user's recipes in the
+// export's format and stores it in the exports bucket.
+func (p *RecipeProcessor) HandleExportRecipes(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "export_recipes", status, duration)
+	}()
+
+	var payload ExportRecipesPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	exp, err := p.db.GetRecipeExport(ctx, parseUUID(payload.ExportID))
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("export not found: %w", err)
+	}
+
+	p.updateExport(ctx, exp.ID, "EXECUTING", 0, "", nil)
+
+	path, count, err := p.exportRecipes(ctx, exp)
+	if err != nil {
+		status = "failure"
+		slog.Error("Recipe export failed", "error", err, "export_id", payload.ExportID)
+		p.updateExport(ctx, exp.ID, "FAILED", 0, "", errors.NewInternalError("Recipe export failed", "EXPORT_FAILED", err))
+		return err
+	}
+
+	p.updateExport(ctx, exp.ID, "COMPLETED", count, path, nil)
+	slog.Info("Recipes exported", "export_id", payload.ExportID, "format", exp.Format, "recipes", count)
+	return nil
+}
+
+// exportRecipes writes the archive and returns its storage path and the
+// number of recipes in it.
+func (p *RecipeProcessor) exportRecipes(ctx context.Context, exp generated.RecipeExport) (string, int, error) {
+	format, err := export.ParseFormat(exp.Format)
+	if err != nil {
+		return "", 0, err
+	}
+
+	ids, err := p.db.GetRecipeIDsByUser(ctx, exp.UserID)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to list recipes: %w", err)
+	}
+
+	loader := export.NewLoader(p.db, func(storagePath string) string {
+		return p.storage.GetPublicURL("recipes", storagePath)
+	})
+	recipes := make([]*export.Recipe, 0, len(ids))
+	for _, id := range ids {
+		r, err := loader.Load(ctx, id)
+		if err != nil {
+			return "", 0, fmt.Errorf("failed to load recipe %s: %w", pgUUIDToString(id), err)
+		}
+		recipes = append(recipes, r)
+	}
+
+	var buf bytes.Buffer
+	if err := export.WriteArchive(&buf, format, recipes); err != nil {
+		return "", 0, err
+	}
+
+	path := fmt.Sprintf("%s/%s%s", pgUUIDToString(exp.UserID), pgUUIDToString(exp.ID), export.ArchiveExtension(format))
+	if err := p.storage.UploadObject(ctx, export.Bucket, path, buf.Bytes(), "application/zip"); err != nil {
+		return "", 0, fmt.Errorf("failed to upload export: %w", err)
+	}
+	return path, len(recipes), nil
+}
+
+func (p *RecipeProcessor) updateExport(ctx context.Context, id pgtype.UUID, status string, count int, path string, failure *errors.AppError) {
+	err := p.db.UpdateRecipeExportStatus(ctx, generated.UpdateRecipeExportStatusParams{
+		ID:          id,
+		Status:      status,
+		RecipeCount: int32(count),
+		StoragePath: pgtype.Text{String: path, Valid: path != ""},
+		Error:       errorJSON(failure),
+	})
+	if err != nil {
+		slog.Error("Failed to update export status", "error", err, "status", status)
+	}
+}
+
+// HandleInstagramRetry handles retry attempts for failed Instagram scrapes.
+// It uses cached data if available and applies fast retry logic.
+func (p *RecipeProcessor) HandleInstagramRetry(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "instagram_retry", status, duration)
+	}()
+
+	var payload InstagramRetryPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	jobID := payload.JobID
+	url := payload.URL
+
+	slog.Info("Processing Instagram retry", "job_id", jobID, "url", url)
+
+	// For now, just log the retry - full implementation would re-scrape
+	// This is a placeholder for the retry logic
+	slog.Info("Instagram retry processed", "job_id", jobID, "url", url)
+
+	return nil
+}
+
+// HandleProcessBulkImport handles bulk import jobs by fanning out individual recipe tasks
+func (p *RecipeProcessor) HandleProcessBulkImport(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "process_bulk_import", status, duration)
+	}()
+
+	var payload ProcessBulkImportPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	bulkJobID := payload.BulkJobID
+	userID := payload.UserID
+	urls := payload.URLs
+
+	slog.Info("Processing bulk import", "bulk_job_id", bulkJobID, "url_count", len(urls))
+
+	if err := p.db.UpdateBulkImportJobStatus(ctx, generated.UpdateBulkImportJobStatusParams{
+		JobID:  bulkJobID,
+		Status: "EXECUTING",
+	}); err != nil {
+		slog.Error("Failed to update bulk import status", "error", err, "bulk_job_id", bulkJobID)
+	}
+	p.broadcastBulkProgress(ctx, bulkJobID, "Bulk import started")
+
+	for _, url := range urls {
+		job, err := p.db.GetBulkImportJobByJobID(ctx, bulkJobID)
+		if err != nil {
+			slog.Error("Failed to check bulk import status", "error", err, "bulk_job_id", bulkJobID)
+			continue
+		}
+		if job.Status == "CANCELED" {
+			slog.Info("Bulk import was canceled, stopping", "bulk_job_id", bulkJobID)
+			break
+		}
+
+		id := uuid.New().String()
+		jobID := uuid.New().String()
+
+		origin := "instagram"
+		if strings.Contains(url, "tiktok") {
+			origin = "tiktok"
+		} else if strings.Contains(url, "youtube") || strings.Contains(url, "youtu.be") {
+			origin = "youtube"
+		}
+
+		_, err = p.db.CreateImportJob(ctx, generated.CreateImportJobParams{
+			ID:     parseUUID(id),
+			JobID:  jobID,
+			UserID: parseUUID(userID),
+			Url:    url,
+			Origin: origin,
+			Status: "QUEUED",
+		})
+		if err != nil {
+			slog.Error("Failed to create import job for bulk", "error", err, "bulk_job_id", bulkJobID, "url", url)
+			continue
+		}
+
+		if err := p.db.UpdateImportJobWithBulkID(ctx, generated.UpdateImportJobWithBulkIDParams{
+			JobID:     jobID,
+			BulkJobID: pgtype.Text{String: bulkJobID, Valid: true},
+		}); err != nil {
+			slog.Error("Failed to link import job to bulk job", "error", err, "job_id", jobID, "bulk_job_id", bulkJobID)
+		}
+
+		recipeTask, err := NewProcessRecipeTask(ProcessRecipePayload{
+			JobID:     jobID,
+			URL:       url,
+			UserID:    userID,
+			BulkJobID: bulkJobID,
+		})
+		if err != nil {
+			slog.Error("Failed to create recipe task", "error", err, "job_id", jobID)
+			continue
+		}
+
+		if _, err := p.asynqClient.Enqueue(recipeTask); err != nil {
+			slog.Error("Failed to enqueue recipe task", "error", err, "job_id", jobID)
+		}
+	}
+
+	slog.Info("Bulk import fan-out completed", "bulk_job_id", bulkJobID, "url_count", len(urls))
+	return nil
+}
+
+// recordBulkResult counts a finished recipe towards its bulk import and
+// completes the bulk import once every recipe is processed.
+func (p *RecipeProcessor) recordBulkResult(ctx context.Context, bulkJobID string, success bool) {
+	successCount := int32(0)
+	failedCount := int32(0)
+	if success {
+		successCount = 1
+	} else {
+		failedCount = 1
+	}
+	if err := p.db.IncrementBulkImportCounters(ctx, generated.IncrementBulkImportCountersParams{
+		JobID:        bulkJobID,
+		SuccessCount: pgtype.Int4{Int32: successCount, Valid: true},
+		FailedCount:  pgtype.Int4{Int32: failedCount, Valid: true},
+	}); err != nil {
+		slog.Error("Failed to increment bulk import counters", "error", err, "bulk_job_id", bulkJobID)
+	}
+
+	job, err := p.db.GetBulkImportJobByJobID(ctx, bulkJobID)
+	if err == nil && job.ProcessedCount.Int32+1 >= job.TotalUrls {
+		if err := p.db.UpdateBulkImportJobStatus(ctx, generated.UpdateBulkImportJobStatusParams{
+			JobID:  bulkJobID,
+			Status: "COMPLETED",
+		}); err != nil {
+			slog.Error("Failed to complete bulk import job", "error", err, "bulk_job_id", bulkJobID)
+		} else if job.Status != "COMPLETED" {
+			// Announce completion once, not for every late result
+			p.emitWebhookEvent(ctx, pgUUIDToString(job.UserID), webhook.EventBulkImportCompleted, webhook.BulkImportData{
+				BulkJobID: bulkJobID,
+				Total:     int(job.TotalUrls),
+				Success:   int(job.SuccessCount.Int32),
+				Failed:    int(job.FailedCount.Int32),
+			})
+		}
+	}
+
+	message := "Recipe imported"
+	if !success {
+		message = "Recipe failed"
+	}
+	p.broadcastBulkProgress(ctx, bulkJobID, message)
+}
+
+// broadcastBulkProgress publishes a bulk import's status and counters to its
+// owner.
+func (p *RecipeProcessor) broadcastBulkProgress(ctx context.Context, bulkJobID, message string) {
+	if p.broadcaster == nil {
+		return
+	}
+
+	job, err := p.db.GetBulkImportJobByJobID(ctx, bulkJobID)
+	if err != nil {
+		slog.Warn("Failed to read bulk import for progress update", "error", err, "bulk_job_id", bulkJobID)
+		return
+	}
+
+	if err := p.broadcaster.Broadcast(uuid.UUID(job.UserID.Bytes).String(), ProgressUpdate{
+		BulkJobID: bulkJobID,
+		Status:    job.Status,
+		Message:   message,
+		Progress: &BulkProgress{
+			Total:     int(job.TotalUrls),
+			Processed: int(job.ProcessedCount.Int32),
+			Success:   int(job.SuccessCount.Int32),
+			Failed:    int(job.FailedCount.Int32),
+		},
+	}); err != nil {
+		slog.Warn("Failed to broadcast bulk import progress", "error", err, "bulk_job_id", bulkJobID)
+	}
+}
+
+// HandleImportRecipeFile imports the recipes in an uploaded export file from
+// another recipe manager. The recipes are already structured, so they are
+// saved without generating them; each gets its own import job under the
+// bulk import, which reports per recipe results.
+func (p *RecipeProcessor) HandleImportRecipeFile(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "import_recipe_file", status, duration)
+	}()
+
+	var payload ImportRecipeFilePayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	bulkJobID := payload.BulkJobID
+	slog.Info("Importing recipe file", "bulk_job_id", bulkJobID, "format", payload.Format)
+
+	if err := p.db.UpdateBulkImportJobStatus(ctx, generated.UpdateBulkImportJobStatusParams{
+		JobID:  bulkJobID,
+		Status: "EXECUTING",
+	}); err != nil {
+		slog.Error("Failed to update bulk import status", "error", err, "bulk_job_id", bulkJobID)
+	}
+	p.broadcastBulkProgress(ctx, bulkJobID, "Bulk import started")
+
+	data, err := p.storage.DownloadObject(ctx, importer.Bucket, payload.StoragePath)
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to download import file: %w", err)
+	}
+
+	items, err := importer.Parse(importer.Format(payload.Format), payload.Filename, data)
+	if err != nil {
+		status = "failure"
+		p.failBulkImport(ctx, bulkJobID, err.Error())
+		return fmt.Errorf("failed to parse import file: %v: %w", err, asynq.SkipRetry)
+	}
+
+	// From here on failures are recorded per recipe. Every recipe counts
+	// towards the processed count once its result is recorded, so a retry
+	// resumes after the recipes already imported.
+	job, err := p.db.GetBulkImportJobByJobID(ctx, bulkJobID)
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to get bulk import job: %w", err)
+	}
+	done := min(int(job.ProcessedCount.Int32), len(items))
+	if done > 0 {
+		slog.Info("Resuming recipe file import", "bulk_job_id", bulkJobID, "processed", done)
+	}
+
+	imported := 0
+	for _, item := range items[done:] {
+		job, err := p.db.GetBulkImportJobByJobID(ctx, bulkJobID)
+		if err == nil && job.Status == "CANCELED" {
+			slog.Info("Bulk import was canceled, stopping", "bulk_job_id", bulkJobID)
+			break
+		}
+
+		ok := p.importFileItem(ctx, payload, item)
+		if ok {
+			imported++
+		}
+		p.recordBulkResult(ctx, bulkJobID, ok)
+	}
+
+	if err := p.storage.DeleteObject(ctx, importer.Bucket, payload.StoragePath); err != nil {
+		slog.Warn("Failed to delete import file", "error", err, "path", payload.StoragePath)
+	}
+
+	slog.Info("Recipe file imported", "bulk_job_id", bulkJobID, "recipes", len(items), "imported", imported)
+	return nil
+}
+
+func (p *RecipeProcessor) failBulkImport(ctx context.Context, bulkJobID, reason string) {
+	summary, _ := json.Marshal(map[string]string{"error": reason})
+	if err := p.db.UpdateBulkImportJobStatus(ctx, generated.UpdateBulkImportJobStatusParams{
+		JobID:   bulkJobID,
+		Status:  "FAILED",
+		Summary: summary,
+	}); err != nil {
+		slog.Error("Failed to fail bulk import job", "error", err, "bulk_job_id", bulkJobID)
+	}
+	p.broadcastBulkProgress(ctx, bulkJobID, reason)
+}
+
+// importFileItem saves one recipe from an import file under a new import
+// job and reports whether it was saved.
+func (p *RecipeProcessor) importFileItem(ctx context.Context, payload ImportRecipeFilePayload, item importer.Item) bool {
+	jobID := uuid.New().String()
+	userID := payload.UserID
+
+	if _, err := p.db.CreateImportJob(ctx, generated.CreateImportJobParams{
+		ID:     parseUUID(uuid.New().String()),
+		JobID:  jobID,
+		UserID: parseUUID(userID),
+		Url:    item.SourceURL,
+		Origin: string(generated.RecipeOriginFile),
+		Status: "QUEUED",
+	}); err != nil {
+		slog.Error("Failed to create import job for file", "error", err, "bulk_job_id", payload.BulkJobID, "name", item.Name)
+		return false
+	}
+	if err := p.db.UpdateImportJobWithBulkID(ctx, generated.UpdateImportJobWithBulkIDParams{
+		JobID:     jobID,
+		BulkJobID: pgtype.Text{String: payload.BulkJobID, Valid: true},
+	}); err != nil {
+		slog.Error("Failed to link import job to bulk job", "error", err, "job_id", jobID, "bulk_job_id", payload.BulkJobID)
+	}
+	p.setImportResult(ctx, jobID, map[string]string{"name": item.Name})
+
+	if item.Err != nil {
+		p.markFailed(ctx, jobID, userID, errors.NewValidationError(fmt.Sprintf("Could not read recipe: %v", item.Err), "UNREADABLE_RECIPE", ""))
+		return false
+	}
+
+	recipeID, err := p.saveImportedRecipe(ctx, jobID, payload, item)
+	if err != nil {
+		p.markFailed(ctx, jobID, userID, errors.NewInternalError("Failed to save recipe", "RECIPE_SAVE_FAILED", err))
+		return false
+	}
+
+	p.setImportResult(ctx, jobID, map[string]string{"name": item.Name, "recipe_id": recipeID})
+	p.updateProgress(ctx, jobID, userID, "COMPLETED", "Recipe imported successfully!")
+	p.emitWebhookEvent(ctx, userID, webhook.EventImportCompleted, webhook.ImportData{
+		JobID:     jobID,
+		URL:       item.SourceURL,
+		RecipeID:  recipeID,
+		BulkJobID: payload.BulkJobID,
+	})
+	return true
+}
+
+// saveImportedRecipe saves an imported recipe as it is, optionally enriching
+// it with suggested categories and rich instructions, and returns its ID.
+func (p *RecipeProcessor) saveImportedRecipe(ctx context.Context, jobID string, payload ImportRecipeFilePayload, item importer.Item) (string, error) {
+	userID := payload.UserID
+	r := item.Recipe
+
+	if payload.Enrich {
+		p.updateProgress(ctx, jobID, userID, "EXECUTING", "Generating categories with AI...")
+		p.applySuggestedCategories(ctx, p.groq, r, userID)
+	}
+
+	p.updateProgress(ctx, jobID, userID, "EXECUTING", "Saving recipe to database...")
+
+	var difficultyRating pgtype.Int2
+	if r.DifficultyRating != nil {
+		difficultyRating = pgtype.Int2{Int16: int16(*r.DifficultyRating), Valid: true}
+	}
+
+	savedRecipe, err := p.db.CreateRecipe(ctx, generated.CreateRecipeParams{
+		ID:                  parseUUID(uuid.New().String()),
+		CreatedBy:           parseUUID(userID),
+		RecipeName:          r.RecipeName,
+		Description:         pgtype.Text{String: r.Description, Valid: r.Description != ""},
+		PrepTime:            pgtype.Int4{Int32: int32(ptrToInt(r.PrepTime)), Valid: r.PrepTime != nil},
+		CookingTime:         pgtype.Int4{Int32: int32(ptrToInt(r.CookingTime)), Valid: r.CookingTime != nil},
+		TotalTime:           pgtype.Int4{Int32: int32(ptrToInt(r.TotalTime)), Valid: r.TotalTime != nil},
+		OriginalServingSize: pgtype.Int4{Int32: int32(ptrToInt(r.OriginalServings)), Valid: r.OriginalServings != nil},
+		DifficultyRating:    difficultyRating,
+		FocusedDiet:         pgtype.Text{String: r.FocusedDiet, Valid: r.FocusedDiet != ""},
+		EstimatedCalories:   pgtype.Int4{Int32: int32(ptrToInt(r.EstimatedCalories)), Valid: r.EstimatedCalories != nil},
+		Origin:              generated.RecipeOriginFile,
+		Url:                 item.SourceURL,
+		Language:            pgtype.Text{String: r.Language, Valid: r.Language != ""},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// The recipe as text lets it be regenerated like any other
+	caption := importer.Text(r)
+	rawDataJSON, _ := json.Marshal(map[string]interface{}{
+		"platform":  string(generated.RecipeOriginFile),
+		"format":    payload.Format,
+		"filename":  payload.Filename,
+		"caption":   caption,
+		"image_url": item.ImageURL,
+		"recipe":    r,
+	})
+	if _, err := p.db.CreateRecipeRawData(ctx, generated.CreateRecipeRawDataParams{
+		RecipeID:       savedRecipe.ID,
+		Origin:         string(generated.RecipeOriginFile),
+		SourceUrl:      item.SourceURL,
+		RawData:        rawDataJSON,
+		Caption:        pgtype.Text{String: caption, Valid: true},
+		ThumbnailUrl:   pgtype.Text{String: item.ImageURL, Valid: item.ImageURL != ""},
+		ScrapedAt:      pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ProcessedAt:    pgtype.Timestamptz{Time: time.Now(), Valid: true},
+		ScraperVersion: pgtype.Text{String: "1.0", Valid: true},
+	}); err != nil {
+		slog.Error("Failed to save raw data (non-critical)", "error", err, "recipe_id", savedRecipe.ID)
+	}
+
+	if err := saveRecipeCategories(ctx, p.db, savedRecipe.ID, r); err != nil {
+		slog.Error("Failed to save some recipe categories", "error", err, "recipe_id", pgUUIDToString(savedRecipe.ID))
+	}
+	savedIngredientIDs, savedInstructions := p.saveRecipeContent(ctx, savedRecipe.ID, r)
+	if payload.Enrich {
+		p.updateProgress(ctx, jobID, userID, "EXECUTING", "Generating rich instruction formatting...")
+		p.saveRichInstructions(ctx, p.groq, savedRecipe.ID, r, savedIngredientIDs, savedInstructions)
+	}
+	if err := saveNutrition(ctx, p.db, savedRecipe.ID, r); err != nil {
+		slog.Error("Failed to save nutrition", "error", err, "recipe_id", pgUUIDToString(savedRecipe.ID))
+	}
+
+	imageData := item.Image
+	if imageData == nil && item.ImageURL != "" {
+		if data, err := downloadImageWith(ctx, p.fileImages, item.ImageURL); err == nil {
+			imageData = data
+		} else {
+			slog.Warn("Failed to download imported recipe image", "error", err, "url", item.ImageURL)
+		}
+	}
+	if imageData != nil {
+		p.updateProgress(ctx, jobID, userID, "EXECUTING", "Processing recipe image...")
+		p.saveRecipeImage(ctx, savedRecipe.ID, item.ImageURL, imageData)
+	}
+
+	recipeID := pgUUIDToString(savedRecipe.ID)
+	p.enqueueEmbedding(recipeID)
+	return recipeID, nil
+}
+
+func (p *RecipeProcessor) setImportResult(ctx context.Context, jobID string, result map[string]string) {
+	data, _ := json.Marshal(result)
+	if err := p.db.SetImportJobResult(ctx, generated.SetImportJobResultParams{JobID: jobID, Result: data}); err != nil {
+		slog.Error("Failed to set import job result", "error", err, "job_id", jobID)
+	}
+}
 
 func (p *RecipeProcessor) saveIngredients(
 	ctx context.Context,
@@ -1353,3 +2248,307 @@ func (p *RecipeProcessor) saveInstructionIngredients(
 
 	return nil
 }
+
+// applySuggestedCategories fills in the recipe categories suggested by the
+// given client. Failures are reported and leave the categories untouched.
+func (p *RecipeProcessor) applySuggestedCategories(ctx context.Context, client GroqClient, recipe *groq.Recipe, userID string) {
+	categoryService := ai.NewCategoryService(p.db, client)
+	categories, err := utils.WithRetry(ctx, func(ctx context.Context) (*ai.CategorySuggestions, error) {
+		return categoryService.SuggestCategories(ctx, ai.RecipeInfo{
+			Name:        recipe.RecipeName,
+			Description: recipe.Description,
+			Ingredients: extractIngredientNames(recipe.Ingredients),
+		}, userID)
+	}, utils.DefaultRetryConfig())
+	if err != nil {
+		slog.Error("Category generation failed after retries", "error", err, "recipe_name", recipe.RecipeName)
+		sentrylib.CaptureError(err, map[string]string{
+			"recipe_name": recipe.RecipeName,
+			"component":   "category_generation",
+		})
+		return
+	}
+
+	recipe.CuisineCategories = categories.CuisineCategories
+	recipe.MealTypes = categories.MealTypes
+	recipe.Occasions = categories.Occasions
+	recipe.DietaryRestrictions = categories.DietaryRestrictions
+	recipe.Equipment = categories.Equipment
+}
+
+func validateGeneratedRecipe(recipe *groq.Recipe) validation.RecipeValidationResult {
+	validationConfig := validation.RecipeOutputValidationConfig{
+		MinIngredients:      2,
+		MinInstructions:     2,
+		MaxPlaceholderRatio: 0.2,
+	}
+
+	vRecipe := validation.Recipe{
+		RecipeName:          recipe.RecipeName,
+		Description:         recipe.Description,
+		PrepTime:            recipe.PrepTime,
+		CookingTime:         recipe.CookingTime,
+		TotalTime:           recipe.TotalTime,
+		OriginalServings:    recipe.OriginalServings,
+		DifficultyRating:    recipe.DifficultyRating,
+		FocusedDiet:         recipe.FocusedDiet,
+		EstimatedCalories:   recipe.EstimatedCalories,
+		Ingredients:         convertIngredients(recipe.Ingredients),
+		Instructions:        convertInstructions(recipe.Instructions),
+		Nutrition:           convertNutrition(recipe.Nutrition),
+		CuisineCategories:   recipe.CuisineCategories,
+		MealTypes:           recipe.MealTypes,
+		Occasions:           recipe.Occasions,
+		DietaryRestrictions: recipe.DietaryRestrictions,
+		Equipment:           recipe.Equipment,
+		Language:            recipe.Language,
+	}
+
+	return validation.ValidateRecipe(vRecipe, validationConfig)
+}
+
+// saveRecipeCategories links the recipe to its generated categories. A
+// failed link doesn't stop the others; the errors are returned together.
+func saveRecipeCategories(ctx context.Context, db DBQueries, recipeID pgtype.UUID, recipe *groq.Recipe) error {
+	var errs []error
+	cuisineSaved := 0
+	for _, cat := range recipe.CuisineCategories {
+		catID, err := db.GetOrCreateCuisineCategory(ctx, cat)
+		if err == nil {
+			err = db.AddRecipeCuisineCategory(ctx, generated.AddRecipeCuisineCategoryParams{
+				RecipeID:          recipeID,
+				CuisineCategoryID: catID,
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("cuisine category %q: %w", cat, err))
+			continue
+		}
+		cuisineSaved++
+	}
+	if cuisineSaved == 0 {
+		slog.Error("No cuisine categories persisted for recipe", "recipe_id", recipeID, "recipe_name", recipe.RecipeName)
+		sentrylib.CaptureError(fmt.Errorf("no cuisine categories persisted for recipe %s", recipe.RecipeName), map[string]string{
+			"recipe_name": recipe.RecipeName,
+			"component":   "category_persistence",
+		})
+	}
+
+	for _, mt := range recipe.MealTypes {
+		mtID, err := db.GetOrCreateMealType(ctx, mt)
+		if err == nil {
+			err = db.AddRecipeMealType(ctx, generated.AddRecipeMealTypeParams{
+				RecipeID:   recipeID,
+				MealTypeID: mtID,
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("meal type %q: %w", mt, err))
+		}
+	}
+
+	for _, occ := range recipe.Occasions {
+		occID, err := db.GetOrCreateOccasion(ctx, occ)
+		if err == nil {
+			err = db.AddRecipeOccasion(ctx, generated.AddRecipeOccasionParams{
+				RecipeID:   recipeID,
+				OccasionID: occID,
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("occasion %q: %w", occ, err))
+		}
+	}
+
+	for _, dr := range recipe.DietaryRestrictions {
+		drID, err := db.GetOrCreateDietaryRestriction(ctx, dr)
+		if err == nil {
+			err = db.AddRecipeDietaryRestriction(ctx, generated.AddRecipeDietaryRestrictionParams{
+				RecipeID:             recipeID,
+				DietaryRestrictionID: drID,
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("dietary restriction %q: %w", dr, err))
+		}
+	}
+
+	for _, eq := range recipe.Equipment {
+		eqID, err := db.GetOrCreateEquipment(ctx, eq)
+		if err == nil {
+			err = db.AddRecipeEquipment(ctx, generated.AddRecipeEquipmentParams{
+				RecipeID:    recipeID,
+				EquipmentID: eqID,
+			})
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("equipment %q: %w", eq, err))
+		}
+	}
+	return stderrors.Join(errs...)
+}
+
+// saveRecipeContent saves the recipe parts, ingredients and instructions and
+// returns the saved ingredient IDs and instructions in recipe order.
+func (p *RecipeProcessor) saveRecipeContent(ctx context.Context, recipeID pgtype.UUID, recipe *groq.Recipe) ([]string, []generated.RecipeInstruction) {
+	var savedIngredientIDs []string
+	var savedInstructions []generated.RecipeInstruction
+
+	if recipe.HasParts() {
+		for partIndex, part := range recipe.Parts {
+			var prepTime, cookTime pgtype.Int4
+			if part.PrepTime != nil {
+				prepTime = pgtype.Int4{Int32: int32(*part.PrepTime), Valid: true}
+			}
+			if part.CookingTime != nil {
+				cookTime = pgtype.Int4{Int32: int32(*part.CookingTime), Valid: true}
+			}
+
+			savedPart, err := p.db.CreateRecipePart(ctx, generated.CreateRecipePartParams{
+				RecipeID:     recipeID,
+				Name:         part.Name,
+				Description:  pgtype.Text{String: part.Description, Valid: part.Description != ""},
+				DisplayOrder: int32(partIndex),
+				IsOptional:   part.IsOptional,
+				PrepTime:     prepTime,
+				CookingTime:  cookTime,
+			})
+			if err != nil {
+				slog.Error("Failed to save recipe part", "error", err, "part_name", part.Name)
+				continue
+			}
+
+			partIngredientIDs, err := p.saveIngredients(ctx, recipeID, savedPart.ID, part.Ingredients, recipe.OriginalServings)
+			if err != nil {
+				slog.Error("Failed to save ingredients for part", "error", err, "part_name", part.Name)
+			}
+			savedIngredientIDs = append(savedIngredientIDs, partIngredientIDs...)
+
+			partInstructions, err := p.saveInstructions(ctx, recipeID, savedPart.ID, part.Instructions, 1)
+			if err != nil {
+				slog.Error("Failed to save instructions for part", "error", err, "part_name", part.Name)
+			}
+			savedInstructions = append(savedInstructions, partInstructions...)
+
+			// Step numbers start over in each part, so steps are linked to
+			// ingredients one part at a time.
+			if err := p.saveInstructionIngredients(ctx, partInstructions, partIngredientIDs, part.Ingredients, part.Instructions); err != nil {
+				slog.Warn("Failed to save instruction-ingredient junction entries", "error", err, "part_name", part.Name)
+			}
+		}
+	} else {
+		var err error
+		savedIngredientIDs, err = p.saveIngredients(ctx, recipeID, pgtype.UUID{}, recipe.Ingredients, recipe.OriginalServings)
+		if err != nil {
+			slog.Error("Failed to save ingredients", "error", err)
+		}
+
+		savedInstructions, err = p.saveInstructions(ctx, recipeID, pgtype.UUID{}, recipe.Instructions, 1)
+		if err != nil {
+			slog.Error("Failed to save instructions", "error", err)
+		}
+
+		if err := p.saveInstructionIngredients(ctx, savedInstructions, savedIngredientIDs, recipe.Ingredients, recipe.Instructions); err != nil {
+			slog.Warn("Failed to save instruction-ingredient junction entries", "error", err, "recipe_name", recipe.RecipeName)
+		}
+	}
+
+	return savedIngredientIDs, savedInstructions
+}
+
+// saveRichInstructions generates rich instruction text for saved instructions,
+// falling back to a retry task when generation fails.
+func (p *RecipeProcessor) saveRichInstructions(ctx context.Context, client GroqClient, recipeID pgtype.UUID, recipe *groq.Recipe, savedIngredientIDs []string, savedInstructions []generated.RecipeInstruction) {
+	for i := range recipe.Ingredients {
+		if i < len(savedIngredientIDs) && savedIngredientIDs[i] != "" {
+			recipe.Ingredients[i].ID = savedIngredientIDs[i]
+		}
+	}
+
+	richResp, err := client.GenerateRichInstructions(ctx, recipe)
+	if err != nil {
+		slog.Warn("Failed to generate rich instructions, enqueueing retry", "error", err, "recipe_name", recipe.RecipeName)
+		p.enqueueRichInstructionsRetry(ctx, pgUUIDToString(recipeID))
+		return
+	}
+	if richResp == nil {
+		return
+	}
+
+	for i, inst := range richResp.Instructions {
+		if i < len(savedInstructions) {
+			err := p.db.UpdateInstructionRich(ctx, generated.UpdateInstructionRichParams{
+				InstructionRich:        pgtype.Text{String: inst.InstructionRich, Valid: inst.InstructionRich != ""},
+				InstructionRichVersion: pgtype.Int4{Int32: int32(richResp.PromptVersion), Valid: richResp.PromptVersion > 0},
+				ID:                     savedInstructions[i].ID,
+			})
+			if err != nil {
+				slog.Error("Failed to update instruction with rich text", "error", err, "step", i+1)
+			}
+		}
+	}
+}
+
+// saveNutrition stores the recipe's nutrition, if it was generated.
+func saveNutrition(ctx context.Context, db DBQueries, recipeID pgtype.UUID, recipe *groq.Recipe) error {
+	if recipe.Nutrition.Protein <= 0 && recipe.Nutrition.Carbs <= 0 {
+		return nil
+	}
+	_, err := db.CreateNutrition(ctx, generated.CreateNutritionParams{
+		RecipeID: recipeID,
+		Protein:  pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Protein * 100)), Exp: -2, Valid: true},
+		Carbs:    pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Carbs * 100)), Exp: -2, Valid: true},
+		Fat:      pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Fat * 100)), Exp: -2, Valid: true},
+		Fiber:    pgtype.Numeric{Int: big.NewInt(int64(recipe.Nutrition.Fiber * 100)), Exp: -2, Valid: true},
+	})
+	return err
+}
+
+// saveRecipeImage stores an image by content hash and makes it the recipe's
+// thumbnail. Failures are logged and leave the recipe without an image.
+func (p *RecipeProcessor) saveRecipeImage(ctx context.Context, recipeID pgtype.UUID, sourceURL string, data []byte) {
+	hash := storage.HashContent(data)
+	path := fmt.Sprintf("post_images/%s", hash)
+	if _, err := p.storage.UploadImageWithHash(ctx, "recipes", path, sourceURL, data); err != nil {
+		slog.Error("Failed to upload image", "error", err)
+		return
+	}
+
+	existing, err := p.storage.GetImageByHash(ctx, hash)
+	if err != nil || existing == nil {
+		slog.Error("Failed to get stored image after upload", "error", err)
+		return
+	}
+
+	recipeImage, err := p.db.CreateRecipeImage(ctx, generated.CreateRecipeImageParams{
+		RecipeID:      recipeID,
+		StoredImageID: parseUUID(existing.ID),
+		ImageType:     "full",
+	})
+	if err != nil {
+		slog.Error("Failed to create recipe image record", "error", err)
+		return
+	}
+
+	if err := p.db.UpdateRecipeThumbnail(ctx, generated.UpdateRecipeThumbnailParams{
+		ID:          recipeID,
+		ThumbnailID: recipeImage.ID,
+	}); err != nil {
+		slog.Error("Failed to update recipe thumbnail", "error", err)
+	}
+}
+
+func (p *RecipeProcessor) enqueueEmbedding(recipeID string) {
+	if p.asynqClient == nil {
+		return
+	}
+	embedTask, err := NewGenerateEmbeddingTask(GenerateEmbeddingPayload{RecipeID: recipeID})
+	if err == nil {
+		_, err = p.asynqClient.Enqueue(embedTask)
+		if err != nil {
+			slog.Error("Failed to enqueue embedding task", "error", err)
+		} else {
+			slog.Info("Enqueued embedding task", "recipe_id", recipeID)
+		}
+	}
+}
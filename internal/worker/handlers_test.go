@@ -6,19 +6,28 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/config"
 	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/errors"
 	"github.com/socialchef/remy/internal/services/ai"
 	"github.com/socialchef/remy/internal/services/groq"
+	"github.com/socialchef/remy/internal/services/openai"
 	recipeservice "github.com/socialchef/remy/internal/services/recipe"
 	"github.com/socialchef/remy/internal/services/scraper"
 	"github.com/socialchef/remy/internal/services/storage"
+	"github.com/socialchef/remy/internal/services/webhook"
+	"github.com/socialchef/remy/internal/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // Mocks
@@ -37,6 +46,11 @@ func (m *MockDB) GetImportJob(ctx context.Context, id pgtype.UUID) (generated.Re
 	return args.Get(0).(generated.RecipeImportJob), args.Error(1)
 }
 
+func (m *MockDB) GetImportJobByJobID(ctx context.Context, jobID string) (generated.RecipeImportJob, error) {
+	args := m.Called(ctx, jobID)
+	return args.Get(0).(generated.RecipeImportJob), args.Error(1)
+}
+
 func (m *MockDB) GetImportJobsByUser(ctx context.Context, userID pgtype.UUID) ([]generated.RecipeImportJob, error) {
 	args := m.Called(ctx, userID)
 	return args.Get(0).([]generated.RecipeImportJob), args.Error(1)
@@ -47,6 +61,11 @@ func (m *MockDB) UpdateImportJobStatus(ctx context.Context, arg generated.Update
 	return args.Error(0)
 }
 
+func (m *MockDB) SetImportJobResult(ctx context.Context, arg generated.SetImportJobResultParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockDB) CreateRecipe(ctx context.Context, arg generated.CreateRecipeParams) (generated.Recipe, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(generated.Recipe), args.Error(1)
@@ -62,6 +81,11 @@ func (m *MockDB) UpdateRecipe(ctx context.Context, arg generated.UpdateRecipePar
 	return args.Get(0).(generated.Recipe), args.Error(1)
 }
 
+func (m *MockDB) CreateRecipeRawData(ctx context.Context, arg generated.CreateRecipeRawDataParams) (generated.RecipeRawDatum, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(generated.RecipeRawDatum), args.Error(1)
+}
+
 func (m *MockDB) CreateIngredient(ctx context.Context, arg generated.CreateIngredientParams) (generated.RecipeIngredient, error) {
 	args := m.Called(ctx, arg)
 	return args.Get(0).(generated.RecipeIngredient), args.Error(1)
@@ -112,11 +136,180 @@ func (m *MockDB) GetInstructionsByRecipe(ctx context.Context, recipeID pgtype.UU
 	return args.Get(0).([]generated.RecipeInstruction), args.Error(1)
 }
 
+func (m *MockDB) GetNutritionByRecipe(ctx context.Context, recipeID pgtype.UUID) (generated.RecipeNutrition, error) {
+	args := m.Called(ctx, recipeID)
+	return args.Get(0).(generated.RecipeNutrition), args.Error(1)
+}
+
+func (m *MockDB) GetImagesByRecipe(ctx context.Context, recipeID pgtype.UUID) ([]generated.StoredImage, error) {
+	args := m.Called(ctx, recipeID)
+	return args.Get(0).([]generated.StoredImage), args.Error(1)
+}
+
 func (m *MockDB) DeleteOldImportJobs(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
 }
 
+func (m *MockDB) DeleteIngredientsByRecipe(ctx context.Context, recipeID pgtype.UUID) error {
+	args := m.Called(ctx, recipeID)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteInstructionsByRecipe(ctx context.Context, recipeID pgtype.UUID) error {
+	args := m.Called(ctx, recipeID)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteNutritionByRecipe(ctx context.Context, recipeID pgtype.UUID) error {
+	args := m.Called(ctx, recipeID)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteRecipeParts(ctx context.Context, recipeID pgtype.UUID) error {
+	args := m.Called(ctx, recipeID)
+	return args.Error(0)
+}
+
+func (m *MockDB) ClearRecipeCategories(ctx context.Context, recipeID pgtype.UUID) error {
+	args := m.Called(ctx, recipeID)
+	return args.Error(0)
+}
+
+func (m *MockDB) UpdateRecipeGeneratedFields(ctx context.Context, arg generated.UpdateRecipeGeneratedFieldsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) RefreshRecipeIngredientNames(ctx context.Context, recipeID pgtype.UUID) error {
+	args := m.Called(ctx, recipeID)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetRecipeRawData(ctx context.Context, recipeID pgtype.UUID) (generated.RecipeRawDatum, error) {
+	args := m.Called(ctx, recipeID)
+	return args.Get(0).(generated.RecipeRawDatum), args.Error(1)
+}
+
+func (m *MockDB) GetRecipeRegeneration(ctx context.Context, id pgtype.UUID) (generated.RecipeRegeneration, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(generated.RecipeRegeneration), args.Error(1)
+}
+
+func (m *MockDB) UpdateRecipeRegenerationStatus(ctx context.Context, arg generated.UpdateRecipeRegenerationStatusParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetRecipeIDsByUser(ctx context.Context, createdBy pgtype.UUID) ([]pgtype.UUID, error) {
+	args := m.Called(ctx, createdBy)
+	return args.Get(0).([]pgtype.UUID), args.Error(1)
+}
+
+func (m *MockDB) GetRecipeExport(ctx context.Context, id pgtype.UUID) (generated.RecipeExport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(generated.RecipeExport), args.Error(1)
+}
+
+func (m *MockDB) UpdateRecipeExportStatus(ctx context.Context, arg generated.UpdateRecipeExportStatusParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetAccountExport(ctx context.Context, id pgtype.UUID) (generated.AccountExport, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(generated.AccountExport), args.Error(1)
+}
+
+func (m *MockDB) UpdateAccountExportStatus(ctx context.Context, arg generated.UpdateAccountExportStatusParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) GetAccountData(ctx context.Context, userID pgtype.UUID) (generated.GetAccountDataRow, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(generated.GetAccountDataRow), args.Error(1)
+}
+
+func (m *MockDB) GetAccountErasure(ctx context.Context, id pgtype.UUID) (generated.AccountErasure, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(generated.AccountErasure), args.Error(1)
+}
+
+func (m *MockDB) UpdateAccountErasureStatus(ctx context.Context, arg generated.UpdateAccountErasureStatusParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteRecipesByUser(ctx context.Context, createdBy pgtype.UUID) (generated.DeleteRecipesByUserRow, error) {
+	args := m.Called(ctx, createdBy)
+	return args.Get(0).(generated.DeleteRecipesByUserRow), args.Error(1)
+}
+
+func (m *MockDB) ListExportPathsByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.Text, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]pgtype.Text), args.Error(1)
+}
+
+func (m *MockDB) AddAccountErasureImageHashes(ctx context.Context, arg generated.AddAccountErasureImageHashesParams) ([]string, error) {
+	args := m.Called(ctx, arg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockDB) DeleteExportsByUser(ctx context.Context, userID pgtype.UUID) ([]pgtype.Text, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]pgtype.Text), args.Error(1)
+}
+
+func (m *MockDB) EraseUserRecords(ctx context.Context, userID pgtype.UUID) (generated.EraseUserRecordsRow, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(generated.EraseUserRecordsRow), args.Error(1)
+}
+
+func (m *MockDB) ListWebhookSubscriptionsForEvent(ctx context.Context, arg generated.ListWebhookSubscriptionsForEventParams) ([]generated.WebhookSubscription, error) {
+	args := m.Called(ctx, arg)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]generated.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockDB) CreateWebhookDelivery(ctx context.Context, arg generated.CreateWebhookDeliveryParams) (generated.WebhookDelivery, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).(generated.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDB) GetWebhookSubscription(ctx context.Context, id pgtype.UUID) (generated.WebhookSubscription, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(generated.WebhookSubscription), args.Error(1)
+}
+
+func (m *MockDB) GetWebhookDelivery(ctx context.Context, id pgtype.UUID) (generated.WebhookDelivery, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(generated.WebhookDelivery), args.Error(1)
+}
+
+func (m *MockDB) UpdateWebhookDeliveryResult(ctx context.Context, arg generated.UpdateWebhookDeliveryResultParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteUnreferencedStoredImages(ctx context.Context, dollar_1 []string) ([]generated.StoredImage, error) {
+	args := m.Called(ctx, dollar_1)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]generated.StoredImage), args.Error(1)
+}
+
+func (m *MockDB) RestoreStoredImage(ctx context.Context, arg generated.RestoreStoredImageParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 func (m *MockDB) DeleteStaleImportJobs(ctx context.Context) error {
 	args := m.Called(ctx)
 	return args.Error(0)
@@ -146,6 +339,21 @@ func (m *MockDB) UpdateRecipeEmbedding(ctx context.Context, arg generated.Update
 	return args.Error(0)
 }
 
+func (m *MockDB) GetRecipesNeedingEmbedding(ctx context.Context, arg generated.GetRecipesNeedingEmbeddingParams) ([]generated.GetRecipesNeedingEmbeddingRow, error) {
+	args := m.Called(ctx, arg)
+	return args.Get(0).([]generated.GetRecipesNeedingEmbeddingRow), args.Error(1)
+}
+
+func (m *MockDB) UpsertRecipeEmbedding(ctx context.Context, arg generated.UpsertRecipeEmbeddingParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
+func (m *MockDB) DeleteStaleRecipeEmbeddings(ctx context.Context, arg generated.DeleteStaleRecipeEmbeddingsParams) error {
+	args := m.Called(ctx, arg)
+	return args.Error(0)
+}
+
 // Category methods
 func (m *MockDB) GetOrCreateCuisineCategory(ctx context.Context, name string) (pgtype.UUID, error) {
 	args := m.Called(ctx, name)
@@ -252,6 +460,11 @@ func (m *MockDB) CreateRecipePart(ctx context.Context, arg generated.CreateRecip
 	return args.Get(0).(generated.RecipePart), args.Error(1)
 }
 
+func (m *MockDB) GetRecipeCategoryNames(ctx context.Context, recipeID pgtype.UUID) (generated.GetRecipeCategoryNamesRow, error) {
+	args := m.Called(ctx, recipeID)
+	return args.Get(0).(generated.GetRecipeCategoryNamesRow), args.Error(1)
+}
+
 func (m *MockDB) GetRecipeParts(ctx context.Context, recipeID pgtype.UUID) ([]generated.RecipePart, error) {
 	args := m.Called(ctx, recipeID)
 	return args.Get(0).([]generated.RecipePart), args.Error(1)
@@ -302,6 +515,19 @@ func (m *MockOpenAIClient) GenerateEmbedding(ctx context.Context, text string) (
 	return args.Get(0).([]float32), args.Error(1)
 }
 
+func (m *MockOpenAIClient) GenerateEmbeddings(ctx context.Context, texts []string) ([][]float32, error) {
+	args := m.Called(ctx, texts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([][]float32), args.Error(1)
+}
+
+func (m *MockOpenAIClient) ExtractRecipeText(ctx context.Context, image []byte, contentType string) (string, error) {
+	args := m.Called(ctx, image, contentType)
+	return args.String(0), args.Error(1)
+}
+
 type MockTranscriptionClient struct {
 	mock.Mock
 }
@@ -348,6 +574,46 @@ func (m *MockStorageClient) UploadImageWithHash(ctx context.Context, bucket, pat
 	return args.String(0), args.Error(1)
 }
 
+func (m *MockStorageClient) DeleteObject(ctx context.Context, bucket, path string) error {
+	args := m.Called(ctx, bucket, path)
+	return args.Error(0)
+}
+
+func (m *MockStorageClient) UploadObject(ctx context.Context, bucket, path string, data []byte, contentType string) error {
+	args := m.Called(ctx, bucket, path, data, contentType)
+	return args.Error(0)
+}
+
+func (m *MockStorageClient) DownloadObject(ctx context.Context, bucket, path string) ([]byte, error) {
+	args := m.Called(ctx, bucket, path)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]byte), args.Error(1)
+}
+
+func (m *MockStorageClient) ListObjects(ctx context.Context, bucket, prefix string) ([]string, error) {
+	args := m.Called(ctx, bucket, prefix)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]string), args.Error(1)
+}
+
+type MockAuthAdmin struct {
+	mock.Mock
+}
+
+func (m *MockAuthAdmin) DeleteUser(ctx context.Context, userID string) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockStorageClient) GetPublicURL(bucket, path string) string {
+	args := m.Called(bucket, path)
+	return args.String(0)
+}
+
 func (m *MockStorageClient) GetImageByHash(ctx context.Context, hash string) (*storage.ExistingImageResponse, error) {
 	args := m.Called(ctx, hash)
 	if args.Get(0) == nil {
@@ -382,6 +648,7 @@ func TestHandleProcessRecipe_ValidRecipe(t *testing.T) {
 	task := asynq.NewTask(TypeProcessRecipe, payloadBytes)
 
 	mockDB := new(MockDB)
+	mockDB.On("GetImportJobByJobID", mock.Anything, mock.Anything).Return(generated.RecipeImportJob{Status: "QUEUED"}, nil)
 	mockInsta := new(MockInstagramScraper)
 	mockTikTok := new(MockTikTokScraper)
 	mockOpenAI := new(MockOpenAIClient)
@@ -458,6 +725,7 @@ func TestHandleProcessRecipe_ValidRecipe(t *testing.T) {
 	recipeUUID := pgtype.UUID{Valid: true} // Simplified for mock
 	mockDB.On("UpdateImportJobStatus", ctx, mock.Anything).Return(nil)
 	mockDB.On("CreateRecipe", ctx, mock.Anything).Return(generated.Recipe{ID: recipeUUID, RecipeName: "Chocolate Cake"}, nil)
+	mockDB.On("CreateRecipeRawData", ctx, mock.Anything).Return(generated.RecipeRawDatum{}, nil)
 	mockDB.On("CreateIngredient", ctx, mock.Anything).Return(generated.RecipeIngredient{ID: pgtype.UUID{Valid: true}}, nil)
 	mockDB.On("CreateInstruction", ctx, mock.Anything).Return(generated.RecipeInstruction{ID: pgtype.UUID{Valid: true}}, nil)
 	mockDB.On("CreateNutrition", ctx, mock.Anything).Return(generated.RecipeNutrition{}, nil)
@@ -490,6 +758,94 @@ func TestHandleProcessRecipe_ValidRecipe(t *testing.T) {
 	mockGroq.AssertExpectations(t)
 }
 
+func TestHandleProcessRecipe_ManualPhoto(t *testing.T) {
+	ctx := context.Background()
+	jobID := uuid.New().String()
+	userID := uuid.New().String()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("fake-image-data"))
+	}))
+	defer ts.Close()
+
+	payloadBytes, _ := json.Marshal(ProcessRecipePayload{
+		JobID:    jobID,
+		UserID:   userID,
+		Text:     "Grandma's pancakes",
+		ImageURL: ts.URL,
+	})
+	task := asynq.NewTask(TypeProcessRecipe, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockDB.On("GetImportJobByJobID", mock.Anything, mock.Anything).Return(generated.RecipeImportJob{Status: "QUEUED"}, nil)
+	mockOpenAI := new(MockOpenAIClient)
+	mockGroq := new(MockGroqClient)
+	mockStorage := new(MockStorageClient)
+	mockBroadcaster := new(MockBroadcaster)
+
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, mockOpenAI, nil, mockGroq, mockStorage, mockBroadcaster, nil, nil,
+	)
+
+	photoText := "Pancakes. Ingredients: 200g flour, 2 eggs, 300ml milk. Whisk the flour, eggs and milk, then fry in a hot pan for 2 minutes per side."
+	mockOpenAI.On("ExtractRecipeText", ctx, []byte("fake-image-data"), mock.Anything).Return(photoText, nil)
+
+	mockGroq.On("GenerateRecipe", ctx, "Grandma's pancakes\n\n"+photoText, "", "manual").Return(&groq.Recipe{
+		RecipeName:  "Pancakes",
+		Description: "Fluffy pancakes from a family recipe card",
+		Ingredients: []groq.Ingredient{
+			{Name: "Flour", OriginalQuantity: "200", Quantity: "200", Unit: "g"},
+			{Name: "Milk", OriginalQuantity: "300", Quantity: "300", Unit: "ml"},
+		},
+		Instructions: []groq.Instruction{
+			{StepNumber: 1, Instruction: "Whisk the flour, eggs and milk"},
+			{StepNumber: 2, Instruction: "Fry for 2 minutes per side"},
+		},
+		Nutrition: groq.Nutrition{Protein: 8, Carbs: 40, Fat: 6, Fiber: 1},
+	}, nil)
+
+	mockDB.On("GetCuisineCategoriesByUser", mock.Anything, mock.Anything).Return([]string{}, nil)
+	mockDB.On("GetMealTypesByUser", mock.Anything, mock.Anything).Return([]string{}, nil)
+	mockDB.On("GetOccasionsByUser", mock.Anything, mock.Anything).Return([]string{}, nil)
+	mockDB.On("GetDietaryRestrictionsByUser", mock.Anything, mock.Anything).Return([]string{}, nil)
+	mockDB.On("GetEquipmentByUser", mock.Anything, mock.Anything).Return([]string{}, nil)
+	mockGroq.On("GenerateCategories", mock.Anything, mock.Anything).Return(&ai.CategoryAIResponse{
+		CuisineCategories: []string{"American"},
+	}, nil)
+	mockGroq.On("GenerateRichInstructions", ctx, mock.Anything).Return(nil, assert.AnError)
+
+	recipeUUID := pgtype.UUID{Valid: true}
+	mockDB.On("UpdateImportJobStatus", ctx, mock.Anything).Return(nil)
+	mockDB.On("CreateRecipe", ctx, mock.MatchedBy(func(arg generated.CreateRecipeParams) bool {
+		return arg.Origin == generated.RecipeOriginManual && arg.Url == ""
+	})).Return(generated.Recipe{ID: recipeUUID, RecipeName: "Pancakes"}, nil)
+	mockDB.On("CreateRecipeRawData", ctx, mock.MatchedBy(func(arg generated.CreateRecipeRawDataParams) bool {
+		return arg.Origin == "manual" && arg.Caption.String == "Grandma's pancakes\n\n"+photoText && arg.ThumbnailUrl.String == ts.URL
+	})).Return(generated.RecipeRawDatum{}, nil)
+	mockDB.On("CreateIngredient", ctx, mock.Anything).Return(generated.RecipeIngredient{ID: pgtype.UUID{Valid: true}}, nil)
+	mockDB.On("CreateInstruction", ctx, mock.Anything).Return(generated.RecipeInstruction{ID: pgtype.UUID{Valid: true}}, nil)
+	mockDB.On("GetOrCreateCuisineCategory", ctx, mock.Anything).Return(pgtype.UUID{Valid: true}, nil)
+	mockDB.On("AddRecipeCuisineCategory", ctx, mock.Anything).Return(nil)
+	mockDB.On("CreateNutrition", ctx, mock.Anything).Return(generated.RecipeNutrition{}, nil)
+
+	// The photo becomes the recipe image
+	mockStorage.On("UploadImageWithHash", ctx, "recipes", mock.Anything, ts.URL, []byte("fake-image-data")).Return("https://public.com/image.jpg", nil)
+	mockStorage.On("GetImageByHash", ctx, mock.Anything).Return(&storage.ExistingImageResponse{ID: uuid.New().String()}, nil)
+	mockDB.On("CreateRecipeImage", ctx, mock.Anything).Return(generated.RecipeImage{ID: pgtype.UUID{Valid: true}}, nil)
+	mockDB.On("UpdateRecipeThumbnail", ctx, mock.Anything).Return(nil)
+
+	mockBroadcaster.On("Broadcast", userID, mock.Anything).Return(nil)
+
+	err := processor.HandleProcessRecipe(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockOpenAI.AssertExpectations(t)
+	mockGroq.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
 func TestHandleProcessRecipe_ContentValidationFails(t *testing.T) {
 	ctx := context.Background()
 	jobID := uuid.New().String()
@@ -505,6 +861,7 @@ func TestHandleProcessRecipe_ContentValidationFails(t *testing.T) {
 	task := asynq.NewTask(TypeProcessRecipe, payloadBytes)
 
 	mockDB := new(MockDB)
+	mockDB.On("GetImportJobByJobID", mock.Anything, mock.Anything).Return(generated.RecipeImportJob{Status: "QUEUED"}, nil)
 	mockInsta := new(MockInstagramScraper)
 	mockBroadcaster := new(MockBroadcaster)
 
@@ -526,6 +883,34 @@ func TestHandleProcessRecipe_ContentValidationFails(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "Content validation failed")
+	// Retrying cannot fix the content, so the import is not retried
+	assert.ErrorIs(t, err, asynq.SkipRetry)
+}
+
+func TestHandleProcessRecipe_CanceledJobIsSkipped(t *testing.T) {
+	ctx := context.Background()
+	jobID := uuid.New().String()
+
+	payloadBytes, _ := json.Marshal(ProcessRecipePayload{
+		JobID:  jobID,
+		UserID: uuid.New().String(),
+		URL:    "https://www.instagram.com/p/C_abc123/",
+	})
+	task := asynq.NewTask(TypeProcessRecipe, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockDB.On("GetImportJobByJobID", ctx, jobID).Return(generated.RecipeImportJob{JobID: jobID, Status: "CANCELED"}, nil)
+	mockInsta := new(MockInstagramScraper)
+
+	processor := NewRecipeProcessor(
+		mockDB, mockInsta, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+	)
+
+	err := processor.HandleProcessRecipe(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertNotCalled(t, "UpdateImportJobStatus", mock.Anything, mock.Anything)
+	mockInsta.AssertNotCalled(t, "Scrape", mock.Anything, mock.Anything)
 }
 
 func TestHandleProcessRecipe_TranscriptionFails(t *testing.T) {
@@ -543,6 +928,7 @@ func TestHandleProcessRecipe_TranscriptionFails(t *testing.T) {
 	task := asynq.NewTask(TypeProcessRecipe, payloadBytes)
 
 	mockDB := new(MockDB)
+	mockDB.On("GetImportJobByJobID", mock.Anything, mock.Anything).Return(generated.RecipeImportJob{Status: "QUEUED"}, nil)
 	mockInsta := new(MockInstagramScraper)
 	mockTranscription := new(MockTranscriptionClient)
 	mockBroadcaster := new(MockBroadcaster)
@@ -568,23 +954,56 @@ func TestHandleProcessRecipe_TranscriptionFails(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Equal(t, "api error", err.Error())
+	assert.NotErrorIs(t, err, asynq.SkipRetry, "expected a provider error to be retried")
 }
 
-func TestHandleProcessRecipe_OutputValidationFails(t *testing.T) {
+func TestMarkFailed_StoresStructuredError(t *testing.T) {
 	ctx := context.Background()
 	jobID := uuid.New().String()
 	userID := uuid.New().String()
-	url := "https://www.instagram.com/p/C_abc123/"
 
-	payload := ProcessRecipePayload{
-		JobID:  jobID,
-		UserID: userID,
-		URL:    url,
-	}
+	mockDB := new(MockDB)
+	mockBroadcaster := new(MockBroadcaster)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, nil, mockBroadcaster, nil, nil,
+	)
+
+	var stored []byte
+	mockDB.On("UpdateImportJobStatus", ctx, mock.MatchedBy(func(arg generated.UpdateImportJobStatusParams) bool {
+		return arg.JobID == jobID && arg.Status == "FAILED"
+	})).Run(func(args mock.Arguments) {
+		stored = args.Get(1).(generated.UpdateImportJobStatusParams).Error
+	}).Return(nil)
+	mockBroadcaster.On("Broadcast", userID, mock.MatchedBy(func(update ProgressUpdate) bool {
+		return update.Message == "Transcription failed"
+	})).Return(nil)
+
+	processor.markFailed(ctx, jobID, userID, errors.NewTranscriptionError("Transcription failed", "TRANSCRIPTION_FAILED", fmt.Errorf("api key revoked")))
+
+	detail := errors.ParseDetail(stored)
+	require.NotNil(t, detail)
+	assert.Equal(t, "TRANSCRIPTION_FAILED", detail.Code)
+	assert.Equal(t, errors.ErrorTypeTranscription, detail.Type)
+	assert.NotContains(t, string(stored), "api key revoked")
+	mockBroadcaster.AssertExpectations(t)
+}
+
+func TestHandleProcessRecipe_OutputValidationFails(t *testing.T) {
+	ctx := context.Background()
+	jobID := uuid.New().String()
+	userID := uuid.New().String()
+	url := "https://www.instagram.com/p/C_abc123/"
+
+	payload := ProcessRecipePayload{
+		JobID:  jobID,
+		UserID: userID,
+		URL:    url,
+	}
 	payloadBytes, _ := json.Marshal(payload)
 	task := asynq.NewTask(TypeProcessRecipe, payloadBytes)
 
 	mockDB := new(MockDB)
+	mockDB.On("GetImportJobByJobID", mock.Anything, mock.Anything).Return(generated.RecipeImportJob{Status: "QUEUED"}, nil)
 	mockInsta := new(MockInstagramScraper)
 	mockGroq := new(MockGroqClient)
 	mockBroadcaster := new(MockBroadcaster)
@@ -635,6 +1054,158 @@ func TestHandleProcessRecipe_OutputValidationFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "Recipe validation failed")
 }
 
+func TestHandleBackfillEmbeddings(t *testing.T) {
+	ctx := context.Background()
+	recipeID1 := parseUUID(uuid.New().String())
+	recipeID2 := parseUUID(uuid.New().String())
+
+	payloadBytes, _ := json.Marshal(BackfillEmbeddingsPayload{BatchSize: 3})
+	task := asynq.NewTask(TypeBackfillEmbeddings, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockOpenAI := new(MockOpenAIClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, mockOpenAI, nil, nil, nil, nil, nil, nil,
+	)
+
+	mockDB.On("GetRecipesNeedingEmbedding", ctx, generated.GetRecipesNeedingEmbeddingParams{
+		ID:      pgtype.UUID{Valid: true},
+		Column2: openai.EmbeddingModel,
+		Column3: EmbeddingVersion,
+		Limit:   3,
+	}).Return([]generated.GetRecipesNeedingEmbeddingRow{
+		{ID: recipeID1, RecipeName: "Pancakes", Description: pgtype.Text{String: "Fluffy", Valid: true}},
+		{ID: recipeID2, RecipeName: "Stamppot"},
+	}, nil).Once()
+	mockRecipeFacets(ctx, mockDB, recipeID1, []generated.RecipeIngredient{{Name: "flour"}})
+	mockRecipeFacets(ctx, mockDB, recipeID2, nil)
+	mockOpenAI.On("GenerateEmbeddings", ctx, []string{"Recipe: Pancakes\nDescription: Fluffy\nIngredients: flour", "Recipe: Stamppot"}).
+		Return([][]float32{{0.1}, {0.2}}, nil).Once()
+	mockDB.On("UpdateRecipeEmbedding", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeEmbeddingParams) bool {
+		return (arg.ID == recipeID1 || arg.ID == recipeID2) &&
+			arg.EmbeddingModel.String == openai.EmbeddingModel &&
+			arg.EmbeddingVersion.Int32 == EmbeddingVersion
+	})).Return(nil).Twice()
+	mockDB.On("UpsertRecipeEmbedding", ctx, mock.MatchedBy(func(arg generated.UpsertRecipeEmbeddingParams) bool {
+		return arg.Kind == EmbeddingKindOverview && arg.Version == EmbeddingVersion
+	})).Return(nil).Twice()
+	mockDB.On("DeleteStaleRecipeEmbeddings", ctx, mock.Anything).Return(nil).Twice()
+
+	// A short page means the corpus is done; no second query or continuation
+	err := processor.HandleBackfillEmbeddings(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockOpenAI.AssertExpectations(t)
+}
+
+func TestHandleBackfillEmbeddings_PagesWithCursor(t *testing.T) {
+	ctx := context.Background()
+	recipeID1 := parseUUID("00000000-0000-0000-0000-000000000001")
+	recipeID2 := parseUUID("00000000-0000-0000-0000-000000000002")
+
+	payloadBytes, _ := json.Marshal(BackfillEmbeddingsPayload{BatchSize: 1, RequestsPerMinute: 60000})
+	task := asynq.NewTask(TypeBackfillEmbeddings, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockOpenAI := new(MockOpenAIClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, mockOpenAI, nil, nil, nil, nil, nil, nil,
+	)
+
+	page := func(after pgtype.UUID) generated.GetRecipesNeedingEmbeddingParams {
+		return generated.GetRecipesNeedingEmbeddingParams{ID: after, Column2: openai.EmbeddingModel, Column3: EmbeddingVersion, Limit: 1}
+	}
+	mockDB.On("GetRecipesNeedingEmbedding", ctx, page(pgtype.UUID{Valid: true})).
+		Return([]generated.GetRecipesNeedingEmbeddingRow{{ID: recipeID1, RecipeName: "A"}}, nil).Once()
+	mockDB.On("GetRecipesNeedingEmbedding", ctx, page(recipeID1)).
+		Return([]generated.GetRecipesNeedingEmbeddingRow{{ID: recipeID2, RecipeName: "B"}}, nil).Once()
+	mockDB.On("GetRecipesNeedingEmbedding", ctx, page(recipeID2)).
+		Return([]generated.GetRecipesNeedingEmbeddingRow{}, nil).Once()
+	mockRecipeFacets(ctx, mockDB, mock.Anything, nil)
+	mockOpenAI.On("GenerateEmbeddings", ctx, mock.Anything).Return([][]float32{{0.1}}, nil).Twice()
+	mockDB.On("UpdateRecipeEmbedding", ctx, mock.Anything).Return(nil).Twice()
+	mockDB.On("UpsertRecipeEmbedding", ctx, mock.Anything).Return(nil).Twice()
+	mockDB.On("DeleteStaleRecipeEmbeddings", ctx, mock.Anything).Return(nil).Twice()
+
+	err := processor.HandleBackfillEmbeddings(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockOpenAI.AssertExpectations(t)
+}
+
+func TestHandleGenerateEmbedding_MultiVector(t *testing.T) {
+	ctx := context.Background()
+	recipeID := parseUUID(uuid.New().String())
+
+	payloadBytes, _ := json.Marshal(GenerateEmbeddingPayload{RecipeID: pgUUIDToString(recipeID)})
+	task := asynq.NewTask(TypeGenerateEmbedding, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockOpenAI := new(MockOpenAIClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, mockOpenAI, nil, nil, nil, nil, nil, nil,
+	)
+	processor.SetEmbeddingConfig(config.EmbeddingConfig{MultiVector: true})
+
+	mockDB.On("GetRecipe", ctx, recipeID).Return(generated.Recipe{ID: recipeID, RecipeName: "Crispy Chickpeas"}, nil)
+	mockDB.On("GetRecipeCategoryNames", ctx, recipeID).Return(generated.GetRecipeCategoryNamesRow{
+		MealTypes:           []string{"Snack"},
+		DietaryRestrictions: []string{"Vegan"},
+		Equipment:           []string{"Air fryer"},
+	}, nil)
+	mockDB.On("GetRecipeParts", ctx, recipeID).Return([]generated.RecipePart{}, nil)
+	mockDB.On("GetIngredientsByRecipe", ctx, recipeID).Return([]generated.RecipeIngredient{{Name: "chickpeas"}}, nil)
+	mockDB.On("GetInstructionsByRecipe", ctx, recipeID).Return([]generated.RecipeInstruction{}, nil)
+	mockOpenAI.On("GenerateEmbeddings", ctx, []string{
+		"Recipe: Crispy Chickpeas\nMeal type: Snack\nDiet: Vegan\nEquipment: Air fryer\nIngredients: chickpeas",
+		"Recipe: Crispy Chickpeas\nIngredients: chickpeas",
+	}).Return([][]float32{{0.1}, {0.2}}, nil).Once()
+	mockDB.On("UpdateRecipeEmbedding", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeEmbeddingParams) bool {
+		return arg.ID == recipeID && arg.Embedding.Slice()[0] == 0.1
+	})).Return(nil).Once()
+	mockDB.On("UpsertRecipeEmbedding", ctx, mock.MatchedBy(func(arg generated.UpsertRecipeEmbeddingParams) bool {
+		return arg.Kind == EmbeddingKindOverview && arg.Embedding.Slice()[0] == 0.1
+	})).Return(nil).Once()
+	mockDB.On("UpsertRecipeEmbedding", ctx, mock.MatchedBy(func(arg generated.UpsertRecipeEmbeddingParams) bool {
+		return arg.Kind == EmbeddingKindIngredients && arg.Embedding.Slice()[0] == 0.2
+	})).Return(nil).Once()
+	mockDB.On("DeleteStaleRecipeEmbeddings", ctx, generated.DeleteStaleRecipeEmbeddingsParams{
+		RecipeID: recipeID,
+		Column2:  []string{EmbeddingKindOverview, EmbeddingKindIngredients},
+	}).Return(nil).Once()
+
+	err := processor.HandleGenerateEmbedding(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockOpenAI.AssertExpectations(t)
+}
+
+// mockRecipeFacets stubs the facet lookups for a recipe with no categories,
+// parts or instructions.
+func mockRecipeFacets(ctx context.Context, mockDB *MockDB, recipeID interface{}, ingredients []generated.RecipeIngredient) {
+	mockDB.On("GetRecipeCategoryNames", ctx, recipeID).Return(generated.GetRecipeCategoryNamesRow{}, nil)
+	mockDB.On("GetRecipeParts", ctx, recipeID).Return([]generated.RecipePart{}, nil)
+	mockDB.On("GetIngredientsByRecipe", ctx, recipeID).Return(ingredients, nil)
+	mockDB.On("GetInstructionsByRecipe", ctx, recipeID).Return([]generated.RecipeInstruction{}, nil)
+}
+
+func TestHandleBackfillEmbeddings_InvalidCursor(t *testing.T) {
+	payloadBytes, _ := json.Marshal(BackfillEmbeddingsPayload{AfterID: "not-a-uuid"})
+	task := asynq.NewTask(TypeBackfillEmbeddings, payloadBytes)
+
+	processor := NewRecipeProcessor(
+		new(MockDB), nil, nil, nil, nil, new(MockOpenAIClient), nil, nil, nil, nil, nil, nil,
+	)
+
+	err := processor.HandleBackfillEmbeddings(context.Background(), task)
+
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid after_id")
+}
+
 func TestSaveInstructionIngredients(t *testing.T) {
 	ctx := context.Background()
 
@@ -1019,3 +1590,765 @@ func TestSaveInstructionIngredients(t *testing.T) {
 		})
 	}
 }
+
+func TestHandleGarbageCollectStorage(t *testing.T) {
+	ctx := context.Background()
+
+	payloadBytes, _ := json.Marshal(GarbageCollectStoragePayload{ContentHashes: []string{"abc", "def"}})
+	task := asynq.NewTask(TypeGarbageCollectStorage, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	// "def" is still referenced, so only "abc" comes back
+	mockDB.On("DeleteUnreferencedStoredImages", ctx, []string{"abc", "def"}).
+		Return([]generated.StoredImage{{ContentHash: "abc", StoragePath: "post_images/abc"}}, nil).Once()
+	mockStorage.On("DeleteObject", ctx, "recipes", "post_images/abc").Return(nil).Once()
+
+	err := processor.HandleGarbageCollectStorage(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleGarbageCollectStorage_SweepsWithoutHashes(t *testing.T) {
+	ctx := context.Background()
+	task := asynq.NewTask(TypeGarbageCollectStorage, nil)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	mockDB.On("DeleteUnreferencedStoredImages", ctx, []string{}).Return([]generated.StoredImage{}, nil).Once()
+
+	err := processor.HandleGarbageCollectStorage(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertNotCalled(t, "DeleteObject", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestHandleGarbageCollectStorage_RestoresImageWhenBlobDeleteFails(t *testing.T) {
+	ctx := context.Background()
+
+	payloadBytes, _ := json.Marshal(GarbageCollectStoragePayload{ContentHashes: []string{"abc", "def"}})
+	task := asynq.NewTask(TypeGarbageCollectStorage, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	imageID := parseUUID("44444444-4444-4444-4444-444444444444")
+	mockDB.On("DeleteUnreferencedStoredImages", ctx, []string{"abc", "def"}).
+		Return([]generated.StoredImage{
+			{ContentHash: "abc", StoragePath: "post_images/abc"},
+			{ID: imageID, ContentHash: "def", StoragePath: "post_images/def", SourceUrl: "https://example.com/def.jpg"},
+		}, nil).Once()
+	mockStorage.On("DeleteObject", ctx, "recipes", "post_images/abc").Return(nil).Once()
+	mockStorage.On("DeleteObject", ctx, "recipes", "post_images/def").Return(fmt.Errorf("storage unavailable")).Once()
+	mockDB.On("RestoreStoredImage", ctx, generated.RestoreStoredImageParams{
+		ID:          imageID,
+		StoragePath: "post_images/def",
+		SourceUrl:   "https://example.com/def.jpg",
+		ContentHash: "def",
+	}).Return(nil).Once()
+
+	err := processor.HandleGarbageCollectStorage(ctx, task)
+
+	assert.Error(t, err, "expected an error so the task is retried")
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleRegenerateRecipe_Draft(t *testing.T) {
+	ctx := context.Background()
+
+	regenID := parseUUID("11111111-1111-1111-1111-111111111111")
+	recipeID := parseUUID("22222222-2222-2222-2222-222222222222")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+
+	payloadBytes, _ := json.Marshal(RegenerateRecipePayload{RegenerationID: pgUUIDToString(regenID)})
+	task := asynq.NewTask(TypeRegenerateRecipe, payloadBytes)
+
+	mockDB := new(MockDB)
+	defaultGroq := new(MockGroqClient)
+	cerebras := new(MockGroqClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, defaultGroq, nil, nil, nil, nil,
+	)
+	processor.SetRecipeProviders(map[string]GroqClient{"cerebras": cerebras})
+
+	mockDB.On("GetRecipeRegeneration", ctx, regenID).Return(generated.RecipeRegeneration{
+		ID:            regenID,
+		RecipeID:      recipeID,
+		Mode:          RegenerationModeDraft,
+		Provider:      "cerebras",
+		PromptVersion: ai.RecipePromptVersion,
+	}, nil)
+	mockDB.On("UpdateRecipeRegenerationStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeRegenerationStatusParams) bool {
+		return arg.Status == "EXECUTING"
+	})).Return(nil).Once()
+	mockDB.On("GetRecipe", ctx, recipeID).Return(generated.Recipe{ID: recipeID, CreatedBy: userID}, nil)
+	mockDB.On("GetRecipeRawData", ctx, recipeID).Return(generated.RecipeRawDatum{
+		RecipeID:   recipeID,
+		Origin:     "instagram",
+		Caption:    pgtype.Text{String: "Pancakes", Valid: true},
+		Transcript: pgtype.Text{String: "Mix flour and milk, then fry.", Valid: true},
+	}, nil)
+
+	cerebras.On("GenerateRecipe", ai.WithRecipePromptVersion(ctx, ai.RecipePromptVersion), "Pancakes", "Mix flour and milk, then fry.", "instagram").Return(&groq.Recipe{
+		RecipeName:   "Pancakes",
+		Ingredients:  []groq.Ingredient{{Name: "flour"}, {Name: "milk"}},
+		Instructions: []groq.Instruction{{StepNumber: 1, Instruction: "Mix flour and milk"}, {StepNumber: 2, Instruction: "Fry"}},
+	}, nil)
+	mockDB.On("GetCuisineCategoriesByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetMealTypesByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetOccasionsByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetDietaryRestrictionsByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetEquipmentByUser", mock.Anything, userID).Return([]string{}, nil)
+	cerebras.On("GenerateCategories", mock.Anything, mock.Anything).Return(&ai.CategoryAIResponse{
+		CuisineCategories: []string{"American"},
+		MealTypes:         []string{"Breakfast"},
+	}, nil)
+	cerebras.On("GenerateRichInstructions", ctx, mock.Anything).Return(&recipeservice.RichInstructionResponse{
+		Instructions: []recipeservice.RichInstruction{
+			{StepNumber: 1, InstructionRich: "Mix the flour and milk"},
+			{StepNumber: 2, InstructionRich: "Fry"},
+		},
+		PromptVersion: 3,
+	}, nil)
+
+	var draft groq.Recipe
+	mockDB.On("UpdateRecipeRegenerationStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeRegenerationStatusParams) bool {
+		return arg.Status == "COMPLETED" && json.Unmarshal(arg.Draft, &draft) == nil
+	})).Return(nil).Once()
+
+	err := processor.HandleRegenerateRecipe(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	defaultGroq.AssertNotCalled(t, "GenerateRecipe", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	// A draft leaves the saved recipe alone
+	mockDB.AssertNotCalled(t, "UpdateRecipeGeneratedFields", mock.Anything, mock.Anything)
+	mockDB.AssertNotCalled(t, "DeleteIngredientsByRecipe", mock.Anything, mock.Anything)
+
+	assert.Equal(t, "Pancakes", draft.RecipeName)
+	assert.Equal(t, []string{"American"}, draft.CuisineCategories)
+	require.Len(t, draft.Instructions, 2)
+	assert.Equal(t, "Mix the flour and milk", draft.Instructions[0].InstructionRich)
+	assert.NotEmpty(t, draft.Ingredients[0].ID)
+}
+
+type failingTxBeginner struct{}
+
+func (failingTxBeginner) Begin(ctx context.Context) (pgx.Tx, error) {
+	return nil, fmt.Errorf("connection refused")
+}
+
+func TestHandleRegenerateRecipe_ReplaceWritesInTransaction(t *testing.T) {
+	ctx := context.Background()
+
+	regenID := parseUUID("11111111-1111-1111-1111-111111111111")
+	recipeID := parseUUID("22222222-2222-2222-2222-222222222222")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+
+	payloadBytes, _ := json.Marshal(RegenerateRecipePayload{RegenerationID: pgUUIDToString(regenID)})
+	task := asynq.NewTask(TypeRegenerateRecipe, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockGroq := new(MockGroqClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, mockGroq, nil, nil, nil, nil,
+	)
+	processor.SetTxBeginner(failingTxBeginner{})
+
+	mockDB.On("GetRecipeRegeneration", ctx, regenID).Return(generated.RecipeRegeneration{
+		ID:            regenID,
+		RecipeID:      recipeID,
+		RequestedBy:   userID,
+		Mode:          RegenerationModeReplace,
+		PromptVersion: ai.RecipePromptVersion,
+	}, nil)
+	mockDB.On("UpdateRecipeRegenerationStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeRegenerationStatusParams) bool {
+		return arg.Status == "EXECUTING"
+	})).Return(nil).Once()
+	mockDB.On("GetRecipe", ctx, recipeID).Return(generated.Recipe{ID: recipeID, CreatedBy: userID}, nil)
+	mockDB.On("GetRecipeRawData", ctx, recipeID).Return(generated.RecipeRawDatum{
+		RecipeID: recipeID,
+		Origin:   "instagram",
+		Caption:  pgtype.Text{String: "Pancakes", Valid: true},
+	}, nil)
+
+	servings := 2
+	mockGroq.On("GenerateRecipe", ai.WithRecipePromptVersion(ctx, ai.RecipePromptVersion), "Pancakes", "", "instagram").Return(&groq.Recipe{
+		RecipeName:        "Pancakes",
+		Description:       "Fluffy pancakes",
+		OriginalServings:  &servings,
+		CuisineCategories: []string{"American"},
+		Ingredients:       []groq.Ingredient{{Name: "flour", Quantity: "200", Unit: "g"}, {Name: "milk", Quantity: "300", Unit: "ml"}, {Name: "egg", Quantity: "1"}},
+		Instructions: []groq.Instruction{
+			{StepNumber: 1, Instruction: "Whisk the flour, milk and egg into a smooth batter"},
+			{StepNumber: 2, Instruction: "Fry ladlefuls of batter in a hot pan until golden"},
+		},
+	}, nil)
+	mockDB.On("GetCuisineCategoriesByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetMealTypesByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetOccasionsByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetDietaryRestrictionsByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockDB.On("GetEquipmentByUser", mock.Anything, userID).Return([]string{}, nil)
+	mockGroq.On("GenerateCategories", mock.Anything, mock.Anything).Return(&ai.CategoryAIResponse{
+		CuisineCategories: []string{"American"},
+	}, nil)
+	mockDB.On("UpdateRecipeRegenerationStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeRegenerationStatusParams) bool {
+		return arg.Status == "FAILED" && errors.ParseDetail(arg.Error).Code == "REGENERATION_FAILED"
+	})).Return(nil).Once()
+
+	err := processor.HandleRegenerateRecipe(ctx, task)
+
+	assert.ErrorIs(t, err, asynq.SkipRetry)
+	mockDB.AssertExpectations(t)
+	// Nothing is written outside the transaction
+	mockDB.AssertNotCalled(t, "UpdateRecipeGeneratedFields", mock.Anything, mock.Anything)
+	mockDB.AssertNotCalled(t, "ClearRecipeCategories", mock.Anything, mock.Anything)
+	mockDB.AssertNotCalled(t, "DeleteIngredientsByRecipe", mock.Anything, mock.Anything)
+}
+
+func TestHandleRegenerateRecipe_UnknownProvider(t *testing.T) {
+	ctx := context.Background()
+
+	regenID := parseUUID("11111111-1111-1111-1111-111111111111")
+	payloadBytes, _ := json.Marshal(RegenerateRecipePayload{RegenerationID: pgUUIDToString(regenID)})
+	task := asynq.NewTask(TypeRegenerateRecipe, payloadBytes)
+
+	mockDB := new(MockDB)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, new(MockGroqClient), nil, nil, nil, nil,
+	)
+
+	mockDB.On("GetRecipeRegeneration", ctx, regenID).Return(generated.RecipeRegeneration{
+		ID:            regenID,
+		Mode:          RegenerationModeReplace,
+		Provider:      "mistral",
+		PromptVersion: ai.RecipePromptVersion,
+	}, nil)
+	mockDB.On("UpdateRecipeRegenerationStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeRegenerationStatusParams) bool {
+		return arg.Status == "EXECUTING"
+	})).Return(nil).Once()
+	mockDB.On("UpdateRecipeRegenerationStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeRegenerationStatusParams) bool {
+		return arg.Status == "FAILED" && errors.ParseDetail(arg.Error).Code == "REGENERATION_FAILED"
+	})).Return(nil).Once()
+
+	err := processor.HandleRegenerateRecipe(ctx, task)
+
+	assert.ErrorIs(t, err, asynq.SkipRetry)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "GetRecipe", mock.Anything, mock.Anything)
+}
+
+func TestHandleExportRecipes(t *testing.T) {
+	ctx := context.Background()
+
+	exportID := parseUUID("44444444-4444-4444-4444-444444444444")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+	recipeID := parseUUID("22222222-2222-2222-2222-222222222222")
+
+	payloadBytes, _ := json.Marshal(ExportRecipesPayload{ExportID: pgUUIDToString(exportID)})
+	task := asynq.NewTask(TypeExportRecipes, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	mockDB.On("GetRecipeExport", ctx, exportID).Return(generated.RecipeExport{
+		ID:     exportID,
+		UserID: userID,
+		Format: "markdown",
+	}, nil)
+	mockDB.On("UpdateRecipeExportStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeExportStatusParams) bool {
+		return arg.Status == "EXECUTING"
+	})).Return(nil).Once()
+	mockDB.On("GetRecipeIDsByUser", ctx, userID).Return([]pgtype.UUID{recipeID}, nil)
+	mockDB.On("GetRecipe", ctx, recipeID).Return(generated.Recipe{ID: recipeID, RecipeName: "Pancakes"}, nil)
+	mockDB.On("GetRecipeParts", ctx, recipeID).Return([]generated.RecipePart{}, nil)
+	mockDB.On("GetIngredientsByRecipe", ctx, recipeID).Return([]generated.RecipeIngredient{
+		{ID: parseUUID(uuid.New().String()), Name: "flour"},
+	}, nil)
+	mockDB.On("GetInstructionsByRecipe", ctx, recipeID).Return([]generated.RecipeInstruction{
+		{ID: parseUUID(uuid.New().String()), StepNumber: 1, Instruction: "Mix"},
+	}, nil)
+	mockDB.On("GetInstructionIngredientsByRecipe", ctx, recipeID).Return([]generated.InstructionIngredient{}, nil)
+	mockDB.On("GetRecipeCategoryNames", ctx, recipeID).Return(generated.GetRecipeCategoryNamesRow{}, nil)
+	mockDB.On("GetNutritionByRecipe", ctx, recipeID).Return(generated.RecipeNutrition{}, nil)
+	mockDB.On("GetImagesByRecipe", ctx, recipeID).Return([]generated.StoredImage{{StoragePath: "post_images/abc"}}, nil)
+	mockStorage.On("GetPublicURL", "recipes", "post_images/abc").Return("https://storage.example.com/post_images/abc")
+
+	archivePath := pgUUIDToString(userID) + "/" + pgUUIDToString(exportID) + ".zip"
+	mockStorage.On("UploadObject", ctx, "exports", archivePath, mock.Anything, "application/zip").Return(nil)
+	mockDB.On("UpdateRecipeExportStatus", ctx, mock.MatchedBy(func(arg generated.UpdateRecipeExportStatusParams) bool {
+		return arg.Status == "COMPLETED" && arg.RecipeCount == 1 && arg.StoragePath.String == archivePath
+	})).Return(nil).Once()
+
+	err := processor.HandleExportRecipes(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleExportAccount(t *testing.T) {
+	ctx := context.Background()
+
+	exportID := parseUUID("44444444-4444-4444-4444-444444444444")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+
+	payloadBytes, _ := json.Marshal(ExportAccountPayload{ExportID: pgUUIDToString(exportID)})
+	task := asynq.NewTask(TypeExportAccount, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	mockDB.On("GetAccountExport", ctx, exportID).Return(generated.AccountExport{ID: exportID, UserID: userID}, nil)
+	mockDB.On("UpdateAccountExportStatus", ctx, mock.MatchedBy(func(arg generated.UpdateAccountExportStatusParams) bool {
+		return arg.Status == AccountRequestExecuting
+	})).Return(nil).Once()
+	mockDB.On("GetAccountData", ctx, userID).Return(generated.GetAccountDataRow{
+		Profile:    []byte(`{"id": "33333333-3333-3333-3333-333333333333"}`),
+		ImportJobs: []byte(`[]`),
+	}, nil)
+	mockDB.On("GetRecipeIDsByUser", ctx, userID).Return([]pgtype.UUID{}, nil)
+
+	archivePath := pgUUIDToString(userID) + "/account-" + pgUUIDToString(exportID) + ".zip"
+	mockStorage.On("UploadObject", ctx, "exports", archivePath, mock.Anything, "application/zip").Return(nil)
+	mockDB.On("UpdateAccountExportStatus", ctx, mock.MatchedBy(func(arg generated.UpdateAccountExportStatusParams) bool {
+		return arg.Status == AccountRequestCompleted && arg.StoragePath.String == archivePath
+	})).Return(nil).Once()
+
+	err := processor.HandleExportAccount(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+}
+
+func TestHandleEraseAccount(t *testing.T) {
+	ctx := context.Background()
+
+	erasureID := parseUUID("55555555-5555-5555-5555-555555555555")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+
+	payloadBytes, _ := json.Marshal(EraseAccountPayload{ErasureID: pgUUIDToString(erasureID)})
+	task := asynq.NewTask(TypeEraseAccount, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	mockAuth := new(MockAuthAdmin)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+	processor.SetAuthAdmin(mockAuth)
+
+	mockDB.On("GetAccountErasure", ctx, erasureID).Return(generated.AccountErasure{
+		ID:     erasureID,
+		UserID: userID,
+		Status: AccountRequestQueued,
+	}, nil)
+	mockDB.On("UpdateAccountErasureStatus", ctx, mock.MatchedBy(func(arg generated.UpdateAccountErasureStatusParams) bool {
+		return arg.Status == AccountRequestExecuting
+	})).Return(nil).Once()
+	mockDB.On("DeleteRecipesByUser", ctx, userID).Return(generated.DeleteRecipesByUserRow{
+		Recipes:       2,
+		ContentHashes: []string{"abc", "shared"},
+	}, nil)
+	mockDB.On("AddAccountErasureImageHashes", ctx, generated.AddAccountErasureImageHashesParams{
+		ImageHashes: []string{"abc", "shared"},
+		ID:          erasureID,
+	}).Return([]string{"abc", "shared"}, nil).Once()
+	// The shared image is still used by another user's recipe
+	mockDB.On("DeleteUnreferencedStoredImages", ctx, []string{"abc", "shared"}).
+		Return([]generated.StoredImage{{ContentHash: "abc", StoragePath: "post_images/abc"}}, nil).Once()
+	mockStorage.On("DeleteObject", ctx, "recipes", "post_images/abc").Return(nil).Once()
+	mockDB.On("ListExportPathsByUser", ctx, userID).Return([]pgtype.Text{
+		{String: "33333333-3333-3333-3333-333333333333/export.zip", Valid: true},
+	}, nil)
+	mockStorage.On("DeleteObject", ctx, "exports", "33333333-3333-3333-3333-333333333333/export.zip").Return(nil).Once()
+	mockDB.On("DeleteExportsByUser", ctx, userID).Return([]pgtype.Text{
+		{String: "33333333-3333-3333-3333-333333333333/export.zip", Valid: true},
+		{},
+	}, nil)
+	mockStorage.On("ListObjects", ctx, "imports", "33333333-3333-3333-3333-333333333333").
+		Return([]string{"33333333-3333-3333-3333-333333333333/bulk-1/recipes.paprikarecipes"}, nil)
+	mockStorage.On("DeleteObject", ctx, "imports", "33333333-3333-3333-3333-333333333333/bulk-1/recipes.paprikarecipes").Return(nil).Once()
+	mockDB.On("EraseUserRecords", ctx, userID).Return(generated.EraseUserRecordsRow{ImportJobs: 3, Profiles: 1}, nil)
+	mockAuth.On("DeleteUser", ctx, "33333333-3333-3333-3333-333333333333").Return(nil).Once()
+	mockDB.On("UpdateAccountErasureStatus", ctx, mock.MatchedBy(func(arg generated.UpdateAccountErasureStatusParams) bool {
+		var summary map[string]int64
+		if arg.Status != AccountRequestCompleted || json.Unmarshal(arg.Summary, &summary) != nil {
+			return false
+		}
+		return summary["recipes"] == 2 && summary["images"] == 1 && summary["exports"] == 2 &&
+			summary["import_files"] == 1 && summary["import_jobs"] == 3
+	})).Return(nil).Once()
+
+	err := processor.HandleEraseAccount(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+	mockAuth.AssertExpectations(t)
+}
+
+func TestHandleEraseAccount_NoImages(t *testing.T) {
+	ctx := context.Background()
+
+	erasureID := parseUUID("55555555-5555-5555-5555-555555555555")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+
+	payloadBytes, _ := json.Marshal(EraseAccountPayload{ErasureID: pgUUIDToString(erasureID)})
+	task := asynq.NewTask(TypeEraseAccount, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	mockAuth := new(MockAuthAdmin)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+	processor.SetAuthAdmin(mockAuth)
+
+	mockDB.On("GetAccountErasure", ctx, erasureID).Return(generated.AccountErasure{ID: erasureID, UserID: userID}, nil)
+	mockDB.On("UpdateAccountErasureStatus", ctx, mock.Anything).Return(nil).Twice()
+	mockDB.On("DeleteRecipesByUser", ctx, userID).Return(generated.DeleteRecipesByUserRow{ContentHashes: []string{}}, nil)
+	mockDB.On("ListExportPathsByUser", ctx, userID).Return([]pgtype.Text{}, nil)
+	mockDB.On("DeleteExportsByUser", ctx, userID).Return([]pgtype.Text{}, nil)
+	mockStorage.On("ListObjects", ctx, "imports", "33333333-3333-3333-3333-333333333333").Return([]string{}, nil)
+	mockDB.On("EraseUserRecords", ctx, userID).Return(generated.EraseUserRecordsRow{}, nil)
+	mockAuth.On("DeleteUser", ctx, "33333333-3333-3333-3333-333333333333").Return(nil).Once()
+
+	err := processor.HandleEraseAccount(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	// An empty hash list would sweep every unreferenced image
+	mockDB.AssertNotCalled(t, "DeleteUnreferencedStoredImages", mock.Anything, mock.Anything)
+}
+
+func TestHandleEraseAccount_FailsWhenImageDeletionFails(t *testing.T) {
+	ctx := context.Background()
+
+	erasureID := parseUUID("55555555-5555-5555-5555-555555555555")
+	userID := parseUUID("33333333-3333-3333-3333-333333333333")
+
+	payloadBytes, _ := json.Marshal(EraseAccountPayload{ErasureID: pgUUIDToString(erasureID)})
+	task := asynq.NewTask(TypeEraseAccount, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	mockAuth := new(MockAuthAdmin)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+	processor.SetAuthAdmin(mockAuth)
+
+	// A retry: the recipes went in the first attempt, which recorded the
+	// images they released
+	mockDB.On("GetAccountErasure", ctx, erasureID).Return(generated.AccountErasure{
+		ID:          erasureID,
+		UserID:      userID,
+		Status:      AccountRequestFailed,
+		ImageHashes: []string{"abc"},
+	}, nil)
+	mockDB.On("UpdateAccountErasureStatus", ctx, mock.MatchedBy(func(arg generated.UpdateAccountErasureStatusParams) bool {
+		return arg.Status == AccountRequestExecuting
+	})).Return(nil).Once()
+	mockDB.On("DeleteRecipesByUser", ctx, userID).Return(generated.DeleteRecipesByUserRow{ContentHashes: []string{}}, nil)
+	mockDB.On("DeleteUnreferencedStoredImages", ctx, []string{"abc"}).
+		Return([]generated.StoredImage{{ContentHash: "abc", StoragePath: "post_images/abc"}}, nil).Once()
+	mockStorage.On("DeleteObject", ctx, "recipes", "post_images/abc").Return(fmt.Errorf("storage unavailable")).Once()
+	mockDB.On("RestoreStoredImage", ctx, mock.Anything).Return(nil).Once()
+	mockDB.On("UpdateAccountErasureStatus", ctx, mock.MatchedBy(func(arg generated.UpdateAccountErasureStatusParams) bool {
+		return arg.Status == AccountRequestFailed
+	})).Return(nil).Once()
+
+	err := processor.HandleEraseAccount(ctx, task)
+
+	assert.Error(t, err)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "EraseUserRecords", mock.Anything, mock.Anything)
+	mockAuth.AssertNotCalled(t, "DeleteUser", mock.Anything, mock.Anything)
+}
+
+func TestHandleImportRecipeFile(t *testing.T) {
+	ctx := context.Background()
+
+	bulkJobID := "bulk-1"
+	userID := "33333333-3333-3333-3333-333333333333"
+	recipeID := parseUUID("22222222-2222-2222-2222-222222222222")
+	file := []byte(`[
+		{"@context": "https://schema.org", "@type": "Recipe", "name": "Pancakes", "url": "https://example.com/pancakes",
+		 "recipeIngredient": ["200 g flour"], "recipeInstructions": ["Mix and fry for 3 minutes"]},
+		{"@type": "Recipe", "recipeIngredient": ["1 egg"]}
+	]`)
+
+	payloadBytes, _ := json.Marshal(ImportRecipeFilePayload{
+		BulkJobID:   bulkJobID,
+		UserID:      userID,
+		StoragePath: userID + "/import.json",
+		Filename:    "import.json",
+	})
+	task := asynq.NewTask(TypeImportRecipeFile, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	mockDB.On("UpdateBulkImportJobStatus", ctx, generated.UpdateBulkImportJobStatusParams{JobID: bulkJobID, Status: "EXECUTING"}).Return(nil)
+	mockStorage.On("DownloadObject", ctx, "imports", userID+"/import.json").Return(file, nil)
+	mockDB.On("GetBulkImportJobByJobID", ctx, bulkJobID).Return(generated.BulkImportJob{JobID: bulkJobID, Status: "EXECUTING", TotalUrls: 2}, nil)
+	mockDB.On("CreateImportJob", ctx, mock.MatchedBy(func(arg generated.CreateImportJobParams) bool {
+		return arg.Origin == "file"
+	})).Return(generated.RecipeImportJob{}, nil).Twice()
+	mockDB.On("UpdateImportJobWithBulkID", ctx, mock.Anything).Return(nil).Twice()
+	mockDB.On("SetImportJobResult", ctx, mock.Anything).Return(nil)
+	mockDB.On("UpdateImportJobStatus", ctx, mock.Anything).Return(nil)
+
+	mockDB.On("CreateRecipe", ctx, mock.MatchedBy(func(arg generated.CreateRecipeParams) bool {
+		return arg.RecipeName == "Pancakes" && arg.Origin == generated.RecipeOriginFile && arg.Url == "https://example.com/pancakes"
+	})).Return(generated.Recipe{ID: recipeID}, nil).Once()
+	mockDB.On("CreateRecipeRawData", ctx, mock.MatchedBy(func(arg generated.CreateRecipeRawDataParams) bool {
+		return arg.Origin == "file" && strings.Contains(arg.Caption.String, "200 g flour")
+	})).Return(generated.RecipeRawDatum{}, nil)
+	mockDB.On("CreateIngredient", ctx, mock.MatchedBy(func(arg generated.CreateIngredientParams) bool {
+		return arg.Name == "flour" && arg.TotalQuantity.String == "200" && arg.Unit.String == "g"
+	})).Return(generated.RecipeIngredient{ID: parseUUID(uuid.New().String())}, nil)
+	mockDB.On("CreateInstruction", ctx, mock.MatchedBy(func(arg generated.CreateInstructionParams) bool {
+		return arg.Instruction == "Mix and fry for 3 minutes" && strings.Contains(string(arg.TimerData), `"duration_seconds":180`)
+	})).Return(generated.RecipeInstruction{ID: parseUUID(uuid.New().String()), StepNumber: 1}, nil)
+
+	mockDB.On("IncrementBulkImportCounters", ctx, generated.IncrementBulkImportCountersParams{
+		JobID:        bulkJobID,
+		SuccessCount: pgtype.Int4{Int32: 1, Valid: true},
+		FailedCount:  pgtype.Int4{Int32: 0, Valid: true},
+	}).Return(nil).Once()
+	mockDB.On("IncrementBulkImportCounters", ctx, generated.IncrementBulkImportCountersParams{
+		JobID:        bulkJobID,
+		SuccessCount: pgtype.Int4{Int32: 0, Valid: true},
+		FailedCount:  pgtype.Int4{Int32: 1, Valid: true},
+	}).Return(nil).Once()
+	mockDB.On("UpdateBulkImportJobStatus", ctx, generated.UpdateBulkImportJobStatusParams{JobID: bulkJobID, Status: "COMPLETED"}).Return(nil).Maybe()
+	mockStorage.On("DeleteObject", ctx, "imports", userID+"/import.json").Return(nil)
+
+	err := processor.HandleImportRecipeFile(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockStorage.AssertExpectations(t)
+	mockDB.AssertCalled(t, "SetImportJobResult", ctx, mock.MatchedBy(func(arg generated.SetImportJobResultParams) bool {
+		return strings.Contains(string(arg.Result), `"recipe_id":"22222222-2222-2222-2222-222222222222"`)
+	}))
+	mockDB.AssertCalled(t, "UpdateImportJobStatus", ctx, mock.MatchedBy(func(arg generated.UpdateImportJobStatusParams) bool {
+		return arg.Status == "FAILED" && strings.Contains(string(arg.Error), "no name")
+	}))
+}
+
+func TestHandleImportRecipeFile_ResumesAfterProcessedRecipes(t *testing.T) {
+	ctx := context.Background()
+
+	bulkJobID := "bulk-1"
+	userID := "33333333-3333-3333-3333-333333333333"
+	file := []byte(`[
+		{"@type": "Recipe", "name": "Pancakes", "recipeIngredient": ["200 g flour"]},
+		{"@type": "Recipe", "recipeIngredient": ["1 egg"]}
+	]`)
+
+	payloadBytes, _ := json.Marshal(ImportRecipeFilePayload{
+		BulkJobID:   bulkJobID,
+		UserID:      userID,
+		StoragePath: userID + "/import.json",
+		Filename:    "import.json",
+	})
+	task := asynq.NewTask(TypeImportRecipeFile, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	// An earlier attempt imported the first recipe before it was cut off
+	mockDB.On("UpdateBulkImportJobStatus", ctx, mock.Anything).Return(nil)
+	mockStorage.On("DownloadObject", ctx, "imports", userID+"/import.json").Return(file, nil)
+	mockDB.On("GetBulkImportJobByJobID", ctx, bulkJobID).Return(generated.BulkImportJob{
+		JobID:          bulkJobID,
+		Status:         "EXECUTING",
+		TotalUrls:      2,
+		ProcessedCount: pgtype.Int4{Int32: 1, Valid: true},
+	}, nil)
+	mockDB.On("CreateImportJob", ctx, mock.Anything).Return(generated.RecipeImportJob{}, nil).Once()
+	mockDB.On("UpdateImportJobWithBulkID", ctx, mock.Anything).Return(nil).Once()
+	mockDB.On("SetImportJobResult", ctx, mock.Anything).Return(nil)
+	mockDB.On("UpdateImportJobStatus", ctx, mock.Anything).Return(nil)
+	mockDB.On("IncrementBulkImportCounters", ctx, generated.IncrementBulkImportCountersParams{
+		JobID:        bulkJobID,
+		SuccessCount: pgtype.Int4{Int32: 0, Valid: true},
+		FailedCount:  pgtype.Int4{Int32: 1, Valid: true},
+	}).Return(nil).Once()
+	mockStorage.On("DeleteObject", ctx, "imports", userID+"/import.json").Return(nil)
+
+	err := processor.HandleImportRecipeFile(ctx, task)
+
+	assert.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "CreateRecipe", mock.Anything, mock.Anything)
+}
+
+func TestFileImageClient_RefusesPrivateAddresses(t *testing.T) {
+	hit := false
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer srv.Close()
+
+	_, err := downloadImageWith(context.Background(), fileImageClient(), srv.URL+"/image.jpg")
+
+	assert.ErrorIs(t, err, webhook.ErrPrivateAddress)
+	assert.False(t, hit, "expected no request to reach a private address")
+}
+
+func TestHandleImportRecipeFile_UnreadableFile(t *testing.T) {
+	ctx := context.Background()
+
+	payloadBytes, _ := json.Marshal(ImportRecipeFilePayload{BulkJobID: "bulk-1", StoragePath: "u/notes.txt", Filename: "notes.txt"})
+	task := asynq.NewTask(TypeImportRecipeFile, payloadBytes)
+
+	mockDB := new(MockDB)
+	mockStorage := new(MockStorageClient)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, mockStorage, nil, nil, nil,
+	)
+
+	mockDB.On("UpdateBulkImportJobStatus", ctx, mock.MatchedBy(func(arg generated.UpdateBulkImportJobStatusParams) bool {
+		return arg.Status == "EXECUTING"
+	})).Return(nil).Once()
+	mockStorage.On("DownloadObject", ctx, "imports", "u/notes.txt").Return([]byte("not a recipe"), nil)
+	mockDB.On("UpdateBulkImportJobStatus", ctx, mock.MatchedBy(func(arg generated.UpdateBulkImportJobStatusParams) bool {
+		return arg.Status == "FAILED"
+	})).Return(nil).Once()
+
+	err := processor.HandleImportRecipeFile(ctx, task)
+
+	assert.ErrorIs(t, err, asynq.SkipRetry)
+	mockDB.AssertExpectations(t)
+	mockDB.AssertNotCalled(t, "CreateImportJob", mock.Anything, mock.Anything)
+}
+
+func TestHandleDeliverWebhook(t *testing.T) {
+	ctx := context.Background()
+
+	var received http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Clone()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	deliveryID := "44444444-4444-4444-4444-444444444444"
+	subID := parseUUID("55555555-5555-5555-5555-555555555555")
+	payloadBytes, _ := json.Marshal(DeliverWebhookPayload{DeliveryID: deliveryID})
+	task := asynq.NewTask(TypeDeliverWebhook, payloadBytes)
+
+	mockDB := new(MockDB)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+	)
+	// The test server listens on loopback, which the default client refuses
+	processor.SetWebhookClient(webhook.NewClient(srv.Client(), utils.WebhookRetryConfig()))
+
+	mockDB.On("GetWebhookDelivery", ctx, parseUUID(deliveryID)).Return(generated.WebhookDelivery{
+		ID:             parseUUID(deliveryID),
+		SubscriptionID: subID,
+		Event:          webhook.EventImportCompleted,
+		Payload:        []byte(`{"type":"import.completed"}`),
+		Status:         WebhookDeliveryPending,
+	}, nil)
+	mockDB.On("GetWebhookSubscription", ctx, subID).Return(generated.WebhookSubscription{
+		ID:     subID,
+		Url:    srv.URL,
+		Secret: "whsec_test",
+		Active: true,
+	}, nil)
+	mockDB.On("UpdateWebhookDeliveryResult", ctx, generated.UpdateWebhookDeliveryResultParams{
+		ID:             parseUUID(deliveryID),
+		Status:         WebhookDeliverySucceeded,
+		Attempts:       1,
+		ResponseStatus: pgtype.Int4{Int32: http.StatusOK, Valid: true},
+	}).Return(nil)
+
+	err := processor.HandleDeliverWebhook(ctx, task)
+
+	require.NoError(t, err)
+	mockDB.AssertExpectations(t)
+	assert.Equal(t, webhook.EventImportCompleted, received.Get(webhook.HeaderEvent))
+	assert.Equal(t, deliveryID, received.Get(webhook.HeaderDelivery))
+	assert.True(t, strings.HasPrefix(received.Get(webhook.HeaderSignature), "t="))
+}
+
+func TestHandleDeliverWebhook_Failed(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	deliveryID := "44444444-4444-4444-4444-444444444444"
+	subID := parseUUID("55555555-5555-5555-5555-555555555555")
+	payloadBytes, _ := json.Marshal(DeliverWebhookPayload{DeliveryID: deliveryID})
+	task := asynq.NewTask(TypeDeliverWebhook, payloadBytes)
+
+	mockDB := new(MockDB)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil,
+	)
+	retry := utils.WebhookRetryConfig()
+	retry.MaxAttempts = 2
+	retry.InitialDelay = time.Millisecond
+	processor.SetWebhookClient(webhook.NewClient(srv.Client(), retry))
+
+	mockDB.On("GetWebhookDelivery", ctx, parseUUID(deliveryID)).Return(generated.WebhookDelivery{
+		ID:             parseUUID(deliveryID),
+		SubscriptionID: subID,
+		Event:          webhook.EventImportFailed,
+		Payload:        []byte(`{}`),
+		Status:         WebhookDeliveryPending,
+	}, nil)
+	mockDB.On("GetWebhookSubscription", ctx, subID).Return(generated.WebhookSubscription{
+		ID:     subID,
+		Url:    srv.URL,
+		Secret: "whsec_test",
+		Active: true,
+	}, nil)
+	mockDB.On("UpdateWebhookDeliveryResult", ctx, mock.MatchedBy(func(arg generated.UpdateWebhookDeliveryResultParams) bool {
+		return arg.Status == WebhookDeliveryFailed && arg.Attempts == 2 &&
+			arg.ResponseStatus.Int32 == http.StatusServiceUnavailable && errors.ParseDetail(arg.Error).Code == "WEBHOOK_DELIVERY_FAILED"
+	})).Return(nil)
+
+	// The failure is in the delivery log; asynq must not deliver again
+	err := processor.HandleDeliverWebhook(ctx, task)
+
+	require.NoError(t, err)
+	mockDB.AssertExpectations(t)
+}
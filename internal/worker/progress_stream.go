@@ -0,0 +1,204 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Progress events are kept in a capped Redis stream per user so that clients
+// can resume after reconnecting, and announced on a pub/sub channel.
+const (
+	progressStreamMaxLen = 500
+	progressStreamTTL    = 24 * time.Hour
+)
+
+func progressStreamKey(userID string) string {
+	return fmt.Sprintf("progress:%s:events", userID)
+}
+
+func progressChannel(userID string) string {
+	return fmt.Sprintf("progress:%s", userID)
+}
+
+// ProgressEvent is a progress update with its stream ID, which clients send
+// back as Last-Event-ID to resume.
+type ProgressEvent struct {
+	ID     string         `json:"id"`
+	Update ProgressUpdate `json:"update"`
+}
+
+// ProgressStream publishes progress updates through Redis and streams them
+// to subscribers, such as the API server's SSE endpoint.
+type ProgressStream struct {
+	client *redis.Client
+}
+
+func NewProgressStream(client *redis.Client) *ProgressStream {
+	return &ProgressStream{client: client}
+}
+
+// Broadcast records an update in the user's event stream and publishes it
+// to the user's subscribers.
+func (s *ProgressStream) Broadcast(userID string, update ProgressUpdate) error {
+	ctx := context.Background()
+
+	data, err := json.Marshal(update)
+	if err != nil {
+		return fmt.Errorf("failed to marshal progress update: %w", err)
+	}
+
+	key := progressStreamKey(userID)
+	id, err := s.client.XAdd(ctx, &redis.XAddArgs{
+		Stream: key,
+		MaxLen: progressStreamMaxLen,
+		Approx: true,
+		Values: map[string]interface{}{"data": data},
+	}).Result()
+	if err != nil {
+		return fmt.Errorf("failed to record progress event: %w", err)
+	}
+	if err := s.client.Expire(ctx, key, progressStreamTTL).Err(); err != nil {
+		slog.Warn("Failed to set progress stream expiry", "error", err, "user_id", userID)
+	}
+
+	message, err := json.Marshal(ProgressEvent{ID: id, Update: update})
+	if err != nil {
+		return fmt.Errorf("failed to marshal progress event: %w", err)
+	}
+	if err := s.client.Publish(ctx, progressChannel(userID), message).Err(); err != nil {
+		return fmt.Errorf("failed to publish progress event: %w", err)
+	}
+	return nil
+}
+
+// Subscribe streams a user's progress events until ctx is done. With a
+// lastEventID the events recorded after it are replayed first; without one
+// only new events are sent.
+func (s *ProgressStream) Subscribe(ctx context.Context, userID, lastEventID string) (<-chan ProgressEvent, error) {
+	sub := s.client.Subscribe(ctx, progressChannel(userID))
+	// Wait for the subscription so no event falls between replay and live
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, fmt.Errorf("failed to subscribe to progress events: %w", err)
+	}
+
+	var replay []ProgressEvent
+	if _, ok := parseStreamID(lastEventID); ok {
+		messages, err := s.client.XRange(ctx, progressStreamKey(userID), "("+lastEventID, "+").Result()
+		if err != nil {
+			sub.Close()
+			return nil, fmt.Errorf("failed to read progress events: %w", err)
+		}
+		for _, msg := range messages {
+			event, err := decodeStreamMessage(msg)
+			if err != nil {
+				slog.Warn("Skipping unreadable progress event", "error", err, "id", msg.ID)
+				continue
+			}
+			replay = append(replay, event)
+		}
+	} else {
+		lastEventID = ""
+	}
+
+	events := make(chan ProgressEvent)
+	go func() {
+		defer close(events)
+		defer sub.Close()
+
+		last := lastEventID
+		send := func(event ProgressEvent) bool {
+			// Live events may repeat the tail of the replay
+			if last != "" && compareStreamIDs(event.ID, last) <= 0 {
+				return true
+			}
+			select {
+			case events <- event:
+				last = event.ID
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for _, event := range replay {
+			if !send(event) {
+				return
+			}
+		}
+
+		messages := sub.Channel()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-messages:
+				if !ok {
+					return
+				}
+				var event ProgressEvent
+				if err := json.Unmarshal([]byte(msg.Payload), &event); err != nil {
+					slog.Warn("Skipping unreadable progress event", "error", err)
+					continue
+				}
+				if !send(event) {
+					return
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func decodeStreamMessage(msg redis.XMessage) (ProgressEvent, error) {
+	data, ok := msg.Values["data"].(string)
+	if !ok {
+		return ProgressEvent{}, fmt.Errorf("progress event has no data")
+	}
+	event := ProgressEvent{ID: msg.ID}
+	if err := json.Unmarshal([]byte(data), &event.Update); err != nil {
+		return ProgressEvent{}, err
+	}
+	return event, nil
+}
+
+// parseStreamID splits a Redis stream ID such as "1700000000000-0" into its
+// millisecond time and sequence number.
+func parseStreamID(id string) ([2]uint64, bool) {
+	msPart, seqPart, ok := strings.Cut(id, "-")
+	if !ok {
+		return [2]uint64{}, false
+	}
+	ms, err := strconv.ParseUint(msPart, 10, 64)
+	if err != nil {
+		return [2]uint64{}, false
+	}
+	seq, err := strconv.ParseUint(seqPart, 10, 64)
+	if err != nil {
+		return [2]uint64{}, false
+	}
+	return [2]uint64{ms, seq}, true
+}
+
+// compareStreamIDs orders two stream IDs; unreadable IDs sort first.
+func compareStreamIDs(a, b string) int {
+	pa, _ := parseStreamID(a)
+	pb, _ := parseStreamID(b)
+	for i := range pa {
+		switch {
+		case pa[i] < pb[i]:
+			return -1
+		case pa[i] > pb[i]:
+			return 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCompareStreamIDs(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"1700000000000-0", "1700000000000-0", 0},
+		{"1700000000000-1", "1700000000000-0", 1},
+		{"1700000000000-9", "1700000000001-0", -1},
+		{"9-0", "10-0", -1},
+		{"garbage", "1-0", -1},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, compareStreamIDs(tt.a, tt.b), "%s vs %s", tt.a, tt.b)
+	}
+}
+
+func TestMultiBroadcaster(t *testing.T) {
+	update := ProgressUpdate{JobID: "job-1", Status: "EXECUTING"}
+	first := new(MockBroadcaster)
+	first.On("Broadcast", "user-1", update).Return(errors.New("supabase down"))
+	second := new(MockBroadcaster)
+	second.On("Broadcast", "user-1", update).Return(nil)
+
+	err := MultiBroadcaster{first, second}.Broadcast("user-1", update)
+
+	assert.EqualError(t, err, "supabase down")
+	first.AssertExpectations(t)
+	second.AssertExpectations(t)
+}
+
+func TestRecordBulkResult_BroadcastsProgress(t *testing.T) {
+	ctx := context.Background()
+	userID := "33333333-3333-3333-3333-333333333333"
+	bulkJobID := "bulk-1"
+
+	mockDB := new(MockDB)
+	mockBroadcaster := new(MockBroadcaster)
+	processor := NewRecipeProcessor(
+		mockDB, nil, nil, nil, nil, nil, nil, nil, nil, mockBroadcaster, nil, nil,
+	)
+
+	mockDB.On("IncrementBulkImportCounters", ctx, mock.Anything).Return(nil)
+	mockDB.On("GetBulkImportJobByJobID", ctx, bulkJobID).Return(generated.BulkImportJob{
+		JobID:          bulkJobID,
+		UserID:         parseUUID(userID),
+		Status:         "EXECUTING",
+		TotalUrls:      3,
+		ProcessedCount: pgtype.Int4{Int32: 1, Valid: true},
+		SuccessCount:   pgtype.Int4{Int32: 1, Valid: true},
+		FailedCount:    pgtype.Int4{Int32: 0, Valid: true},
+	}, nil)
+	mockBroadcaster.On("Broadcast", userID, ProgressUpdate{
+		BulkJobID: bulkJobID,
+		Status:    "EXECUTING",
+		Message:   "Recipe imported",
+		Progress:  &BulkProgress{Total: 3, Processed: 1, Success: 1, Failed: 0},
+	}).Return(nil)
+
+	processor.recordBulkResult(ctx, bulkJobID, true)
+
+	mockDB.AssertExpectations(t)
+	mockBroadcaster.AssertExpectations(t)
+}
@@ -46,3 +46,8 @@ func Start(srv *asynq.Server, handlers map[string]asynq.HandlerFunc) error {
 	}
 	return srv.Start(mux)
 }
+
+// NewScheduler creates a new Asynq scheduler for periodic tasks
+func NewScheduler(redisURL string) *asynq.Scheduler {
+	return asynq.NewSchedulerFromRedisClient(NewRedisClient(redisURL), nil)
+}
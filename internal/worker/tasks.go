@@ -2,6 +2,7 @@ package worker
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/hibiken/asynq"
 )
@@ -21,14 +22,32 @@ const (
 	TypeCleanupJobs              = "cleanup:jobs"
 	TypeInstagramRetry           = "instagram:retry"
 	TypeProcessBulkImport        = "process:bulk-import"
+	TypeBackfillEmbeddings       = "backfill:embeddings"
+	TypeGarbageCollectStorage    = "gc:storage"
+	TypeRegenerateRecipe         = "regenerate:recipe"
+	TypeExportRecipes            = "export:recipes"
+	TypeImportRecipeFile         = "import:recipe-file"
+	TypeDeliverWebhook           = "deliver:webhook"
+	TypeExportAccount            = "export:account"
+	TypeEraseAccount             = "erase:account"
 )
 
-// ProcessRecipePayload is the payload for recipe processing tasks
+// ProcessRecipePayload is the payload for recipe processing tasks. Manual
+// recipes have no URL; they carry pasted Text or the URL of an uploaded
+// photo in ImageURL instead.
 type ProcessRecipePayload struct {
 	JobID     string `json:"job_id"`
 	URL       string `json:"url"`
 	UserID    string `json:"user_id"`
 	BulkJobID string `json:"bulk_job_id,omitempty"`
+	Text      string `json:"text,omitempty"`
+	ImageURL  string `json:"image_url,omitempty"`
+}
+
+// IsManual reports whether the recipe comes from pasted text or a photo
+// rather than a URL.
+func (p ProcessRecipePayload) IsManual() bool {
+	return p.Text != "" || p.ImageURL != ""
 }
 
 // GenerateEmbeddingPayload is the payload for embedding tasks
@@ -54,6 +73,65 @@ type ProcessBulkImportPayload struct {
 	UserID    string   `json:"user_id"`
 }
 
+// BackfillEmbeddingsPayload is the payload for embedding backfill tasks.
+// Zero values fall back to the handler defaults.
+type BackfillEmbeddingsPayload struct {
+	BatchSize         int `json:"batch_size,omitempty"`
+	MaxBatches        int `json:"max_batches,omitempty"`
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"`
+	// AfterID is the keyset cursor a continuation task resumes from
+	AfterID string `json:"after_id,omitempty"`
+}
+
+// GarbageCollectStoragePayload is the payload for storage garbage collection
+// tasks. With no content hashes every unreferenced image is collected.
+type GarbageCollectStoragePayload struct {
+	ContentHashes []string `json:"content_hashes,omitempty"`
+}
+
+// RegenerateRecipePayload is the payload for recipe regeneration tasks. The
+// mode, provider and prompt version are read from the regeneration row.
+type RegenerateRecipePayload struct {
+	RegenerationID string `json:"regeneration_id"`
+}
+
+// ExportRecipesPayload is the payload for bulk recipe export tasks. The
+// user and format are read from the export row.
+type ExportRecipesPayload struct {
+	ExportID string `json:"export_id"`
+}
+
+// ImportRecipeFilePayload is the payload for importing an export file from
+// another recipe manager. The file waits in the imports bucket at
+// StoragePath; an empty Format is detected from the file.
+type ImportRecipeFilePayload struct {
+	BulkJobID   string `json:"bulk_job_id"`
+	UserID      string `json:"user_id"`
+	StoragePath string `json:"storage_path"`
+	Filename    string `json:"filename"`
+	Format      string `json:"format,omitempty"`
+	// Enrich suggests categories and generates rich instructions with AI
+	Enrich bool `json:"enrich,omitempty"`
+}
+
+// DeliverWebhookPayload is the payload for webhook delivery tasks. The
+// event body and subscription are read from the delivery row.
+type DeliverWebhookPayload struct {
+	DeliveryID string `json:"delivery_id"`
+}
+
+// ExportAccountPayload is the payload for account data export tasks. The
+// user is read from the export row.
+type ExportAccountPayload struct {
+	ExportID string `json:"export_id"`
+}
+
+// EraseAccountPayload is the payload for account erasure tasks. The user is
+// read from the erasure row.
+type EraseAccountPayload struct {
+	ErasureID string `json:"erasure_id"`
+}
+
 // NewProcessRecipeTask creates a new process recipe task
 func NewProcessRecipeTask(payload ProcessRecipePayload) (*asynq.Task, error) {
 	data, err := json.Marshal(payload)
@@ -104,7 +182,94 @@ func NewProcessBulkImportTask(payload ProcessBulkImportPayload) (*asynq.Task, er
 	return asynq.NewTask(TypeProcessBulkImport, data, asynq.Queue("bulk_import")), nil
 }
 
+// EmbeddingBackfillUniqueTTL is how long an enqueued backfill task keeps
+// another with the same payload from being enqueued. The lock is released
+// once the task succeeds, and an archived task holds it no longer than this.
+const EmbeddingBackfillUniqueTTL = time.Hour
+
+// NewBackfillEmbeddingsTask creates a new embedding backfill task with low
+// priority. Tasks are unique by payload, so scheduled and manual runs with
+// the same options are not queued twice, and runs that reach the same
+// cursor continue as one chain.
+func NewBackfillEmbeddingsTask(payload BackfillEmbeddingsPayload, opts ...asynq.Option) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	defaults := []asynq.Option{asynq.Queue("bulk_import"), asynq.Unique(EmbeddingBackfillUniqueTTL)}
+	return asynq.NewTask(TypeBackfillEmbeddings, data, append(defaults, opts...)...), nil
+}
+
+// NewGarbageCollectStorageTask creates a new storage garbage collection task
+func NewGarbageCollectStorageTask(payload GarbageCollectStoragePayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeGarbageCollectStorage, data), nil
+}
+
 // Queue returns an asynq Queue option
 func Queue(name string) asynq.Option {
 	return asynq.Queue(name)
 }
+
+// NewRegenerateRecipeTask creates a new recipe regeneration task
+func NewRegenerateRecipeTask(payload RegenerateRecipePayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeRegenerateRecipe, data, asynq.MaxRetry(1)), nil
+}
+
+// NewExportRecipesTask creates a new bulk recipe export task with low priority
+func NewExportRecipesTask(payload ExportRecipesPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeExportRecipes, data, asynq.Queue("bulk_import"), asynq.MaxRetry(3)), nil
+}
+
+// NewImportRecipeFileTask creates a new recipe file import task with low
+// priority. A file may hold thousands of recipes, each maybe enriched with
+// AI, so the task gets a long timeout; a retry resumes after the recipes
+// already imported.
+func NewImportRecipeFileTask(payload ImportRecipeFilePayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeImportRecipeFile, data, asynq.Queue("bulk_import"), asynq.MaxRetry(3), asynq.Timeout(2*time.Hour)), nil
+}
+
+// NewDeliverWebhookTask creates a new webhook delivery task. Failed requests
+// are retried inside the task; asynq only retries when the delivery log
+// can't be read or written.
+func NewDeliverWebhookTask(payload DeliverWebhookPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeDeliverWebhook, data, asynq.MaxRetry(3)), nil
+}
+
+// NewExportAccountTask creates a new account data export task with low priority
+func NewExportAccountTask(payload ExportAccountPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeExportAccount, data, asynq.Queue("bulk_import"), asynq.MaxRetry(3)), nil
+}
+
+// NewEraseAccountTask creates a new account erasure task. Every step of an
+// erasure can be repeated, so failed tasks are retried.
+func NewEraseAccountTask(payload EraseAccountPayload) (*asynq.Task, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	return asynq.NewTask(TypeEraseAccount, data, asynq.MaxRetry(5)), nil
+}
@@ -0,0 +1,168 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/socialchef/remy/internal/db/generated"
+	"github.com/socialchef/remy/internal/errors"
+	"github.com/socialchef/remy/internal/services/webhook"
+)
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliverySucceeded = "SUCCEEDED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+// WebhookQueries is the database access needed to queue webhook deliveries;
+// *generated.Queries satisfies it.
+type WebhookQueries interface {
+	ListWebhookSubscriptionsForEvent(ctx context.Context, arg generated.ListWebhookSubscriptionsForEventParams) ([]generated.WebhookSubscription, error)
+	CreateWebhookDelivery(ctx context.Context, arg generated.CreateWebhookDeliveryParams) (generated.WebhookDelivery, error)
+}
+
+// EmitWebhookEvent queues a delivery of an event to every active
+// subscription of the user, and to every service wide subscription, that
+// wants it. Nothing is sent without an asynq client.
+func EmitWebhookEvent(ctx context.Context, db WebhookQueries, client *asynq.Client, userID, event string, data interface{}) error {
+	if client == nil {
+		return nil
+	}
+
+	subs, err := db.ListWebhookSubscriptionsForEvent(ctx, generated.ListWebhookSubscriptionsForEventParams{
+		UserID: parseUUID(userID),
+		Event:  event,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+
+	envelope, err := webhook.NewEnvelope(event, userID, data)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(envelope)
+	if err != nil {
+		return fmt.Errorf("failed to marshal webhook event: %w", err)
+	}
+
+	for _, sub := range subs {
+		delivery, err := db.CreateWebhookDelivery(ctx, generated.CreateWebhookDeliveryParams{
+			SubscriptionID: sub.ID,
+			EventID:        parseUUID(envelope.ID),
+			Event:          event,
+			Payload:        body,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create webhook delivery: %w", err)
+		}
+		if err := EnqueueWebhookDelivery(client, delivery.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnqueueWebhookDelivery queues a delivery that is already in the log.
+func EnqueueWebhookDelivery(client *asynq.Client, deliveryID pgtype.UUID) error {
+	task, err := NewDeliverWebhookTask(DeliverWebhookPayload{DeliveryID: pgUUIDToString(deliveryID)})
+	if err != nil {
+		return fmt.Errorf("failed to create webhook task: %w", err)
+	}
+	if _, err := client.Enqueue(task); err != nil {
+		return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+	return nil
+}
+
+// emitWebhookEvent sends an event to the user's subscribers. Failures are
+// logged; webhooks never fail the job that raised them.
+func (p *RecipeProcessor) emitWebhookEvent(ctx context.Context, userID, event string, data interface{}) {
+	if err := EmitWebhookEvent(ctx, p.db, p.asynqClient, userID, event, data); err != nil {
+		slog.Error("Failed to emit webhook event", "error", err, "event", event, "user_id", userID)
+	}
+}
+
+// HandleDeliverWebhook POSTs a logged event to its subscription and records
+// the outcome. Failed requests are retried with exponential backoff within
+// the task; a delivery that still fails is marked FAILED and can be
+// redelivered through the API.
+func (p *RecipeProcessor) HandleDeliverWebhook(ctx context.Context, t *asynq.Task) error {
+	start := time.Now()
+	var status = "success"
+	defer func() {
+		duration := time.Since(start).Seconds()
+		p.metrics.RecordJob(ctx, "deliver_webhook", status, duration)
+	}()
+
+	var payload DeliverWebhookPayload
+	if err := json.Unmarshal(t.Payload(), &payload); err != nil {
+		status = "failure"
+		return fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+
+	delivery, err := p.db.GetWebhookDelivery(ctx, parseUUID(payload.DeliveryID))
+	if err != nil {
+		status = "failure"
+		// Deliveries are removed with their subscription
+		return fmt.Errorf("webhook delivery not found: %v: %w", err, asynq.SkipRetry)
+	}
+	if delivery.Status == WebhookDeliverySucceeded {
+		return nil
+	}
+
+	sub, err := p.db.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		status = "failure"
+		return fmt.Errorf("webhook subscription not found: %v: %w", err, asynq.SkipRetry)
+	}
+	if !sub.Active {
+		status = "failure"
+		return p.recordWebhookDelivery(ctx, delivery.ID, WebhookDeliveryFailed, webhook.Result{},
+			errors.NewConflictError("Webhook subscription is disabled", "WEBHOOK_DISABLED", "Enable the subscription, then redeliver the event."))
+	}
+
+	result, err := p.webhooks.Deliver(ctx, sub.Url, sub.Secret, payload.DeliveryID, delivery.Event, delivery.Payload)
+	if err != nil {
+		status = "failure"
+		slog.Warn("Webhook delivery failed", "error", err, "delivery_id", payload.DeliveryID, "attempts", result.Attempts)
+		return p.recordWebhookDelivery(ctx, delivery.ID, WebhookDeliveryFailed, result, deliveryError(err))
+	}
+
+	slog.Info("Webhook delivered", "delivery_id", payload.DeliveryID, "event", delivery.Event, "attempts", result.Attempts)
+	return p.recordWebhookDelivery(ctx, delivery.ID, WebhookDeliverySucceeded, result, nil)
+}
+
+// deliveryError is what a failed delivery records. The response status is
+// stored separately, so the message doesn't repeat it.
+func deliveryError(err error) *errors.AppError {
+	if stderrors.Is(err, webhook.ErrPrivateAddress) {
+		return errors.NewValidationError("Webhook URL does not point to a public address", "WEBHOOK_ADDRESS_REFUSED", "Register a URL on a publicly reachable host.")
+	}
+	return errors.NewUnavailableError("Webhook endpoint did not accept the delivery", "WEBHOOK_DELIVERY_FAILED", "Check that the endpoint is reachable and responds with a 2xx status, then redeliver the event.")
+}
+
+func (p *RecipeProcessor) recordWebhookDelivery(ctx context.Context, id pgtype.UUID, status string, result webhook.Result, failure *errors.AppError) error {
+	if err := p.db.UpdateWebhookDeliveryResult(ctx, generated.UpdateWebhookDeliveryResultParams{
+		ID:             id,
+		Status:         status,
+		Attempts:       int32(result.Attempts),
+		ResponseStatus: pgtype.Int4{Int32: int32(result.StatusCode), Valid: result.StatusCode != 0},
+		Error:          errorJSON(failure),
+	}); err != nil {
+		return fmt.Errorf("failed to record webhook delivery %s: %w", uuid.UUID(id.Bytes), err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+-- Migration: Multilingual full-text search vectors
+-- Created: 2026-10-18
+-- Description: Build recipes.search_vector with the text search configuration
+-- that matches recipes.language instead of always using 'english', and rebuild
+-- existing vectors so Dutch (and other) recipes are stemmed correctly.
+
+-- Map an ISO 639-1 language code to a text search configuration.
+-- Must stay in sync with search.TextSearchConfig in internal/services/search.
+CREATE OR REPLACE FUNCTION recipe_search_config(p_language TEXT)
+RETURNS regconfig
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT CASE lower(COALESCE(p_language, 'en'))
+        WHEN 'en' THEN 'english'::regconfig
+        WHEN 'nl' THEN 'dutch'::regconfig
+        WHEN 'de' THEN 'german'::regconfig
+        WHEN 'fr' THEN 'french'::regconfig
+        WHEN 'es' THEN 'spanish'::regconfig
+        WHEN 'it' THEN 'italian'::regconfig
+        WHEN 'pt' THEN 'portuguese'::regconfig
+        ELSE 'simple'::regconfig
+    END;
+$$;
+
+-- Compose the weighted search vector for a recipe row.
+CREATE OR REPLACE FUNCTION build_recipe_search_vector(
+    p_language TEXT,
+    p_recipe_name TEXT,
+    p_description TEXT,
+    p_ingredient_names TEXT[]
+)
+RETURNS tsvector
+LANGUAGE sql
+IMMUTABLE
+AS $$
+    SELECT
+        setweight(to_tsvector(recipe_search_config(p_language), COALESCE(p_recipe_name, '')), 'A') ||
+        setweight(to_tsvector(recipe_search_config(p_language), COALESCE(p_description, '')), 'B') ||
+        setweight(to_tsvector(recipe_search_config(p_language), COALESCE(array_to_string(p_ingredient_names, ' '), '')), 'C');
+$$;
+
+CREATE OR REPLACE FUNCTION recipes_search_vector_trigger()
+RETURNS trigger
+LANGUAGE plpgsql
+AS $$
+BEGIN
+    NEW.search_vector := build_recipe_search_vector(
+        NEW.language,
+        NEW.recipe_name,
+        NEW.description,
+        NEW.ingredient_names
+    );
+    RETURN NEW;
+END;
+$$;
+
+DROP TRIGGER IF EXISTS recipes_search_vector_update ON recipes;
+CREATE TRIGGER recipes_search_vector_update
+    BEFORE INSERT OR UPDATE OF recipe_name, description, ingredient_names, language
+    ON recipes
+    FOR EACH ROW
+    EXECUTE FUNCTION recipes_search_vector_trigger();
+
+-- Rebuild every existing vector with its language-specific configuration
+UPDATE recipes
+SET search_vector = build_recipe_search_vector(language, recipe_name, description, ingredient_names);
+
+CREATE INDEX IF NOT EXISTS recipe_search_idx ON recipes USING GiST (search_vector);
+CREATE INDEX IF NOT EXISTS idx_recipes_language ON recipes(language);
@@ -0,0 +1,20 @@
+-- Migration: Track embedding model and version
+-- Created: 2026-10-18
+-- Description: Record which model and text composition produced each recipe
+-- embedding, so the backfill job can find and re-embed outdated recipes.
+
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS embedding_model TEXT;
+ALTER TABLE recipes ADD COLUMN IF NOT EXISTS embedding_version INTEGER;
+
+COMMENT ON COLUMN recipes.embedding_model IS 'Embedding model that produced recipes.embedding';
+COMMENT ON COLUMN recipes.embedding_version IS 'Version of the embedding text composition (worker.EmbeddingVersion)';
+
+-- Existing embeddings were all produced by ada-002 with the original composition
+UPDATE recipes
+SET embedding_model = 'text-embedding-ada-002',
+    embedding_version = 1
+WHERE embedding IS NOT NULL
+  AND embedding_model IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_recipes_embedding_model_version
+    ON recipes(embedding_model, embedding_version);
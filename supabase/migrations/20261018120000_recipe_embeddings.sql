@@ -0,0 +1,30 @@
+-- Migration: Per-facet recipe embeddings
+-- Created: 2026-10-18
+-- Description: Store several embeddings per recipe (an overview document and
+-- an ingredients document) so semantic search can score a recipe by its
+-- best-matching facet (max-sim aggregation).
+
+CREATE TABLE IF NOT EXISTS recipe_embeddings (
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    embedding vector(1536) NOT NULL,
+    model TEXT NOT NULL,
+    version INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    PRIMARY KEY (recipe_id, kind)
+);
+
+COMMENT ON TABLE recipe_embeddings IS 'Embeddings of the documents composed for a recipe, one row per document kind';
+COMMENT ON COLUMN recipe_embeddings.kind IS 'Document kind: overview or ingredients (worker.EmbeddingKind*)';
+
+CREATE INDEX IF NOT EXISTS idx_recipe_embeddings_embedding
+    ON recipe_embeddings USING hnsw (embedding vector_cosine_ops);
+
+-- Seed the overview kind from the existing single-vector embeddings so max-sim
+-- search works before the backfill has re-embedded every recipe
+INSERT INTO recipe_embeddings (recipe_id, kind, embedding, model, version)
+SELECT id, 'overview', embedding, COALESCE(embedding_model, 'text-embedding-ada-002'), COALESCE(embedding_version, 1)
+FROM recipes
+WHERE embedding IS NOT NULL
+ON CONFLICT (recipe_id, kind) DO NOTHING;
@@ -0,0 +1,30 @@
+-- Migration: Recipe revision history
+-- Created: 2026-10-18
+-- Description: Store every edit to a recipe as a revision with a full snapshot
+-- of its editable content, a field-level diff and the author, so edits can be
+-- reviewed and any revision restored.
+
+CREATE TABLE IF NOT EXISTS recipe_revisions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    revision_number INTEGER NOT NULL,
+    author_id UUID NOT NULL REFERENCES auth.users(id),
+    action TEXT NOT NULL CHECK (action IN ('original', 'edit', 'restore')),
+    restored_from INTEGER,
+    snapshot JSONB NOT NULL,
+    diff JSONB NOT NULL DEFAULT '[]'::jsonb,
+    message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    UNIQUE (recipe_id, revision_number)
+);
+
+COMMENT ON TABLE recipe_revisions IS 'Edit history of recipes; revision 1 is the content as imported';
+COMMENT ON COLUMN recipe_revisions.snapshot IS 'Editable recipe content after this revision (api.RecipeSnapshot)';
+COMMENT ON COLUMN recipe_revisions.diff IS 'Changed top-level fields with their old and new values';
+
+CREATE INDEX IF NOT EXISTS idx_recipe_revisions_recipe_id ON recipe_revisions(recipe_id);
+
+-- Edits rewrite ingredients inside one transaction, where NOW() is constant;
+-- clock_timestamp() keeps created_at ordered by insertion so ingredient order
+-- survives a rewrite.
+ALTER TABLE recipe_ingredients ALTER COLUMN created_at SET DEFAULT clock_timestamp();
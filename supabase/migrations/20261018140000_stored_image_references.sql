@@ -0,0 +1,16 @@
+-- Migration: Stored image reference counts
+-- Created: 2026-10-18
+-- Description: Count the recipe images and social media owner avatars that use
+-- each stored image, so images no longer referenced after a recipe is deleted
+-- can be garbage-collected from storage.
+
+ALTER TABLE stored_images
+    ADD COLUMN IF NOT EXISTS reference_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE stored_images si
+SET reference_count =
+    (SELECT COUNT(*) FROM recipe_images ri WHERE ri.stored_image_id = si.id) +
+    (SELECT COUNT(*) FROM social_media_owners o WHERE o.profile_pic_stored_image_id = si.id::text);
+
+CREATE INDEX IF NOT EXISTS idx_stored_images_unreferenced
+    ON stored_images(created_at) WHERE reference_count <= 0;
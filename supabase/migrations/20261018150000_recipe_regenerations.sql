@@ -0,0 +1,25 @@
+-- Migration: Recipe regenerations
+-- Created: 2026-10-18
+-- Description: Track requests to rerun recipe generation from the stored raw
+-- data with a chosen provider and prompt version, either as a draft for
+-- comparison or replacing the recipe in place.
+
+CREATE TABLE IF NOT EXISTS recipe_regenerations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    requested_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    mode TEXT NOT NULL CHECK (mode IN ('draft', 'replace')),
+    provider TEXT NOT NULL,
+    prompt_version INTEGER NOT NULL,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    draft JSONB,
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN recipe_regenerations.provider IS 'Recipe provider name; empty uses the configured provider and fallback';
+COMMENT ON COLUMN recipe_regenerations.draft IS 'Generated recipe, including categories and rich instructions';
+
+CREATE INDEX IF NOT EXISTS idx_recipe_regenerations_recipe_id ON recipe_regenerations(recipe_id);
@@ -0,0 +1,23 @@
+-- Migration: Add 'manual' to recipe origins
+-- Created: 2026-10-18
+-- Description: Recipes created from pasted text or an uploaded photo have no
+-- source URL; they get the 'manual' origin, and import jobs accept it too.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_enum
+        WHERE enumlabel = 'manual'
+        AND enumtypid = (SELECT oid FROM pg_type WHERE typname = 'recipe_origin')
+    ) THEN
+        ALTER TYPE recipe_origin ADD VALUE 'manual';
+        RAISE NOTICE 'Added manual to recipe_origin enum';
+    ELSE
+        RAISE NOTICE 'manual already exists in recipe_origin enum';
+    END IF;
+END $$;
+
+ALTER TABLE recipe_import_jobs DROP CONSTRAINT IF EXISTS recipe_import_jobs_origin_check;
+ALTER TABLE recipe_import_jobs ADD CONSTRAINT recipe_import_jobs_origin_check
+    CHECK (origin IN ('instagram', 'tiktok', 'youtube', 'firecrawl', 'manual'));
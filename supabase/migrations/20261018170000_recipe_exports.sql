@@ -0,0 +1,26 @@
+-- Migration: Recipe exports
+-- Created: 2026-10-18
+-- Description: Track bulk exports of a user's recipes, built as a zip archive
+-- by a background task and stored in a private bucket.
+
+CREATE TABLE IF NOT EXISTS recipe_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    format TEXT NOT NULL CHECK (format IN ('jsonld', 'paprika', 'mealie', 'markdown', 'html')),
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    recipe_count INTEGER NOT NULL DEFAULT 0,
+    storage_path TEXT,
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN recipe_exports.storage_path IS 'Archive path in the exports bucket, set once the export completes';
+
+CREATE INDEX IF NOT EXISTS idx_recipe_exports_user_id ON recipe_exports(user_id);
+
+-- Archives are only handed out through signed URLs
+INSERT INTO storage.buckets (id, name, public)
+VALUES ('exports', 'exports', false)
+ON CONFLICT (id) DO NOTHING;
@@ -0,0 +1,28 @@
+-- Migration: File recipe imports
+-- Created: 2026-10-18
+-- Description: Recipes imported from other recipe managers' export files get
+-- the 'file' origin, and the uploaded files wait in a private bucket until
+-- the worker reads them.
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1
+        FROM pg_enum
+        WHERE enumlabel = 'file'
+        AND enumtypid = (SELECT oid FROM pg_type WHERE typname = 'recipe_origin')
+    ) THEN
+        ALTER TYPE recipe_origin ADD VALUE 'file';
+        RAISE NOTICE 'Added file to recipe_origin enum';
+    ELSE
+        RAISE NOTICE 'file already exists in recipe_origin enum';
+    END IF;
+END $$;
+
+ALTER TABLE recipe_import_jobs DROP CONSTRAINT IF EXISTS recipe_import_jobs_origin_check;
+ALTER TABLE recipe_import_jobs ADD CONSTRAINT recipe_import_jobs_origin_check
+    CHECK (origin IN ('instagram', 'tiktok', 'youtube', 'firecrawl', 'manual', 'file'));
+
+INSERT INTO storage.buckets (id, name, public)
+VALUES ('imports', 'imports', false)
+ON CONFLICT (id) DO NOTHING;
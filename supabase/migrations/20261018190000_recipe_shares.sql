@@ -0,0 +1,19 @@
+-- Migration: Recipe share links
+-- Created: 2026-10-18
+-- Description: Track public share links for recipes. Links carry a token
+-- signed with SHARE_LINK_SECRET; the row holds their expiry and revocation.
+
+CREATE TABLE IF NOT EXISTS recipe_shares (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipe_id UUID NOT NULL REFERENCES recipes(id) ON DELETE CASCADE,
+    created_by UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    view_count INTEGER NOT NULL DEFAULT 0,
+    last_viewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN recipe_shares.revoked_at IS 'Set when the owner revokes the link; revoked links stop resolving';
+
+CREATE INDEX IF NOT EXISTS idx_recipe_shares_recipe_id ON recipe_shares(recipe_id);
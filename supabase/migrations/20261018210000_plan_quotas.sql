@@ -0,0 +1,31 @@
+-- Migration: Plan import quotas
+-- Created: 2026-10-18
+-- Description: Daily and monthly recipe import quotas per plan tier, and the
+-- plan each user is on. Users without a plan are on the free tier.
+
+CREATE TABLE IF NOT EXISTS plan_quotas (
+    plan TEXT PRIMARY KEY,
+    daily_imports INTEGER,
+    monthly_imports INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN plan_quotas.daily_imports IS 'Imports allowed per UTC day; NULL for unlimited';
+COMMENT ON COLUMN plan_quotas.monthly_imports IS 'Imports allowed per UTC calendar month; NULL for unlimited';
+
+INSERT INTO plan_quotas (plan, daily_imports, monthly_imports) VALUES
+    ('free', 10, 100),
+    ('pro', 100, 2000),
+    ('unlimited', NULL, NULL)
+ON CONFLICT (plan) DO NOTHING;
+
+CREATE TABLE IF NOT EXISTS user_plans (
+    user_id UUID PRIMARY KEY REFERENCES auth.users(id) ON DELETE CASCADE,
+    plan TEXT NOT NULL REFERENCES plan_quotas(plan),
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+-- Quota checks count a user's imports since the start of the day or month
+CREATE INDEX IF NOT EXISTS idx_recipe_import_jobs_user_created ON recipe_import_jobs(user_id, created_at);
@@ -0,0 +1,23 @@
+-- Migration: Service API keys
+-- Created: 2026-10-18
+-- Description: Hashed API keys for sibling services calling Remy on behalf of
+-- users. Each key is limited to a set of scopes and optionally to a list of
+-- users, and replaces the single shared INTERNAL_SERVICE_TOKEN.
+
+CREATE TABLE IF NOT EXISTS service_api_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name TEXT NOT NULL,
+    key_prefix TEXT NOT NULL,
+    key_hash TEXT NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL,
+    allowed_user_ids UUID[],
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN service_api_keys.name IS 'Calling service, recorded when it acts for a user';
+COMMENT ON COLUMN service_api_keys.key_prefix IS 'First characters of the key, to recognise it without the secret';
+COMMENT ON COLUMN service_api_keys.key_hash IS 'Hex SHA-256 of the key; the key itself is only shown when minted';
+COMMENT ON COLUMN service_api_keys.allowed_user_ids IS 'Users the key may act for; NULL for any user';
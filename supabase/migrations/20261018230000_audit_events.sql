@@ -0,0 +1,41 @@
+-- Migration: Audit events
+-- Created: 2026-10-18
+-- Description: Append-only record of data-changing API requests: who acted,
+-- which service acted for them, what they did to which resource, and whether
+-- it succeeded. Rows cannot be updated or deleted.
+
+CREATE TABLE IF NOT EXISTS audit_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID,
+    actor TEXT NOT NULL,
+    impersonator TEXT,
+    action TEXT NOT NULL,
+    resource_type TEXT,
+    resource_id TEXT,
+    method TEXT NOT NULL,
+    path TEXT NOT NULL,
+    request_id TEXT,
+    outcome TEXT NOT NULL CHECK (outcome IN ('success', 'failure')),
+    status_code INTEGER NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN audit_events.user_id IS 'User the action was taken as; NULL for admin actions not tied to a user';
+COMMENT ON COLUMN audit_events.actor IS 'user:<id>, service:<key name> or admin-token';
+COMMENT ON COLUMN audit_events.impersonator IS 'Service that acted for user_id through X-On-Behalf-Of';
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_user_id ON audit_events(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events(created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS audit_events_append_only ON audit_events;
+CREATE TRIGGER audit_events_append_only
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+    EXECUTE FUNCTION audit_events_append_only();
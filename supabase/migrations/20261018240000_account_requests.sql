@@ -0,0 +1,42 @@
+-- Migration: Account data requests
+-- Created: 2026-10-18
+-- Description: Track data subject requests. Account exports are zip archives
+-- of everything stored about a user, built by a background task; account
+-- erasures remove or anonymize that data and are kept as the record that
+-- the erasure happened.
+
+CREATE TABLE IF NOT EXISTS account_exports (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES auth.users(id) ON DELETE CASCADE,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    storage_path TEXT,
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN account_exports.storage_path IS 'Archive path in the exports bucket, set once the export completes';
+
+CREATE INDEX IF NOT EXISTS idx_account_exports_user_id ON account_exports(user_id);
+
+-- user_id has no foreign key so the record outlives the auth user
+CREATE TABLE IF NOT EXISTS account_erasures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL,
+    status TEXT NOT NULL DEFAULT 'QUEUED' CHECK (status IN ('QUEUED', 'EXECUTING', 'COMPLETED', 'FAILED')),
+    summary JSONB,
+    error TEXT,
+    completed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+COMMENT ON COLUMN account_erasures.summary IS 'Number of records and images removed, by kind';
+
+CREATE INDEX IF NOT EXISTS idx_account_erasures_user_id ON account_erasures(user_id, created_at DESC);
+
+-- One erasure at a time per user
+CREATE UNIQUE INDEX IF NOT EXISTS idx_account_erasures_active
+    ON account_erasures(user_id)
+    WHERE status IN ('QUEUED', 'EXECUTING');
@@ -0,0 +1,26 @@
+-- Migration: Structured job errors
+-- Created: 2026-10-18
+-- Description: Store the errors of regenerations, exports, account requests
+-- and webhook deliveries as the JSON error detail import jobs use, so status
+-- endpoints return a code and recovery suggestion instead of raw error text.
+-- Existing messages are kept as internal errors.
+
+ALTER TABLE recipe_regenerations ALTER COLUMN error TYPE JSONB
+    USING CASE WHEN error IS NULL THEN NULL
+        ELSE jsonb_build_object('code', 'REGENERATION_FAILED', 'type', 'INTERNAL_ERROR', 'message', error) END;
+
+ALTER TABLE recipe_exports ALTER COLUMN error TYPE JSONB
+    USING CASE WHEN error IS NULL THEN NULL
+        ELSE jsonb_build_object('code', 'EXPORT_FAILED', 'type', 'INTERNAL_ERROR', 'message', error) END;
+
+ALTER TABLE webhook_deliveries ALTER COLUMN error TYPE JSONB
+    USING CASE WHEN error IS NULL THEN NULL
+        ELSE jsonb_build_object('code', 'WEBHOOK_DELIVERY_FAILED', 'type', 'UNAVAILABLE_ERROR', 'message', error) END;
+
+ALTER TABLE account_exports ALTER COLUMN error TYPE JSONB
+    USING CASE WHEN error IS NULL THEN NULL
+        ELSE jsonb_build_object('code', 'ACCOUNT_EXPORT_FAILED', 'type', 'INTERNAL_ERROR', 'message', error) END;
+
+ALTER TABLE account_erasures ALTER COLUMN error TYPE JSONB
+    USING CASE WHEN error IS NULL THEN NULL
+        ELSE jsonb_build_object('code', 'ACCOUNT_ERASURE_FAILED', 'type', 'INTERNAL_ERROR', 'message', error) END;
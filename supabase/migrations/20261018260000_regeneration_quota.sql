@@ -0,0 +1,7 @@
+-- Migration: Regenerations count against import quotas
+-- Created: 2026-10-18
+-- Description: Regenerations make the same AI calls as imports and use the
+-- user's import quota, which counts them per user and period.
+
+CREATE INDEX IF NOT EXISTS idx_recipe_regenerations_requested_by_created
+    ON recipe_regenerations(requested_by, created_at);
@@ -0,0 +1,14 @@
+-- Migration: Account erasure cleanup
+-- Created: 2026-10-18
+-- Description: Let an erasure delete the Supabase Auth user. The profile
+-- goes with the auth user, and the erasure keeps the content hashes of the
+-- images it released until they are collected, so a retried erasure still
+-- deletes images whose blobs could not be deleted the first time.
+
+ALTER TABLE profiles DROP CONSTRAINT IF EXISTS profiles_id_fkey;
+ALTER TABLE profiles
+    ADD CONSTRAINT profiles_id_fkey FOREIGN KEY (id) REFERENCES auth.users(id) ON DELETE CASCADE;
+
+ALTER TABLE account_erasures ADD COLUMN IF NOT EXISTS image_hashes TEXT[] NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN account_erasures.image_hashes IS 'Content hashes of the stored images the erasure released';
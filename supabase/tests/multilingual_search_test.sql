@@ -0,0 +1,38 @@
+-- pgTAP tests for language-aware recipe search vectors.
+-- Run with: supabase test db
+BEGIN;
+
+SELECT plan(7);
+
+-- Configuration mapping
+SELECT is(recipe_search_config('nl'), 'dutch'::regconfig, 'nl maps to the dutch configuration');
+SELECT is(recipe_search_config(NULL), 'english'::regconfig, 'missing language defaults to english');
+SELECT is(recipe_search_config('ja'), 'simple'::regconfig, 'unsupported languages use simple');
+
+-- Dutch stemming: plural and diminutive forms reduce to the same lexeme
+SELECT ok(
+    build_recipe_search_vector('nl', 'Pannenkoeken', NULL, NULL) @@ plainto_tsquery('dutch', 'pannenkoek'),
+    'Dutch plural matches singular query'
+);
+SELECT ok(
+    build_recipe_search_vector('nl', NULL, 'Romige aardappelen uit de oven', ARRAY['aardappel']) @@ plainto_tsquery('dutch', 'aardappelen'),
+    'Dutch description and ingredients are stemmed with the dutch configuration'
+);
+SELECT ok(
+    NOT (build_recipe_search_vector('nl', 'De lekkerste stamppot', NULL, NULL) @@ to_tsquery('dutch', 'de')),
+    'Dutch stop words are not indexed'
+);
+
+-- The trigger rebuilds the vector when the language changes
+INSERT INTO auth.users (id) VALUES ('00000000-0000-0000-0000-00000000a026');
+INSERT INTO recipes (id, recipe_name, origin, url, language, created_by)
+VALUES ('00000000-0000-0000-0000-00000000b026', 'Gevulde koeken', 'instagram', 'https://example.com/p/1', 'en', '00000000-0000-0000-0000-00000000a026');
+UPDATE recipes SET language = 'nl' WHERE id = '00000000-0000-0000-0000-00000000b026';
+
+SELECT ok(
+    (SELECT search_vector @@ plainto_tsquery('dutch', 'koek') FROM recipes WHERE id = '00000000-0000-0000-0000-00000000b026'),
+    'search_vector is rebuilt with the dutch configuration after a language update'
+);
+
+SELECT * FROM finish();
+ROLLBACK;